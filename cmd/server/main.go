@@ -43,6 +43,14 @@ func main() {
 		logger.Log.Fatal("load config error", zap.Error(err))
 	}
 
+	// Run "goat-server migrate ..." instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			logger.Log.Fatal("migrate failed", zap.Error(err))
+		}
+		return
+	}
+
 	// Create application
 	app := bootstrap.CreateApp()
 
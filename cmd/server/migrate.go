@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/bootstrap"
+	"github.com/HiroLiang/goat-server/internal/config"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/migration"
+)
+
+const migrateUsage = `usage: goat-server migrate <command>
+
+  up            apply every pending migration
+  down [steps]  revert the newest applied migrations, 1 by default
+  status        list migrations and when they were applied
+  baseline <v>  mark migrations up to version v applied without running them,
+                once for a schema created before migrations were tracked
+  new <name>    create empty up and down scripts for every backend`
+
+// runMigrate runs a migrate subcommand against the configured database backend
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	// Scripts are written to the source tree, no database needed
+	if args[0] == "new" {
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		files, err := migration.Create(migration.SourceDir, args[1])
+		for _, file := range files {
+			fmt.Println("created", file)
+		}
+		return err
+	}
+
+	steps := 1
+	var version int64
+	switch args[0] {
+	case "up", "status":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+	case "baseline":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		v, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || v < 1 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		version = v
+	case "down":
+		if len(args) > 2 {
+			return errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid steps %q", args[1])
+			}
+			steps = n
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	backend, err := bootstrap.DatabaseBackend(config.App())
+	if err != nil {
+		return err
+	}
+	dataSources, err := database.NewDataSources(database.BuildDatabaseConfigs(config.App().Database), backend)
+	if err != nil {
+		return err
+	}
+	defer dataSources.CloseAllDBs()
+
+	migrator, err := migration.NewMigrator(dataSources.GetDB(backend), backend)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		printMigrations("applied", applied)
+		if err == nil && len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return err
+	case "baseline":
+		recorded, err := migrator.Baseline(ctx, version)
+		printMigrations("marked applied", recorded)
+		return err
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		printMigrations("reverted", reverted)
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("no applied migration to revert")
+		}
+		return err
+	default:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printStatus(statuses)
+	}
+}
+
+func printMigrations(action string, migrations []migration.Migration) {
+	for _, m := range migrations {
+		fmt.Printf("%s %04d_%s\n", action, m.Version, m.Name)
+	}
+}
+
+func printStatus(statuses []migration.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.UTC().Format(time.DateTime)
+		}
+
+		note := ""
+		switch {
+		case s.Missing:
+			note = "not in this build"
+		case s.Modified:
+			note = "changed after applied"
+		}
+
+		_, _ = fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, appliedAt, note)
+	}
+	return w.Flush()
+}
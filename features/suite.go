@@ -38,8 +38,10 @@ func InitializeSuite(ctx *godog.TestSuiteContext) {
 				deps.AgentRepo = mockRepo.MockAgentRepository() // TODO not implemented
 				deps.TokenService = token.NewAuthTokenService(sessionStore, conf.AuthToken.Expiration)
 				deps.RateLimiter = mockShared.MockRateLimiter()
-				deps.UserRepo = mockRepo.MockUserRepo()         // TODO not implemented
-				deps.UserRoleRepo = mockAuth.MockUserRoleRepo() // TODO not implemented
+				deps.LoginLimiter = mockShared.MockLoginRateLimiter()
+				deps.UserRepo = mockRepo.MockUserRepo() // TODO not implemented
+				deps.UserRoleRepo = mockAuth.MockUserRoleRepo()
+				deps.PermissionRepo = mockAuth.MockPermissionRepo()
 			},
 		)
 
@@ -1,4 +1,72 @@
 package agent
 
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
 type QueryAvailableAgentsInput struct {
 }
+
+// CheckQuotaInput asks whether the current user may send another agent request.
+type CheckQuotaInput struct {
+}
+
+// RecordUsageInput is the metering data of one answered agent request.
+type RecordUsageInput struct {
+	AgentID          agent.ID
+	PromptTokens     int64
+	CompletionTokens int64
+	Latency          time.Duration
+}
+
+// QueryUsageInput selects the usage report range. Zero values default to the last 7 days.
+type QueryUsageInput struct {
+	From time.Time
+	To   time.Time
+}
+
+// QueryUserUsageInput selects the usage report of another user (admin only).
+type QueryUserUsageInput struct {
+	UserID user.ID
+	From   time.Time
+	To     time.Time
+}
+
+// SyncCatalogInput triggers a catalog sync of every configured provider.
+type SyncCatalogInput struct {
+}
+
+type QueryCatalogInput struct {
+}
+
+// ParametersInput are the generation parameters of an agent config.
+type ParametersInput struct {
+	Temperature   float64
+	MaxTokens     int
+	StopSequences []string
+	SystemPrompt  string
+}
+
+// CreateAgentInput creates an agent on a catalog model (admin only).
+type CreateAgentInput struct {
+	Name       string
+	ModelID    agentmodel.ID
+	Parameters ParametersInput
+}
+
+// UpdateAgentConfigInput stores a new config version of an agent (admin only).
+// A zero ModelID keeps the model of the current version.
+type UpdateAgentConfigInput struct {
+	AgentID    agent.ID
+	ModelID    agentmodel.ID
+	Parameters ParametersInput
+	ChangeNote string
+}
+
+type QueryConfigHistoryInput struct {
+	AgentID agent.ID
+}
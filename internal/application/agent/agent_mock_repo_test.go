@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/application/shared/modelcatalog"
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+	"github.com/HiroLiang/goat-server/internal/domain/agentusage"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/domain/userrole"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUsageRepo struct {
+	mock.Mock
+}
+
+var _ agentusage.Repository = (*MockUsageRepo)(nil)
+
+func (m *MockUsageRepo) Record(ctx context.Context, usage *agentusage.Usage) error {
+	args := m.Called(ctx, usage)
+	return args.Error(0)
+}
+
+func (m *MockUsageRepo) SumByUserAndDay(ctx context.Context, userID user.ID, day time.Time) (*agentusage.Usage, error) {
+	args := m.Called(ctx, userID, day)
+	return args.Get(0).(*agentusage.Usage), args.Error(1)
+}
+
+func (m *MockUsageRepo) FindByUser(ctx context.Context, userID user.ID, from, to time.Time) ([]*agentusage.Usage, error) {
+	args := m.Called(ctx, userID, from, to)
+	return args.Get(0).([]*agentusage.Usage), args.Error(1)
+}
+
+type MockUserRoleRepo struct {
+	mock.Mock
+}
+
+var _ userrole.Repository = (*MockUserRoleRepo)(nil)
+
+func (m *MockUserRoleRepo) FindRolesByUser(ctx context.Context, userID user.ID) ([]*role.Role, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*role.Role), args.Error(1)
+}
+
+func (m *MockUserRoleRepo) Exists(ctx context.Context, userID user.ID, role role.Type) bool {
+	args := m.Called(ctx, userID, role)
+	return args.Bool(0)
+}
+
+func (m *MockUserRoleRepo) Assign(ctx context.Context, userID user.ID, role role.Type) error {
+	args := m.Called(ctx, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRoleRepo) Revoke(ctx context.Context, userID user.ID, role role.Type) error {
+	args := m.Called(ctx, userID, role)
+	return args.Error(0)
+}
+
+type MockAgentRepo struct {
+	mock.Mock
+}
+
+var _ agent.Repository = (*MockAgentRepo)(nil)
+
+func (m *MockAgentRepo) FindAll(ctx context.Context) ([]*agent.Agent, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*agent.Agent), args.Error(1)
+}
+
+func (m *MockAgentRepo) FindAllByStatus(ctx context.Context, status agent.Status) ([]*agent.Agent, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]*agent.Agent), args.Error(1)
+}
+
+func (m *MockAgentRepo) FindByID(ctx context.Context, id agent.ID) (*agent.Agent, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*agent.Agent), args.Error(1)
+}
+
+func (m *MockAgentRepo) Create(ctx context.Context, a *agent.Agent) error {
+	args := m.Called(ctx, a)
+	return args.Error(0)
+}
+
+type MockConfigRepo struct {
+	mock.Mock
+}
+
+var _ agent.ConfigRepository = (*MockConfigRepo)(nil)
+
+func (m *MockConfigRepo) FindCurrent(ctx context.Context, agentID agent.ID) (*agent.Config, error) {
+	args := m.Called(ctx, agentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*agent.Config), args.Error(1)
+}
+
+func (m *MockConfigRepo) FindHistory(ctx context.Context, agentID agent.ID) ([]*agent.Config, error) {
+	args := m.Called(ctx, agentID)
+	return args.Get(0).([]*agent.Config), args.Error(1)
+}
+
+func (m *MockConfigRepo) Create(ctx context.Context, config *agent.Config) error {
+	args := m.Called(ctx, config)
+	return args.Error(0)
+}
+
+type MockModelRepo struct {
+	mock.Mock
+}
+
+var _ agentmodel.Repository = (*MockModelRepo)(nil)
+
+func (m *MockModelRepo) FindAll(ctx context.Context) ([]*agentmodel.Model, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*agentmodel.Model), args.Error(1)
+}
+
+func (m *MockModelRepo) FindByID(ctx context.Context, id agentmodel.ID) (*agentmodel.Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*agentmodel.Model), args.Error(1)
+}
+
+func (m *MockModelRepo) Upsert(ctx context.Context, model *agentmodel.Model) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockModelRepo) MarkMissing(ctx context.Context, provider string, present []string) error {
+	args := m.Called(ctx, provider, present)
+	return args.Error(0)
+}
+
+// stubProvider is a model provider returning fixed models or a fixed error.
+type stubProvider struct {
+	name   string
+	models []modelcatalog.ProviderModel
+	err    error
+}
+
+var _ modelcatalog.Provider = (*stubProvider)(nil)
+
+func (p stubProvider) Name() string          { return p.name }
+func (p stubProvider) AgentType() agent.Type { return agent.Local }
+func (p stubProvider) Engine() agent.Engine  { return agent.GGUF }
+
+func (p stubProvider) ListModels(_ context.Context) ([]modelcatalog.ProviderModel, error) {
+	return p.models, p.err
+}
+
+// stubPolicy grants the same permissions to every user
+type stubPolicy struct {
+	granted []permission.Permission
+}
+
+var _ auth.PolicyChecker = stubPolicy{}
+
+func (s stubPolicy) Permissions(_ context.Context, _ user.ID) ([]permission.Permission, error) {
+	return s.granted, nil
+}
+
+func (s stubPolicy) Authorize(_ context.Context, _ user.ID, perm permission.Permission) error {
+	if !slices.Contains(s.granted, perm) {
+		return permission.ErrDenied
+	}
+	return nil
+}
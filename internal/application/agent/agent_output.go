@@ -7,3 +7,82 @@ type QueryAvailableAgentsOutput struct {
 	Provider string
 	Status   agent.Status
 }
+
+// UsageItem is the usage of one agent on one day.
+type UsageItem struct {
+	Date             string
+	AgentID          int64
+	PromptTokens     int64
+	CompletionTokens int64
+	RequestCount     int64
+	AvgLatencyMs     int64
+}
+
+// UsageSummary totals the usage of the whole report range.
+type UsageSummary struct {
+	PromptTokens     int64
+	CompletionTokens int64
+	RequestCount     int64
+	AvgLatencyMs     int64
+}
+
+// QuotaStatus shows today's budget and how much of it is used. Zero limits mean unlimited.
+type QuotaStatus struct {
+	DailyTokens   int64
+	DailyRequests int64
+	UsedTokens    int64
+	UsedRequests  int64
+	ResetAt       string
+}
+
+type UsageReportOutput struct {
+	UserID int64
+	From   string
+	To     string
+	Total  UsageSummary
+	Daily  []UsageItem
+	Quota  QuotaStatus
+}
+
+// SyncCatalogOutput summarizes one catalog sync. Failed maps a skipped provider to its error.
+type SyncCatalogOutput struct {
+	Synced   int
+	Failed   map[string]string
+	SyncedAt string
+}
+
+type CatalogModelOutput struct {
+	ID            int64
+	Provider      string
+	Name          string
+	Family        string
+	ParameterSize string
+	Quantization  string
+	SizeBytes     int64
+	IsAvailable   bool
+	SyncedAt      string
+}
+
+type ParametersOutput struct {
+	Temperature   float64
+	MaxTokens     int
+	StopSequences []string
+	SystemPrompt  string
+}
+
+type AgentConfigOutput struct {
+	AgentID    int64
+	Version    int
+	ModelID    int64
+	Parameters ParametersOutput
+	ChangeNote string
+	CreatedBy  int64
+	CreatedAt  string
+}
+
+type CreateAgentOutput struct {
+	ID     int64
+	Name   string
+	Status agent.Status
+	Config AgentConfigOutput
+}
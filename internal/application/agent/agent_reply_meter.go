@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/agentreply"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// ReplyMeter applies the quotas of CheckQuota and RecordUsage to the agent replies of chats,
+// on behalf of the user whose message started the reply rather than the caller.
+type ReplyMeter struct {
+	useCase *UseCase
+}
+
+var _ agentreply.Meter = (*ReplyMeter)(nil)
+
+func NewReplyMeter(useCase *UseCase) *ReplyMeter {
+	return &ReplyMeter{useCase: useCase}
+}
+
+func (m *ReplyMeter) CheckQuota(ctx context.Context, userID user.ID) error {
+	return m.useCase.checkQuota(ctx, userID)
+}
+
+func (m *ReplyMeter) RecordUsage(ctx context.Context, usage agentreply.Usage) error {
+	return m.useCase.recordUsage(ctx, usage.UserID, RecordUsageInput{
+		AgentID:          usage.AgentID,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		Latency:          usage.Latency,
+	})
+}
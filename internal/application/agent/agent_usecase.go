@@ -2,17 +2,58 @@ package agent
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/application/shared/modelcatalog"
 	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+	"github.com/HiroLiang/goat-server/internal/domain/agentusage"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/domain/userrole"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+)
+
+const (
+	defaultUsageDays = 7
+	maxUsageDays     = 92
 )
 
 type UseCase struct {
-	agentRepo agent.Repository
+	agentRepo    agent.Repository
+	configRepo   agent.ConfigRepository
+	modelRepo    agentmodel.Repository
+	usageRepo    agentusage.Repository
+	userRoleRepo userrole.Repository
+	policy       auth.PolicyChecker
+	quotaPolicy  agentusage.QuotaPolicy
+	providers    []modelcatalog.Provider
 }
 
-func NewUseCase(agentRepo agent.Repository) *UseCase {
-	return &UseCase{agentRepo}
+func NewUseCase(
+	agentRepo agent.Repository,
+	configRepo agent.ConfigRepository,
+	modelRepo agentmodel.Repository,
+	usageRepo agentusage.Repository,
+	userRoleRepo userrole.Repository,
+	policy auth.PolicyChecker,
+	quotaPolicy agentusage.QuotaPolicy,
+	providers []modelcatalog.Provider,
+) *UseCase {
+	return &UseCase{
+		agentRepo:    agentRepo,
+		configRepo:   configRepo,
+		modelRepo:    modelRepo,
+		usageRepo:    usageRepo,
+		userRoleRepo: userRoleRepo,
+		policy:       policy,
+		quotaPolicy:  quotaPolicy,
+		providers:    providers,
+	}
 }
 
 func (u UseCase) FindAvailableAgents(
@@ -26,12 +67,489 @@ func (u UseCase) FindAvailableAgents(
 
 	outputs := make([]QueryAvailableAgentsOutput, len(domains))
 	for i, domain := range domains {
+		provider, err := u.providerOf(ctx, domain.ID)
+		if err != nil {
+			return nil, err
+		}
+
 		outputs[i] = QueryAvailableAgentsOutput{
 			Name:     domain.Name,
-			Provider: "",
+			Provider: provider,
 			Status:   domain.Status,
 		}
 	}
 
 	return outputs, nil
 }
+
+// CheckQuota returns agentusage.ErrQuotaExceeded (as *agentusage.QuotaExceededError)
+// when the current user has used up today's budget of their roles.
+func (u UseCase) CheckQuota(ctx context.Context, input shared.UseCaseInput[CheckQuotaInput]) error {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return user.ErrInvalidUser
+	}
+
+	return u.checkQuota(ctx, userID)
+}
+
+// RecordUsage meters one answered agent request of the current user.
+func (u UseCase) RecordUsage(ctx context.Context, input shared.UseCaseInput[RecordUsageInput]) error {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return user.ErrInvalidUser
+	}
+
+	return u.recordUsage(ctx, userID, input.Data)
+}
+
+// GetMyUsage returns the usage report of the current user.
+func (u UseCase) GetMyUsage(
+	ctx context.Context,
+	input shared.UseCaseInput[QueryUsageInput],
+) (UsageReportOutput, error) {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return UsageReportOutput{}, user.ErrInvalidUser
+	}
+
+	return u.usageReport(ctx, userID, input.Data.From, input.Data.To)
+}
+
+// GetUserUsage returns the usage report of any user. Requires agent:view_usage.
+func (u UseCase) GetUserUsage(
+	ctx context.Context,
+	input shared.UseCaseInput[QueryUserUsageInput],
+) (UsageReportOutput, error) {
+	_, err := u.authorize(ctx, input.Base, permission.AgentViewUsage)
+	if errors.Is(err, agent.ErrForbidden) {
+		return UsageReportOutput{}, agentusage.ErrForbidden
+	}
+	if err != nil {
+		return UsageReportOutput{}, err
+	}
+
+	return u.usageReport(ctx, input.Data.UserID, input.Data.From, input.Data.To)
+}
+
+func (u UseCase) usageReport(
+	ctx context.Context,
+	userID user.ID,
+	from, to time.Time,
+) (UsageReportOutput, error) {
+	now := time.Now()
+
+	// Default to the last 7 days, today included
+	if to.IsZero() {
+		to = now
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(defaultUsageDays - 1))
+	}
+	from, to = agentusage.Day(from), agentusage.Day(to)
+
+	if from.After(to) || to.Sub(from) > maxUsageDays*24*time.Hour {
+		return UsageReportOutput{}, agentusage.ErrInvalidRange
+	}
+
+	usages, err := u.usageRepo.FindByUser(ctx, userID, from, to)
+	if err != nil {
+		return UsageReportOutput{}, err
+	}
+
+	total := &agentusage.Usage{}
+	daily := make([]UsageItem, 0, len(usages))
+	for _, usage := range usages {
+		total.Add(usage)
+		daily = append(daily, UsageItem{
+			Date:             timeutil.Format(usage.Date, timeutil.FormatDate),
+			AgentID:          int64(usage.AgentID),
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			RequestCount:     usage.RequestCount,
+			AvgLatencyMs:     usage.AverageLatency().Milliseconds(),
+		})
+	}
+
+	quota, err := u.quotaOf(ctx, userID)
+	if err != nil {
+		return UsageReportOutput{}, err
+	}
+
+	today, err := u.usageRepo.SumByUserAndDay(ctx, userID, now)
+	if err != nil {
+		return UsageReportOutput{}, err
+	}
+
+	return UsageReportOutput{
+		UserID: int64(userID),
+		From:   timeutil.Format(from, timeutil.FormatDate),
+		To:     timeutil.Format(to, timeutil.FormatDate),
+		Total: UsageSummary{
+			PromptTokens:     total.PromptTokens,
+			CompletionTokens: total.CompletionTokens,
+			RequestCount:     total.RequestCount,
+			AvgLatencyMs:     total.AverageLatency().Milliseconds(),
+		},
+		Daily: daily,
+		Quota: QuotaStatus{
+			DailyTokens:   quota.DailyTokens,
+			DailyRequests: quota.DailyRequests,
+			UsedTokens:    today.TotalTokens(),
+			UsedRequests:  today.RequestCount,
+			ResetAt:       agentusage.Day(now).Add(24 * time.Hour).Format(timeutil.FormatISO),
+		},
+	}, nil
+}
+
+// SyncCatalog upserts the models of every provider into the catalog and flags
+// models a provider no longer lists as unavailable. A failing provider is reported
+// in the output but does not stop the others.
+func (u UseCase) SyncCatalog(
+	ctx context.Context,
+	_ shared.UseCaseInput[SyncCatalogInput],
+) (SyncCatalogOutput, error) {
+	output := SyncCatalogOutput{Failed: map[string]string{}}
+
+	for _, provider := range u.providers {
+		synced, err := u.syncProvider(ctx, provider)
+		if err != nil {
+			output.Failed[provider.Name()] = err.Error()
+			continue
+		}
+		output.Synced += synced
+	}
+
+	output.SyncedAt = timeutil.Format(time.Now(), timeutil.FormatISO)
+	return output, nil
+}
+
+// RequestCatalogSync runs SyncCatalog on behalf of a user with agent:manage.
+func (u UseCase) RequestCatalogSync(
+	ctx context.Context,
+	input shared.UseCaseInput[SyncCatalogInput],
+) (SyncCatalogOutput, error) {
+	if _, err := u.authorize(ctx, input.Base, permission.AgentManage); err != nil {
+		return SyncCatalogOutput{}, err
+	}
+
+	return u.SyncCatalog(ctx, input)
+}
+
+func (u UseCase) syncProvider(ctx context.Context, provider modelcatalog.Provider) (int, error) {
+	models, err := provider.ListModels(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	present := make([]string, 0, len(models))
+	for _, m := range models {
+		if err := u.modelRepo.Upsert(ctx, &agentmodel.Model{
+			Provider:      provider.Name(),
+			Name:          m.Name,
+			Family:        m.Family,
+			ParameterSize: m.ParameterSize,
+			Quantization:  m.Quantization,
+			SizeBytes:     m.SizeBytes,
+			Digest:        m.Digest,
+			ModifiedAt:    m.ModifiedAt,
+			IsAvailable:   true,
+		}); err != nil {
+			return 0, err
+		}
+		present = append(present, m.Name)
+	}
+
+	if err := u.modelRepo.MarkMissing(ctx, provider.Name(), present); err != nil {
+		return 0, err
+	}
+
+	return len(models), nil
+}
+
+// ListCatalog returns every catalog model, including unavailable ones (requires agent:manage).
+func (u UseCase) ListCatalog(
+	ctx context.Context,
+	input shared.UseCaseInput[QueryCatalogInput],
+) ([]CatalogModelOutput, error) {
+	if _, err := u.authorize(ctx, input.Base, permission.AgentManage); err != nil {
+		return nil, err
+	}
+
+	models, err := u.modelRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]CatalogModelOutput, len(models))
+	for i, m := range models {
+		outputs[i] = CatalogModelOutput{
+			ID:            int64(m.ID),
+			Provider:      m.Provider,
+			Name:          m.Name,
+			Family:        m.Family,
+			ParameterSize: m.ParameterSize,
+			Quantization:  m.Quantization,
+			SizeBytes:     m.SizeBytes,
+			IsAvailable:   m.IsAvailable,
+			SyncedAt:      timeutil.Format(m.SyncedAt, timeutil.FormatISO),
+		}
+	}
+
+	return outputs, nil
+}
+
+// CreateAgent creates an agent on an available catalog model with its first config version (requires agent:manage).
+func (u UseCase) CreateAgent(
+	ctx context.Context,
+	input shared.UseCaseInput[CreateAgentInput],
+) (CreateAgentOutput, error) {
+	adminID, err := u.authorize(ctx, input.Base, permission.AgentManage)
+	if err != nil {
+		return CreateAgentOutput{}, err
+	}
+
+	model, err := u.modelRepo.FindByID(ctx, input.Data.ModelID)
+	if err != nil {
+		return CreateAgentOutput{}, err
+	}
+	if !model.CanBeUsed() {
+		return CreateAgentOutput{}, agentmodel.ErrUnavailable
+	}
+
+	provider, ok := u.findProvider(model.Provider)
+	if !ok {
+		return CreateAgentOutput{}, agentmodel.ErrUnavailable
+	}
+
+	name := input.Data.Name
+	if name == "" {
+		name = model.Name
+	}
+
+	config, err := agent.NewConfig(0, model.ID, toParameters(input.Data.Parameters), "initial config", adminID)
+	if err != nil {
+		return CreateAgentOutput{}, err
+	}
+
+	newAgent := agent.NewAgent(name, provider.AgentType(), provider.Engine(), adminID)
+	if err := u.agentRepo.Create(ctx, newAgent); err != nil {
+		return CreateAgentOutput{}, err
+	}
+
+	config.AgentID = newAgent.ID
+	if err := u.configRepo.Create(ctx, config); err != nil {
+		return CreateAgentOutput{}, err
+	}
+
+	return CreateAgentOutput{
+		ID:     int64(newAgent.ID),
+		Name:   newAgent.Name,
+		Status: newAgent.Status,
+		Config: toConfigOutput(config),
+	}, nil
+}
+
+// UpdateAgentConfig stores a new config version of an agent (requires agent:manage).
+func (u UseCase) UpdateAgentConfig(
+	ctx context.Context,
+	input shared.UseCaseInput[UpdateAgentConfigInput],
+) (AgentConfigOutput, error) {
+	adminID, err := u.authorize(ctx, input.Base, permission.AgentManage)
+	if err != nil {
+		return AgentConfigOutput{}, err
+	}
+
+	if _, err := u.agentRepo.FindByID(ctx, input.Data.AgentID); err != nil {
+		return AgentConfigOutput{}, err
+	}
+
+	modelID := input.Data.ModelID
+	if modelID == 0 {
+		current, err := u.configRepo.FindCurrent(ctx, input.Data.AgentID)
+		if err != nil {
+			return AgentConfigOutput{}, err
+		}
+		modelID = current.ModelID
+	}
+
+	model, err := u.modelRepo.FindByID(ctx, modelID)
+	if err != nil {
+		return AgentConfigOutput{}, err
+	}
+	if !model.CanBeUsed() {
+		return AgentConfigOutput{}, agentmodel.ErrUnavailable
+	}
+
+	config, err := agent.NewConfig(
+		input.Data.AgentID,
+		model.ID,
+		toParameters(input.Data.Parameters),
+		input.Data.ChangeNote,
+		adminID,
+	)
+	if err != nil {
+		return AgentConfigOutput{}, err
+	}
+
+	if err := u.configRepo.Create(ctx, config); err != nil {
+		return AgentConfigOutput{}, err
+	}
+
+	return toConfigOutput(config), nil
+}
+
+// GetAgentConfigHistory returns every config version of an agent, newest first (requires agent:manage).
+func (u UseCase) GetAgentConfigHistory(
+	ctx context.Context,
+	input shared.UseCaseInput[QueryConfigHistoryInput],
+) ([]AgentConfigOutput, error) {
+	if _, err := u.authorize(ctx, input.Base, permission.AgentManage); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.agentRepo.FindByID(ctx, input.Data.AgentID); err != nil {
+		return nil, err
+	}
+
+	configs, err := u.configRepo.FindHistory(ctx, input.Data.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]AgentConfigOutput, len(configs))
+	for i, config := range configs {
+		outputs[i] = toConfigOutput(config)
+	}
+
+	return outputs, nil
+}
+
+// authorize returns the caller ID, or agent.ErrForbidden when the caller lacks perm.
+func (u UseCase) authorize(ctx context.Context, base shared.BaseInput, perm permission.Permission) (user.ID, error) {
+	callerID, err := user.ToID(base.Auth.UserID)
+	if err != nil {
+		return 0, user.ErrInvalidUser
+	}
+
+	err = u.policy.Authorize(ctx, callerID, perm)
+	if errors.Is(err, permission.ErrDenied) {
+		return 0, agent.ErrForbidden
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return callerID, nil
+}
+
+func (u UseCase) findProvider(name string) (modelcatalog.Provider, bool) {
+	for _, provider := range u.providers {
+		if provider.Name() == name {
+			return provider, true
+		}
+	}
+	return nil, false
+}
+
+// providerOf resolves the provider of the current model of an agent, empty if not configured.
+func (u UseCase) providerOf(ctx context.Context, agentID agent.ID) (string, error) {
+	config, err := u.configRepo.FindCurrent(ctx, agentID)
+	if errors.Is(err, agent.ErrConfigNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	model, err := u.modelRepo.FindByID(ctx, config.ModelID)
+	if errors.Is(err, agentmodel.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return model.Provider, nil
+}
+
+func toParameters(input ParametersInput) agent.Parameters {
+	return agent.Parameters{
+		Temperature:   input.Temperature,
+		MaxTokens:     input.MaxTokens,
+		StopSequences: input.StopSequences,
+		SystemPrompt:  input.SystemPrompt,
+	}
+}
+
+func toConfigOutput(config *agent.Config) AgentConfigOutput {
+	stops := config.Parameters.StopSequences
+	if stops == nil {
+		stops = []string{}
+	}
+
+	return AgentConfigOutput{
+		AgentID: int64(config.AgentID),
+		Version: config.Version,
+		ModelID: int64(config.ModelID),
+		Parameters: ParametersOutput{
+			Temperature:   config.Parameters.Temperature,
+			MaxTokens:     config.Parameters.MaxTokens,
+			StopSequences: stops,
+			SystemPrompt:  config.Parameters.SystemPrompt,
+		},
+		ChangeNote: config.ChangeNote,
+		CreatedBy:  int64(config.CreatedBy),
+		CreatedAt:  timeutil.Format(config.CreatedAt, timeutil.FormatISO),
+	}
+}
+
+// checkQuota compares today's usage of the user with their quota.
+func (u UseCase) checkQuota(ctx context.Context, userID user.ID) error {
+	quota, err := u.quotaOf(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if quota.IsUnlimited() {
+		return nil
+	}
+
+	now := time.Now()
+	today, err := u.usageRepo.SumByUserAndDay(ctx, userID, now)
+	if err != nil {
+		return err
+	}
+
+	return quota.Check(today, now)
+}
+
+func (u UseCase) recordUsage(ctx context.Context, userID user.ID, data RecordUsageInput) error {
+	usage, err := agentusage.NewUsage(
+		userID,
+		data.AgentID,
+		data.PromptTokens,
+		data.CompletionTokens,
+		data.Latency,
+		time.Now(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return u.usageRepo.Record(ctx, usage)
+}
+
+// quotaOf resolves the most generous quota among the user's roles.
+func (u UseCase) quotaOf(ctx context.Context, userID user.ID) (agentusage.Quota, error) {
+	roles, err := u.userRoleRepo.FindRolesByUser(ctx, userID)
+	if err != nil {
+		return agentusage.Quota{}, err
+	}
+
+	types := make([]role.Type, 0, len(roles))
+	for _, r := range roles {
+		types = append(types, r.Type)
+	}
+
+	return u.quotaPolicy.For(types), nil
+}
@@ -0,0 +1,232 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/modelcatalog"
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+	"github.com/HiroLiang/goat-server/internal/domain/agentusage"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testQuotaPolicy = agentusage.QuotaPolicy{
+	Default: agentusage.Quota{DailyTokens: 100},
+	Roles: map[role.Type]agentusage.Quota{
+		role.Guest: {DailyTokens: 1000, DailyRequests: 10},
+		role.User:  {DailyTokens: 50000, DailyRequests: 500},
+		role.Admin: {},
+	},
+}
+
+func authInput[T any](userID string, data T) shared.UseCaseInput[T] {
+	return shared.UseCaseInput[T]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: userID}},
+		Data: data,
+	}
+}
+
+func TestCheckQuota_GuestExceeded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	roles := new(MockUserRoleRepo)
+	roles.On("FindRolesByUser", mock.Anything, user.ID(1)).
+		Return([]*role.Role{{Type: role.Guest}}, nil)
+
+	usages := new(MockUsageRepo)
+	usages.On("SumByUserAndDay", mock.Anything, user.ID(1), mock.Anything).
+		Return(&agentusage.Usage{PromptTokens: 700, CompletionTokens: 300, RequestCount: 3}, nil)
+
+	uc := NewUseCase(nil, nil, nil, usages, roles, nil, testQuotaPolicy, nil)
+
+	err := uc.CheckQuota(ctx, authInput("1", CheckQuotaInput{}))
+
+	assert.ErrorIs(t, err, agentusage.ErrQuotaExceeded)
+	var quotaErr *agentusage.QuotaExceededError
+	if assert.ErrorAs(t, err, &quotaErr) {
+		assert.Equal(t, "tokens", quotaErr.Kind)
+		assert.Equal(t, int64(1000), quotaErr.Limit)
+	}
+}
+
+func TestCheckQuota_MostGenerousRoleWins(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	roles := new(MockUserRoleRepo)
+	roles.On("FindRolesByUser", mock.Anything, user.ID(1)).
+		Return([]*role.Role{{Type: role.Guest}, {Type: role.User}}, nil)
+
+	usages := new(MockUsageRepo)
+	usages.On("SumByUserAndDay", mock.Anything, user.ID(1), mock.Anything).
+		Return(&agentusage.Usage{PromptTokens: 1500, RequestCount: 20}, nil)
+
+	uc := NewUseCase(nil, nil, nil, usages, roles, nil, testQuotaPolicy, nil)
+
+	err := uc.CheckQuota(ctx, authInput("1", CheckQuotaInput{}))
+
+	assert.NoError(t, err)
+}
+
+func TestCheckQuota_UnlimitedSkipsUsageLookup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	roles := new(MockUserRoleRepo)
+	roles.On("FindRolesByUser", mock.Anything, user.ID(1)).
+		Return([]*role.Role{{Type: role.Admin}}, nil)
+
+	usages := new(MockUsageRepo)
+
+	uc := NewUseCase(nil, nil, nil, usages, roles, nil, testQuotaPolicy, nil)
+
+	err := uc.CheckQuota(ctx, authInput("1", CheckQuotaInput{}))
+
+	assert.NoError(t, err)
+	usages.AssertNotCalled(t, "SumByUserAndDay", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReplyMeter_RefusesOverQuotaRequester(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	roles := new(MockUserRoleRepo)
+	roles.On("FindRolesByUser", mock.Anything, user.ID(7)).
+		Return([]*role.Role{{Type: role.Guest}}, nil)
+
+	usages := new(MockUsageRepo)
+	usages.On("SumByUserAndDay", mock.Anything, user.ID(7), mock.Anything).
+		Return(&agentusage.Usage{PromptTokens: 900, CompletionTokens: 100, RequestCount: 4}, nil)
+
+	meter := NewReplyMeter(NewUseCase(nil, nil, nil, usages, roles, nil, testQuotaPolicy, nil))
+
+	err := meter.CheckQuota(ctx, user.ID(7))
+
+	assert.ErrorIs(t, err, agentusage.ErrQuotaExceeded)
+}
+
+func TestGetUserUsage_NonAdminForbidden(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// managing agents does not allow reading the usage of others
+	policy := stubPolicy{granted: []permission.Permission{permission.AgentManage}}
+
+	uc := NewUseCase(nil, nil, nil, new(MockUsageRepo), new(MockUserRoleRepo), policy, testQuotaPolicy, nil)
+
+	_, err := uc.GetUserUsage(ctx, authInput("1", QueryUserUsageInput{UserID: 2}))
+
+	assert.ErrorIs(t, err, agentusage.ErrForbidden)
+}
+
+func TestGetMyUsage_InvalidRange(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	uc := NewUseCase(nil, nil, nil, new(MockUsageRepo), new(MockUserRoleRepo), nil, testQuotaPolicy, nil)
+
+	now := time.Now()
+	_, err := uc.GetMyUsage(ctx, authInput("1", QueryUsageInput{From: now, To: now.AddDate(0, 0, -1)}))
+
+	assert.ErrorIs(t, err, agentusage.ErrInvalidRange)
+}
+
+func TestSyncCatalog_FailedProviderDoesNotStopOthers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	models := new(MockModelRepo)
+	models.On("Upsert", mock.Anything, mock.MatchedBy(func(m *agentmodel.Model) bool {
+		return m.Provider == "ollama" && m.IsAvailable
+	})).Return(nil).Twice()
+	models.On("MarkMissing", mock.Anything, "ollama", []string{"llama3:8b", "qwen2:7b"}).Return(nil)
+
+	providers := []modelcatalog.Provider{
+		stubProvider{name: "offline", err: errors.New("connection refused")},
+		stubProvider{name: "ollama", models: []modelcatalog.ProviderModel{
+			{Name: "llama3:8b"},
+			{Name: "qwen2:7b"},
+		}},
+	}
+
+	uc := NewUseCase(nil, nil, models, nil, nil, nil, testQuotaPolicy, providers)
+
+	output, err := uc.SyncCatalog(ctx, shared.UseCaseInput[SyncCatalogInput]{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, output.Synced)
+	assert.Equal(t, map[string]string{"offline": "connection refused"}, output.Failed)
+	models.AssertExpectations(t)
+	models.AssertNotCalled(t, "MarkMissing", mock.Anything, "offline", mock.Anything)
+}
+
+func TestUpdateAgentConfig_KeepsCurrentModelAsNewVersion(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	policy := stubPolicy{granted: []permission.Permission{permission.AgentManage}}
+
+	agents := new(MockAgentRepo)
+	agents.On("FindByID", mock.Anything, agent.ID(5)).Return(&agent.Agent{ID: 5}, nil)
+
+	models := new(MockModelRepo)
+	models.On("FindByID", mock.Anything, agentmodel.ID(3)).
+		Return(&agentmodel.Model{ID: 3, IsAvailable: true}, nil)
+
+	configs := new(MockConfigRepo)
+	configs.On("FindCurrent", mock.Anything, agent.ID(5)).
+		Return(&agent.Config{AgentID: 5, Version: 1, ModelID: 3}, nil)
+	configs.On("Create", mock.Anything, mock.AnythingOfType("*agent.Config")).
+		Run(func(args mock.Arguments) { args.Get(1).(*agent.Config).Version = 2 }).
+		Return(nil)
+
+	uc := NewUseCase(agents, configs, models, nil, nil, policy, testQuotaPolicy, nil)
+
+	output, err := uc.UpdateAgentConfig(ctx, authInput("1", UpdateAgentConfigInput{
+		AgentID:    5,
+		Parameters: ParametersInput{Temperature: 0.2, StopSequences: []string{"###"}},
+		ChangeNote: "lower temperature",
+	}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, output.Version)
+	assert.Equal(t, int64(3), output.ModelID)
+	assert.Equal(t, int64(1), output.CreatedBy)
+	assert.Equal(t, 0.2, output.Parameters.Temperature)
+}
+
+func TestUpdateAgentConfig_InvalidParameters(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	policy := stubPolicy{granted: []permission.Permission{permission.AgentManage}}
+
+	agents := new(MockAgentRepo)
+	agents.On("FindByID", mock.Anything, agent.ID(5)).Return(&agent.Agent{ID: 5}, nil)
+
+	models := new(MockModelRepo)
+	models.On("FindByID", mock.Anything, agentmodel.ID(3)).
+		Return(&agentmodel.Model{ID: 3, IsAvailable: true}, nil)
+
+	configs := new(MockConfigRepo)
+
+	uc := NewUseCase(agents, configs, models, nil, nil, policy, testQuotaPolicy, nil)
+
+	_, err := uc.UpdateAgentConfig(ctx, authInput("1", UpdateAgentConfigInput{
+		AgentID:    5,
+		ModelID:    3,
+		Parameters: ParametersInput{Temperature: 3},
+	}))
+
+	assert.ErrorIs(t, err, agent.ErrInvalidParameters)
+	configs.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,14 @@
+package apikey
+
+import "time"
+
+// CreateInput a named key with its scopes, ExpiresIn zero uses the default lifetime
+type CreateInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresIn time.Duration
+}
+
+type RevokeInput struct {
+	ID int64
+}
@@ -0,0 +1,65 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyRepo struct {
+	mock.Mock
+}
+
+var _ apikey.Repository = (*MockAPIKeyRepo)(nil)
+
+func (m *MockAPIKeyRepo) Create(ctx context.Context, k *apikey.APIKey) error {
+	args := m.Called(ctx, k)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepo) FindByHash(ctx context.Context, hash string) (*apikey.APIKey, error) {
+	args := m.Called(ctx, hash)
+	k, _ := args.Get(0).(*apikey.APIKey)
+	return k, args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) FindByUser(ctx context.Context, userID user.ID) ([]*apikey.APIKey, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*apikey.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) CountActive(ctx context.Context, userID user.ID) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) Revoke(ctx context.Context, userID user.ID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepo) Touch(ctx context.Context, id int64, at time.Time, ip string) error {
+	args := m.Called(ctx, id, at, ip)
+	return args.Error(0)
+}
+
+type MockUserRepo struct {
+	user.Repository
+	mock.Mock
+}
+
+func (m *MockUserRepo) FindByID(ctx context.Context, id user.ID) (*user.User, error) {
+	args := m.Called(ctx, id)
+	u, _ := args.Get(0).(*user.User)
+	return u, args.Error(1)
+}
+
+// stubHMACer signs by tagging the message, enough to tell the stored hash from the key
+type stubHMACer struct{}
+
+func (stubHMACer) Sign(message string) string { return "hmac:" + message }
+
+func (stubHMACer) Verify(message, signature string) bool { return signature == "hmac:"+message }
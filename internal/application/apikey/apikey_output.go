@@ -0,0 +1,22 @@
+package apikey
+
+// CreateOutput holds the only copy of the key, it cannot be shown again
+type CreateOutput struct {
+	Key    string
+	APIKey APIKeyItem
+}
+
+type APIKeyItem struct {
+	ID         int64
+	Name       string
+	Prefix     string
+	Scopes     []string
+	ExpiresAt  string
+	LastUsedAt string
+	LastUsedIP string
+	CreatedAt  string
+}
+
+type ListOutput struct {
+	APIKeys []APIKeyItem
+}
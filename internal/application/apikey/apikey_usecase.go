@@ -0,0 +1,209 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/application/shared/security"
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+)
+
+// prefixLength characters of a key are kept in clear to tell keys apart
+const prefixLength = len(apikey.TokenPrefix) + 6
+
+// Config lifetimes and limits of API keys
+type Config struct {
+	DefaultTTL    time.Duration
+	MaxTTL        time.Duration
+	MaxPerUser    int
+	TouchInterval time.Duration // minimum time between last-used writes from the same IP
+}
+
+// UseCase mints, lists and revokes API keys, and authenticates requests made with them.
+// Keys are random, so an HMAC is enough to store them; a slow password hash would
+// run on every request.
+type UseCase struct {
+	repo     apikey.Repository
+	userRepo user.Repository
+	hmacer   security.HMACer
+	conf     Config
+}
+
+var _ auth.APIKeyAuthenticator = (*UseCase)(nil)
+
+func NewUseCase(
+	repo apikey.Repository,
+	userRepo user.Repository,
+	hmacer security.HMACer,
+	conf Config) *UseCase {
+	return &UseCase{
+		repo:     repo,
+		userRepo: userRepo,
+		hmacer:   hmacer,
+		conf:     conf,
+	}
+}
+
+// Create mints a key for the current user. A request made with an API key cannot
+// mint keys, so a leaked key never outlives its own expiry.
+func (u *UseCase) Create(ctx context.Context, input shared.UseCaseInput[CreateInput]) (CreateOutput, error) {
+	if input.Base.Auth.APIKeyID != "" {
+		return CreateOutput{}, apikey.ErrKeyNotAllowed
+	}
+
+	id, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return CreateOutput{}, user.ErrInvalidUser
+	}
+
+	name := strings.TrimSpace(input.Data.Name)
+	if name == "" {
+		return CreateOutput{}, apikey.ErrNameRequired
+	}
+	if len(input.Data.Scopes) == 0 {
+		return CreateOutput{}, apikey.ErrInvalidScope
+	}
+
+	scopes := make([]apikey.Scope, 0, len(input.Data.Scopes))
+	for _, s := range input.Data.Scopes {
+		scope, err := apikey.ParseScope(s)
+		if err != nil {
+			return CreateOutput{}, err
+		}
+		scopes = append(scopes, scope)
+	}
+
+	ttl := input.Data.ExpiresIn
+	if ttl == 0 {
+		ttl = u.conf.DefaultTTL
+	}
+	if ttl < 0 || (u.conf.MaxTTL > 0 && ttl > u.conf.MaxTTL) {
+		return CreateOutput{}, apikey.ErrInvalidExpiry
+	}
+
+	if u.conf.MaxPerUser > 0 {
+		count, err := u.repo.CountActive(ctx, id)
+		if err != nil {
+			return CreateOutput{}, err
+		}
+		if count >= u.conf.MaxPerUser {
+			return CreateOutput{}, apikey.ErrTooManyKeys
+		}
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return CreateOutput{}, err
+	}
+
+	key := &apikey.APIKey{
+		UserID:    id,
+		Name:      name,
+		Prefix:    token[:prefixLength],
+		Hash:      u.hmacer.Sign(token),
+		Scopes:    scopes,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := u.repo.Create(ctx, key); err != nil {
+		return CreateOutput{}, err
+	}
+
+	return CreateOutput{Key: token, APIKey: toAPIKeyItem(key)}, nil
+}
+
+// List lists the active keys of the current user
+func (u *UseCase) List(ctx context.Context, input shared.UseCaseInput[struct{}]) (ListOutput, error) {
+	id, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return ListOutput{}, user.ErrInvalidUser
+	}
+
+	keys, err := u.repo.FindByUser(ctx, id)
+	if err != nil {
+		return ListOutput{}, err
+	}
+
+	items := make([]APIKeyItem, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, toAPIKeyItem(k))
+	}
+
+	return ListOutput{APIKeys: items}, nil
+}
+
+// Revoke revokes a key of the current user, it stops working with the next request
+func (u *UseCase) Revoke(ctx context.Context, input shared.UseCaseInput[RevokeInput]) error {
+	id, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return user.ErrInvalidUser
+	}
+
+	return u.repo.Revoke(ctx, id, input.Data.ID)
+}
+
+// Authenticate returns the key of the token when it is active and its user may log in
+func (u *UseCase) Authenticate(ctx context.Context, token, ip string) (*apikey.APIKey, error) {
+	if !strings.HasPrefix(token, apikey.TokenPrefix) {
+		return nil, apikey.ErrInvalidAPIKey
+	}
+
+	key, err := u.repo.FindByHash(ctx, u.hmacer.Sign(token))
+	if err != nil {
+		return nil, apikey.ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, apikey.ErrInvalidAPIKey
+	}
+
+	// Banned users lose their keys along with their sessions
+	owner, err := u.userRepo.FindByID(ctx, key.UserID)
+	if err != nil || owner.Status != user.Active {
+		return nil, apikey.ErrInvalidAPIKey
+	}
+
+	// A failed write only loses the usage record, the request still goes through
+	if key.NeedsTouch(now, ip, u.conf.TouchInterval) {
+		_ = u.repo.Touch(ctx, key.ID, now, ip)
+	}
+
+	return key, nil
+}
+
+// newToken returns a key of the prefix and 32 random bytes
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apikey.TokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func toAPIKeyItem(k *apikey.APIKey) APIKeyItem {
+	scopes := make([]string, 0, len(k.Scopes))
+	for _, s := range k.Scopes {
+		scopes = append(scopes, string(s))
+	}
+
+	item := APIKeyItem{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  timeutil.Format(k.ExpiresAt, timeutil.FormatISO),
+		LastUsedIP: k.LastUsedIP,
+		CreatedAt:  timeutil.Format(k.CreatedAt, timeutil.FormatISO),
+	}
+	if k.LastUsedAt != nil {
+		item.LastUsedAt = timeutil.Format(*k.LastUsedAt, timeutil.FormatISO)
+	}
+	return item
+}
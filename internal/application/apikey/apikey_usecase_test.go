@@ -0,0 +1,163 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func createInput(auth *shared.AuthContext, data CreateInput) shared.UseCaseInput[CreateInput] {
+	return shared.UseCaseInput[CreateInput]{Base: shared.BaseInput{Auth: auth}, Data: data}
+}
+
+func TestCreate_ReturnsKeyOnceAndStoresHash(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var stored *apikey.APIKey
+	repo := new(MockAPIKeyRepo)
+	repo.On("CountActive", mock.Anything, user.ID(1)).Return(0, nil)
+	repo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*apikey.APIKey)
+		stored.ID = 7
+	}).Return(nil)
+
+	uc := NewUseCase(repo, nil, stubHMACer{}, Config{DefaultTTL: time.Hour, MaxPerUser: 5})
+
+	out, err := uc.Create(ctx, createInput(&shared.AuthContext{UserID: "1"},
+		CreateInput{Name: " deploy bot ", Scopes: []string{"chat:write"}}))
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.Key, apikey.TokenPrefix))
+	assert.Equal(t, "hmac:"+out.Key, stored.Hash)
+	assert.Equal(t, "deploy bot", stored.Name)
+	assert.Equal(t, out.Key[:prefixLength], out.APIKey.Prefix)
+	assert.Equal(t, []string{"chat:write"}, out.APIKey.Scopes)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), stored.ExpiresAt, time.Minute)
+}
+
+func TestCreate_Rejects(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	session := &shared.AuthContext{UserID: "1"}
+	tests := []struct {
+		name  string
+		auth  *shared.AuthContext
+		data  CreateInput
+		count int
+		want  error
+	}{
+		{"api key auth", &shared.AuthContext{UserID: "1", APIKeyID: "3"}, CreateInput{Name: "a", Scopes: []string{"user:read"}}, 0, apikey.ErrKeyNotAllowed},
+		{"blank name", session, CreateInput{Name: " ", Scopes: []string{"user:read"}}, 0, apikey.ErrNameRequired},
+		{"no scopes", session, CreateInput{Name: "a"}, 0, apikey.ErrInvalidScope},
+		{"unknown scope", session, CreateInput{Name: "a", Scopes: []string{"root:write"}}, 0, apikey.ErrInvalidScope},
+		{"over max ttl", session, CreateInput{Name: "a", Scopes: []string{"user:read"}, ExpiresIn: 48 * time.Hour}, 0, apikey.ErrInvalidExpiry},
+		{"too many keys", session, CreateInput{Name: "a", Scopes: []string{"user:read"}}, 5, apikey.ErrTooManyKeys},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockAPIKeyRepo)
+			repo.On("CountActive", mock.Anything, user.ID(1)).Return(tt.count, nil)
+
+			uc := NewUseCase(repo, nil, stubHMACer{}, Config{DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour, MaxPerUser: 5})
+
+			_, err := uc.Create(ctx, createInput(tt.auth, tt.data))
+			assert.ErrorIs(t, err, tt.want)
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAuthenticate_RejectsInactiveKeys(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name string
+		key  *apikey.APIKey
+	}{
+		{"revoked", &apikey.APIKey{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &past}},
+		{"expired", &apikey.APIKey{ID: 1, UserID: 1, ExpiresAt: past}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockAPIKeyRepo)
+			repo.On("FindByHash", mock.Anything, "hmac:goat_k").Return(tt.key, nil)
+
+			uc := NewUseCase(repo, new(MockUserRepo), stubHMACer{}, Config{})
+
+			_, err := uc.Authenticate(ctx, "goat_k", "10.0.0.1")
+			assert.ErrorIs(t, err, apikey.ErrInvalidAPIKey)
+			repo.AssertNotCalled(t, "Touch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestAuthenticate_RejectsBannedOwner(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	repo := new(MockAPIKeyRepo)
+	repo.On("FindByHash", mock.Anything, "hmac:goat_k").
+		Return(&apikey.APIKey{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+	users := new(MockUserRepo)
+	users.On("FindByID", mock.Anything, user.ID(1)).Return(&user.User{ID: 1, Status: user.Banned}, nil)
+
+	uc := NewUseCase(repo, users, stubHMACer{}, Config{})
+
+	_, err := uc.Authenticate(ctx, "goat_k", "10.0.0.1")
+	assert.ErrorIs(t, err, apikey.ErrInvalidAPIKey)
+}
+
+func TestAuthenticate_ThrottlesTouch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	recent := time.Now().Add(-10 * time.Second)
+	tests := []struct {
+		name  string
+		ip    string
+		touch bool
+	}{
+		{"same ip within interval", "10.0.0.1", false},
+		{"new ip", "10.0.0.2", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := &apikey.APIKey{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour),
+				LastUsedAt: &recent, LastUsedIP: "10.0.0.1"}
+
+			repo := new(MockAPIKeyRepo)
+			repo.On("FindByHash", mock.Anything, "hmac:goat_k").Return(key, nil)
+			repo.On("Touch", mock.Anything, int64(1), mock.Anything, tt.ip).Return(nil)
+
+			users := new(MockUserRepo)
+			users.On("FindByID", mock.Anything, user.ID(1)).Return(&user.User{ID: 1, Status: user.Active}, nil)
+
+			uc := NewUseCase(repo, users, stubHMACer{}, Config{TouchInterval: time.Minute})
+
+			got, err := uc.Authenticate(ctx, "goat_k", tt.ip)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), got.ID)
+			if tt.touch {
+				repo.AssertCalled(t, "Touch", mock.Anything, int64(1), mock.Anything, tt.ip)
+			} else {
+				repo.AssertNotCalled(t, "Touch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
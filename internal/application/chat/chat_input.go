@@ -1,5 +1,7 @@
 package chat
 
+import "time"
+
 type GetMyGroupsInput struct{}
 
 type GetGroupMessagesInput struct {
@@ -7,3 +9,37 @@ type GetGroupMessagesInput struct {
 	BeforeID *int64
 	Limit    uint64
 }
+
+// CreateGroupInput opens a GROUP or CHANNEL chat owned by the current user.
+type CreateGroupInput struct {
+	Name        string
+	Description string
+	Type        string
+	MaxMembers  int
+}
+
+// SendMessageInput posts a text message of the current user to a group.
+type SendMessageInput struct {
+	GroupID   int64
+	Content   string
+	ReplyToID *int64
+}
+
+// PostAgentReplyInput is the answer of an agent runtime to a dispatched reply request.
+type PostAgentReplyInput struct {
+	TriggerID int64
+	AgentID   int64
+	Content   string
+
+	PromptTokens     int64
+	CompletionTokens int64
+	Latency          time.Duration
+}
+
+// UpdateResponsePolicyInput changes when an agent member of a group replies.
+type UpdateResponsePolicyInput struct {
+	GroupID       int64
+	ParticipantID int64
+	Policy        string
+	Keywords      []string
+}
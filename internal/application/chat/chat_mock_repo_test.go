@@ -0,0 +1,291 @@
+package chat
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/agentreply"
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/application/shared/notification"
+	"github.com/HiroLiang/goat-server/internal/application/shared/transaction"
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
+	"github.com/HiroLiang/goat-server/internal/domain/chatmember"
+	"github.com/HiroLiang/goat-server/internal/domain/chatmessage"
+	"github.com/HiroLiang/goat-server/internal/domain/participant"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockParticipantRepo struct {
+	mock.Mock
+}
+
+var _ participant.Repository = (*MockParticipantRepo)(nil)
+
+func (m *MockParticipantRepo) FindByID(ctx context.Context, id participant.ID) (*participant.Participant, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*participant.Participant), args.Error(1)
+}
+
+func (m *MockParticipantRepo) FindByUserID(ctx context.Context, userID user.ID) (*participant.Participant, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*participant.Participant), args.Error(1)
+}
+
+func (m *MockParticipantRepo) FindByAgentID(ctx context.Context, agentID agent.ID) (*participant.Participant, error) {
+	args := m.Called(ctx, agentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*participant.Participant), args.Error(1)
+}
+
+func (m *MockParticipantRepo) FindSystem(ctx context.Context) (*participant.Participant, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*participant.Participant), args.Error(1)
+}
+
+func (m *MockParticipantRepo) Create(ctx context.Context, p *participant.Participant) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+type MockChatGroupRepo struct {
+	mock.Mock
+}
+
+var _ chatgroup.Repository = (*MockChatGroupRepo)(nil)
+
+func (m *MockChatGroupRepo) FindByID(ctx context.Context, id chatgroup.ID) (*chatgroup.ChatGroup, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*chatgroup.ChatGroup), args.Error(1)
+}
+
+func (m *MockChatGroupRepo) FindByCreator(ctx context.Context, creatorID user.ID) ([]*chatgroup.ChatGroup, error) {
+	args := m.Called(ctx, creatorID)
+	return args.Get(0).([]*chatgroup.ChatGroup), args.Error(1)
+}
+
+func (m *MockChatGroupRepo) Create(ctx context.Context, group *chatgroup.ChatGroup) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockChatGroupRepo) Update(ctx context.Context, group *chatgroup.ChatGroup) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockChatGroupRepo) SoftDelete(ctx context.Context, id chatgroup.ID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockChatMemberRepo struct {
+	mock.Mock
+}
+
+var _ chatmember.Repository = (*MockChatMemberRepo)(nil)
+
+func (m *MockChatMemberRepo) FindByID(ctx context.Context, id chatmember.ID) (*chatmember.ChatMember, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*chatmember.ChatMember), args.Error(1)
+}
+
+func (m *MockChatMemberRepo) FindByGroupAndParticipant(
+	ctx context.Context,
+	groupID chatgroup.ID,
+	participantID participant.ID,
+) (*chatmember.ChatMember, error) {
+	args := m.Called(ctx, groupID, participantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*chatmember.ChatMember), args.Error(1)
+}
+
+func (m *MockChatMemberRepo) FindByGroup(ctx context.Context, groupID chatgroup.ID) ([]*chatmember.ChatMember, error) {
+	args := m.Called(ctx, groupID)
+	return args.Get(0).([]*chatmember.ChatMember), args.Error(1)
+}
+
+func (m *MockChatMemberRepo) FindByParticipant(ctx context.Context, participantID participant.ID) ([]*chatmember.ChatMember, error) {
+	args := m.Called(ctx, participantID)
+	return args.Get(0).([]*chatmember.ChatMember), args.Error(1)
+}
+
+func (m *MockChatMemberRepo) Add(ctx context.Context, member *chatmember.ChatMember) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+func (m *MockChatMemberRepo) Update(ctx context.Context, member *chatmember.ChatMember) error {
+	args := m.Called(ctx, member)
+	return args.Error(0)
+}
+
+func (m *MockChatMemberRepo) Remove(ctx context.Context, groupID chatgroup.ID, participantID participant.ID) error {
+	args := m.Called(ctx, groupID, participantID)
+	return args.Error(0)
+}
+
+type MockChatMessageRepo struct {
+	mock.Mock
+}
+
+var _ chatmessage.Repository = (*MockChatMessageRepo)(nil)
+
+func (m *MockChatMessageRepo) FindByID(ctx context.Context, id chatmessage.ID) (*chatmessage.ChatMessage, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*chatmessage.ChatMessage), args.Error(1)
+}
+
+func (m *MockChatMessageRepo) FindByGroup(
+	ctx context.Context,
+	groupID chatgroup.ID,
+	limit, offset uint64,
+) ([]*chatmessage.ChatMessage, error) {
+	args := m.Called(ctx, groupID, limit, offset)
+	return args.Get(0).([]*chatmessage.ChatMessage), args.Error(1)
+}
+
+func (m *MockChatMessageRepo) FindByGroupBefore(
+	ctx context.Context,
+	groupID chatgroup.ID,
+	beforeID chatmessage.ID,
+	limit uint64,
+) ([]*chatmessage.ChatMessage, error) {
+	args := m.Called(ctx, groupID, beforeID, limit)
+	return args.Get(0).([]*chatmessage.ChatMessage), args.Error(1)
+}
+
+func (m *MockChatMessageRepo) FindLatestByGroup(ctx context.Context, groupID chatgroup.ID) (*chatmessage.ChatMessage, error) {
+	args := m.Called(ctx, groupID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*chatmessage.ChatMessage), args.Error(1)
+}
+
+func (m *MockChatMessageRepo) CountByGroupAfter(ctx context.Context, groupID chatgroup.ID, since time.Time) (int64, error) {
+	args := m.Called(ctx, groupID, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockChatMessageRepo) FindBySender(ctx context.Context, senderID participant.ID) ([]*chatmessage.ChatMessage, error) {
+	args := m.Called(ctx, senderID)
+	return args.Get(0).([]*chatmessage.ChatMessage), args.Error(1)
+}
+
+func (m *MockChatMessageRepo) Create(ctx context.Context, message *chatmessage.ChatMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockChatMessageRepo) Update(ctx context.Context, message *chatmessage.ChatMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockChatMessageRepo) SoftDelete(ctx context.Context, id chatmessage.ID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockDispatcher struct {
+	mock.Mock
+}
+
+var _ agentreply.Dispatcher = (*MockDispatcher)(nil)
+
+func (m *MockDispatcher) Dispatch(ctx context.Context, request agentreply.Request) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+type MockMeter struct {
+	mock.Mock
+}
+
+var _ agentreply.Meter = (*MockMeter)(nil)
+
+func (m *MockMeter) CheckQuota(ctx context.Context, userID user.ID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockMeter) RecordUsage(ctx context.Context, usage agentreply.Usage) error {
+	args := m.Called(ctx, usage)
+	return args.Error(0)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+var _ notification.Notifier = (*MockNotifier)(nil)
+
+func (m *MockNotifier) NotifyChatMessage(ctx context.Context, msg notification.ChatMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+type MockPolicy struct {
+	mock.Mock
+}
+
+var _ auth.PolicyChecker = (*MockPolicy)(nil)
+
+func (m *MockPolicy) Permissions(ctx context.Context, userID user.ID) ([]permission.Permission, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]permission.Permission), args.Error(1)
+}
+
+func (m *MockPolicy) Authorize(ctx context.Context, userID user.ID, perm permission.Permission) error {
+	args := m.Called(ctx, userID, perm)
+	return args.Error(0)
+}
+
+type txKey struct{}
+
+// stubTxManager marks the context of the function as transactional and records
+// whether the transaction committed or rolled back
+type stubTxManager struct {
+	committed  bool
+	rolledBack bool
+}
+
+var _ transaction.Manager = (*stubTxManager)(nil)
+
+func (m *stubTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		m.rolledBack = true
+		return err
+	}
+	m.committed = true
+	return nil
+}
+
+// inTx matches a context passed through stubTxManager
+func inTx() any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Value(txKey{}) != nil
+	})
+}
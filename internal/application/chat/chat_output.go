@@ -42,3 +42,21 @@ type GetGroupMessagesOutput struct {
 	NextCursor *int64
 	HasMore    bool
 }
+
+// SendMessageOutput is the stored message, the participants it mentions and
+// the agents asked to reply to it. RefusedAgents would have replied but the
+// sender is over their agent quota.
+type SendMessageOutput struct {
+	Message         ChatMessageItem
+	Mentions        []int64
+	TriggeredAgents []int64
+	FailedAgents    []int64
+	RefusedAgents   []int64
+}
+
+type ResponsePolicyOutput struct {
+	GroupID       int64
+	ParticipantID int64
+	Policy        string
+	Keywords      []string
+}
@@ -3,37 +3,70 @@ package chat
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/agentreply"
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/application/shared/notification"
+	"github.com/HiroLiang/goat-server/internal/application/shared/transaction"
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentusage"
 	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
 	"github.com/HiroLiang/goat-server/internal/domain/chatmember"
 	"github.com/HiroLiang/goat-server/internal/domain/chatmessage"
 	"github.com/HiroLiang/goat-server/internal/domain/participant"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
 	"github.com/HiroLiang/goat-server/internal/domain/user"
 )
 
 const defaultLimit uint64 = 20
 const maxLimit uint64 = 50
+const maxContentLength = 4000
+const defaultMaxMembers = 100
 
 type UseCase struct {
 	participantRepo participant.Repository
 	chatGroupRepo   chatgroup.Repository
 	chatMemberRepo  chatmember.Repository
 	chatMessageRepo chatmessage.Repository
+	dispatcher      agentreply.Dispatcher
+	meter           agentreply.Meter
+	notifier        notification.Notifier
+	txManager       transaction.Manager
+	policy          auth.PolicyChecker
+	maxAgentDepth   int
 }
 
+// NewUseCase creates the chat use case. maxAgentDepth bounds how many agent replies
+// may chain after one human message; agents always answer humans, and 0 or 1
+// disables agent-to-agent replies. meter keeps the
+// replies within the quota of the user whose message started them.
 func NewUseCase(
 	participantRepo participant.Repository,
 	chatGroupRepo chatgroup.Repository,
 	chatMemberRepo chatmember.Repository,
 	chatMessageRepo chatmessage.Repository,
+	dispatcher agentreply.Dispatcher,
+	meter agentreply.Meter,
+	notifier notification.Notifier,
+	txManager transaction.Manager,
+	policy auth.PolicyChecker,
+	maxAgentDepth int,
 ) *UseCase {
 	return &UseCase{
 		participantRepo: participantRepo,
 		chatGroupRepo:   chatGroupRepo,
 		chatMemberRepo:  chatMemberRepo,
 		chatMessageRepo: chatMessageRepo,
+		dispatcher:      dispatcher,
+		meter:           meter,
+		notifier:        notifier,
+		txManager:       txManager,
+		policy:          policy,
+		maxAgentDepth:   maxAgentDepth,
 	}
 }
 
@@ -122,6 +155,69 @@ func (u *UseCase) GetMyGroups(
 	return GetMyGroupsOutput{Groups: items}, nil
 }
 
+// CreateGroup opens a GROUP or CHANNEL chat with the current user as its owner.
+// Opening a CHANNEL requires the chat:create_channel permission.
+func (u *UseCase) CreateGroup(
+	ctx context.Context,
+	input shared.UseCaseInput[CreateGroupInput],
+) (ChatGroupItem, error) {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return ChatGroupItem{}, user.ErrInvalidUser
+	}
+
+	maxMembers := input.Data.MaxMembers
+	if maxMembers <= 0 {
+		maxMembers = defaultMaxMembers
+	}
+	name := strings.TrimSpace(input.Data.Name)
+	if name == "" || maxMembers < 2 {
+		return ChatGroupItem{}, chatgroup.ErrInvalid
+	}
+
+	var group *chatgroup.ChatGroup
+	switch chatgroup.GroupType(input.Data.Type) {
+	case chatgroup.Group:
+		group = chatgroup.NewGroup(name, input.Data.Description, maxMembers, userID)
+	case chatgroup.Channel:
+		err := u.policy.Authorize(ctx, userID, permission.ChatCreateChannel)
+		if errors.Is(err, permission.ErrDenied) {
+			return ChatGroupItem{}, chatgroup.ErrForbidden
+		}
+		if err != nil {
+			return ChatGroupItem{}, err
+		}
+		group = chatgroup.NewChannel(name, input.Data.Description, maxMembers, userID)
+	default:
+		return ChatGroupItem{}, chatgroup.ErrInvalid
+	}
+
+	owner, err := u.participantRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return ChatGroupItem{}, err
+	}
+
+	// A group without its owner can't be managed, so both rows are stored or neither
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.chatGroupRepo.Create(ctx, group); err != nil {
+			return err
+		}
+		return u.chatMemberRepo.Add(ctx, chatmember.NewChatMember(group.ID, owner.ID, chatmember.Owner))
+	})
+	if err != nil {
+		return ChatGroupItem{}, err
+	}
+
+	return ChatGroupItem{
+		ID:          int64(group.ID),
+		Type:        string(group.Type),
+		Name:        group.Name,
+		Description: group.Description,
+		AvatarURL:   group.AvatarURL,
+		MemberCount: 1,
+	}, nil
+}
+
 // GetGroupMessages returns paginated messages for a group using cursor-based pagination.
 func (u *UseCase) GetGroupMessages(
 	ctx context.Context,
@@ -241,3 +337,338 @@ func (u *UseCase) GetGroupMessages(
 		HasMore:    hasMore,
 	}, nil
 }
+
+// SendMessage stores a text message of the current user and asks the agent members
+// whose response policy matches to reply.
+func (u *UseCase) SendMessage(
+	ctx context.Context,
+	input shared.UseCaseInput[SendMessageInput],
+) (SendMessageOutput, error) {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return SendMessageOutput{}, user.ErrInvalidUser
+	}
+
+	if err := validateContent(input.Data.Content); err != nil {
+		return SendMessageOutput{}, err
+	}
+
+	group, err := u.activeGroup(ctx, chatgroup.ID(input.Data.GroupID))
+	if err != nil {
+		return SendMessageOutput{}, err
+	}
+
+	sender, err := u.participantRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return SendMessageOutput{}, chatgroup.ErrForbidden
+	}
+	if _, err := u.chatMemberRepo.FindByGroupAndParticipant(ctx, group.ID, sender.ID); err != nil {
+		return SendMessageOutput{}, chatgroup.ErrForbidden
+	}
+
+	msg := chatmessage.NewTextMessage(group.ID, sender.ID, input.Data.Content)
+	if input.Data.ReplyToID != nil {
+		replyTo := chatmessage.ID(*input.Data.ReplyToID)
+		msg.ReplyToID = &replyTo
+	}
+
+	return u.postMessage(ctx, group, msg, sender, userID)
+}
+
+// PostAgentReply stores the answer of an agent to a dispatched message and meters it
+// against the user whose message started the chain, found by walking back from the trigger. The reply is routed to the other agents
+// again as long as the agent depth allows it.
+func (u *UseCase) PostAgentReply(
+	ctx context.Context,
+	input shared.UseCaseInput[PostAgentReplyInput],
+) (SendMessageOutput, error) {
+	if err := validateContent(input.Data.Content); err != nil {
+		return SendMessageOutput{}, err
+	}
+
+	trigger, err := u.chatMessageRepo.FindByID(ctx, chatmessage.ID(input.Data.TriggerID))
+	if err != nil {
+		return SendMessageOutput{}, err
+	}
+
+	group, err := u.activeGroup(ctx, trigger.GroupID)
+	if err != nil {
+		return SendMessageOutput{}, err
+	}
+
+	sender, err := u.participantRepo.FindByAgentID(ctx, agent.ID(input.Data.AgentID))
+	if err != nil {
+		return SendMessageOutput{}, err
+	}
+	if _, err := u.chatMemberRepo.FindByGroupAndParticipant(ctx, trigger.GroupID, sender.ID); err != nil {
+		return SendMessageOutput{}, chatgroup.ErrForbidden
+	}
+
+	requestedBy, err := u.requesterOf(ctx, trigger)
+	if err != nil {
+		return SendMessageOutput{}, err
+	}
+
+	// The answer has been produced already, so it is metered even when it pushes the user over the quota
+	if err := u.meter.RecordUsage(ctx, agentreply.Usage{
+		UserID:           requestedBy,
+		AgentID:          agent.ID(input.Data.AgentID),
+		PromptTokens:     input.Data.PromptTokens,
+		CompletionTokens: input.Data.CompletionTokens,
+		Latency:          input.Data.Latency,
+	}); err != nil {
+		return SendMessageOutput{}, err
+	}
+
+	reply := chatmessage.NewAgentReply(trigger, sender.ID, input.Data.Content)
+	return u.postMessage(ctx, group, reply, sender, requestedBy)
+}
+
+// UpdateResponsePolicy changes when an agent member replies. Only group owners and admins may change it.
+func (u *UseCase) UpdateResponsePolicy(
+	ctx context.Context,
+	input shared.UseCaseInput[UpdateResponsePolicyInput],
+) (ResponsePolicyOutput, error) {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return ResponsePolicyOutput{}, user.ErrInvalidUser
+	}
+
+	group, err := u.activeGroup(ctx, chatgroup.ID(input.Data.GroupID))
+	if err != nil {
+		return ResponsePolicyOutput{}, err
+	}
+
+	caller, err := u.participantRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return ResponsePolicyOutput{}, chatgroup.ErrForbidden
+	}
+	callerMember, err := u.chatMemberRepo.FindByGroupAndParticipant(ctx, group.ID, caller.ID)
+	if err != nil {
+		return ResponsePolicyOutput{}, chatgroup.ErrForbidden
+	}
+	if !callerMember.CanManageMembers() {
+		return ResponsePolicyOutput{}, chatmember.ErrForbidden
+	}
+
+	target, err := u.participantRepo.FindByID(ctx, participant.ID(input.Data.ParticipantID))
+	if err != nil {
+		return ResponsePolicyOutput{}, err
+	}
+	if !target.IsAgent() {
+		return ResponsePolicyOutput{}, chatmember.ErrInvalidPolicy
+	}
+
+	member, err := u.chatMemberRepo.FindByGroupAndParticipant(ctx, group.ID, target.ID)
+	if err != nil {
+		return ResponsePolicyOutput{}, err
+	}
+
+	if err := member.SetResponsePolicy(chatmember.ResponsePolicy(input.Data.Policy), input.Data.Keywords); err != nil {
+		return ResponsePolicyOutput{}, err
+	}
+
+	if err := u.chatMemberRepo.Update(ctx, member); err != nil {
+		return ResponsePolicyOutput{}, err
+	}
+
+	return ResponsePolicyOutput{
+		GroupID:       int64(member.GroupID),
+		ParticipantID: int64(member.ParticipantID),
+		Policy:        string(member.ResponsePolicy),
+		Keywords:      member.Keywords,
+	}, nil
+}
+
+// requesterOf follows the agent replies back from msg to the human message that
+// started the chain and returns its sender.
+func (u *UseCase) requesterOf(ctx context.Context, msg *chatmessage.ChatMessage) (user.ID, error) {
+	for msg.AgentDepth > 0 {
+		if msg.ReplyToID == nil {
+			return 0, chatmessage.ErrNoRequester
+		}
+		parent, err := u.chatMessageRepo.FindByID(ctx, *msg.ReplyToID)
+		if err != nil {
+			return 0, err
+		}
+		if parent.AgentDepth >= msg.AgentDepth {
+			return 0, chatmessage.ErrNoRequester
+		}
+		msg = parent
+	}
+
+	sender, err := u.participantRepo.FindByID(ctx, msg.SenderID)
+	if err != nil {
+		return 0, err
+	}
+	if !sender.IsUser() {
+		return 0, chatmessage.ErrNoRequester
+	}
+	return *sender.UserID, nil
+}
+
+// postMessage stores msg, notifies the user members and dispatches it to the agent
+// members that should reply, as long as requestedBy is within the agent quota.
+func (u *UseCase) postMessage(
+	ctx context.Context,
+	group *chatgroup.ChatGroup,
+	msg *chatmessage.ChatMessage,
+	sender *participant.Participant,
+	requestedBy user.ID,
+) (SendMessageOutput, error) {
+	members, err := u.chatMemberRepo.FindByGroup(ctx, msg.GroupID)
+	if err != nil {
+		return SendMessageOutput{}, err
+	}
+
+	participants := make([]*participant.Participant, 0, len(members))
+	memberOf := make(map[participant.ID]*chatmember.ChatMember, len(members))
+	for _, m := range members {
+		p, err := u.participantRepo.FindByID(ctx, m.ParticipantID)
+		if err != nil {
+			continue
+		}
+		participants = append(participants, p)
+		memberOf[p.ID] = m
+	}
+
+	if err := u.chatMessageRepo.Create(ctx, msg); err != nil {
+		return SendMessageOutput{}, err
+	}
+
+	// A failed push does not fail the message, it is stored already
+	_ = u.notifier.NotifyChatMessage(ctx, chatNotification(group, msg, sender, participants, memberOf))
+
+	mentions := chatmessage.ResolveMentions(msg.Content, participants)
+	mentioned := make(map[participant.ID]bool, len(mentions))
+	output := SendMessageOutput{
+		Message:         toMessageItem(msg, sender),
+		Mentions:        make([]int64, 0, len(mentions)),
+		TriggeredAgents: []int64{},
+		FailedAgents:    []int64{},
+		RefusedAgents:   []int64{},
+	}
+	for _, id := range mentions {
+		mentioned[id] = true
+		output.Mentions = append(output.Mentions, int64(id))
+	}
+
+	// Loop guard: stop once the agent chain of this human message is deep enough
+	if !msg.AllowsAgentReply(u.maxAgentDepth) {
+		return output, nil
+	}
+
+	var quotaErr error
+	quotaChecked := false
+	for _, p := range participants {
+		if !p.IsAgent() || p.ID == msg.SenderID {
+			continue
+		}
+		if !memberOf[p.ID].ShouldRespond(msg.Content, mentioned[p.ID]) {
+			continue
+		}
+
+		// The quota is checked once, and only when some agent is about to reply
+		if !quotaChecked {
+			quotaErr = u.meter.CheckQuota(ctx, requestedBy)
+			quotaChecked = true
+		}
+		if errors.Is(quotaErr, agentusage.ErrQuotaExceeded) {
+			output.RefusedAgents = append(output.RefusedAgents, int64(p.ID))
+			continue
+		}
+		if quotaErr != nil {
+			output.FailedAgents = append(output.FailedAgents, int64(p.ID))
+			continue
+		}
+
+		err := u.dispatcher.Dispatch(ctx, agentreply.Request{
+			GroupID:       msg.GroupID,
+			TriggerID:     msg.ID,
+			AgentID:       *p.AgentID,
+			ParticipantID: p.ID,
+			Depth:         msg.AgentDepth,
+			UserID:        requestedBy,
+		})
+		if err != nil {
+			output.FailedAgents = append(output.FailedAgents, int64(p.ID))
+			continue
+		}
+		output.TriggeredAgents = append(output.TriggeredAgents, int64(p.ID))
+	}
+
+	return output, nil
+}
+
+func (u *UseCase) activeGroup(ctx context.Context, groupID chatgroup.ID) (*chatgroup.ChatGroup, error) {
+	group, err := u.chatGroupRepo.FindByID(ctx, groupID)
+	if err != nil {
+		return nil, chatgroup.ErrNotFound
+	}
+	if group.IsDeleted {
+		return nil, chatgroup.ErrDeleted
+	}
+	return group, nil
+}
+
+// chatNotification addresses msg to the user members of the group other than the sender
+func chatNotification(
+	group *chatgroup.ChatGroup,
+	msg *chatmessage.ChatMessage,
+	sender *participant.Participant,
+	participants []*participant.Participant,
+	memberOf map[participant.ID]*chatmember.ChatMember,
+) notification.ChatMessage {
+	recipients := make([]notification.Recipient, 0, len(participants))
+	for _, p := range participants {
+		if !p.IsUser() || p.UserID == nil || p.ID == msg.SenderID {
+			continue
+		}
+		recipients = append(recipients, notification.Recipient{
+			UserID: *p.UserID,
+			Muted:  memberOf[p.ID].IsMuted,
+		})
+	}
+
+	return notification.ChatMessage{
+		GroupID:    group.ID,
+		GroupName:  group.Name,
+		MessageID:  msg.ID,
+		SenderName: sender.DisplayName,
+		Content:    msg.Content,
+		Recipients: recipients,
+	}
+}
+
+func validateContent(content string) error {
+	if strings.TrimSpace(content) == "" {
+		return chatmessage.ErrEmpty
+	}
+	if utf8.RuneCountInString(content) > maxContentLength {
+		return chatmessage.ErrTooLong
+	}
+	return nil
+}
+
+// toMessageItem converts a message posted by sender, as seen by the sender.
+func toMessageItem(msg *chatmessage.ChatMessage, sender *participant.Participant) ChatMessageItem {
+	var replyToID *int64
+	if msg.ReplyToID != nil {
+		v := int64(*msg.ReplyToID)
+		replyToID = &v
+	}
+
+	return ChatMessageItem{
+		ID:           int64(msg.ID),
+		ChatID:       int64(msg.GroupID),
+		SenderID:     int64(msg.SenderID),
+		SenderName:   sender.DisplayName,
+		SenderAvatar: sender.AvatarURL,
+		Content:      msg.Content,
+		Type:         msg.Type,
+		ReplyToID:    replyToID,
+		IsEdited:     msg.IsEdited,
+		IsMe:         true,
+		Timestamp:    msg.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
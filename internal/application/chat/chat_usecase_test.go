@@ -0,0 +1,389 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/agentreply"
+	"github.com/HiroLiang/goat-server/internal/application/shared/notification"
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentusage"
+	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
+	"github.com/HiroLiang/goat-server/internal/domain/chatmember"
+	"github.com/HiroLiang/goat-server/internal/domain/chatmessage"
+	"github.com/HiroLiang/goat-server/internal/domain/participant"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testGroupID chatgroup.ID = 10
+
+// groupFixture is a GROUP chat of one user (participant 1) and three agents:
+// a mention-only coder (2), a keyword reviewer (3) and an always-on bot (4).
+type groupFixture struct {
+	participants *MockParticipantRepo
+	groups       *MockChatGroupRepo
+	members      *MockChatMemberRepo
+	messages     *MockChatMessageRepo
+	dispatcher   *MockDispatcher
+	meter        *MockMeter
+	notifier     *MockNotifier
+	tx           *stubTxManager
+	policy       *MockPolicy
+}
+
+func newGroupFixture() *groupFixture {
+	f := &groupFixture{
+		participants: new(MockParticipantRepo),
+		groups:       new(MockChatGroupRepo),
+		members:      new(MockChatMemberRepo),
+		messages:     new(MockChatMessageRepo),
+		dispatcher:   new(MockDispatcher),
+		meter:        new(MockMeter),
+		notifier:     new(MockNotifier),
+		tx:           new(stubTxManager),
+		policy:       new(MockPolicy),
+	}
+
+	human := participant.NewUserParticipant(user.ID(100), "Hiro", "")
+	human.ID = 1
+	coder := participant.NewAgentParticipant(agent.ID(20), "Coder", "")
+	coder.ID = 2
+	reviewer := participant.NewAgentParticipant(agent.ID(30), "Code Reviewer", "")
+	reviewer.ID = 3
+	bot := participant.NewAgentParticipant(agent.ID(40), "Helper", "")
+	bot.ID = 4
+
+	members := []*chatmember.ChatMember{
+		{GroupID: testGroupID, ParticipantID: 1, Role: chatmember.Owner},
+		{GroupID: testGroupID, ParticipantID: 2, ResponsePolicy: chatmember.RespondOnMention},
+		{GroupID: testGroupID, ParticipantID: 3, ResponsePolicy: chatmember.RespondOnKeyword, Keywords: []string{"review"}},
+		{GroupID: testGroupID, ParticipantID: 4, ResponsePolicy: chatmember.RespondAlways},
+	}
+
+	f.groups.On("FindByID", mock.Anything, testGroupID).
+		Return(&chatgroup.ChatGroup{ID: testGroupID, Type: chatgroup.Group}, nil)
+	f.participants.On("FindByUserID", mock.Anything, user.ID(100)).Return(human, nil)
+	for _, p := range []*participant.Participant{human, coder, reviewer, bot} {
+		f.participants.On("FindByID", mock.Anything, p.ID).Return(p, nil)
+		if p.IsAgent() {
+			f.participants.On("FindByAgentID", mock.Anything, *p.AgentID).Return(p, nil)
+		}
+	}
+	for _, m := range members {
+		f.members.On("FindByGroupAndParticipant", mock.Anything, testGroupID, m.ParticipantID).Return(m, nil)
+	}
+	f.members.On("FindByGroup", mock.Anything, testGroupID).Return(members, nil)
+	f.messages.On("Create", mock.Anything, mock.AnythingOfType("*chatmessage.ChatMessage")).
+		Run(func(args mock.Arguments) { args.Get(1).(*chatmessage.ChatMessage).ID = 99 }).
+		Return(nil)
+	f.dispatcher.On("Dispatch", mock.Anything, mock.Anything).Return(nil)
+	f.meter.On("CheckQuota", mock.Anything, mock.Anything).Return(nil)
+	f.meter.On("RecordUsage", mock.Anything, mock.Anything).Return(nil)
+	f.notifier.On("NotifyChatMessage", mock.Anything, mock.Anything).Return(nil)
+
+	return f
+}
+
+func (f *groupFixture) useCase(maxAgentDepth int) *UseCase {
+	return NewUseCase(f.participants, f.groups, f.members, f.messages, f.dispatcher, f.meter, f.notifier, f.tx, f.policy, maxAgentDepth)
+}
+
+func sendInput(content string) shared.UseCaseInput[SendMessageInput] {
+	return shared.UseCaseInput[SendMessageInput]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "100"}},
+		Data: SendMessageInput{GroupID: int64(testGroupID), Content: content},
+	}
+}
+
+func TestSendMessage_RoutesByResponsePolicy(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+
+	output, err := f.useCase(3).SendMessage(ctx, sendInput("hello everyone"))
+
+	assert.NoError(t, err)
+	assert.Empty(t, output.Mentions)
+	assert.Equal(t, []int64{4}, output.TriggeredAgents)
+}
+
+func TestSendMessage_MentionAndKeywordTriggerAgents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+
+	output, err := f.useCase(3).SendMessage(ctx, sendInput("@coder please fix it, then ask for a review. mail me at hiro@example.com"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2}, output.Mentions)
+	assert.ElementsMatch(t, []int64{2, 3, 4}, output.TriggeredAgents)
+	f.dispatcher.AssertCalled(t, "Dispatch", mock.Anything, agentreply.Request{
+		GroupID:       testGroupID,
+		TriggerID:     99,
+		AgentID:       20,
+		ParticipantID: 2,
+		Depth:         0,
+		UserID:        100,
+	})
+}
+
+func TestSendMessage_OverQuotaAgentIsRefused(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+	f.meter = new(MockMeter)
+	f.meter.On("CheckQuota", mock.Anything, user.ID(100)).
+		Return(&agentusage.QuotaExceededError{Kind: "requests", Limit: 10, Used: 10})
+
+	output, err := f.useCase(3).SendMessage(ctx, sendInput("hello everyone"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(99), output.Message.ID)
+	assert.Empty(t, output.TriggeredAgents)
+	assert.Equal(t, []int64{4}, output.RefusedAgents)
+	f.dispatcher.AssertNotCalled(t, "Dispatch", mock.Anything, mock.Anything)
+}
+
+func TestPostAgentReply_RecordsUsageOfRequester(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+	trigger := &chatmessage.ChatMessage{ID: 50, GroupID: testGroupID, SenderID: 1}
+	f.messages.On("FindByID", mock.Anything, chatmessage.ID(50)).Return(trigger, nil)
+
+	_, err := f.useCase(3).PostAgentReply(ctx, shared.UseCaseInput[PostAgentReplyInput]{
+		Data: PostAgentReplyInput{
+			TriggerID:        50,
+			AgentID:          40,
+			Content:          "done",
+			PromptTokens:     120,
+			CompletionTokens: 30,
+			Latency:          time.Second,
+		},
+	})
+
+	assert.NoError(t, err)
+	f.meter.AssertCalled(t, "RecordUsage", mock.Anything, agentreply.Usage{
+		UserID:           100,
+		AgentID:          40,
+		PromptTokens:     120,
+		CompletionTokens: 30,
+		Latency:          time.Second,
+	})
+}
+
+func TestSendMessage_ZeroMaxDepthStillAnswersHumans(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+
+	output, err := f.useCase(0).SendMessage(ctx, sendInput("hello everyone"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{4}, output.TriggeredAgents)
+}
+
+func TestPostAgentReply_ZeroMaxDepthDoesNotChainAgents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+	trigger := &chatmessage.ChatMessage{ID: 50, GroupID: testGroupID, SenderID: 1}
+	f.messages.On("FindByID", mock.Anything, chatmessage.ID(50)).Return(trigger, nil)
+
+	output, err := f.useCase(0).PostAgentReply(ctx, shared.UseCaseInput[PostAgentReplyInput]{
+		Data: PostAgentReplyInput{TriggerID: 50, AgentID: 20, Content: "@helper what do you think?"},
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, output.TriggeredAgents)
+	f.dispatcher.AssertNotCalled(t, "Dispatch", mock.Anything, mock.Anything)
+}
+
+func TestPostAgentReply_StopsAtMaxDepth(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+	question := &chatmessage.ChatMessage{ID: 49, GroupID: testGroupID, SenderID: 1}
+	replyTo := question.ID
+	trigger := &chatmessage.ChatMessage{ID: 50, GroupID: testGroupID, SenderID: 4, ReplyToID: &replyTo, AgentDepth: 1}
+	f.messages.On("FindByID", mock.Anything, chatmessage.ID(49)).Return(question, nil)
+	f.messages.On("FindByID", mock.Anything, chatmessage.ID(50)).Return(trigger, nil)
+
+	output, err := f.useCase(2).PostAgentReply(ctx, shared.UseCaseInput[PostAgentReplyInput]{
+		Data: PostAgentReplyInput{TriggerID: 50, AgentID: 20, Content: "@helper what do you think?"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{4}, output.Mentions)
+	assert.Empty(t, output.TriggeredAgents)
+	f.messages.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(m *chatmessage.ChatMessage) bool {
+		return m.AgentDepth == 2 && *m.ReplyToID == 50 && m.SenderID == 2
+	}))
+	f.dispatcher.AssertNotCalled(t, "Dispatch", mock.Anything, mock.Anything)
+	f.meter.AssertCalled(t, "RecordUsage", mock.Anything, mock.MatchedBy(func(u agentreply.Usage) bool {
+		return u.UserID == 100
+	}))
+}
+
+func TestPostAgentReply_RefusesChainWithoutUser(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+	trigger := &chatmessage.ChatMessage{ID: 50, GroupID: testGroupID, SenderID: 4, AgentDepth: 1}
+	f.messages.On("FindByID", mock.Anything, chatmessage.ID(50)).Return(trigger, nil)
+
+	_, err := f.useCase(3).PostAgentReply(ctx, shared.UseCaseInput[PostAgentReplyInput]{
+		Data: PostAgentReplyInput{TriggerID: 50, AgentID: 20, Content: "done"},
+	})
+
+	assert.ErrorIs(t, err, chatmessage.ErrNoRequester)
+	f.meter.AssertNotCalled(t, "RecordUsage", mock.Anything, mock.Anything)
+	f.messages.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPostAgentReply_AgentDoesNotAnswerItself(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+	trigger := &chatmessage.ChatMessage{ID: 50, GroupID: testGroupID, SenderID: 1}
+	f.messages.On("FindByID", mock.Anything, chatmessage.ID(50)).Return(trigger, nil)
+
+	output, err := f.useCase(3).PostAgentReply(ctx, shared.UseCaseInput[PostAgentReplyInput]{
+		Data: PostAgentReplyInput{TriggerID: 50, AgentID: 40, Content: "done"},
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, output.TriggeredAgents)
+}
+
+func TestUpdateResponsePolicy_KeywordRequiresKeywords(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+
+	_, err := f.useCase(3).UpdateResponsePolicy(ctx, shared.UseCaseInput[UpdateResponsePolicyInput]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "100"}},
+		Data: UpdateResponsePolicyInput{GroupID: int64(testGroupID), ParticipantID: 2, Policy: "KEYWORD", Keywords: []string{" "}},
+	})
+
+	assert.ErrorIs(t, err, chatmember.ErrInvalidPolicy)
+	f.members.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestPostAgentReply_NotifiesUserMembers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+	trigger := &chatmessage.ChatMessage{ID: 50, GroupID: testGroupID, SenderID: 1}
+	f.messages.On("FindByID", mock.Anything, chatmessage.ID(50)).Return(trigger, nil)
+
+	_, err := f.useCase(3).PostAgentReply(ctx, shared.UseCaseInput[PostAgentReplyInput]{
+		Data: PostAgentReplyInput{TriggerID: 50, AgentID: 40, Content: "done"},
+	})
+
+	assert.NoError(t, err)
+	f.notifier.AssertCalled(t, "NotifyChatMessage", mock.Anything, mock.MatchedBy(func(n notification.ChatMessage) bool {
+		return n.SenderName == "Helper" && n.MessageID == 99 &&
+			len(n.Recipients) == 1 && n.Recipients[0].UserID == 100
+	}))
+}
+
+func TestSendMessage_DoesNotNotifySender(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+
+	_, err := f.useCase(3).SendMessage(ctx, sendInput("hello"))
+
+	assert.NoError(t, err)
+	f.notifier.AssertCalled(t, "NotifyChatMessage", mock.Anything, mock.MatchedBy(func(n notification.ChatMessage) bool {
+		return len(n.Recipients) == 0
+	}))
+}
+
+func createInput(groupType chatgroup.GroupType) shared.UseCaseInput[CreateGroupInput] {
+	return shared.UseCaseInput[CreateGroupInput]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "100"}},
+		Data: CreateGroupInput{Name: "Team", Type: string(groupType)},
+	}
+}
+
+func TestCreateGroup_AddsOwnerInTransaction(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+	f.groups.On("Create", inTx(), mock.AnythingOfType("*chatgroup.ChatGroup")).
+		Run(func(args mock.Arguments) { args.Get(1).(*chatgroup.ChatGroup).ID = 11 }).
+		Return(nil)
+	f.members.On("Add", inTx(), mock.AnythingOfType("*chatmember.ChatMember")).Return(nil)
+
+	output, err := f.useCase(3).CreateGroup(ctx, createInput(chatgroup.Group))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), output.ID)
+	assert.Equal(t, defaultMaxMembers, f.groups.Calls[len(f.groups.Calls)-1].Arguments.Get(1).(*chatgroup.ChatGroup).MaxMembers)
+	assert.True(t, f.tx.committed)
+
+	owner := f.members.Calls[len(f.members.Calls)-1].Arguments.Get(1).(*chatmember.ChatMember)
+	assert.Equal(t, chatgroup.ID(11), owner.GroupID)
+	assert.Equal(t, participant.ID(1), owner.ParticipantID)
+	assert.Equal(t, chatmember.Owner, owner.Role)
+	f.policy.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateGroup_RollsBackWhenOwnerFails(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+	failed := errors.New("insert chat member: constraint failed")
+	f.groups.On("Create", inTx(), mock.Anything).Return(nil)
+	f.members.On("Add", inTx(), mock.Anything).Return(failed)
+
+	_, err := f.useCase(3).CreateGroup(ctx, createInput(chatgroup.Group))
+	assert.ErrorIs(t, err, failed)
+	assert.True(t, f.tx.rolledBack)
+	assert.False(t, f.tx.committed)
+	f.groups.AssertCalled(t, "Create", inTx(), mock.Anything)
+}
+
+func TestCreateGroup_ChannelRequiresPermission(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+	f.policy.On("Authorize", mock.Anything, user.ID(100), permission.ChatCreateChannel).Return(permission.ErrDenied)
+
+	_, err := f.useCase(3).CreateGroup(ctx, createInput(chatgroup.Channel))
+	assert.ErrorIs(t, err, chatgroup.ErrForbidden)
+	f.groups.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateGroup_RejectsInvalidType(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	f := newGroupFixture()
+
+	_, err := f.useCase(3).CreateGroup(ctx, createInput(chatgroup.Direct))
+	assert.ErrorIs(t, err, chatgroup.ErrInvalid)
+	f.groups.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,21 @@
+package device
+
+import "github.com/HiroLiang/goat-server/internal/domain/device"
+
+// RegisterInput DeviceID is chosen by the client and stays the same across logins,
+// PushToken is empty when the device takes no push notifications
+type RegisterInput struct {
+	DeviceID  string
+	Name      string
+	Platform  string
+	PushToken string
+}
+
+type RenameInput struct {
+	ID   device.ID
+	Name string
+}
+
+type RemoveInput struct {
+	ID device.ID
+}
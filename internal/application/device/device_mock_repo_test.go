@@ -0,0 +1,98 @@
+package device
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	session "github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDeviceRepo struct {
+	mock.Mock
+}
+
+var _ device.Repository = (*MockDeviceRepo)(nil)
+
+func (m *MockDeviceRepo) Register(ctx context.Context, d *device.Device) error {
+	args := m.Called(ctx, d)
+	return args.Error(0)
+}
+
+func (m *MockDeviceRepo) Find(ctx context.Context, userID user.ID, id device.ID) (*device.Device, error) {
+	args := m.Called(ctx, userID, id)
+	d, _ := args.Get(0).(*device.Device)
+	return d, args.Error(1)
+}
+
+func (m *MockDeviceRepo) FindByID(ctx context.Context, id device.ID) (*device.Device, error) {
+	args := m.Called(ctx, id)
+	d, _ := args.Get(0).(*device.Device)
+	return d, args.Error(1)
+}
+
+func (m *MockDeviceRepo) FindBySecretHash(ctx context.Context, hash string) (*device.Device, error) {
+	args := m.Called(ctx, hash)
+	d, _ := args.Get(0).(*device.Device)
+	return d, args.Error(1)
+}
+
+func (m *MockDeviceRepo) FindByUser(ctx context.Context, userID user.ID) ([]*device.Device, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*device.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepo) FindPushTargets(ctx context.Context, userIDs []user.ID) ([]*device.Device, error) {
+	args := m.Called(ctx, userIDs)
+	return args.Get(0).([]*device.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepo) ClearPushToken(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockDeviceRepo) Rename(ctx context.Context, userID user.ID, id device.ID, name string) error {
+	args := m.Called(ctx, userID, id, name)
+	return args.Error(0)
+}
+
+func (m *MockDeviceRepo) Delete(ctx context.Context, userID user.ID, id device.ID) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+type MockUserRepo struct {
+	user.Repository
+	mock.Mock
+}
+
+func (m *MockUserRepo) FindByID(ctx context.Context, id user.ID) (*user.User, error) {
+	args := m.Called(ctx, id)
+	u, _ := args.Get(0).(*user.User)
+	return u, args.Error(1)
+}
+
+// stubHMACer signs by tagging the message, enough to tell the stored hash from the secret
+type stubHMACer struct{}
+
+func (stubHMACer) Sign(message string) string { return "hmac:" + message }
+
+func (stubHMACer) Verify(message, signature string) bool { return signature == "hmac:"+message }
+
+type MockTokenService struct {
+	auth.TokenService
+	mock.Mock
+}
+
+func (m *MockTokenService) ListUserSessions(ctx context.Context, userID string) ([]*session.Session, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*session.Session), args.Error(1)
+}
+
+func (m *MockTokenService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
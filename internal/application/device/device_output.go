@@ -0,0 +1,27 @@
+package device
+
+import "github.com/HiroLiang/goat-server/internal/domain/device"
+
+// RegisterOutput Secret is only set for headless devices, it cannot be shown again
+type RegisterOutput struct {
+	Secret string
+	Device DeviceItem
+}
+
+// DeviceItem Current is set for the device of the requesting session,
+// Headless for devices that connect with a secret and take commands
+type DeviceItem struct {
+	ID        device.ID
+	DeviceID  string
+	Name      string
+	Platform  string
+	Push      bool
+	Headless  bool
+	Current   bool
+	CreatedAt string
+	UpdatedAt string
+}
+
+type ListOutput struct {
+	Devices []DeviceItem
+}
@@ -0,0 +1,219 @@
+package device
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/application/shared/security"
+	session "github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+)
+
+// UseCase keeps the registry of the devices of a user. A device is bound to the
+// session that registered it, and to later sessions that log in with its DeviceID.
+// Headless devices have no sessions; they connect with a secret issued at registration.
+type UseCase struct {
+	repo         device.Repository
+	userRepo     user.Repository
+	tokenService auth.TokenService
+	hmacer       security.HMACer
+}
+
+var _ auth.DeviceAuthenticator = (*UseCase)(nil)
+
+func NewUseCase(
+	repo device.Repository,
+	userRepo user.Repository,
+	tokenService auth.TokenService,
+	hmacer security.HMACer) *UseCase {
+	return &UseCase{
+		repo:         repo,
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		hmacer:       hmacer,
+	}
+}
+
+// Register registers the device of the current session. Registering the same
+// DeviceID again updates it, so clients may register on every start. Headless
+// devices get a new secret on every registration, the previous one stops working.
+func (u *UseCase) Register(ctx context.Context, input shared.UseCaseInput[RegisterInput]) (RegisterOutput, error) {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return RegisterOutput{}, user.ErrInvalidUser
+	}
+
+	// API keys have no session to bind the device to
+	d, err := device.New(userID, input.Data.DeviceID, input.Data.Name, input.Data.Platform, input.Base.Auth.SessionID)
+	if err != nil {
+		return RegisterOutput{}, err
+	}
+
+	if err := d.SetPushToken(input.Data.PushToken); err != nil {
+		return RegisterOutput{}, err
+	}
+
+	// A push token belongs to one installation; a previous owner of the device loses it
+	if d.PushToken != "" {
+		if err := u.repo.ClearPushToken(ctx, d.PushToken); err != nil {
+			return RegisterOutput{}, err
+		}
+	}
+
+	var secret string
+	if d.IsHeadless() {
+		if secret, err = newSecret(); err != nil {
+			return RegisterOutput{}, err
+		}
+		d.SecretHash = u.hmacer.Sign(secret)
+	}
+
+	if err := u.repo.Register(ctx, d); err != nil {
+		return RegisterOutput{}, err
+	}
+
+	item := toDeviceItem(d)
+	item.Current = true
+	return RegisterOutput{Secret: secret, Device: item}, nil
+}
+
+// List lists the devices of the current user, most recently registered first
+func (u *UseCase) List(ctx context.Context, input shared.UseCaseInput[struct{}]) (ListOutput, error) {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return ListOutput{}, user.ErrInvalidUser
+	}
+
+	devices, err := u.repo.FindByUser(ctx, userID)
+	if err != nil {
+		return ListOutput{}, err
+	}
+
+	items := make([]DeviceItem, 0, len(devices))
+	for _, d := range devices {
+		item := toDeviceItem(d)
+		item.Current = isCurrent(d, input.Base)
+		items = append(items, item)
+	}
+
+	return ListOutput{Devices: items}, nil
+}
+
+// Rename changes the display name of a device of the current user
+func (u *UseCase) Rename(ctx context.Context, input shared.UseCaseInput[RenameInput]) error {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return user.ErrInvalidUser
+	}
+
+	name, err := device.ParseName(input.Data.Name)
+	if err != nil {
+		return err
+	}
+
+	return u.repo.Rename(ctx, userID, input.Data.ID, name)
+}
+
+// Remove forgets a device of the current user and logs out the sessions bound to it
+func (u *UseCase) Remove(ctx context.Context, input shared.UseCaseInput[RemoveInput]) error {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return user.ErrInvalidUser
+	}
+
+	devices, err := u.repo.FindByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var target *device.Device
+	for _, d := range devices {
+		if d.ID == input.Data.ID {
+			target = d
+			break
+		}
+	}
+	if target == nil {
+		return device.ErrDeviceNotFound
+	}
+
+	if err := u.repo.Delete(ctx, userID, target.ID); err != nil {
+		return err
+	}
+
+	sessions, err := u.tokenService.ListUserSessions(ctx, input.Base.Auth.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sessions {
+		if s.ID != target.SessionID && (s.DeviceID == "" || s.DeviceID != target.DeviceID) {
+			continue
+		}
+
+		// The session may have expired in the meantime
+		err := u.tokenService.RevokeSession(ctx, input.Base.Auth.UserID, s.ID)
+		if err != nil && !errors.Is(err, session.ErrSessionNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AuthenticateDevice returns the device of the secret when its user may log in
+func (u *UseCase) AuthenticateDevice(ctx context.Context, secret string) (*device.Device, error) {
+	if !strings.HasPrefix(secret, device.SecretPrefix) {
+		return nil, device.ErrInvalidSecret
+	}
+
+	d, err := u.repo.FindBySecretHash(ctx, u.hmacer.Sign(secret))
+	if err != nil {
+		return nil, device.ErrInvalidSecret
+	}
+
+	// Banned users lose their devices along with their sessions
+	owner, err := u.userRepo.FindByID(ctx, d.UserID)
+	if err != nil || owner.Status != user.Active {
+		return nil, device.ErrInvalidSecret
+	}
+
+	return d, nil
+}
+
+// newSecret returns a device secret of the prefix and 32 random bytes
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return device.SecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// isCurrent reports whether the request comes from the device
+func isCurrent(d *device.Device, base shared.BaseInput) bool {
+	if base.Auth != nil && base.Auth.SessionID != "" && d.SessionID == base.Auth.SessionID {
+		return true
+	}
+	return base.Request.DeviceID != "" && d.DeviceID == base.Request.DeviceID
+}
+
+func toDeviceItem(d *device.Device) DeviceItem {
+	return DeviceItem{
+		ID:        d.ID,
+		DeviceID:  d.DeviceID,
+		Name:      d.Name,
+		Platform:  string(d.Platform),
+		Push:      d.PushToken != "",
+		Headless:  d.IsHeadless(),
+		CreatedAt: timeutil.Format(d.CreatedAt, timeutil.FormatISO),
+		UpdatedAt: timeutil.Format(d.UpdatedAt, timeutil.FormatISO),
+	}
+}
@@ -0,0 +1,181 @@
+package device
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	session "github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRegister_BindsCurrentSession(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	repo := new(MockDeviceRepo)
+	repo.On("ClearPushToken", mock.Anything, "fcm-token").Return(nil)
+	repo.On("Register", mock.Anything, mock.MatchedBy(func(d *device.Device) bool {
+		return d.UserID == 1 && d.DeviceID == "pixel-7" && d.Platform == device.Android &&
+			d.SessionID == "s1" && d.PushToken == "fcm-token"
+	})).Return(nil)
+
+	uc := NewUseCase(repo, nil, nil, stubHMACer{})
+
+	got, err := uc.Register(ctx, shared.UseCaseInput[RegisterInput]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "1", SessionID: "s1"}},
+		Data: RegisterInput{DeviceID: " pixel-7 ", Name: "Phone", Platform: "Android", PushToken: "fcm-token"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pixel-7", got.Device.DeviceID)
+	assert.True(t, got.Device.Current)
+	assert.True(t, got.Device.Push)
+	assert.Empty(t, got.Secret)
+	repo.AssertExpectations(t)
+}
+
+func TestRegister_IssuesSecretToHeadlessDevice(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var stored *device.Device
+	repo := new(MockDeviceRepo)
+	repo.On("Register", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*device.Device)
+	}).Return(nil)
+
+	uc := NewUseCase(repo, nil, nil, stubHMACer{})
+
+	got, err := uc.Register(ctx, shared.UseCaseInput[RegisterInput]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "1", SessionID: "s1"}},
+		Data: RegisterInput{DeviceID: "pi-1", Name: "Garage", Platform: "embedded"},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(got.Secret, device.SecretPrefix))
+	assert.True(t, got.Device.Headless)
+	assert.Equal(t, "hmac:"+got.Secret, stored.SecretHash)
+}
+
+func TestAuthenticateDevice(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	pi := &device.Device{ID: 3, UserID: 1, Platform: device.Embedded}
+	tests := []struct {
+		name   string
+		secret string
+		status user.Status
+		want   error
+	}{
+		{"active owner", device.SecretPrefix + "known", user.Active, nil},
+		{"banned owner", device.SecretPrefix + "known", user.Banned, device.ErrInvalidSecret},
+		{"unknown secret", device.SecretPrefix + "unknown", user.Active, device.ErrInvalidSecret},
+		{"session token", "known", user.Active, device.ErrInvalidSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockDeviceRepo)
+			repo.On("FindBySecretHash", mock.Anything, "hmac:"+device.SecretPrefix+"known").Return(pi, nil)
+			repo.On("FindBySecretHash", mock.Anything, mock.Anything).Return(nil, device.ErrDeviceNotFound)
+			userRepo := new(MockUserRepo)
+			userRepo.On("FindByID", mock.Anything, user.ID(1)).Return(&user.User{ID: 1, Status: tt.status}, nil)
+
+			uc := NewUseCase(repo, userRepo, nil, stubHMACer{})
+
+			got, err := uc.AuthenticateDevice(ctx, tt.secret)
+			assert.ErrorIs(t, err, tt.want)
+			if tt.want == nil {
+				assert.Equal(t, pi, got)
+			}
+		})
+	}
+}
+
+func TestRegister_Rejects(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	loggedIn := &shared.AuthContext{UserID: "1", SessionID: "s1"}
+	tests := []struct {
+		name string
+		auth *shared.AuthContext
+		data RegisterInput
+		want error
+	}{
+		{"api key", &shared.AuthContext{UserID: "1", APIKeyID: "2"}, RegisterInput{DeviceID: "d", Name: "n", Platform: "ios"}, device.ErrSessionRequired},
+		{"device id with spaces", loggedIn, RegisterInput{DeviceID: "my phone", Name: "n", Platform: "ios"}, device.ErrInvalidDeviceID},
+		{"blank name", loggedIn, RegisterInput{DeviceID: "d", Name: " ", Platform: "ios"}, device.ErrInvalidName},
+		{"unknown platform", loggedIn, RegisterInput{DeviceID: "d", Name: "n", Platform: "symbian"}, device.ErrInvalidPlatform},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockDeviceRepo)
+			uc := NewUseCase(repo, nil, nil, stubHMACer{})
+
+			_, err := uc.Register(ctx, shared.UseCaseInput[RegisterInput]{
+				Base: shared.BaseInput{Auth: tt.auth},
+				Data: tt.data,
+			})
+			assert.ErrorIs(t, err, tt.want)
+			repo.AssertNotCalled(t, "Register", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRemove_RevokesBoundSessions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	repo := new(MockDeviceRepo)
+	repo.On("FindByUser", mock.Anything, user.ID(1)).Return([]*device.Device{
+		{ID: 3, UserID: 1, DeviceID: "pi-1", SessionID: "registered"},
+	}, nil)
+	repo.On("Delete", mock.Anything, user.ID(1), device.ID(3)).Return(nil)
+
+	tokenService := new(MockTokenService)
+	tokenService.On("ListUserSessions", mock.Anything, "1").Return([]*session.Session{
+		{ID: "registered"},
+		{ID: "logged-in", DeviceID: "pi-1"},
+		{ID: "browser"},
+	}, nil)
+	tokenService.On("RevokeSession", mock.Anything, "1", "registered").Return(session.ErrSessionNotFound)
+	tokenService.On("RevokeSession", mock.Anything, "1", "logged-in").Return(nil)
+
+	uc := NewUseCase(repo, nil, tokenService, stubHMACer{})
+
+	err := uc.Remove(ctx, shared.UseCaseInput[RemoveInput]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "1", SessionID: "browser"}},
+		Data: RemoveInput{ID: 3},
+	})
+
+	assert.NoError(t, err)
+	tokenService.AssertExpectations(t)
+	tokenService.AssertNotCalled(t, "RevokeSession", mock.Anything, "1", "browser")
+}
+
+func TestRemove_UnknownDevice(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	repo := new(MockDeviceRepo)
+	repo.On("FindByUser", mock.Anything, user.ID(1)).Return([]*device.Device{}, nil)
+
+	uc := NewUseCase(repo, nil, nil, stubHMACer{})
+
+	err := uc.Remove(ctx, shared.UseCaseInput[RemoveInput]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "1"}},
+		Data: RemoveInput{ID: 3},
+	})
+
+	assert.ErrorIs(t, err, device.ErrDeviceNotFound)
+	repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
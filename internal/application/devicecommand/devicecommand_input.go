@@ -0,0 +1,35 @@
+package devicecommand
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+)
+
+// SendInput Payload is a JSON object, a zero ExpiresIn uses the default lifetime
+type SendInput struct {
+	DeviceID  device.ID
+	Type      string
+	Payload   json.RawMessage
+	ExpiresIn time.Duration
+}
+
+type ListInput struct {
+	DeviceID device.ID
+	Limit    uint64
+}
+
+type GetInput struct {
+	DeviceID device.ID
+	ID       devicecommand.ID
+}
+
+// AckInput is the outcome a device reports for a command, Status is succeeded or failed
+type AckInput struct {
+	ID     devicecommand.ID
+	Status string
+	Result json.RawMessage
+	Error  string
+}
@@ -0,0 +1,89 @@
+package devicecommand
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCommandRepo struct {
+	mock.Mock
+}
+
+var _ devicecommand.Repository = (*MockCommandRepo)(nil)
+
+func (m *MockCommandRepo) Create(ctx context.Context, c *devicecommand.Command) error {
+	args := m.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (m *MockCommandRepo) Find(ctx context.Context, userID user.ID, deviceID device.ID, id devicecommand.ID) (*devicecommand.Command, error) {
+	args := m.Called(ctx, userID, deviceID, id)
+	c, _ := args.Get(0).(*devicecommand.Command)
+	return c, args.Error(1)
+}
+
+func (m *MockCommandRepo) FindForDevice(ctx context.Context, deviceID device.ID, id devicecommand.ID) (*devicecommand.Command, error) {
+	args := m.Called(ctx, deviceID, id)
+	c, _ := args.Get(0).(*devicecommand.Command)
+	return c, args.Error(1)
+}
+
+func (m *MockCommandRepo) FindByDevice(ctx context.Context, userID user.ID, deviceID device.ID, limit int) ([]*devicecommand.Command, error) {
+	args := m.Called(ctx, userID, deviceID, limit)
+	return args.Get(0).([]*devicecommand.Command), args.Error(1)
+}
+
+func (m *MockCommandRepo) FindUndelivered(ctx context.Context, deviceID device.ID, at time.Time) ([]*devicecommand.Command, error) {
+	args := m.Called(ctx, deviceID, at)
+	return args.Get(0).([]*devicecommand.Command), args.Error(1)
+}
+
+func (m *MockCommandRepo) CountOpen(ctx context.Context, deviceID device.ID, at time.Time) (int, error) {
+	args := m.Called(ctx, deviceID, at)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCommandRepo) MarkDelivered(ctx context.Context, id devicecommand.ID, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockCommandRepo) Complete(ctx context.Context, c *devicecommand.Command) error {
+	args := m.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (m *MockCommandRepo) ExpireDue(ctx context.Context, at time.Time) (int64, error) {
+	args := m.Called(ctx, at)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockDeviceRepo struct {
+	device.Repository
+	mock.Mock
+}
+
+func (m *MockDeviceRepo) Find(ctx context.Context, userID user.ID, id device.ID) (*device.Device, error) {
+	args := m.Called(ctx, userID, id)
+	d, _ := args.Get(0).(*device.Device)
+	return d, args.Error(1)
+}
+
+// stubChannel records deliveries, devices in online are connected
+type stubChannel struct {
+	online    map[device.ID]bool
+	delivered []devicecommand.ID
+}
+
+func (c *stubChannel) Deliver(cmd *devicecommand.Command) bool {
+	if !c.online[cmd.DeviceID] {
+		return false
+	}
+	c.delivered = append(c.delivered, cmd.ID)
+	return true
+}
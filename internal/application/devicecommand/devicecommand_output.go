@@ -0,0 +1,24 @@
+package devicecommand
+
+import (
+	"encoding/json"
+
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+)
+
+type CommandItem struct {
+	ID          devicecommand.ID
+	Type        string
+	Payload     json.RawMessage
+	Status      string
+	Result      json.RawMessage
+	Error       string
+	ExpiresAt   string
+	DeliveredAt string
+	CompletedAt string
+	CreatedAt   string
+}
+
+type ListOutput struct {
+	Commands []CommandItem
+}
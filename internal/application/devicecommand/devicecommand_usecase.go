@@ -0,0 +1,232 @@
+package devicecommand
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/devicechannel"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+)
+
+const defaultLimit uint64 = 20
+const maxLimit uint64 = 100
+
+// Config DefaultTTL is how long a command waits for its device unless the sender
+// says otherwise; MaxOpen bounds the queue of a device, zero leaves it unbounded.
+type Config struct {
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+	MaxOpen    int
+}
+
+// UseCase sends commands to headless devices and records their lifecycle. A command
+// is pending until its device is connected, delivered until the device acknowledges
+// it, and expires when neither happens in time.
+type UseCase struct {
+	repo       devicecommand.Repository
+	deviceRepo device.Repository
+	channel    devicechannel.Channel
+	conf       Config
+	now        func() time.Time
+}
+
+func NewUseCase(
+	repo devicecommand.Repository,
+	deviceRepo device.Repository,
+	channel devicechannel.Channel,
+	conf Config) *UseCase {
+	return &UseCase{
+		repo:       repo,
+		deviceRepo: deviceRepo,
+		channel:    channel,
+		conf:       conf,
+		now:        time.Now,
+	}
+}
+
+// Send queues a command for a headless device of the current user and delivers it
+// right away when the device is connected
+func (u *UseCase) Send(ctx context.Context, input shared.UseCaseInput[SendInput]) (CommandItem, error) {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return CommandItem{}, user.ErrInvalidUser
+	}
+
+	d, err := u.deviceRepo.Find(ctx, userID, input.Data.DeviceID)
+	if err != nil {
+		return CommandItem{}, err
+	}
+	if !d.IsHeadless() {
+		return CommandItem{}, device.ErrNotHeadless
+	}
+
+	ttl := input.Data.ExpiresIn
+	if ttl == 0 {
+		ttl = u.conf.DefaultTTL
+	}
+	if ttl < 0 || (u.conf.MaxTTL > 0 && ttl > u.conf.MaxTTL) {
+		return CommandItem{}, devicecommand.ErrInvalidExpiry
+	}
+
+	now := u.now()
+	cmd, err := devicecommand.New(d.ID, userID, input.Data.Type, input.Data.Payload, now.Add(ttl))
+	if err != nil {
+		return CommandItem{}, err
+	}
+
+	if u.conf.MaxOpen > 0 {
+		count, err := u.repo.CountOpen(ctx, d.ID, now)
+		if err != nil {
+			return CommandItem{}, err
+		}
+		if count >= u.conf.MaxOpen {
+			return CommandItem{}, devicecommand.ErrTooManyPending
+		}
+	}
+
+	if err := u.repo.Create(ctx, cmd); err != nil {
+		return CommandItem{}, err
+	}
+
+	if err := u.deliver(ctx, cmd); err != nil {
+		return CommandItem{}, err
+	}
+
+	return toCommandItem(cmd), nil
+}
+
+// List lists the latest commands sent to a device of the current user, newest first
+func (u *UseCase) List(ctx context.Context, input shared.UseCaseInput[ListInput]) (ListOutput, error) {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return ListOutput{}, user.ErrInvalidUser
+	}
+
+	if _, err := u.deviceRepo.Find(ctx, userID, input.Data.DeviceID); err != nil {
+		return ListOutput{}, err
+	}
+
+	limit := input.Data.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	} else if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	commands, err := u.repo.FindByDevice(ctx, userID, input.Data.DeviceID, int(limit))
+	if err != nil {
+		return ListOutput{}, err
+	}
+
+	items := make([]CommandItem, 0, len(commands))
+	for _, c := range commands {
+		items = append(items, u.toCurrentItem(c))
+	}
+
+	return ListOutput{Commands: items}, nil
+}
+
+// Get returns a command sent to a device of the current user
+func (u *UseCase) Get(ctx context.Context, input shared.UseCaseInput[GetInput]) (CommandItem, error) {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return CommandItem{}, user.ErrInvalidUser
+	}
+
+	cmd, err := u.repo.Find(ctx, userID, input.Data.DeviceID, input.Data.ID)
+	if err != nil {
+		return CommandItem{}, err
+	}
+
+	return u.toCurrentItem(cmd), nil
+}
+
+// DeliverPending delivers the commands queued while the device was offline, oldest first
+func (u *UseCase) DeliverPending(ctx context.Context, deviceID device.ID) error {
+	commands, err := u.repo.FindUndelivered(ctx, deviceID, u.now())
+	if err != nil {
+		return err
+	}
+
+	for _, cmd := range commands {
+		if err := u.deliver(ctx, cmd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Acknowledge records the outcome the device reports for one of its commands
+func (u *UseCase) Acknowledge(ctx context.Context, deviceID device.ID, input AckInput) error {
+	status, err := devicecommand.ParseOutcome(input.Status)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := u.repo.FindForDevice(ctx, deviceID, input.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Complete(status, input.Result, input.Error, u.now()); err != nil {
+		return err
+	}
+
+	return u.repo.Complete(ctx, cmd)
+}
+
+// ExpireDue expires the commands whose devices did not answer in time
+func (u *UseCase) ExpireDue(ctx context.Context) (int64, error) {
+	return u.repo.ExpireDue(ctx, u.now())
+}
+
+// deliver hands the command to the channel and marks it delivered when the device is connected.
+// A device answering before the mark keeps its outcome, only pending commands are marked.
+func (u *UseCase) deliver(ctx context.Context, cmd *devicecommand.Command) error {
+	if !u.channel.Deliver(cmd) {
+		return nil
+	}
+
+	now := u.now()
+	if err := u.repo.MarkDelivered(ctx, cmd.ID, now); err != nil {
+		return err
+	}
+
+	cmd.Status = devicecommand.Delivered
+	cmd.DeliveredAt = &now
+	return nil
+}
+
+// toCurrentItem shows open commands past their expiry as expired before the job gets to them
+func (u *UseCase) toCurrentItem(cmd *devicecommand.Command) CommandItem {
+	item := toCommandItem(cmd)
+	if cmd.IsExpired(u.now()) {
+		item.Status = string(devicecommand.Expired)
+	}
+	return item
+}
+
+func toCommandItem(c *devicecommand.Command) CommandItem {
+	item := CommandItem{
+		ID:        c.ID,
+		Type:      c.Type,
+		Payload:   c.Payload,
+		Status:    string(c.Status),
+		Result:    c.Result,
+		Error:     c.Error,
+		ExpiresAt: timeutil.Format(c.ExpiresAt, timeutil.FormatISO),
+		CreatedAt: timeutil.Format(c.CreatedAt, timeutil.FormatISO),
+	}
+	if c.DeliveredAt != nil {
+		item.DeliveredAt = timeutil.Format(*c.DeliveredAt, timeutil.FormatISO)
+	}
+	if c.CompletedAt != nil {
+		item.CompletedAt = timeutil.Format(*c.CompletedAt, timeutil.FormatISO)
+	}
+	return item
+}
@@ -0,0 +1,191 @@
+package devicecommand
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testConf = Config{DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour, MaxOpen: 10}
+
+func sendInput(data SendInput) shared.UseCaseInput[SendInput] {
+	return shared.UseCaseInput[SendInput]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "1"}},
+		Data: data,
+	}
+}
+
+func piRepo() *MockDeviceRepo {
+	deviceRepo := new(MockDeviceRepo)
+	deviceRepo.On("Find", mock.Anything, user.ID(1), device.ID(3)).
+		Return(&device.Device{ID: 3, UserID: 1, Platform: device.Embedded}, nil)
+	return deviceRepo
+}
+
+func TestSend_DeliversToConnectedDevice(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	repo := new(MockCommandRepo)
+	repo.On("CountOpen", mock.Anything, device.ID(3), mock.Anything).Return(0, nil)
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(c *devicecommand.Command) bool {
+		return c.DeviceID == 3 && c.UserID == 1 && c.Type == "gpio.write" && c.Status == devicecommand.Pending
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*devicecommand.Command).ID = 9
+	}).Return(nil)
+	repo.On("MarkDelivered", mock.Anything, devicecommand.ID(9), mock.Anything).Return(nil)
+
+	channel := &stubChannel{online: map[device.ID]bool{3: true}}
+	uc := NewUseCase(repo, piRepo(), channel, testConf)
+
+	got, err := uc.Send(ctx, sendInput(SendInput{DeviceID: 3, Type: "gpio.write", Payload: json.RawMessage(`{"pin":17}`)}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "delivered", got.Status)
+	assert.NotEmpty(t, got.DeliveredAt)
+	assert.Equal(t, []devicecommand.ID{9}, channel.delivered)
+	repo.AssertExpectations(t)
+}
+
+func TestSend_QueuesForOfflineDevice(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	repo := new(MockCommandRepo)
+	repo.On("CountOpen", mock.Anything, device.ID(3), mock.Anything).Return(0, nil)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	uc := NewUseCase(repo, piRepo(), &stubChannel{}, testConf)
+
+	got, err := uc.Send(ctx, sendInput(SendInput{DeviceID: 3, Type: "reboot"}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", got.Status)
+	assert.JSONEq(t, `{}`, string(got.Payload))
+	repo.AssertNotCalled(t, "MarkDelivered", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSend_Rejects(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name string
+		data SendInput
+		open int
+		want error
+	}{
+		{"phone", SendInput{DeviceID: 4, Type: "reboot"}, 0, device.ErrNotHeadless},
+		{"unknown device", SendInput{DeviceID: 5, Type: "reboot"}, 0, device.ErrDeviceNotFound},
+		{"bad type", SendInput{DeviceID: 3, Type: "Reboot now"}, 0, devicecommand.ErrInvalidType},
+		{"array payload", SendInput{DeviceID: 3, Type: "reboot", Payload: json.RawMessage(`[1]`)}, 0, devicecommand.ErrInvalidPayload},
+		{"too long", SendInput{DeviceID: 3, Type: "reboot", ExpiresIn: 48 * time.Hour}, 0, devicecommand.ErrInvalidExpiry},
+		{"full queue", SendInput{DeviceID: 3, Type: "reboot"}, 10, devicecommand.ErrTooManyPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deviceRepo := piRepo()
+			deviceRepo.On("Find", mock.Anything, user.ID(1), device.ID(4)).
+				Return(&device.Device{ID: 4, UserID: 1, Platform: device.Android}, nil)
+			deviceRepo.On("Find", mock.Anything, user.ID(1), device.ID(5)).
+				Return(nil, device.ErrDeviceNotFound)
+
+			repo := new(MockCommandRepo)
+			repo.On("CountOpen", mock.Anything, mock.Anything, mock.Anything).Return(tt.open, nil)
+			uc := NewUseCase(repo, deviceRepo, &stubChannel{}, testConf)
+
+			_, err := uc.Send(ctx, sendInput(tt.data))
+			assert.ErrorIs(t, err, tt.want)
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDeliverPending_DeliversQueuedCommands(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	repo := new(MockCommandRepo)
+	repo.On("FindUndelivered", mock.Anything, device.ID(3), mock.Anything).Return([]*devicecommand.Command{
+		{ID: 7, DeviceID: 3, Status: devicecommand.Pending},
+		{ID: 8, DeviceID: 3, Status: devicecommand.Pending},
+	}, nil)
+	repo.On("MarkDelivered", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	channel := &stubChannel{online: map[device.ID]bool{3: true}}
+	uc := NewUseCase(repo, nil, channel, testConf)
+
+	err := uc.DeliverPending(ctx, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []devicecommand.ID{7, 8}, channel.delivered)
+	repo.AssertNumberOfCalls(t, "MarkDelivered", 2)
+}
+
+func TestAcknowledge(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		name  string
+		cmd   *devicecommand.Command
+		input AckInput
+		want  error
+	}{
+		{"succeeded", &devicecommand.Command{ID: 9, Status: devicecommand.Delivered, ExpiresAt: future},
+			AckInput{ID: 9, Status: "succeeded", Result: json.RawMessage(`{"temp":21.5}`)}, nil},
+		{"failed before delivery mark", &devicecommand.Command{ID: 9, Status: devicecommand.Pending, ExpiresAt: future},
+			AckInput{ID: 9, Status: "failed", Error: "pin busy"}, nil},
+		{"already succeeded", &devicecommand.Command{ID: 9, Status: devicecommand.Succeeded, ExpiresAt: future},
+			AckInput{ID: 9, Status: "failed"}, devicecommand.ErrCommandClosed},
+		{"expired", &devicecommand.Command{ID: 9, Status: devicecommand.Delivered, ExpiresAt: time.Now().Add(-time.Second)},
+			AckInput{ID: 9, Status: "succeeded"}, devicecommand.ErrCommandClosed},
+		{"not an outcome", &devicecommand.Command{ID: 9, Status: devicecommand.Delivered, ExpiresAt: future},
+			AckInput{ID: 9, Status: "delivered"}, devicecommand.ErrInvalidStatus},
+		{"invalid result", &devicecommand.Command{ID: 9, Status: devicecommand.Delivered, ExpiresAt: future},
+			AckInput{ID: 9, Status: "succeeded", Result: json.RawMessage(`{oops`)}, devicecommand.ErrInvalidResult},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockCommandRepo)
+			repo.On("FindForDevice", mock.Anything, device.ID(3), devicecommand.ID(9)).Return(tt.cmd, nil)
+			repo.On("Complete", mock.Anything, tt.cmd).Return(nil)
+
+			uc := NewUseCase(repo, nil, &stubChannel{}, testConf)
+
+			err := uc.Acknowledge(ctx, 3, tt.input)
+			assert.ErrorIs(t, err, tt.want)
+			if tt.want == nil {
+				repo.AssertCalled(t, "Complete", mock.Anything, tt.cmd)
+				assert.Equal(t, devicecommand.Status(tt.input.Status), tt.cmd.Status)
+				assert.NotNil(t, tt.cmd.CompletedAt)
+			} else {
+				repo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAcknowledge_OtherDevice(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	repo := new(MockCommandRepo)
+	repo.On("FindForDevice", mock.Anything, device.ID(4), devicecommand.ID(9)).Return(nil, devicecommand.ErrCommandNotFound)
+
+	uc := NewUseCase(repo, nil, &stubChannel{}, testConf)
+
+	err := uc.Acknowledge(ctx, 4, AckInput{ID: 9, Status: "succeeded"})
+	assert.ErrorIs(t, err, devicecommand.ErrCommandNotFound)
+}
@@ -0,0 +1,13 @@
+package identity
+
+// ProviderInput the OIDC provider to start a login or link with
+type ProviderInput struct {
+	Provider string
+}
+
+// CallbackInput what the provider redirected back with
+type CallbackInput struct {
+	Provider string
+	Code     string
+	State    string
+}
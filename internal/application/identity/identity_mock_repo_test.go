@@ -0,0 +1,131 @@
+package identity
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/application/shared/oidc"
+	"github.com/HiroLiang/goat-server/internal/application/shared/security"
+	session "github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/identity"
+	domainSecurity "github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIdentityRepo struct {
+	mock.Mock
+}
+
+var _ identity.Repository = (*MockIdentityRepo)(nil)
+
+func (m *MockIdentityRepo) FindBySubject(ctx context.Context, provider, subject string) (*identity.Identity, error) {
+	args := m.Called(ctx, provider, subject)
+	i, _ := args.Get(0).(*identity.Identity)
+	return i, args.Error(1)
+}
+
+func (m *MockIdentityRepo) FindByUser(ctx context.Context, userID user.ID) ([]*identity.Identity, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*identity.Identity), args.Error(1)
+}
+
+func (m *MockIdentityRepo) Create(ctx context.Context, i *identity.Identity) error {
+	args := m.Called(ctx, i)
+	return args.Error(0)
+}
+
+func (m *MockIdentityRepo) Delete(ctx context.Context, userID user.ID, provider string) error {
+	args := m.Called(ctx, userID, provider)
+	return args.Error(0)
+}
+
+type MockUserRepo struct {
+	user.Repository
+	mock.Mock
+}
+
+func (m *MockUserRepo) FindByID(ctx context.Context, id user.ID) (*user.User, error) {
+	args := m.Called(ctx, id)
+	u, _ := args.Get(0).(*user.User)
+	return u, args.Error(1)
+}
+
+func (m *MockUserRepo) FindByEmail(ctx context.Context, email user.Email) (*user.User, error) {
+	args := m.Called(ctx, email)
+	u, _ := args.Get(0).(*user.User)
+	return u, args.Error(1)
+}
+
+type MockTwoFactorRepo struct {
+	twofactor.Repository
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepo) Find(ctx context.Context, userID user.ID) (*twofactor.TwoFactor, error) {
+	args := m.Called(ctx, userID)
+	t, _ := args.Get(0).(*twofactor.TwoFactor)
+	return t, args.Error(1)
+}
+
+type MockTokenService struct {
+	auth.TokenService
+	mock.Mock
+}
+
+func (m *MockTokenService) Generate(ctx context.Context, params session.CreateSessionParams) (session.TokenPair, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(session.TokenPair), args.Error(1)
+}
+
+// memActionTokens keeps issued tokens in a map, each consumable once
+type memActionTokens struct {
+	subjects map[string]string
+}
+
+var _ security.ActionTokenService = (*memActionTokens)(nil)
+
+func (m *memActionTokens) Issue(
+	_ context.Context,
+	purpose domainSecurity.TokenPurpose,
+	subject string,
+	_ time.Duration,
+) (string, error) {
+	token := string(purpose) + "-" + string(rune('a'+len(m.subjects)))
+	m.subjects[token] = subject
+	return token, nil
+}
+
+func (m *memActionTokens) Consume(_ context.Context, _ domainSecurity.TokenPurpose, token string) (string, error) {
+	subject, ok := m.subjects[token]
+	if !ok {
+		return "", domainSecurity.ErrInvalidActionToken
+	}
+	delete(m.subjects, token)
+	return subject, nil
+}
+
+// stubProvider returns the claims with the nonce it was sent, unless nonce is set
+type stubProvider struct {
+	claims oidc.Claims
+	nonce  string
+}
+
+var _ oidc.Provider = (*stubProvider)(nil)
+
+func (p *stubProvider) Name() string { return "fake" }
+
+func (p *stubProvider) AuthCodeURL(_ context.Context, state, nonce, _ string) (string, error) {
+	if p.nonce == "" {
+		p.nonce = nonce
+	}
+	return "https://idp.example/authorize?state=" + state, nil
+}
+
+func (p *stubProvider) Exchange(_ context.Context, _, _ string) (oidc.Claims, error) {
+	claims := p.claims
+	claims.Nonce = p.nonce
+	return claims, nil
+}
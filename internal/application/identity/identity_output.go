@@ -0,0 +1,31 @@
+package identity
+
+type ProvidersOutput struct {
+	Providers []string
+}
+
+// AuthorizeOutput the provider URL to send the browser to
+type AuthorizeOutput struct {
+	URL string
+}
+
+// CallbackOutput a session, a 2FA challenge, or only Linked when the callback
+// finished linking an identity to the logged-in user
+type CallbackOutput struct {
+	Token             string
+	RefreshToken      string
+	ExpiresIn         int
+	TwoFactorRequired bool
+	ChallengeToken    string
+	Linked            bool
+}
+
+type IdentityItem struct {
+	Provider string
+	Email    string
+	LinkedAt string
+}
+
+type ListIdentitiesOutput struct {
+	Identities []IdentityItem
+}
@@ -0,0 +1,61 @@
+package identity
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Config how long the user may take at the provider, and the login challenge
+// lifetime shared with the password login
+type Config struct {
+	StateTTL     time.Duration
+	ChallengeTTL time.Duration
+}
+
+// authState is kept server side under the state parameter until the callback,
+// so the PKCE verifier and the nonce never reach the browser
+type authState struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+
+	// UserID and SessionID are set when a logged-in user links an identity,
+	// only the same session may finish the link
+	UserID    string `json:"user_id,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
+func newAuthState(provider string) (authState, error) {
+	verifier, err := randomString(32)
+	if err != nil {
+		return authState{}, err
+	}
+	nonce, err := randomString(16)
+	if err != nil {
+		return authState{}, err
+	}
+	return authState{Provider: provider, Verifier: verifier, Nonce: nonce}, nil
+}
+
+func (s authState) encode() (string, error) {
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+func decodeAuthState(subject string) (authState, error) {
+	var s authState
+	err := json.Unmarshal([]byte(subject), &s)
+	return s, err
+}
+
+// randomString returns n random bytes, base64url encoded. 32 bytes give the
+// 43 characters PKCE asks of a verifier.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
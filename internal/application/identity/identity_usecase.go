@@ -0,0 +1,308 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/application/shared/oidc"
+	"github.com/HiroLiang/goat-server/internal/application/shared/security"
+	session "github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/identity"
+	domainSecurity "github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+)
+
+// UseCase logs users in through OpenID Connect providers and links external
+// identities to local accounts
+type UseCase struct {
+	providers     map[string]oidc.Provider
+	identityRepo  identity.Repository
+	userRepo      user.Repository
+	twoFactorRepo twofactor.Repository
+	tokenService  auth.TokenService
+	actionTokens  security.ActionTokenService
+	conf          Config
+}
+
+func NewUseCase(
+	providers []oidc.Provider,
+	identityRepo identity.Repository,
+	userRepo user.Repository,
+	twoFactorRepo twofactor.Repository,
+	tokenService auth.TokenService,
+	actionTokens security.ActionTokenService,
+	conf Config) *UseCase {
+	byName := make(map[string]oidc.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &UseCase{
+		providers:     byName,
+		identityRepo:  identityRepo,
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		tokenService:  tokenService,
+		actionTokens:  actionTokens,
+		conf:          conf,
+	}
+}
+
+// Providers lists the configured providers by name
+func (u *UseCase) Providers(_ context.Context, _ shared.UseCaseInput[struct{}]) ProvidersOutput {
+	names := make([]string, 0, len(u.providers))
+	for name := range u.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return ProvidersOutput{Providers: names}
+}
+
+// Authorize starts a login at the provider
+func (u *UseCase) Authorize(ctx context.Context, input shared.UseCaseInput[ProviderInput]) (AuthorizeOutput, error) {
+	state, err := newAuthState(input.Data.Provider)
+	if err != nil {
+		return AuthorizeOutput{}, err
+	}
+	return u.authorize(ctx, state)
+}
+
+// Link starts linking a provider account to the logged-in user. LinkCallback
+// links whatever account the user logs in with at the provider.
+func (u *UseCase) Link(ctx context.Context, input shared.UseCaseInput[ProviderInput]) (AuthorizeOutput, error) {
+	if _, err := user.ToID(input.Base.Auth.UserID); err != nil {
+		return AuthorizeOutput{}, user.ErrInvalidUser
+	}
+	// API keys have no session to bind the link to
+	if input.Base.Auth.SessionID == "" {
+		return AuthorizeOutput{}, identity.ErrLinkSession
+	}
+
+	state, err := newAuthState(input.Data.Provider)
+	if err != nil {
+		return AuthorizeOutput{}, err
+	}
+	state.UserID = input.Base.Auth.UserID
+	state.SessionID = input.Base.Auth.SessionID
+	return u.authorize(ctx, state)
+}
+
+// Callback finishes the login started by Authorize. It resolves the local user
+// by the linked identity first, then by email when both the provider and the
+// local account verified it. Without either the user has to log in with a
+// password and link the identity explicitly.
+func (u *UseCase) Callback(ctx context.Context, input shared.UseCaseInput[CallbackInput]) (CallbackOutput, error) {
+	provider, state, claims, err := u.redeem(ctx, input.Data)
+	if err != nil {
+		return CallbackOutput{}, err
+	}
+	// A link state only finishes through LinkCallback
+	if state.UserID != "" {
+		return CallbackOutput{}, domainSecurity.ErrInvalidActionToken
+	}
+
+	currentUser, err := u.resolveUser(ctx, provider.Name(), claims)
+	if err != nil {
+		return CallbackOutput{}, err
+	}
+
+	switch currentUser.Status {
+	case user.Active:
+		break
+	case user.Applying:
+		return CallbackOutput{}, user.ErrUserApplying
+	case user.Banned:
+		return CallbackOutput{}, user.ErrUserBanned
+	case user.Rejected:
+		return CallbackOutput{}, user.ErrUserRejected
+	default:
+		return CallbackOutput{}, user.ErrInvalidUser
+	}
+
+	// The provider replaces the password, not the second factor
+	enrollment, err := u.twoFactorRepo.Find(ctx, currentUser.ID)
+	if err != nil && !errors.Is(err, twofactor.ErrNotEnrolled) {
+		return CallbackOutput{}, err
+	}
+	if enrollment != nil && enrollment.IsEnabled() {
+		challenge, err := u.actionTokens.Issue(
+			ctx,
+			domainSecurity.PurposeLoginChallenge,
+			strconv.FormatInt(int64(currentUser.ID), 10),
+			u.conf.ChallengeTTL,
+		)
+		if err != nil {
+			return CallbackOutput{}, user.ErrGenerateToken
+		}
+		return CallbackOutput{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	pair, err := u.tokenService.Generate(ctx, session.CreateSessionParams{
+		UserID:    strconv.FormatInt(int64(currentUser.ID), 10),
+		IP:        input.Base.Request.IP,
+		UserAgent: input.Base.Request.UserAgent,
+		DeviceID:  input.Base.Request.DeviceID,
+	})
+	if err != nil {
+		return CallbackOutput{}, user.ErrGenerateToken
+	}
+
+	return CallbackOutput{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    int(pair.ExpiresIn.Seconds()),
+	}, nil
+}
+
+// LinkCallback finishes the link started by Link. Only the session that started
+// it may finish it, otherwise a victim completing an attacker's link, or the
+// other way round, would bind the wrong provider account.
+func (u *UseCase) LinkCallback(ctx context.Context, input shared.UseCaseInput[CallbackInput]) (CallbackOutput, error) {
+	provider, state, claims, err := u.redeem(ctx, input.Data)
+	if err != nil {
+		return CallbackOutput{}, err
+	}
+	if state.UserID == "" {
+		return CallbackOutput{}, domainSecurity.ErrInvalidActionToken
+	}
+	if state.UserID != input.Base.Auth.UserID || state.SessionID != input.Base.Auth.SessionID {
+		return CallbackOutput{}, identity.ErrLinkSession
+	}
+
+	id, err := user.ToID(state.UserID)
+	if err != nil {
+		return CallbackOutput{}, user.ErrInvalidUser
+	}
+	if err := u.identityRepo.Create(ctx, identity.New(id, provider.Name(), claims.Subject, claims.Email)); err != nil {
+		return CallbackOutput{}, err
+	}
+	return CallbackOutput{Linked: true}, nil
+}
+
+// ListIdentities lists the providers linked to the current user
+func (u *UseCase) ListIdentities(
+	ctx context.Context,
+	input shared.UseCaseInput[struct{}]) (ListIdentitiesOutput, error) {
+	id, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return ListIdentitiesOutput{}, user.ErrInvalidUser
+	}
+
+	identities, err := u.identityRepo.FindByUser(ctx, id)
+	if err != nil {
+		return ListIdentitiesOutput{}, err
+	}
+
+	items := make([]IdentityItem, 0, len(identities))
+	for _, i := range identities {
+		items = append(items, IdentityItem{Provider: i.Provider, Email: i.Email, LinkedAt: timeutil.Format(i.CreatedAt, timeutil.FormatISO)})
+	}
+
+	return ListIdentitiesOutput{Identities: items}, nil
+}
+
+// Unlink removes the identity of the provider from the current user
+func (u *UseCase) Unlink(ctx context.Context, input shared.UseCaseInput[ProviderInput]) error {
+	id, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return user.ErrInvalidUser
+	}
+
+	return u.identityRepo.Delete(ctx, id, input.Data.Provider)
+}
+
+func (u *UseCase) authorize(ctx context.Context, state authState) (AuthorizeOutput, error) {
+	provider, ok := u.providers[state.Provider]
+	if !ok {
+		return AuthorizeOutput{}, identity.ErrUnknownProvider
+	}
+
+	subject, err := state.encode()
+	if err != nil {
+		return AuthorizeOutput{}, err
+	}
+
+	token, err := u.actionTokens.Issue(ctx, domainSecurity.PurposeOIDCState, subject, u.conf.StateTTL)
+	if err != nil {
+		return AuthorizeOutput{}, err
+	}
+
+	url, err := provider.AuthCodeURL(ctx, token, state.Nonce, state.Verifier)
+	if err != nil {
+		return AuthorizeOutput{}, err
+	}
+
+	return AuthorizeOutput{URL: url}, nil
+}
+
+// redeem consumes the state and exchanges the code for verified claims
+func (u *UseCase) redeem(ctx context.Context, data CallbackInput) (oidc.Provider, authState, oidc.Claims, error) {
+	provider, ok := u.providers[data.Provider]
+	if !ok {
+		return nil, authState{}, oidc.Claims{}, identity.ErrUnknownProvider
+	}
+
+	subject, err := u.actionTokens.Consume(ctx, domainSecurity.PurposeOIDCState, data.State)
+	if err != nil {
+		return nil, authState{}, oidc.Claims{}, err
+	}
+	state, err := decodeAuthState(subject)
+	if err != nil || state.Provider != provider.Name() {
+		return nil, authState{}, oidc.Claims{}, domainSecurity.ErrInvalidActionToken
+	}
+
+	claims, err := provider.Exchange(ctx, data.Code, state.Verifier)
+	if err != nil {
+		return nil, authState{}, oidc.Claims{}, err
+	}
+	if claims.Subject == "" || claims.Nonce != state.Nonce {
+		return nil, authState{}, oidc.Claims{}, identity.ErrInvalidIDToken
+	}
+
+	return provider, state, claims, nil
+}
+
+// resolveUser finds the user of the identity, linking it on first login by verified email.
+// An unverified local email could belong to someone who registered the address
+// before its owner, so it never gets linked automatically.
+func (u *UseCase) resolveUser(ctx context.Context, provider string, claims oidc.Claims) (*user.User, error) {
+	linked, err := u.identityRepo.FindBySubject(ctx, provider, claims.Subject)
+	if err == nil {
+		found, err := u.userRepo.FindByID(ctx, linked.UserID)
+		if err != nil {
+			return nil, user.ErrUserNotFound
+		}
+		return found, nil
+	}
+	if !errors.Is(err, identity.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, identity.ErrEmailNotVerified
+	}
+
+	email, err := user.NewEmail(claims.Email)
+	if err != nil {
+		return nil, identity.ErrEmailNotVerified
+	}
+
+	currentUser, err := u.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, identity.ErrIdentityNotFound
+	}
+	if !currentUser.IsEmailVerified() {
+		return nil, identity.ErrEmailNotVerified
+	}
+
+	if err := u.identityRepo.Create(ctx, identity.New(currentUser.ID, provider, claims.Subject, claims.Email)); err != nil {
+		return nil, err
+	}
+
+	return currentUser, nil
+}
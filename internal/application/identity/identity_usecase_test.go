@@ -0,0 +1,209 @@
+package identity
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/oidc"
+	session "github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/identity"
+	domainSecurity "github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// startLogin runs Authorize and returns the state the provider would redirect back with
+func startLogin(t *testing.T, uc *UseCase, input shared.UseCaseInput[ProviderInput]) string {
+	out, err := uc.Authorize(context.Background(), input)
+	require.NoError(t, err)
+	return out.URL[strings.Index(out.URL, "state=")+len("state="):]
+}
+
+func verifiedUser() *user.User {
+	now := time.Now()
+	return &user.User{ID: 1, Email: "a@b.com", Status: user.Active, EmailVerifiedAt: &now}
+}
+
+func TestCallback_LinksByVerifiedEmailAndIssuesSession(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	provider := &stubProvider{claims: oidc.Claims{Subject: "ext-1", Email: "a@b.com", EmailVerified: true}}
+
+	identities := new(MockIdentityRepo)
+	identities.On("FindBySubject", mock.Anything, "fake", "ext-1").Return(nil, identity.ErrIdentityNotFound)
+	identities.On("Create", mock.Anything, mock.MatchedBy(func(i *identity.Identity) bool {
+		return i.UserID == 1 && i.Subject == "ext-1"
+	})).Return(nil)
+
+	users := new(MockUserRepo)
+	users.On("FindByEmail", mock.Anything, user.Email("a@b.com")).Return(verifiedUser(), nil)
+
+	twoFactors := new(MockTwoFactorRepo)
+	twoFactors.On("Find", mock.Anything, user.ID(1)).Return(nil, twofactor.ErrNotEnrolled)
+
+	tokenService := new(MockTokenService)
+	tokenService.On("Generate", mock.Anything, mock.Anything).Return(session.TokenPair{AccessToken: "t"}, nil)
+
+	uc := NewUseCase([]oidc.Provider{provider}, identities, users, twoFactors, tokenService,
+		&memActionTokens{subjects: map[string]string{}}, Config{})
+
+	state := startLogin(t, uc, shared.UseCaseInput[ProviderInput]{Data: ProviderInput{Provider: "fake"}})
+
+	out, err := uc.Callback(ctx, shared.UseCaseInput[CallbackInput]{
+		Data: CallbackInput{Provider: "fake", Code: "c", State: state},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "t", out.Token)
+	identities.AssertExpectations(t)
+
+	// The state is single use
+	_, err = uc.Callback(ctx, shared.UseCaseInput[CallbackInput]{
+		Data: CallbackInput{Provider: "fake", Code: "c", State: state},
+	})
+	assert.ErrorIs(t, err, domainSecurity.ErrInvalidActionToken)
+}
+
+func TestCallback_RejectsNonceMismatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	provider := &stubProvider{claims: oidc.Claims{Subject: "ext-1"}, nonce: "replayed"}
+	uc := NewUseCase([]oidc.Provider{provider}, nil, nil, nil, nil,
+		&memActionTokens{subjects: map[string]string{}}, Config{})
+
+	state := startLogin(t, uc, shared.UseCaseInput[ProviderInput]{Data: ProviderInput{Provider: "fake"}})
+
+	_, err := uc.Callback(ctx, shared.UseCaseInput[CallbackInput]{
+		Data: CallbackInput{Provider: "fake", Code: "c", State: state},
+	})
+
+	assert.ErrorIs(t, err, identity.ErrInvalidIDToken)
+}
+
+func TestCallback_UnverifiedLocalEmailIsNotLinked(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	provider := &stubProvider{claims: oidc.Claims{Subject: "ext-1", Email: "a@b.com", EmailVerified: true}}
+
+	identities := new(MockIdentityRepo)
+	identities.On("FindBySubject", mock.Anything, "fake", "ext-1").Return(nil, identity.ErrIdentityNotFound)
+
+	users := new(MockUserRepo)
+	users.On("FindByEmail", mock.Anything, user.Email("a@b.com")).
+		Return(&user.User{ID: 1, Email: "a@b.com", Status: user.Active}, nil)
+
+	uc := NewUseCase([]oidc.Provider{provider}, identities, users, nil, nil,
+		&memActionTokens{subjects: map[string]string{}}, Config{})
+
+	state := startLogin(t, uc, shared.UseCaseInput[ProviderInput]{Data: ProviderInput{Provider: "fake"}})
+
+	_, err := uc.Callback(ctx, shared.UseCaseInput[CallbackInput]{
+		Data: CallbackInput{Provider: "fake", Code: "c", State: state},
+	})
+
+	assert.ErrorIs(t, err, identity.ErrEmailNotVerified)
+	identities.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// startLink runs Link for the caller and returns the state the provider would redirect back with
+func startLink(t *testing.T, uc *UseCase, caller *shared.AuthContext) string {
+	out, err := uc.Link(context.Background(), shared.UseCaseInput[ProviderInput]{
+		Base: shared.BaseInput{Auth: caller},
+		Data: ProviderInput{Provider: "fake"},
+	})
+	require.NoError(t, err)
+	return out.URL[strings.Index(out.URL, "state=")+len("state="):]
+}
+
+func TestLinkCallback_LinksToLoggedInUser(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// An unverified provider email is fine, the user proved both accounts
+	provider := &stubProvider{claims: oidc.Claims{Subject: "ext-1", Email: "other@b.com"}}
+
+	identities := new(MockIdentityRepo)
+	identities.On("Create", mock.Anything, mock.MatchedBy(func(i *identity.Identity) bool {
+		return i.UserID == 7 && i.Provider == "fake" && i.Subject == "ext-1"
+	})).Return(nil)
+
+	uc := NewUseCase([]oidc.Provider{provider}, identities, nil, nil, nil,
+		&memActionTokens{subjects: map[string]string{}}, Config{})
+
+	caller := &shared.AuthContext{UserID: "7", SessionID: "s7"}
+	state := startLink(t, uc, caller)
+
+	got, err := uc.LinkCallback(ctx, shared.UseCaseInput[CallbackInput]{
+		Base: shared.BaseInput{Auth: caller},
+		Data: CallbackInput{Provider: "fake", Code: "c", State: state},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, got.Linked)
+	assert.Empty(t, got.Token)
+	identities.AssertExpectations(t)
+}
+
+func TestLinkCallback_OtherCallerIsRejected(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	attacker := &shared.AuthContext{UserID: "7", SessionID: "s7"}
+	linkBy := func(caller *shared.AuthContext) func(uc *UseCase, state string) error {
+		return func(uc *UseCase, state string) error {
+			_, err := uc.LinkCallback(ctx, shared.UseCaseInput[CallbackInput]{
+				Base: shared.BaseInput{Auth: caller},
+				Data: CallbackInput{Provider: "fake", Code: "c", State: state},
+			})
+			return err
+		}
+	}
+
+	tests := []struct {
+		name   string
+		finish func(uc *UseCase, state string) error
+		want   error
+	}{
+		{"other user", linkBy(&shared.AuthContext{UserID: "8", SessionID: "s8"}), identity.ErrLinkSession},
+		{"other session", linkBy(&shared.AuthContext{UserID: "7", SessionID: "other"}), identity.ErrLinkSession},
+		{"login callback", func(uc *UseCase, state string) error {
+			_, err := uc.Callback(ctx, shared.UseCaseInput[CallbackInput]{
+				Data: CallbackInput{Provider: "fake", Code: "c", State: state},
+			})
+			return err
+		}, domainSecurity.ErrInvalidActionToken},
+	}
+
+	for _, tt := range tests {
+		provider := &stubProvider{claims: oidc.Claims{Subject: "attacker-ext"}}
+		identities := new(MockIdentityRepo)
+		uc := NewUseCase([]oidc.Provider{provider}, identities, nil, nil, nil,
+			&memActionTokens{subjects: map[string]string{}}, Config{})
+
+		state := startLink(t, uc, attacker)
+
+		assert.ErrorIs(t, tt.finish(uc, state), tt.want, tt.name)
+		identities.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	}
+}
+
+func TestLink_RequiresSession(t *testing.T) {
+	uc := NewUseCase([]oidc.Provider{&stubProvider{}}, nil, nil, nil, nil,
+		&memActionTokens{subjects: map[string]string{}}, Config{})
+
+	_, err := uc.Link(context.Background(), shared.UseCaseInput[ProviderInput]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "7", APIKeyID: "k1"}},
+		Data: ProviderInput{Provider: "fake"},
+	})
+
+	assert.ErrorIs(t, err, identity.ErrLinkSession)
+}
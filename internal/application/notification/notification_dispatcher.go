@@ -0,0 +1,201 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	notify "github.com/HiroLiang/goat-server/internal/application/shared/notification"
+	"github.com/HiroLiang/goat-server/internal/application/shared/push"
+	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// Config CollapseWindow is how long further messages of a group are folded into the
+// next notification of a user; PreviewLength bounds the message text in a notification.
+type Config struct {
+	CollapseWindow time.Duration
+	PreviewLength  int
+}
+
+// Dispatcher pushes chat messages to the registered devices of members that are
+// neither connected nor muted the group. Within the collapse window a user gets one
+// notification per group, the next one after the window counts the folded messages.
+type Dispatcher struct {
+	deviceRepo device.Repository
+	presence   notify.Presence
+	providers  []push.Provider
+	conf       Config
+	now        func() time.Time
+
+	mu        sync.Mutex
+	collapsed map[collapseKey]*collapseState
+	sweptAt   time.Time
+}
+
+type collapseKey struct {
+	userID  user.ID
+	groupID chatgroup.ID
+}
+
+// collapseState the last notification of a user for a group, and the messages folded since
+type collapseState struct {
+	sentAt time.Time
+	folded int
+}
+
+var _ notify.Notifier = (*Dispatcher)(nil)
+
+// NewDispatcher creates the dispatcher. A device goes through the first provider
+// supporting its platform; devices no provider supports are skipped.
+func NewDispatcher(
+	deviceRepo device.Repository,
+	presence notify.Presence,
+	providers []push.Provider,
+	conf Config) *Dispatcher {
+	return &Dispatcher{
+		deviceRepo: deviceRepo,
+		presence:   presence,
+		providers:  providers,
+		conf:       conf,
+		now:        time.Now,
+		collapsed:  make(map[collapseKey]*collapseState),
+	}
+}
+
+// NotifyChatMessage pushes the message to the offline recipients. Tokens the provider
+// rejects are removed from their devices; other delivery errors are joined.
+func (d *Dispatcher) NotifyChatMessage(ctx context.Context, msg notify.ChatMessage) error {
+	counts := make(map[user.ID]int, len(msg.Recipients))
+	userIDs := make([]user.ID, 0, len(msg.Recipients))
+	for _, r := range msg.Recipients {
+		if r.Muted || d.presence.IsOnline(strconv.FormatInt(int64(r.UserID), 10)) {
+			continue
+		}
+
+		count, ok := d.collapse(r.UserID, msg.GroupID)
+		if !ok {
+			continue
+		}
+		counts[r.UserID] = count
+		userIDs = append(userIDs, r.UserID)
+	}
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	devices, err := d.deviceRepo.FindPushTargets(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, target := range devices {
+		provider := d.provider(target.Platform)
+		if provider == nil {
+			continue
+		}
+
+		err := provider.Send(ctx, d.message(msg, target.PushToken, counts[target.UserID]))
+		if errors.Is(err, device.ErrPushTokenRejected) {
+			if err := d.deviceRepo.ClearPushToken(ctx, target.PushToken); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("push to device %d via %s: %w", target.ID, provider.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// collapse returns how many messages the next notification of the user for the group
+// stands for, or false while the collapse window of the last notification is open
+func (d *Dispatcher) collapse(userID user.ID, groupID chatgroup.ID) (int, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	d.sweep(now)
+
+	key := collapseKey{userID: userID, groupID: groupID}
+	state, ok := d.collapsed[key]
+	if ok && now.Sub(state.sentAt) < d.conf.CollapseWindow {
+		state.folded++
+		return 0, false
+	}
+
+	count := 1
+	if ok {
+		count += state.folded
+	}
+	d.collapsed[key] = &collapseState{sentAt: now}
+	return count, true
+}
+
+// sweep forgets closed windows without folded messages, at most once per window.
+// Caller must hold d.mu.
+func (d *Dispatcher) sweep(now time.Time) {
+	if now.Sub(d.sweptAt) < d.conf.CollapseWindow {
+		return
+	}
+	d.sweptAt = now
+
+	for key, state := range d.collapsed {
+		if state.folded == 0 && now.Sub(state.sentAt) >= d.conf.CollapseWindow {
+			delete(d.collapsed, key)
+		}
+	}
+}
+
+func (d *Dispatcher) provider(platform device.Platform) push.Provider {
+	for _, p := range d.providers {
+		if p.Supports(platform) {
+			return p
+		}
+	}
+	return nil
+}
+
+// message builds the notification of a message that stands for count messages of the group
+func (d *Dispatcher) message(msg notify.ChatMessage, token string, count int) push.Message {
+	title := msg.GroupName
+	if title == "" {
+		title = "New message"
+	}
+
+	body := msg.SenderName + ": " + preview(msg.Content, d.conf.PreviewLength)
+	if count > 1 {
+		body = fmt.Sprintf("%s (+%d more)", body, count-1)
+	}
+
+	return push.Message{
+		Token:       token,
+		Title:       title,
+		Body:        body,
+		CollapseKey: fmt.Sprintf("chat-group-%d", msg.GroupID),
+		Data: map[string]string{
+			"type":       "chat.message",
+			"group_id":   strconv.FormatInt(int64(msg.GroupID), 10),
+			"message_id": strconv.FormatInt(int64(msg.MessageID), 10),
+			"count":      strconv.Itoa(count),
+		},
+	}
+}
+
+// preview cuts content to limit runes, 0 keeps it whole
+func preview(content string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(content) <= limit {
+		return content
+	}
+	runes := []rune(content)
+	return string(runes[:limit]) + "…"
+}
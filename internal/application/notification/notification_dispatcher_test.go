@@ -0,0 +1,113 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	notify "github.com/HiroLiang/goat-server/internal/application/shared/notification"
+	"github.com/HiroLiang/goat-server/internal/application/shared/push"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func chatMessage(content string, recipients ...notify.Recipient) notify.ChatMessage {
+	return notify.ChatMessage{
+		GroupID:    7,
+		GroupName:  "Team",
+		MessageID:  1,
+		SenderName: "Alice",
+		Content:    content,
+		Recipients: recipients,
+	}
+}
+
+func TestNotifyChatMessage_SkipsMutedAndOnlineMembers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	devices := new(MockDeviceRepo)
+	devices.On("FindPushTargets", mock.Anything, []user.ID{3}).Return([]*device.Device{
+		{ID: 1, UserID: 3, Platform: device.Android, PushToken: "t3"},
+	}, nil)
+
+	provider := &recordingProvider{platforms: []device.Platform{device.Android}}
+	d := NewDispatcher(devices, stubPresence{"2": true}, []push.Provider{provider}, Config{})
+
+	err := d.NotifyChatMessage(ctx, chatMessage("hello",
+		notify.Recipient{UserID: 1, Muted: true},
+		notify.Recipient{UserID: 2},
+		notify.Recipient{UserID: 3},
+	))
+
+	require.NoError(t, err)
+	require.Len(t, provider.sent, 1)
+	assert.Equal(t, "t3", provider.sent[0].Token)
+	assert.Equal(t, "Team", provider.sent[0].Title)
+	assert.Equal(t, "Alice: hello", provider.sent[0].Body)
+	assert.Equal(t, "chat-group-7", provider.sent[0].CollapseKey)
+}
+
+func TestNotifyChatMessage_CollapsesGroupMessages(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	devices := new(MockDeviceRepo)
+	devices.On("FindPushTargets", mock.Anything, []user.ID{3}).Return([]*device.Device{
+		{ID: 1, UserID: 3, Platform: device.IOS, PushToken: "t3"},
+	}, nil)
+
+	provider := &recordingProvider{platforms: []device.Platform{device.IOS}}
+	d := NewDispatcher(devices, stubPresence{}, []push.Provider{provider}, Config{CollapseWindow: time.Minute})
+
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	recipient := notify.Recipient{UserID: 3}
+	for _, content := range []string{"one", "two", "three"} {
+		require.NoError(t, d.NotifyChatMessage(ctx, chatMessage(content, recipient)))
+	}
+	require.Len(t, provider.sent, 1)
+
+	// the first notification after the window stands for the folded messages too
+	now = now.Add(time.Minute)
+	require.NoError(t, d.NotifyChatMessage(ctx, chatMessage("four", recipient)))
+
+	require.Len(t, provider.sent, 2)
+	assert.Equal(t, "Alice: four (+2 more)", provider.sent[1].Body)
+	assert.Equal(t, "3", provider.sent[1].Data["count"])
+}
+
+func TestNotifyChatMessage_PrunesRejectedTokens(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	devices := new(MockDeviceRepo)
+	devices.On("FindPushTargets", mock.Anything, []user.ID{3}).Return([]*device.Device{
+		{ID: 1, UserID: 3, Platform: device.Android, PushToken: "stale"},
+		{ID: 2, UserID: 3, Platform: device.Android, PushToken: "fresh"},
+		{ID: 3, UserID: 3, Platform: device.Embedded, PushToken: "unsupported"},
+	}, nil)
+	devices.On("ClearPushToken", mock.Anything, "stale").Return(nil)
+
+	provider := &recordingProvider{
+		platforms: []device.Platform{device.Android},
+		rejected:  map[string]bool{"stale": true},
+	}
+	d := NewDispatcher(devices, stubPresence{}, []push.Provider{provider}, Config{})
+
+	err := d.NotifyChatMessage(ctx, chatMessage("hello", notify.Recipient{UserID: 3}))
+
+	require.NoError(t, err)
+	require.Len(t, provider.sent, 1)
+	assert.Equal(t, "fresh", provider.sent[0].Token)
+	devices.AssertExpectations(t)
+}
+
+func TestPreview(t *testing.T) {
+	assert.Equal(t, "héllo", preview("héllo", 0))
+	assert.Equal(t, "hé…", preview("héllo", 2))
+}
@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"context"
+	"sync"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/push"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDeviceRepo struct {
+	device.Repository
+	mock.Mock
+}
+
+func (m *MockDeviceRepo) FindPushTargets(ctx context.Context, userIDs []user.ID) ([]*device.Device, error) {
+	args := m.Called(ctx, userIDs)
+	return args.Get(0).([]*device.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepo) ClearPushToken(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+// stubPresence reports the listed users as connected
+type stubPresence map[string]bool
+
+func (p stubPresence) IsOnline(userID string) bool { return p[userID] }
+
+// recordingProvider records sent messages and rejects the listed tokens
+type recordingProvider struct {
+	platforms []device.Platform
+	rejected  map[string]bool
+
+	mu   sync.Mutex
+	sent []push.Message
+}
+
+func (p *recordingProvider) Name() string { return "recording" }
+
+func (p *recordingProvider) Supports(platform device.Platform) bool {
+	for _, supported := range p.platforms {
+		if supported == platform {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *recordingProvider) Send(_ context.Context, msg push.Message) error {
+	if p.rejected[msg.Token] {
+		return device.ErrPushTokenRejected
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, msg)
+	return nil
+}
@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/domain/userrole"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserRoleRepo struct {
+	mock.Mock
+}
+
+var _ userrole.Repository = (*MockUserRoleRepo)(nil)
+
+func (m *MockUserRoleRepo) FindRolesByUser(ctx context.Context, userID user.ID) ([]*role.Role, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*role.Role), args.Error(1)
+}
+
+func (m *MockUserRoleRepo) Exists(ctx context.Context, userID user.ID, role role.Type) bool {
+	args := m.Called(ctx, userID, role)
+	return args.Bool(0)
+}
+
+func (m *MockUserRoleRepo) Assign(ctx context.Context, userID user.ID, role role.Type) error {
+	args := m.Called(ctx, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockUserRoleRepo) Revoke(ctx context.Context, userID user.ID, role role.Type) error {
+	args := m.Called(ctx, userID, role)
+	return args.Error(0)
+}
+
+type MockPermissionRepo struct {
+	mock.Mock
+}
+
+var _ permission.Repository = (*MockPermissionRepo)(nil)
+
+func (m *MockPermissionRepo) FindByRole(ctx context.Context, roleType role.Type) ([]permission.Permission, error) {
+	args := m.Called(ctx, roleType)
+	return args.Get(0).([]permission.Permission), args.Error(1)
+}
+
+type MockTwoFactorRepo struct {
+	mock.Mock
+}
+
+var _ twofactor.Repository = (*MockTwoFactorRepo)(nil)
+
+func (m *MockTwoFactorRepo) Find(ctx context.Context, userID user.ID) (*twofactor.TwoFactor, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*twofactor.TwoFactor), args.Error(1)
+}
+
+func (m *MockTwoFactorRepo) Save(ctx context.Context, t *twofactor.TwoFactor) error {
+	args := m.Called(ctx, t)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepo) Delete(ctx context.Context, userID user.ID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, userID user.ID, hashes []string) error {
+	args := m.Called(ctx, userID, hashes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepo) FindUnusedRecoveryCodes(ctx context.Context, userID user.ID) ([]*twofactor.RecoveryCode, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*twofactor.RecoveryCode), args.Error(1)
+}
+
+func (m *MockTwoFactorRepo) UseRecoveryCode(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
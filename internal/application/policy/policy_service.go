@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/domain/userrole"
+)
+
+// Service checks permissions for use cases and middleware. Roles and their
+// permissions come from the cached repositories, so checks are cheap enough
+// to run on every request.
+type Service struct {
+	userRoleRepo   userrole.Repository
+	permissionRepo permission.Repository
+	twoFactorRepo  twofactor.Repository
+}
+
+var _ auth.PolicyChecker = (*Service)(nil)
+
+func NewService(
+	userRoleRepo userrole.Repository,
+	permissionRepo permission.Repository,
+	twoFactorRepo twofactor.Repository) *Service {
+	return &Service{
+		userRoleRepo:   userRoleRepo,
+		permissionRepo: permissionRepo,
+		twoFactorRepo:  twoFactorRepo,
+	}
+}
+
+// Permissions returns the effective permissions of the user. Roles that
+// require 2FA grant nothing until the user has enabled it.
+func (s *Service) Permissions(ctx context.Context, userID user.ID) ([]permission.Permission, error) {
+	roles, err := s.userRoleRepo.FindRolesByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.ContainsFunc(roles, func(r *role.Role) bool { return r.RequireTwoFactor }) {
+		enabled, err := s.twoFactorEnabled(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !enabled {
+			roles = slices.DeleteFunc(slices.Clone(roles), func(r *role.Role) bool { return r.RequireTwoFactor })
+		}
+	}
+
+	sets := make([][]permission.Permission, 0, len(roles))
+	for _, r := range roles {
+		granted, err := s.permissionRepo.FindByRole(ctx, r.Type)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, granted)
+	}
+
+	return permission.Merge(sets...), nil
+}
+
+// Authorize returns permission.ErrDenied when the user lacks perm
+func (s *Service) Authorize(ctx context.Context, userID user.ID, perm permission.Permission) error {
+	permissions, err := s.Permissions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Contains(permissions, perm) {
+		return permission.ErrDenied
+	}
+	return nil
+}
+
+func (s *Service) twoFactorEnabled(ctx context.Context, userID user.ID) (bool, error) {
+	enrollment, err := s.twoFactorRepo.Find(ctx, userID)
+	if errors.Is(err, twofactor.ErrNotEnrolled) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return enrollment.IsEnabled(), nil
+}
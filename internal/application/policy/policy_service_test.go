@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPermissions_MergesRoles(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	roles := new(MockUserRoleRepo)
+	roles.On("FindRolesByUser", mock.Anything, user.ID(1)).
+		Return([]*role.Role{{Type: role.User}, {Type: role.Vendor}}, nil)
+
+	permissions := new(MockPermissionRepo)
+	permissions.On("FindByRole", mock.Anything, role.User).
+		Return([]permission.Permission{permission.ChatCreateChannel}, nil)
+	permissions.On("FindByRole", mock.Anything, role.Vendor).
+		Return([]permission.Permission{permission.AgentManage, permission.ChatCreateChannel}, nil)
+
+	s := NewService(roles, permissions, new(MockTwoFactorRepo))
+
+	got, err := s.Permissions(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []permission.Permission{permission.AgentManage, permission.ChatCreateChannel}, got)
+}
+
+func TestAuthorize_DeniesMissingPermission(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	roles := new(MockUserRoleRepo)
+	roles.On("FindRolesByUser", mock.Anything, user.ID(1)).
+		Return([]*role.Role{{Type: role.User}}, nil)
+
+	permissions := new(MockPermissionRepo)
+	permissions.On("FindByRole", mock.Anything, role.User).
+		Return(permission.DefaultGrants[role.User], nil)
+
+	s := NewService(roles, permissions, new(MockTwoFactorRepo))
+
+	assert.NoError(t, s.Authorize(ctx, 1, permission.ChatCreateChannel))
+	assert.ErrorIs(t, s.Authorize(ctx, 1, permission.UserBan), permission.ErrDenied)
+}
+
+func TestPermissions_SkipsRolesRequiringTwoFactor(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	roles := new(MockUserRoleRepo)
+	roles.On("FindRolesByUser", mock.Anything, user.ID(1)).
+		Return([]*role.Role{{Type: role.User}, {Type: role.Admin, RequireTwoFactor: true}}, nil)
+
+	permissions := new(MockPermissionRepo)
+	permissions.On("FindByRole", mock.Anything, role.User).
+		Return(permission.DefaultGrants[role.User], nil)
+	permissions.On("FindByRole", mock.Anything, role.Admin).
+		Return(permission.DefaultGrants[role.Admin], nil)
+
+	twoFactors := new(MockTwoFactorRepo)
+	twoFactors.On("Find", mock.Anything, user.ID(1)).Return(nil, twofactor.ErrNotEnrolled).Once()
+
+	s := NewService(roles, permissions, twoFactors)
+
+	assert.ErrorIs(t, s.Authorize(ctx, 1, permission.UserBan), permission.ErrDenied)
+	permissions.AssertNotCalled(t, "FindByRole", mock.Anything, role.Admin)
+
+	enabled := twofactor.New(1, "SECRET")
+	assert.NoError(t, enabled.Enable(time.Now()))
+	twoFactors.On("Find", mock.Anything, user.ID(1)).Return(enabled, nil)
+
+	assert.NoError(t, s.Authorize(ctx, 1, permission.UserBan))
+}
@@ -0,0 +1,33 @@
+package agentreply
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
+	"github.com/HiroLiang/goat-server/internal/domain/chatmessage"
+	"github.com/HiroLiang/goat-server/internal/domain/participant"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// Request asks an agent to answer a group message.
+type Request struct {
+	GroupID       chatgroup.ID
+	TriggerID     chatmessage.ID
+	AgentID       agent.ID
+	ParticipantID participant.ID
+
+	// Depth is the agent depth of the trigger message.
+	Depth int
+
+	// UserID is the user whose message started the chain, the reply counts
+	// against their quota. The server derives it again from the trigger when
+	// the answer comes back, so the runtime need not return it.
+	UserID user.ID
+}
+
+// Dispatcher hands reply requests to the agent runtime. Dispatch must not block
+// on the generation itself; the runtime posts the answer back as a new message.
+type Dispatcher interface {
+	Dispatch(ctx context.Context, request Request) error
+}
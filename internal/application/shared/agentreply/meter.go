@@ -0,0 +1,27 @@
+package agentreply
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// Usage is the metering data of one answered reply request.
+type Usage struct {
+	UserID           user.ID
+	AgentID          agent.ID
+	PromptTokens     int64
+	CompletionTokens int64
+	Latency          time.Duration
+}
+
+// Meter keeps agent replies within the daily budget of the user they are made for.
+type Meter interface {
+	// CheckQuota returns agentusage.ErrQuotaExceeded once the user has used up today's budget.
+	CheckQuota(ctx context.Context, userID user.ID) error
+
+	// RecordUsage meters one answered reply.
+	RecordUsage(ctx context.Context, usage Usage) error
+}
@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+)
+
+// APIKeyAuthenticator resolves API keys for the transport layers.
+type APIKeyAuthenticator interface {
+
+	// Authenticate returns the active key of the token and records its use from ip.
+	// It returns apikey.ErrInvalidAPIKey for unknown, revoked or expired keys.
+	Authenticate(ctx context.Context, token, ip string) (*apikey.APIKey, error)
+}
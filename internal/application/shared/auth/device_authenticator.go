@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+)
+
+// DeviceAuthenticator resolves the secrets headless devices connect with.
+type DeviceAuthenticator interface {
+
+	// AuthenticateDevice returns the device of the secret. It returns
+	// device.ErrInvalidSecret for unknown secrets and devices of inactive users.
+	AuthenticateDevice(ctx context.Context, secret string) (*device.Device, error)
+}
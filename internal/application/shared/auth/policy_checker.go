@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// PolicyChecker resolves what a user may do from the permissions of their roles.
+type PolicyChecker interface {
+
+	// Permissions returns the sorted union of the permissions of every role of the user.
+	Permissions(ctx context.Context, userID user.ID) ([]permission.Permission, error)
+
+	// Authorize returns permission.ErrDenied when the user lacks perm.
+	Authorize(ctx context.Context, userID user.ID, perm permission.Permission) error
+}
@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+)
+
+// RoleChecker authorizes users by role for the transport layers.
+type RoleChecker interface {
+
+	// MatchRole returns the role of the user that matches one of roles.
+	// It returns user.ErrForbidden when the user holds none of them.
+	MatchRole(ctx context.Context, userID string, roles ...role.Type) (*role.Role, error)
+}
@@ -7,10 +7,20 @@ import (
 )
 
 type TokenService interface {
-	Generate(ctx context.Context, params auth.CreateSessionParams) (string, error)
-	Refresh(ctx context.Context, token string) (string, error)
+	Generate(ctx context.Context, params auth.CreateSessionParams) (auth.TokenPair, error)
+
+	// Refresh exchanges a refresh token (the session token in opaque mode) for a new pair.
+	// It returns auth.ErrTokenReused when an already rotated token is presented again.
+	Refresh(ctx context.Context, token string) (auth.TokenPair, error)
 	Validate(ctx context.Context, token string) (*auth.Session, error)
 	Revoke(ctx context.Context, token string) error
 	RevokeAllForUser(ctx context.Context, userID string) error
 	ListUserSessions(ctx context.Context, userID string) ([]*auth.Session, error)
+
+	// RevokeSession deletes the session of the user with the given session ID.
+	// It returns auth.ErrSessionNotFound when the user has no such session.
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+
+	// RevokeOtherSessions deletes every session of the user except keepSessionID.
+	RevokeOtherSessions(ctx context.Context, userID, keepSessionID string) error
 }
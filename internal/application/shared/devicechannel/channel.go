@@ -0,0 +1,34 @@
+package devicechannel
+
+import (
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+)
+
+// Channel reaches headless devices over their live connection.
+type Channel interface {
+
+	// Deliver sends the command to its device, and reports false when the device
+	// is not connected. Devices may see a command twice and should skip known IDs.
+	Deliver(cmd *devicecommand.Command) bool
+}
+
+// Channels tries each channel in turn until one reaches the device.
+type Channels []Channel
+
+func (cs Channels) Deliver(cmd *devicecommand.Command) bool {
+	for _, c := range cs {
+		if c.Deliver(cmd) {
+			return true
+		}
+	}
+	return false
+}
+
+// Feed streams the telemetry of devices to the users watching them.
+type Feed interface {
+
+	// Publish sends the samples of a device to its watchers, if there are any
+	Publish(deviceID device.ID, samples []*telemetry.Sample)
+}
@@ -0,0 +1,14 @@
+package mail
+
+import "context"
+
+// Message a plain text mail to one recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,27 @@
+package modelcatalog
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+)
+
+// ProviderModel is a model as reported by a provider.
+type ProviderModel struct {
+	Name          string
+	Family        string
+	ParameterSize string
+	Quantization  string
+	SizeBytes     int64
+	Digest        string
+	ModifiedAt    time.Time
+}
+
+// Provider is a model host the catalog can be synced from, e.g. an Ollama server.
+type Provider interface {
+	Name() string
+	AgentType() agent.Type
+	Engine() agent.Engine
+	ListModels(ctx context.Context) ([]ProviderModel, error)
+}
@@ -0,0 +1,36 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
+	"github.com/HiroLiang/goat-server/internal/domain/chatmessage"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// Recipient a user member of the group of a message
+type Recipient struct {
+	UserID user.ID
+	Muted  bool
+}
+
+// ChatMessage a new group message, for the members that are not connected to see
+type ChatMessage struct {
+	GroupID    chatgroup.ID
+	GroupName  string
+	MessageID  chatmessage.ID
+	SenderName string
+	Content    string
+	Recipients []Recipient
+}
+
+// Notifier tells members about messages they cannot see live. NotifyChatMessage
+// must not block on the delivery itself.
+type Notifier interface {
+	NotifyChatMessage(ctx context.Context, msg ChatMessage) error
+}
+
+// Presence reports whether a user has a live connection that already receives messages
+type Presence interface {
+	IsOnline(userID string) bool
+}
@@ -0,0 +1,26 @@
+package oidc
+
+import "context"
+
+// Claims the identity an ID token asserts, after its signature, issuer,
+// audience and expiry were checked
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Nonce         string
+}
+
+// Provider is an OpenID Connect provider using the authorization code flow with PKCE.
+type Provider interface {
+	Name() string
+
+	// AuthCodeURL returns the URL to send the browser to. The code challenge is
+	// derived from the verifier with S256.
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+
+	// Exchange redeems the code with the verifier and returns the claims of the
+	// validated ID token. The nonce is left for the caller to compare.
+	Exchange(ctx context.Context, code, verifier string) (Claims, error)
+}
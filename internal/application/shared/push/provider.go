@@ -0,0 +1,29 @@
+package push
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+)
+
+// Message a notification to one device. Notifications with the same CollapseKey
+// replace each other on the device, so only the latest one is shown.
+type Message struct {
+	Token       string
+	Title       string
+	Body        string
+	CollapseKey string
+	Data        map[string]string
+}
+
+// Provider delivers notifications to devices, e.g. through FCM or APNs.
+type Provider interface {
+	Name() string
+
+	// Supports reports whether the provider reaches devices of the platform
+	Supports(platform device.Platform) bool
+
+	// Send delivers the message. It returns device.ErrPushTokenRejected when the
+	// provider no longer knows the token, so the token can be dropped.
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,26 @@
+package security
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+)
+
+// ActionTokenService issues single-use, expiring tokens, such as email verification
+// and password reset links or the challenge of a login waiting for its second factor.
+type ActionTokenService interface {
+
+	// Issue returns a new token of the purpose for the subject. Earlier tokens of
+	// the same purpose and subject stop working.
+	Issue(ctx context.Context, purpose security.TokenPurpose, subject string, ttl time.Duration) (string, error)
+
+	// Consume returns the subject of the token and invalidates it.
+	// It returns security.ErrInvalidActionToken when the token is unknown, used or expired.
+	Consume(ctx context.Context, purpose security.TokenPurpose, token string) (string, error)
+}
+
+// MailRateLimiter limits how often mails are sent to one address
+type MailRateLimiter interface {
+	CheckMail(ctx context.Context, email string) error
+}
@@ -3,4 +3,7 @@ package security
 type Hasher interface {
 	Hash(password string) (string, error)
 	Verify(password, hash string) bool
+
+	// NeedsRehash reports whether the hash was made with an outdated format or weaker parameters
+	NeedsRehash(hash string) bool
 }
@@ -1,11 +1,21 @@
 package security
 
-import "context"
+import (
+	"context"
 
-// RateLimiter Generic rate limiter
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+)
+
+// RateLimiter Generic rate limiter. Every check counts a request and returns the quota
+// left, along with security.ErrRateLimitExceeded once it is used up.
 type RateLimiter interface {
-	CheckGlobal(ctx context.Context) error
-	CheckIP(ctx context.Context, ip string) error
+	CheckGlobal(ctx context.Context) (security.RateLimitResult, error)
+	CheckIP(ctx context.Context, ip string) (security.RateLimitResult, error)
+	CheckUser(ctx context.Context, userID string) (security.RateLimitResult, error)
+
+	// CheckRoute counts a request to route, such as "POST /api/user/login", for key.
+	// Routes without a policy of their own are not counted and return a zero result.
+	CheckRoute(ctx context.Context, route, key string) (security.RateLimitResult, error)
 }
 
 // LoginRateLimiter THe rate limiter for login attempts
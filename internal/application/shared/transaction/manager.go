@@ -0,0 +1,11 @@
+package transaction
+
+import "context"
+
+// Manager runs use case steps in one database transaction. Repositories called with the
+// context fn gets join it, and a nested WithinTx becomes a savepoint.
+// fn may run again when the database aborts it for a conflict, so side effects such as
+// mails belong after WithinTx returns.
+type Manager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -1,14 +1,24 @@
 package shared
 
+import "github.com/HiroLiang/goat-server/internal/domain/apikey"
+
 type RequestContext struct {
-	IP      string
-	TraceID string
+	IP        string
+	TraceID   string
+	UserAgent string
+	DeviceID  string // registered device the request comes from, if the client says so
 }
 
 type AuthContext struct {
-	UserID string
-	RoleID string
-	Token  string
+	UserID    string
+	RoleID    string
+	Token     string
+	SessionID string
+
+	// APIKeyID is set instead of SessionID when the request used an API key,
+	// which only reaches the route groups of its scopes
+	APIKeyID string
+	Scopes   []apikey.Scope
 }
 
 type BaseInput struct {
@@ -0,0 +1,28 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+)
+
+// SampleInput a zero At is the time the sample arrives
+type SampleInput struct {
+	Metric string
+	Value  float64
+	At     time.Time
+}
+
+type IngestInput struct {
+	Samples []SampleInput
+}
+
+// QueryInput zero times and step use the defaults: the last day up to now in
+// buckets of the rollup step. An empty Metrics selects every metric.
+type QueryInput struct {
+	DeviceID device.ID
+	Metrics  []string
+	From     time.Time
+	To       time.Time
+	Step     time.Duration
+}
@@ -0,0 +1,63 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTelemetryRepo struct {
+	mock.Mock
+}
+
+var _ telemetry.Repository = (*MockTelemetryRepo)(nil)
+
+func (m *MockTelemetryRepo) Insert(ctx context.Context, samples []*telemetry.Sample) error {
+	args := m.Called(ctx, samples)
+	return args.Error(0)
+}
+
+func (m *MockTelemetryRepo) Aggregate(ctx context.Context, q telemetry.Query) ([]telemetry.Point, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).([]telemetry.Point), args.Error(1)
+}
+
+func (m *MockTelemetryRepo) Rollup(ctx context.Context, cutoff time.Time, step time.Duration) (int64, error) {
+	args := m.Called(ctx, cutoff, step)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTelemetryRepo) DeleteRollupsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockDeviceRepo struct {
+	device.Repository
+	mock.Mock
+}
+
+func (m *MockDeviceRepo) Find(ctx context.Context, userID user.ID, id device.ID) (*device.Device, error) {
+	args := m.Called(ctx, userID, id)
+	d, _ := args.Get(0).(*device.Device)
+	return d, args.Error(1)
+}
+
+func (m *MockDeviceRepo) FindByID(ctx context.Context, id device.ID) (*device.Device, error) {
+	args := m.Called(ctx, id)
+	d, _ := args.Get(0).(*device.Device)
+	return d, args.Error(1)
+}
+
+// stubFeed records what was published
+type stubFeed struct {
+	published []*telemetry.Sample
+}
+
+func (f *stubFeed) Publish(_ device.ID, samples []*telemetry.Sample) {
+	f.published = append(f.published, samples...)
+}
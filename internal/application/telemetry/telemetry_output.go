@@ -0,0 +1,32 @@
+package telemetry
+
+type IngestOutput struct {
+	Accepted int
+}
+
+type PointItem struct {
+	At    string
+	Count int64
+	Avg   float64
+	Min   float64
+	Max   float64
+}
+
+type SeriesItem struct {
+	Metric string
+	Points []PointItem
+}
+
+// QueryOutput Step is in seconds
+type QueryOutput struct {
+	From   string
+	To     string
+	Step   int64
+	Series []SeriesItem
+}
+
+// CompactOutput RolledUp counts the buckets written, Deleted the buckets past retention
+type CompactOutput struct {
+	RolledUp int64
+	Deleted  int64
+}
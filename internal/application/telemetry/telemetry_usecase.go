@@ -0,0 +1,221 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/devicechannel"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+)
+
+const (
+	defaultRange = 24 * time.Hour
+
+	// maxClockSkew tolerates device clocks running slightly ahead
+	maxClockSkew = time.Minute
+)
+
+// Config Schemas are what each platform may report. Samples older than MaxAge are
+// rejected. Raw samples are rolled up into buckets of RollupStep once older than
+// RawRetention, and the buckets are deleted once older than RollupRetention.
+type Config struct {
+	Schemas         map[device.Platform]telemetry.Schema
+	MaxBatch        int
+	MaxAge          time.Duration
+	MaxPoints       int
+	RollupStep      time.Duration
+	RawRetention    time.Duration
+	RollupRetention time.Duration
+}
+
+// UseCase ingests the telemetry devices report, answers aggregated series to their
+// owners and streams new samples to the owners watching a device.
+type UseCase struct {
+	repo       telemetry.Repository
+	deviceRepo device.Repository
+	feed       devicechannel.Feed
+	conf       Config
+	now        func() time.Time
+}
+
+func NewUseCase(
+	repo telemetry.Repository,
+	deviceRepo device.Repository,
+	feed devicechannel.Feed,
+	conf Config) *UseCase {
+	return &UseCase{
+		repo:       repo,
+		deviceRepo: deviceRepo,
+		feed:       feed,
+		conf:       conf,
+		now:        time.Now,
+	}
+}
+
+// Ingest validates the samples of a device against the schema of its platform and
+// stores them. A batch with an invalid sample is rejected as a whole.
+func (u *UseCase) Ingest(ctx context.Context, deviceID device.ID, input IngestInput) (IngestOutput, error) {
+	if len(input.Samples) == 0 {
+		return IngestOutput{}, telemetry.ErrEmptyBatch
+	}
+	if u.conf.MaxBatch > 0 && len(input.Samples) > u.conf.MaxBatch {
+		return IngestOutput{}, telemetry.ErrBatchTooLarge
+	}
+
+	d, err := u.deviceRepo.FindByID(ctx, deviceID)
+	if err != nil {
+		return IngestOutput{}, err
+	}
+
+	schema, ok := u.conf.Schemas[d.Platform]
+	if !ok {
+		return IngestOutput{}, telemetry.ErrNoSchema
+	}
+
+	now := u.now()
+	samples := make([]*telemetry.Sample, 0, len(input.Samples))
+	for i, in := range input.Samples {
+		at := in.At
+		if at.IsZero() {
+			at = now
+		}
+		if at.After(now.Add(maxClockSkew)) || (u.conf.MaxAge > 0 && at.Before(now.Add(-u.conf.MaxAge))) {
+			return IngestOutput{}, fmt.Errorf("sample %d: %w", i, telemetry.ErrInvalidTime)
+		}
+
+		if err := schema.Validate(in.Metric, in.Value); err != nil {
+			return IngestOutput{}, fmt.Errorf("sample %d: %w", i, err)
+		}
+
+		samples = append(samples, &telemetry.Sample{
+			DeviceID: d.ID,
+			Metric:   in.Metric,
+			Value:    in.Value,
+			At:       at,
+		})
+	}
+
+	if err := u.repo.Insert(ctx, samples); err != nil {
+		return IngestOutput{}, err
+	}
+
+	u.feed.Publish(d.ID, samples)
+
+	return IngestOutput{Accepted: len(samples)}, nil
+}
+
+// Query returns the series of a device of the current user, one per metric
+func (u *UseCase) Query(ctx context.Context, input shared.UseCaseInput[QueryInput]) (QueryOutput, error) {
+	userID, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return QueryOutput{}, user.ErrInvalidUser
+	}
+
+	q := telemetry.Query{
+		DeviceID: input.Data.DeviceID,
+		Metrics:  input.Data.Metrics,
+		From:     input.Data.From,
+		To:       input.Data.To,
+		Step:     input.Data.Step,
+	}
+	if q.To.IsZero() {
+		q.To = u.now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-defaultRange)
+	}
+	if q.Step == 0 {
+		q.Step = u.conf.RollupStep
+	}
+
+	if !q.From.Before(q.To) {
+		return QueryOutput{}, telemetry.ErrInvalidRange
+	}
+	if q.Step < time.Second || q.Step%time.Second != 0 {
+		return QueryOutput{}, telemetry.ErrInvalidStep
+	}
+	if u.conf.MaxPoints > 0 && q.To.Sub(q.From)/q.Step > time.Duration(u.conf.MaxPoints) {
+		return QueryOutput{}, telemetry.ErrTooManyPoints
+	}
+	for _, metric := range q.Metrics {
+		if !telemetry.ValidMetric(metric) {
+			return QueryOutput{}, telemetry.ErrInvalidMetric
+		}
+	}
+
+	if _, err := u.deviceRepo.Find(ctx, userID, q.DeviceID); err != nil {
+		return QueryOutput{}, err
+	}
+
+	points, err := u.repo.Aggregate(ctx, q)
+	if err != nil {
+		return QueryOutput{}, err
+	}
+
+	return QueryOutput{
+		From:   timeutil.Format(q.From, timeutil.FormatISO),
+		To:     timeutil.Format(q.To, timeutil.FormatISO),
+		Step:   int64(q.Step / time.Second),
+		Series: toSeries(points),
+	}, nil
+}
+
+// Watch checks that the user owns the device before streaming its telemetry to them
+func (u *UseCase) Watch(ctx context.Context, userID string, deviceID device.ID) error {
+	id, err := user.ToID(userID)
+	if err != nil {
+		return user.ErrInvalidUser
+	}
+
+	_, err = u.deviceRepo.Find(ctx, id, deviceID)
+	return err
+}
+
+// Compact downsamples the raw samples past their retention and drops the buckets past theirs
+func (u *UseCase) Compact(ctx context.Context) (CompactOutput, error) {
+	now := u.now()
+
+	var output CompactOutput
+	if u.conf.RawRetention > 0 {
+		n, err := u.repo.Rollup(ctx, now.Add(-u.conf.RawRetention), u.conf.RollupStep)
+		if err != nil {
+			return output, err
+		}
+		output.RolledUp = n
+	}
+
+	if u.conf.RollupRetention > 0 {
+		n, err := u.repo.DeleteRollupsBefore(ctx, now.Add(-u.conf.RollupRetention))
+		if err != nil {
+			return output, err
+		}
+		output.Deleted = n
+	}
+
+	return output, nil
+}
+
+// toSeries groups the points, which come ordered by metric and bucket
+func toSeries(points []telemetry.Point) []SeriesItem {
+	series := make([]SeriesItem, 0)
+	for _, p := range points {
+		if len(series) == 0 || series[len(series)-1].Metric != p.Metric {
+			series = append(series, SeriesItem{Metric: p.Metric, Points: make([]PointItem, 0)})
+		}
+
+		last := &series[len(series)-1]
+		last.Points = append(last.Points, PointItem{
+			At:    timeutil.Format(p.Bucket, timeutil.FormatISO),
+			Count: p.Count,
+			Avg:   p.Avg,
+			Min:   p.Min,
+			Max:   p.Max,
+		})
+	}
+	return series
+}
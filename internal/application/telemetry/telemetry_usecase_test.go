@@ -0,0 +1,204 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	testNow  = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	zero     = 0.0
+	hundred  = 100.0
+	testConf = Config{
+		Schemas: map[device.Platform]telemetry.Schema{
+			device.Embedded: {
+				Metrics: map[string]telemetry.Range{"cpu": {Min: &zero, Max: &hundred}},
+				Custom:  true,
+			},
+		},
+		MaxBatch:        3,
+		MaxAge:          time.Hour,
+		MaxPoints:       100,
+		RollupStep:      time.Hour,
+		RawRetention:    24 * time.Hour,
+		RollupRetention: 30 * 24 * time.Hour,
+	}
+)
+
+func newTestUseCase(repo *MockTelemetryRepo, deviceRepo *MockDeviceRepo, feed *stubFeed) *UseCase {
+	uc := NewUseCase(repo, deviceRepo, feed, testConf)
+	uc.now = func() time.Time { return testNow }
+	return uc
+}
+
+func piRepo() *MockDeviceRepo {
+	pi := &device.Device{ID: 3, UserID: 1, Platform: device.Embedded}
+	deviceRepo := new(MockDeviceRepo)
+	deviceRepo.On("FindByID", mock.Anything, device.ID(3)).Return(pi, nil)
+	deviceRepo.On("Find", mock.Anything, user.ID(1), device.ID(3)).Return(pi, nil)
+	deviceRepo.On("Find", mock.Anything, user.ID(2), device.ID(3)).Return(nil, device.ErrDeviceNotFound)
+	return deviceRepo
+}
+
+func queryInput(data QueryInput) shared.UseCaseInput[QueryInput] {
+	return shared.UseCaseInput[QueryInput]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "1"}},
+		Data: data,
+	}
+}
+
+func TestIngest_StoresAndPublishes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	repo := new(MockTelemetryRepo)
+	repo.On("Insert", mock.Anything, mock.MatchedBy(func(samples []*telemetry.Sample) bool {
+		return len(samples) == 2 &&
+			samples[0].DeviceID == 3 && samples[0].At.Equal(testNow) &&
+			samples[1].Metric == "custom.fan_rpm" && samples[1].At.Equal(testNow.Add(-time.Minute))
+	})).Return(nil)
+
+	feed := &stubFeed{}
+	uc := newTestUseCase(repo, piRepo(), feed)
+
+	got, err := uc.Ingest(ctx, 3, IngestInput{Samples: []SampleInput{
+		{Metric: "cpu", Value: 42},
+		{Metric: "custom.fan_rpm", Value: 1200, At: testNow.Add(-time.Minute)},
+	}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, got.Accepted)
+	assert.Len(t, feed.published, 2)
+	repo.AssertExpectations(t)
+}
+
+func TestIngest_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []SampleInput
+		wantErr error
+	}{
+		{"empty", nil, telemetry.ErrEmptyBatch},
+		{"too large", make([]SampleInput, 4), telemetry.ErrBatchTooLarge},
+		{"unknown metric", []SampleInput{{Metric: "humidity", Value: 1}}, telemetry.ErrUnknownMetric},
+		{"out of range", []SampleInput{{Metric: "cpu", Value: 101}}, telemetry.ErrValueOutOfRange},
+		{"invalid metric", []SampleInput{{Metric: "CPU", Value: 1}}, telemetry.ErrInvalidMetric},
+		{"future", []SampleInput{{Metric: "cpu", Value: 1, At: testNow.Add(time.Hour)}}, telemetry.ErrInvalidTime},
+		{"too old", []SampleInput{{Metric: "cpu", Value: 1, At: testNow.Add(-2 * time.Hour)}}, telemetry.ErrInvalidTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockTelemetryRepo)
+			feed := &stubFeed{}
+			uc := newTestUseCase(repo, piRepo(), feed)
+
+			_, err := uc.Ingest(context.Background(), 3, IngestInput{Samples: tt.samples})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, feed.published)
+			repo.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestIngest_NoSchema(t *testing.T) {
+	deviceRepo := new(MockDeviceRepo)
+	deviceRepo.On("FindByID", mock.Anything, device.ID(4)).
+		Return(&device.Device{ID: 4, UserID: 1, Platform: device.Web}, nil)
+
+	uc := newTestUseCase(new(MockTelemetryRepo), deviceRepo, &stubFeed{})
+
+	_, err := uc.Ingest(context.Background(), 4, IngestInput{Samples: []SampleInput{{Metric: "cpu", Value: 1}}})
+
+	assert.ErrorIs(t, err, telemetry.ErrNoSchema)
+}
+
+func TestQuery_GroupsSeries(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	repo := new(MockTelemetryRepo)
+	repo.On("Aggregate", mock.Anything, telemetry.Query{
+		DeviceID: 3,
+		From:     testNow.Add(-24 * time.Hour),
+		To:       testNow,
+		Step:     time.Hour,
+	}).Return([]telemetry.Point{
+		{Metric: "cpu", Bucket: testNow.Add(-2 * time.Hour), Count: 2, Avg: 40, Min: 30, Max: 50},
+		{Metric: "cpu", Bucket: testNow.Add(-time.Hour), Count: 1, Avg: 20, Min: 20, Max: 20},
+		{Metric: "custom.fan_rpm", Bucket: testNow.Add(-time.Hour), Count: 1, Avg: 900, Min: 900, Max: 900},
+	}, nil)
+
+	uc := newTestUseCase(repo, piRepo(), &stubFeed{})
+
+	got, err := uc.Query(ctx, queryInput(QueryInput{DeviceID: 3}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3600), got.Step)
+	if assert.Len(t, got.Series, 2) {
+		assert.Equal(t, "cpu", got.Series[0].Metric)
+		assert.Len(t, got.Series[0].Points, 2)
+		assert.Equal(t, "custom.fan_rpm", got.Series[1].Metric)
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestQuery_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   QueryInput
+		wantErr error
+	}{
+		{"inverted range", QueryInput{DeviceID: 3, From: testNow, To: testNow.Add(-time.Hour)}, telemetry.ErrInvalidRange},
+		{"sub-second step", QueryInput{DeviceID: 3, Step: time.Millisecond}, telemetry.ErrInvalidStep},
+		{"too many points", QueryInput{DeviceID: 3, Step: time.Minute}, telemetry.ErrTooManyPoints},
+		{"invalid metric", QueryInput{DeviceID: 3, Metrics: []string{"CPU"}}, telemetry.ErrInvalidMetric},
+		{"other user's device", QueryInput{DeviceID: 3}, device.ErrDeviceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockTelemetryRepo)
+			uc := newTestUseCase(repo, piRepo(), &stubFeed{})
+
+			input := queryInput(tt.input)
+			if tt.wantErr == device.ErrDeviceNotFound {
+				input.Base.Auth.UserID = "2"
+			}
+			_, err := uc.Query(context.Background(), input)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			repo.AssertNotCalled(t, "Aggregate", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	uc := newTestUseCase(new(MockTelemetryRepo), piRepo(), &stubFeed{})
+
+	assert.NoError(t, uc.Watch(context.Background(), "1", 3))
+	assert.ErrorIs(t, uc.Watch(context.Background(), "2", 3), device.ErrDeviceNotFound)
+}
+
+func TestCompact(t *testing.T) {
+	repo := new(MockTelemetryRepo)
+	repo.On("Rollup", mock.Anything, testNow.Add(-24*time.Hour), time.Hour).Return(int64(5), nil)
+	repo.On("DeleteRollupsBefore", mock.Anything, testNow.Add(-30*24*time.Hour)).Return(int64(2), nil)
+
+	uc := newTestUseCase(repo, piRepo(), &stubFeed{})
+
+	got, err := uc.Compact(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, CompactOutput{RolledUp: 5, Deleted: 2}, got)
+	repo.AssertExpectations(t)
+}
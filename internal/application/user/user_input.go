@@ -32,3 +32,65 @@ type RevokeRoleInput struct {
 	UserID user.ID
 	Role   role.Type
 }
+
+type RevokeSessionInput struct {
+	SessionID string
+}
+
+// ReleaseLoginLockInput the email whose login lockout is lifted
+type ReleaseLoginLockInput struct {
+	Email string
+}
+
+// RefreshTokenInput the refresh token to exchange
+type RefreshTokenInput struct {
+	RefreshToken string
+}
+
+// ApplicationStatusInput the credentials of an applicant checking their registration
+type ApplicationStatusInput struct {
+	Email    string
+	Password string
+}
+
+// ChangeUserStatusInput the user an admin approves, rejects, bans or unbans. Reason is required to reject.
+type ChangeUserStatusInput struct {
+	UserID user.ID
+	Reason string
+}
+
+type QueryStatusHistoryInput struct {
+	UserID user.ID
+}
+
+// EmailInput an email address to mail a link to
+type EmailInput struct {
+	Email string
+}
+
+type VerifyEmailInput struct {
+	Token string
+}
+
+// ResetPasswordInput the mailed reset token and the new password
+type ResetPasswordInput struct {
+	Token    string
+	Password string
+}
+
+// TwoFactorCodeInput a TOTP code, or a recovery code where the action accepts one
+type TwoFactorCodeInput struct {
+	Code string
+}
+
+// LoginTwoFactorInput the challenge token from Login and the second factor
+type LoginTwoFactorInput struct {
+	ChallengeToken string
+	Code           string
+}
+
+// SetRoleTwoFactorInput turns the 2FA requirement of a role on or off
+type SetRoleTwoFactorInput struct {
+	Role     role.Type
+	Required bool
+}
@@ -0,0 +1,41 @@
+package user
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/mail"
+)
+
+// MailConfig where mailed links point to and how long they stay valid
+type MailConfig struct {
+	LinkBaseURL string
+	VerifyTTL   time.Duration
+	ResetTTL    time.Duration
+}
+
+func (c MailConfig) link(path, token string) string {
+	return strings.TrimRight(c.LinkBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func verificationMail(to, link string, ttl time.Duration) mail.Message {
+	return mail.Message{
+		To:      to,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Please verify your email address by opening the link below.\n\n%s\n\nThe link expires in %s. If you did not register, ignore this mail.\n",
+			link, ttl),
+	}
+}
+
+func resetPasswordMail(to, link string, ttl time.Duration) mail.Message {
+	return mail.Message{
+		To:      to,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"A password reset was requested for your account. Open the link below to choose a new password.\n\n%s\n\nThe link expires in %s and works once. All your sessions will be logged out. If you did not ask for this, ignore this mail.\n",
+			link, ttl),
+	}
+}
@@ -2,8 +2,21 @@ package user
 
 import (
 	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/application/shared/security"
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	session "github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/participant"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	domainSecurity "github.com/HiroLiang/goat-server/internal/domain/security"
 
 	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
 	"github.com/HiroLiang/goat-server/internal/domain/user"
 	"github.com/HiroLiang/goat-server/internal/domain/userrole"
 	"github.com/stretchr/testify/mock"
@@ -34,3 +47,224 @@ func (m *MockUserRoleRepo) Revoke(ctx context.Context, userID user.ID, role role
 	args := m.Called(ctx, userID, role)
 	return args.Error(0)
 }
+
+type MockUserRepo struct {
+	mock.Mock
+}
+
+var _ user.Repository = (*MockUserRepo)(nil)
+
+func (m *MockUserRepo) FindByID(ctx context.Context, id user.ID) (*user.User, error) {
+	args := m.Called(ctx, id)
+	u, _ := args.Get(0).(*user.User)
+	return u, args.Error(1)
+}
+
+func (m *MockUserRepo) FindByEmail(ctx context.Context, email user.Email) (*user.User, error) {
+	args := m.Called(ctx, email)
+	u, _ := args.Get(0).(*user.User)
+	return u, args.Error(1)
+}
+
+func (m *MockUserRepo) FindByStatus(ctx context.Context, status user.Status) ([]*user.User, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]*user.User), args.Error(1)
+}
+
+func (m *MockUserRepo) Create(ctx context.Context, u *user.User) error {
+	args := m.Called(ctx, u)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) Update(ctx context.Context, u *user.User) error {
+	args := m.Called(ctx, u)
+	return args.Error(0)
+}
+
+type MockStatusHistoryRepo struct {
+	mock.Mock
+}
+
+var _ user.StatusHistoryRepository = (*MockStatusHistoryRepo)(nil)
+
+func (m *MockStatusHistoryRepo) Create(ctx context.Context, change *user.StatusChange) error {
+	args := m.Called(ctx, change)
+	return args.Error(0)
+}
+
+func (m *MockStatusHistoryRepo) FindByUser(ctx context.Context, userID user.ID) ([]*user.StatusChange, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*user.StatusChange), args.Error(1)
+}
+
+func (m *MockStatusHistoryRepo) FindLatest(ctx context.Context, userID user.ID) (*user.StatusChange, error) {
+	args := m.Called(ctx, userID)
+	c, _ := args.Get(0).(*user.StatusChange)
+	return c, args.Error(1)
+}
+
+type MockTwoFactorRepo struct {
+	mock.Mock
+}
+
+var _ twofactor.Repository = (*MockTwoFactorRepo)(nil)
+
+func (m *MockTwoFactorRepo) Find(ctx context.Context, userID user.ID) (*twofactor.TwoFactor, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*twofactor.TwoFactor), args.Error(1)
+}
+
+func (m *MockTwoFactorRepo) Save(ctx context.Context, t *twofactor.TwoFactor) error {
+	args := m.Called(ctx, t)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepo) Delete(ctx context.Context, userID user.ID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, userID user.ID, hashes []string) error {
+	args := m.Called(ctx, userID, hashes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepo) FindUnusedRecoveryCodes(ctx context.Context, userID user.ID) ([]*twofactor.RecoveryCode, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*twofactor.RecoveryCode), args.Error(1)
+}
+
+func (m *MockTwoFactorRepo) UseRecoveryCode(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockTokenService struct {
+	auth.TokenService
+	mock.Mock
+}
+
+func (m *MockTokenService) Generate(ctx context.Context, params session.CreateSessionParams) (session.TokenPair, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(session.TokenPair), args.Error(1)
+}
+
+func (m *MockTokenService) RevokeAllForUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockTokenService) ListUserSessions(ctx context.Context, userID string) ([]*session.Session, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*session.Session), args.Error(1)
+}
+
+type MockDeviceRepo struct {
+	device.Repository
+	mock.Mock
+}
+
+func (m *MockDeviceRepo) FindByUser(ctx context.Context, userID user.ID) ([]*device.Device, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*device.Device), args.Error(1)
+}
+
+type MockParticipantRepo struct {
+	mock.Mock
+}
+
+var _ participant.Repository = (*MockParticipantRepo)(nil)
+
+func (m *MockParticipantRepo) FindByID(ctx context.Context, id participant.ID) (*participant.Participant, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*participant.Participant), args.Error(1)
+}
+
+func (m *MockParticipantRepo) FindByUserID(ctx context.Context, userID user.ID) (*participant.Participant, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(*participant.Participant), args.Error(1)
+}
+
+func (m *MockParticipantRepo) FindByAgentID(ctx context.Context, agentID agent.ID) (*participant.Participant, error) {
+	args := m.Called(ctx, agentID)
+	return args.Get(0).(*participant.Participant), args.Error(1)
+}
+
+func (m *MockParticipantRepo) FindSystem(ctx context.Context) (*participant.Participant, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*participant.Participant), args.Error(1)
+}
+
+func (m *MockParticipantRepo) Create(ctx context.Context, p *participant.Participant) error {
+	args := m.Called(ctx, p)
+	return args.Error(0)
+}
+
+type MockActionTokens struct {
+	mock.Mock
+}
+
+var _ security.ActionTokenService = (*MockActionTokens)(nil)
+
+func (m *MockActionTokens) Issue(
+	ctx context.Context,
+	purpose domainSecurity.TokenPurpose,
+	subject string,
+	ttl time.Duration,
+) (string, error) {
+	args := m.Called(ctx, purpose, subject, ttl)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockActionTokens) Consume(ctx context.Context, purpose domainSecurity.TokenPurpose, token string) (string, error) {
+	args := m.Called(ctx, purpose, token)
+	return args.String(0), args.Error(1)
+}
+
+type stubHasher struct{}
+
+func (stubHasher) Hash(password string) (string, error) { return "hashed:" + password, nil }
+
+func (stubHasher) Verify(password, hash string) bool { return hash == "hashed:"+password }
+
+func (stubHasher) NeedsRehash(hash string) bool { return !strings.HasPrefix(hash, "hashed:") }
+
+type stubLoginLimiter struct{ security.LoginRateLimiter }
+
+func (stubLoginLimiter) CheckLoginAttempt(context.Context, string, string) error { return nil }
+
+func (stubLoginLimiter) RecordLoginAttempt(context.Context, string, string, bool) error { return nil }
+
+func (stubLoginLimiter) ReleaseLock(context.Context, string) error { return nil }
+
+// stubTxManager runs the function without a transaction
+type stubTxManager struct{}
+
+func (stubTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type stubMailLimiter struct{}
+
+func (stubMailLimiter) CheckMail(context.Context, string) error { return nil }
+
+// stubPolicy grants the same permissions to every user
+type stubPolicy struct {
+	granted []permission.Permission
+}
+
+var _ auth.PolicyChecker = stubPolicy{}
+
+func (s stubPolicy) Permissions(_ context.Context, _ user.ID) ([]permission.Permission, error) {
+	return s.granted, nil
+}
+
+func (s stubPolicy) Authorize(_ context.Context, _ user.ID, perm permission.Permission) error {
+	if !slices.Contains(s.granted, perm) {
+		return permission.ErrDenied
+	}
+	return nil
+}
@@ -1,21 +1,114 @@
 package user
 
-import "github.com/HiroLiang/goat-server/internal/domain/role"
+import (
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
 
-// LoginOutput represents the server's response after a successful login.
-// It contains a token and user details.
+// RegisterOutput VerificationSent is false when the verification mail could not be sent
+type RegisterOutput struct {
+	VerificationSent bool
+}
+
+// LoginOutput represents the server's response after a successful login or token refresh.
+// RefreshToken is empty in opaque token mode. ExpiresIn is the access token lifetime in seconds.
+// With TwoFactorRequired no session exists yet; ChallengeToken must be passed to LoginTwoFactor.
+// TwoFactorSetupRequired means a role of the user requires 2FA the user has not enabled.
 type LoginOutput struct {
-	Token string
+	Token                  string
+	RefreshToken           string
+	ExpiresIn              int
+	TwoFactorRequired      bool
+	ChallengeToken         string
+	TwoFactorSetupRequired bool
 }
 
 // CurrentUserOutput let current user logout
 type CurrentUserOutput struct {
-	ID       int
-	Name     string
-	Email    string
-	CreateAt string
+	ID            int
+	Name          string
+	Email         string
+	CreateAt      string
+	EmailVerified bool
+	Permissions   []permission.Permission
 }
 
 type FindUserRolesOutput struct {
 	Roles []role.Type
 }
+
+// SessionItem is one login of the current user. ID is opaque and never the bearer token.
+type SessionItem struct {
+	ID         string
+	IP         string
+	UserAgent  string
+	Browser    string
+	OS         string
+	Platform   string
+	CreatedAt  string
+	LastSeenAt string
+	Current    bool
+
+	// Registered device of the session, zero when there is none
+	DeviceID       device.ID
+	DeviceName     string
+	DevicePlatform string
+}
+
+type ListSessionsOutput struct {
+	Sessions []SessionItem
+}
+
+// ApplicationStatusOutput the registration status of an applicant. Reason is set once rejected or banned.
+type ApplicationStatusOutput struct {
+	Status    user.Status
+	Reason    string
+	UpdatedAt string
+}
+
+type ApplicantItem struct {
+	ID        user.ID
+	Name      string
+	Email     string
+	IP        string
+	AppliedAt string
+}
+
+type ListApplicantsOutput struct {
+	Applicants []ApplicantItem
+}
+
+// StatusChangeItem one status transition. ActorID 0 means the system.
+type StatusChangeItem struct {
+	From      user.Status
+	To        user.Status
+	Reason    string
+	ActorID   user.ID
+	CreatedAt string
+}
+
+type StatusHistoryOutput struct {
+	Changes []StatusChangeItem
+}
+
+// TwoFactorEnrollmentOutput the secret to add to an authenticator app, also as otpauth:// URI
+type TwoFactorEnrollmentOutput struct {
+	Secret          string
+	ProvisioningURI string
+}
+
+// RecoveryCodesOutput plain recovery codes, shown once
+type RecoveryCodesOutput struct {
+	Codes []string
+}
+
+type RoleItem struct {
+	Type             role.Type
+	RequireTwoFactor bool
+}
+
+type ListRolesOutput struct {
+	Roles []RoleItem
+}
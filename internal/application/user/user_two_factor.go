@@ -0,0 +1,41 @@
+package user
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+)
+
+// TwoFactorConfig how TOTP codes are checked and how long a login challenge stays valid
+type TwoFactorConfig struct {
+	Issuer        string
+	ChallengeTTL  time.Duration
+	Skew          int // accepted time steps before and after the current one
+	RecoveryCodes int
+}
+
+// recoveryCodeLength is long enough that recovery codes never look like TOTP codes
+const recoveryCodeLength = 10
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCodes returns n random codes formatted as "xxxxx-xxxxx"
+func newRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		b := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(recoveryEncoding.EncodeToString(b))[:recoveryCodeLength]
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode drops the separator and case so codes match however they were typed
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -3,48 +3,103 @@ package user
 import (
 	"context"
 	"errors"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/HiroLiang/goat-server/internal/application/shared"
 	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/application/shared/mail"
 	"github.com/HiroLiang/goat-server/internal/application/shared/security"
+	"github.com/HiroLiang/goat-server/internal/application/shared/transaction"
 	session "github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/participant"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
 	"github.com/HiroLiang/goat-server/internal/domain/role"
+	domainSecurity "github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
 	"github.com/HiroLiang/goat-server/internal/domain/user"
 	"github.com/HiroLiang/goat-server/internal/domain/userrole"
 	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+	"github.com/HiroLiang/goat-server/internal/shared/totp"
+	"github.com/HiroLiang/goat-server/internal/shared/useragent"
 )
 
 type UseCase struct {
-	userRepo     user.Repository
-	userRoleRepo userrole.Repository
-	hasher       security.Hasher
-	tokenService auth.TokenService
+	userRepo          user.Repository
+	statusHistoryRepo user.StatusHistoryRepository
+	userRoleRepo      userrole.Repository
+	roleRepo          role.Repository
+	twoFactorRepo     twofactor.Repository
+	deviceRepo        device.Repository
+	participantRepo   participant.Repository
+	txManager         transaction.Manager
+	hasher            security.Hasher
+	tokenService      auth.TokenService
+	policy            auth.PolicyChecker
+	loginLimiter      security.LoginRateLimiter
+	mailer            mail.Mailer
+	actionTokens      security.ActionTokenService
+	mailLimiter       security.MailRateLimiter
+	mailConf          MailConfig
+	twoFactorConf     TwoFactorConfig
 }
 
+var _ auth.RoleChecker = (*UseCase)(nil)
+
 func NewUseCase(
 	repo user.Repository,
+	statusHistoryRepo user.StatusHistoryRepository,
 	userRoleRepo userrole.Repository,
+	roleRepo role.Repository,
+	twoFactorRepo twofactor.Repository,
+	deviceRepo device.Repository,
+	participantRepo participant.Repository,
+	txManager transaction.Manager,
 	hasher security.Hasher,
-	tokenService auth.TokenService) *UseCase {
+	tokenService auth.TokenService,
+	policy auth.PolicyChecker,
+	loginLimiter security.LoginRateLimiter,
+	mailer mail.Mailer,
+	actionTokens security.ActionTokenService,
+	mailLimiter security.MailRateLimiter,
+	mailConf MailConfig,
+	twoFactorConf TwoFactorConfig) *UseCase {
 	return &UseCase{
-		userRepo:     repo,
-		userRoleRepo: userRoleRepo,
-		hasher:       hasher,
-		tokenService: tokenService,
+		userRepo:          repo,
+		statusHistoryRepo: statusHistoryRepo,
+		userRoleRepo:      userRoleRepo,
+		roleRepo:          roleRepo,
+		twoFactorRepo:     twoFactorRepo,
+		deviceRepo:        deviceRepo,
+		participantRepo:   participantRepo,
+		txManager:         txManager,
+		hasher:            hasher,
+		tokenService:      tokenService,
+		policy:            policy,
+		loginLimiter:      loginLimiter,
+		mailer:            mailer,
+		actionTokens:      actionTokens,
+		mailLimiter:       mailLimiter,
+		mailConf:          mailConf,
+		twoFactorConf:     twoFactorConf,
 	}
 }
 
-// Register User register
-func (u *UseCase) Register(ctx context.Context, input shared.UseCaseInput[RegisterInput]) error {
+// Register User register. The account is created even when the verification mail
+// cannot be sent; VerificationSent tells the caller to offer a resend.
+func (u *UseCase) Register(ctx context.Context, input shared.UseCaseInput[RegisterInput]) (RegisterOutput, error) {
 	hash, err := u.hasher.Hash(input.Data.Password)
 	if err != nil {
-		return user.ErrInvalidPassword
+		return RegisterOutput{}, user.ErrInvalidPassword
 	}
 
 	email, err := user.NewEmail(input.Data.Email)
 	if err != nil {
-		return user.ErrInvalidEmail
+		return RegisterOutput{}, user.ErrInvalidEmail
 	}
 
 	newUser := user.NewUser(
@@ -54,11 +109,120 @@ func (u *UseCase) Register(ctx context.Context, input shared.UseCaseInput[Regist
 		input.Base.Request.IP,
 	)
 
-	if err := u.userRepo.Create(ctx, newUser); err != nil {
-		return user.ErrUserAlreadyExists
+	// The user and the participant it chats as exist together or not at all
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// user.ErrUserAlreadyExists when the email is taken, other errors pass
+		// through so a conflict with a concurrent transaction is retried
+		if err := u.userRepo.Create(ctx, newUser); err != nil {
+			return err
+		}
+		return u.participantRepo.Create(ctx, participant.NewUserParticipant(newUser.ID, newUser.Name, ""))
+	})
+	if err != nil {
+		return RegisterOutput{}, err
 	}
 
-	return nil
+	err = u.mailLimiter.CheckMail(ctx, string(email))
+	if err == nil {
+		err = u.sendVerification(ctx, email)
+	}
+
+	return RegisterOutput{VerificationSent: err == nil}, nil
+}
+
+// VerifyEmail marks the email of the token owner as verified
+func (u *UseCase) VerifyEmail(ctx context.Context, input shared.UseCaseInput[VerifyEmailInput]) error {
+	subject, err := u.actionTokens.Consume(ctx, domainSecurity.PurposeVerifyEmail, input.Data.Token)
+	if err != nil {
+		return err
+	}
+
+	target, err := u.userRepo.FindByEmail(ctx, user.Email(subject))
+	if err != nil {
+		return domainSecurity.ErrInvalidActionToken
+	}
+
+	if target.IsEmailVerified() {
+		return nil
+	}
+
+	target.VerifyEmail(time.Now())
+	return u.userRepo.Update(ctx, target)
+}
+
+// ResendVerification mails a new verification link. Unknown or verified emails
+// succeed silently, so the endpoint does not reveal which accounts exist.
+func (u *UseCase) ResendVerification(ctx context.Context, input shared.UseCaseInput[EmailInput]) error {
+	email, err := user.NewEmail(input.Data.Email)
+	if err != nil {
+		return user.ErrInvalidEmail
+	}
+
+	if err := u.mailLimiter.CheckMail(ctx, string(email)); err != nil {
+		return err
+	}
+
+	target, err := u.userRepo.FindByEmail(ctx, email)
+	if err != nil || target.IsEmailVerified() {
+		return nil
+	}
+
+	return u.sendVerification(ctx, email)
+}
+
+// ForgotPassword mails a password reset link. Unknown emails succeed silently.
+func (u *UseCase) ForgotPassword(ctx context.Context, input shared.UseCaseInput[EmailInput]) error {
+	email, err := user.NewEmail(input.Data.Email)
+	if err != nil {
+		return user.ErrInvalidEmail
+	}
+
+	if err := u.mailLimiter.CheckMail(ctx, string(email)); err != nil {
+		return err
+	}
+
+	if _, err := u.userRepo.FindByEmail(ctx, email); err != nil {
+		return nil
+	}
+
+	token, err := u.actionTokens.Issue(ctx, domainSecurity.PurposeResetPassword, string(email), u.mailConf.ResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := u.mailConf.link("/reset-password", token)
+	return u.mailer.Send(ctx, resetPasswordMail(string(email), link, u.mailConf.ResetTTL))
+}
+
+// ResetPassword sets a new password with a mailed reset token and logs out every session.
+// The mailed link also proves the email, and lifts any login lockout.
+func (u *UseCase) ResetPassword(ctx context.Context, input shared.UseCaseInput[ResetPasswordInput]) error {
+	subject, err := u.actionTokens.Consume(ctx, domainSecurity.PurposeResetPassword, input.Data.Token)
+	if err != nil {
+		return err
+	}
+
+	target, err := u.userRepo.FindByEmail(ctx, user.Email(subject))
+	if err != nil {
+		return domainSecurity.ErrInvalidActionToken
+	}
+
+	hash, err := u.hasher.Hash(input.Data.Password)
+	if err != nil {
+		return user.ErrInvalidPassword
+	}
+
+	target.Password = hash
+	target.VerifyEmail(time.Now())
+	if err := u.userRepo.Update(ctx, target); err != nil {
+		return err
+	}
+
+	if err := u.tokenService.RevokeAllForUser(ctx, strconv.FormatInt(int64(target.ID), 10)); err != nil {
+		return err
+	}
+
+	return u.loginLimiter.ReleaseLock(ctx, subject)
 }
 
 // Login User login
@@ -70,9 +234,18 @@ func (u *UseCase) Login(ctx context.Context, input shared.UseCaseInput[LoginInpu
 		return LoginOutput{}, user.ErrInvalidEmail
 	}
 
+	// Reject locked out email or IP before touching the password
+	ip := input.Base.Request.IP
+	if err := u.loginLimiter.CheckLoginAttempt(ctx, ip, string(email)); err != nil {
+		return LoginOutput{}, err
+	}
+
 	// Check is user exists
 	currentUser, err := u.userRepo.FindByEmail(ctx, email)
 	if err != nil {
+		if err := u.loginLimiter.RecordLoginAttempt(ctx, ip, string(email), false); err != nil {
+			return LoginOutput{}, err
+		}
 		return LoginOutput{}, user.ErrUserNotFound
 	}
 
@@ -83,26 +256,245 @@ func (u *UseCase) Login(ctx context.Context, input shared.UseCaseInput[LoginInpu
 		return LoginOutput{}, user.ErrUserApplying
 	case user.Banned:
 		return LoginOutput{}, user.ErrUserBanned
+	case user.Rejected:
+		return LoginOutput{}, user.ErrUserRejected
 	default:
 		return LoginOutput{}, user.ErrInvalidUser
 	}
 
 	// Check password
 	if !u.hasher.Verify(input.Data.Password, currentUser.Password) {
+		if err := u.loginLimiter.RecordLoginAttempt(ctx, ip, string(email), false); err != nil {
+			return LoginOutput{}, err
+		}
 		return LoginOutput{}, user.ErrInvalidPassword
 	}
 
-	// Generate auth token and store in redis
-	authToken, err := u.tokenService.Generate(ctx, session.CreateSessionParams{
-		UserID:    strconv.FormatInt(int64(currentUser.ID), 10),
-		IP:        input.Base.Request.IP,
-		UserAgent: "",
-	})
+	u.upgradePasswordHash(ctx, currentUser, input.Data.Password)
+
+	// With 2FA the failures are only reset once the second factor passed too
+	enrollment, err := u.twoFactorRepo.Find(ctx, currentUser.ID)
+	if err != nil && !errors.Is(err, twofactor.ErrNotEnrolled) {
+		return LoginOutput{}, err
+	}
+	if enrollment != nil && enrollment.IsEnabled() {
+		challenge, err := u.actionTokens.Issue(
+			ctx,
+			domainSecurity.PurposeLoginChallenge,
+			strconv.FormatInt(int64(currentUser.ID), 10),
+			u.twoFactorConf.ChallengeTTL,
+		)
+		if err != nil {
+			return LoginOutput{}, user.ErrGenerateToken
+		}
+		return LoginOutput{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	if err := u.loginLimiter.RecordLoginAttempt(ctx, ip, string(email), true); err != nil {
+		return LoginOutput{}, err
+	}
+
+	output, err := u.issueSession(ctx, input.Base, currentUser)
 	if err != nil {
-		return LoginOutput{}, user.ErrGenerateToken
+		return LoginOutput{}, err
 	}
 
-	return LoginOutput{Token: authToken}, nil
+	output.TwoFactorSetupRequired, err = u.twoFactorSetupRequired(ctx, currentUser.ID)
+	if err != nil {
+		return LoginOutput{}, err
+	}
+
+	return output, nil
+}
+
+// LoginTwoFactor finishes a login that returned a challenge, with a TOTP or recovery code.
+// The challenge works once, so a wrong code means logging in with the password again.
+func (u *UseCase) LoginTwoFactor(
+	ctx context.Context,
+	input shared.UseCaseInput[LoginTwoFactorInput]) (LoginOutput, error) {
+	subject, err := u.actionTokens.Consume(ctx, domainSecurity.PurposeLoginChallenge, input.Data.ChallengeToken)
+	if err != nil {
+		return LoginOutput{}, err
+	}
+
+	id, err := user.ToID(subject)
+	if err != nil {
+		return LoginOutput{}, domainSecurity.ErrInvalidActionToken
+	}
+
+	currentUser, err := u.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return LoginOutput{}, user.ErrUserNotFound
+	}
+	if currentUser.Status != user.Active {
+		return LoginOutput{}, user.ErrInvalidUser
+	}
+
+	ip := input.Base.Request.IP
+	email := string(currentUser.Email)
+	if err := u.loginLimiter.CheckLoginAttempt(ctx, ip, email); err != nil {
+		return LoginOutput{}, err
+	}
+
+	enrollment, err := u.twoFactorRepo.Find(ctx, id)
+	if err != nil {
+		return LoginOutput{}, err
+	}
+	if !enrollment.IsEnabled() {
+		return LoginOutput{}, twofactor.ErrNotEnabled
+	}
+
+	if err := u.verifySecondFactor(ctx, enrollment, input.Data.Code); err != nil {
+		if errors.Is(err, twofactor.ErrInvalidCode) {
+			if err := u.loginLimiter.RecordLoginAttempt(ctx, ip, email, false); err != nil {
+				return LoginOutput{}, err
+			}
+		}
+		return LoginOutput{}, err
+	}
+
+	if err := u.loginLimiter.RecordLoginAttempt(ctx, ip, email, true); err != nil {
+		return LoginOutput{}, err
+	}
+
+	return u.issueSession(ctx, input.Base, currentUser)
+}
+
+// EnrollTwoFactor starts a TOTP enrollment of the current user, replacing a pending one.
+// Only accounts with the admin or vendor role, or a role requiring 2FA, may enroll.
+func (u *UseCase) EnrollTwoFactor(
+	ctx context.Context,
+	input shared.UseCaseInput[struct{}]) (TwoFactorEnrollmentOutput, error) {
+	id, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return TwoFactorEnrollmentOutput{}, user.ErrInvalidUser
+	}
+
+	currentUser, err := u.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return TwoFactorEnrollmentOutput{}, user.ErrUserNotFound
+	}
+
+	if err := u.requireTwoFactorEligible(ctx, id); err != nil {
+		return TwoFactorEnrollmentOutput{}, err
+	}
+
+	existing, err := u.twoFactorRepo.Find(ctx, id)
+	if err != nil && !errors.Is(err, twofactor.ErrNotEnrolled) {
+		return TwoFactorEnrollmentOutput{}, err
+	}
+	if existing != nil && existing.IsEnabled() {
+		return TwoFactorEnrollmentOutput{}, twofactor.ErrAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return TwoFactorEnrollmentOutput{}, err
+	}
+
+	if err := u.twoFactorRepo.Save(ctx, twofactor.New(id, secret)); err != nil {
+		return TwoFactorEnrollmentOutput{}, err
+	}
+
+	return TwoFactorEnrollmentOutput{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(secret, u.twoFactorConf.Issuer, string(currentUser.Email)),
+	}, nil
+}
+
+// ConfirmTwoFactor enables a pending enrollment with a first TOTP code and returns the recovery codes
+func (u *UseCase) ConfirmTwoFactor(
+	ctx context.Context,
+	input shared.UseCaseInput[TwoFactorCodeInput]) (RecoveryCodesOutput, error) {
+	id, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return RecoveryCodesOutput{}, user.ErrInvalidUser
+	}
+
+	enrollment, err := u.twoFactorRepo.Find(ctx, id)
+	if err != nil {
+		return RecoveryCodesOutput{}, err
+	}
+	if enrollment.IsEnabled() {
+		return RecoveryCodesOutput{}, twofactor.ErrAlreadyEnabled
+	}
+
+	now := time.Now()
+	step, ok := totp.Validate(enrollment.Secret, input.Data.Code, now, u.twoFactorConf.Skew)
+	if !ok {
+		return RecoveryCodesOutput{}, twofactor.ErrInvalidCode
+	}
+	if err := enrollment.UseStep(step); err != nil {
+		return RecoveryCodesOutput{}, err
+	}
+	if err := enrollment.Enable(now); err != nil {
+		return RecoveryCodesOutput{}, err
+	}
+
+	codes, err := newRecoveryCodes(u.twoFactorConf.RecoveryCodes)
+	if err != nil {
+		return RecoveryCodesOutput{}, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hash, err := u.hasher.Hash(normalizeRecoveryCode(code))
+		if err != nil {
+			return RecoveryCodesOutput{}, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	// Store the codes first, an enabled enrollment must never lack them
+	if err := u.twoFactorRepo.ReplaceRecoveryCodes(ctx, id, hashes); err != nil {
+		return RecoveryCodesOutput{}, err
+	}
+	if err := u.twoFactorRepo.Save(ctx, enrollment); err != nil {
+		return RecoveryCodesOutput{}, err
+	}
+
+	return RecoveryCodesOutput{Codes: codes}, nil
+}
+
+// DisableTwoFactor removes the 2FA of the current user after checking a TOTP or recovery code
+func (u *UseCase) DisableTwoFactor(ctx context.Context, input shared.UseCaseInput[TwoFactorCodeInput]) error {
+	id, err := user.ToID(input.Base.Auth.UserID)
+	if err != nil {
+		return user.ErrInvalidUser
+	}
+
+	enrollment, err := u.twoFactorRepo.Find(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !enrollment.IsEnabled() {
+		return twofactor.ErrNotEnabled
+	}
+
+	if err := u.verifySecondFactor(ctx, enrollment, input.Data.Code); err != nil {
+		return err
+	}
+
+	return u.twoFactorRepo.Delete(ctx, id)
+}
+
+// RefreshToken exchanges a refresh token for a new token pair
+func (u *UseCase) RefreshToken(
+	ctx context.Context,
+	input shared.UseCaseInput[RefreshTokenInput]) (LoginOutput, error) {
+	pair, err := u.tokenService.Refresh(ctx, input.Data.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, session.ErrTokenReused), errors.Is(err, session.ErrInvalidToken):
+			return LoginOutput{}, err
+		case errors.Is(err, session.ErrSessionNotFound):
+			return LoginOutput{}, session.ErrInvalidToken
+		default:
+			return LoginOutput{}, user.ErrGenerateToken
+		}
+	}
+
+	return toLoginOutput(pair), nil
 }
 
 // Logout User logout
@@ -124,11 +516,18 @@ func (u *UseCase) CurrentUserInfo(
 		return CurrentUserOutput{}, user.ErrUserNotFound
 	}
 
+	permissions, err := u.policy.Permissions(ctx, id)
+	if err != nil {
+		return CurrentUserOutput{}, err
+	}
+
 	return CurrentUserOutput{
-		ID:       int(domainUser.ID),
-		Name:     domainUser.Name,
-		Email:    string(domainUser.Email),
-		CreateAt: timeutil.Format(domainUser.CreatedAt, "2006/01/02 15:04:05"),
+		ID:            int(domainUser.ID),
+		Name:          domainUser.Name,
+		Email:         string(domainUser.Email),
+		CreateAt:      timeutil.Format(domainUser.CreatedAt, "2006/01/02 15:04:05"),
+		EmailVerified: domainUser.IsEmailVerified(),
+		Permissions:   permissions,
 	}, nil
 }
 
@@ -141,13 +540,33 @@ func (u *UseCase) FindRolesByUser(
 		return FindUserRolesOutput{}, err
 	}
 
-	types := make([]role.Type, len(roles))
+	types := make([]role.Type, 0, len(roles))
 	for _, r := range roles {
 		types = append(types, r.Type)
 	}
 
 	return FindUserRolesOutput{Roles: types}, nil
+}
+
+// MatchRole returns the role of the user that matches one of roles, user.ErrForbidden if none does
+func (u *UseCase) MatchRole(ctx context.Context, userID string, roles ...role.Type) (*role.Role, error) {
+	id, err := user.ToID(userID)
+	if err != nil {
+		return nil, user.ErrInvalidUser
+	}
+
+	held, err := u.userRoleRepo.FindRolesByUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
+	for _, r := range held {
+		if slices.Contains(roles, r.Type) {
+			return r, nil
+		}
+	}
+
+	return nil, user.ErrForbidden
 }
 
 // AssignRoleToUser Assign a role to the user
@@ -171,3 +590,468 @@ func (u *UseCase) RevokeRoleFromUser(ctx context.Context, input shared.UseCaseIn
 	}
 	return nil
 }
+
+// ListRoles lists every role with its 2FA requirement. Requires user:manage_roles.
+func (u *UseCase) ListRoles(
+	ctx context.Context,
+	input shared.UseCaseInput[struct{}]) (ListRolesOutput, error) {
+	if _, err := u.authorize(ctx, input.Base, permission.UserManageRoles); err != nil {
+		return ListRolesOutput{}, err
+	}
+
+	roles, err := u.roleRepo.FindAll(ctx)
+	if err != nil {
+		return ListRolesOutput{}, err
+	}
+
+	items := make([]RoleItem, 0, len(roles))
+	for _, r := range roles {
+		items = append(items, RoleItem{Type: r.Type, RequireTwoFactor: r.RequireTwoFactor})
+	}
+
+	return ListRolesOutput{Roles: items}, nil
+}
+
+// SetRoleTwoFactor requires 2FA from the members of a role, or stops requiring it.
+// Members without 2FA lose the permissions of the role until they enroll.
+// Requires user:manage_roles.
+func (u *UseCase) SetRoleTwoFactor(ctx context.Context, input shared.UseCaseInput[SetRoleTwoFactorInput]) error {
+	if _, err := u.authorize(ctx, input.Base, permission.UserManageRoles); err != nil {
+		return err
+	}
+
+	return u.roleRepo.SetRequireTwoFactor(ctx, input.Data.Role, input.Data.Required)
+}
+
+// ListSessions lists the active sessions of the current user, most recently used first.
+// Sessions of a registered device carry its name.
+func (u *UseCase) ListSessions(
+	ctx context.Context,
+	input shared.UseCaseInput[struct{}]) (ListSessionsOutput, error) {
+	sessions, err := u.tokenService.ListUserSessions(ctx, input.Base.Auth.UserID)
+	if err != nil {
+		return ListSessionsOutput{}, err
+	}
+
+	bySession, byDeviceID, err := u.sessionDevices(ctx, input.Base.Auth.UserID)
+	if err != nil {
+		return ListSessionsOutput{}, err
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	items := make([]SessionItem, 0, len(sessions))
+	for _, s := range sessions {
+		client := useragent.Parse(s.UserAgent)
+		item := SessionItem{
+			ID:         s.ID,
+			IP:         s.IP,
+			UserAgent:  s.UserAgent,
+			Browser:    client.Browser,
+			OS:         client.OS,
+			Platform:   string(client.Platform),
+			CreatedAt:  timeutil.Format(s.CreatedAt, timeutil.FormatISO),
+			LastSeenAt: timeutil.Format(s.LastSeenAt, timeutil.FormatISO),
+			Current:    s.ID == input.Base.Auth.SessionID,
+		}
+
+		// A device is bound to the session that registered it, and to sessions logged in with its device ID
+		d, ok := bySession[s.ID]
+		if !ok && s.DeviceID != "" {
+			d, ok = byDeviceID[s.DeviceID]
+		}
+		if ok {
+			item.DeviceID = d.ID
+			item.DeviceName = d.Name
+			item.DevicePlatform = string(d.Platform)
+		}
+
+		items = append(items, item)
+	}
+
+	return ListSessionsOutput{Sessions: items}, nil
+}
+
+// RevokeSession logs out one session of the current user. Revoking the current session equals Logout.
+func (u *UseCase) RevokeSession(ctx context.Context, input shared.UseCaseInput[RevokeSessionInput]) error {
+	return u.tokenService.RevokeSession(ctx, input.Base.Auth.UserID, input.Data.SessionID)
+}
+
+// RevokeOtherSessions logs out every session of the current user except the current one.
+func (u *UseCase) RevokeOtherSessions(ctx context.Context, input shared.UseCaseInput[struct{}]) error {
+	if input.Base.Auth.SessionID == "" {
+		return session.ErrSessionNotFound
+	}
+	return u.tokenService.RevokeOtherSessions(ctx, input.Base.Auth.UserID, input.Base.Auth.SessionID)
+}
+
+// ReleaseLoginLock lifts the login lockout of an email. Requires user:unlock.
+func (u *UseCase) ReleaseLoginLock(ctx context.Context, input shared.UseCaseInput[ReleaseLoginLockInput]) error {
+	if _, err := u.authorize(ctx, input.Base, permission.UserUnlock); err != nil {
+		return err
+	}
+
+	email, err := user.NewEmail(input.Data.Email)
+	if err != nil {
+		return user.ErrInvalidEmail
+	}
+
+	return u.loginLimiter.ReleaseLock(ctx, string(email))
+}
+
+// ApplicationStatus lets an applicant, who cannot log in yet, check their registration with their credentials.
+func (u *UseCase) ApplicationStatus(
+	ctx context.Context,
+	input shared.UseCaseInput[ApplicationStatusInput]) (ApplicationStatusOutput, error) {
+	email, err := user.NewEmail(input.Data.Email)
+	if err != nil {
+		return ApplicationStatusOutput{}, user.ErrInvalidEmail
+	}
+
+	// Same lockout as login, so this is no password oracle
+	ip := input.Base.Request.IP
+	if err := u.loginLimiter.CheckLoginAttempt(ctx, ip, string(email)); err != nil {
+		return ApplicationStatusOutput{}, err
+	}
+
+	applicant, err := u.userRepo.FindByEmail(ctx, email)
+	if err != nil || !u.hasher.Verify(input.Data.Password, applicant.Password) {
+		if err := u.loginLimiter.RecordLoginAttempt(ctx, ip, string(email), false); err != nil {
+			return ApplicationStatusOutput{}, err
+		}
+		return ApplicationStatusOutput{}, user.ErrInvalidPassword
+	}
+
+	output := ApplicationStatusOutput{
+		Status:    applicant.Status,
+		UpdatedAt: timeutil.Format(applicant.UpdatedAt, timeutil.FormatISO),
+	}
+
+	latest, err := u.statusHistoryRepo.FindLatest(ctx, applicant.ID)
+	switch {
+	case errors.Is(err, user.ErrStatusChangeNotFound):
+	case err != nil:
+		return ApplicationStatusOutput{}, err
+	case latest.To == applicant.Status:
+		output.Reason = latest.Reason
+		output.UpdatedAt = timeutil.Format(latest.CreatedAt, timeutil.FormatISO)
+	}
+
+	return output, nil
+}
+
+// ListApplicants lists the users waiting for approval, oldest first. Requires user:approve.
+func (u *UseCase) ListApplicants(
+	ctx context.Context,
+	input shared.UseCaseInput[struct{}]) (ListApplicantsOutput, error) {
+	if _, err := u.authorize(ctx, input.Base, permission.UserApprove); err != nil {
+		return ListApplicantsOutput{}, err
+	}
+
+	applicants, err := u.userRepo.FindByStatus(ctx, user.Applying)
+	if err != nil {
+		return ListApplicantsOutput{}, err
+	}
+
+	items := make([]ApplicantItem, 0, len(applicants))
+	for _, a := range applicants {
+		items = append(items, ApplicantItem{
+			ID:        a.ID,
+			Name:      a.Name,
+			Email:     string(a.Email),
+			IP:        a.LastIP,
+			AppliedAt: timeutil.Format(a.CreatedAt, timeutil.FormatISO),
+		})
+	}
+
+	return ListApplicantsOutput{Applicants: items}, nil
+}
+
+// ApproveUser activates an applicant and assigns the default user role. Requires user:approve.
+func (u *UseCase) ApproveUser(ctx context.Context, input shared.UseCaseInput[ChangeUserStatusInput]) error {
+	target, err := u.changeStatus(ctx, input, permission.UserApprove, (*user.User).Approve)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRoleRepo.Assign(ctx, target.ID, role.User); err != nil &&
+		!errors.Is(err, userrole.ErrUserRoleAlreadyAssigned) {
+		return userrole.ErrAssignFailed
+	}
+
+	return nil
+}
+
+// RejectUser declines an applicant with a reason the applicant can read. Requires user:approve.
+func (u *UseCase) RejectUser(ctx context.Context, input shared.UseCaseInput[ChangeUserStatusInput]) error {
+	if strings.TrimSpace(input.Data.Reason) == "" {
+		return user.ErrReasonRequired
+	}
+
+	_, err := u.changeStatus(ctx, input, permission.UserApprove, (*user.User).Reject)
+	return err
+}
+
+// BanUser blocks a user and logs out all of their sessions. Requires user:ban.
+func (u *UseCase) BanUser(ctx context.Context, input shared.UseCaseInput[ChangeUserStatusInput]) error {
+	target, err := u.changeStatus(ctx, input, permission.UserBan, (*user.User).Ban)
+	if err != nil {
+		return err
+	}
+
+	return u.tokenService.RevokeAllForUser(ctx, strconv.FormatInt(int64(target.ID), 10))
+}
+
+// UnbanUser lifts the ban of a user, restoring the status they had before. Requires user:ban.
+func (u *UseCase) UnbanUser(ctx context.Context, input shared.UseCaseInput[ChangeUserStatusInput]) error {
+	_, err := u.changeStatus(ctx, input, permission.UserBan, func(target *user.User) error {
+		previous, err := u.statusBeforeBan(ctx, target)
+		if err != nil {
+			return err
+		}
+		return target.Unban(previous)
+	})
+	return err
+}
+
+// StatusHistory lists the status transitions of a user, newest first. Requires user:approve.
+func (u *UseCase) StatusHistory(
+	ctx context.Context,
+	input shared.UseCaseInput[QueryStatusHistoryInput]) (StatusHistoryOutput, error) {
+	if _, err := u.authorize(ctx, input.Base, permission.UserApprove); err != nil {
+		return StatusHistoryOutput{}, err
+	}
+
+	if _, err := u.userRepo.FindByID(ctx, input.Data.UserID); err != nil {
+		return StatusHistoryOutput{}, user.ErrUserNotFound
+	}
+
+	changes, err := u.statusHistoryRepo.FindByUser(ctx, input.Data.UserID)
+	if err != nil {
+		return StatusHistoryOutput{}, err
+	}
+
+	items := make([]StatusChangeItem, 0, len(changes))
+	for _, c := range changes {
+		items = append(items, StatusChangeItem{
+			From:      c.From,
+			To:        c.To,
+			Reason:    c.Reason,
+			ActorID:   c.ActorID,
+			CreatedAt: timeutil.Format(c.CreatedAt, timeutil.FormatISO),
+		})
+	}
+
+	return StatusHistoryOutput{Changes: items}, nil
+}
+
+// upgradePasswordHash rehashes a verified password stored with outdated parameters.
+// Failures are ignored: the old hash keeps working and is upgraded on a later login.
+func (u *UseCase) upgradePasswordHash(ctx context.Context, target *user.User, password string) {
+	if !u.hasher.NeedsRehash(target.Password) {
+		return
+	}
+
+	hash, err := u.hasher.Hash(password)
+	if err != nil {
+		return
+	}
+
+	target.Password = hash
+	_ = u.userRepo.Update(ctx, target)
+}
+
+// sendVerification issues a verification token and mails the link
+func (u *UseCase) sendVerification(ctx context.Context, email user.Email) error {
+	token, err := u.actionTokens.Issue(ctx, domainSecurity.PurposeVerifyEmail, string(email), u.mailConf.VerifyTTL)
+	if err != nil {
+		return err
+	}
+
+	link := u.mailConf.link("/verify-email", token)
+	return u.mailer.Send(ctx, verificationMail(string(email), link, u.mailConf.VerifyTTL))
+}
+
+// changeStatus applies the transition to the target user and records it with the acting admin.
+func (u *UseCase) changeStatus(
+	ctx context.Context,
+	input shared.UseCaseInput[ChangeUserStatusInput],
+	perm permission.Permission,
+	transition func(*user.User) error,
+) (*user.User, error) {
+	actorID, err := u.authorize(ctx, input.Base, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := u.userRepo.FindByID(ctx, input.Data.UserID)
+	if err != nil {
+		return nil, user.ErrUserNotFound
+	}
+
+	from := target.Status
+	if err := transition(target); err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.Update(ctx, target); err != nil {
+		return nil, err
+	}
+
+	change := user.NewStatusChange(target.ID, from, target.Status, strings.TrimSpace(input.Data.Reason), actorID)
+	if err := u.statusHistoryRepo.Create(ctx, change); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
+
+// statusBeforeBan looks up the status a banned user had from the change that banned them.
+// It is empty when unknown, which Unban treats as active.
+func (u *UseCase) statusBeforeBan(ctx context.Context, target *user.User) (user.Status, error) {
+	if target.Status != user.Banned {
+		return "", nil
+	}
+
+	latest, err := u.statusHistoryRepo.FindLatest(ctx, target.ID)
+	switch {
+	case errors.Is(err, user.ErrStatusChangeNotFound):
+		return "", nil
+	case err != nil:
+		return "", err
+	case latest.To != user.Banned:
+		return "", nil
+	}
+
+	return latest.From, nil
+}
+
+// authorize returns the ID of the current user if they hold perm
+func (u *UseCase) authorize(ctx context.Context, base shared.BaseInput, perm permission.Permission) (user.ID, error) {
+	id, err := user.ToID(base.Auth.UserID)
+	if err != nil {
+		return 0, user.ErrInvalidUser
+	}
+
+	err = u.policy.Authorize(ctx, id, perm)
+	if errors.Is(err, permission.ErrDenied) {
+		return 0, user.ErrForbidden
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// issueSession creates the session of a user that passed every login factor
+func (u *UseCase) issueSession(ctx context.Context, base shared.BaseInput, target *user.User) (LoginOutput, error) {
+	pair, err := u.tokenService.Generate(ctx, session.CreateSessionParams{
+		UserID:    strconv.FormatInt(int64(target.ID), 10),
+		IP:        base.Request.IP,
+		UserAgent: base.Request.UserAgent,
+		DeviceID:  base.Request.DeviceID,
+	})
+	if err != nil {
+		return LoginOutput{}, user.ErrGenerateToken
+	}
+
+	return toLoginOutput(pair), nil
+}
+
+// verifySecondFactor accepts a TOTP code once, or else an unused recovery code
+func (u *UseCase) verifySecondFactor(ctx context.Context, enrollment *twofactor.TwoFactor, code string) error {
+	if step, ok := totp.Validate(enrollment.Secret, code, time.Now(), u.twoFactorConf.Skew); ok {
+		if err := enrollment.UseStep(step); err != nil {
+			return err
+		}
+		return u.twoFactorRepo.Save(ctx, enrollment)
+	}
+
+	// Only codes shaped like recovery codes are hashed, each check runs the password hasher
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return twofactor.ErrInvalidCode
+	}
+
+	codes, err := u.twoFactorRepo.FindUnusedRecoveryCodes(ctx, enrollment.UserID)
+	if err != nil {
+		return err
+	}
+	for _, recovery := range codes {
+		if u.hasher.Verify(normalized, recovery.Hash) {
+			return u.twoFactorRepo.UseRecoveryCode(ctx, recovery.ID)
+		}
+	}
+
+	return twofactor.ErrInvalidCode
+}
+
+// requireTwoFactorEligible returns twofactor.ErrNotAllowed unless the user is an admin or
+// vendor, or holds a role that requires 2FA
+func (u *UseCase) requireTwoFactorEligible(ctx context.Context, id user.ID) error {
+	roles, err := u.userRoleRepo.FindRolesByUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range roles {
+		if r.Type == role.Admin || r.Type == role.Vendor || r.RequireTwoFactor {
+			return nil
+		}
+	}
+	return twofactor.ErrNotAllowed
+}
+
+// twoFactorSetupRequired reports whether a role of the user requires 2FA the user has not enabled
+func (u *UseCase) twoFactorSetupRequired(ctx context.Context, id user.ID) (bool, error) {
+	roles, err := u.userRoleRepo.FindRolesByUser(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if !slices.ContainsFunc(roles, func(r *role.Role) bool { return r.RequireTwoFactor }) {
+		return false, nil
+	}
+
+	enrollment, err := u.twoFactorRepo.Find(ctx, id)
+	if errors.Is(err, twofactor.ErrNotEnrolled) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !enrollment.IsEnabled(), nil
+}
+
+// sessionDevices indexes the devices of the user by the session that registered them and by device ID
+func (u *UseCase) sessionDevices(
+	ctx context.Context,
+	userID string) (bySession, byDeviceID map[string]*device.Device, err error) {
+	id, err := user.ToID(userID)
+	if err != nil {
+		return nil, nil, user.ErrInvalidUser
+	}
+
+	devices, err := u.deviceRepo.FindByUser(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bySession = make(map[string]*device.Device, len(devices))
+	byDeviceID = make(map[string]*device.Device, len(devices))
+	for _, d := range devices {
+		bySession[d.SessionID] = d
+		byDeviceID[d.DeviceID] = d
+	}
+	return bySession, byDeviceID, nil
+}
+
+func toLoginOutput(pair session.TokenPair) LoginOutput {
+	return LoginOutput{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    int(pair.ExpiresIn.Seconds()),
+	}
+}
@@ -7,9 +7,16 @@ import (
 	"time"
 
 	"github.com/HiroLiang/goat-server/internal/application/shared"
+	session "github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/participant"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
 	"github.com/HiroLiang/goat-server/internal/domain/role"
+	domainSecurity "github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
 	"github.com/HiroLiang/goat-server/internal/domain/user"
 	"github.com/HiroLiang/goat-server/internal/domain/userrole"
+	"github.com/HiroLiang/goat-server/internal/shared/totp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -82,3 +89,526 @@ func TestAssignRoleToUser_RepoError(t *testing.T) {
 
 	assert.ErrorIs(t, err, userrole.ErrAssignFailed)
 }
+
+func TestMatchRole_ReturnsHeldRoleOrForbidden(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	repo := new(MockUserRoleRepo)
+	repo.
+		On("FindRolesByUser", mock.Anything, user.ID(1)).
+		Return([]*role.Role{{ID: 3, Type: role.User}, {ID: 2, Type: role.Vendor}}, nil)
+
+	uc := &UseCase{userRoleRepo: repo}
+
+	matched, err := uc.MatchRole(ctx, "1", role.Admin, role.Vendor)
+	assert.NoError(t, err)
+	assert.Equal(t, role.Vendor, matched.Type)
+
+	_, err = uc.MatchRole(ctx, "1", role.Admin)
+	assert.ErrorIs(t, err, user.ErrForbidden)
+
+	_, err = uc.MatchRole(ctx, "not-a-number", role.Admin)
+	assert.ErrorIs(t, err, user.ErrInvalidUser)
+}
+
+func adminInput[T any](data T) shared.UseCaseInput[T] {
+	return shared.UseCaseInput[T]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "9"}},
+		Data: data,
+	}
+}
+
+func TestApproveUser_ActivatesAndRecords(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	roleRepo := new(MockUserRoleRepo)
+	roleRepo.On("Assign", mock.Anything, user.ID(1), role.User).Return(nil)
+
+	applicant := &user.User{ID: 1, Status: user.Applying}
+	userRepo := new(MockUserRepo)
+	userRepo.On("FindByID", mock.Anything, user.ID(1)).Return(applicant, nil)
+	userRepo.On("Update", mock.Anything, applicant).Return(nil)
+
+	historyRepo := new(MockStatusHistoryRepo)
+	historyRepo.On("Create", mock.Anything, mock.MatchedBy(func(c *user.StatusChange) bool {
+		return c.UserID == 1 && c.From == user.Applying && c.To == user.Active && c.ActorID == 9
+	})).Return(nil)
+
+	uc := &UseCase{
+		userRepo:          userRepo,
+		statusHistoryRepo: historyRepo,
+		userRoleRepo:      roleRepo,
+		policy:            stubPolicy{granted: []permission.Permission{permission.UserApprove}},
+	}
+
+	err := uc.ApproveUser(ctx, adminInput(ChangeUserStatusInput{UserID: 1}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, user.Active, applicant.Status)
+	roleRepo.AssertExpectations(t)
+	historyRepo.AssertExpectations(t)
+}
+
+func TestRejectUser_RequiresReason(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	uc := &UseCase{}
+
+	err := uc.RejectUser(ctx, adminInput(ChangeUserStatusInput{UserID: 1, Reason: "  "}))
+
+	assert.ErrorIs(t, err, user.ErrReasonRequired)
+}
+
+func TestUnbanUser_InvalidTransition(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	userRepo := new(MockUserRepo)
+	userRepo.On("FindByID", mock.Anything, user.ID(1)).Return(&user.User{ID: 1, Status: user.Active}, nil)
+
+	uc := &UseCase{userRepo: userRepo, policy: stubPolicy{granted: []permission.Permission{permission.UserBan}}}
+
+	err := uc.UnbanUser(ctx, adminInput(ChangeUserStatusInput{UserID: 1}))
+
+	assert.ErrorIs(t, err, user.ErrInvalidTransition)
+	userRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUnbanUser_RestoresStatusBeforeBan(t *testing.T) {
+	tests := []struct {
+		name   string
+		latest *user.StatusChange
+		err    error
+		want   user.Status
+	}{
+		{"banned applicant", &user.StatusChange{From: user.Applying, To: user.Banned}, nil, user.Applying},
+		{"banned inactive user", &user.StatusChange{From: user.Inactive, To: user.Banned}, nil, user.Inactive},
+		{"banned active user", &user.StatusChange{From: user.Active, To: user.Banned}, nil, user.Active},
+		{"no history", nil, user.ErrStatusChangeNotFound, user.Active},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			banned := &user.User{ID: 1, Status: user.Banned}
+			userRepo := new(MockUserRepo)
+			userRepo.On("FindByID", mock.Anything, user.ID(1)).Return(banned, nil)
+			userRepo.On("Update", mock.Anything, banned).Return(nil)
+
+			historyRepo := new(MockStatusHistoryRepo)
+			historyRepo.On("FindLatest", mock.Anything, user.ID(1)).Return(tt.latest, tt.err)
+			historyRepo.On("Create", mock.Anything, mock.MatchedBy(func(c *user.StatusChange) bool {
+				return c.From == user.Banned && c.To == tt.want
+			})).Return(nil)
+
+			uc := &UseCase{
+				userRepo:          userRepo,
+				statusHistoryRepo: historyRepo,
+				policy:            stubPolicy{granted: []permission.Permission{permission.UserBan}},
+			}
+
+			err := uc.UnbanUser(ctx, adminInput(ChangeUserStatusInput{UserID: 1}))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, banned.Status)
+			historyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBanUser_ThenUnban_RestoresApplicant(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	applicant := &user.User{ID: 1, Status: user.Applying}
+	userRepo := new(MockUserRepo)
+	userRepo.On("FindByID", mock.Anything, user.ID(1)).Return(applicant, nil)
+	userRepo.On("Update", mock.Anything, applicant).Return(nil)
+
+	var recorded *user.StatusChange
+	historyRepo := new(MockStatusHistoryRepo)
+	historyRepo.On("Create", mock.Anything, mock.AnythingOfType("*user.StatusChange")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*user.StatusChange) }).
+		Return(nil)
+
+	tokenService := new(MockTokenService)
+	tokenService.On("RevokeAllForUser", mock.Anything, "1").Return(nil)
+
+	uc := &UseCase{
+		userRepo:          userRepo,
+		statusHistoryRepo: historyRepo,
+		tokenService:      tokenService,
+		policy:            stubPolicy{granted: []permission.Permission{permission.UserBan}},
+	}
+
+	assert.NoError(t, uc.BanUser(ctx, adminInput(ChangeUserStatusInput{UserID: 1, Reason: "spam"})))
+	assert.Equal(t, user.Banned, applicant.Status)
+
+	// the ban is the latest change now
+	historyRepo.On("FindLatest", mock.Anything, user.ID(1)).Return(recorded, nil)
+
+	assert.NoError(t, uc.UnbanUser(ctx, adminInput(ChangeUserStatusInput{UserID: 1})))
+	assert.Equal(t, user.Applying, applicant.Status)
+}
+
+func TestBanUser_RequiresPermission(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// approving applicants does not allow banning
+	uc := &UseCase{policy: stubPolicy{granted: []permission.Permission{permission.UserApprove}}}
+
+	err := uc.BanUser(ctx, adminInput(ChangeUserStatusInput{UserID: 1, Reason: "spam"}))
+
+	assert.ErrorIs(t, err, user.ErrForbidden)
+}
+
+func TestRegister_CreatesParticipant(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	userRepo := new(MockUserRepo)
+	userRepo.On("Create", mock.Anything, mock.AnythingOfType("*user.User")).
+		Run(func(args mock.Arguments) { args.Get(1).(*user.User).ID = 7 }).
+		Return(nil)
+
+	participantRepo := new(MockParticipantRepo)
+	participantRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *participant.Participant) bool {
+		return p.Type == participant.UserType && p.DisplayName == "alice" && *p.UserID == 7
+	})).Return(nil)
+
+	tokens := new(MockActionTokens)
+	tokens.On("Issue", mock.Anything, domainSecurity.PurposeVerifyEmail, "a@b.com", mock.Anything).
+		Return("", errors.New("token store down"))
+
+	uc := &UseCase{
+		userRepo:        userRepo,
+		participantRepo: participantRepo,
+		txManager:       stubTxManager{},
+		hasher:          stubHasher{},
+		mailLimiter:     stubMailLimiter{},
+		actionTokens:    tokens,
+	}
+
+	output, err := uc.Register(ctx, shared.UseCaseInput[RegisterInput]{
+		Data: RegisterInput{Name: "alice", Email: "a@b.com", Password: "secret"},
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, output.VerificationSent)
+	participantRepo.AssertExpectations(t)
+}
+
+func TestRegister_ParticipantFails(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	userRepo := new(MockUserRepo)
+	userRepo.On("Create", mock.Anything, mock.AnythingOfType("*user.User")).Return(nil)
+
+	failed := errors.New("insert participant")
+	participantRepo := new(MockParticipantRepo)
+	participantRepo.On("Create", mock.Anything, mock.Anything).Return(failed)
+
+	tokens := new(MockActionTokens)
+	uc := &UseCase{
+		userRepo:        userRepo,
+		participantRepo: participantRepo,
+		txManager:       stubTxManager{},
+		hasher:          stubHasher{},
+		actionTokens:    tokens,
+	}
+
+	_, err := uc.Register(ctx, shared.UseCaseInput[RegisterInput]{
+		Data: RegisterInput{Name: "alice", Email: "a@b.com", Password: "secret"},
+	})
+
+	assert.ErrorIs(t, err, failed)
+	tokens.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRegister_KeepsCreateErrors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// a serialization failure must reach the transaction manager as it is
+	conflict := errors.New("could not serialize access")
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"duplicate email", user.ErrUserAlreadyExists},
+		{"conflict", conflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(MockUserRepo)
+			userRepo.On("Create", mock.Anything, mock.Anything).Return(tt.err)
+
+			uc := &UseCase{
+				userRepo:  userRepo,
+				txManager: stubTxManager{},
+				hasher:    stubHasher{},
+			}
+
+			_, err := uc.Register(ctx, shared.UseCaseInput[RegisterInput]{
+				Data: RegisterInput{Name: "alice", Email: "a@b.com", Password: "secret"},
+			})
+
+			assert.ErrorIs(t, err, tt.err)
+			if tt.err == conflict {
+				assert.NotErrorIs(t, err, user.ErrUserAlreadyExists)
+			}
+		})
+	}
+}
+
+func TestResetPassword_RevokesSessions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tokens := new(MockActionTokens)
+	tokens.On("Consume", mock.Anything, domainSecurity.PurposeResetPassword, "tkn").Return("a@b.com", nil)
+
+	target := &user.User{ID: 1, Email: "a@b.com", Password: "hashed:old", Status: user.Active}
+	userRepo := new(MockUserRepo)
+	userRepo.On("FindByEmail", mock.Anything, user.Email("a@b.com")).Return(target, nil)
+	userRepo.On("Update", mock.Anything, target).Return(nil)
+
+	tokenService := new(MockTokenService)
+	tokenService.On("RevokeAllForUser", mock.Anything, "1").Return(nil)
+
+	uc := &UseCase{
+		userRepo:     userRepo,
+		hasher:       stubHasher{},
+		tokenService: tokenService,
+		loginLimiter: stubLoginLimiter{},
+		actionTokens: tokens,
+	}
+
+	err := uc.ResetPassword(ctx, shared.UseCaseInput[ResetPasswordInput]{
+		Data: ResetPasswordInput{Token: "tkn", Password: "new-secret"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hashed:new-secret", target.Password)
+	assert.True(t, target.IsEmailVerified())
+	tokenService.AssertExpectations(t)
+}
+
+func TestResetPassword_InvalidToken(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	tokens := new(MockActionTokens)
+	tokens.On("Consume", mock.Anything, domainSecurity.PurposeResetPassword, "used").
+		Return("", domainSecurity.ErrInvalidActionToken)
+
+	uc := &UseCase{actionTokens: tokens}
+
+	err := uc.ResetPassword(ctx, shared.UseCaseInput[ResetPasswordInput]{
+		Data: ResetPasswordInput{Token: "used", Password: "new-secret"},
+	})
+
+	assert.ErrorIs(t, err, domainSecurity.ErrInvalidActionToken)
+}
+
+func TestForgotPassword_UnknownEmailIsSilent(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	userRepo := new(MockUserRepo)
+	userRepo.On("FindByEmail", mock.Anything, user.Email("nobody@b.com")).Return(nil, user.ErrUserNotFound)
+
+	tokens := new(MockActionTokens)
+	uc := &UseCase{userRepo: userRepo, mailLimiter: stubMailLimiter{}, actionTokens: tokens}
+
+	err := uc.ForgotPassword(ctx, shared.UseCaseInput[EmailInput]{Data: EmailInput{Email: "nobody@b.com"}})
+
+	assert.NoError(t, err)
+	tokens.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLogin_RehashesOutdatedPassword(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	target := &user.User{ID: 1, Email: "a@b.com", Password: "legacy", Status: user.Active}
+	userRepo := new(MockUserRepo)
+	userRepo.On("FindByEmail", mock.Anything, user.Email("a@b.com")).Return(target, nil)
+	userRepo.On("Update", mock.Anything, target).Return(nil)
+
+	tokenService := new(MockTokenService)
+	tokenService.On("Generate", mock.Anything, mock.Anything).Return(session.TokenPair{AccessToken: "t"}, nil)
+
+	twoFactors := new(MockTwoFactorRepo)
+	twoFactors.On("Find", mock.Anything, user.ID(1)).Return(nil, twofactor.ErrNotEnrolled)
+
+	roles := new(MockUserRoleRepo)
+	roles.On("FindRolesByUser", mock.Anything, user.ID(1)).Return([]*role.Role{{Type: role.User}}, nil)
+
+	uc := &UseCase{
+		userRepo:      userRepo,
+		userRoleRepo:  roles,
+		twoFactorRepo: twoFactors,
+		hasher:        legacyHasher{stubHasher{}},
+		tokenService:  tokenService,
+		loginLimiter:  stubLoginLimiter{},
+	}
+
+	_, err := uc.Login(ctx, shared.UseCaseInput[LoginInput]{
+		Data: LoginInput{Email: "a@b.com", Password: "secret"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hashed:secret", target.Password)
+	userRepo.AssertCalled(t, "Update", mock.Anything, target)
+}
+
+// legacyHasher also accepts the "legacy" hash for any password
+type legacyHasher struct{ stubHasher }
+
+func (h legacyHasher) Verify(password, hash string) bool {
+	return hash == "legacy" || h.stubHasher.Verify(password, hash)
+}
+
+func TestLogin_TwoFactorReturnsChallenge(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	target := &user.User{ID: 1, Email: "a@b.com", Password: "hashed:secret", Status: user.Active}
+	userRepo := new(MockUserRepo)
+	userRepo.On("FindByEmail", mock.Anything, user.Email("a@b.com")).Return(target, nil)
+
+	enrollment := twofactor.New(1, "JBSWY3DPEHPK3PXP")
+	assert.NoError(t, enrollment.Enable(time.Now()))
+	twoFactors := new(MockTwoFactorRepo)
+	twoFactors.On("Find", mock.Anything, user.ID(1)).Return(enrollment, nil)
+
+	tokens := new(MockActionTokens)
+	tokens.On("Issue", mock.Anything, domainSecurity.PurposeLoginChallenge, "1", time.Minute).Return("challenge", nil)
+
+	tokenService := new(MockTokenService)
+	uc := &UseCase{
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactors,
+		hasher:        stubHasher{},
+		tokenService:  tokenService,
+		loginLimiter:  stubLoginLimiter{},
+		actionTokens:  tokens,
+		twoFactorConf: TwoFactorConfig{ChallengeTTL: time.Minute},
+	}
+
+	got, err := uc.Login(ctx, shared.UseCaseInput[LoginInput]{
+		Data: LoginInput{Email: "a@b.com", Password: "secret"},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, got.TwoFactorRequired)
+	assert.Equal(t, "challenge", got.ChallengeToken)
+	assert.Empty(t, got.Token)
+	tokenService.AssertNotCalled(t, "Generate", mock.Anything, mock.Anything)
+}
+
+func TestLoginTwoFactor_IssuesSessionForValidCode(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	const secret = "JBSWY3DPEHPK3PXP"
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	assert.NoError(t, err)
+
+	tokens := new(MockActionTokens)
+	tokens.On("Consume", mock.Anything, domainSecurity.PurposeLoginChallenge, "challenge").Return("1", nil)
+
+	userRepo := new(MockUserRepo)
+	userRepo.On("FindByID", mock.Anything, user.ID(1)).
+		Return(&user.User{ID: 1, Email: "a@b.com", Status: user.Active}, nil)
+
+	enrollment := twofactor.New(1, secret)
+	assert.NoError(t, enrollment.Enable(time.Now()))
+	twoFactors := new(MockTwoFactorRepo)
+	twoFactors.On("Find", mock.Anything, user.ID(1)).Return(enrollment, nil)
+	twoFactors.On("Save", mock.Anything, enrollment).Return(nil)
+
+	tokenService := new(MockTokenService)
+	tokenService.On("Generate", mock.Anything, mock.Anything).Return(session.TokenPair{AccessToken: "t"}, nil)
+
+	uc := &UseCase{
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactors,
+		hasher:        stubHasher{},
+		tokenService:  tokenService,
+		loginLimiter:  stubLoginLimiter{},
+		actionTokens:  tokens,
+		twoFactorConf: TwoFactorConfig{Skew: 1},
+	}
+
+	got, err := uc.LoginTwoFactor(ctx, shared.UseCaseInput[LoginTwoFactorInput]{
+		Data: LoginTwoFactorInput{ChallengeToken: "challenge", Code: code},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "t", got.Token)
+	assert.NotZero(t, enrollment.LastUsedStep)
+
+	// the same code cannot be replayed within its step
+	err = uc.verifySecondFactor(ctx, enrollment, code)
+	assert.ErrorIs(t, err, twofactor.ErrInvalidCode)
+}
+
+func TestLoginTwoFactor_AcceptsRecoveryCodeOnce(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	enrollment := twofactor.New(1, "JBSWY3DPEHPK3PXP")
+	twoFactors := new(MockTwoFactorRepo)
+	twoFactors.On("FindUnusedRecoveryCodes", mock.Anything, user.ID(1)).
+		Return([]*twofactor.RecoveryCode{{ID: 7, UserID: 1, Hash: "hashed:abcde12345"}}, nil)
+	twoFactors.On("UseRecoveryCode", mock.Anything, int64(7)).Return(nil)
+
+	uc := &UseCase{twoFactorRepo: twoFactors, hasher: stubHasher{}}
+
+	assert.NoError(t, uc.verifySecondFactor(ctx, enrollment, "ABCDE-12345"))
+	assert.ErrorIs(t, uc.verifySecondFactor(ctx, enrollment, "other-codes"), twofactor.ErrInvalidCode)
+	twoFactors.AssertNumberOfCalls(t, "UseRecoveryCode", 1)
+}
+
+func TestListSessions_ShowsBoundDevices(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	now := time.Now()
+	tokenService := new(MockTokenService)
+	tokenService.On("ListUserSessions", mock.Anything, "1").Return([]*session.Session{
+		{ID: "registered", LastSeenAt: now},
+		{ID: "logged-in", DeviceID: "pi-1", LastSeenAt: now.Add(-time.Minute)},
+		{ID: "browser", LastSeenAt: now.Add(-time.Hour)},
+	}, nil)
+
+	devices := new(MockDeviceRepo)
+	devices.On("FindByUser", mock.Anything, user.ID(1)).Return([]*device.Device{
+		{ID: 3, DeviceID: "phone-1", Name: "Phone", Platform: device.Android, SessionID: "registered"},
+		{ID: 4, DeviceID: "pi-1", Name: "Kitchen Pi", Platform: device.Embedded, SessionID: "expired"},
+	}, nil)
+
+	uc := &UseCase{tokenService: tokenService, deviceRepo: devices}
+
+	got, err := uc.ListSessions(ctx, shared.UseCaseInput[struct{}]{
+		Base: shared.BaseInput{Auth: &shared.AuthContext{UserID: "1", SessionID: "registered"}},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, got.Sessions, 3)
+	assert.Equal(t, "Phone", got.Sessions[0].DeviceName)
+	assert.True(t, got.Sessions[0].Current)
+	assert.Equal(t, device.ID(4), got.Sessions[1].DeviceID)
+	assert.Equal(t, "embedded", got.Sessions[1].DevicePlatform)
+	assert.Empty(t, got.Sessions[2].DeviceName)
+}
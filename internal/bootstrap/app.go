@@ -3,12 +3,15 @@ package bootstrap
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/HiroLiang/goat-server/internal/config"
 	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/migration"
 	redisInfra "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/redis"
+	"github.com/HiroLiang/goat-server/internal/interface/mqtt"
 	"github.com/HiroLiang/goat-server/internal/logger"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
@@ -16,8 +19,10 @@ import (
 
 type App struct {
 	Server      *http.Server
+	MQTT        *mqtt.Broker
 	Redis       *redis.Client
 	DataSources *database.DataSources
+	stopJobs    context.CancelFunc
 }
 
 func CreateApp() *App {
@@ -39,13 +44,24 @@ func (app *App) Start() error {
 		return err
 	}
 
-	// Initialize DataSource
+	// Initialize DataSource of the configured backend
+	backend, err := DatabaseBackend(config.App())
+	if err != nil {
+		return err
+	}
 	databaseConfig := database.BuildDatabaseConfigs(config.App().Database)
-	app.DataSources, err = database.NewDataSources(databaseConfig)
+	app.DataSources, err = database.NewDataSources(databaseConfig, backend)
 	if err != nil {
 		return err
 	}
 
+	// Apply pending migrations when asked to, replicas take turns on the migration lock
+	if config.App().Migration.OnBoot {
+		if err := migrate(app.DataSources, backend); err != nil {
+			return err
+		}
+	}
+
 	// Init all dependencies
 	dependencies, err := BuildDeps(app.Redis, app.DataSources)
 	if err != nil {
@@ -55,6 +71,11 @@ func (app *App) Start() error {
 	// build use cases
 	useCases := BuildUseCases(dependencies)
 
+	// Start background jobs
+	var jobsCtx context.Context
+	jobsCtx, app.stopJobs = context.WithCancel(context.Background())
+	StartJobs(jobsCtx, useCases)
+
 	// Start api server
 	app.Server = NewServer(
 		":"+config.Env("SERVER_PORT", "8080"),
@@ -69,6 +90,9 @@ func (app *App) Start() error {
 		}
 	}()
 
+	// Start mqtt broker
+	app.MQTT = StartMqtt(dependencies, useCases)
+
 	logger.Log.Info(
 		"application boot completed",
 		zap.Duration("boot_time", time.Since(start)),
@@ -76,6 +100,23 @@ func (app *App) Start() error {
 	return nil
 }
 
+// migrate apply the pending migrations of the backend
+func migrate(dataSources *database.DataSources, backend database.DBName) error {
+	migrator, err := migration.NewMigrator(dataSources.GetDB(backend), backend)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
+	for _, m := range applied {
+		logger.Log.Info("migration applied", zap.Int64("version", m.Version), zap.String("name", m.Name))
+	}
+	if err != nil {
+		return fmt.Errorf("migrate on boot: %w", err)
+	}
+	return nil
+}
+
 func (app *App) Stop(ctx context.Context) {
 
 	// 1. Stop accepting requests
@@ -85,12 +126,23 @@ func (app *App) Stop(ctx context.Context) {
 		}
 	}
 
-	// 2. Close DB
+	if app.MQTT != nil {
+		if err := app.MQTT.Close(); err != nil {
+			logger.Log.Error("mqtt broker shutdown error", zap.Error(err))
+		}
+	}
+
+	// 2. Stop background jobs
+	if app.stopJobs != nil {
+		app.stopJobs()
+	}
+
+	// 3. Close DB
 	if app.DataSources != nil {
 		app.DataSources.CloseAllDBs()
 	}
 
-	// 3. Close Redis
+	// 4. Close Redis
 	if app.Redis != nil {
 		_ = app.Redis.Close()
 	}
@@ -1,43 +1,113 @@
 package bootstrap
 
 import (
+	"errors"
+	"os"
+	"strings"
+
+	apikeyApp "github.com/HiroLiang/goat-server/internal/application/apikey"
+	commandApp "github.com/HiroLiang/goat-server/internal/application/devicecommand"
+	identityApp "github.com/HiroLiang/goat-server/internal/application/identity"
+	notificationApp "github.com/HiroLiang/goat-server/internal/application/notification"
+	"github.com/HiroLiang/goat-server/internal/application/shared/agentreply"
 	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/application/shared/mail"
+	"github.com/HiroLiang/goat-server/internal/application/shared/modelcatalog"
+	"github.com/HiroLiang/goat-server/internal/application/shared/notification"
+	"github.com/HiroLiang/goat-server/internal/application/shared/oidc"
+	"github.com/HiroLiang/goat-server/internal/application/shared/push"
 	"github.com/HiroLiang/goat-server/internal/application/shared/security"
+	"github.com/HiroLiang/goat-server/internal/application/shared/transaction"
+	telemetryApp "github.com/HiroLiang/goat-server/internal/application/telemetry"
+	userApp "github.com/HiroLiang/goat-server/internal/application/user"
 	"github.com/HiroLiang/goat-server/internal/config"
 	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+	"github.com/HiroLiang/goat-server/internal/domain/agentusage"
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
 	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
 	"github.com/HiroLiang/goat-server/internal/domain/chatmember"
 	"github.com/HiroLiang/goat-server/internal/domain/chatmessage"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/identity"
 	"github.com/HiroLiang/goat-server/internal/domain/participant"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
 	domainSecurity "github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
 	"github.com/HiroLiang/goat-server/internal/domain/user"
 	"github.com/HiroLiang/goat-server/internal/domain/userrole"
+	infraOIDC "github.com/HiroLiang/goat-server/internal/infrastructure/auth/oidc"
 	"github.com/HiroLiang/goat-server/internal/infrastructure/auth/session"
 	infraAuth "github.com/HiroLiang/goat-server/internal/infrastructure/auth/token"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/cache"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/llm/dispatcher"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/llm/ollama"
+	infraMail "github.com/HiroLiang/goat-server/internal/infrastructure/mail"
 	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
-	dbAgent "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/agent"
-	dbChat "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/chat"
-	dbUser "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/user"
-	dbUserrole "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/userrole"
+	memoryInfra "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/memory"
+	memoryInfraSecurity "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/memory/security"
+	mockRepo "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/mock"
 	redisInfra "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/redis"
+	redisPermission "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/redis/permission"
 	redisInfraSecurity "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/redis/security"
 	redisUserrole "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/redis/userrole"
+	infraPush "github.com/HiroLiang/goat-server/internal/infrastructure/push"
 	infraSecurity "github.com/HiroLiang/goat-server/internal/infrastructure/shared/security"
+	"github.com/HiroLiang/goat-server/internal/interface/mqtt"
+	mqttDevice "github.com/HiroLiang/goat-server/internal/interface/mqtt/handler/device"
+	"github.com/HiroLiang/goat-server/internal/interface/ws"
+	"github.com/HiroLiang/goat-server/internal/logger"
 	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 )
 
 type Dependencies struct {
 	AgentRepo       agent.Repository
+	AgentConfigRepo agent.ConfigRepository
+	AgentModelRepo  agentmodel.Repository
+	AgentUsageRepo  agentusage.Repository
+	AgentQuota      agentusage.QuotaPolicy
+	ModelProviders  []modelcatalog.Provider
+	AgentDispatcher agentreply.Dispatcher
+	MaxAgentDepth   int
 	TokenService    auth.TokenService
 	Hasher          security.Hasher
 	HMACer          security.HMACer
 	RateLimiter     security.RateLimiter
+	LoginLimiter    security.LoginRateLimiter
+	MailLimiter     security.MailRateLimiter
+	ActionTokens    security.ActionTokenService
+	Mailer          mail.Mailer
+	UserMail        userApp.MailConfig
+	TwoFactor       userApp.TwoFactorConfig
+	OIDCProviders   []oidc.Provider
+	OIDC            identityApp.Config
+	APIKey          apikeyApp.Config
+	DeviceCommand   commandApp.Config
+	Telemetry       telemetryApp.Config
+	Hub             *ws.Hub
+	MQTT            *mqtt.Broker
+	MQTTTopics      mqttDevice.Topics
+	Notifier        notification.Notifier
 	UserRepo        user.Repository
+	UserStatusRepo  user.StatusHistoryRepository
 	UserRoleRepo    userrole.Repository
+	RoleRepo        role.Repository
+	TwoFactorRepo   twofactor.Repository
+	IdentityRepo    identity.Repository
+	APIKeyRepo      apikey.Repository
+	DeviceRepo      device.Repository
+	CommandRepo     devicecommand.Repository
+	TelemetryRepo   telemetry.Repository
+	PermissionRepo  permission.Repository
 	ChatGroupRepo   chatgroup.Repository
 	ChatMemberRepo  chatmember.Repository
 	ChatMessageRepo chatmessage.Repository
 	ParticipantRepo participant.Repository
+	TxManager       transaction.Manager
 }
 
 func BuildDeps(redis *redis.Client, dataSources *database.DataSources) (*Dependencies, error) {
@@ -45,27 +115,69 @@ func BuildDeps(redis *redis.Client, dataSources *database.DataSources) (*Depende
 	// get config
 	conf := config.App()
 
-	// Postgres datasource
-	postgres := dataSources.GetDB(database.Postgres)
+	// Repositories of the configured database backend
+	repos, err := buildRepositories(dataSources, conf)
+	if err != nil {
+		return nil, err
+	}
 
-	// redis cache
-	redisCache := redisInfra.NewRedisCache(redis)
+	// Cache, session store and rate limit counters, in process when running without Redis
+	appCache, sessionStore, rateLimits := buildSharedStores(redis)
+
+	tokenService, err := buildTokenService(sessionStore, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	hmacer := infraSecurity.NewSHA256HMACer(conf.Secrets.HmacSecret)
 
-	// Session store
-	sessionStore := session.NewRedisSessionStore(redisCache, redis)
+	// Connections of the hub tell which users are online and need no push
+	hub := ws.NewHub()
 
 	return &Dependencies{
-		AgentRepo:       dbAgent.NewAgentRepository(postgres),
-		TokenService:    infraAuth.NewAuthTokenService(sessionStore, conf.AuthToken.Expiration),
-		Hasher:          infraSecurity.NewArgon2Hasher(),
-		HMACer:          infraSecurity.NewSHA256HMACer(conf.Secrets.HmacSecret),
-		RateLimiter:     buildRateLimiter(redis, conf),
-		UserRepo:        dbUser.NewUserRepository(postgres),
-		UserRoleRepo:    redisUserrole.NewUserRoleCachedRepo(redisCache, dbUserrole.NewUserRoleRepository(postgres)),
-		ChatGroupRepo:   dbChat.NewChatGroupRepository(postgres),
-		ChatMemberRepo:  dbChat.NewChatMemberRepository(postgres),
-		ChatMessageRepo: dbChat.NewChatMessageRepository(postgres),
-		ParticipantRepo: dbChat.NewParticipantRepository(postgres),
+		AgentRepo:       repos.Agent,
+		AgentConfigRepo: repos.AgentConfig,
+		AgentModelRepo:  repos.AgentModel,
+		AgentUsageRepo:  repos.AgentUsage,
+		AgentQuota:      buildAgentQuotaPolicy(conf),
+		ModelProviders:  buildModelProviders(conf),
+		AgentDispatcher: dispatcher.NewLogDispatcher(),
+		MaxAgentDepth:   conf.Chat.MaxAgentDepth,
+		TokenService:    tokenService,
+		Hasher:          buildHasher(conf),
+		HMACer:          hmacer,
+		RateLimiter:     buildRateLimiter(rateLimits, conf),
+		LoginLimiter:    buildLoginRateLimiter(rateLimits, appCache, conf),
+		MailLimiter:     buildMailRateLimiter(rateLimits, conf),
+		ActionTokens:    infraSecurity.NewRedisActionTokenService(appCache, hmacer),
+		Mailer:          buildMailer(conf),
+		UserMail:        buildUserMailConfig(conf),
+		TwoFactor:       buildTwoFactorConfig(conf),
+		OIDCProviders:   buildOIDCProviders(conf),
+		OIDC:            buildOIDCConfig(conf),
+		APIKey:          buildAPIKeyConfig(conf),
+		DeviceCommand:   buildDeviceCommandConfig(conf),
+		Telemetry:       buildTelemetryConfig(conf),
+		Hub:             hub,
+		MQTT:            buildMQTTBroker(conf),
+		MQTTTopics:      mqttDevice.Topics{Prefix: conf.MQTT.TopicPrefix},
+		Notifier:        buildNotifier(repos.Device, hub, conf),
+		UserRepo:        repos.User,
+		UserStatusRepo:  repos.UserStatus,
+		UserRoleRepo:    redisUserrole.NewUserRoleCachedRepo(appCache, repos.UserRole),
+		RoleRepo:        redisUserrole.NewRoleCachedRepo(appCache, repos.Role),
+		TwoFactorRepo:   repos.TwoFactor,
+		IdentityRepo:    repos.Identity,
+		APIKeyRepo:      repos.APIKey,
+		DeviceRepo:      repos.Device,
+		CommandRepo:     repos.Command,
+		TelemetryRepo:   repos.Telemetry,
+		PermissionRepo:  redisPermission.NewPermissionCachedRepo(appCache, repos.Permission),
+		ChatGroupRepo:   repos.ChatGroup,
+		ChatMemberRepo:  repos.ChatMember,
+		ChatMessageRepo: repos.ChatMessage,
+		ParticipantRepo: repos.Participant,
+		TxManager:       repos.TxManager,
 	}, nil
 }
 
@@ -74,8 +186,20 @@ func BuildMockDeps(opts ...DepsOption) *Dependencies {
 
 	//Default dependencies
 	deps := &Dependencies{
-		Hasher: infraSecurity.NewArgon2Hasher(),
-		HMACer: infraSecurity.NewSHA256HMACer(conf.Secrets.HmacSecret),
+		AgentQuota:    buildAgentQuotaPolicy(conf),
+		MaxAgentDepth: conf.Chat.MaxAgentDepth,
+		UserMail:      buildUserMailConfig(conf),
+		TwoFactor:     buildTwoFactorConfig(conf),
+		OIDC:          buildOIDCConfig(conf),
+		APIKey:        buildAPIKeyConfig(conf),
+		DeviceCommand: buildDeviceCommandConfig(conf),
+		Telemetry:     buildTelemetryConfig(conf),
+		Hub:           ws.NewHub(),
+		MQTT:          buildMQTTBroker(conf),
+		MQTTTopics:    mqttDevice.Topics{Prefix: conf.MQTT.TopicPrefix},
+		Hasher:        buildHasher(conf),
+		HMACer:        infraSecurity.NewSHA256HMACer(conf.Secrets.HmacSecret),
+		TxManager:     mockRepo.MockTxManager(),
 	}
 
 	// Optionals
@@ -88,19 +212,360 @@ func BuildMockDeps(opts ...DepsOption) *Dependencies {
 
 type DepsOption func(*Dependencies)
 
+// buildSharedStores build the Redis backed cache, session store and rate limit counters,
+// or in-memory ones for standalone mode when no Redis is configured
+func buildSharedStores(redis *redis.Client) (cache.Cache, session.Store, domainSecurity.RateLimitRepository) {
+	if redis == nil {
+		logger.Log.Info("running standalone, keep cache, sessions and rate limits in memory")
+		return memoryInfra.NewMemoryCache(),
+			session.NewMemorySessionStore(),
+			memoryInfraSecurity.NewMemoryRateLimitRepository()
+	}
+
+	redisCache := redisInfra.NewRedisCache(redis)
+	return redisCache,
+		session.NewRedisSessionStore(redisCache, redis),
+		redisInfraSecurity.NewRedisRateLimitRepository(redis)
+}
+
+// buildTokenService build the token service of the configured auth token mode, jwt
+// tokens can't be signed without a secret
+func buildTokenService(store session.Store, conf *config.AppConfig) (auth.TokenService, error) {
+	tokenConf := conf.AuthToken
+	switch tokenConf.Mode {
+	case "jwt":
+		if conf.Secrets.JwtSecret == "" {
+			return nil, errors.New("auth token mode jwt requires a jwt secret")
+		}
+		return infraAuth.NewJWTTokenService(
+			store,
+			conf.Secrets.JwtSecret,
+			tokenConf.AccessExpiration,
+			tokenConf.RefreshExpiration), nil
+	case "", "opaque":
+		return infraAuth.NewAuthTokenService(store, tokenConf.Expiration), nil
+	default:
+		logger.Log.Warn("unknown auth token mode, fall back to opaque", zap.String("mode", tokenConf.Mode))
+		return infraAuth.NewAuthTokenService(store, tokenConf.Expiration), nil
+	}
+}
+
+// buildHasher build the password hasher, falling back to the default cost for unset values
+func buildHasher(conf *config.AppConfig) security.Hasher {
+	hashConf := conf.PasswordHash
+	params := infraSecurity.DefaultArgon2Params
+	if hashConf.Memory > 0 {
+		params.Memory = hashConf.Memory
+	}
+	if hashConf.Iterations > 0 {
+		params.Iterations = hashConf.Iterations
+	}
+	if hashConf.Parallelism > 0 {
+		params.Parallelism = hashConf.Parallelism
+	}
+	if hashConf.SaltLength > 0 {
+		params.SaltLength = hashConf.SaltLength
+	}
+	if hashConf.KeyLength > 0 {
+		params.KeyLength = hashConf.KeyLength
+	}
+	return infraSecurity.NewArgon2Hasher(params, conf.Secrets.PasswordPepper, hashConf.PepperID)
+}
+
 // buildRateLimiter build rate limiter
-func buildRateLimiter(redis *redis.Client, conf *config.AppConfig) security.RateLimiter {
+func buildRateLimiter(rateLimits domainSecurity.RateLimitRepository, conf *config.AppConfig) security.RateLimiter {
 	rateLimitConf := conf.RateLimitConfig
 	globalPolicy := domainSecurity.RateLimitPolicy{
-		Limit:  int64(rateLimitConf.GlobalLimit),
-		Window: rateLimitConf.GlobalUnit,
+		Limit:     int64(rateLimitConf.GlobalLimit),
+		Window:    rateLimitConf.GlobalUnit,
+		Algorithm: rateLimitAlgorithm(rateLimitConf.GlobalAlgorithm, domainSecurity.SlidingWindow),
 	}
 	ipPolicy := domainSecurity.RateLimitPolicy{
-		Limit:  int64(rateLimitConf.IPLimit),
-		Window: rateLimitConf.IPUnit,
+		Limit:     int64(rateLimitConf.IPLimit),
+		Window:    rateLimitConf.IPUnit,
+		Algorithm: rateLimitAlgorithm(rateLimitConf.IPAlgorithm, domainSecurity.FixedWindow),
+	}
+	userPolicy := domainSecurity.RateLimitPolicy{
+		Limit:     int64(rateLimitConf.UserLimit),
+		Window:    rateLimitConf.UserUnit,
+		Algorithm: rateLimitAlgorithm(rateLimitConf.UserAlgorithm, domainSecurity.SlidingWindow),
+	}
+	routePolicies := make(map[string]domainSecurity.RateLimitPolicy, len(rateLimitConf.Routes))
+	for _, route := range rateLimitConf.Routes {
+		routePolicies[route.Route] = domainSecurity.RateLimitPolicy{
+			Limit:     int64(route.Limit),
+			Window:    route.Unit,
+			Algorithm: rateLimitAlgorithm(route.Algorithm, domainSecurity.SlidingWindow),
+		}
 	}
 	return infraSecurity.NewRedisRateLimiter(
-		redisInfraSecurity.NewRedisRateLimitRepository(redis),
+		rateLimits,
 		globalPolicy,
+		ipPolicy,
+		userPolicy,
+		routePolicies)
+}
+
+// rateLimitAlgorithm parse a configured algorithm, empty and unknown ones fall back to def
+func rateLimitAlgorithm(value string, def domainSecurity.RateLimitAlgorithm) domainSecurity.RateLimitAlgorithm {
+	if value == "" {
+		return def
+	}
+	algorithm := domainSecurity.RateLimitAlgorithm(value)
+	if !algorithm.Valid() {
+		logger.Log.Warn("unknown rate limit algorithm, fall back", zap.String("algorithm", value), zap.String("fallback", string(def)))
+		return def
+	}
+	return algorithm
+}
+
+// buildLoginRateLimiter build the login rate limiter with progressive lockout
+func buildLoginRateLimiter(rateLimits domainSecurity.RateLimitRepository, locks cache.Cache, conf *config.AppConfig) security.LoginRateLimiter {
+	loginConf := conf.LoginRateLimit
+	emailPolicy := domainSecurity.LoginLockPolicy{
+		MaxFailures: loginConf.MaxFailures,
+		Window:      loginConf.Window,
+		BaseLockout: loginConf.BaseLockout,
+		MaxLockout:  loginConf.MaxLockout,
+	}
+	ipPolicy := domainSecurity.LoginLockPolicy{
+		MaxFailures: loginConf.IPMaxFailures,
+		Window:      loginConf.IPWindow,
+		BaseLockout: loginConf.BaseLockout,
+		MaxLockout:  loginConf.MaxLockout,
+	}
+	return infraSecurity.NewRedisLoginRateLimiter(
+		rateLimits,
+		locks,
+		emailPolicy,
 		ipPolicy)
 }
+
+// buildMailRateLimiter build the per address mail rate limiter
+func buildMailRateLimiter(rateLimits domainSecurity.RateLimitRepository, conf *config.AppConfig) security.MailRateLimiter {
+	policy := domainSecurity.RateLimitPolicy{
+		Limit:  int64(conf.Mail.RateLimit),
+		Window: conf.Mail.RateWindow,
+	}
+	return infraSecurity.NewRedisMailRateLimiter(rateLimits, policy)
+}
+
+// buildMailer build the mailer of the configured driver
+func buildMailer(conf *config.AppConfig) mail.Mailer {
+	mailConf := conf.Mail
+	switch mailConf.Driver {
+	case "smtp":
+		return infraMail.NewSMTPMailer(
+			mailConf.SMTP.Host,
+			mailConf.SMTP.Port,
+			mailConf.SMTP.Username,
+			mailConf.SMTP.Password,
+			mailConf.From)
+	case "", "outbox":
+		return infraMail.NewOutboxMailer(mailConf.OutboxDir, mailConf.From)
+	default:
+		logger.Log.Warn("unknown mail driver, fall back to outbox", zap.String("driver", mailConf.Driver))
+		return infraMail.NewOutboxMailer(mailConf.OutboxDir, mailConf.From)
+	}
+}
+
+// buildUserMailConfig build where account mails link to and how long links stay valid
+func buildUserMailConfig(conf *config.AppConfig) userApp.MailConfig {
+	return userApp.MailConfig{
+		LinkBaseURL: conf.Mail.LinkBaseURL,
+		VerifyTTL:   conf.Mail.VerifyTTL,
+		ResetTTL:    conf.Mail.ResetTTL,
+	}
+}
+
+// buildTwoFactorConfig build the TOTP settings, a zero skew only accepts the current time step
+func buildTwoFactorConfig(conf *config.AppConfig) userApp.TwoFactorConfig {
+	twoFactorConf := conf.TwoFactor
+	return userApp.TwoFactorConfig{
+		Issuer:        twoFactorConf.Issuer,
+		ChallengeTTL:  twoFactorConf.ChallengeTTL,
+		Skew:          twoFactorConf.Skew,
+		RecoveryCodes: twoFactorConf.RecoveryCodes,
+	}
+}
+
+// buildOIDCConfig build the OIDC flow lifetimes, the login challenge is the one of 2FA
+func buildOIDCConfig(conf *config.AppConfig) identityApp.Config {
+	return identityApp.Config{
+		StateTTL:     conf.OIDC.StateTTL,
+		ChallengeTTL: conf.TwoFactor.ChallengeTTL,
+	}
+}
+
+// buildAPIKeyConfig build the lifetimes and limits of API keys
+func buildAPIKeyConfig(conf *config.AppConfig) apikeyApp.Config {
+	apiKeyConf := conf.APIKey
+	return apikeyApp.Config{
+		DefaultTTL:    apiKeyConf.DefaultTTL,
+		MaxTTL:        apiKeyConf.MaxTTL,
+		MaxPerUser:    apiKeyConf.MaxPerUser,
+		TouchInterval: apiKeyConf.TouchInterval,
+	}
+}
+
+// buildDeviceCommandConfig build the lifetimes and queue bound of device commands
+func buildDeviceCommandConfig(conf *config.AppConfig) commandApp.Config {
+	commandConf := conf.DeviceCommand
+	return commandApp.Config{
+		DefaultTTL: commandConf.DefaultTTL,
+		MaxTTL:     commandConf.MaxTTL,
+		MaxOpen:    commandConf.MaxOpen,
+	}
+}
+
+// buildTelemetryConfig build the platform schemas, bounds and retention of device telemetry
+func buildTelemetryConfig(conf *config.AppConfig) telemetryApp.Config {
+	telemetryConf := conf.Telemetry
+
+	schemas := make(map[device.Platform]telemetry.Schema, len(telemetryConf.Schemas))
+	for platform, schemaConf := range telemetryConf.Schemas {
+		metrics := make(map[string]telemetry.Range, len(schemaConf.Metrics))
+		for metric, r := range schemaConf.Metrics {
+			metrics[metric] = telemetry.Range{Min: r.Min, Max: r.Max}
+		}
+		schemas[device.Platform(platform)] = telemetry.Schema{Metrics: metrics, Custom: schemaConf.Custom}
+	}
+
+	return telemetryApp.Config{
+		Schemas:         schemas,
+		MaxBatch:        telemetryConf.MaxBatch,
+		MaxAge:          telemetryConf.MaxAge,
+		MaxPoints:       telemetryConf.MaxPoints,
+		RollupStep:      telemetryConf.RollupStep,
+		RawRetention:    telemetryConf.RawRetention,
+		RollupRetention: telemetryConf.RollupRetention,
+	}
+}
+
+// buildMQTTBroker build the broker embedded devices connect to, falling back to the default
+// limits for unset values. It only listens once started
+func buildMQTTBroker(conf *config.AppConfig) *mqtt.Broker {
+	mqttConf := conf.MQTT
+	brokerConf := mqtt.DefaultConfig
+	if mqttConf.MaxPacketSize > 0 {
+		brokerConf.MaxPacketSize = mqttConf.MaxPacketSize
+	}
+	if mqttConf.ConnectTimeout > 0 {
+		brokerConf.ConnectTimeout = mqttConf.ConnectTimeout
+	}
+	return mqtt.NewBroker(brokerConf)
+}
+
+// buildNotifier build the background notifier pushing chat messages to offline members
+func buildNotifier(deviceRepo device.Repository, presence notification.Presence, conf *config.AppConfig) notification.Notifier {
+	pushConf := conf.Push
+	dispatcher := notificationApp.NewDispatcher(deviceRepo, presence, buildPushProviders(conf), notificationApp.Config{
+		CollapseWindow: pushConf.CollapseWindow,
+		PreviewLength:  pushConf.PreviewLength,
+	})
+	return infraPush.NewAsyncNotifier(dispatcher, pushConf.Timeout)
+}
+
+// buildPushProviders build the configured push services, logging notifications when none is
+func buildPushProviders(conf *config.AppConfig) []push.Provider {
+	pushConf := conf.Push
+	providers := make([]push.Provider, 0, 2)
+
+	if apnsConf := pushConf.APNs; apnsConf.KeyFile != "" {
+		key, err := os.ReadFile(apnsConf.KeyFile)
+		if err != nil {
+			logger.Log.Warn("read apns key failed, apns skipped", zap.Error(err))
+		} else {
+			baseURL := infraPush.APNsProductionURL
+			if apnsConf.Sandbox {
+				baseURL = infraPush.APNsSandboxURL
+			}
+			providers = append(providers, infraPush.NewAPNsProvider(infraPush.APNsConfig{
+				TeamID:     apnsConf.TeamID,
+				KeyID:      apnsConf.KeyID,
+				PrivateKey: string(key),
+				Topic:      apnsConf.Topic,
+				BaseURL:    baseURL,
+			}, nil))
+		}
+	}
+
+	if file := pushConf.FCM.CredentialsFile; file != "" {
+		fcmConf, err := readFCMCredentials(file)
+		if err != nil {
+			logger.Log.Warn("read fcm credentials failed, fcm skipped", zap.Error(err))
+		} else {
+			providers = append(providers, infraPush.NewFCMProvider(fcmConf, nil))
+		}
+	}
+
+	if len(providers) == 0 {
+		logger.Log.Info("no push service configured, notifications are only logged")
+		providers = append(providers, infraPush.NewLogProvider())
+	}
+	return providers
+}
+
+// readFCMCredentials read the service account file of the Firebase project
+func readFCMCredentials(file string) (infraPush.FCMConfig, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return infraPush.FCMConfig{}, err
+	}
+	return infraPush.ParseFCMCredentials(data)
+}
+
+// buildOIDCProviders build the OpenID Connect providers users can sign in with
+func buildOIDCProviders(conf *config.AppConfig) []oidc.Provider {
+	providers := make([]oidc.Provider, 0, len(conf.OIDC.Providers))
+	for name, providerConf := range conf.OIDC.Providers {
+		if providerConf.ClientID == "" {
+			logger.Log.Info("oidc provider without client id, skipped", zap.String("provider", name))
+			continue
+		}
+		providers = append(providers, infraOIDC.NewProvider(name, infraOIDC.Config{
+			Issuer:       providerConf.Issuer,
+			ClientID:     providerConf.ClientID,
+			ClientSecret: providerConf.ClientSecret,
+			RedirectURL:  providerConf.RedirectURL,
+			Scopes:       strings.Fields(providerConf.Scopes),
+		}, nil))
+	}
+	return providers
+}
+
+// buildAgentQuotaPolicy build the role based agent quota policy
+func buildAgentQuotaPolicy(conf *config.AppConfig) agentusage.QuotaPolicy {
+	quotaConf := conf.AgentQuotaConfig
+	policy := agentusage.QuotaPolicy{
+		Default: agentusage.Quota{
+			DailyTokens:   quotaConf.Default.DailyTokens,
+			DailyRequests: quotaConf.Default.DailyRequests,
+		},
+		Roles: make(map[role.Type]agentusage.Quota, len(quotaConf.Roles)),
+	}
+	for name, quota := range quotaConf.Roles {
+		policy.Roles[role.Type(name)] = agentusage.Quota{
+			DailyTokens:   quota.DailyTokens,
+			DailyRequests: quota.DailyRequests,
+		}
+	}
+	return policy
+}
+
+// buildModelProviders build the model providers the agent catalog is synced from
+func buildModelProviders(conf *config.AppConfig) []modelcatalog.Provider {
+	providers := make([]modelcatalog.Provider, 0, len(conf.AgentCatalog.Providers))
+	for name, providerConf := range conf.AgentCatalog.Providers {
+		switch providerConf.Type {
+		case "ollama":
+			providers = append(providers, ollama.NewClient(name, providerConf.BaseURL, nil))
+		default:
+			logger.Log.Warn("unknown model provider type, skipped",
+				zap.String("provider", name),
+				zap.String("type", providerConf.Type),
+			)
+		}
+	}
+	return providers
+}
@@ -0,0 +1,96 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/agent"
+	"github.com/HiroLiang/goat-server/internal/application/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/telemetry"
+	"github.com/HiroLiang/goat-server/internal/config"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"go.uber.org/zap"
+)
+
+// StartJobs starts the background jobs, which stop when ctx is cancelled.
+func StartJobs(ctx context.Context, useCases *UseCases) {
+	if interval := config.App().AgentCatalog.SyncInterval; interval > 0 {
+		go runPeriodic(ctx, "agent catalog sync", interval, func(ctx context.Context) {
+			syncAgentCatalog(ctx, useCases.AgentUseCase)
+		})
+	}
+
+	if interval := config.App().DeviceCommand.ExpireInterval; interval > 0 {
+		go runPeriodic(ctx, "device command expiry", interval, func(ctx context.Context) {
+			expireDeviceCommands(ctx, useCases.CommandUseCase)
+		})
+	}
+
+	if interval := config.App().Telemetry.RollupInterval; interval > 0 {
+		go runPeriodic(ctx, "device telemetry rollup", interval, func(ctx context.Context) {
+			compactTelemetry(ctx, useCases.TelemetryUseCase)
+		})
+	}
+}
+
+// runPeriodic runs job right away and then once every interval until ctx is done.
+func runPeriodic(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger.Log.Info("job started", zap.String("job", name), zap.Duration("interval", interval))
+	for {
+		job(ctx)
+
+		select {
+		case <-ctx.Done():
+			logger.Log.Info("job stopped", zap.String("job", name))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func syncAgentCatalog(ctx context.Context, useCase *agent.UseCase) {
+	output, err := useCase.SyncCatalog(ctx, shared.UseCaseInput[agent.SyncCatalogInput]{})
+	if err != nil {
+		logger.Log.Error("agent catalog sync failed", zap.Error(err))
+		return
+	}
+
+	for provider, reason := range output.Failed {
+		logger.Log.Warn("agent catalog provider skipped",
+			zap.String("provider", provider),
+			zap.String("reason", reason),
+		)
+	}
+	logger.Log.Info("agent catalog synced", zap.Int("models", output.Synced))
+}
+
+func expireDeviceCommands(ctx context.Context, useCase *devicecommand.UseCase) {
+	expired, err := useCase.ExpireDue(ctx)
+	if err != nil {
+		logger.Log.Error("device command expiry failed", zap.Error(err))
+		return
+	}
+
+	if expired > 0 {
+		logger.Log.Info("device commands expired", zap.Int64("commands", expired))
+	}
+}
+
+func compactTelemetry(ctx context.Context, useCase *telemetry.UseCase) {
+	output, err := useCase.Compact(ctx)
+	if err != nil {
+		logger.Log.Error("device telemetry rollup failed", zap.Error(err))
+		return
+	}
+
+	if output.RolledUp > 0 || output.Deleted > 0 {
+		logger.Log.Info("device telemetry rolled up",
+			zap.Int64("buckets", output.RolledUp),
+			zap.Int64("deleted", output.Deleted),
+		)
+	}
+}
@@ -0,0 +1,56 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+
+	"github.com/HiroLiang/goat-server/internal/config"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/interface/mqtt"
+	mqttDevice "github.com/HiroLiang/goat-server/internal/interface/mqtt/handler/device"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"go.uber.org/zap"
+)
+
+// BuildMqttComponents wires the device topics onto a router. Devices get the commands
+// queued while they were offline as soon as they subscribe to their command topic.
+func BuildMqttComponents(deps *Dependencies, useCases *UseCases) *mqtt.MessageRouter {
+	topics := deps.MQTTTopics
+	deps.MQTT.OnSubscribe(func(client *mqtt.Client, filter string) {
+		id, err := device.ToID(client.Identity)
+		if err != nil || !mqtt.Match(filter, topics.Commands(id)) {
+			return
+		}
+		if err := useCases.CommandUseCase.DeliverPending(context.Background(), id); err != nil {
+			logger.Log.Error("deliver pending device commands failed", zap.String("device_id", client.Identity), zap.Error(err))
+		}
+	})
+
+	router := mqtt.NewMessageRouter()
+	router.Register(topics.Ack(), mqttDevice.NewAckHandler(useCases.CommandUseCase))
+	router.Register(topics.Telemetry(), mqttDevice.NewTelemetryHandler(useCases.TelemetryUseCase))
+
+	return router
+}
+
+// StartMqtt serves the broker of the dependencies on the configured address, and
+// returns nil when no address is configured.
+func StartMqtt(deps *Dependencies, useCases *UseCases) *mqtt.Broker {
+	addr := config.App().MQTT.Addr
+	if addr == "" {
+		return nil
+	}
+
+	router := BuildMqttComponents(deps, useCases)
+	guard := mqttDevice.NewGuard(useCases.DeviceUseCase, deps.MQTTTopics)
+
+	go func() {
+		if err := deps.MQTT.ListenAndServe(addr, guard, router); err != nil &&
+			!errors.Is(err, mqtt.ErrBrokerClosed) {
+			logger.Log.Error("mqtt broker error", zap.Error(err))
+		}
+	}()
+
+	logger.Log.Info("mqtt broker started", zap.String("addr", addr))
+	return deps.MQTT
+}
@@ -0,0 +1,157 @@
+package bootstrap
+
+import (
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/transaction"
+	"github.com/HiroLiang/goat-server/internal/config"
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+	"github.com/HiroLiang/goat-server/internal/domain/agentusage"
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
+	"github.com/HiroLiang/goat-server/internal/domain/chatmember"
+	"github.com/HiroLiang/goat-server/internal/domain/chatmessage"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/identity"
+	"github.com/HiroLiang/goat-server/internal/domain/participant"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/domain/userrole"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	pgAgent "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/agent"
+	pgAgentModel "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/agentmodel"
+	pgAgentUsage "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/agentusage"
+	pgAPIKey "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/apikey"
+	pgChat "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/chat"
+	pgDevice "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/device"
+	pgIdentity "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/identity"
+	pgPermission "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/permission"
+	pgRole "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/role"
+	pgTelemetry "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/telemetry"
+	pgTwoFactor "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/twofactor"
+	pgUser "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/user"
+	pgUserrole "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/userrole"
+	liteAgent "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/agent"
+	liteAgentModel "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/agentmodel"
+	liteAgentUsage "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/agentusage"
+	liteAPIKey "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/apikey"
+	liteChat "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/chat"
+	liteDevice "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/device"
+	liteIdentity "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/identity"
+	litePermission "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/permission"
+	liteRole "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/role"
+	liteTelemetry "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/telemetry"
+	liteTwoFactor "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/twofactor"
+	liteUser "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/user"
+	liteUserrole "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/userrole"
+	"github.com/jmoiron/sqlx"
+)
+
+// repositories the database repositories of one storage backend, and the transactions spanning them
+type repositories struct {
+	TxManager   transaction.Manager
+	Agent       agent.Repository
+	AgentConfig agent.ConfigRepository
+	AgentModel  agentmodel.Repository
+	AgentUsage  agentusage.Repository
+	User        user.Repository
+	UserStatus  user.StatusHistoryRepository
+	UserRole    userrole.Repository
+	Role        role.Repository
+	TwoFactor   twofactor.Repository
+	Identity    identity.Repository
+	APIKey      apikey.Repository
+	Device      device.Repository
+	Command     devicecommand.Repository
+	Telemetry   telemetry.Repository
+	Permission  permission.Repository
+	ChatGroup   chatgroup.Repository
+	ChatMember  chatmember.Repository
+	ChatMessage chatmessage.Repository
+	Participant participant.Repository
+}
+
+// DatabaseBackend the database the repositories are stored in, Postgres unless configured
+func DatabaseBackend(conf *config.AppConfig) (database.DBName, error) {
+	if conf.DatabaseBackend == "" {
+		return database.Postgres, nil
+	}
+	return database.ParseDBName(conf.DatabaseBackend)
+}
+
+// buildRepositories build the repositories of the configured backend
+func buildRepositories(dataSources *database.DataSources, conf *config.AppConfig) (*repositories, error) {
+	backend, err := DatabaseBackend(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	db := dataSources.GetDB(backend)
+	if db == nil {
+		return nil, fmt.Errorf("database %s not initialized", backend)
+	}
+
+	var repos *repositories
+	switch backend {
+	case database.Sqlite:
+		repos = buildSqliteRepositories(db)
+	default:
+		repos = buildPostgresRepositories(db)
+	}
+	repos.TxManager = database.NewTxManager(db, backend)
+
+	return repos, nil
+}
+
+func buildPostgresRepositories(db *sqlx.DB) *repositories {
+	return &repositories{
+		Agent:       pgAgent.NewAgentRepository(db),
+		AgentConfig: pgAgent.NewAgentConfigRepository(db),
+		AgentModel:  pgAgentModel.NewModelRepository(db),
+		AgentUsage:  pgAgentUsage.NewUsageRepository(db),
+		User:        pgUser.NewUserRepository(db),
+		UserStatus:  pgUser.NewStatusHistoryRepository(db),
+		UserRole:    pgUserrole.NewUserRoleRepository(db),
+		Role:        pgRole.NewRoleRepository(db),
+		TwoFactor:   pgTwoFactor.NewTwoFactorRepository(db),
+		Identity:    pgIdentity.NewIdentityRepository(db),
+		APIKey:      pgAPIKey.NewAPIKeyRepository(db),
+		Device:      pgDevice.NewDeviceRepository(db),
+		Command:     pgDevice.NewDeviceCommandRepository(db),
+		Telemetry:   pgTelemetry.NewTelemetryRepository(db),
+		Permission:  pgPermission.NewPermissionRepository(db),
+		ChatGroup:   pgChat.NewChatGroupRepository(db),
+		ChatMember:  pgChat.NewChatMemberRepository(db),
+		ChatMessage: pgChat.NewChatMessageRepository(db),
+		Participant: pgChat.NewParticipantRepository(db),
+	}
+}
+
+func buildSqliteRepositories(db *sqlx.DB) *repositories {
+	return &repositories{
+		Agent:       liteAgent.NewAgentRepository(db),
+		AgentConfig: liteAgent.NewAgentConfigRepository(db),
+		AgentModel:  liteAgentModel.NewModelRepository(db),
+		AgentUsage:  liteAgentUsage.NewUsageRepository(db),
+		User:        liteUser.NewUserRepository(db),
+		UserStatus:  liteUser.NewStatusHistoryRepository(db),
+		UserRole:    liteUserrole.NewUserRoleRepository(db),
+		Role:        liteRole.NewRoleRepository(db),
+		TwoFactor:   liteTwoFactor.NewTwoFactorRepository(db),
+		Identity:    liteIdentity.NewIdentityRepository(db),
+		APIKey:      liteAPIKey.NewAPIKeyRepository(db),
+		Device:      liteDevice.NewDeviceRepository(db),
+		Command:     liteDevice.NewDeviceCommandRepository(db),
+		Telemetry:   liteTelemetry.NewTelemetryRepository(db),
+		Permission:  litePermission.NewPermissionRepository(db),
+		ChatGroup:   liteChat.NewChatGroupRepository(db),
+		ChatMember:  liteChat.NewChatMemberRepository(db),
+		ChatMessage: liteChat.NewChatMessageRepository(db),
+		Participant: liteChat.NewParticipantRepository(db),
+	}
+}
@@ -2,10 +2,14 @@ package bootstrap
 
 import (
 	"github.com/HiroLiang/goat-server/internal/config"
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+	"github.com/HiroLiang/goat-server/internal/interface/http/handler/admin"
 	"github.com/HiroLiang/goat-server/internal/interface/http/handler/agent"
+	apikeyHandler "github.com/HiroLiang/goat-server/internal/interface/http/handler/apikey"
 	"github.com/HiroLiang/goat-server/internal/interface/http/handler/chat"
 	"github.com/HiroLiang/goat-server/internal/interface/http/handler/device"
 	"github.com/HiroLiang/goat-server/internal/interface/http/handler/health"
+	"github.com/HiroLiang/goat-server/internal/interface/http/handler/identity"
 	"github.com/HiroLiang/goat-server/internal/interface/http/handler/test"
 	"github.com/HiroLiang/goat-server/internal/interface/http/handler/user"
 	"github.com/HiroLiang/goat-server/internal/interface/http/middleware"
@@ -18,7 +22,9 @@ func RegisterRestRoutes(group *gin.RouterGroup, useCases *UseCases, dependencies
 	group.Use(middleware.ErrorHandler())
 	group.Use(middleware.GlobalRateLimitMiddleware(dependencies.RateLimiter))
 	group.Use(middleware.IPRateLimitMiddleware(dependencies.RateLimiter))
-	group.Use(middleware.AuthMiddleware(dependencies.TokenService))
+	group.Use(middleware.AuthMiddleware(dependencies.TokenService, useCases.APIKeyUseCase, dependencies.UserRoleRepo))
+	group.Use(middleware.UserRateLimitMiddleware(dependencies.RateLimiter))
+	group.Use(middleware.RouteRateLimitMiddleware(dependencies.RateLimiter))
 	group.Use(middleware.ContextMiddleware())
 
 	// Test Handler
@@ -33,17 +39,42 @@ func RegisterRestRoutes(group *gin.RouterGroup, useCases *UseCases, dependencies
 
 	// User Handler
 	var userHandler = user.NewUserHandler(useCases.UserUseCase)
-	userHandler.RegisterUserRoutes(group.Group("/user"))
+	userHandler.RegisterUserRoutes(group.Group("/user", middleware.RequireScope(apikey.GroupUser)))
+
+	// API Key Handler
+	var apiKeyHandler = apikeyHandler.NewAPIKeyHandler(useCases.APIKeyUseCase)
+	apiKeyHandler.RegisterAPIKeyRoutes(group.Group("/user/api-keys",
+		middleware.RequireAuthMiddleware(),
+		middleware.RequireScope(apikey.GroupUser)))
+
+	// OIDC Handler
+	var identityHandler = identity.NewIdentityHandler(useCases.IdentityUseCase)
+	identityHandler.RegisterIdentityRoutes(group.Group("/oidc", middleware.RequireScope(apikey.GroupUser)))
+
+	// Admin Handler
+	var adminHandler = admin.NewAdminHandler(useCases.UserUseCase, useCases.Policy)
+	adminHandler.RegisterAdminRoutes(group.Group("/admin",
+		middleware.RequireAuthMiddleware(),
+		middleware.RequireScope(apikey.GroupAdmin)))
 
 	// Agent Handler
 	var agentHandler = agent.NewAgentHandler(useCases.AgentUseCase)
-	agentHandler.RegisterAgentRoutes(group.Group("/agent", middleware.RequireAuthMiddleware()))
+	agentHandler.RegisterAgentRoutes(group.Group("/agent",
+		middleware.RequireAuthMiddleware(),
+		middleware.RequireScope(apikey.GroupAgent)))
 
 	// Chat Handler
-	var chatHandler = chat.NewChatHandler(useCases.ChatUseCase)
-	chatHandler.RegisterChatRoutes(group.Group("/chat", middleware.RequireAuthMiddleware()))
+	var chatHandler = chat.NewChatHandler(useCases.ChatUseCase, useCases.Policy)
+	chatHandler.RegisterChatRoutes(group.Group("/chat",
+		middleware.RequireAuthMiddleware(),
+		middleware.RequireScope(apikey.GroupChat)))
 
 	// Device Handler
-	var deviceHandler = device.NewDeviceHandler()
-	deviceHandler.RegisterDeviceRoutes(group.Group("/device"))
+	var deviceHandler = device.NewDeviceHandler(useCases.DeviceUseCase, useCases.CommandUseCase, useCases.TelemetryUseCase)
+	deviceHandler.RegisterDeviceRoutes(group.Group("/device",
+		middleware.RequireAuthMiddleware(),
+		middleware.RequireScope(apikey.GroupDevice)))
+	deviceHandler.RegisterHeadlessRoutes(group.Group("/device",
+		middleware.DeviceAuthMiddleware(useCases.DeviceUseCase),
+		middleware.RequireDeviceMiddleware()))
 }
@@ -26,8 +26,8 @@ func NewServer(addr string, useCases *UseCases, dependencies *Dependencies) *htt
 	RegisterRestRoutes(r.Group("/api"), useCases, dependencies)
 
 	// Register WebSocket routes
-	hub, wsRouter := BuildWsComponents(dependencies)
-	RegisterWsRoutes(r, hub, wsRouter, dependencies)
+	hub, wsRouter := BuildWsComponents(dependencies, useCases)
+	RegisterWsRoutes(r, hub, wsRouter, dependencies, useCases)
 
 	// Setting server
 	return &http.Server{
@@ -61,8 +61,8 @@ func initConfig(r *gin.Engine) {
 			return false
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Accept", "Content-Type", "Authorization", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length", "Authorization", "X-Request-Id"},
+		AllowHeaders:     []string{"Origin", "Accept", "Content-Type", "Authorization", "X-Requested-With", "X-Device-ID"},
+		ExposeHeaders:    []string{"Content-Length", "Authorization", "X-Request-Id", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -2,25 +2,113 @@ package bootstrap
 
 import (
 	"github.com/HiroLiang/goat-server/internal/application/agent"
+	"github.com/HiroLiang/goat-server/internal/application/apikey"
 	"github.com/HiroLiang/goat-server/internal/application/chat"
+	"github.com/HiroLiang/goat-server/internal/application/device"
+	"github.com/HiroLiang/goat-server/internal/application/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/application/identity"
+	"github.com/HiroLiang/goat-server/internal/application/policy"
+	"github.com/HiroLiang/goat-server/internal/application/shared/devicechannel"
+	"github.com/HiroLiang/goat-server/internal/application/telemetry"
 	"github.com/HiroLiang/goat-server/internal/application/user"
+	mqttDevice "github.com/HiroLiang/goat-server/internal/interface/mqtt/handler/device"
+	wsDevice "github.com/HiroLiang/goat-server/internal/interface/ws/handler/device"
 )
 
 type UseCases struct {
-	UserUseCase  *user.UseCase
-	AgentUseCase *agent.UseCase
-	ChatUseCase  *chat.UseCase
+	Policy           *policy.Service
+	UserUseCase      *user.UseCase
+	IdentityUseCase  *identity.UseCase
+	APIKeyUseCase    *apikey.UseCase
+	DeviceUseCase    *device.UseCase
+	CommandUseCase   *devicecommand.UseCase
+	TelemetryUseCase *telemetry.UseCase
+	AgentUseCase     *agent.UseCase
+	ChatUseCase      *chat.UseCase
 }
 
 func BuildUseCases(deps *Dependencies) *UseCases {
+	policyService := policy.NewService(deps.UserRoleRepo, deps.PermissionRepo, deps.TwoFactorRepo)
+	agentUseCase := agent.NewUseCase(
+		deps.AgentRepo,
+		deps.AgentConfigRepo,
+		deps.AgentModelRepo,
+		deps.AgentUsageRepo,
+		deps.UserRoleRepo,
+		policyService,
+		deps.AgentQuota,
+		deps.ModelProviders,
+	)
+
 	return &UseCases{
-		UserUseCase:  user.NewUseCase(deps.UserRepo, deps.UserRoleRepo, deps.Hasher, deps.TokenService),
-		AgentUseCase: agent.NewUseCase(deps.AgentRepo),
+		Policy: policyService,
+		UserUseCase: user.NewUseCase(
+			deps.UserRepo,
+			deps.UserStatusRepo,
+			deps.UserRoleRepo,
+			deps.RoleRepo,
+			deps.TwoFactorRepo,
+			deps.DeviceRepo,
+			deps.ParticipantRepo,
+			deps.TxManager,
+			deps.Hasher,
+			deps.TokenService,
+			policyService,
+			deps.LoginLimiter,
+			deps.Mailer,
+			deps.ActionTokens,
+			deps.MailLimiter,
+			deps.UserMail,
+			deps.TwoFactor,
+		),
+		APIKeyUseCase: apikey.NewUseCase(
+			deps.APIKeyRepo,
+			deps.UserRepo,
+			deps.HMACer,
+			deps.APIKey,
+		),
+		DeviceUseCase: device.NewUseCase(
+			deps.DeviceRepo,
+			deps.UserRepo,
+			deps.TokenService,
+			deps.HMACer,
+		),
+		CommandUseCase: devicecommand.NewUseCase(
+			deps.CommandRepo,
+			deps.DeviceRepo,
+			devicechannel.Channels{
+				wsDevice.NewCommandChannel(deps.Hub),
+				mqttDevice.NewCommandChannel(deps.MQTT, deps.MQTTTopics),
+			},
+			deps.DeviceCommand,
+		),
+		TelemetryUseCase: telemetry.NewUseCase(
+			deps.TelemetryRepo,
+			deps.DeviceRepo,
+			wsDevice.NewTelemetryFeed(deps.Hub),
+			deps.Telemetry,
+		),
+		AgentUseCase: agentUseCase,
+		IdentityUseCase: identity.NewUseCase(
+			deps.OIDCProviders,
+			deps.IdentityRepo,
+			deps.UserRepo,
+			deps.TwoFactorRepo,
+			deps.TokenService,
+			deps.ActionTokens,
+			deps.OIDC,
+		),
 		ChatUseCase: chat.NewUseCase(
 			deps.ParticipantRepo,
 			deps.ChatGroupRepo,
 			deps.ChatMemberRepo,
 			deps.ChatMessageRepo,
+			deps.AgentDispatcher,
+			agent.NewReplyMeter(agentUseCase),
+			deps.Notifier,
+			deps.TxManager,
+			policyService,
+			deps.MaxAgentDepth,
 		),
 	}
 }
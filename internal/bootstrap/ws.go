@@ -1,15 +1,21 @@
 package bootstrap
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
 	"github.com/HiroLiang/goat-server/internal/interface/http/middleware"
 	"github.com/HiroLiang/goat-server/internal/interface/ws"
 	wsChat "github.com/HiroLiang/goat-server/internal/interface/ws/handler/chat"
+	wsDevice "github.com/HiroLiang/goat-server/internal/interface/ws/handler/device"
 	wsGame "github.com/HiroLiang/goat-server/internal/interface/ws/handler/game"
+	"github.com/HiroLiang/goat-server/internal/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
 )
 
 var upgrader = websocket.Upgrader{
@@ -21,29 +27,55 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-// BuildWsComponents creates and starts the Hub, and wires all message handlers
-// onto the router.
-func BuildWsComponents(deps *Dependencies) (*ws.Hub, *ws.MessageRouter) {
-	hub := ws.NewHub()
+// BuildWsComponents starts the Hub of the dependencies, and wires all message
+// handlers onto the router. Devices get the commands queued while they were offline
+// as soon as they connect.
+func BuildWsComponents(deps *Dependencies, useCases *UseCases) (*ws.Hub, *ws.MessageRouter) {
+	hub := deps.Hub
+	hub.OnDeviceConnect(func(deviceID string) {
+		id, err := device.ToID(deviceID)
+		if err != nil {
+			return
+		}
+		if err := useCases.CommandUseCase.DeliverPending(context.Background(), id); err != nil {
+			logger.Log.Error("deliver pending device commands failed", zap.String("device_id", deviceID), zap.Error(err))
+		}
+	})
 	go hub.Run()
 
 	router := ws.NewMessageRouter()
 	router.Register("chat.send", wsChat.NewMessageHandler())
 	router.Register("game.move", wsGame.NewMoveHandler())
+	router.Register("device.ack", wsDevice.NewAckHandler(useCases.CommandUseCase))
+	router.Register("device.telemetry", wsDevice.NewTelemetryHandler(useCases.TelemetryUseCase))
+	router.Register("device.telemetry.subscribe", wsDevice.NewSubscribeHandler(hub, useCases.TelemetryUseCase))
+	router.Register("device.telemetry.unsubscribe", wsDevice.NewUnsubscribeHandler(hub))
 
 	return hub, router
 }
 
-// RegisterWsRoutes registers the single /ws upgrade endpoint.
-func RegisterWsRoutes(r *gin.Engine, hub *ws.Hub, router *ws.MessageRouter, deps *Dependencies) {
+// RegisterWsRoutes registers the single /ws upgrade endpoint. Users connect with
+// their session token, headless devices with their device secret.
+func RegisterWsRoutes(r *gin.Engine, hub *ws.Hub, router *ws.MessageRouter, deps *Dependencies, useCases *UseCases) {
 	r.GET("/ws",
-		middleware.AuthMiddleware(deps.TokenService),
+		middleware.AuthMiddleware(deps.TokenService, nil, deps.UserRoleRepo),
+		middleware.DeviceAuthMiddleware(useCases.DeviceUseCase),
 		func(c *gin.Context) {
 			conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 			if err != nil {
 				return
 			}
 
+			if v, ok := c.Get("deviceContext"); ok {
+				deviceID := strconv.FormatInt(int64(v.(*device.Device).ID), 10)
+				client := ws.NewDeviceClient(hub, conn, deviceID)
+				hub.Register <- client
+
+				go client.WritePump()
+				go client.ReadPump(router)
+				return
+			}
+
 			userID := ""
 			if v, ok := c.Get("authContext"); ok {
 				userID = v.(*shared.AuthContext).UserID
@@ -14,23 +14,146 @@ import (
 // Add more config here if any new config been added in config.yaml.
 type AppConfig struct {
 	AuthToken struct {
-		Expiration time.Duration `mapstructure:"expiration"`
+		Mode              string        `mapstructure:"mode"`
+		Expiration        time.Duration `mapstructure:"expiration"`
+		AccessExpiration  time.Duration `mapstructure:"access_expiration"`
+		RefreshExpiration time.Duration `mapstructure:"refresh_expiration"`
 	} `mapstructure:"auth_token"`
 
 	Secrets struct {
-		HmacSecret string `mapstructure:"HMAC_SECRET"`
+		HmacSecret     string `mapstructure:"HMAC_SECRET"`
+		JwtSecret      string `mapstructure:"JWT_SECRET"`
+		PasswordPepper string `mapstructure:"PASSWORD_PEPPER"`
 	} `mapstructure:"secrets"`
 
+	PasswordHash struct {
+		Memory      uint32 `mapstructure:"memory"`
+		Iterations  uint32 `mapstructure:"iterations"`
+		Parallelism uint8  `mapstructure:"parallelism"`
+		SaltLength  uint32 `mapstructure:"salt_length"`
+		KeyLength   uint32 `mapstructure:"key_length"`
+		PepperID    string `mapstructure:"pepper_id"`
+	} `mapstructure:"password_hash"`
+
 	RateLimitConfig struct {
-		GlobalLimit int           `mapstructure:"global_limit"`
-		GlobalUnit  time.Duration `mapstructure:"global_unit"`
-		IPLimit     int           `mapstructure:"ip_limit"`
-		IPUnit      time.Duration `mapstructure:"ip_unit"`
-		UserLimit   int           `mapstructure:"user_limit"`
-		UserUnit    time.Duration `mapstructure:"user_unit"`
+		GlobalLimit     int                    `mapstructure:"global_limit"`
+		GlobalUnit      time.Duration          `mapstructure:"global_unit"`
+		GlobalAlgorithm string                 `mapstructure:"global_algorithm"`
+		IPLimit         int                    `mapstructure:"ip_limit"`
+		IPUnit          time.Duration          `mapstructure:"ip_unit"`
+		IPAlgorithm     string                 `mapstructure:"ip_algorithm"`
+		UserLimit       int                    `mapstructure:"user_limit"`
+		UserUnit        time.Duration          `mapstructure:"user_unit"`
+		UserAlgorithm   string                 `mapstructure:"user_algorithm"`
+		Routes          []RouteRateLimitConfig `mapstructure:"routes"`
 	} `mapstructure:"rate_limit_config"`
 
-	Database map[string]*DBConfig `mapstructure:"databases"`
+	LoginRateLimit struct {
+		MaxFailures   int64         `mapstructure:"max_failures"`
+		Window        time.Duration `mapstructure:"window"`
+		IPMaxFailures int64         `mapstructure:"ip_max_failures"`
+		IPWindow      time.Duration `mapstructure:"ip_window"`
+		BaseLockout   time.Duration `mapstructure:"base_lockout"`
+		MaxLockout    time.Duration `mapstructure:"max_lockout"`
+	} `mapstructure:"login_rate_limit"`
+
+	AgentQuotaConfig struct {
+		Default AgentQuota            `mapstructure:"default"`
+		Roles   map[string]AgentQuota `mapstructure:"roles"`
+	} `mapstructure:"agent_quota_config"`
+
+	AgentCatalog struct {
+		SyncInterval time.Duration                  `mapstructure:"sync_interval"`
+		Providers    map[string]AgentProviderConfig `mapstructure:"providers"`
+	} `mapstructure:"agent_catalog"`
+
+	Chat struct {
+		MaxAgentDepth int `mapstructure:"max_agent_depth"`
+	} `mapstructure:"chat"`
+
+	Mail struct {
+		Driver      string        `mapstructure:"driver"`
+		From        string        `mapstructure:"from"`
+		OutboxDir   string        `mapstructure:"outbox_dir"`
+		LinkBaseURL string        `mapstructure:"link_base_url"`
+		VerifyTTL   time.Duration `mapstructure:"verify_ttl"`
+		ResetTTL    time.Duration `mapstructure:"reset_ttl"`
+		RateLimit   int           `mapstructure:"rate_limit"`
+		RateWindow  time.Duration `mapstructure:"rate_window"`
+		SMTP        struct {
+			Host     string `mapstructure:"host"`
+			Port     int    `mapstructure:"port"`
+			Username string `mapstructure:"username"`
+			Password string `mapstructure:"password"`
+		} `mapstructure:"smtp"`
+	} `mapstructure:"mail"`
+
+	TwoFactor struct {
+		Issuer        string        `mapstructure:"issuer"`
+		ChallengeTTL  time.Duration `mapstructure:"challenge_ttl"`
+		Skew          int           `mapstructure:"skew"`
+		RecoveryCodes int           `mapstructure:"recovery_codes"`
+	} `mapstructure:"two_factor"`
+
+	OIDC struct {
+		StateTTL  time.Duration                 `mapstructure:"state_ttl"`
+		Providers map[string]OIDCProviderConfig `mapstructure:"providers"`
+	} `mapstructure:"oidc"`
+
+	APIKey struct {
+		DefaultTTL    time.Duration `mapstructure:"default_ttl"`
+		MaxTTL        time.Duration `mapstructure:"max_ttl"`
+		MaxPerUser    int           `mapstructure:"max_per_user"`
+		TouchInterval time.Duration `mapstructure:"touch_interval"`
+	} `mapstructure:"api_key"`
+
+	DeviceCommand struct {
+		DefaultTTL     time.Duration `mapstructure:"default_ttl"`
+		MaxTTL         time.Duration `mapstructure:"max_ttl"`
+		MaxOpen        int           `mapstructure:"max_open"`
+		ExpireInterval time.Duration `mapstructure:"expire_interval"`
+	} `mapstructure:"device_command"`
+
+	Telemetry struct {
+		MaxBatch        int                              `mapstructure:"max_batch"`
+		MaxAge          time.Duration                    `mapstructure:"max_age"`
+		MaxPoints       int                              `mapstructure:"max_points"`
+		RollupStep      time.Duration                    `mapstructure:"rollup_step"`
+		RawRetention    time.Duration                    `mapstructure:"raw_retention"`
+		RollupRetention time.Duration                    `mapstructure:"rollup_retention"`
+		RollupInterval  time.Duration                    `mapstructure:"rollup_interval"`
+		Schemas         map[string]TelemetrySchemaConfig `mapstructure:"schemas"`
+	} `mapstructure:"telemetry"`
+
+	MQTT struct {
+		Addr           string        `mapstructure:"addr"`
+		TopicPrefix    string        `mapstructure:"topic_prefix"`
+		MaxPacketSize  int           `mapstructure:"max_packet_size"`
+		ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+	} `mapstructure:"mqtt"`
+
+	Push struct {
+		CollapseWindow time.Duration `mapstructure:"collapse_window"`
+		PreviewLength  int           `mapstructure:"preview_length"`
+		Timeout        time.Duration `mapstructure:"timeout"`
+		FCM            struct {
+			CredentialsFile string `mapstructure:"credentials_file"`
+		} `mapstructure:"fcm"`
+		APNs struct {
+			KeyFile string `mapstructure:"key_file"`
+			KeyID   string `mapstructure:"key_id"`
+			TeamID  string `mapstructure:"team_id"`
+			Topic   string `mapstructure:"topic"`
+			Sandbox bool   `mapstructure:"sandbox"`
+		} `mapstructure:"apns"`
+	} `mapstructure:"push"`
+
+	DatabaseBackend string               `mapstructure:"database_backend"`
+	Database        map[string]*DBConfig `mapstructure:"databases"`
+
+	Migration struct {
+		OnBoot bool `mapstructure:"on_boot"`
+	} `mapstructure:"migration"`
 
 	Redis struct {
 		Addr     string `mapstructure:"addr"`
@@ -39,6 +162,47 @@ type AppConfig struct {
 	} `mapstructure:"redis"`
 }
 
+// AgentQuota daily agent budget of a role, 0 means unlimited
+type AgentQuota struct {
+	DailyTokens   int64 `mapstructure:"daily_tokens"`
+	DailyRequests int64 `mapstructure:"daily_requests"`
+}
+
+// AgentProviderConfig model provider the agent catalog is synced from, keyed by provider name
+type AgentProviderConfig struct {
+	Type    string `mapstructure:"type"`
+	BaseURL string `mapstructure:"base_url"`
+}
+
+// OIDCProviderConfig client registration at an OpenID Connect provider, keyed by provider name
+type OIDCProviderConfig struct {
+	Issuer       string `mapstructure:"issuer"`
+	ClientID     string `mapstructure:"client_id"`
+	ClientSecret string `mapstructure:"client_secret"`
+	RedirectURL  string `mapstructure:"redirect_url"`
+	Scopes       string `mapstructure:"scopes"` // space separated, empty = openid email profile
+}
+
+// RouteRateLimitConfig policy of a single route, such as "POST /api/user/login"
+type RouteRateLimitConfig struct {
+	Route     string        `mapstructure:"route"`
+	Limit     int           `mapstructure:"limit"`
+	Unit      time.Duration `mapstructure:"unit"`
+	Algorithm string        `mapstructure:"algorithm"`
+}
+
+// TelemetrySchemaConfig metrics a platform may report, custom allows any "custom." metric
+type TelemetrySchemaConfig struct {
+	Custom  bool                            `mapstructure:"custom"`
+	Metrics map[string]TelemetryRangeConfig `mapstructure:"metrics"`
+}
+
+// TelemetryRangeConfig bounds of a metric, an omitted bound is open
+type TelemetryRangeConfig struct {
+	Min *float64 `mapstructure:"min"`
+	Max *float64 `mapstructure:"max"`
+}
+
 type DBPoolConfig struct {
 	MaxOpenConns    int `mapstructure:"max_open_conns"`
 	MaxIdleConns    int `mapstructure:"max_idle_conns"`
@@ -93,8 +257,12 @@ func load(path string) error {
 		return fmt.Errorf("read config error: %w", err)
 	}
 
+	// Only string leaves can carry env references, lists and maps are kept as read
 	for _, key := range viper.AllKeys() {
-		val := viper.GetString(key)
+		val, ok := viper.Get(key).(string)
+		if !ok {
+			continue
+		}
 		if containsBraceEnv(val) {
 			val = expandEnvWithDefault(val)
 		} else {
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testYAML = `
+secrets:
+  JWT_SECRET: "${GOAT_TEST_SECRET:fallback}"
+rate_limit_config:
+  ip_limit: 10
+  ip_unit: 10s
+  routes:
+    - route: POST /api/user/login
+      limit: 10
+      unit: 1m
+    - route: POST /api/user/register
+      limit: 3
+      unit: 1h
+      algorithm: gcra
+`
+
+// TestLoad_KeepsRouteList Test env references are expanded without flattening lists
+func TestLoad_KeepsRouteList(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(testYAML), 0o644))
+	t.Setenv("GOAT_TEST_SECRET", "from-env")
+	t.Cleanup(viper.Reset)
+
+	require.NoError(t, load(dir))
+
+	assert.Equal(t, "from-env", config.Secrets.JwtSecret)
+	assert.Equal(t, 10, config.RateLimitConfig.IPLimit)
+	assert.Equal(t, []RouteRateLimitConfig{
+		{Route: "POST /api/user/login", Limit: 10, Unit: time.Minute},
+		{Route: "POST /api/user/register", Limit: 3, Unit: time.Hour, Algorithm: "gcra"},
+	}, config.RateLimitConfig.Routes)
+}
@@ -18,6 +18,17 @@ type Agent struct {
 	UpdatedBy user.ID
 }
 
+func NewAgent(name string, agentType Type, engine Engine, creator user.ID) *Agent {
+	return &Agent{
+		Name:      name,
+		Type:      agentType,
+		Status:    Available,
+		Engine:    engine,
+		CreatedBy: creator,
+		UpdatedBy: creator,
+	}
+}
+
 func (a Agent) InUse() bool {
 	return a.Status == Available
 }
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+const (
+	MaxTemperature   = 2.0
+	MaxStopSequences = 8
+)
+
+// Parameters tune how the model of an agent generates. MaxTokens 0 keeps the provider default.
+type Parameters struct {
+	Temperature   float64
+	MaxTokens     int
+	StopSequences []string
+	SystemPrompt  string
+}
+
+func (p Parameters) Validate() error {
+	if p.Temperature < 0 || p.Temperature > MaxTemperature {
+		return ErrInvalidParameters
+	}
+	if p.MaxTokens < 0 {
+		return ErrInvalidParameters
+	}
+	if len(p.StopSequences) > MaxStopSequences {
+		return ErrInvalidParameters
+	}
+	for _, s := range p.StopSequences {
+		if s == "" {
+			return ErrInvalidParameters
+		}
+	}
+	return nil
+}
+
+// Config is one immutable version of the model and parameters of an agent.
+// Every change creates a new version, so the history stays traceable.
+type Config struct {
+	ID         ConfigID
+	AgentID    ID
+	Version    int
+	ModelID    agentmodel.ID
+	Parameters Parameters
+	ChangeNote string
+	CreatedBy  user.ID
+	CreatedAt  time.Time
+}
+
+func NewConfig(
+	agentID ID,
+	modelID agentmodel.ID,
+	params Parameters,
+	note string,
+	creator user.ID,
+) (*Config, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		AgentID:    agentID,
+		ModelID:    modelID,
+		Parameters: params,
+		ChangeNote: note,
+		CreatedBy:  creator,
+	}, nil
+}
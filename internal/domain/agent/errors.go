@@ -1 +1,11 @@
 package agent
+
+import "errors"
+
+var (
+	ErrNotFound          = errors.New("agent not found")
+	ErrInvalidID         = errors.New("invalid agent id")
+	ErrConfigNotFound    = errors.New("agent config not found")
+	ErrInvalidParameters = errors.New("invalid agent parameters")
+	ErrForbidden         = errors.New("admin role required to manage agents")
+)
@@ -7,5 +7,17 @@ import (
 type Repository interface {
 	FindAll(ctx context.Context) ([]*Agent, error)
 	FindAllByStatus(ctx context.Context, status Status) ([]*Agent, error)
+	FindByID(ctx context.Context, id ID) (*Agent, error)
 	Create(ctx context.Context, agent *Agent) error
 }
+
+type ConfigRepository interface {
+	// FindCurrent returns the latest config version of the agent.
+	FindCurrent(ctx context.Context, agentID ID) (*Config, error)
+
+	// FindHistory returns every config version of the agent, newest first.
+	FindHistory(ctx context.Context, agentID ID) ([]*Config, error)
+
+	// Create stores config as the next version of its agent and fills ID, Version and CreatedAt.
+	Create(ctx context.Context, config *Config) error
+}
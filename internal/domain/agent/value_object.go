@@ -1,7 +1,19 @@
 package agent
 
+import "strconv"
+
 type ID int64
 
+func ToID(str string) (ID, error) {
+	i, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || i <= 0 {
+		return 0, ErrInvalidID
+	}
+	return ID(i), nil
+}
+
+type ConfigID int64
+
 type Type string
 
 type Status string
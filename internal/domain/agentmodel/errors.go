@@ -0,0 +1,8 @@
+package agentmodel
+
+import "errors"
+
+var (
+	ErrNotFound    = errors.New("model not found in catalog")
+	ErrUnavailable = errors.New("model is no longer offered by its provider")
+)
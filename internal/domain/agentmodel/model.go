@@ -0,0 +1,23 @@
+package agentmodel
+
+import "time"
+
+// Model is a catalog entry of a model offered by a configured provider.
+type Model struct {
+	ID            ID
+	Provider      string
+	Name          string
+	Family        string
+	ParameterSize string
+	Quantization  string
+	SizeBytes     int64
+	Digest        string
+	ModifiedAt    time.Time
+	IsAvailable   bool
+	SyncedAt      time.Time
+	CreatedAt     time.Time
+}
+
+func (m *Model) CanBeUsed() bool {
+	return m.IsAvailable
+}
@@ -0,0 +1,14 @@
+package agentmodel
+
+import "context"
+
+type Repository interface {
+	FindAll(ctx context.Context) ([]*Model, error)
+	FindByID(ctx context.Context, id ID) (*Model, error)
+
+	// Upsert inserts the model or refreshes the entry with the same provider and name.
+	Upsert(ctx context.Context, model *Model) error
+
+	// MarkMissing flags every model of provider not listed in present as unavailable.
+	MarkMissing(ctx context.Context, provider string, present []string) error
+}
@@ -0,0 +1,3 @@
+package agentmodel
+
+type ID int64
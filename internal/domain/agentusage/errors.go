@@ -0,0 +1,30 @@
+package agentusage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidUsage  = errors.New("invalid agent usage")
+	ErrInvalidRange  = errors.New("invalid usage date range")
+	ErrQuotaExceeded = errors.New("agent usage quota exceeded")
+	ErrForbidden     = errors.New("not allowed to view usage of other users")
+)
+
+// QuotaExceededError tells which daily budget has been used up and when it resets.
+type QuotaExceededError struct {
+	Kind    string
+	Limit   int64
+	Used    int64
+	ResetAt time.Time
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("daily %s quota exceeded: used %d of %d", e.Kind, e.Used, e.Limit)
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
@@ -0,0 +1,85 @@
+package agentusage
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+)
+
+// Quota is a daily budget. A zero value in either field means unlimited.
+type Quota struct {
+	DailyTokens   int64
+	DailyRequests int64
+}
+
+func (q Quota) IsUnlimited() bool {
+	return q.DailyTokens == 0 && q.DailyRequests == 0
+}
+
+// covers reports whether q is at least as generous as other.
+func (q Quota) covers(other Quota) bool {
+	return generous(q.DailyTokens, other.DailyTokens) && generous(q.DailyRequests, other.DailyRequests)
+}
+
+func generous(a, b int64) bool {
+	if a == 0 {
+		return true
+	}
+	return b != 0 && a >= b
+}
+
+// Check returns a QuotaExceededError when today's usage has used up the quota.
+func (q Quota) Check(today *Usage, now time.Time) error {
+	resetAt := Day(now).Add(24 * time.Hour)
+
+	if q.DailyTokens > 0 && today.TotalTokens() >= q.DailyTokens {
+		return &QuotaExceededError{
+			Kind:    "tokens",
+			Limit:   q.DailyTokens,
+			Used:    today.TotalTokens(),
+			ResetAt: resetAt,
+		}
+	}
+
+	if q.DailyRequests > 0 && today.RequestCount >= q.DailyRequests {
+		return &QuotaExceededError{
+			Kind:    "requests",
+			Limit:   q.DailyRequests,
+			Used:    today.RequestCount,
+			ResetAt: resetAt,
+		}
+	}
+
+	return nil
+}
+
+// QuotaPolicy maps roles to their daily quota.
+// Users without any configured role fall back to Default.
+type QuotaPolicy struct {
+	Default Quota
+	Roles   map[role.Type]Quota
+}
+
+// For returns the most generous quota among the given roles.
+func (p QuotaPolicy) For(roles []role.Type) Quota {
+	var (
+		best  Quota
+		found bool
+	)
+
+	for _, r := range roles {
+		q, ok := p.Roles[r]
+		if !ok {
+			continue
+		}
+		if !found || q.covers(best) {
+			best = q
+			found = true
+		}
+	}
+
+	if !found {
+		return p.Default
+	}
+	return best
+}
@@ -0,0 +1,19 @@
+package agentusage
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type Repository interface {
+	// Record adds the usage to the user/agent/day bucket it belongs to.
+	Record(ctx context.Context, usage *Usage) error
+
+	// SumByUserAndDay returns the usage of the user across all agents on day.
+	SumByUserAndDay(ctx context.Context, userID user.ID, day time.Time) (*Usage, error)
+
+	// FindByUser returns the per-agent, per-day usage of the user within [from, to].
+	FindByUser(ctx context.Context, userID user.ID, from, to time.Time) ([]*Usage, error)
+}
@@ -0,0 +1,67 @@
+package agentusage
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// Usage is the aggregated agent usage of one user on one agent for a single UTC day.
+type Usage struct {
+	UserID           user.ID
+	AgentID          agent.ID
+	Date             time.Time
+	PromptTokens     int64
+	CompletionTokens int64
+	RequestCount     int64
+	TotalLatency     time.Duration
+}
+
+// NewUsage builds the usage of a single agent request.
+func NewUsage(
+	userID user.ID,
+	agentID agent.ID,
+	promptTokens, completionTokens int64,
+	latency time.Duration,
+	at time.Time,
+) (*Usage, error) {
+	if promptTokens < 0 || completionTokens < 0 || latency < 0 {
+		return nil, ErrInvalidUsage
+	}
+
+	return &Usage{
+		UserID:           userID,
+		AgentID:          agentID,
+		Date:             Day(at),
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		RequestCount:     1,
+		TotalLatency:     latency,
+	}, nil
+}
+
+func (u *Usage) TotalTokens() int64 {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+func (u *Usage) AverageLatency() time.Duration {
+	if u.RequestCount == 0 {
+		return 0
+	}
+	return u.TotalLatency / time.Duration(u.RequestCount)
+}
+
+// Add merges other into u.
+func (u *Usage) Add(other *Usage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.RequestCount += other.RequestCount
+	u.TotalLatency += other.TotalLatency
+}
+
+// Day truncates t to the start of its UTC day, the bucket usage is metered in.
+func Day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
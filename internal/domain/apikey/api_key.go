@@ -0,0 +1,40 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// TokenPrefix starts every API key, so the auth middleware tells keys from session tokens
+const TokenPrefix = "goat_"
+
+// APIKey is a long-lived credential for scripts and bots. Only the hash of the
+// key is stored; Prefix keeps its first characters so users can tell keys apart.
+type APIKey struct {
+	ID         int64
+	UserID     user.ID
+	Name       string
+	Prefix     string
+	Hash       string
+	Scopes     []Scope
+	ExpiresAt  time.Time
+	LastUsedAt *time.Time
+	LastUsedIP string
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// IsActive reports whether the key may authenticate at the time
+func (k *APIKey) IsActive(at time.Time) bool {
+	return k.RevokedAt == nil && at.Before(k.ExpiresAt)
+}
+
+// NeedsTouch reports whether the last use is worth writing. Uses from the same
+// IP within the interval are skipped, so busy scripts do not write on every request.
+func (k *APIKey) NeedsTouch(at time.Time, ip string, interval time.Duration) bool {
+	if k.LastUsedAt == nil || k.LastUsedIP != ip {
+		return true
+	}
+	return at.Sub(*k.LastUsedAt) >= interval
+}
@@ -0,0 +1,13 @@
+package apikey
+
+import "errors"
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrInvalidScope   = errors.New("invalid api key scope")
+	ErrNameRequired   = errors.New("api key name required")
+	ErrInvalidExpiry  = errors.New("invalid api key expiry")
+	ErrTooManyKeys    = errors.New("too many api keys")
+	ErrKeyNotAllowed  = errors.New("api keys cannot manage api keys")
+)
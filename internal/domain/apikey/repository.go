@@ -0,0 +1,29 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type Repository interface {
+
+	// Create stores the key and sets its ID and creation time
+	Create(ctx context.Context, k *APIKey) error
+
+	// FindByHash returns the key with the hash, or ErrAPIKeyNotFound
+	FindByHash(ctx context.Context, hash string) (*APIKey, error)
+
+	// FindByUser returns the keys of the user that are neither revoked nor expired, newest first
+	FindByUser(ctx context.Context, userID user.ID) ([]*APIKey, error)
+
+	// CountActive counts the keys of the user that are neither revoked nor expired
+	CountActive(ctx context.Context, userID user.ID) (int, error)
+
+	// Revoke revokes a key of the user, or returns ErrAPIKeyNotFound
+	Revoke(ctx context.Context, userID user.ID, id int64) error
+
+	// Touch records the last use of the key
+	Touch(ctx context.Context, id int64, at time.Time, ip string) error
+}
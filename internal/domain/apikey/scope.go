@@ -0,0 +1,60 @@
+package apikey
+
+import "strings"
+
+// Scope limits an API key to a route group, "<group>:read" or "<group>:write".
+// Write implies read; read only reaches GET requests.
+type Scope string
+
+// Route groups a key can be scoped to
+const (
+	GroupUser   = "user"
+	GroupAdmin  = "admin"
+	GroupAgent  = "agent"
+	GroupChat   = "chat"
+	GroupDevice = "device"
+)
+
+const (
+	UserRead    Scope = "user:read"
+	UserWrite   Scope = "user:write"
+	AdminRead   Scope = "admin:read"
+	AdminWrite  Scope = "admin:write"
+	AgentRead   Scope = "agent:read"
+	AgentWrite  Scope = "agent:write"
+	ChatRead    Scope = "chat:read"
+	ChatWrite   Scope = "chat:write"
+	DeviceRead  Scope = "device:read"
+	DeviceWrite Scope = "device:write"
+)
+
+var All = []Scope{
+	UserRead, UserWrite,
+	AdminRead, AdminWrite,
+	AgentRead, AgentWrite,
+	ChatRead, ChatWrite,
+	DeviceRead, DeviceWrite,
+}
+
+func ParseScope(s string) (Scope, error) {
+	scope := Scope(strings.TrimSpace(s))
+	for _, known := range All {
+		if scope == known {
+			return scope, nil
+		}
+	}
+	return "", ErrInvalidScope
+}
+
+// Allows reports whether the scopes reach the route group, for a write when write is set
+func Allows(scopes []Scope, group string, write bool) bool {
+	for _, s := range scopes {
+		if s == Scope(group+":write") {
+			return true
+		}
+		if !write && s == Scope(group+":read") {
+			return true
+		}
+	}
+	return false
+}
@@ -6,4 +6,6 @@ var (
 	ErrSessionNotFound = errors.New("session not found")
 	ErrGenerateToken   = errors.New("generate token error")
 	ErrRefreshToken    = errors.New("refresh token error")
+	ErrInvalidToken    = errors.New("invalid token")
+	ErrTokenReused     = errors.New("refresh token reused")
 )
@@ -2,16 +2,30 @@ package auth
 
 import "time"
 
+// Session is a login of a user on one device. ID is an opaque identifier that
+// is safe to show to the user; the bearer token itself is never part of a listing.
+// DeviceID is the client device ID sent at login, empty for browsers and scripts.
 type Session struct {
-	ID        string
-	UserID    string
-	IP        string
-	UserAgent string
-	CreatedAt time.Time
+	ID         string
+	UserID     string
+	IP         string
+	UserAgent  string
+	DeviceID   string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
 }
 
 type CreateSessionParams struct {
 	UserID    string
 	IP        string
 	UserAgent string
+	DeviceID  string
+}
+
+// TokenPair is handed out on login and refresh. RefreshToken is empty in
+// opaque token mode, where the access token itself is the session.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
 }
@@ -7,4 +7,5 @@ var (
 	ErrDeleted   = errors.New("chat group has been deleted")
 	ErrFull      = errors.New("chat group has reached its member limit")
 	ErrForbidden = errors.New("operation not permitted for this chat group type")
+	ErrInvalid   = errors.New("invalid chat group")
 )
@@ -1,6 +1,7 @@
 package chatmember
 
 import (
+	"strings"
 	"time"
 
 	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
@@ -18,8 +19,14 @@ type ChatMember struct {
 	IsPinned      bool
 	LastReadAt    *time.Time
 	UpdatedAt     time.Time
+
+	// ResponsePolicy and Keywords only apply to agent members.
+	ResponsePolicy ResponsePolicy
+	Keywords       []string
 }
 
+const MaxKeywords = 20
+
 func NewChatMember(groupID chatgroup.ID, participantID participant.ID, role Role) *ChatMember {
 	return &ChatMember{
 		GroupID:       groupID,
@@ -28,6 +35,21 @@ func NewChatMember(groupID chatgroup.ID, participantID participant.ID, role Role
 	}
 }
 
+// NewAgentMember adds an agent to a group with the default policy of the group type:
+// agents in BOT and DIRECT groups always reply, elsewhere only when mentioned.
+func NewAgentMember(group *chatgroup.ChatGroup, participantID participant.ID) *ChatMember {
+	m := NewChatMember(group.ID, participantID, Member)
+	m.ResponsePolicy = DefaultResponsePolicy(group.Type)
+	return m
+}
+
+func DefaultResponsePolicy(groupType chatgroup.GroupType) ResponsePolicy {
+	if groupType == chatgroup.Bot || groupType == chatgroup.Direct {
+		return RespondAlways
+	}
+	return RespondOnMention
+}
+
 func (m *ChatMember) IsOwner() bool {
 	return m.Role == Owner
 }
@@ -43,3 +65,53 @@ func (m *ChatMember) CanManageMembers() bool {
 func (m *ChatMember) MarkAsRead(at time.Time) {
 	m.LastReadAt = &at
 }
+
+// SetResponsePolicy replaces the policy. KEYWORD requires at least one keyword;
+// keywords are trimmed, lower-cased and de-duplicated.
+func (m *ChatMember) SetResponsePolicy(policy ResponsePolicy, keywords []string) error {
+	if !policy.IsValid() {
+		return ErrInvalidPolicy
+	}
+
+	seen := make(map[string]bool, len(keywords))
+	cleaned := make([]string, 0, len(keywords))
+	for _, k := range keywords {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		cleaned = append(cleaned, k)
+	}
+
+	if len(cleaned) > MaxKeywords || (policy == RespondOnKeyword && len(cleaned) == 0) {
+		return ErrInvalidPolicy
+	}
+
+	m.ResponsePolicy = policy
+	m.Keywords = cleaned
+	return nil
+}
+
+// ShouldRespond reports whether the agent member replies to a message.
+// A mention always triggers a reply, whatever the policy.
+func (m *ChatMember) ShouldRespond(content string, mentioned bool) bool {
+	if mentioned {
+		return true
+	}
+
+	switch m.ResponsePolicy {
+	case RespondAlways:
+		return true
+	case RespondOnKeyword:
+		lower := strings.ToLower(content)
+		for _, k := range m.Keywords {
+			if strings.Contains(lower, k) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
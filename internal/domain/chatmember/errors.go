@@ -7,4 +7,5 @@ var (
 	ErrAlreadyMember     = errors.New("participant is already a member of this group")
 	ErrForbidden         = errors.New("insufficient role to perform this action")
 	ErrCannotRemoveOwner = errors.New("cannot remove the group owner")
+	ErrInvalidPolicy     = errors.New("invalid response policy")
 )
@@ -10,3 +10,21 @@ const (
 	Member Role = "MEMBER"
 	Guest  Role = "GUEST"
 )
+
+// ResponsePolicy decides when an agent member replies to a group message.
+type ResponsePolicy string
+
+const (
+	RespondAlways    ResponsePolicy = "ALWAYS"
+	RespondOnMention ResponsePolicy = "MENTION"
+	RespondOnKeyword ResponsePolicy = "KEYWORD"
+)
+
+func (p ResponsePolicy) IsValid() bool {
+	switch p {
+	case RespondAlways, RespondOnMention, RespondOnKeyword:
+		return true
+	default:
+		return false
+	}
+}
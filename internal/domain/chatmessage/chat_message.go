@@ -14,6 +14,11 @@ type ChatMessage struct {
 	Content   string
 	Type      MessageType
 	ReplyToID *ID
+
+	// AgentDepth counts the agent replies chained since the last human message.
+	// Human messages are 0; an agent reply is one deeper than the message it answers.
+	AgentDepth int
+
 	IsEdited  bool
 	IsDeleted bool
 	CreatedAt time.Time
@@ -50,3 +55,22 @@ func (m *ChatMessage) IsActive() bool {
 func (m *ChatMessage) IsReply() bool {
 	return m.ReplyToID != nil
 }
+
+// NewAgentReply creates the reply of an agent to trigger, one level deeper in the agent chain.
+func NewAgentReply(trigger *ChatMessage, senderID participant.ID, content string) *ChatMessage {
+	replyTo := trigger.ID
+	return &ChatMessage{
+		GroupID:    trigger.GroupID,
+		SenderID:   senderID,
+		Content:    content,
+		Type:       Text,
+		ReplyToID:  &replyTo,
+		AgentDepth: trigger.AgentDepth + 1,
+	}
+}
+
+// AllowsAgentReply reports whether agents may still reply without exceeding maxDepth.
+// maxDepth only bounds agents answering agents: human messages may always be answered.
+func (m *ChatMessage) AllowsAgentReply(maxDepth int) bool {
+	return m.AgentDepth == 0 || m.AgentDepth < maxDepth
+}
@@ -6,4 +6,9 @@ var (
 	ErrNotFound  = errors.New("chat message not found")
 	ErrDeleted   = errors.New("chat message has been deleted")
 	ErrForbidden = errors.New("operation not permitted for this chat message")
+	ErrEmpty     = errors.New("chat message content is empty")
+	ErrTooLong   = errors.New("chat message content is too long")
+
+	// ErrNoRequester is returned when an agent chain does not lead back to a user message
+	ErrNoRequester = errors.New("chat message was not started by a user")
 )
@@ -0,0 +1,61 @@
+package chatmessage
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/HiroLiang/goat-server/internal/domain/participant"
+)
+
+// mentionPattern matches "@handle" at the start of the content or after a
+// non-word character, so e-mail addresses are not taken for mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])@([\p{L}\p{N}_.\-]+)`)
+
+// MentionHandle normalizes a display name into the handle used to mention it:
+// lower-cased with spaces removed, e.g. "Code Reviewer" is mentioned as @codereviewer.
+func MentionHandle(displayName string) string {
+	return strings.ToLower(strings.Join(strings.Fields(displayName), ""))
+}
+
+// ParseMentions returns the normalized handles mentioned in content, in order of first appearance.
+func ParseMentions(content string) []string {
+	matches := mentionPattern.FindAllStringSubmatch(content, -1)
+
+	seen := make(map[string]bool, len(matches))
+	handles := make([]string, 0, len(matches))
+	for _, match := range matches {
+		handle := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		if handle == "" || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		handles = append(handles, handle)
+	}
+
+	return handles
+}
+
+// ResolveMentions maps the handles mentioned in content to the participants whose
+// display names they match. Unknown handles are ignored.
+func ResolveMentions(content string, candidates []*participant.Participant) []participant.ID {
+	handles := ParseMentions(content)
+	if len(handles) == 0 {
+		return nil
+	}
+
+	byHandle := make(map[string]participant.ID, len(candidates))
+	for _, p := range candidates {
+		if handle := MentionHandle(p.DisplayName); handle != "" {
+			byHandle[handle] = p.ID
+		}
+	}
+
+	ids := make([]participant.ID, 0, len(handles))
+	for _, handle := range handles {
+		if id, ok := byHandle[handle]; ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,104 @@
+package device
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// SecretPrefix starts every device secret, so the auth middleware tells secrets from session tokens
+const SecretPrefix = "gdev_"
+
+const (
+	maxDeviceIDLength  = 128
+	maxNameLength      = 64
+	maxPushTokenLength = 4096
+)
+
+// Device is a client installation of a user. DeviceID is chosen by the client
+// and is unique per user, so registering it again updates the same device.
+// SessionID is the login session that registered the device last. PushToken is
+// the FCM or APNs registration of the installation, empty when it takes no pushes.
+// SecretHash is the HMAC of the secret a headless device connects with.
+type Device struct {
+	ID         ID
+	UserID     user.ID
+	DeviceID   string
+	Name       string
+	Platform   Platform
+	SessionID  string
+	PushToken  string
+	SecretHash string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func New(userID user.ID, deviceID, name, platform, sessionID string) (*Device, error) {
+	deviceID = strings.TrimSpace(deviceID)
+	if !validDeviceID(deviceID) {
+		return nil, ErrInvalidDeviceID
+	}
+
+	name, err := ParseName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := ParsePlatform(platform)
+	if err != nil {
+		return nil, err
+	}
+
+	if sessionID == "" {
+		return nil, ErrSessionRequired
+	}
+
+	return &Device{
+		UserID:    userID,
+		DeviceID:  deviceID,
+		Name:      name,
+		Platform:  p,
+		SessionID: sessionID,
+	}, nil
+}
+
+// SetPushToken sets the push token, an empty token turns pushes off
+func (d *Device) SetPushToken(token string) error {
+	token = strings.TrimSpace(token)
+	if len(token) > maxPushTokenLength || strings.ContainsFunc(token, unicode.IsSpace) {
+		return ErrInvalidPushToken
+	}
+	d.PushToken = token
+	return nil
+}
+
+// IsHeadless reports whether the device runs without a user, such as a Raspberry Pi.
+// Headless devices connect with their secret and take commands.
+func (d *Device) IsHeadless() bool {
+	return d.Platform == Embedded
+}
+
+// ParseName trims the display name of a device and checks its length
+func ParseName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return "", ErrInvalidName
+	}
+	return name, nil
+}
+
+// validDeviceID accepts printable identifiers without spaces, such as UUIDs or vendor IDs
+func validDeviceID(id string) bool {
+	if id == "" || len(id) > maxDeviceIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) || unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
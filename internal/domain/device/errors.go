@@ -0,0 +1,16 @@
+package device
+
+import "errors"
+
+var (
+	ErrDeviceNotFound    = errors.New("device not found")
+	ErrInvalidID         = errors.New("invalid device id")
+	ErrInvalidDeviceID   = errors.New("invalid client device id")
+	ErrInvalidName       = errors.New("invalid device name")
+	ErrInvalidPlatform   = errors.New("invalid device platform")
+	ErrSessionRequired   = errors.New("device registration requires a login session")
+	ErrInvalidPushToken  = errors.New("invalid push token")
+	ErrPushTokenRejected = errors.New("push token rejected by provider")
+	ErrInvalidSecret     = errors.New("invalid device secret")
+	ErrNotHeadless       = errors.New("device takes no commands")
+)
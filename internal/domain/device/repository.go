@@ -0,0 +1,38 @@
+package device
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type Repository interface {
+
+	// Register stores the device, or updates the name, platform, session, push token and
+	// secret of the device the user registered with the same DeviceID. It sets ID and the times.
+	Register(ctx context.Context, d *Device) error
+
+	// Find returns a device of the user, or ErrDeviceNotFound
+	Find(ctx context.Context, userID user.ID, id ID) (*Device, error)
+
+	// FindByID returns a device of any user, or ErrDeviceNotFound
+	FindByID(ctx context.Context, id ID) (*Device, error)
+
+	// FindBySecretHash returns the device of the secret, or ErrDeviceNotFound
+	FindBySecretHash(ctx context.Context, hash string) (*Device, error)
+
+	// FindByUser returns the devices of the user, most recently registered first
+	FindByUser(ctx context.Context, userID user.ID) ([]*Device, error)
+
+	// FindPushTargets returns the devices of the users that have a push token
+	FindPushTargets(ctx context.Context, userIDs []user.ID) ([]*Device, error)
+
+	// ClearPushToken removes the push token from every device holding it
+	ClearPushToken(ctx context.Context, token string) error
+
+	// Rename renames a device of the user, or returns ErrDeviceNotFound
+	Rename(ctx context.Context, userID user.ID, id ID, name string) error
+
+	// Delete removes a device of the user, or returns ErrDeviceNotFound
+	Delete(ctx context.Context, userID user.ID, id ID) error
+}
@@ -0,0 +1,38 @@
+package device
+
+import (
+	"strconv"
+	"strings"
+)
+
+type ID int64
+
+func ToID(str string) (ID, error) {
+	i, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || i <= 0 {
+		return 0, ErrInvalidID
+	}
+	return ID(i), nil
+}
+
+type Platform string
+
+const (
+	IOS      Platform = "ios"
+	Android  Platform = "android"
+	Web      Platform = "web"
+	Desktop  Platform = "desktop"
+	Embedded Platform = "embedded"
+)
+
+var Platforms = []Platform{IOS, Android, Web, Desktop, Embedded}
+
+func ParsePlatform(s string) (Platform, error) {
+	p := Platform(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Platforms {
+		if p == known {
+			return p, nil
+		}
+	}
+	return "", ErrInvalidPlatform
+}
@@ -0,0 +1,115 @@
+package devicecommand
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+const (
+	maxTypeLength    = 64
+	maxPayloadLength = 16 << 10
+	maxResultLength  = 2 << 10
+	maxErrorLength   = 512
+)
+
+// Command is an order a user sends to a headless device. Type names what the
+// device should do, such as "gpio.write", and Payload holds its JSON arguments.
+// Result and Error are what the device acknowledged the command with.
+type Command struct {
+	ID          ID
+	DeviceID    device.ID
+	UserID      user.ID
+	Type        string
+	Payload     json.RawMessage
+	Status      Status
+	Result      json.RawMessage
+	Error       string
+	ExpiresAt   time.Time
+	DeliveredAt *time.Time
+	CompletedAt *time.Time
+	CreatedAt   time.Time
+}
+
+func New(deviceID device.ID, userID user.ID, commandType string, payload json.RawMessage, expiresAt time.Time) (*Command, error) {
+	commandType = strings.TrimSpace(commandType)
+	if !validType(commandType) {
+		return nil, ErrInvalidType
+	}
+
+	payload = bytes.TrimSpace(payload)
+	if len(payload) == 0 {
+		payload = json.RawMessage(`{}`)
+	}
+	if len(payload) > maxPayloadLength || payload[0] != '{' || !json.Valid(payload) {
+		return nil, ErrInvalidPayload
+	}
+
+	if !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	return &Command{
+		DeviceID:  deviceID,
+		UserID:    userID,
+		Type:      commandType,
+		Payload:   payload,
+		Status:    Pending,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// IsExpired reports whether the command ran out of time without an acknowledgement
+func (c *Command) IsExpired(at time.Time) bool {
+	return c.Status == Expired || (c.Status.IsOpen() && !at.Before(c.ExpiresAt))
+}
+
+// Complete records the acknowledgement of the device. A failed command may carry
+// an error message, a result is any JSON value.
+func (c *Command) Complete(status Status, result json.RawMessage, errMessage string, at time.Time) error {
+	if !c.Status.IsOpen() || c.IsExpired(at) {
+		return ErrCommandClosed
+	}
+	if status != Succeeded && status != Failed {
+		return ErrInvalidStatus
+	}
+
+	result = bytes.TrimSpace(result)
+	if len(result) > maxResultLength || (len(result) > 0 && !json.Valid(result)) {
+		return ErrInvalidResult
+	}
+
+	errMessage = strings.TrimSpace(errMessage)
+	if utf8.RuneCountInString(errMessage) > maxErrorLength {
+		return ErrInvalidResult
+	}
+
+	c.Status = status
+	c.Result = result
+	c.Error = errMessage
+	c.CompletedAt = &at
+	return nil
+}
+
+// validType accepts dotted lowercase names, such as "reboot" or "gpio.write"
+func validType(t string) bool {
+	if t == "" || len(t) > maxTypeLength {
+		return false
+	}
+	for _, part := range strings.Split(t, ".") {
+		if part == "" || part[0] < 'a' || part[0] > 'z' {
+			return false
+		}
+		for _, r := range part {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
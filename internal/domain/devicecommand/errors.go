@@ -0,0 +1,15 @@
+package devicecommand
+
+import "errors"
+
+var (
+	ErrCommandNotFound = errors.New("device command not found")
+	ErrInvalidID       = errors.New("invalid device command id")
+	ErrInvalidType     = errors.New("invalid device command type")
+	ErrInvalidPayload  = errors.New("invalid device command payload")
+	ErrInvalidExpiry   = errors.New("invalid device command expiry")
+	ErrInvalidResult   = errors.New("invalid device command result")
+	ErrInvalidStatus   = errors.New("invalid device command status")
+	ErrCommandClosed   = errors.New("device command already completed or expired")
+	ErrTooManyPending  = errors.New("too many open commands for the device")
+)
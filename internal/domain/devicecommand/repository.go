@@ -0,0 +1,39 @@
+package devicecommand
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type Repository interface {
+
+	// Create stores the command and sets its ID and creation time
+	Create(ctx context.Context, c *Command) error
+
+	// Find returns a command the user sent to the device, or ErrCommandNotFound
+	Find(ctx context.Context, userID user.ID, deviceID device.ID, id ID) (*Command, error)
+
+	// FindForDevice returns a command of the device, or ErrCommandNotFound
+	FindForDevice(ctx context.Context, deviceID device.ID, id ID) (*Command, error)
+
+	// FindByDevice returns the latest commands the user sent to the device, newest first
+	FindByDevice(ctx context.Context, userID user.ID, deviceID device.ID, limit int) ([]*Command, error)
+
+	// FindUndelivered returns the pending commands of the device that expire after at, oldest first
+	FindUndelivered(ctx context.Context, deviceID device.ID, at time.Time) ([]*Command, error)
+
+	// CountOpen counts the pending and delivered commands of the device that expire after at
+	CountOpen(ctx context.Context, deviceID device.ID, at time.Time) (int, error)
+
+	// MarkDelivered moves a pending command to delivered, other commands are left as they are
+	MarkDelivered(ctx context.Context, id ID, at time.Time) error
+
+	// Complete stores the outcome of an open command, or returns ErrCommandClosed
+	Complete(ctx context.Context, c *Command) error
+
+	// ExpireDue expires the open commands that ran out of time by at, and returns how many
+	ExpireDue(ctx context.Context, at time.Time) (int64, error)
+}
@@ -0,0 +1,42 @@
+package devicecommand
+
+import (
+	"strconv"
+	"strings"
+)
+
+type ID int64
+
+func ToID(str string) (ID, error) {
+	i, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || i <= 0 {
+		return 0, ErrInvalidID
+	}
+	return ID(i), nil
+}
+
+// Status is where a command is in its lifecycle. Pending commands wait for the
+// device to connect, delivered ones for its acknowledgement.
+type Status string
+
+const (
+	Pending   Status = "pending"
+	Delivered Status = "delivered"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+	Expired   Status = "expired"
+)
+
+// IsOpen reports whether the command still waits for the device
+func (s Status) IsOpen() bool {
+	return s == Pending || s == Delivered
+}
+
+// ParseOutcome parses the status a device acknowledges a command with
+func ParseOutcome(s string) (Status, error) {
+	status := Status(strings.ToLower(strings.TrimSpace(s)))
+	if status != Succeeded && status != Failed {
+		return "", ErrInvalidStatus
+	}
+	return status, nil
+}
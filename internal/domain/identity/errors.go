@@ -0,0 +1,12 @@
+package identity
+
+import "errors"
+
+var (
+	ErrIdentityNotFound = errors.New("identity not found")
+	ErrAlreadyLinked    = errors.New("identity already linked")
+	ErrUnknownProvider  = errors.New("unknown identity provider")
+	ErrEmailNotVerified = errors.New("identity email not verified")
+	ErrInvalidIDToken   = errors.New("invalid id token")
+	ErrLinkSession      = errors.New("identity link belongs to another session")
+)
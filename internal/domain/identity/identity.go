@@ -0,0 +1,28 @@
+package identity
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// Identity links an account at an external OpenID Connect provider to a local
+// user. The subject is the stable account ID at the provider, the email only
+// records what the provider reported when the link was made.
+type Identity struct {
+	ID        int64
+	UserID    user.ID
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
+
+func New(userID user.ID, provider, subject, email string) *Identity {
+	return &Identity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+	}
+}
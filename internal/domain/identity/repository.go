@@ -0,0 +1,23 @@
+package identity
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type Repository interface {
+
+	// FindBySubject returns the identity of the provider account, or ErrIdentityNotFound
+	FindBySubject(ctx context.Context, provider, subject string) (*Identity, error)
+
+	// FindByUser returns every identity linked to the user
+	FindByUser(ctx context.Context, userID user.ID) ([]*Identity, error)
+
+	// Create links the identity, or returns ErrAlreadyLinked when the provider account
+	// or the provider of the user is linked already
+	Create(ctx context.Context, i *Identity) error
+
+	// Delete unlinks the provider from the user, or returns ErrIdentityNotFound
+	Delete(ctx context.Context, userID user.ID, provider string) error
+}
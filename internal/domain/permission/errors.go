@@ -0,0 +1,7 @@
+package permission
+
+import "errors"
+
+var (
+	ErrDenied = errors.New("permission denied")
+)
@@ -0,0 +1,54 @@
+package permission
+
+import (
+	"slices"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+)
+
+// Permission is a named action, written as "<resource>:<action>".
+type Permission string
+
+const (
+	AgentManage       Permission = "agent:manage"
+	AgentViewUsage    Permission = "agent:view_usage"
+	ChatCreateChannel Permission = "chat:create_channel"
+	UserApprove       Permission = "user:approve"
+	UserBan           Permission = "user:ban"
+	UserUnlock        Permission = "user:unlock"
+	UserManageRoles   Permission = "user:manage_roles"
+)
+
+// All lists every known permission.
+var All = []Permission{
+	AgentManage,
+	AgentViewUsage,
+	ChatCreateChannel,
+	UserApprove,
+	UserBan,
+	UserUnlock,
+	UserManageRoles,
+}
+
+// DefaultGrants are the permissions each role starts with. The seed migration
+// inserts the same rows, so keep both in sync.
+var DefaultGrants = map[role.Type][]Permission{
+	role.Admin:  All,
+	role.Vendor: {ChatCreateChannel},
+	role.User:   {ChatCreateChannel},
+	role.Guest:  {},
+}
+
+// Merge returns the sorted union of the given permission sets.
+func Merge(sets ...[]Permission) []Permission {
+	merged := make([]Permission, 0)
+	for _, set := range sets {
+		for _, p := range set {
+			if !slices.Contains(merged, p) {
+				merged = append(merged, p)
+			}
+		}
+	}
+	slices.Sort(merged)
+	return merged
+}
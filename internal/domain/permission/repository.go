@@ -0,0 +1,12 @@
+package permission
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+)
+
+type Repository interface {
+	// FindByRole returns the permissions granted to the role.
+	FindByRole(ctx context.Context, roleType role.Type) ([]Permission, error)
+}
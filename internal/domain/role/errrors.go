@@ -3,5 +3,6 @@ package role
 import "errors"
 
 var (
-	ErrInvalidType = errors.New("invalid role type")
+	ErrInvalidType  = errors.New("invalid role type")
+	ErrRoleNotFound = errors.New("role not found")
 )
@@ -0,0 +1,10 @@
+package role
+
+import "context"
+
+type Repository interface {
+	FindAll(ctx context.Context) ([]*Role, error)
+
+	// SetRequireTwoFactor turns the 2FA requirement of the role on or off, ErrRoleNotFound if unknown
+	SetRequireTwoFactor(ctx context.Context, roleType Type, required bool) error
+}
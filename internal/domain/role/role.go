@@ -7,9 +7,12 @@ import (
 )
 
 type Role struct {
-	ID        ID
-	Type      Type
-	Creator   user.ID
-	CreateAt  time.Time
-	UpdatedAt time.Time
+	ID   ID
+	Type Type
+
+	// RequireTwoFactor withholds the permissions of the role from members without 2FA
+	RequireTwoFactor bool
+	Creator          user.ID
+	CreateAt         time.Time
+	UpdatedAt        time.Time
 }
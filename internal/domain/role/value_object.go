@@ -13,7 +13,8 @@ const (
 	Guest  Type = "guest"
 )
 
-func typeFrom(s string) (Type, error) {
+// ParseType converts a string into a known role type
+func ParseType(s string) (Type, error) {
 	switch Type(s) {
 	case Admin, Vendor, User, Guest:
 		return Type(s), nil
@@ -28,7 +29,7 @@ func (t *Type) UnmarshalJSON(b []byte) error {
 		return err
 	}
 
-	v, err := typeFrom(s)
+	v, err := ParseType(s)
 	if err != nil {
 		return err
 	}
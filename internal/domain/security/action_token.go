@@ -0,0 +1,11 @@
+package security
+
+// TokenPurpose what a single-use action token may be used for
+type TokenPurpose string
+
+const (
+	PurposeVerifyEmail    TokenPurpose = "verify_email"
+	PurposeResetPassword  TokenPurpose = "reset_password"
+	PurposeLoginChallenge TokenPurpose = "login_challenge"
+	PurposeOIDCState      TokenPurpose = "oidc_state"
+)
@@ -1,7 +1,27 @@
 package security
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
-	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+	ErrRateLimitExceeded  = errors.New("rate limit exceeded")
+	ErrAccountLocked      = errors.New("account temporarily locked")
+	ErrInvalidActionToken = errors.New("invalid or expired token")
 )
+
+// AccountLockedError is returned while an email or IP is locked out after too
+// many failed logins. It matches ErrAccountLocked with errors.Is.
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrAccountLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *AccountLockedError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
@@ -2,7 +2,70 @@ package security
 
 import "time"
 
+// RateLimitAlgorithm how a policy counts requests
+type RateLimitAlgorithm string
+
+const (
+	// FixedWindow counts requests in windows starting at the first request
+	FixedWindow RateLimitAlgorithm = "fixed_window"
+
+	// SlidingWindow counts the requests of the last window, exact but keeps every request
+	SlidingWindow RateLimitAlgorithm = "sliding_window"
+
+	// GCRA spaces requests Window/Limit apart with bursts up to Limit, keeping a
+	// single timestamp per key
+	GCRA RateLimitAlgorithm = "gcra"
+)
+
+// Valid reports whether a is a known algorithm
+func (a RateLimitAlgorithm) Valid() bool {
+	switch a {
+	case FixedWindow, SlidingWindow, GCRA:
+		return true
+	}
+	return false
+}
+
+// RateLimitPolicy allows Limit requests per Window, counted by Algorithm
 type RateLimitPolicy struct {
-	Limit  int64
-	Window time.Duration
+	Limit     int64
+	Window    time.Duration
+	Algorithm RateLimitAlgorithm
+}
+
+// RateLimitResult is the quota of a key after counting a request. Reset is how long
+// until the quota is whole again, RetryAfter how long until the next request is
+// allowed once the quota is used up.
+type RateLimitResult struct {
+	Limit      int64
+	Remaining  int64
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Exceeded reports whether the request was over the limit
+func (r RateLimitResult) Exceeded() bool {
+	return r.RetryAfter > 0
+}
+
+// LoginLockPolicy locks a login key (email or IP) once it collects MaxFailures
+// failed attempts within Window. Each further lock doubles the lockout,
+// starting at BaseLockout and capped at MaxLockout.
+type LoginLockPolicy struct {
+	MaxFailures int64
+	Window      time.Duration
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+// LockoutFor returns the lockout of the level-th lock (1-based).
+func (p LoginLockPolicy) LockoutFor(level int64) time.Duration {
+	lockout := p.BaseLockout
+	for i := int64(1); i < level && lockout < p.MaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > p.MaxLockout {
+		return p.MaxLockout
+	}
+	return lockout
 }
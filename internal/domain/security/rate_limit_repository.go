@@ -18,4 +18,8 @@ type RateLimitRepository interface {
 
 	// IncrementSliding increments the rate limit counter for a key with the sliding window
 	IncrementSliding(ctx context.Context, key string, window time.Duration, now time.Time) (count int64, err error)
+
+	// Take counts a request for a key with the algorithm of the policy, and returns the
+	// quota left in the same atomic step. Requests over the limit are not counted.
+	Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error)
 }
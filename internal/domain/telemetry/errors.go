@@ -0,0 +1,16 @@
+package telemetry
+
+import "errors"
+
+var (
+	ErrNoSchema        = errors.New("device type takes no telemetry")
+	ErrInvalidMetric   = errors.New("invalid telemetry metric")
+	ErrUnknownMetric   = errors.New("metric not in the telemetry schema")
+	ErrValueOutOfRange = errors.New("telemetry value out of range")
+	ErrInvalidTime     = errors.New("telemetry sample time out of range")
+	ErrEmptyBatch      = errors.New("telemetry batch is empty")
+	ErrBatchTooLarge   = errors.New("telemetry batch too large")
+	ErrInvalidRange    = errors.New("invalid telemetry time range")
+	ErrInvalidStep     = errors.New("invalid telemetry step")
+	ErrTooManyPoints   = errors.New("telemetry query returns too many points")
+)
@@ -0,0 +1,23 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+
+	// Insert stores the samples
+	Insert(ctx context.Context, samples []*Sample) error
+
+	// Aggregate returns the points of the query, ordered by metric and bucket.
+	// Downsampled data answers with the resolution it was rolled up to.
+	Aggregate(ctx context.Context, q Query) ([]Point, error)
+
+	// Rollup moves the samples recorded before cutoff into buckets of step, and
+	// returns how many buckets were written
+	Rollup(ctx context.Context, cutoff time.Time, step time.Duration) (int64, error)
+
+	// DeleteRollupsBefore removes the buckets starting before cutoff
+	DeleteRollupsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
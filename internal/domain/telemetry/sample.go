@@ -0,0 +1,35 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+)
+
+// Sample is one reading a device reported
+type Sample struct {
+	DeviceID device.ID
+	Metric   string
+	Value    float64
+	At       time.Time
+}
+
+// Point aggregates the samples of a metric in the bucket starting at Bucket
+type Point struct {
+	Metric string
+	Bucket time.Time
+	Count  int64
+	Avg    float64
+	Min    float64
+	Max    float64
+}
+
+// Query selects the series of a device, bucketed by Step within [From, To).
+// An empty Metrics selects every metric.
+type Query struct {
+	DeviceID device.ID
+	Metrics  []string
+	From     time.Time
+	To       time.Time
+	Step     time.Duration
+}
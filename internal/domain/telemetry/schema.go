@@ -0,0 +1,65 @@
+package telemetry
+
+import (
+	"math"
+	"strings"
+)
+
+// CustomPrefix starts the metrics a device defines itself, such as "custom.fan_rpm"
+const CustomPrefix = "custom."
+
+const maxMetricLength = 64
+
+// Range bounds the values of a metric, a nil bound is open
+type Range struct {
+	Min *float64
+	Max *float64
+}
+
+// Schema is what a type of device may report. Metrics maps the known metrics to
+// their range; Custom lets devices report any metric under CustomPrefix.
+type Schema struct {
+	Metrics map[string]Range
+	Custom  bool
+}
+
+// Validate checks the metric and value of a sample against the schema
+func (s Schema) Validate(metric string, value float64) error {
+	if !ValidMetric(metric) {
+		return ErrInvalidMetric
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return ErrValueOutOfRange
+	}
+
+	r, ok := s.Metrics[metric]
+	if !ok {
+		if s.Custom && strings.HasPrefix(metric, CustomPrefix) {
+			return nil
+		}
+		return ErrUnknownMetric
+	}
+
+	if (r.Min != nil && value < *r.Min) || (r.Max != nil && value > *r.Max) {
+		return ErrValueOutOfRange
+	}
+	return nil
+}
+
+// ValidMetric accepts dotted lowercase names, such as "cpu" or "custom.fan_rpm"
+func ValidMetric(metric string) bool {
+	if metric == "" || len(metric) > maxMetricLength {
+		return false
+	}
+	for _, part := range strings.Split(metric, ".") {
+		if part == "" || part[0] < 'a' || part[0] > 'z' {
+			return false
+		}
+		for _, r := range part {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+				return false
+			}
+		}
+	}
+	return true
+}
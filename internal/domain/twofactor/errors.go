@@ -0,0 +1,11 @@
+package twofactor
+
+import "errors"
+
+var (
+	ErrNotEnrolled    = errors.New("two-factor authentication not enrolled")
+	ErrAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	ErrNotEnabled     = errors.New("two-factor authentication not enabled")
+	ErrInvalidCode    = errors.New("invalid two-factor code")
+	ErrNotAllowed     = errors.New("two-factor authentication not available for this account")
+)
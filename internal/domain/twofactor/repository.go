@@ -0,0 +1,28 @@
+package twofactor
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type Repository interface {
+
+	// Find returns the enrollment of the user, or ErrNotEnrolled
+	Find(ctx context.Context, userID user.ID) (*TwoFactor, error)
+
+	// Save creates or replaces the enrollment of the user
+	Save(ctx context.Context, t *TwoFactor) error
+
+	// Delete removes the enrollment and the recovery codes of the user
+	Delete(ctx context.Context, userID user.ID) error
+
+	// ReplaceRecoveryCodes drops every recovery code of the user and stores the new hashes
+	ReplaceRecoveryCodes(ctx context.Context, userID user.ID, hashes []string) error
+
+	// FindUnusedRecoveryCodes returns the recovery codes of the user that were not used yet
+	FindUnusedRecoveryCodes(ctx context.Context, userID user.ID) ([]*RecoveryCode, error)
+
+	// UseRecoveryCode marks the code used, or returns ErrInvalidCode when it already was
+	UseRecoveryCode(ctx context.Context, id int64) error
+}
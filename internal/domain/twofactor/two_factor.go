@@ -0,0 +1,56 @@
+package twofactor
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// TwoFactor is the TOTP enrollment of a user. It stays pending until the user
+// confirms a first code, so a lost QR code never locks anyone out.
+type TwoFactor struct {
+	UserID       user.ID
+	Secret       string
+	EnabledAt    *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func New(userID user.ID, secret string) *TwoFactor {
+	return &TwoFactor{
+		UserID: userID,
+		Secret: secret,
+	}
+}
+
+func (t *TwoFactor) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// Enable confirms a pending enrollment
+func (t *TwoFactor) Enable(at time.Time) error {
+	if t.IsEnabled() {
+		return ErrAlreadyEnabled
+	}
+	t.EnabledAt = &at
+	return nil
+}
+
+// UseStep accepts a code of the time step once. A step at or before the last
+// accepted one is a replayed code.
+func (t *TwoFactor) UseStep(step int64) error {
+	if step <= t.LastUsedStep {
+		return ErrInvalidCode
+	}
+	t.LastUsedStep = step
+	return nil
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code, stored hashed
+type RecoveryCode struct {
+	ID     int64
+	UserID user.ID
+	Hash   string
+	UsedAt *time.Time
+}
@@ -3,13 +3,18 @@ package user
 import "errors"
 
 var (
-	ErrInvalidID         = errors.New("invalid id format")
-	ErrUserNotFound      = errors.New("user not found")
-	ErrUserAlreadyExists = errors.New("user already exists")
-	ErrInvalidUser       = errors.New("invalid user")
-	ErrUserApplying      = errors.New("user registration is pending approval")
-	ErrUserBanned        = errors.New("user is banned")
-	ErrInvalidPassword   = errors.New("invalid password")
-	ErrInvalidEmail      = errors.New("invalid email format")
-	ErrGenerateToken     = errors.New("generate token error")
+	ErrInvalidID            = errors.New("invalid id format")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrUserAlreadyExists    = errors.New("user already exists")
+	ErrInvalidUser          = errors.New("invalid user")
+	ErrUserApplying         = errors.New("user registration is pending approval")
+	ErrUserBanned           = errors.New("user is banned")
+	ErrUserRejected         = errors.New("user registration was rejected")
+	ErrInvalidTransition    = errors.New("invalid user status transition")
+	ErrReasonRequired       = errors.New("reason is required")
+	ErrStatusChangeNotFound = errors.New("status change not found")
+	ErrInvalidPassword      = errors.New("invalid password")
+	ErrInvalidEmail         = errors.New("invalid email format")
+	ErrGenerateToken        = errors.New("generate token error")
+	ErrForbidden            = errors.New("forbidden")
 )
@@ -5,6 +5,20 @@ import "context"
 type Repository interface {
 	FindByID(ctx context.Context, id ID) (*User, error)
 	FindByEmail(ctx context.Context, email Email) (*User, error)
+
+	// FindByStatus returns the users in the status, oldest first
+	FindByStatus(ctx context.Context, status Status) ([]*User, error)
+
 	Create(ctx context.Context, u *User) error
 	Update(ctx context.Context, u *User) error
 }
+
+type StatusHistoryRepository interface {
+	Create(ctx context.Context, change *StatusChange) error
+
+	// FindByUser returns the status changes of the user, newest first
+	FindByUser(ctx context.Context, userID ID) ([]*StatusChange, error)
+
+	// FindLatest returns the latest status change of the user, or ErrStatusChangeNotFound
+	FindLatest(ctx context.Context, userID ID) (*StatusChange, error)
+}
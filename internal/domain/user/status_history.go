@@ -0,0 +1,27 @@
+package user
+
+import "time"
+
+type StatusChangeID int64
+
+// StatusChange records one status transition of a user and who made it.
+// ActorID is 0 for transitions made by the system.
+type StatusChange struct {
+	ID        StatusChangeID
+	UserID    ID
+	From      Status
+	To        Status
+	Reason    string
+	ActorID   ID
+	CreatedAt time.Time
+}
+
+func NewStatusChange(userID ID, from, to Status, reason string, actorID ID) *StatusChange {
+	return &StatusChange{
+		UserID:  userID,
+		From:    from,
+		To:      to,
+		Reason:  reason,
+		ActorID: actorID,
+	}
+}
@@ -3,14 +3,15 @@ package user
 import "time"
 
 type User struct {
-	ID        ID
-	Name      string
-	Email     Email
-	Password  string
-	Status    Status
-	LastIP    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID              ID
+	Name            string
+	Email           Email
+	Password        string
+	Status          Status
+	LastIP          string
+	EmailVerifiedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
 
 func NewUser(name string, email Email, hash string, ip string) *User {
@@ -30,3 +31,50 @@ func (u *User) IsValid() bool {
 func (u *User) IsActive() bool {
 	return u.Status == Active
 }
+
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// VerifyEmail marks the email as verified, keeping the first verification time.
+func (u *User) VerifyEmail(at time.Time) {
+	if u.EmailVerifiedAt == nil {
+		u.EmailVerifiedAt = &at
+	}
+}
+
+// Approve activates an applicant. Rejected applicants may be approved later.
+func (u *User) Approve() error {
+	return u.transit(Active, Applying, Rejected)
+}
+
+// Reject declines a pending applicant.
+func (u *User) Reject() error {
+	return u.transit(Rejected, Applying)
+}
+
+// Ban blocks a user from logging in.
+func (u *User) Ban() error {
+	return u.transit(Banned, Active, Inactive, Applying)
+}
+
+// Unban lifts a ban, returning the user to previous, the status they had when banned.
+// Users whose earlier status is unknown become active.
+func (u *User) Unban(previous Status) error {
+	switch previous {
+	case Active, Inactive, Applying:
+	default:
+		previous = Active
+	}
+	return u.transit(previous, Banned)
+}
+
+func (u *User) transit(to Status, from ...Status) error {
+	for _, s := range from {
+		if u.Status == s {
+			u.Status = to
+			return nil
+		}
+	}
+	return ErrInvalidTransition
+}
@@ -39,5 +39,6 @@ const (
 	Inactive Status = "inactive"
 	Banned   Status = "banned"
 	Applying Status = "applying"
+	Rejected Status = "rejected"
 	Deleted  Status = "deleted"
 )
@@ -1,7 +1,8 @@
 package mock
 
-import "errors"
+import "github.com/HiroLiang/goat-server/internal/domain/auth"
 
 var (
-	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionNotFound same error as the real session stores, so callers can match it
+	ErrSessionNotFound = auth.ErrSessionNotFound
 )
@@ -0,0 +1,21 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+)
+
+// PermissionRepo grants the default permissions of each role
+type PermissionRepo struct{}
+
+func MockPermissionRepo() *PermissionRepo {
+	return &PermissionRepo{}
+}
+
+var _ permission.Repository = (*PermissionRepo)(nil)
+
+func (p PermissionRepo) FindByRole(_ context.Context, roleType role.Type) ([]permission.Permission, error) {
+	return permission.DefaultGrants[roleType], nil
+}
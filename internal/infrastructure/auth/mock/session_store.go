@@ -18,12 +18,14 @@ type SessionStore struct {
 	mu           sync.RWMutex
 	sessions     map[string]*sessionEntry       // token -> session
 	userSessions map[string]map[string]struct{} // userID -> set of tokens
+	rotated      map[string]*sessionEntry       // rotated refresh token -> session
 }
 
 func MockSessionStore() *SessionStore {
 	return &SessionStore{
 		sessions:     make(map[string]*sessionEntry),
 		userSessions: make(map[string]map[string]struct{}),
+		rotated:      make(map[string]*sessionEntry),
 	}
 }
 
@@ -51,6 +53,12 @@ func (m *SessionStore) Set(_ context.Context, token string, sess *auth.Session,
 		session:   sess,
 		expiresAt: time.Now().Add(ttl),
 	}
+
+	// like the redis store, index the token under its user
+	if m.userSessions[sess.UserID] == nil {
+		m.userSessions[sess.UserID] = make(map[string]struct{})
+	}
+	m.userSessions[sess.UserID][token] = struct{}{}
 	return nil
 }
 
@@ -58,6 +66,9 @@ func (m *SessionStore) Delete(_ context.Context, token string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if entry, exists := m.sessions[token]; exists {
+		delete(m.userSessions[entry.session.UserID], token)
+	}
 	delete(m.sessions, token)
 	return nil
 }
@@ -75,6 +86,39 @@ func (m *SessionStore) Refresh(_ context.Context, token string, ttl time.Duratio
 	return nil
 }
 
+func (m *SessionStore) Rotate(_ context.Context, token, newToken string, sess *auth.Session, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.sessions[token]
+	if !exists || time.Now().After(entry.expiresAt) {
+		return ErrSessionNotFound
+	}
+	delete(m.sessions, token)
+	delete(m.userSessions[entry.session.UserID], token)
+
+	expiresAt := time.Now().Add(ttl)
+	m.rotated[token] = &sessionEntry{session: sess, expiresAt: expiresAt}
+	m.sessions[newToken] = &sessionEntry{session: sess, expiresAt: expiresAt}
+	if m.userSessions[sess.UserID] == nil {
+		m.userSessions[sess.UserID] = make(map[string]struct{})
+	}
+	m.userSessions[sess.UserID][newToken] = struct{}{}
+	return nil
+}
+
+func (m *SessionStore) FindRotated(_ context.Context, token string) (*auth.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, exists := m.rotated[token]
+	if !exists || time.Now().After(entry.expiresAt) {
+		return nil, ErrSessionNotFound
+	}
+
+	return entry.session, nil
+}
+
 func (m *SessionStore) AddUserSession(_ context.Context, userID, token string, _ time.Duration) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
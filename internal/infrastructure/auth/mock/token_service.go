@@ -15,12 +15,12 @@ func MockTokenServiceFactory() auth.TokenService {
 
 var _ auth.TokenService = (*MockTokenService)(nil)
 
-func (m MockTokenService) Generate(ctx context.Context, params session.CreateSessionParams) (string, error) {
+func (m MockTokenService) Generate(ctx context.Context, params session.CreateSessionParams) (session.TokenPair, error) {
 	//TODO implement me
 	panic("implement me")
 }
 
-func (m MockTokenService) Refresh(ctx context.Context, token string) (string, error) {
+func (m MockTokenService) Refresh(ctx context.Context, token string) (session.TokenPair, error) {
 	//TODO implement me
 	panic("implement me")
 }
@@ -44,3 +44,13 @@ func (m MockTokenService) ListUserSessions(ctx context.Context, userID string) (
 	//TODO implement me
 	panic("implement me")
 }
+
+func (m MockTokenService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (m MockTokenService) RevokeOtherSessions(ctx context.Context, userID, keepSessionID string) error {
+	//TODO implement me
+	panic("implement me")
+}
@@ -2,36 +2,67 @@ package mock
 
 import (
 	"context"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/HiroLiang/goat-server/internal/domain/role"
 	"github.com/HiroLiang/goat-server/internal/domain/user"
 	"github.com/HiroLiang/goat-server/internal/domain/userrole"
 )
 
-type UserRoleRepo struct{}
+// roleIDs are the fixed IDs of the in-memory roles
+var roleIDs = map[role.Type]role.ID{
+	role.Admin:  1,
+	role.Vendor: 2,
+	role.User:   3,
+	role.Guest:  4,
+}
+
+type UserRoleRepo struct {
+	mu    sync.RWMutex
+	roles map[user.ID][]role.Type
+}
 
 func MockUserRoleRepo() *UserRoleRepo {
-	return &UserRoleRepo{}
+	return &UserRoleRepo{roles: make(map[user.ID][]role.Type)}
 }
 
 var _ userrole.Repository = (*UserRoleRepo)(nil)
 
-func (u UserRoleRepo) FindRolesByUser(ctx context.Context, userID user.ID) ([]*role.Role, error) {
-	//TODO implement me
-	panic("implement me")
+func (u *UserRoleRepo) FindRolesByUser(_ context.Context, userID user.ID) ([]*role.Role, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	roles := make([]*role.Role, 0, len(u.roles[userID]))
+	for _, t := range u.roles[userID] {
+		roles = append(roles, &role.Role{ID: roleIDs[t], Type: t, CreateAt: time.Now()})
+	}
+	return roles, nil
 }
 
-func (u UserRoleRepo) Exists(ctx context.Context, userID user.ID, role role.Type) bool {
-	//TODO implement me
-	panic("implement me")
+func (u *UserRoleRepo) Exists(_ context.Context, userID user.ID, roleType role.Type) bool {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	return slices.Contains(u.roles[userID], roleType)
 }
 
-func (u UserRoleRepo) Assign(ctx context.Context, userID user.ID, role role.Type) error {
-	//TODO implement me
-	panic("implement me")
+func (u *UserRoleRepo) Assign(_ context.Context, userID user.ID, roleType role.Type) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if slices.Contains(u.roles[userID], roleType) {
+		return userrole.ErrUserRoleAlreadyAssigned
+	}
+	u.roles[userID] = append(u.roles[userID], roleType)
+	return nil
 }
 
-func (u UserRoleRepo) Revoke(ctx context.Context, userID user.ID, role role.Type) error {
-	//TODO implement me
-	panic("implement me")
+func (u *UserRoleRepo) Revoke(_ context.Context, userID user.ID, roleType role.Type) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.roles[userID] = slices.DeleteFunc(u.roles[userID], func(t role.Type) bool { return t == roleType })
+	return nil
 }
@@ -0,0 +1,263 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/oidc"
+	"github.com/HiroLiang/goat-server/internal/domain/identity"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Config the client registration at a provider
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Provider is a generic OpenID Connect client. The endpoints come from the
+// discovery document of the issuer, which is fetched on first use together
+// with the signing keys. Unknown key IDs refetch the keys once, so key
+// rotation at the provider needs no restart.
+type Provider struct {
+	name string
+	conf Config
+	http *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      map[string]any
+}
+
+var _ oidc.Provider = (*Provider)(nil)
+
+// NewProvider creates an OIDC client. A nil httpClient uses a client with a 10s timeout.
+func NewProvider(name string, conf Config, httpClient *http.Client) *Provider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	if len(conf.Scopes) == 0 {
+		conf.Scopes = []string{"openid", "email", "profile"}
+	}
+	conf.Issuer = strings.TrimRight(conf.Issuer, "/")
+	return &Provider{
+		name: name,
+		conf: conf,
+		http: httpClient,
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.name
+}
+
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.conf.ClientID},
+		"redirect_uri":          {p.conf.RedirectURL},
+		"scope":                 {strings.Join(p.conf.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (oidc.Claims, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return oidc.Claims{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.conf.RedirectURL},
+		"client_id":     {p.conf.ClientID},
+		"code_verifier": {verifier},
+	}
+	if p.conf.ClientSecret != "" {
+		form.Set("client_secret", p.conf.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return oidc.Claims{}, fmt.Errorf("build oidc token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return oidc.Claims{}, fmt.Errorf("request oidc token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The provider rejects unknown, used or expired codes with 400
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return oidc.Claims{}, identity.ErrInvalidIDToken
+	}
+	if resp.StatusCode != http.StatusOK {
+		return oidc.Claims{}, fmt.Errorf("oidc token: unexpected status %d", resp.StatusCode)
+	}
+
+	var body tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return oidc.Claims{}, fmt.Errorf("decode oidc token: %w", err)
+	}
+	if body.IDToken == "" {
+		return oidc.Claims{}, identity.ErrInvalidIDToken
+	}
+
+	return p.validate(ctx, body.IDToken)
+}
+
+// validate checks the signature, issuer, audience and expiry of the ID token
+func (p *Provider) validate(ctx context.Context, idToken string) (oidc.Claims, error) {
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(idToken, &claims,
+		func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			return p.key(ctx, kid)
+		},
+		jwt.WithIssuer(p.conf.Issuer),
+		jwt.WithAudience(p.conf.ClientID),
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return oidc.Claims{}, fmt.Errorf("%w: %v", identity.ErrInvalidIDToken, err)
+	}
+
+	return oidc.Claims{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Nonce:         claims.Nonce,
+	}, nil
+}
+
+// key returns the signing key of the ID, refetching the key set once when it is unknown
+func (p *Provider) key(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var doc discoveryDocument
+	if err := p.getJSON(ctx, p.conf.Issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if strings.TrimRight(doc.Issuer, "/") != p.conf.Issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match %q", doc.Issuer, p.conf.Issuer)
+	}
+
+	p.discovery = &doc
+	return p.discovery, nil
+}
+
+// fetchKeys loads the key set, the caller holds the lock
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]any, error) {
+	if p.discovery == nil {
+		return nil, fmt.Errorf("oidc keys: discovery not loaded")
+	}
+
+	var set jsonWebKeySet
+	if err := p.getJSON(ctx, p.discovery.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("oidc keys: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			// Skip keys of unsupported types instead of failing the whole set
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, target string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %d", target, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode %s: %w", target, err)
+	}
+	return nil
+}
+
+// codeChallenge is the S256 PKCE challenge of the verifier
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,197 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/identity"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider is a tiny OIDC provider: discovery, keys and a token endpoint
+// that checks the PKCE verifier of the code it handed out
+type fakeProvider struct {
+	srv      *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+
+	mu    sync.Mutex
+	codes map[string]fakeGrant
+}
+
+type fakeGrant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	f := &fakeProvider{key: key, clientID: "goat", codes: map[string]fakeGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 f.srv.URL,
+			"authorization_endpoint": f.srv.URL + "/authorize",
+			"token_endpoint":         f.srv.URL + "/token",
+			"jwks_uri":               f.srv.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "k1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+
+		f.mu.Lock()
+		grant, ok := f.codes[r.PostForm.Get("code")]
+		delete(f.codes, r.PostForm.Get("code"))
+		f.mu.Unlock()
+
+		if !ok || codeChallenge(r.PostForm.Get("code_verifier")) != grant.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "at",
+			"token_type":   "Bearer",
+			"id_token":     f.sign(t, grant.claims),
+		})
+	})
+
+	f.srv = httptest.NewServer(mux)
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+// authorize plays the login at the provider and returns the code for the redirect
+func (f *fakeProvider) authorize(t *testing.T, authURL string, claims jwt.MapClaims) string {
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	q := u.Query()
+
+	claims["iss"] = f.srv.URL
+	claims["aud"] = f.clientID
+	claims["nonce"] = q.Get("nonce")
+	claims["iat"] = time.Now().Unix()
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+	}
+
+	code := "code-" + q.Get("state")
+	f.mu.Lock()
+	f.codes[code] = fakeGrant{challenge: q.Get("code_challenge"), claims: claims}
+	f.mu.Unlock()
+	return code
+}
+
+func (f *fakeProvider) sign(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(f.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func (f *fakeProvider) client() *Provider {
+	return NewProvider("fake", Config{
+		Issuer:      f.srv.URL,
+		ClientID:    f.clientID,
+		RedirectURL: "http://localhost/callback",
+	}, f.srv.Client())
+}
+
+func TestProvider_CodeFlowWithPKCE(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeProvider(t)
+	p := fake.client()
+
+	authURL, err := p.AuthCodeURL(ctx, "st", "n1", "verifier-of-at-least-43-characters-long-abcdef")
+	require.NoError(t, err)
+	assert.Contains(t, authURL, fake.srv.URL+"/authorize?")
+	assert.Contains(t, authURL, "code_challenge_method=S256")
+
+	code := fake.authorize(t, authURL, jwt.MapClaims{
+		"sub":            "ext-1",
+		"email":          "a@b.com",
+		"email_verified": "true",
+	})
+
+	claims, err := p.Exchange(ctx, code, "verifier-of-at-least-43-characters-long-abcdef")
+	require.NoError(t, err)
+	assert.Equal(t, "ext-1", claims.Subject)
+	assert.Equal(t, "a@b.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+	assert.Equal(t, "n1", claims.Nonce)
+}
+
+func TestProvider_RejectsWrongVerifier(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeProvider(t)
+	p := fake.client()
+
+	authURL, err := p.AuthCodeURL(ctx, "st", "n1", "the-right-verifier")
+	require.NoError(t, err)
+	code := fake.authorize(t, authURL, jwt.MapClaims{"sub": "ext-1"})
+
+	_, err = p.Exchange(ctx, code, "a-stolen-code-without-verifier")
+	assert.ErrorIs(t, err, identity.ErrInvalidIDToken)
+}
+
+func TestProvider_RejectsExpiredToken(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeProvider(t)
+	p := fake.client()
+
+	authURL, err := p.AuthCodeURL(ctx, "st", "n1", "verifier")
+	require.NoError(t, err)
+	code := fake.authorize(t, authURL, jwt.MapClaims{
+		"sub": "ext-1",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+
+	_, err = p.Exchange(ctx, code, "verifier")
+	assert.ErrorIs(t, err, identity.ErrInvalidIDToken)
+}
+
+func TestProvider_RejectsOtherAudience(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeProvider(t)
+	p := fake.client()
+
+	authURL, err := p.AuthCodeURL(ctx, "st", "n1", "verifier")
+	require.NoError(t, err)
+	code := fake.authorize(t, authURL, jwt.MapClaims{"sub": "ext-1"})
+
+	// A token for another client of the same provider must not log in here
+	fake.mu.Lock()
+	fake.codes[code].claims["aud"] = "someone-else"
+	fake.mu.Unlock()
+
+	_, err = p.Exchange(ctx, code, "verifier")
+	assert.ErrorIs(t, err, identity.ErrInvalidIDToken)
+}
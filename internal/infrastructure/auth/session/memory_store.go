@@ -0,0 +1,257 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/auth"
+)
+
+type memorySession struct {
+	session auth.Session
+	expires time.Time
+}
+
+type memoryUserSessions struct {
+	tokens  map[string]struct{}
+	expires time.Time
+}
+
+// MemorySessionStore keeps sessions in process for running without Redis, with the
+// same expiry rules as RedisSessionStore
+type MemorySessionStore struct {
+	mu           sync.Mutex
+	sessions     map[string]memorySession
+	rotated      map[string]memorySession
+	userSessions map[string]*memoryUserSessions
+	lastSweep    time.Time
+	now          func() time.Time
+}
+
+var _ Store = (*MemorySessionStore)(nil)
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		sessions:     make(map[string]memorySession),
+		rotated:      make(map[string]memorySession),
+		userSessions: make(map[string]*memoryUserSessions),
+		now:          time.Now,
+	}
+}
+
+func (s *MemorySessionStore) Get(_ context.Context, token string) (*auth.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.find(s.sessions, token)
+}
+
+func (s *MemorySessionStore) Set(_ context.Context, token string, session *auth.Session, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	s.sessions[token] = memorySession{session: *session, expires: now.Add(ttl)}
+	s.addUserSession(session.UserID, token, now.Add(ttl))
+
+	return nil
+}
+
+func (s *MemorySessionStore) Delete(_ context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.sessions[token]
+	delete(s.sessions, token)
+	if ok {
+		s.removeUserSession(stored.session.UserID, token)
+	}
+
+	return nil
+}
+
+func (s *MemorySessionStore) Refresh(_ context.Context, token string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, err := s.find(s.sessions, token)
+	if err != nil {
+		return err
+	}
+
+	expires := s.now().Add(ttl)
+	s.sessions[token] = memorySession{session: *session, expires: expires}
+	if set, ok := s.userSessions[session.UserID]; ok {
+		set.expires = expires
+	}
+
+	return nil
+}
+
+func (s *MemorySessionStore) Rotate(_ context.Context, token, newToken string, session *auth.Session, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.find(s.sessions, token)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	delete(s.sessions, token)
+	s.removeUserSession(current.UserID, token)
+
+	s.rotated[token] = memorySession{session: *session, expires: now.Add(ttl)}
+	s.sessions[newToken] = memorySession{session: *session, expires: now.Add(ttl)}
+	s.addUserSession(session.UserID, newToken, now.Add(ttl))
+
+	return nil
+}
+
+func (s *MemorySessionStore) FindRotated(_ context.Context, token string) (*auth.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.find(s.rotated, token)
+}
+
+func (s *MemorySessionStore) AddUserSession(_ context.Context, userID, token string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addUserSession(userID, token, s.now().Add(ttl))
+	return nil
+}
+
+func (s *MemorySessionStore) RemoveUserSession(_ context.Context, userID, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeUserSession(userID, token)
+	return nil
+}
+
+func (s *MemorySessionStore) ListUserSessions(_ context.Context, userID string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set := s.findUserSessions(userID)
+	if set == nil {
+		return []string{}, nil
+	}
+
+	tokens := make([]string, 0, len(set.tokens))
+	for token := range set.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func (s *MemorySessionStore) DeleteAllUserSessions(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if set := s.findUserSessions(userID); set != nil {
+		for token := range set.tokens {
+			delete(s.sessions, token)
+		}
+	}
+	delete(s.userSessions, userID)
+
+	return nil
+}
+
+func (s *MemorySessionStore) CleanExpiredUserSessions(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set := s.findUserSessions(userID)
+	if set == nil {
+		return nil
+	}
+
+	for token := range set.tokens {
+		if _, err := s.find(s.sessions, token); err != nil {
+			delete(set.tokens, token)
+		}
+	}
+	if len(set.tokens) == 0 {
+		delete(s.userSessions, userID)
+	}
+
+	return nil
+}
+
+// sweep drops expired entries nobody read again, at most once a minute. The caller holds the lock.
+func (s *MemorySessionStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for _, sessions := range []map[string]memorySession{s.sessions, s.rotated} {
+		for token, stored := range sessions {
+			if !now.Before(stored.expires) {
+				delete(sessions, token)
+			}
+		}
+	}
+	for userID, set := range s.userSessions {
+		if !now.Before(set.expires) {
+			delete(s.userSessions, userID)
+		}
+	}
+}
+
+// find returns a copy of a live session, dropping it once expired. The caller holds the lock.
+func (s *MemorySessionStore) find(sessions map[string]memorySession, token string) (*auth.Session, error) {
+	stored, ok := sessions[token]
+	if !ok {
+		return nil, auth.ErrSessionNotFound
+	}
+	if !s.now().Before(stored.expires) {
+		delete(sessions, token)
+		return nil, auth.ErrSessionNotFound
+	}
+
+	session := stored.session
+	return &session, nil
+}
+
+// findUserSessions returns the live token set of a user. The caller holds the lock.
+func (s *MemorySessionStore) findUserSessions(userID string) *memoryUserSessions {
+	set, ok := s.userSessions[userID]
+	if !ok {
+		return nil
+	}
+	if !s.now().Before(set.expires) {
+		delete(s.userSessions, userID)
+		return nil
+	}
+	return set
+}
+
+// addUserSession adds a token to the user set and renews its expiry like Redis EXPIRE. The caller holds the lock.
+func (s *MemorySessionStore) addUserSession(userID, token string, expires time.Time) {
+	set := s.findUserSessions(userID)
+	if set == nil {
+		set = &memoryUserSessions{tokens: make(map[string]struct{})}
+		s.userSessions[userID] = set
+	}
+	set.tokens[token] = struct{}{}
+	set.expires = expires
+}
+
+// removeUserSession removes a token from the user set. The caller holds the lock.
+func (s *MemorySessionStore) removeUserSession(userID, token string) {
+	set := s.findUserSessions(userID)
+	if set == nil {
+		return
+	}
+	delete(set.tokens, token)
+	if len(set.tokens) == 0 {
+		delete(s.userSessions, userID)
+	}
+}
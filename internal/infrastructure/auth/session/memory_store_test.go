@@ -0,0 +1,88 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMemoryStore(now *time.Time) *MemorySessionStore {
+	store := NewMemorySessionStore()
+	store.now = func() time.Time { return *now }
+	return store
+}
+
+func TestMemorySessionStore_ExpiresAndRefreshes(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newTestMemoryStore(&now)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Set(ctx, "token", &auth.Session{ID: "s1", UserID: "1"}, time.Minute))
+
+	now = now.Add(50 * time.Second)
+	assert.NoError(t, store.Refresh(ctx, "token", time.Minute))
+
+	now = now.Add(50 * time.Second)
+	session, err := store.Get(ctx, "token")
+	assert.NoError(t, err)
+	assert.Equal(t, "s1", session.ID)
+
+	now = now.Add(time.Minute)
+	_, err = store.Get(ctx, "token")
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+	assert.ErrorIs(t, store.Refresh(ctx, "token", time.Minute), auth.ErrSessionNotFound)
+}
+
+func TestMemorySessionStore_UserSessions(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newTestMemoryStore(&now)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Set(ctx, "a", &auth.Session{UserID: "1"}, time.Minute))
+	assert.NoError(t, store.Set(ctx, "b", &auth.Session{UserID: "1"}, time.Hour))
+
+	tokens, err := store.ListUserSessions(ctx, "1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b"}, tokens)
+
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, store.CleanExpiredUserSessions(ctx, "1"))
+	tokens, _ = store.ListUserSessions(ctx, "1")
+	assert.Equal(t, []string{"b"}, tokens)
+
+	assert.NoError(t, store.Delete(ctx, "b"))
+	tokens, _ = store.ListUserSessions(ctx, "1")
+	assert.Empty(t, tokens)
+
+	assert.NoError(t, store.Set(ctx, "c", &auth.Session{UserID: "1"}, time.Hour))
+	assert.NoError(t, store.DeleteAllUserSessions(ctx, "1"))
+	_, err = store.Get(ctx, "c")
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+}
+
+func TestMemorySessionStore_RotatedTokens(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newTestMemoryStore(&now)
+	ctx := context.Background()
+
+	assert.NoError(t, store.Set(ctx, "old", &auth.Session{ID: "s1", UserID: "1"}, time.Minute))
+	assert.NoError(t, store.Rotate(ctx, "old", "new", &auth.Session{ID: "s1", UserID: "1"}, time.Minute))
+	assert.ErrorIs(t, store.Rotate(ctx, "old", "other", &auth.Session{ID: "s1", UserID: "1"}, time.Minute),
+		auth.ErrSessionNotFound, "a token rotates once")
+
+	session, err := store.FindRotated(ctx, "old")
+	assert.NoError(t, err)
+	assert.Equal(t, "s1", session.ID)
+
+	_, err = store.Get(ctx, "old")
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound, "rotated tokens are no sessions")
+	tokens, _ := store.ListUserSessions(ctx, "1")
+	assert.Equal(t, []string{"new"}, tokens)
+
+	now = now.Add(time.Minute)
+	_, err = store.FindRotated(ctx, "old")
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+}
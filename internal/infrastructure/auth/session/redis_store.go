@@ -90,6 +90,61 @@ func (s *RedisSessionStore) Refresh(ctx context.Context, token string, ttl time.
 	return err
 }
 
+// rotateScript KEYS: session, rotated marker and new session of the token, user sessions.
+// ARGV: session JSON, ttl (ms), token, new token. Deleting the session is the check, so
+// only one of concurrent rotations of a token wins.
+var rotateScript = redis.NewScript(`
+if redis.call('DEL', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('SET', KEYS[2], ARGV[1], 'PX', ARGV[2])
+redis.call('SET', KEYS[3], ARGV[1], 'PX', ARGV[2])
+redis.call('SREM', KEYS[4], ARGV[3])
+redis.call('SADD', KEYS[4], ARGV[4])
+redis.call('PEXPIRE', KEYS[4], ARGV[2])
+return 1
+`)
+
+func (s *RedisSessionStore) Rotate(ctx context.Context, token, newToken string, session *auth.Session, ttl time.Duration) error {
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{
+		"session:" + token,
+		"session_rotated:" + token,
+		"session:" + newToken,
+		"user_sessions:" + session.UserID,
+	}
+	rotated, err := rotateScript.Run(ctx, s.redis, keys, b, ttl.Milliseconds(), token, newToken).Int()
+	if err != nil {
+		return err
+	}
+	if rotated == 0 {
+		return auth.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (s *RedisSessionStore) FindRotated(ctx context.Context, token string) (*auth.Session, error) {
+	b, ok, err := s.cache.Get(ctx, "session_rotated:"+token)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, auth.ErrSessionNotFound
+	}
+
+	var session auth.Session
+	if err := json.Unmarshal(b, &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
 func (s *RedisSessionStore) AddUserSession(ctx context.Context, userID, token string, ttl time.Duration) error {
 	key := "user_sessions:" + userID
 
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/auth"
+	redisInfra "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/redis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisStore(t *testing.T) *RedisSessionStore {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewRedisSessionStore(redisInfra.NewRedisCache(client), client)
+}
+
+func TestRedisSessionStore_RotateOnce(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	store := newTestRedisStore(t)
+	sess := &auth.Session{ID: "s1", UserID: "1"}
+	require.NoError(t, store.Set(ctx, "old", sess, time.Minute))
+
+	require.NoError(t, store.Rotate(ctx, "old", "new", sess, time.Minute))
+	assert.ErrorIs(t, store.Rotate(ctx, "old", "other", sess, time.Minute), auth.ErrSessionNotFound)
+
+	_, err := store.Get(ctx, "old")
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+	got, err := store.Get(ctx, "new")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", got.ID)
+
+	rotated, err := store.FindRotated(ctx, "old")
+	require.NoError(t, err)
+	assert.Equal(t, "s1", rotated.ID)
+
+	tokens, err := store.ListUserSessions(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new"}, tokens)
+}
@@ -13,6 +13,14 @@ type Store interface {
 	Delete(ctx context.Context, token string) error
 	Refresh(ctx context.Context, token string, ttl time.Duration) error
 
+	// Rotate atomically exchanges token for newToken holding session, and remembers token as rotated so
+	// presenting it again is detected as reuse. Returns auth.ErrSessionNotFound, changing nothing, when
+	// token is no live session, such as when a concurrent Rotate has exchanged it already.
+	Rotate(ctx context.Context, token, newToken string, session *auth.Session, ttl time.Duration) error
+
+	// FindRotated returns the session a rotated token belonged to, or auth.ErrSessionNotFound
+	FindRotated(ctx context.Context, token string) (*auth.Session, error)
+
 	AddUserSession(ctx context.Context, userID, token string, ttl time.Duration) error
 	RemoveUserSession(ctx context.Context, userID, token string) error
 	ListUserSessions(ctx context.Context, userID string) ([]string, error)
@@ -0,0 +1,144 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	iAuth "github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/auth/session"
+	"github.com/HiroLiang/goat-server/internal/shared/jwt"
+)
+
+// JWTTokenService issues short-lived signed access tokens and long-lived
+// rotating refresh tokens. Refresh tokens are opaque session tokens, so session
+// listing and revocation are shared with AuthTokenService; the session ID is the
+// token family. Access tokens are checked by signature only and stay usable
+// until they expire, even after their session is revoked.
+type JWTTokenService struct {
+	*AuthTokenService
+	secret    string
+	accessTTL time.Duration
+}
+
+var _ iAuth.TokenService = (*JWTTokenService)(nil)
+
+func NewJWTTokenService(
+	store session.Store,
+	secret string,
+	accessTTL time.Duration,
+	refreshTTL time.Duration,
+) *JWTTokenService {
+	return &JWTTokenService{
+		AuthTokenService: NewAuthTokenService(store, refreshTTL),
+		secret:           secret,
+		accessTTL:        accessTTL,
+	}
+}
+
+// Generate creates a new session and returns its refresh token with a signed access token.
+func (s *JWTTokenService) Generate(ctx context.Context, params auth.CreateSessionParams) (auth.TokenPair, error) {
+	refreshToken, sess, err := s.createSession(ctx, params)
+	if err != nil {
+		return auth.TokenPair{}, err
+	}
+
+	return s.pair(refreshToken, sess)
+}
+
+// Validate verifies the access token signature without touching the session store.
+func (s *JWTTokenService) Validate(_ context.Context, token string) (*auth.Session, error) {
+	claims, err := jwt.ValidateToken(token, s.secret)
+	if err != nil {
+		return nil, auth.ErrInvalidToken
+	}
+
+	return &auth.Session{
+		ID:     claims.SessionID,
+		UserID: strconv.FormatInt(claims.UserID, 10),
+	}, nil
+}
+
+// Refresh rotates the refresh token. A token that was already rotated means a
+// copy leaked, so the whole family is revoked and auth.ErrTokenReused returned.
+// Of concurrent refreshes with one token, only the first rotates and the others
+// count as reuse.
+func (s *JWTTokenService) Refresh(ctx context.Context, token string) (auth.TokenPair, error) {
+	sess, err := s.store.Get(ctx, token)
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		return auth.TokenPair{}, s.detectReuse(ctx, token)
+	}
+	if err != nil {
+		return auth.TokenPair{}, err
+	}
+
+	newToken, err := s.generateSecureToken(32)
+	if err != nil {
+		return auth.TokenPair{}, auth.ErrGenerateToken
+	}
+
+	sess.LastSeenAt = time.Now()
+	err = s.store.Rotate(ctx, token, newToken, sess, s.tokenTTL)
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		return auth.TokenPair{}, s.detectReuse(ctx, token)
+	}
+	if err != nil {
+		return auth.TokenPair{}, err
+	}
+
+	return s.pair(newToken, sess)
+}
+
+// Revoke ends the session the access token belongs to.
+func (s *JWTTokenService) Revoke(ctx context.Context, token string) error {
+	sess, err := s.Validate(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	err = s.RevokeSession(ctx, sess.UserID, sess.ID)
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		return nil
+	}
+	return err
+}
+
+// --- Helpers ---
+
+// detectReuse revokes the family of a rotated refresh token. Unknown tokens are just invalid.
+func (s *JWTTokenService) detectReuse(ctx context.Context, token string) error {
+	rotated, err := s.store.FindRotated(ctx, token)
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		return auth.ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.RevokeSession(ctx, rotated.UserID, rotated.ID)
+	if err != nil && !errors.Is(err, auth.ErrSessionNotFound) {
+		return err
+	}
+
+	return auth.ErrTokenReused
+}
+
+func (s *JWTTokenService) pair(refreshToken string, sess *auth.Session) (auth.TokenPair, error) {
+	userID, err := strconv.ParseInt(sess.UserID, 10, 64)
+	if err != nil {
+		return auth.TokenPair{}, auth.ErrGenerateToken
+	}
+
+	accessToken, err := jwt.GenerateToken(userID, "", sess.ID, s.secret, s.accessTTL)
+	if err != nil {
+		return auth.TokenPair{}, auth.ErrGenerateToken
+	}
+
+	return auth.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    s.accessTTL,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/auth/mock"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/auth/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJWTService() *JWTTokenService {
+	return NewJWTTokenService(mock.MockSessionStore(), "secret", time.Minute, time.Hour)
+}
+
+func jwtLogin(t *testing.T, s *JWTTokenService) auth.TokenPair {
+	t.Helper()
+
+	pair, err := s.Generate(context.Background(), auth.CreateSessionParams{
+		UserID:    "1",
+		IP:        "127.0.0.1",
+		UserAgent: "agent",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, pair.RefreshToken)
+	require.NotEqual(t, pair.AccessToken, pair.RefreshToken)
+
+	return pair
+}
+
+func TestJWTValidate_CarriesSessionID(t *testing.T) {
+	s := newJWTService()
+	pair := jwtLogin(t, s)
+
+	sess, err := s.Validate(context.Background(), pair.AccessToken)
+	require.NoError(t, err)
+
+	sessions, err := s.ListUserSessions(context.Background(), "1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "1", sess.UserID)
+	assert.Equal(t, sessions[0].ID, sess.ID)
+
+	_, err = s.Validate(context.Background(), pair.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestJWTRefresh_RotatesAndKeepsSession(t *testing.T) {
+	s := newJWTService()
+	pair := jwtLogin(t, s)
+	before, err := s.Validate(context.Background(), pair.AccessToken)
+	require.NoError(t, err)
+
+	next, err := s.Refresh(context.Background(), pair.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, pair.RefreshToken, next.RefreshToken)
+
+	after, err := s.Validate(context.Background(), next.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, before.ID, after.ID)
+
+	_, err = s.Refresh(context.Background(), "unknown")
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestJWTRefresh_ReuseRevokesFamily(t *testing.T) {
+	s := newJWTService()
+	pair := jwtLogin(t, s)
+	other := jwtLogin(t, s)
+
+	next, err := s.Refresh(context.Background(), pair.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = s.Refresh(context.Background(), pair.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrTokenReused)
+
+	// the legitimate successor is gone too, other sessions are untouched
+	_, err = s.Refresh(context.Background(), next.RefreshToken)
+	assert.Error(t, err)
+	_, err = s.Refresh(context.Background(), other.RefreshToken)
+	assert.NoError(t, err)
+}
+
+// racingStore holds the first reads of a session until all of them are made, so
+// concurrent refreshes all see the token live before any of them rotates it.
+type racingStore struct {
+	*session.MemorySessionStore
+	reads   atomic.Int32
+	holding int32
+	waiting sync.WaitGroup
+}
+
+func newRacingStore(holding int) *racingStore {
+	s := &racingStore{MemorySessionStore: session.NewMemorySessionStore(), holding: int32(holding)}
+	s.waiting.Add(holding)
+	return s
+}
+
+func (s *racingStore) Get(ctx context.Context, token string) (*auth.Session, error) {
+	sess, err := s.MemorySessionStore.Get(ctx, token)
+	if s.reads.Add(1) <= s.holding {
+		s.waiting.Done()
+		s.waiting.Wait()
+	}
+	return sess, err
+}
+
+func TestJWTRefresh_ConcurrentReuseRotatesOnce(t *testing.T) {
+	const attempts = 8
+	store := newRacingStore(attempts)
+	s := NewJWTTokenService(store, "secret", time.Minute, time.Hour)
+	pair := jwtLogin(t, s)
+
+	var wg sync.WaitGroup
+	errs := make([]error, attempts)
+	for i := range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = s.Refresh(context.Background(), pair.RefreshToken)
+		}()
+	}
+	wg.Wait()
+
+	rotated := 0
+	for _, err := range errs {
+		if err == nil {
+			rotated++
+			continue
+		}
+		assert.ErrorIs(t, err, auth.ErrTokenReused)
+	}
+	assert.Equal(t, 1, rotated)
+
+	// the reuse revoked the family, so no session of it is left
+	sessions, err := s.ListUserSessions(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
@@ -12,6 +12,10 @@ import (
 	"github.com/HiroLiang/goat-server/internal/infrastructure/auth/session"
 )
 
+// lastSeenResolution how stale LastSeenAt may get before Validate writes it back,
+// so a burst of requests does not rewrite the session every time
+const lastSeenResolution = time.Minute
+
 type AuthTokenService struct {
 	store    session.Store
 	tokenTTL time.Duration
@@ -27,24 +31,13 @@ func NewAuthTokenService(store session.Store, ttl time.Duration) *AuthTokenServi
 }
 
 // Generate creates a new session token and stores it.
-func (s *AuthTokenService) Generate(ctx context.Context, params auth.CreateSessionParams) (string, error) {
-	token, err := s.generateSecureToken(32)
+func (s *AuthTokenService) Generate(ctx context.Context, params auth.CreateSessionParams) (auth.TokenPair, error) {
+	token, _, err := s.createSession(ctx, params)
 	if err != nil {
-		return "", auth.ErrGenerateToken
-	}
-
-	sess := &auth.Session{
-		UserID:    params.UserID,
-		IP:        params.IP,
-		UserAgent: params.UserAgent,
-		CreatedAt: time.Now(),
+		return auth.TokenPair{}, err
 	}
 
-	if err := s.store.Set(ctx, token, sess, s.tokenTTL); err != nil {
-		return "", err
-	}
-
-	return token, nil
+	return auth.TokenPair{AccessToken: token, ExpiresIn: s.tokenTTL}, nil
 }
 
 // Validate checks if the session exists and auto-refreshes TTL (sliding session).
@@ -54,7 +47,7 @@ func (s *AuthTokenService) Validate(ctx context.Context, token string) (*auth.Se
 		return nil, err
 	}
 
-	if err := s.store.Refresh(ctx, token, s.tokenTTL); err != nil {
+	if err := s.touch(ctx, token, sess); err != nil {
 		return nil, auth.ErrRefreshToken
 	}
 
@@ -67,20 +60,21 @@ func (s *AuthTokenService) Validate(ctx context.Context, token string) (*auth.Se
 }
 
 // Refresh creates a brand-new session token for the same user.
-func (s *AuthTokenService) Refresh(ctx context.Context, token string) (string, error) {
+func (s *AuthTokenService) Refresh(ctx context.Context, token string) (auth.TokenPair, error) {
 	sess, err := s.store.Get(ctx, token)
 	if err != nil {
-		return "", err
+		return auth.TokenPair{}, err
 	}
 
 	if err := s.store.Delete(ctx, token); err != nil {
-		return "", err
+		return auth.TokenPair{}, err
 	}
 
 	return s.Generate(ctx, auth.CreateSessionParams{
 		UserID:    sess.UserID,
 		IP:        sess.IP,
 		UserAgent: sess.UserAgent,
+		DeviceID:  sess.DeviceID,
 	})
 }
 
@@ -91,12 +85,103 @@ func (s *AuthTokenService) Revoke(ctx context.Context, token string) error {
 
 // ListUserSessions returns all active sessions of the user.
 func (s *AuthTokenService) ListUserSessions(ctx context.Context, userID string) ([]*auth.Session, error) {
-	tokens, err := s.store.ListUserSessions(ctx, userID)
+	tokens, err := s.userTokens(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
 	sessions := make([]*auth.Session, 0, len(tokens))
+	for _, sess := range tokens {
+		sessions = append(sessions, sess)
+	}
+
+	return sessions, nil
+}
+
+// RevokeAllForUser deletes all sessions for a user.
+func (s *AuthTokenService) RevokeAllForUser(ctx context.Context, userID string) error {
+	return s.store.DeleteAllUserSessions(ctx, userID)
+}
+
+// RevokeSession deletes the session of the user with the given session ID.
+func (s *AuthTokenService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	tokens, err := s.userTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for token, sess := range tokens {
+		if sess.ID == sessionID {
+			return s.store.Delete(ctx, token)
+		}
+	}
+
+	return auth.ErrSessionNotFound
+}
+
+// RevokeOtherSessions deletes every session of the user except keepSessionID.
+func (s *AuthTokenService) RevokeOtherSessions(ctx context.Context, userID, keepSessionID string) error {
+	tokens, err := s.userTokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for token, sess := range tokens {
+		if sess.ID == keepSessionID {
+			continue
+		}
+		if err := s.store.Delete(ctx, token); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// --- Helpers ---
+
+// createSession stores a new session of the user under a fresh random token.
+func (s *AuthTokenService) createSession(
+	ctx context.Context,
+	params auth.CreateSessionParams,
+) (string, *auth.Session, error) {
+	token, err := s.generateSecureToken(32)
+	if err != nil {
+		return "", nil, auth.ErrGenerateToken
+	}
+
+	sessionID, err := s.generateSecureToken(16)
+	if err != nil {
+		return "", nil, auth.ErrGenerateToken
+	}
+
+	now := time.Now()
+	sess := &auth.Session{
+		ID:         sessionID,
+		UserID:     params.UserID,
+		IP:         params.IP,
+		UserAgent:  params.UserAgent,
+		DeviceID:   params.DeviceID,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+
+	if err := s.store.Set(ctx, token, sess, s.tokenTTL); err != nil {
+		return "", nil, err
+	}
+
+	return token, sess, nil
+}
+
+// userTokens returns the live sessions of the user keyed by token. Tokens stay
+// inside the service; callers address sessions by their ID only.
+func (s *AuthTokenService) userTokens(ctx context.Context, userID string) (map[string]*auth.Session, error) {
+	tokens, err := s.store.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make(map[string]*auth.Session, len(tokens))
 	for _, token := range tokens {
 		sess, err := s.store.Get(ctx, token)
 		if errors.Is(err, auth.ErrSessionNotFound) {
@@ -105,18 +190,31 @@ func (s *AuthTokenService) ListUserSessions(ctx context.Context, userID string)
 		if err != nil {
 			return nil, err
 		}
-		sessions = append(sessions, sess)
+		sessions[token] = sess
 	}
 
 	return sessions, nil
 }
 
-// RevokeAllForUser deletes all sessions for a user.
-func (s *AuthTokenService) RevokeAllForUser(ctx context.Context, userID string) error {
-	return s.store.DeleteAllUserSessions(ctx, userID)
-}
+// touch extends the session TTL and records the last activity. Sessions created
+// before session IDs existed get one here.
+func (s *AuthTokenService) touch(ctx context.Context, token string, sess *auth.Session) error {
+	if sess.ID != "" && time.Since(sess.LastSeenAt) < lastSeenResolution {
+		return s.store.Refresh(ctx, token, s.tokenTTL)
+	}
 
-// --- Helpers ---
+	if sess.ID == "" {
+		sessionID, err := s.generateSecureToken(16)
+		if err != nil {
+			return err
+		}
+		sess.ID = sessionID
+	}
+	sess.LastSeenAt = time.Now()
+
+	// Set rewrites the session with a fresh TTL
+	return s.store.Set(ctx, token, sess, s.tokenTTL)
+}
 
 func (s *AuthTokenService) generateSecureToken(length int) (string, error) {
 	bytes := make([]byte, length)
@@ -0,0 +1,97 @@
+package token
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/auth/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func login(t *testing.T, s *AuthTokenService, ua string) (string, *auth.Session) {
+	t.Helper()
+
+	pair, err := s.Generate(context.Background(), auth.CreateSessionParams{
+		UserID:    "1",
+		IP:        "127.0.0.1",
+		UserAgent: ua,
+	})
+	require.NoError(t, err)
+
+	sess, err := s.Validate(context.Background(), pair.AccessToken)
+	require.NoError(t, err)
+
+	return pair.AccessToken, sess
+}
+
+func TestListUserSessions_HidesTokens(t *testing.T) {
+	s := NewAuthTokenService(mock.MockSessionStore(), time.Hour)
+
+	tokenA, sessA := login(t, s, "agent-a")
+	tokenB, sessB := login(t, s, "agent-b")
+
+	sessions, err := s.ListUserSessions(context.Background(), "1")
+
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.NotEqual(t, sessA.ID, sessB.ID)
+	for _, sess := range sessions {
+		assert.NotEmpty(t, sess.ID)
+		assert.NotEqual(t, tokenA, sess.ID)
+		assert.NotEqual(t, tokenB, sess.ID)
+		assert.False(t, sess.LastSeenAt.IsZero())
+	}
+}
+
+func TestRevokeSession_ByID(t *testing.T) {
+	s := NewAuthTokenService(mock.MockSessionStore(), time.Hour)
+
+	tokenA, sessA := login(t, s, "agent-a")
+	tokenB, _ := login(t, s, "agent-b")
+
+	err := s.RevokeSession(context.Background(), "1", sessA.ID)
+	require.NoError(t, err)
+
+	_, err = s.Validate(context.Background(), tokenA)
+	assert.Error(t, err)
+	_, err = s.Validate(context.Background(), tokenB)
+	assert.NoError(t, err)
+
+	err = s.RevokeSession(context.Background(), "2", sessA.ID)
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+}
+
+func TestRevokeOtherSessions_KeepsCurrent(t *testing.T) {
+	s := NewAuthTokenService(mock.MockSessionStore(), time.Hour)
+
+	tokenA, sessA := login(t, s, "agent-a")
+	tokenB, _ := login(t, s, "agent-b")
+	tokenC, _ := login(t, s, "agent-c")
+
+	err := s.RevokeOtherSessions(context.Background(), "1", sessA.ID)
+	require.NoError(t, err)
+
+	_, err = s.Validate(context.Background(), tokenA)
+	assert.NoError(t, err)
+	for _, token := range []string{tokenB, tokenC} {
+		_, err = s.Validate(context.Background(), token)
+		assert.Error(t, err)
+	}
+}
+
+func TestRefresh_KeepsDevice(t *testing.T) {
+	s := NewAuthTokenService(mock.MockSessionStore(), time.Hour)
+
+	pair, err := s.Generate(context.Background(), auth.CreateSessionParams{UserID: "1", DeviceID: "pi-1"})
+	require.NoError(t, err)
+
+	refreshed, err := s.Refresh(context.Background(), pair.AccessToken)
+	require.NoError(t, err)
+
+	sess, err := s.Validate(context.Background(), refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "pi-1", sess.DeviceID)
+}
@@ -9,4 +9,7 @@ type Cache interface {
 	Get(ctx context.Context, key string) ([]byte, bool, error)
 	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
+
+	// Take returns the value of key and deletes it in one step, so of concurrent callers only one gets it
+	Take(ctx context.Context, key string) ([]byte, bool, error)
 }
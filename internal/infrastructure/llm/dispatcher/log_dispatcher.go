@@ -0,0 +1,29 @@
+package dispatcher
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/agentreply"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"go.uber.org/zap"
+)
+
+// LogDispatcher only logs reply requests. It is used until an agent runtime is attached.
+type LogDispatcher struct{}
+
+var _ agentreply.Dispatcher = (*LogDispatcher)(nil)
+
+func NewLogDispatcher() *LogDispatcher {
+	return &LogDispatcher{}
+}
+
+func (d *LogDispatcher) Dispatch(_ context.Context, request agentreply.Request) error {
+	logger.Log.Info("agent reply requested",
+		zap.Int64("group_id", int64(request.GroupID)),
+		zap.Int64("trigger_id", int64(request.TriggerID)),
+		zap.Int64("agent_id", int64(request.AgentID)),
+		zap.Int("depth", request.Depth),
+		zap.Int64("user_id", int64(request.UserID)),
+	)
+	return nil
+}
@@ -0,0 +1,105 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/modelcatalog"
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Client talks to the HTTP API of an Ollama server.
+type Client struct {
+	name    string
+	baseURL string
+	http    *http.Client
+}
+
+var _ modelcatalog.Provider = (*Client)(nil)
+
+// NewClient creates an Ollama client. A nil httpClient uses a client with a 10s timeout.
+func NewClient(name, baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	return &Client{
+		name:    name,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    httpClient,
+	}
+}
+
+func (c *Client) Name() string {
+	return c.name
+}
+
+// AgentType Ollama runs models on the local machine
+func (c *Client) AgentType() agent.Type {
+	return agent.Local
+}
+
+// Engine Ollama serves GGUF models
+func (c *Client) Engine() agent.Engine {
+	return agent.GGUF
+}
+
+// ListModels queries the tags endpoint, which lists every locally pulled model.
+func (c *Client) ListModels(ctx context.Context) ([]modelcatalog.ProviderModel, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("build ollama tags request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request ollama tags: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama tags: unexpected status %d", resp.StatusCode)
+	}
+
+	var body tagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode ollama tags: %w", err)
+	}
+
+	models := make([]modelcatalog.ProviderModel, 0, len(body.Models))
+	for _, m := range body.Models {
+		models = append(models, modelcatalog.ProviderModel{
+			Name:          m.Name,
+			Family:        m.Details.Family,
+			ParameterSize: m.Details.ParameterSize,
+			Quantization:  m.Details.QuantizationLevel,
+			SizeBytes:     m.Size,
+			Digest:        m.Digest,
+			ModifiedAt:    m.ModifiedAt,
+		})
+	}
+
+	return models, nil
+}
+
+type tagsResponse struct {
+	Models []tagModel `json:"models"`
+}
+
+type tagModel struct {
+	Name       string    `json:"name"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Size       int64     `json:"size"`
+	Digest     string    `json:"digest"`
+	Details    struct {
+		Format            string `json:"format"`
+		Family            string `json:"family"`
+		ParameterSize     string `json:"parameter_size"`
+		QuantizationLevel string `json:"quantization_level"`
+	} `json:"details"`
+}
@@ -0,0 +1,71 @@
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tagsBody = `{
+  "models": [
+    {
+      "name": "llama3:8b",
+      "model": "llama3:8b",
+      "modified_at": "2024-05-01T10:00:00.000000+08:00",
+      "size": 4661224676,
+      "digest": "365c0bd3c000",
+      "details": {
+        "format": "gguf",
+        "family": "llama",
+        "families": ["llama"],
+        "parameter_size": "8.0B",
+        "quantization_level": "Q4_0"
+      }
+    },
+    {
+      "name": "qwen2:0.5b",
+      "modified_at": "2024-06-01T10:00:00Z",
+      "size": 352164041,
+      "digest": "6f48b936a09f",
+      "details": {"family": "qwen2", "parameter_size": "494M", "quantization_level": "Q4_0"}
+    }
+  ]
+}`
+
+func TestClient_ListModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/tags", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(tagsBody))
+	}))
+	defer srv.Close()
+
+	client := NewClient("ollama", srv.URL+"/", nil)
+
+	models, err := client.ListModels(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, models, 2)
+	assert.Equal(t, "llama3:8b", models[0].Name)
+	assert.Equal(t, "llama", models[0].Family)
+	assert.Equal(t, "8.0B", models[0].ParameterSize)
+	assert.Equal(t, "Q4_0", models[0].Quantization)
+	assert.Equal(t, int64(4661224676), models[0].SizeBytes)
+	assert.False(t, models[0].ModifiedAt.IsZero())
+	assert.Equal(t, "qwen2:0.5b", models[1].Name)
+}
+
+func TestClient_ListModels_UnexpectedStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient("ollama", srv.URL, nil).ListModels(context.Background())
+
+	assert.ErrorContains(t, err, "503")
+}
@@ -0,0 +1,23 @@
+package mail
+
+import (
+	"bytes"
+	"mime"
+	"time"
+
+	mailApp "github.com/HiroLiang/goat-server/internal/application/shared/mail"
+)
+
+// format renders the message as an RFC 5322 plain text mail
+func format(from string, msg mailApp.Message, now time.Time) []byte {
+	var b bytes.Buffer
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return b.Bytes()
+}
@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	mailApp "github.com/HiroLiang/goat-server/internal/application/shared/mail"
+)
+
+// OutboxMailer writes every mail as an .eml file into a directory instead of
+// sending it. For dev and tests.
+type OutboxMailer struct {
+	dir  string
+	from string
+}
+
+func NewOutboxMailer(dir, from string) *OutboxMailer {
+	return &OutboxMailer{dir: dir, from: from}
+}
+
+var _ mailApp.Mailer = (*OutboxMailer)(nil)
+
+func (m *OutboxMailer) Send(_ context.Context, msg mailApp.Message) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%d-%s.eml", now.UnixNano(), sanitize(msg.To))
+
+	return os.WriteFile(filepath.Join(m.dir, name), format(m.from, msg, now), 0o644)
+}
+
+// sanitize keeps the recipient readable in the file name without path separators
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '@', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mailApp "github.com/HiroLiang/goat-server/internal/application/shared/mail"
+)
+
+func TestOutboxMailer_WritesEml(t *testing.T) {
+	dir := t.TempDir()
+	mailer := NewOutboxMailer(dir, "Goat <no-reply@goat.local>")
+
+	err := mailer.Send(context.Background(), mailApp.Message{
+		To:      "../a@b.com",
+		Subject: "Reset your password",
+		Body:    "https://goat.local/reset?token=abc",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("outbox files = %v, err = %v, want one file", files, err)
+	}
+	if strings.Contains(filepath.Base(files[0]), "/") || !strings.HasSuffix(files[0], "-.._a@b.com.eml") {
+		t.Errorf("file name = %q, want sanitized recipient", filepath.Base(files[0]))
+	}
+
+	b, _ := os.ReadFile(files[0])
+	content := string(b)
+	for _, want := range []string{"To: ../a@b.com\r\n", "Subject: Reset your password\r\n", "\r\n\r\nhttps://goat.local/reset?token=abc"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("mail does not contain %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	mailApp "github.com/HiroLiang/goat-server/internal/application/shared/mail"
+)
+
+// SMTPMailer sends mails through an SMTP relay. Auth is skipped when Username is empty.
+type SMTPMailer struct {
+	addr     string
+	host     string
+	from     string
+	username string
+	password string
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		host:     host,
+		from:     from,
+		username: username,
+		password: password,
+	}
+}
+
+var _ mailApp.Mailer = (*SMTPMailer)(nil)
+
+func (m *SMTPMailer) Send(ctx context.Context, msg mailApp.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	return smtp.SendMail(m.addr, auth, sender.Address, []string{msg.To}, format(m.from, msg, time.Now()))
+}
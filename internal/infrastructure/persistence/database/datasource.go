@@ -1,7 +1,10 @@
 package database
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -15,30 +18,53 @@ type DBName string
 
 const (
 	Postgres DBName = "postgres"
+	Sqlite   DBName = "sqlite"
 )
 
 var dbNames = map[string]DBName{
 	string(Postgres): Postgres,
+	string(Sqlite):   Sqlite,
 }
 
 var placeholders = map[DBName]squirrel.PlaceholderFormat{
 	Postgres: squirrel.Dollar,
+	Sqlite:   squirrel.Question,
+}
+
+// ErrDriverMissing the configured driver is not compiled into this build
+var ErrDriverMissing = errors.New("database driver not in this build")
+
+// driverHints how to get the driver of a database into the build
+var driverHints = map[DBName]string{
+	Sqlite: "build with -tags sqlite",
 }
 
 type DataSources struct {
 	Sources sync.Map
 }
 
-func NewDataSources(databases map[string]ConnectionConfig) (*DataSources, error) {
+// NewDataSources connect the databases of the given names, or every known database when none is given
+func NewDataSources(databases map[string]ConnectionConfig, names ...DBName) (*DataSources, error) {
 
 	// Clear dbs
 	var dataSources = &DataSources{}
 
+	if len(names) == 0 {
+		for _, dbName := range dbNames {
+			names = append(names, dbName)
+		}
+	}
+
+	// Fail before connecting anything when a wanted driver is missing
+	if err := checkDrivers(databases, names); err != nil {
+		return nil, err
+	}
+
 	// For each database config
 	for name, conf := range databases {
 
-		// check if db name is valid (set in enum DBName)
-		if _, ok := isValidName(name); ok {
+		// check if db name is valid (set in enum DBName) and wanted
+		if dbName, ok := isValidName(name); ok && slices.Contains(names, dbName) {
 
 			// connect to database
 			db, err := sqlx.Connect(conf.Driver, conf.Dsn)
@@ -72,7 +98,7 @@ func NewDataSources(databases map[string]ConnectionConfig) (*DataSources, error)
 	}
 
 	// Check if all databases are initialized
-	if err := checkDBMap(dataSources); err != nil {
+	if err := checkDBMap(dataSources, names); err != nil {
 		return nil, err
 	}
 
@@ -101,14 +127,38 @@ func (sources *DataSources) CloseAllDBs() {
 	})
 }
 
+// ParseDBName returns the database of a configured name
+func ParseDBName(name string) (DBName, error) {
+	dbName, ok := isValidName(name)
+	if !ok {
+		return "", fmt.Errorf("unknown database %q", name)
+	}
+	return dbName, nil
+}
+
 func isValidName(name string) (DBName, bool) {
 	dbName, ok := dbNames[name]
 	return dbName, ok
 }
 
-func checkDBMap(dataSources *DataSources) error {
-	for name, key := range dbNames {
-		if db := dataSources.GetDB(key); db == nil {
+func checkDrivers(databases map[string]ConnectionConfig, names []DBName) error {
+	for _, name := range names {
+		conf, ok := databases[string(name)]
+		if !ok || slices.Contains(sql.Drivers(), conf.Driver) {
+			continue
+		}
+		if hint, ok := driverHints[name]; ok {
+			return fmt.Errorf("init database %s: %w: %q, %s", name, ErrDriverMissing, conf.Driver, hint)
+		}
+		return fmt.Errorf("init database %s: %w: %q", name, ErrDriverMissing, conf.Driver)
+	}
+
+	return nil
+}
+
+func checkDBMap(dataSources *DataSources, names []DBName) error {
+	for _, name := range names {
+		if db := dataSources.GetDB(name); db == nil {
 			return fmt.Errorf("Database %s not initialized\n", name)
 		}
 	}
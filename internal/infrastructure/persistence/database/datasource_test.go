@@ -0,0 +1,17 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewDataSources_DriverMissing Test a backend whose driver is not built in fails before connecting
+func TestNewDataSources_DriverMissing(t *testing.T) {
+	_, err := NewDataSources(map[string]ConnectionConfig{
+		string(Sqlite): {Driver: "sqlite", Dsn: "file:goat.db"},
+	}, Sqlite)
+
+	assert.ErrorIs(t, err, ErrDriverMissing)
+	assert.ErrorContains(t, err, "build with -tags sqlite")
+}
@@ -0,0 +1,7 @@
+//go:build sqlite
+
+package database
+
+// Pure Go SQLite driver for the embedded backend, registered as "sqlite".
+// Only builds with -tags sqlite carry it, NewDataSources refuses the backend otherwise.
+import _ "modernc.org/sqlite"
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// Queryer what repositories run statements on, a *sqlx.DB or the *sqlx.Tx of a transaction
+type Queryer interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+var (
+	_ Queryer = (*sqlx.DB)(nil)
+	_ Queryer = (*sqlx.Tx)(nil)
+)
+
+// txAttempts how often TxManager runs a function the database gave up on for a conflict
+const txAttempts = 3
+
+// txBackoff wait before the next attempt, multiplied by the attempts made
+const txBackoff = 20 * time.Millisecond
+
+type txKey struct{}
+
+// txState the transaction a context carries, depth numbers the savepoints nested in it
+type txState struct {
+	db    *sqlx.DB
+	tx    *sqlx.Tx
+	depth int
+}
+
+func txFrom(ctx context.Context, db *sqlx.DB) (*txState, bool) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok || state.db != db {
+		return nil, false
+	}
+	return state, true
+}
+
+// Conn returns the transaction on q the context carries, or q itself outside a transaction
+func Conn(ctx context.Context, q Queryer) Queryer {
+	if db, ok := q.(*sqlx.DB); ok {
+		if state, ok := txFrom(ctx, db); ok {
+			return state.tx
+		}
+	}
+	return q
+}
+
+// WithinTx runs fn in a transaction of db, or in a savepoint when ctx already carries one.
+// Repositories called with the context fn gets join it. An error of fn rolls back its own level only.
+func WithinTx(ctx context.Context, db *sqlx.DB, fn func(ctx context.Context) error) (err error) {
+	if state, ok := txFrom(ctx, db); ok {
+		return withinSavepoint(ctx, state, fn)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{db: db, tx: tx})); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// withinSavepoint runs fn in a savepoint of the running transaction
+func withinSavepoint(ctx context.Context, state *txState, fn func(ctx context.Context) error) error {
+	state.depth++
+	defer func() { state.depth-- }()
+
+	name := fmt.Sprintf("sp_%d", state.depth)
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("create savepoint: %w", err)
+	}
+
+	if err := fn(ctx); err != nil {
+		if _, rbErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback to savepoint: %w", rbErr))
+		}
+		return err
+	}
+
+	if _, err := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+	return nil
+}
+
+// TxManager runs use case steps in one transaction of a database. A transaction the database
+// aborts for a conflict with a concurrent one is run again from the start.
+type TxManager struct {
+	db        *sqlx.DB
+	retryable func(err error) bool
+}
+
+func NewTxManager(db *sqlx.DB, name DBName) *TxManager {
+	retryable := isSerializationFailure
+	if name == Sqlite {
+		retryable = isBusy
+	}
+	return &TxManager{db: db, retryable: retryable}
+}
+
+// WithinTx runs fn in a transaction, fn may run again and must leave side effects to after it.
+// Nested calls become savepoints and leave retrying to the outermost one.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := txFrom(ctx, m.db); ok {
+		return WithinTx(ctx, m.db, fn)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := WithinTx(ctx, m.db, fn)
+		if err == nil || attempt >= txAttempts || !m.retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * txBackoff):
+		}
+	}
+}
+
+// isSerializationFailure Postgres serialization_failure and deadlock_detected
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+// isBusy SQLite gave up waiting for the write lock, extended codes keep SQLITE_BUSY in the low byte
+func isBusy(err error) bool {
+	const sqliteBusy = 5
+
+	var coded interface{ Code() int }
+	return errors.As(err, &coded) && coded.Code()&0xff == sqliteBusy
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/testutil"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWithinTx_Commit Test statements on the context run in the transaction
+func TestWithinTx_Commit(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	xdb := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO chat_groups`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := WithinTx(context.Background(), xdb, func(ctx context.Context) error {
+		conn := Conn(ctx, xdb)
+		assert.IsType(t, &sqlx.Tx{}, conn)
+
+		_, err := conn.ExecContext(ctx, "INSERT INTO chat_groups (name) VALUES ('g')")
+		return err
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestWithinTx_Rollback Test an error of the function rolls everything back
+func TestWithinTx_Rollback(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	xdb := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	failed := errors.New("member exists")
+	err := WithinTx(context.Background(), xdb, func(ctx context.Context) error {
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestWithinTx_NestedSavepoint Test a failing nested call only rolls back to its savepoint
+func TestWithinTx_NestedSavepoint(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	xdb := sqlx.NewDb(db, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`RELEASE SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT sp_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	failed := errors.New("optional step failed")
+	err := WithinTx(context.Background(), xdb, func(ctx context.Context) error {
+		assert.NoError(t, WithinTx(ctx, xdb, func(ctx context.Context) error { return nil }))
+		assert.ErrorIs(t, WithinTx(ctx, xdb, func(ctx context.Context) error { return failed }), failed)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestConn_OtherDatabase Test a transaction of one database is not used for another
+func TestConn_OtherDatabase(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	xdb := sqlx.NewDb(db, "postgres")
+	other, _ := testutil.SetupDB(t)
+	otherDB := sqlx.NewDb(other, "postgres")
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	err := WithinTx(context.Background(), xdb, func(ctx context.Context) error {
+		assert.Same(t, otherDB, Conn(ctx, otherDB))
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTxManager_RetrySerializationFailure Test the whole function runs again after a serialization failure
+func TestTxManager_RetrySerializationFailure(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	manager := NewTxManager(sqlx.NewDb(db, "postgres"), Postgres)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	calls := 0
+	err := manager.WithinTx(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTxManager_NoRetry Test other errors are returned at once
+func TestTxManager_NoRetry(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	manager := NewTxManager(sqlx.NewDb(db, "postgres"), Postgres)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	calls := 0
+	err := manager.WithinTx(context.Background(), func(ctx context.Context) error {
+		calls++
+		return &pgconn.PgError{Code: "23505"}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/infrastructure/cache"
+)
+
+// sweepInterval is how often Set drops expired entries nobody read again
+const sweepInterval = time.Minute
+
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// MemoryCache keeps values in process for running without Redis. A zero ttl never expires.
+type MemoryCache struct {
+	mu        sync.Mutex
+	values    map[string]entry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+var _ cache.Cache = (*MemoryCache)(nil)
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		values: make(map[string]entry),
+		now:    time.Now,
+	}
+}
+
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.values[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if e.expired(m.now()) {
+		delete(m.values, key)
+		return nil, false, nil
+	}
+
+	return append([]byte(nil), e.value...), true, nil
+}
+
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	e := entry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+	m.values[key] = e
+
+	return nil
+}
+
+func (m *MemoryCache) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.values, key)
+	return nil
+}
+
+func (m *MemoryCache) Take(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.values[key]
+	delete(m.values, key)
+	if !ok || e.expired(m.now()) {
+		return nil, false, nil
+	}
+
+	return e.value, true, nil
+}
+
+// sweep drops expired entries at most once per sweepInterval, the caller holds the lock
+func (m *MemoryCache) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, e := range m.values {
+		if e.expired(now) {
+			delete(m.values, key)
+		}
+	}
+}
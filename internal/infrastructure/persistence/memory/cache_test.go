@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCache_ExpiresAfterTTL(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewMemoryCache()
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, c.Set(ctx, "short", []byte("a"), time.Second))
+	assert.NoError(t, c.Set(ctx, "forever", []byte("b"), 0))
+
+	v, ok, err := c.Get(ctx, "short")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("a"), v)
+
+	now = now.Add(time.Second)
+	_, ok, _ = c.Get(ctx, "short")
+	assert.False(t, ok)
+
+	v, ok, _ = c.Get(ctx, "forever")
+	assert.True(t, ok, "a zero ttl never expires")
+	assert.Equal(t, []byte("b"), v)
+
+	assert.NoError(t, c.Delete(ctx, "forever"))
+	_, ok, _ = c.Get(ctx, "forever")
+	assert.False(t, ok)
+}
+
+func TestMemoryCache_TakeOnce(t *testing.T) {
+	c := NewMemoryCache()
+	ctx := context.Background()
+
+	assert.NoError(t, c.Set(ctx, "key", []byte("a"), time.Minute))
+
+	v, ok, err := c.Take(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("a"), v)
+
+	_, ok, _ = c.Take(ctx, "key")
+	assert.False(t, ok)
+	_, ok, _ = c.Get(ctx, "key")
+	assert.False(t, ok)
+}
+
+func TestMemoryCache_SweepsUnreadEntries(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewMemoryCache()
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.NoError(t, c.Set(ctx, "stale", []byte("a"), time.Second))
+
+	now = now.Add(2 * sweepInterval)
+	assert.NoError(t, c.Set(ctx, "fresh", []byte("b"), time.Second))
+
+	assert.Len(t, c.values, 1)
+}
+
+func TestMemoryCache_ConcurrentUse(t *testing.T) {
+	c := NewMemoryCache()
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for j := 0; j < 100; j++ {
+				_ = c.Set(ctx, "key", []byte("v"), time.Minute)
+				_, _, _ = c.Get(ctx, "key")
+				_ = c.Delete(ctx, "key")
+			}
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		<-done
+	}
+}
@@ -0,0 +1,203 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+)
+
+// rateLimitEntry holds whichever state the algorithm of its key needs
+type rateLimitEntry struct {
+	count   int64
+	hits    []time.Time
+	tat     time.Time
+	expires time.Time
+}
+
+// MemoryRateLimitRepository counts requests in process for running without Redis.
+// It follows the take scripts of the Redis repository step by step.
+type MemoryRateLimitRepository struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryRateLimitRepository() *MemoryRateLimitRepository {
+	return &MemoryRateLimitRepository{
+		entries: make(map[string]*rateLimitEntry),
+		now:     time.Now,
+	}
+}
+
+var _ security.RateLimitRepository = (*MemoryRateLimitRepository)(nil)
+
+func (r *MemoryRateLimitRepository) Increment(_ context.Context, key string, window time.Duration) (count int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	entry := r.entry(key, now)
+	entry.count++
+	entry.expires = now.Add(window)
+
+	return entry.count, nil
+}
+
+func (r *MemoryRateLimitRepository) Get(_ context.Context, key string) (count int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[key]
+	if !ok || !r.now().Before(entry.expires) {
+		return 0, nil
+	}
+	return entry.count, nil
+}
+
+func (r *MemoryRateLimitRepository) Reset(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.entries, key)
+	return nil
+}
+
+func (r *MemoryRateLimitRepository) IncrementSliding(_ context.Context, key string, window time.Duration, now time.Time) (count int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := r.entry(key, now)
+	entry.hits = append(dropBefore(entry.hits, now.Add(-window)), now)
+	entry.expires = now.Add(window)
+
+	return int64(len(entry.hits)), nil
+}
+
+func (r *MemoryRateLimitRepository) Take(_ context.Context, key string, policy security.RateLimitPolicy, now time.Time) (security.RateLimitResult, error) {
+	// Nothing passes a policy without quota
+	if policy.Limit <= 0 {
+		return security.RateLimitResult{Limit: policy.Limit, RetryAfter: max(policy.Window, time.Microsecond)}, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Algorithms keep different state under their keys
+	entry := r.entry(string(policy.Algorithm)+":"+key, now)
+
+	switch policy.Algorithm {
+	case security.FixedWindow:
+		return takeFixedWindow(entry, policy, now), nil
+	case security.SlidingWindow:
+		return takeSlidingWindow(entry, policy, now), nil
+	case security.GCRA:
+		return takeGCRA(entry, policy, now), nil
+	default:
+		return security.RateLimitResult{}, fmt.Errorf("unknown rate limit algorithm %q", policy.Algorithm)
+	}
+}
+
+// entry returns the live entry of a key, starting over once it expired. The caller holds the lock.
+func (r *MemoryRateLimitRepository) entry(key string, now time.Time) *rateLimitEntry {
+	r.sweep(now)
+
+	entry, ok := r.entries[key]
+	if !ok || !now.Before(entry.expires) {
+		entry = &rateLimitEntry{}
+		r.entries[key] = entry
+	}
+	return entry
+}
+
+// sweep drops expired entries at most once a minute. The caller holds the lock.
+func (r *MemoryRateLimitRepository) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < time.Minute {
+		return
+	}
+	r.lastSweep = now
+
+	for key, entry := range r.entries {
+		if !now.Before(entry.expires) {
+			delete(r.entries, key)
+		}
+	}
+}
+
+// takeFixedWindow starts the window with the first request and never extends it
+func takeFixedWindow(entry *rateLimitEntry, policy security.RateLimitPolicy, now time.Time) security.RateLimitResult {
+	result := security.RateLimitResult{Limit: policy.Limit}
+
+	if entry.count == 0 {
+		entry.expires = now.Add(policy.Window)
+	}
+	result.Reset = entry.expires.Sub(now)
+
+	if entry.count >= policy.Limit {
+		result.RetryAfter = max(result.Reset, time.Millisecond)
+		return result
+	}
+
+	entry.count++
+	result.Remaining = policy.Limit - entry.count
+	return result
+}
+
+// takeSlidingWindow counts the requests of the last window
+func takeSlidingWindow(entry *rateLimitEntry, policy security.RateLimitPolicy, now time.Time) security.RateLimitResult {
+	result := security.RateLimitResult{Limit: policy.Limit}
+
+	entry.hits = dropBefore(entry.hits, now.Add(-policy.Window))
+	allowed := int64(len(entry.hits)) < policy.Limit
+	if allowed {
+		entry.hits = append(entry.hits, now)
+		entry.expires = now.Add(policy.Window)
+	}
+
+	result.Remaining = max(policy.Limit-int64(len(entry.hits)), 0)
+	if len(entry.hits) > 0 {
+		result.Reset = entry.hits[len(entry.hits)-1].Add(policy.Window).Sub(now)
+		if !allowed {
+			result.RetryAfter = max(entry.hits[0].Add(policy.Window).Sub(now), time.Microsecond)
+		}
+	}
+	return result
+}
+
+// takeGCRA keeps the theoretical arrival time of the next request; a request is
+// allowed while that time is no more than one window ahead
+func takeGCRA(entry *rateLimitEntry, policy security.RateLimitPolicy, now time.Time) security.RateLimitResult {
+	result := security.RateLimitResult{Limit: policy.Limit}
+	interval := max(policy.Window/time.Duration(policy.Limit), 1)
+
+	tat := entry.tat
+	if tat.Before(now) {
+		tat = now
+	}
+
+	next := tat.Add(interval)
+	allowAt := next.Add(-policy.Window)
+	if allowAt.After(now) {
+		result.Reset = tat.Sub(now)
+		result.RetryAfter = max(allowAt.Sub(now), time.Microsecond)
+		return result
+	}
+
+	entry.tat = next
+	entry.expires = next
+	result.Remaining = int64(now.Sub(allowAt) / interval)
+	result.Reset = next.Sub(now)
+	return result
+}
+
+// dropBefore removes the hits at or before start, hits are kept in order
+func dropBefore(hits []time.Time, start time.Time) []time.Time {
+	i := 0
+	for i < len(hits) && !hits[i].After(start) {
+		i++
+	}
+	return hits[i:]
+}
@@ -0,0 +1,108 @@
+package security
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/stretchr/testify/assert"
+)
+
+var testNow = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestMemoryRateLimitRepository_Take_FixedWindow(t *testing.T) {
+	repo := NewMemoryRateLimitRepository()
+	ctx := context.Background()
+	policy := security.RateLimitPolicy{Limit: 2, Window: time.Minute, Algorithm: security.FixedWindow}
+
+	result, err := repo.Take(ctx, "ip:1", policy, testNow)
+	assert.NoError(t, err)
+	assert.Equal(t, security.RateLimitResult{Limit: 2, Remaining: 1, Reset: time.Minute}, result)
+
+	result, _ = repo.Take(ctx, "ip:1", policy, testNow.Add(10*time.Second))
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.Equal(t, 50*time.Second, result.Reset, "the window is not extended")
+
+	result, _ = repo.Take(ctx, "ip:1", policy, testNow.Add(20*time.Second))
+	assert.True(t, result.Exceeded())
+	assert.Equal(t, 40*time.Second, result.RetryAfter)
+
+	result, _ = repo.Take(ctx, "ip:1", policy, testNow.Add(time.Minute))
+	assert.False(t, result.Exceeded(), "a new window starts once the old one expired")
+}
+
+func TestMemoryRateLimitRepository_Take_SlidingWindow(t *testing.T) {
+	repo := NewMemoryRateLimitRepository()
+	ctx := context.Background()
+	policy := security.RateLimitPolicy{Limit: 2, Window: time.Minute, Algorithm: security.SlidingWindow}
+
+	_, _ = repo.Take(ctx, "user:1", policy, testNow)
+	_, _ = repo.Take(ctx, "user:1", policy, testNow.Add(30*time.Second))
+
+	result, _ := repo.Take(ctx, "user:1", policy, testNow.Add(40*time.Second))
+	assert.True(t, result.Exceeded())
+	assert.Equal(t, 20*time.Second, result.RetryAfter, "retry once the oldest request leaves the window")
+
+	result, _ = repo.Take(ctx, "user:1", policy, testNow.Add(time.Minute))
+	assert.False(t, result.Exceeded())
+	assert.Equal(t, int64(0), result.Remaining)
+}
+
+func TestMemoryRateLimitRepository_Take_GCRA(t *testing.T) {
+	repo := NewMemoryRateLimitRepository()
+	ctx := context.Background()
+	policy := security.RateLimitPolicy{Limit: 2, Window: time.Minute, Algorithm: security.GCRA}
+
+	result, _ := repo.Take(ctx, "user:1", policy, testNow)
+	assert.Equal(t, security.RateLimitResult{Limit: 2, Remaining: 1, Reset: 30 * time.Second}, result)
+
+	result, _ = repo.Take(ctx, "user:1", policy, testNow)
+	assert.Equal(t, security.RateLimitResult{Limit: 2, Remaining: 0, Reset: time.Minute}, result)
+
+	result, _ = repo.Take(ctx, "user:1", policy, testNow)
+	assert.True(t, result.Exceeded())
+	assert.Equal(t, 30*time.Second, result.RetryAfter, "one request is released per interval")
+
+	result, _ = repo.Take(ctx, "user:1", policy, testNow.Add(30*time.Second))
+	assert.False(t, result.Exceeded())
+}
+
+func TestMemoryRateLimitRepository_Take_KeepsAlgorithmsApart(t *testing.T) {
+	repo := NewMemoryRateLimitRepository()
+	ctx := context.Background()
+
+	fixed := security.RateLimitPolicy{Limit: 1, Window: time.Minute, Algorithm: security.FixedWindow}
+	gcra := security.RateLimitPolicy{Limit: 1, Window: time.Minute, Algorithm: security.GCRA}
+
+	_, _ = repo.Take(ctx, "key", fixed, testNow)
+	result, _ := repo.Take(ctx, "key", gcra, testNow)
+	assert.False(t, result.Exceeded())
+
+	_, err := repo.Take(ctx, "key", security.RateLimitPolicy{Limit: 1, Window: time.Minute, Algorithm: "leaky"}, testNow)
+	assert.Error(t, err)
+}
+
+func TestMemoryRateLimitRepository_Increment(t *testing.T) {
+	repo := NewMemoryRateLimitRepository()
+	repo.now = func() time.Time { return testNow }
+	ctx := context.Background()
+
+	count, err := repo.Increment(ctx, "mail:a", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, _ = repo.Increment(ctx, "mail:a", time.Minute)
+	assert.Equal(t, int64(2), count)
+
+	count, _ = repo.Get(ctx, "mail:a")
+	assert.Equal(t, int64(2), count)
+
+	repo.now = func() time.Time { return testNow.Add(time.Minute) }
+	count, _ = repo.Get(ctx, "mail:a")
+	assert.Zero(t, count, "counters expire after the window")
+
+	_, _ = repo.Increment(ctx, "mail:a", time.Minute)
+	assert.NoError(t, repo.Reset(ctx, "mail:a"))
+	count, _ = repo.Get(ctx, "mail:a")
+	assert.Zero(t, count)
+}
@@ -0,0 +1,388 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// VersionTable records the applied migrations
+const VersionTable = "schema_migrations"
+
+// lockKey Postgres advisory lock replicas take before migrating, "goat" in ASCII
+const lockKey int64 = 0x676f6174
+
+var (
+	ErrChecksumMismatch = errors.New("applied migration was changed afterwards")
+	ErrUnknownVersion   = errors.New("applied migration is missing from this build")
+	ErrUntracked        = errors.New("schema exists without migration history, run migrate baseline first")
+	ErrAlreadyTracked   = errors.New("schema already has applied migrations")
+	ErrNoSuchVersion    = errors.New("no migration of this version")
+)
+
+// dialect the statements that differ between backends
+type dialect struct {
+	builder     squirrel.StatementBuilderType
+	createTable string
+	lock        string // empty when the backend needs no lock
+	unlock      string
+	schemaFound string // whether the users table of the first migration exists
+}
+
+var dialects = map[database.DBName]dialect{
+	database.Postgres: {
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+		createTable: `CREATE TABLE IF NOT EXISTS ` + VersionTable + ` (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT      NOT NULL,
+			checksum   TEXT      NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT now()
+		)`,
+		lock:        `SELECT pg_advisory_lock($1)`,
+		unlock:      `SELECT pg_advisory_unlock($1)`,
+		schemaFound: `SELECT to_regclass('users') IS NOT NULL`,
+	},
+	// A SQLite file belongs to a single server, its write lock already serializes transactions
+	database.Sqlite: {
+		builder: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		createTable: `CREATE TABLE IF NOT EXISTS ` + VersionTable + ` (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT      NOT NULL,
+			checksum   TEXT      NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT (` + sqlite.Now + `)
+		)`,
+		schemaFound: `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'users')`,
+	},
+}
+
+// Status of one migration, Modified tells the script changed after it was applied
+// and Missing that an applied version is not part of this build
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	Modified  bool
+	Missing   bool
+}
+
+type appliedRecord struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	Checksum  string    `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// Migrator applies and reverts the migrations of one backend, each in its own transaction
+type Migrator struct {
+	db         *sqlx.DB
+	dialect    dialect
+	migrations []Migration
+}
+
+func NewMigrator(db *sqlx.DB, backend database.DBName) (*Migrator, error) {
+	d, ok := dialects[backend]
+	if !ok {
+		return nil, fmt.Errorf("no migrations for database %s", backend)
+	}
+
+	migrations, err := Load(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, dialect: d, migrations: migrations}, nil
+}
+
+// Up applies every pending migration in version order and returns the applied ones.
+// Replicas migrating at the same time wait for the lock and find nothing left to do.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sqlx.Conn) error {
+		applied, err := m.verified(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			if err := m.checkUntracked(ctx, conn); err != nil {
+				return err
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the newest steps applied migrations and returns the reverted ones
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sqlx.Conn) error {
+		applied, err := m.verified(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Baseline records the migrations up to version as applied without running them, for a
+// schema created before migrations were tracked. It refuses a schema with applied migrations.
+func (m *Migrator) Baseline(ctx context.Context, version int64) ([]Migration, error) {
+	if !slices.ContainsFunc(m.migrations, func(migration Migration) bool { return migration.Version == version }) {
+		return nil, fmt.Errorf("%w: %d", ErrNoSuchVersion, version)
+	}
+
+	var done []Migration
+	err := m.locked(ctx, func(conn *sqlx.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) > 0 {
+			return ErrAlreadyTracked
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if err := m.record(ctx, conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status lists the migrations of this build followed by applied versions it does not know
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close()
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if rec, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &rec.AppliedAt
+			status.Modified = rec.Checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, rec := range sortedRecords(applied) {
+		statuses = append(statuses, Status{Version: rec.Version, Name: rec.Name, AppliedAt: &rec.AppliedAt, Missing: true})
+	}
+
+	return statuses, nil
+}
+
+// locked runs fn on one connection holding the migration lock
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sqlx.Conn) error) (err error) {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close()
+
+	if m.dialect.lock != "" {
+		if _, err := conn.ExecContext(ctx, m.dialect.lock, lockKey); err != nil {
+			return fmt.Errorf("take migration lock: %w", err)
+		}
+		defer func() {
+			// The lock also goes with the session if the unlock fails
+			if _, unlockErr := conn.ExecContext(context.WithoutCancel(ctx), m.dialect.unlock, lockKey); unlockErr != nil && err == nil {
+				err = fmt.Errorf("release migration lock: %w", unlockErr)
+			}
+		}()
+	}
+
+	return fn(conn)
+}
+
+// verified returns the applied migrations after checking they match this build
+func (m *Migrator) verified(ctx context.Context, conn *sqlx.Conn) (map[int64]appliedRecord, error) {
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for _, rec := range sortedRecords(applied) {
+		migration, ok := known[rec.Version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d_%s", ErrUnknownVersion, rec.Version, rec.Name)
+		}
+		if migration.Checksum != rec.Checksum {
+			return nil, fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, rec.Version, rec.Name)
+		}
+	}
+
+	return applied, nil
+}
+
+// applied creates the version table when missing and reads it
+func (m *Migrator) applied(ctx context.Context, conn *sqlx.Conn) (map[int64]appliedRecord, error) {
+	if _, err := conn.ExecContext(ctx, m.dialect.createTable); err != nil {
+		return nil, fmt.Errorf("create version table: %w", err)
+	}
+
+	query, args, err := m.dialect.builder.
+		Select("version", "name", "checksum", "applied_at").
+		From(VersionTable).
+		OrderBy("version").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build applied query: %w", err)
+	}
+
+	var records []appliedRecord
+	if err := conn.SelectContext(ctx, &records, query, args...); err != nil {
+		return nil, fmt.Errorf("read applied migrations: %w", err)
+	}
+
+	applied := make(map[int64]appliedRecord, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
+
+// checkUntracked fails when the schema was created without migrations, applying
+// the first one would collide with the existing tables
+func (m *Migrator) checkUntracked(ctx context.Context, conn *sqlx.Conn) error {
+	var found bool
+	if err := conn.GetContext(ctx, &found, m.dialect.schemaFound); err != nil {
+		return fmt.Errorf("look for existing schema: %w", err)
+	}
+	if found {
+		return ErrUntracked
+	}
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sqlx.Conn, migration Migration) error {
+	return m.inRecordTx(ctx, conn, migration, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+			return fmt.Errorf("apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		return nil
+	})
+}
+
+// record marks the migration applied without running it
+func (m *Migrator) record(ctx context.Context, conn *sqlx.Conn, migration Migration) error {
+	return m.inRecordTx(ctx, conn, migration, func(*sqlx.Tx) error { return nil })
+}
+
+// inRecordTx runs fn and records the migration in the same transaction
+func (m *Migrator) inRecordTx(ctx context.Context, conn *sqlx.Conn, migration Migration, fn func(tx *sqlx.Tx) error) error {
+	query, args, err := m.dialect.builder.
+		Insert(VersionTable).
+		Columns("version", "name", "checksum").
+		Values(migration.Version, migration.Name, migration.Checksum).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build version insert: %w", err)
+	}
+
+	return inTx(ctx, conn, func(tx *sqlx.Tx) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("record migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		return nil
+	})
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sqlx.Conn, migration Migration) error {
+	query, args, err := m.dialect.builder.
+		Delete(VersionTable).
+		Where(squirrel.Eq{"version": migration.Version}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build version delete: %w", err)
+	}
+
+	return inTx(ctx, conn, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+			return fmt.Errorf("revert migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("unrecord migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		return nil
+	})
+}
+
+// inTx commits when fn succeeds, so a failed script leaves the schema untouched
+func inTx(ctx context.Context, conn *sqlx.Conn, fn func(tx *sqlx.Tx) error) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin migration: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration: %w", err)
+	}
+	return nil
+}
+
+func sortedRecords(applied map[int64]appliedRecord) []appliedRecord {
+	records := make([]appliedRecord, 0, len(applied))
+	for _, rec := range applied {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Version < records[j].Version
+	})
+	return records
+}
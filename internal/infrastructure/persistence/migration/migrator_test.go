@@ -0,0 +1,331 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+func testMigrations() []Migration {
+	return []Migration{
+		{Version: 1, Name: "users", Up: "CREATE TABLE users (id BIGINT)", Down: "DROP TABLE users", Checksum: checksum("CREATE TABLE users (id BIGINT)")},
+		{Version: 2, Name: "agents", Up: "CREATE TABLE agents (id BIGINT)", Down: "DROP TABLE agents", Checksum: checksum("CREATE TABLE agents (id BIGINT)")},
+	}
+}
+
+func appliedRows(migrations ...Migration) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at"})
+	for _, m := range migrations {
+		rows.AddRow(m.Version, m.Name, m.Checksum, time.Now())
+	}
+	return rows
+}
+
+// TestLoad_BackendsInStep Test every backend embeds the same versions
+func TestLoad_BackendsInStep(t *testing.T) {
+	postgres, err := Load(database.Postgres)
+	require.NoError(t, err)
+	sqlite, err := Load(database.Sqlite)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, postgres)
+	require.Len(t, sqlite, len(postgres))
+	for i := range postgres {
+		assert.Equal(t, postgres[i].Version, sqlite[i].Version)
+		assert.Equal(t, postgres[i].Name, sqlite[i].Name)
+	}
+}
+
+// TestParse_MissingDown Test a migration without its down script is rejected
+func TestParse_MissingDown(t *testing.T) {
+	_, err := parse(fstest.MapFS{
+		"0001_users.up.sql": {Data: []byte("CREATE TABLE users (id BIGINT)")},
+	})
+	assert.ErrorContains(t, err, "needs both an up and a down script")
+}
+
+// TestParse_UnexpectedFile Test files outside the naming scheme are rejected
+func TestParse_UnexpectedFile(t *testing.T) {
+	_, err := parse(fstest.MapFS{
+		"users.sql": {Data: []byte("CREATE TABLE users (id BIGINT)")},
+	})
+	assert.ErrorContains(t, err, "unexpected migration file")
+}
+
+// TestMigrator_Up Test only pending migrations are applied, under the advisory lock
+func TestMigrator_Up(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	migrations := testMigrations()
+	migrator := Migrator{db: sqlx.NewDb(db, "postgres"), dialect: dialects[database.Postgres], migrations: migrations}
+
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`).
+		WillReturnRows(appliedRows(migrations[0]))
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE agents`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations \(version,name,checksum\) VALUES \(\$1,\$2,\$3\)`).
+		WithArgs(int64(2), "agents", migrations[1].Checksum).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := migrator.Up(context.Background())
+	assert.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, int64(2), applied[0].Version)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigrator_Up_Fails Test a failing script is rolled back and stops the following ones
+func TestMigrator_Up_Fails(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	migrator := Migrator{db: sqlx.NewDb(db, "postgres"), dialect: dialects[database.Postgres], migrations: testMigrations()}
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, name, checksum, applied_at FROM schema_migrations`).WillReturnRows(appliedRows())
+	mock.ExpectQuery(`SELECT to_regclass\('users'\) IS NOT NULL`).WillReturnRows(sqlmock.NewRows([]string{"found"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE users`).WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := migrator.Up(context.Background())
+	assert.ErrorContains(t, err, "apply migration 1_users")
+	assert.Empty(t, applied)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigrator_Up_ChecksumMismatch Test nothing is applied once an applied script was edited
+func TestMigrator_Up_ChecksumMismatch(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	migrations := testMigrations()
+	migrator := Migrator{db: sqlx.NewDb(db, "postgres"), dialect: dialects[database.Postgres], migrations: migrations}
+
+	edited := migrations[0]
+	edited.Checksum = checksum("CREATE TABLE users (id INT)")
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, name, checksum, applied_at FROM schema_migrations`).WillReturnRows(appliedRows(edited))
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := migrator.Up(context.Background())
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.Empty(t, applied)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigrator_Up_Untracked Test a schema created without migrations is not migrated over
+func TestMigrator_Up_Untracked(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	migrator := Migrator{db: sqlx.NewDb(db, "postgres"), dialect: dialects[database.Postgres], migrations: testMigrations()}
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, name, checksum, applied_at FROM schema_migrations`).WillReturnRows(appliedRows())
+	mock.ExpectQuery(`SELECT to_regclass`).WillReturnRows(sqlmock.NewRows([]string{"found"}).AddRow(true))
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := migrator.Up(context.Background())
+	assert.ErrorIs(t, err, ErrUntracked)
+	assert.Empty(t, applied)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigrator_Baseline Test migrations up to the version are recorded without running
+func TestMigrator_Baseline(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	migrations := testMigrations()
+	migrator := Migrator{db: sqlx.NewDb(db, "postgres"), dialect: dialects[database.Postgres], migrations: migrations}
+
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, name, checksum, applied_at FROM schema_migrations`).WillReturnRows(appliedRows())
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO schema_migrations`).
+		WithArgs(int64(1), "users", migrations[0].Checksum).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	recorded, err := migrator.Baseline(context.Background(), 1)
+	assert.NoError(t, err)
+	require.Len(t, recorded, 1)
+	assert.Equal(t, "users", recorded[0].Name)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = migrator.Baseline(context.Background(), 9)
+	assert.ErrorIs(t, err, ErrNoSuchVersion)
+}
+
+// TestMigrator_BaselineOnSqlite Test a schema made by running the scripts by hand is upgraded after a baseline
+func TestMigrator_BaselineOnSqlite(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlx.Connect("sqlite", "file:"+filepath.Join(t.TempDir(), "goat.db")+"?_pragma=foreign_keys(1)")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrations, err := Load(database.Sqlite)
+	require.NoError(t, err)
+	for _, migration := range migrations {
+		_, err := db.ExecContext(ctx, migration.Up)
+		require.NoError(t, err)
+	}
+
+	migrator, err := NewMigrator(db, database.Sqlite)
+	require.NoError(t, err)
+
+	_, err = migrator.Up(ctx)
+	assert.ErrorIs(t, err, ErrUntracked)
+
+	recorded, err := migrator.Baseline(ctx, migrations[len(migrations)-1].Version)
+	require.NoError(t, err)
+	assert.Len(t, recorded, len(migrations))
+
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	_, err = migrator.Baseline(ctx, migrations[0].Version)
+	assert.ErrorIs(t, err, ErrAlreadyTracked)
+}
+
+// TestMigrator_BaselineInitialOnSqlite Test a schema of the initial scripts only gets every later migration after a baseline
+func TestMigrator_BaselineInitialOnSqlite(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlx.Connect("sqlite", "file:"+filepath.Join(t.TempDir(), "goat.db")+"?_pragma=foreign_keys(1)")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrations, err := Load(database.Sqlite)
+	require.NoError(t, err)
+	for _, migration := range migrations[:3] {
+		_, err := db.ExecContext(ctx, migration.Up)
+		require.NoError(t, err)
+	}
+
+	migrator, err := NewMigrator(db, database.Sqlite)
+	require.NoError(t, err)
+
+	_, err = migrator.Baseline(ctx, 3)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrations)-3)
+
+	var found bool
+	require.NoError(t, db.GetContext(ctx, &found,
+		`SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'device_telemetry_rollups')`))
+	assert.True(t, found)
+}
+
+// TestMigrator_DownAllOnSqlite Test every down script reverts its up script, so the schema can be rebuilt
+func TestMigrator_DownAllOnSqlite(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqlx.Connect("sqlite", "file:"+filepath.Join(t.TempDir(), "goat.db")+"?_pragma=foreign_keys(1)")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrator, err := NewMigrator(db, database.Sqlite)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+
+	reverted, err := migrator.Down(ctx, len(applied))
+	require.NoError(t, err)
+	assert.Len(t, reverted, len(applied))
+
+	reapplied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, reapplied, len(applied))
+}
+
+// TestMigrator_Down Test the newest applied migration is reverted and unrecorded
+func TestMigrator_Down(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	migrations := testMigrations()
+	migrator := Migrator{db: sqlx.NewDb(db, "sqlite"), dialect: dialects[database.Sqlite], migrations: migrations}
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, name, checksum, applied_at FROM schema_migrations`).
+		WillReturnRows(appliedRows(migrations...))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DROP TABLE agents`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM schema_migrations WHERE version = \?`).
+		WithArgs(int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	reverted, err := migrator.Down(context.Background(), 1)
+	assert.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, "agents", reverted[0].Name)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestMigrator_Status Test pending, applied and unknown versions are listed
+func TestMigrator_Status(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	migrations := testMigrations()
+	migrator := Migrator{db: sqlx.NewDb(db, "postgres"), dialect: dialects[database.Postgres], migrations: migrations}
+
+	unknown := Migration{Version: 3, Name: "devices", Checksum: checksum("CREATE TABLE devices (id BIGINT)")}
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, name, checksum, applied_at FROM schema_migrations`).
+		WillReturnRows(appliedRows(migrations[0], unknown))
+
+	statuses, err := migrator.Status(context.Background())
+	assert.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+	assert.True(t, statuses[2].Missing)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCreate Test new scripts take the next version for every backend
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	for _, backend := range backends {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, string(backend)), 0o755))
+		for _, direction := range []string{"up", "down"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, string(backend), "0001_users."+direction+".sql"), []byte("--"), 0o644))
+		}
+	}
+
+	files, err := Create(dir, "Add_Devices")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "postgres", "0002_add_devices.up.sql"),
+		filepath.Join(dir, "postgres", "0002_add_devices.down.sql"),
+		filepath.Join(dir, "sqlite", "0002_add_devices.up.sql"),
+		filepath.Join(dir, "sqlite", "0002_add_devices.down.sql"),
+	}, files)
+
+	_, err = Create(dir, "add devices")
+	assert.ErrorIs(t, err, ErrInvalidName)
+}
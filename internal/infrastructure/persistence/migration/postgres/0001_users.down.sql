@@ -0,0 +1,10 @@
+---- Drop Tables ----
+-- Indexes go with their tables
+
+DROP TABLE IF EXISTS goat.public.users_roles CASCADE;
+DROP TABLE IF EXISTS goat.public.roles CASCADE;
+DROP TABLE IF EXISTS goat.public.users CASCADE;
+
+---- Drop Types ----
+
+DROP TYPE IF EXISTS user_status;
@@ -0,0 +1,10 @@
+---- Drop Tables ----
+-- Indexes go with their tables
+
+DROP TABLE IF EXISTS goat.public.agents CASCADE;
+
+---- Drop Types ----
+
+DROP TYPE IF EXISTS agent_status;
+DROP TYPE IF EXISTS agent_types;
+DROP TYPE IF EXISTS agent_engine;
@@ -0,0 +1,14 @@
+---- Drop Tables ----
+-- Indexes go with their tables
+
+DROP TABLE IF EXISTS goat.public.chat_records CASCADE;
+DROP TABLE IF EXISTS goat.public.chat_group_members CASCADE;
+DROP TABLE IF EXISTS goat.public.participants CASCADE;
+DROP TABLE IF EXISTS goat.public.chat_groups CASCADE;
+
+---- Drop Types ----
+
+DROP TYPE IF EXISTS chat_group_type;
+DROP TYPE IF EXISTS participant_type;
+DROP TYPE IF EXISTS chat_message_type;
+DROP TYPE IF EXISTS chat_member_role;
@@ -0,0 +1,4 @@
+---- Drop Tables ----
+-- Indexes go with their tables
+
+DROP TABLE IF EXISTS goat.public.agent_models CASCADE;
@@ -0,0 +1,19 @@
+---- Tables ----
+
+-- Agent model catalog, synced from the configured providers
+CREATE TABLE IF NOT EXISTS goat.public.agent_models
+(
+    id             BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    provider       TEXT      NOT NULL,
+    name           TEXT      NOT NULL,
+    family         TEXT      NOT NULL DEFAULT '',
+    parameter_size TEXT      NOT NULL DEFAULT '',
+    quantization   TEXT      NOT NULL DEFAULT '',
+    size_bytes     BIGINT    NOT NULL DEFAULT 0,
+    digest         TEXT      NOT NULL DEFAULT '',
+    modified_at    TIMESTAMP,
+    is_available   BOOLEAN   NOT NULL DEFAULT TRUE,
+    synced_at      TIMESTAMP NOT NULL DEFAULT now(),
+    created_at     TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (provider, name)
+);
@@ -0,0 +1,18 @@
+---- Tables ----
+
+-- Agent configs, one immutable row per version
+CREATE TABLE IF NOT EXISTS goat.public.agent_configs
+(
+    id             BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    agent_id       BIGINT           NOT NULL REFERENCES agents (id) ON DELETE CASCADE,
+    version        INT              NOT NULL,
+    model_id       BIGINT           NOT NULL REFERENCES agent_models (id),
+    temperature    DOUBLE PRECISION NOT NULL DEFAULT 0.7,
+    max_tokens     INT              NOT NULL DEFAULT 0,
+    stop_sequences TEXT             NOT NULL DEFAULT '[]', -- JSON array
+    system_prompt  TEXT             NOT NULL DEFAULT '',
+    change_note    TEXT             NOT NULL DEFAULT '',
+    created_by     BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    created_at     TIMESTAMP        NOT NULL DEFAULT now(),
+    UNIQUE (agent_id, version)
+);
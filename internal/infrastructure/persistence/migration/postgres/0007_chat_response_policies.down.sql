@@ -0,0 +1,12 @@
+---- Drop Columns ----
+
+ALTER TABLE goat.public.chat_records
+    DROP COLUMN IF EXISTS agent_depth;
+
+ALTER TABLE goat.public.chat_group_members
+    DROP COLUMN IF EXISTS keywords,
+    DROP COLUMN IF EXISTS response_policy;
+
+---- Drop Types ----
+
+DROP TYPE IF EXISTS chat_response_policy;
@@ -0,0 +1,14 @@
+---- Types ----
+
+CREATE TYPE chat_response_policy AS ENUM ('ALWAYS', 'MENTION', 'KEYWORD');
+
+---- Columns ----
+
+-- When an agent member replies, ignored for users
+ALTER TABLE goat.public.chat_group_members
+    ADD COLUMN IF NOT EXISTS response_policy chat_response_policy NOT NULL DEFAULT 'MENTION',
+    ADD COLUMN IF NOT EXISTS keywords        TEXT                 NOT NULL DEFAULT '[]'; -- JSON array
+
+-- Agent replies chained since the last human message
+ALTER TABLE goat.public.chat_records
+    ADD COLUMN IF NOT EXISTS agent_depth INTEGER NOT NULL DEFAULT 0;
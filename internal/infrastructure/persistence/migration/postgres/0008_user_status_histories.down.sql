@@ -0,0 +1,5 @@
+---- Drop Tables ----
+-- Indexes go with their tables
+-- Postgres can't drop an enum value, 'rejected' stays in user_status
+
+DROP TABLE IF EXISTS goat.public.user_status_histories CASCADE;
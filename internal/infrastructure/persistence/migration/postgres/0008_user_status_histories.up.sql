@@ -0,0 +1,20 @@
+---- Types ----
+
+-- Registrations an admin turned down
+ALTER TYPE user_status ADD VALUE IF NOT EXISTS 'rejected' BEFORE 'deleted';
+
+---- Tables ----
+
+-- User status transitions, written on every approve, reject, ban and unban
+CREATE TABLE IF NOT EXISTS goat.public.user_status_histories
+(
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id     BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    from_status user_status NOT NULL,
+    to_status   user_status NOT NULL,
+    reason      TEXT        NOT NULL DEFAULT '',
+    actor_id    BIGINT REFERENCES users (id) ON DELETE SET NULL, -- NULL = system
+    created_at  TIMESTAMP   NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_user_status_histories_user ON user_status_histories (user_id, created_at DESC);
@@ -0,0 +1,4 @@
+---- Drop Columns ----
+
+ALTER TABLE goat.public.users
+    DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,5 @@
+---- Columns ----
+
+-- Set once the user confirmed the email address
+ALTER TABLE goat.public.users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
@@ -0,0 +1,5 @@
+---- Drop Tables ----
+-- Indexes go with their tables
+
+DROP TABLE IF EXISTS goat.public.role_permissions CASCADE;
+DROP TABLE IF EXISTS goat.public.permissions CASCADE;
@@ -0,0 +1,18 @@
+---- Tables ----
+
+-- Permissions Table, names are "<resource>:<action>"
+CREATE TABLE IF NOT EXISTS goat.public.permissions
+(
+    name        TEXT PRIMARY KEY,
+    description TEXT      NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- Role Permissions Table
+CREATE TABLE IF NOT EXISTS goat.public.role_permissions
+(
+    role_id    BIGINT REFERENCES roles (id) ON DELETE CASCADE,
+    permission TEXT REFERENCES permissions (name) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (role_id, permission)
+);
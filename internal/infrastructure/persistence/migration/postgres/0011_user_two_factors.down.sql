@@ -0,0 +1,10 @@
+---- Drop Tables ----
+-- Indexes go with their tables
+
+DROP TABLE IF EXISTS goat.public.user_recovery_codes CASCADE;
+DROP TABLE IF EXISTS goat.public.user_two_factors CASCADE;
+
+---- Drop Columns ----
+
+ALTER TABLE goat.public.roles
+    DROP COLUMN IF EXISTS require_two_factor;
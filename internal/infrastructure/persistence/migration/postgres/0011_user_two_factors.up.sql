@@ -0,0 +1,29 @@
+---- Columns ----
+
+-- Members without 2FA lose the role permissions
+ALTER TABLE goat.public.roles
+    ADD COLUMN IF NOT EXISTS require_two_factor BOOLEAN NOT NULL DEFAULT false;
+
+---- Tables ----
+
+-- TOTP enrollments, enabled_at NULL = waiting for the first code
+CREATE TABLE IF NOT EXISTS goat.public.user_two_factors
+(
+    user_id        BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret         TEXT      NOT NULL,
+    enabled_at     TIMESTAMP,
+    last_used_step BIGINT    NOT NULL DEFAULT 0, -- rejects replayed codes
+    created_at     TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- One-time recovery codes, stored hashed
+CREATE TABLE IF NOT EXISTS goat.public.user_recovery_codes
+(
+    id        BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id   BIGINT NOT NULL REFERENCES user_two_factors (user_id) ON DELETE CASCADE,
+    code_hash TEXT   NOT NULL,
+    used_at   TIMESTAMP
+);
+
+CREATE INDEX idx_user_recovery_codes_user ON user_recovery_codes (user_id);
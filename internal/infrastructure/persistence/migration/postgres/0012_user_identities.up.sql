@@ -0,0 +1,14 @@
+---- Tables ----
+
+-- External OpenID Connect accounts linked to users, one per provider and user
+CREATE TABLE IF NOT EXISTS goat.public.user_identities
+(
+    id         BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id    BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider   TEXT      NOT NULL,
+    subject    TEXT      NOT NULL, -- account ID at the provider, the "sub" claim
+    email      TEXT      NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
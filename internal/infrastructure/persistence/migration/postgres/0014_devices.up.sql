@@ -0,0 +1,17 @@
+---- Tables ----
+
+-- Client installations of users, device_id is chosen by the client and unique per user
+CREATE TABLE IF NOT EXISTS goat.public.devices
+(
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id     BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    device_id   TEXT      NOT NULL,
+    name        TEXT      NOT NULL,
+    platform    TEXT      NOT NULL, -- ios, android, web, desktop, embedded
+    session_id  TEXT      NOT NULL, -- login session that registered the device last
+    push_token  TEXT UNIQUE,        -- FCM or APNs registration, NULL when the device takes no pushes
+    secret_hash TEXT UNIQUE,        -- HMAC of the secret headless devices connect with
+    created_at  TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (user_id, device_id)
+);
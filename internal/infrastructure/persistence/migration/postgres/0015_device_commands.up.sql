@@ -0,0 +1,20 @@
+---- Tables ----
+
+-- Commands users send to their headless devices, kept after completion as a log
+CREATE TABLE IF NOT EXISTS goat.public.device_commands
+(
+    id           BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    device_id    BIGINT    NOT NULL REFERENCES devices (id) ON DELETE CASCADE,
+    user_id      BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    command_type TEXT      NOT NULL,                    -- e.g. "reboot", "gpio.write"
+    payload      TEXT      NOT NULL DEFAULT '{}',       -- JSON object
+    status       TEXT      NOT NULL DEFAULT 'pending',  -- pending, delivered, succeeded, failed, expired
+    result       TEXT,                                  -- JSON value the device acknowledged with
+    error        TEXT      NOT NULL DEFAULT '',
+    expires_at   TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at   TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_device_commands_device_status ON device_commands (device_id, status);
@@ -0,0 +1,5 @@
+---- Drop Tables ----
+-- Indexes go with their tables
+
+DROP TABLE IF EXISTS goat.public.device_telemetry_rollups CASCADE;
+DROP TABLE IF EXISTS goat.public.device_telemetry CASCADE;
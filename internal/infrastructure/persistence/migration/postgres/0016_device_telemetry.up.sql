@@ -0,0 +1,28 @@
+---- Tables ----
+
+-- Raw telemetry of devices, rolled up into device_telemetry_rollups once past the raw retention
+CREATE TABLE IF NOT EXISTS goat.public.device_telemetry
+(
+    device_id   BIGINT           NOT NULL REFERENCES devices (id) ON DELETE CASCADE,
+    metric      TEXT             NOT NULL, -- e.g. "temperature", "cpu", "custom.fan_rpm"
+    value       DOUBLE PRECISION NOT NULL,
+    recorded_at TIMESTAMP        NOT NULL
+);
+
+CREATE INDEX idx_device_telemetry_device_time ON device_telemetry (device_id, recorded_at);
+CREATE INDEX idx_device_telemetry_time ON device_telemetry (recorded_at);
+
+-- Downsampled telemetry, one row per metric and bucket of the rollup step
+CREATE TABLE IF NOT EXISTS goat.public.device_telemetry_rollups
+(
+    device_id    BIGINT           NOT NULL REFERENCES devices (id) ON DELETE CASCADE,
+    metric       TEXT             NOT NULL,
+    bucket_start TIMESTAMP        NOT NULL,
+    sample_count BIGINT           NOT NULL,
+    value_sum    DOUBLE PRECISION NOT NULL,
+    value_min    DOUBLE PRECISION NOT NULL,
+    value_max    DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (device_id, metric, bucket_start)
+);
+
+CREATE INDEX idx_device_telemetry_rollups_bucket ON device_telemetry_rollups (bucket_start);
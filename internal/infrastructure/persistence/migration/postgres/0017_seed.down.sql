@@ -0,0 +1,9 @@
+---- Remove Seeds ----
+-- Role permissions go with their roles and permissions
+
+DELETE FROM goat.public.permissions
+WHERE name IN ('agent:manage', 'agent:view_usage', 'chat:create_channel',
+               'user:approve', 'user:ban', 'user:unlock', 'user:manage_roles');
+
+DELETE FROM goat.public.roles
+WHERE type IN ('admin', 'vendor', 'user', 'guest');
@@ -0,0 +1,36 @@
+---- Seeds ----
+-- Run after the table scripts. Every statement is idempotent.
+
+-- Roles
+INSERT INTO goat.public.roles (type)
+VALUES ('admin'),
+       ('vendor'),
+       ('user'),
+       ('guest')
+ON CONFLICT (type) DO NOTHING;
+
+-- Permissions, keep in sync with permission.All
+INSERT INTO goat.public.permissions (name, description)
+VALUES ('agent:manage', 'Sync the model catalog, create agents and change their configs'),
+       ('agent:view_usage', 'Read the agent usage of any user'),
+       ('chat:create_channel', 'Create chat channels'),
+       ('user:approve', 'Review, approve and reject registrations'),
+       ('user:ban', 'Ban and unban users'),
+       ('user:unlock', 'Lift login lockouts'),
+       ('user:manage_roles', 'Assign and revoke user roles')
+ON CONFLICT (name) DO NOTHING;
+
+-- Role permissions, keep in sync with permission.DefaultGrants
+INSERT INTO goat.public.role_permissions (role_id, permission)
+SELECT r.id, g.permission
+FROM (VALUES ('admin', 'agent:manage'),
+             ('admin', 'agent:view_usage'),
+             ('admin', 'chat:create_channel'),
+             ('admin', 'user:approve'),
+             ('admin', 'user:ban'),
+             ('admin', 'user:unlock'),
+             ('admin', 'user:manage_roles'),
+             ('vendor', 'chat:create_channel'),
+             ('user', 'chat:create_channel')) AS g (role, permission)
+         JOIN goat.public.roles r ON r.type = g.role
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,161 @@
+package migration
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+)
+
+// SourceDir where the migration files live, relative to the project root
+const SourceDir = "internal/infrastructure/persistence/migration"
+
+//go:embed postgres/*.sql sqlite/*.sql
+var sources embed.FS
+
+// backends every backend keeps its own copy of each migration under the same version
+var backends = []database.DBName{database.Postgres, database.Sqlite}
+
+// fileName matches "0001_users.up.sql" and "0001_users.down.sql"
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+var ErrInvalidName = errors.New("migration name must be lower case letters, digits and underscores")
+
+// Migration one versioned schema change, Checksum is taken over the up script
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Load returns the embedded migrations of a backend ordered by version
+func Load(backend database.DBName) ([]Migration, error) {
+	dir, err := fs.Sub(sources, string(backend))
+	if err != nil {
+		return nil, fmt.Errorf("open %s migrations: %w", backend, err)
+	}
+	return parse(dir)
+}
+
+// parse reads the up and down scripts of a directory, every version needs both
+func parse(dir fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+
+		content, err := fs.ReadFile(dir, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %q: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down script", m.Version, m.Name)
+		}
+		m.Checksum = checksum(m.Up)
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func checksum(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create writes empty up and down scripts of the next version for every backend under dir,
+// and returns the created paths. They are embedded with the next build.
+func Create(dir, name string) ([]string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !fileName.MatchString("1_" + name + ".up.sql") {
+		return nil, ErrInvalidName
+	}
+
+	// Versions stay in step across backends
+	var version int64
+	for _, backend := range backends {
+		migrations, err := parse(os.DirFS(filepath.Join(dir, string(backend))))
+		if err != nil {
+			return nil, err
+		}
+		if n := len(migrations); n > 0 {
+			version = max(version, migrations[n-1].Version)
+		}
+	}
+	version++
+
+	var created []string
+	for _, backend := range backends {
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dir, string(backend), fmt.Sprintf("%04d_%s.%s.sql", version, name, direction))
+			if err := writeNew(file, fmt.Sprintf("-- %s %s\n", name, direction)); err != nil {
+				return created, err
+			}
+			created = append(created, file)
+		}
+	}
+
+	return created, nil
+}
+
+// writeNew writes a file that must not exist yet
+func writeNew(file, content string) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("create migration: %w", err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write migration: %w", err)
+	}
+	return f.Close()
+}
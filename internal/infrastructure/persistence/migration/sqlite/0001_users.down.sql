@@ -0,0 +1,6 @@
+---- Drop Tables ----
+-- Indexes go with their tables, children before parents as SQLite has no CASCADE
+
+DROP TABLE IF EXISTS users_roles;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS users;
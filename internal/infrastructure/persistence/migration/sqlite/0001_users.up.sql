@@ -0,0 +1,35 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- Users Table, 'rejected' is allowed from the start as SQLite can't change a CHECK later
+CREATE TABLE IF NOT EXISTS users
+(
+    id                INTEGER PRIMARY KEY AUTOINCREMENT,
+    name              TEXT        NOT NULL,
+    email             TEXT        NOT NULL UNIQUE,
+    password          TEXT        NOT NULL,
+    user_status       TEXT        NOT NULL CHECK (user_status IN ('active', 'inactive', 'banned', 'applying', 'rejected', 'deleted')),
+    user_ip           TEXT        NOT NULL,
+    created_at        TIMESTAMP   NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at        TIMESTAMP   NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+-- Roles Table
+CREATE TABLE IF NOT EXISTS roles
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    type       TEXT      NOT NULL UNIQUE, -- 'admin', 'vendor', 'user', 'guest'
+    creator    BIGINT REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+-- Roles Users Table
+CREATE TABLE IF NOT EXISTS users_roles
+(
+    user_id    BIGINT REFERENCES users (id) ON DELETE CASCADE,
+    role_id    BIGINT REFERENCES roles (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (user_id, role_id)
+);
@@ -0,0 +1,17 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- Agents Table
+CREATE TABLE IF NOT EXISTS agents
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       TEXT         NOT NULL,
+    type       TEXT         NOT NULL CHECK (type IN ('local', 'remote', 'hybrid')),
+    status     TEXT         NOT NULL DEFAULT 'maintaining' CHECK (status IN ('available', 'maintaining', 'discontinued', 'error')),
+    engine     TEXT         NOT NULL CHECK (engine IN ('gguf', 'onnx', 'api', 'cloud', 'mlc', 'webGPU')),
+    created_at TIMESTAMP    NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    created_by BIGINT REFERENCES users (id) ON DELETE CASCADE,
+    updated_at TIMESTAMP    NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_by BIGINT REFERENCES users (id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+---- Drop Tables ----
+-- Indexes go with their tables, children before parents as SQLite has no CASCADE
+
+DROP TABLE IF EXISTS chat_records;
+DROP TABLE IF EXISTS chat_group_members;
+DROP TABLE IF EXISTS participants;
+DROP TABLE IF EXISTS chat_groups;
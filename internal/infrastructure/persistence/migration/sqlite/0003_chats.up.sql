@@ -0,0 +1,82 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- Chat groups
+CREATE TABLE IF NOT EXISTS chat_groups
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        TEXT,
+    description TEXT,
+    avatar_url  TEXT,
+    type        TEXT            NOT NULL DEFAULT 'GROUP' CHECK (type IN ('DIRECT', 'GROUP', 'CHANNEL', 'BOT')),
+    max_members INTEGER         NOT NULL DEFAULT 2,
+    is_deleted  BOOLEAN         NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMP       NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at  TIMESTAMP       NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    created_by  BIGINT          REFERENCES users (id) ON DELETE SET NULL
+);
+
+-- Participants of chat groups
+CREATE TABLE IF NOT EXISTS participants
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    type         TEXT             NOT NULL CHECK (type IN ('USER', 'AGENT', 'SYSTEM')),
+    user_id      BIGINT REFERENCES users (id) ON DELETE CASCADE,
+    agent_id     BIGINT REFERENCES agents (id) ON DELETE CASCADE,
+    display_name TEXT,
+    avatar_url   TEXT,
+    created_at   TIMESTAMP        NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+
+    -- NOTE: uniqueness is enforced by the partial indexes below (UNIQUE constraint
+    -- with NULLs would not prevent duplicate USER/AGENT/SYSTEM rows)
+    CONSTRAINT chk_participant CHECK (
+        (type = 'USER' AND user_id IS NOT NULL AND agent_id IS NULL) OR
+        (type = 'AGENT' AND agent_id IS NOT NULL AND user_id IS NULL) OR
+        (type = 'SYSTEM' AND user_id IS NULL AND agent_id IS NULL)
+        )
+);
+
+-- Add indexes for searching by participant with type
+CREATE UNIQUE INDEX idx_participants_user ON participants (user_id) WHERE type = 'USER';
+CREATE UNIQUE INDEX idx_participants_agent ON participants (agent_id) WHERE type = 'AGENT';
+CREATE UNIQUE INDEX idx_participants_system ON participants (type) WHERE type = 'SYSTEM';
+
+-- Chat group members
+CREATE TABLE IF NOT EXISTS chat_group_members
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id        BIGINT               NOT NULL REFERENCES chat_groups (id) ON DELETE CASCADE,
+    participant_id  BIGINT               NOT NULL REFERENCES participants (id) ON DELETE CASCADE,
+    role            TEXT                 NOT NULL DEFAULT 'MEMBER' CHECK (role IN ('OWNER', 'ADMIN', 'MEMBER', 'GUEST')),
+    joined_at       TIMESTAMP            NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    is_archived     BOOLEAN              NOT NULL DEFAULT FALSE,
+    is_muted        BOOLEAN              NOT NULL DEFAULT FALSE,
+    is_pinned       BOOLEAN              NOT NULL DEFAULT FALSE,
+    last_read_at    TIMESTAMP,
+    updated_at      TIMESTAMP            NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+
+    UNIQUE (group_id, participant_id)
+);
+
+-- Add indexes for searching by participant
+CREATE INDEX idx_chat_group_members_participant ON chat_group_members (participant_id);
+
+-- Chat records
+CREATE TABLE IF NOT EXISTS chat_records
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id     BIGINT            NOT NULL REFERENCES chat_groups (id) ON DELETE CASCADE,
+    sender_id    BIGINT            NOT NULL REFERENCES participants (id) ON DELETE CASCADE,
+    content      TEXT              NOT NULL,
+    message_type TEXT              NOT NULL DEFAULT 'TEXT' CHECK (message_type IN ('TEXT', 'IMAGE', 'FILE', 'SYSTEM')),
+    reply_to_id  BIGINT            REFERENCES chat_records (id) ON DELETE SET NULL,
+    is_edited    BOOLEAN           NOT NULL DEFAULT FALSE,
+    is_deleted   BOOLEAN           NOT NULL DEFAULT FALSE,
+    created_at   TIMESTAMP         NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at   TIMESTAMP         NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX idx_chat_records_group_id ON chat_records (group_id);
+CREATE INDEX idx_chat_records_group_created ON chat_records (group_id, created_at DESC);
+CREATE INDEX idx_chat_records_sender ON chat_records (sender_id);
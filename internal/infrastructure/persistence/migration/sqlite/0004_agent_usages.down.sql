@@ -0,0 +1,4 @@
+---- Drop Tables ----
+-- Indexes go with their tables, children before parents as SQLite has no CASCADE
+
+DROP TABLE IF EXISTS agent_usages;
@@ -0,0 +1,19 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- Agent usage, one row per user, agent and UTC day
+CREATE TABLE IF NOT EXISTS agent_usages
+(
+    user_id           BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    agent_id          BIGINT    NOT NULL REFERENCES agents (id) ON DELETE CASCADE,
+    usage_date        DATE      NOT NULL,
+    prompt_tokens     BIGINT    NOT NULL DEFAULT 0,
+    completion_tokens BIGINT    NOT NULL DEFAULT 0,
+    request_count     BIGINT    NOT NULL DEFAULT 0,
+    total_latency_ms  BIGINT    NOT NULL DEFAULT 0,
+    updated_at        TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (user_id, agent_id, usage_date)
+);
+
+CREATE INDEX idx_agent_usages_user_date ON agent_usages (user_id, usage_date);
@@ -0,0 +1,21 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- Agent model catalog, synced from the configured providers
+CREATE TABLE IF NOT EXISTS agent_models
+(
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider       TEXT      NOT NULL,
+    name           TEXT      NOT NULL,
+    family         TEXT      NOT NULL DEFAULT '',
+    parameter_size TEXT      NOT NULL DEFAULT '',
+    quantization   TEXT      NOT NULL DEFAULT '',
+    size_bytes     BIGINT    NOT NULL DEFAULT 0,
+    digest         TEXT      NOT NULL DEFAULT '',
+    modified_at    TIMESTAMP,
+    is_available   BOOLEAN   NOT NULL DEFAULT TRUE,
+    synced_at      TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    created_at     TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    UNIQUE (provider, name)
+);
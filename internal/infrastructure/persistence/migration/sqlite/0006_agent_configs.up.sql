@@ -0,0 +1,20 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- Agent configs, one immutable row per version
+CREATE TABLE IF NOT EXISTS agent_configs
+(
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    agent_id       BIGINT           NOT NULL REFERENCES agents (id) ON DELETE CASCADE,
+    version        INT              NOT NULL,
+    model_id       BIGINT           NOT NULL REFERENCES agent_models (id),
+    temperature    REAL             NOT NULL DEFAULT 0.7,
+    max_tokens     INT              NOT NULL DEFAULT 0,
+    stop_sequences TEXT             NOT NULL DEFAULT '[]', -- JSON array
+    system_prompt  TEXT             NOT NULL DEFAULT '',
+    change_note    TEXT             NOT NULL DEFAULT '',
+    created_by     BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    created_at     TIMESTAMP        NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    UNIQUE (agent_id, version)
+);
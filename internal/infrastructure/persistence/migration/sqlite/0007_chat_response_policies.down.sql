@@ -0,0 +1,5 @@
+---- Drop Columns ----
+
+ALTER TABLE chat_records DROP COLUMN agent_depth;
+ALTER TABLE chat_group_members DROP COLUMN keywords;
+ALTER TABLE chat_group_members DROP COLUMN response_policy;
@@ -0,0 +1,12 @@
+---- Columns ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK
+
+-- When an agent member replies, ignored for users
+ALTER TABLE chat_group_members
+    ADD COLUMN response_policy TEXT NOT NULL DEFAULT 'MENTION' CHECK (response_policy IN ('ALWAYS', 'MENTION', 'KEYWORD'));
+ALTER TABLE chat_group_members
+    ADD COLUMN keywords TEXT NOT NULL DEFAULT '[]'; -- JSON array
+
+-- Agent replies chained since the last human message
+ALTER TABLE chat_records
+    ADD COLUMN agent_depth INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,18 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+-- users.user_status accepts 'rejected' since 0001.
+
+-- User status transitions, written on every approve, reject, ban and unban
+CREATE TABLE IF NOT EXISTS user_status_histories
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id     BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    from_status TEXT        NOT NULL CHECK (from_status IN ('active', 'inactive', 'banned', 'applying', 'rejected', 'deleted')),
+    to_status   TEXT        NOT NULL CHECK (to_status IN ('active', 'inactive', 'banned', 'applying', 'rejected', 'deleted')),
+    reason      TEXT        NOT NULL DEFAULT '',
+    actor_id    BIGINT REFERENCES users (id) ON DELETE SET NULL, -- NULL = system
+    created_at  TIMESTAMP   NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX idx_user_status_histories_user ON user_status_histories (user_id, created_at DESC);
@@ -0,0 +1,3 @@
+---- Drop Columns ----
+
+ALTER TABLE users DROP COLUMN email_verified_at;
@@ -0,0 +1,5 @@
+---- Columns ----
+
+-- Set once the user confirmed the email address
+ALTER TABLE users
+    ADD COLUMN email_verified_at TIMESTAMP;
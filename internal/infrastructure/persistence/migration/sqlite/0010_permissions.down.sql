@@ -0,0 +1,5 @@
+---- Drop Tables ----
+-- Indexes go with their tables, children before parents as SQLite has no CASCADE
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
@@ -0,0 +1,20 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- Permissions Table, names are "<resource>:<action>"
+CREATE TABLE IF NOT EXISTS permissions
+(
+    name        TEXT PRIMARY KEY,
+    description TEXT      NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+-- Role Permissions Table
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role_id    BIGINT REFERENCES roles (id) ON DELETE CASCADE,
+    permission TEXT REFERENCES permissions (name) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (role_id, permission)
+);
@@ -0,0 +1,9 @@
+---- Drop Tables ----
+-- Indexes go with their tables, children before parents as SQLite has no CASCADE
+
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_two_factors;
+
+---- Drop Columns ----
+
+ALTER TABLE roles DROP COLUMN require_two_factor;
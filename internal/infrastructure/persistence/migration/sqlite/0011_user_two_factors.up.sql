@@ -0,0 +1,31 @@
+---- Columns ----
+
+-- Members without 2FA lose the role permissions
+ALTER TABLE roles
+    ADD COLUMN require_two_factor BOOLEAN NOT NULL DEFAULT false;
+
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- TOTP enrollments, enabled_at NULL = waiting for the first code
+CREATE TABLE IF NOT EXISTS user_two_factors
+(
+    user_id        BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret         TEXT      NOT NULL,
+    enabled_at     TIMESTAMP,
+    last_used_step BIGINT    NOT NULL DEFAULT 0, -- rejects replayed codes
+    created_at     TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at     TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+-- One-time recovery codes, stored hashed
+CREATE TABLE IF NOT EXISTS user_recovery_codes
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id   BIGINT NOT NULL REFERENCES user_two_factors (user_id) ON DELETE CASCADE,
+    code_hash TEXT   NOT NULL,
+    used_at   TIMESTAMP
+);
+
+CREATE INDEX idx_user_recovery_codes_user ON user_recovery_codes (user_id);
@@ -0,0 +1,16 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- External OpenID Connect accounts linked to users, one per provider and user
+CREATE TABLE IF NOT EXISTS user_identities
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider   TEXT      NOT NULL,
+    subject    TEXT      NOT NULL, -- account ID at the provider, the "sub" claim
+    email      TEXT      NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider)
+);
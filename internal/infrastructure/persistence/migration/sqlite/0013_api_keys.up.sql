@@ -0,0 +1,21 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- Personal access tokens for scripts and bots, only the HMAC of the key is stored
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id      BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         TEXT      NOT NULL,
+    prefix       TEXT      NOT NULL, -- first characters of the key, shown to tell keys apart
+    key_hash     TEXT      NOT NULL UNIQUE,
+    scopes       TEXT      NOT NULL, -- space separated, e.g. "user:read chat:write"
+    expires_at   TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    last_used_ip TEXT,
+    revoked_at   TIMESTAMP,
+    created_at   TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX idx_api_keys_user ON api_keys (user_id);
@@ -0,0 +1,22 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- Commands users send to their headless devices, kept after completion as a log
+CREATE TABLE IF NOT EXISTS device_commands
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id    BIGINT    NOT NULL REFERENCES devices (id) ON DELETE CASCADE,
+    user_id      BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    command_type TEXT      NOT NULL,                    -- e.g. "reboot", "gpio.write"
+    payload      TEXT      NOT NULL DEFAULT '{}',       -- JSON object
+    status       TEXT      NOT NULL DEFAULT 'pending',  -- pending, delivered, succeeded, failed, expired
+    result       TEXT,                                  -- JSON value the device acknowledged with
+    error        TEXT      NOT NULL DEFAULT '',
+    expires_at   TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at   TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX idx_device_commands_device_status ON device_commands (device_id, status);
@@ -0,0 +1,5 @@
+---- Drop Tables ----
+-- Indexes go with their tables, children before parents as SQLite has no CASCADE
+
+DROP TABLE IF EXISTS device_telemetry_rollups;
+DROP TABLE IF EXISTS device_telemetry;
@@ -0,0 +1,30 @@
+---- Tables ----
+-- SQLite port of the postgres migration: enums are TEXT with CHECK, times are UTC text
+-- in the layout the driver writes, run with foreign_keys on.
+
+-- Raw telemetry of devices, rolled up into device_telemetry_rollups once past the raw retention
+CREATE TABLE IF NOT EXISTS device_telemetry
+(
+    device_id   BIGINT           NOT NULL REFERENCES devices (id) ON DELETE CASCADE,
+    metric      TEXT             NOT NULL, -- e.g. "temperature", "cpu", "custom.fan_rpm"
+    value       REAL             NOT NULL,
+    recorded_at TIMESTAMP        NOT NULL
+);
+
+CREATE INDEX idx_device_telemetry_device_time ON device_telemetry (device_id, recorded_at);
+CREATE INDEX idx_device_telemetry_time ON device_telemetry (recorded_at);
+
+-- Downsampled telemetry, one row per metric and bucket of the rollup step
+CREATE TABLE IF NOT EXISTS device_telemetry_rollups
+(
+    device_id    BIGINT           NOT NULL REFERENCES devices (id) ON DELETE CASCADE,
+    metric       TEXT             NOT NULL,
+    bucket_start TIMESTAMP        NOT NULL,
+    sample_count BIGINT           NOT NULL,
+    value_sum    REAL             NOT NULL,
+    value_min    REAL             NOT NULL,
+    value_max    REAL             NOT NULL,
+    PRIMARY KEY (device_id, metric, bucket_start)
+);
+
+CREATE INDEX idx_device_telemetry_rollups_bucket ON device_telemetry_rollups (bucket_start);
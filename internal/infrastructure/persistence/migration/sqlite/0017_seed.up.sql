@@ -0,0 +1,34 @@
+---- Seeds ----
+-- Run after the table scripts. Every statement is idempotent.
+
+-- Roles
+INSERT OR IGNORE INTO roles (type)
+VALUES ('admin'),
+       ('vendor'),
+       ('user'),
+       ('guest');
+
+-- Permissions, keep in sync with permission.All
+INSERT OR IGNORE INTO permissions (name, description)
+VALUES ('agent:manage', 'Sync the model catalog, create agents and change their configs'),
+       ('agent:view_usage', 'Read the agent usage of any user'),
+       ('chat:create_channel', 'Create chat channels'),
+       ('user:approve', 'Review, approve and reject registrations'),
+       ('user:ban', 'Ban and unban users'),
+       ('user:unlock', 'Lift login lockouts'),
+       ('user:manage_roles', 'Assign and revoke user roles');
+
+-- Role permissions, keep in sync with permission.DefaultGrants
+WITH g (role, permission) AS (VALUES ('admin', 'agent:manage'),
+                                     ('admin', 'agent:view_usage'),
+                                     ('admin', 'chat:create_channel'),
+                                     ('admin', 'user:approve'),
+                                     ('admin', 'user:ban'),
+                                     ('admin', 'user:unlock'),
+                                     ('admin', 'user:manage_roles'),
+                                     ('vendor', 'chat:create_channel'),
+                                     ('user', 'chat:create_channel'))
+INSERT OR IGNORE INTO role_permissions (role_id, permission)
+SELECT r.id, g.permission
+FROM g
+         JOIN roles r ON r.type = g.role;
@@ -28,3 +28,8 @@ func (a AgentRepository) Create(ctx context.Context, agent *agent.Agent) error {
 	//TODO implement me
 	panic("implement me")
 }
+
+func (a AgentRepository) FindByID(ctx context.Context, id agent.ID) (*agent.Agent, error) {
+	//TODO implement me
+	panic("implement me")
+}
@@ -0,0 +1,20 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/transaction"
+)
+
+// TxManager runs the steps without a transaction, for use cases on mock repositories
+type TxManager struct{}
+
+func MockTxManager() *TxManager {
+	return &TxManager{}
+}
+
+var _ transaction.Manager = (*TxManager)(nil)
+
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
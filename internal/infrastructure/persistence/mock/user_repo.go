@@ -33,3 +33,8 @@ func (u *UserRepo) Update(ctx context.Context, user *user.User) error {
 	//TODO implement me
 	panic("implement me")
 }
+
+func (u *UserRepo) FindByStatus(ctx context.Context, status user.Status) ([]*user.User, error) {
+	//TODO implement me
+	panic("implement me")
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+func toConfigDomain(rec *AgentConfigRecord) (*agent.Config, error) {
+	var stops []string
+	if rec.StopSequences != "" {
+		if err := json.Unmarshal([]byte(rec.StopSequences), &stops); err != nil {
+			return nil, fmt.Errorf("decode stop sequences: %w", err)
+		}
+	}
+
+	var createdBy user.ID
+	if rec.CreatedBy != nil {
+		createdBy = *rec.CreatedBy
+	}
+
+	return &agent.Config{
+		ID:      rec.ID,
+		AgentID: rec.AgentID,
+		Version: rec.Version,
+		ModelID: rec.ModelID,
+		Parameters: agent.Parameters{
+			Temperature:   rec.Temperature,
+			MaxTokens:     rec.MaxTokens,
+			StopSequences: stops,
+			SystemPrompt:  rec.SystemPrompt,
+		},
+		ChangeNote: rec.ChangeNote,
+		CreatedBy:  createdBy,
+		CreatedAt:  rec.CreatedAt,
+	}, nil
+}
+
+func toConfigRecord(c *agent.Config) (*AgentConfigRecord, error) {
+	stops := c.Parameters.StopSequences
+	if stops == nil {
+		stops = []string{}
+	}
+	b, err := json.Marshal(stops)
+	if err != nil {
+		return nil, fmt.Errorf("encode stop sequences: %w", err)
+	}
+
+	var createdBy *user.ID
+	if c.CreatedBy != 0 {
+		createdBy = &c.CreatedBy
+	}
+
+	return &AgentConfigRecord{
+		ID:            c.ID,
+		AgentID:       c.AgentID,
+		Version:       c.Version,
+		ModelID:       c.ModelID,
+		Temperature:   c.Parameters.Temperature,
+		MaxTokens:     c.Parameters.MaxTokens,
+		StopSequences: string(b),
+		SystemPrompt:  c.Parameters.SystemPrompt,
+		ChangeNote:    c.ChangeNote,
+		CreatedBy:     createdBy,
+		CreatedAt:     c.CreatedAt,
+	}, nil
+}
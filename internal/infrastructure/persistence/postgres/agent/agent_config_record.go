@@ -0,0 +1,23 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type AgentConfigRecord struct {
+	ID            agent.ConfigID `db:"id"`
+	AgentID       agent.ID       `db:"agent_id"`
+	Version       int            `db:"version"`
+	ModelID       agentmodel.ID  `db:"model_id"`
+	Temperature   float64        `db:"temperature"`
+	MaxTokens     int            `db:"max_tokens"`
+	StopSequences string         `db:"stop_sequences"` // JSON array
+	SystemPrompt  string         `db:"system_prompt"`
+	ChangeNote    string         `db:"change_note"`
+	CreatedBy     *user.ID       `db:"created_by"`
+	CreatedAt     time.Time      `db:"created_at"`
+}
@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var ConfigTable = postgres.Table{
+	Name: "public.agent_configs",
+	Columns: []string{
+		"id",
+		"agent_id",
+		"version",
+		"model_id",
+		"temperature",
+		"max_tokens",
+		"stop_sequences",
+		"system_prompt",
+		"change_note",
+		"created_by",
+		"created_at",
+	},
+}
+
+type AgentConfigRepository struct {
+	db *sqlx.DB
+}
+
+var _ agent.ConfigRepository = (*AgentConfigRepository)(nil)
+
+func NewAgentConfigRepository(db *sqlx.DB) *AgentConfigRepository {
+	return &AgentConfigRepository{db: db}
+}
+
+// FindCurrent returns the latest config version of the agent.
+func (r AgentConfigRepository) FindCurrent(ctx context.Context, agentID agent.ID) (*agent.Config, error) {
+	query, args, err := ConfigTable.Select(ConfigTable.Columns...).
+		Where(squirrel.Eq{"agent_id": agentID}).
+		OrderBy("version DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build agent config query: %w", err)
+	}
+
+	rec, err := postgres.ScanOne[AgentConfigRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, agent.ErrConfigNotFound
+		}
+		return nil, fmt.Errorf("find agent config: %w", err)
+	}
+
+	return toConfigDomain(rec)
+}
+
+// FindHistory returns every config version of the agent, newest first.
+func (r AgentConfigRepository) FindHistory(ctx context.Context, agentID agent.ID) ([]*agent.Config, error) {
+	query, args, err := ConfigTable.Select(ConfigTable.Columns...).
+		Where(squirrel.Eq{"agent_id": agentID}).
+		OrderBy("version DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build agent config history query: %w", err)
+	}
+
+	records, err := postgres.ScanAll[AgentConfigRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan agent configs: %w", err)
+	}
+
+	configs := make([]*agent.Config, 0, len(records))
+	for _, rec := range records {
+		c, err := toConfigDomain(&rec)
+		if err != nil {
+			return nil, fmt.Errorf("convert agent config: %w", err)
+		}
+		configs = append(configs, c)
+	}
+
+	return configs, nil
+}
+
+// Create stores the config as the next version of its agent.
+func (r AgentConfigRepository) Create(ctx context.Context, c *agent.Config) error {
+	rec, err := toConfigRecord(c)
+	if err != nil {
+		return err
+	}
+
+	nextVersion := squirrel.Expr(
+		"(SELECT COALESCE(MAX(version), 0) + 1 FROM "+ConfigTable.Name+" WHERE agent_id = ?)",
+		rec.AgentID,
+	)
+
+	query, args, err := ConfigTable.Insert().
+		Columns(
+			"agent_id",
+			"version",
+			"model_id",
+			"temperature",
+			"max_tokens",
+			"stop_sequences",
+			"system_prompt",
+			"change_note",
+			"created_by",
+		).
+		Values(
+			rec.AgentID,
+			nextVersion,
+			rec.ModelID,
+			rec.Temperature,
+			rec.MaxTokens,
+			rec.StopSequences,
+			rec.SystemPrompt,
+			rec.ChangeNote,
+			rec.CreatedBy,
+		).
+		Suffix("RETURNING id, version, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build insert agent config: %w", err)
+	}
+
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&c.ID, &c.Version, &c.CreatedAt); err != nil {
+		return fmt.Errorf("insert agent config: %w", err)
+	}
+
+	return nil
+}
@@ -2,9 +2,11 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
 	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
 	"github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
@@ -19,7 +21,9 @@ var Table = postgres.Table{
 		"status",
 		"engine",
 		"created_at",
+		"created_by",
 		"updated_at",
+		"updated_by",
 	},
 }
 
@@ -46,19 +50,42 @@ func (r AgentRepository) FindAllByStatus(
 	return r.find(ctx, squirrel.Eq{"status": status})
 }
 
-// Create inserts a new agent.
+// FindByID returns the agent with id.
+func (r AgentRepository) FindByID(ctx context.Context, id agent.ID) (*agent.Agent, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"id": id}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build agent query: %w", err)
+	}
+
+	rec, err := postgres.ScanOne[AgentRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, agent.ErrNotFound
+		}
+		return nil, fmt.Errorf("find agent: %w", err)
+	}
+
+	return toDomain(rec)
+}
+
+// Create inserts a new agent and fills its ID.
 func (r AgentRepository) Create(ctx context.Context, agent *agent.Agent) error {
 	record := toRecord(agent)
 
 	query, args, err := Table.Insert().
-		Columns("name", "type", "status", "engine", "create_by", "update_by").
+		Columns("name", "type", "status", "engine", "created_by", "updated_by").
 		Values(record.Name, record.Type, record.Status, record.Engine, record.CreatedBy, record.UpdatedBy).
+		Suffix("RETURNING id, created_at, updated_at").
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("build insert agent: %w", err)
 	}
 
-	if err := postgres.Exec(ctx, r.db, query, args...); err != nil {
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).
+		Scan(&agent.ID, &agent.CreatedAt, &agent.UpdatedAt); err != nil {
 		return fmt.Errorf("insert agent: %w", err)
 	}
 
@@ -0,0 +1,23 @@
+package agentmodel
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+)
+
+type ModelRecord struct {
+	ID            agentmodel.ID `db:"id"`
+	Provider      string        `db:"provider"`
+	Name          string        `db:"name"`
+	Family        string        `db:"family"`
+	ParameterSize string        `db:"parameter_size"`
+	Quantization  string        `db:"quantization"`
+	SizeBytes     int64         `db:"size_bytes"`
+	Digest        string        `db:"digest"`
+	ModifiedAt    sql.NullTime  `db:"modified_at"`
+	IsAvailable   bool          `db:"is_available"`
+	SyncedAt      time.Time     `db:"synced_at"`
+	CreatedAt     time.Time     `db:"created_at"`
+}
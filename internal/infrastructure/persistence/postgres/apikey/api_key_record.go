@@ -0,0 +1,22 @@
+package apikey
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type APIKeyRecord struct {
+	ID         int64          `db:"id"`
+	UserID     user.ID        `db:"user_id"`
+	Name       string         `db:"name"`
+	Prefix     string         `db:"prefix"`
+	KeyHash    string         `db:"key_hash"`
+	Scopes     string         `db:"scopes"` // space separated
+	ExpiresAt  time.Time      `db:"expires_at"`
+	LastUsedAt sql.NullTime   `db:"last_used_at"`
+	LastUsedIP sql.NullString `db:"last_used_ip"`
+	RevokedAt  sql.NullTime   `db:"revoked_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
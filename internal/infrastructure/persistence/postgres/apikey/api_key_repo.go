@@ -0,0 +1,153 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var Table = postgres.Table{
+	Name: "goat.public.api_keys",
+	Columns: []string{
+		"id",
+		"user_id",
+		"name",
+		"prefix",
+		"key_hash",
+		"scopes",
+		"expires_at",
+		"last_used_at",
+		"last_used_ip",
+		"revoked_at",
+		"created_at",
+	},
+}
+
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+var _ apikey.Repository = (*APIKeyRepository)(nil)
+
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, k *apikey.APIKey) error {
+	query, args, err := Table.Insert().
+		Columns("user_id", "name", "prefix", "key_hash", "scopes", "expires_at").
+		Values(k.UserID, k.Name, k.Prefix, k.Hash, joinScopes(k.Scopes), k.ExpiresAt).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build create api key: %w", err)
+	}
+
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&k.ID, &k.CreatedAt); err != nil {
+		return fmt.Errorf("create api key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) FindByHash(ctx context.Context, hash string) (*apikey.APIKey, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"key_hash": hash}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build api key query: %w", err)
+	}
+
+	rec, err := postgres.ScanOne[APIKeyRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, apikey.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("find api key: %w", err)
+	}
+
+	return toDomain(rec), nil
+}
+
+func (r *APIKeyRepository) FindByUser(ctx context.Context, userID user.ID) ([]*apikey.APIKey, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"user_id": userID, "revoked_at": nil}).
+		Where("expires_at > now()").
+		OrderBy("created_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build api keys query: %w", err)
+	}
+
+	records, err := postgres.ScanAll[APIKeyRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan api keys: %w", err)
+	}
+
+	keys := make([]*apikey.APIKey, 0, len(records))
+	for _, rec := range records {
+		keys = append(keys, toDomain(&rec))
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepository) CountActive(ctx context.Context, userID user.ID) (int, error) {
+	query, args, err := Table.Select("count(*)").
+		Where(squirrel.Eq{"user_id": userID, "revoked_at": nil}).
+		Where("expires_at > now()").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build count api keys: %w", err)
+	}
+
+	var count int
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count api keys: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, userID user.ID, id int64) error {
+	query, args, err := Table.Update().
+		Set("revoked_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": id, "user_id": userID, "revoked_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build revoke api key: %w", err)
+	}
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+
+	// Keys of other users look the same as unknown ones
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return apikey.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) Touch(ctx context.Context, id int64, at time.Time, ip string) error {
+	query, args, err := Table.Update().
+		Set("last_used_at", at).
+		Set("last_used_ip", ip).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build touch api key: %w", err)
+	}
+
+	return postgres.Exec(ctx, r.db, query, args...)
+}
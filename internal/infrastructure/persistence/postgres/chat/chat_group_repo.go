@@ -7,6 +7,7 @@ import (
 
 	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
 	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
 	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
 	"github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
@@ -89,12 +90,18 @@ func (r *ChatGroupRepository) Create(ctx context.Context, g *chatgroup.ChatGroup
 	query, args, err := ChatGroupTable.Insert().
 		Columns("name", "description", "avatar_url", "type", "max_members", "created_by").
 		Values(rec.Name, rec.Description, rec.AvatarURL, rec.Type, rec.MaxMembers, rec.CreatedBy).
+		Suffix("RETURNING id, created_at, updated_at").
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("build insert chat group: %w", err)
 	}
 
-	return postgres.Exec(ctx, r.db, query, args...)
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).
+		Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt); err != nil {
+		return fmt.Errorf("insert chat group: %w", err)
+	}
+
+	return nil
 }
 
 func (r *ChatGroupRepository) Update(ctx context.Context, g *chatgroup.ChatGroup) error {
@@ -26,6 +26,8 @@ var ChatMemberTable = postgres.Table{
 		"is_pinned",
 		"last_read_at",
 		"updated_at",
+		"response_policy",
+		"keywords",
 	},
 }
 
@@ -95,8 +97,8 @@ func (r *ChatMemberRepository) Add(ctx context.Context, m *chatmember.ChatMember
 	rec := toChatMemberRecord(m)
 
 	query, args, err := ChatMemberTable.Insert().
-		Columns("group_id", "participant_id", "role").
-		Values(rec.GroupID, rec.ParticipantID, rec.Role).
+		Columns("group_id", "participant_id", "role", "response_policy", "keywords").
+		Values(rec.GroupID, rec.ParticipantID, rec.Role, rec.ResponsePolicy, rec.Keywords).
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("build insert chat member: %w", err)
@@ -114,6 +116,8 @@ func (r *ChatMemberRepository) Update(ctx context.Context, m *chatmember.ChatMem
 		Set("is_muted", rec.IsMuted).
 		Set("is_pinned", rec.IsPinned).
 		Set("last_read_at", rec.LastReadAt).
+		Set("response_policy", rec.ResponsePolicy).
+		Set("keywords", rec.Keywords).
 		Set("updated_at", squirrel.Expr("now()")).
 		Where(squirrel.Eq{"id": rec.ID}).
 		ToSql()
@@ -4,30 +4,32 @@ import "github.com/HiroLiang/goat-server/internal/domain/chatmessage"
 
 func toChatMessageDomain(rec *ChatMessageRecord) (*chatmessage.ChatMessage, error) {
 	return &chatmessage.ChatMessage{
-		ID:        rec.ID,
-		GroupID:   rec.GroupID,
-		SenderID:  rec.SenderID,
-		Content:   rec.Content,
-		Type:      rec.Type,
-		ReplyToID: rec.ReplyToID,
-		IsEdited:  rec.IsEdited,
-		IsDeleted: rec.IsDeleted,
-		CreatedAt: rec.CreatedAt,
-		UpdatedAt: rec.UpdatedAt,
+		ID:         rec.ID,
+		GroupID:    rec.GroupID,
+		SenderID:   rec.SenderID,
+		Content:    rec.Content,
+		Type:       rec.Type,
+		ReplyToID:  rec.ReplyToID,
+		AgentDepth: rec.AgentDepth,
+		IsEdited:   rec.IsEdited,
+		IsDeleted:  rec.IsDeleted,
+		CreatedAt:  rec.CreatedAt,
+		UpdatedAt:  rec.UpdatedAt,
 	}, nil
 }
 
 func toChatMessageRecord(msg *chatmessage.ChatMessage) *ChatMessageRecord {
 	return &ChatMessageRecord{
-		ID:        msg.ID,
-		GroupID:   msg.GroupID,
-		SenderID:  msg.SenderID,
-		Content:   msg.Content,
-		Type:      msg.Type,
-		ReplyToID: msg.ReplyToID,
-		IsEdited:  msg.IsEdited,
-		IsDeleted: msg.IsDeleted,
-		CreatedAt: msg.CreatedAt,
-		UpdatedAt: msg.UpdatedAt,
+		ID:         msg.ID,
+		GroupID:    msg.GroupID,
+		SenderID:   msg.SenderID,
+		Content:    msg.Content,
+		Type:       msg.Type,
+		ReplyToID:  msg.ReplyToID,
+		AgentDepth: msg.AgentDepth,
+		IsEdited:   msg.IsEdited,
+		IsDeleted:  msg.IsDeleted,
+		CreatedAt:  msg.CreatedAt,
+		UpdatedAt:  msg.UpdatedAt,
 	}
 }
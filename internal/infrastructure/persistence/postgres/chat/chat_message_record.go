@@ -9,14 +9,15 @@ import (
 )
 
 type ChatMessageRecord struct {
-	ID        chatmessage.ID          `db:"id"`
-	GroupID   chatgroup.ID            `db:"group_id"`
-	SenderID  participant.ID          `db:"sender_id"`
-	Content   string                  `db:"content"`
-	Type      chatmessage.MessageType `db:"message_type"`
-	ReplyToID *chatmessage.ID         `db:"reply_to_id"`
-	IsEdited  bool                    `db:"is_edited"`
-	IsDeleted bool                    `db:"is_deleted"`
-	CreatedAt time.Time               `db:"created_at"`
-	UpdatedAt time.Time               `db:"updated_at"`
+	ID         chatmessage.ID          `db:"id"`
+	GroupID    chatgroup.ID            `db:"group_id"`
+	SenderID   participant.ID          `db:"sender_id"`
+	Content    string                  `db:"content"`
+	Type       chatmessage.MessageType `db:"message_type"`
+	ReplyToID  *chatmessage.ID         `db:"reply_to_id"`
+	AgentDepth int                     `db:"agent_depth"`
+	IsEdited   bool                    `db:"is_edited"`
+	IsDeleted  bool                    `db:"is_deleted"`
+	CreatedAt  time.Time               `db:"created_at"`
+	UpdatedAt  time.Time               `db:"updated_at"`
 }
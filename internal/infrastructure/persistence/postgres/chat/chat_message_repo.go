@@ -9,6 +9,7 @@ import (
 	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
 	"github.com/HiroLiang/goat-server/internal/domain/chatmessage"
 	"github.com/HiroLiang/goat-server/internal/domain/participant"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
 	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
 	"github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
@@ -23,6 +24,7 @@ var CharMessageTable = postgres.Table{
 		"content",
 		"message_type",
 		"reply_to_id",
+		"agent_depth",
 		"is_edited",
 		"is_deleted",
 		"created_at",
@@ -175,7 +177,7 @@ func (r *ChatMessageRepository) CountByGroupAfter(
 	}
 
 	var count int64
-	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
 		return 0, fmt.Errorf("count messages after: %w", err)
 	}
 
@@ -215,14 +217,20 @@ func (r *ChatMessageRepository) Create(ctx context.Context, msg *chatmessage.Cha
 	rec := toChatMessageRecord(msg)
 
 	query, args, err := CharMessageTable.Insert().
-		Columns("group_id", "sender_id", "content", "message_type", "reply_to_id").
-		Values(rec.GroupID, rec.SenderID, rec.Content, rec.Type, rec.ReplyToID).
+		Columns("group_id", "sender_id", "content", "message_type", "reply_to_id", "agent_depth").
+		Values(rec.GroupID, rec.SenderID, rec.Content, rec.Type, rec.ReplyToID, rec.AgentDepth).
+		Suffix("RETURNING id, created_at, updated_at").
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("build insert chat message: %w", err)
 	}
 
-	return postgres.Exec(ctx, r.db, query, args...)
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).
+		Scan(&msg.ID, &msg.CreatedAt, &msg.UpdatedAt); err != nil {
+		return fmt.Errorf("insert chat message: %w", err)
+	}
+
+	return nil
 }
 
 func (r *ChatMessageRepository) Update(ctx context.Context, msg *chatmessage.ChatMessage) error {
@@ -0,0 +1,220 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var CommandTable = postgres.Table{
+	Name: "goat.public.device_commands",
+	Columns: []string{
+		"id",
+		"device_id",
+		"user_id",
+		"command_type",
+		"payload",
+		"status",
+		"result",
+		"error",
+		"expires_at",
+		"delivered_at",
+		"completed_at",
+		"created_at",
+	},
+}
+
+// openStatuses the commands still waiting for their device
+var openStatuses = []string{string(devicecommand.Pending), string(devicecommand.Delivered)}
+
+type DeviceCommandRepository struct {
+	db *sqlx.DB
+}
+
+var _ devicecommand.Repository = (*DeviceCommandRepository)(nil)
+
+func NewDeviceCommandRepository(db *sqlx.DB) *DeviceCommandRepository {
+	return &DeviceCommandRepository{db: db}
+}
+
+func (r *DeviceCommandRepository) Create(ctx context.Context, c *devicecommand.Command) error {
+	query, args, err := CommandTable.Insert().
+		Columns("device_id", "user_id", "command_type", "payload", "status", "expires_at").
+		Values(c.DeviceID, c.UserID, c.Type, string(c.Payload), string(c.Status), c.ExpiresAt).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build create device command: %w", err)
+	}
+
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&c.ID, &c.CreatedAt); err != nil {
+		return fmt.Errorf("create device command: %w", err)
+	}
+
+	return nil
+}
+
+func (r *DeviceCommandRepository) Find(
+	ctx context.Context,
+	userID user.ID,
+	deviceID device.ID,
+	id devicecommand.ID) (*devicecommand.Command, error) {
+	query, args, err := CommandTable.Select(CommandTable.Columns...).
+		Where(squirrel.Eq{"id": id, "device_id": deviceID, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build device command query: %w", err)
+	}
+
+	return r.findOne(ctx, query, args...)
+}
+
+func (r *DeviceCommandRepository) FindForDevice(ctx context.Context, deviceID device.ID, id devicecommand.ID) (*devicecommand.Command, error) {
+	query, args, err := CommandTable.Select(CommandTable.Columns...).
+		Where(squirrel.Eq{"id": id, "device_id": deviceID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build device command query: %w", err)
+	}
+
+	return r.findOne(ctx, query, args...)
+}
+
+func (r *DeviceCommandRepository) FindByDevice(
+	ctx context.Context,
+	userID user.ID,
+	deviceID device.ID,
+	limit int) ([]*devicecommand.Command, error) {
+	query, args, err := CommandTable.Select(CommandTable.Columns...).
+		Where(squirrel.Eq{"device_id": deviceID, "user_id": userID}).
+		OrderBy("id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build device commands query: %w", err)
+	}
+
+	return r.findAll(ctx, query, args...)
+}
+
+func (r *DeviceCommandRepository) FindUndelivered(ctx context.Context, deviceID device.ID, at time.Time) ([]*devicecommand.Command, error) {
+	query, args, err := CommandTable.Select(CommandTable.Columns...).
+		Where(squirrel.Eq{"device_id": deviceID, "status": string(devicecommand.Pending)}).
+		Where(squirrel.Gt{"expires_at": at}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build undelivered commands query: %w", err)
+	}
+
+	return r.findAll(ctx, query, args...)
+}
+
+func (r *DeviceCommandRepository) CountOpen(ctx context.Context, deviceID device.ID, at time.Time) (int, error) {
+	query, args, err := CommandTable.Select("COUNT(*)").
+		Where(squirrel.Eq{"device_id": deviceID, "status": openStatuses}).
+		Where(squirrel.Gt{"expires_at": at}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build count open commands: %w", err)
+	}
+
+	var count int
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &count, query, args...); err != nil {
+		return 0, fmt.Errorf("count open commands: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *DeviceCommandRepository) MarkDelivered(ctx context.Context, id devicecommand.ID, at time.Time) error {
+	query, args, err := CommandTable.Update().
+		Set("status", string(devicecommand.Delivered)).
+		Set("delivered_at", at).
+		Where(squirrel.Eq{"id": id, "status": string(devicecommand.Pending)}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build mark command delivered: %w", err)
+	}
+
+	return postgres.Exec(ctx, r.db, query, args...)
+}
+
+func (r *DeviceCommandRepository) Complete(ctx context.Context, c *devicecommand.Command) error {
+	query, args, err := CommandTable.Update().
+		Set("status", string(c.Status)).
+		Set("result", nullable(string(c.Result))).
+		Set("error", c.Error).
+		Set("completed_at", c.CompletedAt).
+		Where(squirrel.Eq{"id": c.ID, "status": openStatuses}).
+		Where(squirrel.Gt{"expires_at": c.CompletedAt}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build complete device command: %w", err)
+	}
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("complete device command: %w", err)
+	}
+
+	// Acknowledged twice, or expired in the meantime
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return devicecommand.ErrCommandClosed
+	}
+
+	return nil
+}
+
+func (r *DeviceCommandRepository) ExpireDue(ctx context.Context, at time.Time) (int64, error) {
+	query, args, err := CommandTable.Update().
+		Set("status", string(devicecommand.Expired)).
+		Where(squirrel.Eq{"status": openStatuses}).
+		Where(squirrel.LtOrEq{"expires_at": at}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build expire device commands: %w", err)
+	}
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("expire device commands: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *DeviceCommandRepository) findOne(ctx context.Context, query string, args ...any) (*devicecommand.Command, error) {
+	rec, err := postgres.ScanOne[DeviceCommandRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, devicecommand.ErrCommandNotFound
+		}
+		return nil, fmt.Errorf("find device command: %w", err)
+	}
+
+	return toCommandDomain(rec), nil
+}
+
+func (r *DeviceCommandRepository) findAll(ctx context.Context, query string, args ...any) ([]*devicecommand.Command, error) {
+	records, err := postgres.ScanAll[DeviceCommandRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan device commands: %w", err)
+	}
+
+	commands := make([]*devicecommand.Command, 0, len(records))
+	for _, rec := range records {
+		commands = append(commands, toCommandDomain(&rec))
+	}
+
+	return commands, nil
+}
@@ -0,0 +1,27 @@
+package device
+
+import (
+	"database/sql"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+)
+
+func toDomain(record *DeviceRecord) *device.Device {
+	return &device.Device{
+		ID:         record.ID,
+		UserID:     record.UserID,
+		DeviceID:   record.DeviceID,
+		Name:       record.Name,
+		Platform:   device.Platform(record.Platform),
+		SessionID:  record.SessionID,
+		PushToken:  record.PushToken.String,
+		SecretHash: record.SecretHash.String,
+		CreatedAt:  record.CreatedAt,
+		UpdatedAt:  record.UpdatedAt,
+	}
+}
+
+// nullable stores a missing token or secret as NULL, so the unique index only covers real ones
+func nullable(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
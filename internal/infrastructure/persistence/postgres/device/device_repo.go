@@ -0,0 +1,207 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var Table = postgres.Table{
+	Name: "goat.public.devices",
+	Columns: []string{
+		"id",
+		"user_id",
+		"device_id",
+		"name",
+		"platform",
+		"session_id",
+		"push_token",
+		"secret_hash",
+		"created_at",
+		"updated_at",
+	},
+}
+
+type DeviceRepository struct {
+	db *sqlx.DB
+}
+
+var _ device.Repository = (*DeviceRepository)(nil)
+
+func NewDeviceRepository(db *sqlx.DB) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+func (r *DeviceRepository) Register(ctx context.Context, d *device.Device) error {
+	query, args, err := Table.Insert().
+		Columns("user_id", "device_id", "name", "platform", "session_id", "push_token", "secret_hash").
+		Values(d.UserID, d.DeviceID, d.Name, string(d.Platform), d.SessionID, nullable(d.PushToken), nullable(d.SecretHash)).
+		Suffix(`ON CONFLICT (user_id, device_id) DO UPDATE SET
+			name = EXCLUDED.name,
+			platform = EXCLUDED.platform,
+			session_id = EXCLUDED.session_id,
+			push_token = EXCLUDED.push_token,
+			secret_hash = EXCLUDED.secret_hash,
+			updated_at = now()
+			RETURNING id, created_at, updated_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build register device: %w", err)
+	}
+
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return fmt.Errorf("register device: %w", err)
+	}
+
+	return nil
+}
+
+func (r *DeviceRepository) Find(ctx context.Context, userID user.ID, id device.ID) (*device.Device, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"id": id, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build device query: %w", err)
+	}
+
+	return r.findOne(ctx, query, args...)
+}
+
+func (r *DeviceRepository) FindByID(ctx context.Context, id device.ID) (*device.Device, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build device query: %w", err)
+	}
+
+	return r.findOne(ctx, query, args...)
+}
+
+func (r *DeviceRepository) FindBySecretHash(ctx context.Context, hash string) (*device.Device, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"secret_hash": hash}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build device secret query: %w", err)
+	}
+
+	return r.findOne(ctx, query, args...)
+}
+
+func (r *DeviceRepository) FindByUser(ctx context.Context, userID user.ID) ([]*device.Device, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("updated_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build devices query: %w", err)
+	}
+
+	records, err := postgres.ScanAll[DeviceRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan devices: %w", err)
+	}
+
+	devices := make([]*device.Device, 0, len(records))
+	for _, rec := range records {
+		devices = append(devices, toDomain(&rec))
+	}
+
+	return devices, nil
+}
+
+func (r *DeviceRepository) FindPushTargets(ctx context.Context, userIDs []user.ID) ([]*device.Device, error) {
+	if len(userIDs) == 0 {
+		return []*device.Device{}, nil
+	}
+
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"user_id": userIDs}).
+		Where(squirrel.NotEq{"push_token": nil}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build push targets query: %w", err)
+	}
+
+	records, err := postgres.ScanAll[DeviceRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan push targets: %w", err)
+	}
+
+	devices := make([]*device.Device, 0, len(records))
+	for _, rec := range records {
+		devices = append(devices, toDomain(&rec))
+	}
+
+	return devices, nil
+}
+
+func (r *DeviceRepository) ClearPushToken(ctx context.Context, token string) error {
+	query, args, err := Table.Update().
+		Set("push_token", nil).
+		Where(squirrel.Eq{"push_token": token}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build clear push token: %w", err)
+	}
+
+	return postgres.Exec(ctx, r.db, query, args...)
+}
+
+func (r *DeviceRepository) Rename(ctx context.Context, userID user.ID, id device.ID, name string) error {
+	query, args, err := Table.Update().
+		Set("name", name).
+		Set("updated_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": id, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build rename device: %w", err)
+	}
+
+	return r.execOwned(ctx, "rename device", query, args...)
+}
+
+func (r *DeviceRepository) Delete(ctx context.Context, userID user.ID, id device.ID) error {
+	query, args, err := Table.Delete().
+		Where(squirrel.Eq{"id": id, "user_id": userID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build delete device: %w", err)
+	}
+
+	return r.execOwned(ctx, "delete device", query, args...)
+}
+
+func (r *DeviceRepository) findOne(ctx context.Context, query string, args ...any) (*device.Device, error) {
+	rec, err := postgres.ScanOne[DeviceRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, device.ErrDeviceNotFound
+		}
+		return nil, fmt.Errorf("find device: %w", err)
+	}
+
+	return toDomain(rec), nil
+}
+
+// execOwned runs a statement scoped to the owner; devices of other users look the same as unknown ones
+func (r *DeviceRepository) execOwned(ctx context.Context, op, query string, args ...any) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return device.ErrDeviceNotFound
+	}
+
+	return nil
+}
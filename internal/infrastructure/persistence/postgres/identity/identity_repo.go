@@ -0,0 +1,120 @@
+package identity
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/identity"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var Table = postgres.Table{
+	Name: "goat.public.user_identities",
+	Columns: []string{
+		"id",
+		"user_id",
+		"provider",
+		"subject",
+		"email",
+		"created_at",
+	},
+}
+
+type IdentityRepository struct {
+	db *sqlx.DB
+}
+
+var _ identity.Repository = (*IdentityRepository)(nil)
+
+func NewIdentityRepository(db *sqlx.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+func (r *IdentityRepository) FindBySubject(ctx context.Context, provider, subject string) (*identity.Identity, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"provider": provider, "subject": subject}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build identity query: %w", err)
+	}
+
+	rec, err := postgres.ScanOne[IdentityRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, identity.ErrIdentityNotFound
+		}
+		return nil, fmt.Errorf("find identity: %w", err)
+	}
+
+	return toDomain(rec), nil
+}
+
+func (r *IdentityRepository) FindByUser(ctx context.Context, userID user.ID) ([]*identity.Identity, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("provider").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build identities query: %w", err)
+	}
+
+	records, err := postgres.ScanAll[IdentityRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan identities: %w", err)
+	}
+
+	identities := make([]*identity.Identity, 0, len(records))
+	for _, rec := range records {
+		identities = append(identities, toDomain(&rec))
+	}
+
+	return identities, nil
+}
+
+func (r *IdentityRepository) Create(ctx context.Context, i *identity.Identity) error {
+	query, args, err := Table.Insert().
+		Columns("user_id", "provider", "subject", "email").
+		Values(i.UserID, i.Provider, i.Subject, i.Email).
+		Suffix("ON CONFLICT DO NOTHING RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build create identity: %w", err)
+	}
+
+	// Both unique keys, provider account and provider per user, end up here
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&i.ID, &i.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return identity.ErrAlreadyLinked
+	}
+	if err != nil {
+		return fmt.Errorf("create identity: %w", err)
+	}
+
+	return nil
+}
+
+func (r *IdentityRepository) Delete(ctx context.Context, userID user.ID, provider string) error {
+	query, args, err := Table.Delete().
+		Where(squirrel.Eq{"user_id": userID, "provider": provider}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build delete identity: %w", err)
+	}
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("delete identity: %w", err)
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return identity.ErrIdentityNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package permission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPermissionRepository_FindByRole Test permissions are joined through roles
+func TestPermissionRepository_FindByRole(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := PermissionRepository{db: sqlx.NewDb(db, "postgres")}
+
+	mock.ExpectQuery(`SELECT rp.permission FROM goat.public.role_permissions rp JOIN goat.public.roles r ON r.id = rp.role_id WHERE r.type = \$1`).
+		WithArgs("admin").
+		WillReturnRows(
+			sqlmock.NewRows([]string{"permission"}).
+				AddRow("agent:manage").
+				AddRow("user:ban"),
+		)
+
+	permissions, err := repo.FindByRole(context.Background(), role.Admin)
+	assert.NoError(t, err)
+	assert.Equal(t, []permission.Permission{permission.AgentManage, permission.UserBan}, permissions)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
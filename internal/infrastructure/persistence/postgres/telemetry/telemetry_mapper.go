@@ -0,0 +1,14 @@
+package telemetry
+
+import "github.com/HiroLiang/goat-server/internal/domain/telemetry"
+
+func toPoint(rec *PointRecord) telemetry.Point {
+	return telemetry.Point{
+		Metric: rec.Metric,
+		Bucket: rec.Bucket,
+		Count:  rec.Count,
+		Avg:    rec.Avg,
+		Min:    rec.Min,
+		Max:    rec.Max,
+	}
+}
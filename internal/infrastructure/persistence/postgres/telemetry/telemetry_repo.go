@@ -0,0 +1,178 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var SampleTable = postgres.Table{
+	Name: "goat.public.device_telemetry",
+	Columns: []string{
+		"device_id",
+		"metric",
+		"value",
+		"recorded_at",
+	},
+}
+
+var RollupTable = postgres.Table{
+	Name: "goat.public.device_telemetry_rollups",
+	Columns: []string{
+		"device_id",
+		"metric",
+		"bucket_start",
+		"sample_count",
+		"value_sum",
+		"value_min",
+		"value_max",
+	},
+}
+
+// bucketOf truncates a timestamp column to buckets of a number of seconds, counted from the epoch
+func bucketOf(column string) string {
+	return fmt.Sprintf("to_timestamp(floor(extract(epoch FROM %s) / ?) * ?) AT TIME ZONE 'UTC'", column)
+}
+
+// rollupQuery moves old samples into buckets in one statement, so a sample is
+// never counted twice or lost between the copy and the delete
+var rollupQuery = `WITH moved AS (
+	DELETE FROM ` + SampleTable.Name + ` WHERE recorded_at < $1
+	RETURNING device_id, metric, value, recorded_at
+)
+INSERT INTO ` + RollupTable.Name + ` (device_id, metric, bucket_start, sample_count, value_sum, value_min, value_max)
+SELECT device_id, metric, to_timestamp(floor(extract(epoch FROM recorded_at) / $2) * $2) AT TIME ZONE 'UTC',
+	COUNT(*), SUM(value), MIN(value), MAX(value)
+FROM moved
+GROUP BY 1, 2, 3
+ON CONFLICT (device_id, metric, bucket_start) DO UPDATE SET
+	sample_count = device_telemetry_rollups.sample_count + EXCLUDED.sample_count,
+	value_sum = device_telemetry_rollups.value_sum + EXCLUDED.value_sum,
+	value_min = LEAST(device_telemetry_rollups.value_min, EXCLUDED.value_min),
+	value_max = GREATEST(device_telemetry_rollups.value_max, EXCLUDED.value_max)`
+
+type TelemetryRepository struct {
+	db *sqlx.DB
+}
+
+var _ telemetry.Repository = (*TelemetryRepository)(nil)
+
+func NewTelemetryRepository(db *sqlx.DB) *TelemetryRepository {
+	return &TelemetryRepository{db: db}
+}
+
+func (r *TelemetryRepository) Insert(ctx context.Context, samples []*telemetry.Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	insert := SampleTable.Insert().Columns(SampleTable.Columns...)
+	for _, s := range samples {
+		insert = insert.Values(s.DeviceID, s.Metric, s.Value, s.At)
+	}
+
+	query, args, err := insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("build insert telemetry: %w", err)
+	}
+
+	return postgres.Exec(ctx, r.db, query, args...)
+}
+
+// Aggregate buckets the raw samples and the rollups of the range together, a bucket
+// of rolled up data counts the samples it was made of
+func (r *TelemetryRepository) Aggregate(ctx context.Context, q telemetry.Query) ([]telemetry.Point, error) {
+	raw := squirrel.Select(
+		"metric",
+		"recorded_at AS at",
+		"1 AS samples",
+		"value AS total",
+		"value AS low",
+		"value AS high").
+		From(SampleTable.Name).
+		Where(squirrel.Eq{"device_id": q.DeviceID}).
+		Where(squirrel.GtOrEq{"recorded_at": q.From}).
+		Where(squirrel.Lt{"recorded_at": q.To})
+
+	rolled := squirrel.Select(
+		"metric",
+		"bucket_start",
+		"sample_count",
+		"value_sum",
+		"value_min",
+		"value_max").
+		From(RollupTable.Name).
+		Where(squirrel.Eq{"device_id": q.DeviceID}).
+		Where(squirrel.GtOrEq{"bucket_start": q.From}).
+		Where(squirrel.Lt{"bucket_start": q.To})
+
+	if len(q.Metrics) > 0 {
+		raw = raw.Where(squirrel.Eq{"metric": q.Metrics})
+		rolled = rolled.Where(squirrel.Eq{"metric": q.Metrics})
+	}
+
+	rolledSQL, rolledArgs, err := rolled.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build telemetry rollups query: %w", err)
+	}
+
+	step := int64(q.Step / time.Second)
+	query, args, err := postgres.Builder.
+		Select("metric").
+		Column(squirrel.Expr(bucketOf("at")+" AS bucket", step, step)).
+		Columns(
+			"SUM(samples) AS sample_count",
+			"SUM(total) / SUM(samples) AS value_avg",
+			"MIN(low) AS value_min",
+			"MAX(high) AS value_max").
+		FromSelect(raw.Suffix("UNION ALL "+rolledSQL, rolledArgs...), "s").
+		GroupBy("metric", "bucket").
+		OrderBy("metric", "bucket").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build telemetry query: %w", err)
+	}
+
+	records, err := postgres.ScanAll[PointRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan telemetry: %w", err)
+	}
+
+	points := make([]telemetry.Point, 0, len(records))
+	for _, rec := range records {
+		points = append(points, toPoint(&rec))
+	}
+
+	return points, nil
+}
+
+func (r *TelemetryRepository) Rollup(ctx context.Context, cutoff time.Time, step time.Duration) (int64, error) {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, rollupQuery, cutoff, int64(step/time.Second))
+	if err != nil {
+		return 0, fmt.Errorf("rollup telemetry: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *TelemetryRepository) DeleteRollupsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	query, args, err := RollupTable.Delete().
+		Where(squirrel.Lt{"bucket_start": cutoff}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build delete telemetry rollups: %w", err)
+	}
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete telemetry rollups: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,78 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTelemetryRepository_Insert Test a batch is stored in one statement
+func TestTelemetryRepository_Insert(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := TelemetryRepository{db: sqlx.NewDb(db, "postgres")}
+
+	now := time.Now()
+	mock.ExpectExec(`INSERT INTO goat.public.device_telemetry \(device_id,metric,value,recorded_at\) VALUES \(\$1,\$2,\$3,\$4\),\(\$5,\$6,\$7,\$8\)`).
+		WithArgs(device.ID(3), "temperature", 21.5, now, device.ID(3), "cpu", 12.0, now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := repo.Insert(context.Background(), []*telemetry.Sample{
+		{DeviceID: 3, Metric: "temperature", Value: 21.5, At: now},
+		{DeviceID: 3, Metric: "cpu", Value: 12, At: now},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTelemetryRepository_Aggregate Test raw samples and rollups are bucketed together
+func TestTelemetryRepository_Aggregate(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := TelemetryRepository{db: sqlx.NewDb(db, "postgres")}
+
+	to := time.Now().Truncate(time.Hour)
+	from := to.Add(-time.Hour)
+	mock.ExpectQuery(`SELECT metric, to_timestamp\(floor\(extract\(epoch FROM at\) / \$1\) \* \$2\) AT TIME ZONE 'UTC' AS bucket, SUM\(samples\) AS sample_count, .* `+
+		`FROM \(SELECT metric, recorded_at AS at, .* FROM goat.public.device_telemetry WHERE device_id = \$3 AND recorded_at >= \$4 AND recorded_at < \$5 AND metric IN \(\$6\) `+
+		`UNION ALL SELECT metric, bucket_start, .* FROM goat.public.device_telemetry_rollups WHERE device_id = \$7 AND bucket_start >= \$8 AND bucket_start < \$9 AND metric IN \(\$10\)\) AS s `+
+		`GROUP BY metric, bucket ORDER BY metric, bucket`).
+		WithArgs(int64(300), int64(300), device.ID(3), from, to, "cpu", device.ID(3), from, to, "cpu").
+		WillReturnRows(sqlmock.NewRows([]string{"metric", "bucket", "sample_count", "value_avg", "value_min", "value_max"}).
+			AddRow("cpu", from, 10, 12.5, 3.0, 40.0))
+
+	points, err := repo.Aggregate(context.Background(), telemetry.Query{
+		DeviceID: 3,
+		Metrics:  []string{"cpu"},
+		From:     from,
+		To:       to,
+		Step:     5 * time.Minute,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []telemetry.Point{{Metric: "cpu", Bucket: from, Count: 10, Avg: 12.5, Min: 3, Max: 40}}, points)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTelemetryRepository_Rollup Test old samples are moved into buckets of the step
+func TestTelemetryRepository_Rollup(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := TelemetryRepository{db: sqlx.NewDb(db, "postgres")}
+
+	cutoff := time.Now().Add(-48 * time.Hour)
+	mock.ExpectExec(`WITH moved AS \(\s*DELETE FROM goat.public.device_telemetry WHERE recorded_at < \$1`).
+		WithArgs(cutoff, int64(300)).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	n, err := repo.Rollup(context.Background(), cutoff, 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,47 @@
+package twofactor
+
+import (
+	"database/sql"
+
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
+)
+
+func toDomain(record *TwoFactorRecord) *twofactor.TwoFactor {
+	t := &twofactor.TwoFactor{
+		UserID:       record.UserID,
+		Secret:       record.Secret,
+		LastUsedStep: record.LastUsedStep,
+		CreatedAt:    record.CreatedAt,
+		UpdatedAt:    record.UpdatedAt,
+	}
+	if record.EnabledAt.Valid {
+		t.EnabledAt = &record.EnabledAt.Time
+	}
+	return t
+}
+
+func toRecord(t *twofactor.TwoFactor) *TwoFactorRecord {
+	rec := &TwoFactorRecord{
+		UserID:       t.UserID,
+		Secret:       t.Secret,
+		LastUsedStep: t.LastUsedStep,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+	if t.EnabledAt != nil {
+		rec.EnabledAt = sql.NullTime{Time: *t.EnabledAt, Valid: true}
+	}
+	return rec
+}
+
+func toRecoveryCodeDomain(record *RecoveryCodeRecord) *twofactor.RecoveryCode {
+	code := &twofactor.RecoveryCode{
+		ID:     record.ID,
+		UserID: record.UserID,
+		Hash:   record.CodeHash,
+	}
+	if record.UsedAt.Valid {
+		code.UsedAt = &record.UsedAt.Time
+	}
+	return code
+}
@@ -0,0 +1,162 @@
+package twofactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var Table = postgres.Table{
+	Name: "goat.public.user_two_factors",
+	Columns: []string{
+		"user_id",
+		"secret",
+		"enabled_at",
+		"last_used_step",
+		"created_at",
+		"updated_at",
+	},
+}
+
+var RecoveryCodeTable = postgres.Table{
+	Name: "goat.public.user_recovery_codes",
+	Columns: []string{
+		"id",
+		"user_id",
+		"code_hash",
+		"used_at",
+	},
+}
+
+type TwoFactorRepository struct {
+	db *sqlx.DB
+}
+
+var _ twofactor.Repository = (*TwoFactorRepository)(nil)
+
+func NewTwoFactorRepository(db *sqlx.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) Find(ctx context.Context, userID user.ID) (*twofactor.TwoFactor, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build two factor query: %w", err)
+	}
+
+	rec, err := postgres.ScanOne[TwoFactorRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNotFound) {
+			return nil, twofactor.ErrNotEnrolled
+		}
+		return nil, fmt.Errorf("find two factor: %w", err)
+	}
+
+	return toDomain(rec), nil
+}
+
+func (r *TwoFactorRepository) Save(ctx context.Context, t *twofactor.TwoFactor) error {
+	rec := toRecord(t)
+
+	query, args, err := Table.Insert().
+		Columns("user_id", "secret", "enabled_at", "last_used_step").
+		Values(rec.UserID, rec.Secret, rec.EnabledAt, rec.LastUsedStep).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			enabled_at = EXCLUDED.enabled_at,
+			last_used_step = EXCLUDED.last_used_step,
+			updated_at = now()
+			RETURNING created_at, updated_at`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build save two factor: %w", err)
+	}
+
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&t.CreatedAt, &t.UpdatedAt); err != nil {
+		return fmt.Errorf("save two factor: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRepository) Delete(ctx context.Context, userID user.ID) error {
+	query, args, err := Table.Delete().
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build delete two factor: %w", err)
+	}
+
+	// Recovery codes go with the enrollment through ON DELETE CASCADE
+	return postgres.Exec(ctx, r.db, query, args...)
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID user.ID, hashes []string) error {
+	insert := RecoveryCodeTable.Insert().
+		Prefix("WITH deleted AS (DELETE FROM "+RecoveryCodeTable.Name+" WHERE user_id = ?)", userID).
+		Columns("user_id", "code_hash")
+	for _, hash := range hashes {
+		insert = insert.Values(userID, hash)
+	}
+
+	// One statement, so a failure never leaves the user without codes
+	query, args, err := insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("build replace recovery codes: %w", err)
+	}
+
+	return postgres.Exec(ctx, r.db, query, args...)
+}
+
+func (r *TwoFactorRepository) FindUnusedRecoveryCodes(ctx context.Context, userID user.ID) ([]*twofactor.RecoveryCode, error) {
+	query, args, err := RecoveryCodeTable.Select(RecoveryCodeTable.Columns...).
+		Where(squirrel.Eq{"user_id": userID, "used_at": nil}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build recovery codes query: %w", err)
+	}
+
+	records, err := postgres.ScanAll[RecoveryCodeRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan recovery codes: %w", err)
+	}
+
+	codes := make([]*twofactor.RecoveryCode, 0, len(records))
+	for _, rec := range records {
+		codes = append(codes, toRecoveryCodeDomain(&rec))
+	}
+
+	return codes, nil
+}
+
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, id int64) error {
+	query, args, err := RecoveryCodeTable.Update().
+		Set("used_at", squirrel.Expr("now()")).
+		Where(squirrel.Eq{"id": id, "used_at": nil}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build use recovery code: %w", err)
+	}
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("use recovery code: %w", err)
+	}
+
+	// Zero rows means a concurrent login used the code first
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return twofactor.ErrInvalidCode
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package twofactor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTwoFactorRepository_ReplaceRecoveryCodes Test old codes are dropped in the same statement
+func TestTwoFactorRepository_ReplaceRecoveryCodes(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := TwoFactorRepository{db: sqlx.NewDb(db, "postgres")}
+
+	mock.ExpectExec(`WITH deleted AS \(DELETE FROM goat.public.user_recovery_codes WHERE user_id = \$1\) INSERT INTO goat.public.user_recovery_codes \(user_id,code_hash\) VALUES \(\$2,\$3\),\(\$4,\$5\)`).
+		WithArgs(user.ID(1), user.ID(1), "h1", user.ID(1), "h2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := repo.ReplaceRecoveryCodes(context.Background(), 1, []string{"h1", "h2"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTwoFactorRepository_UseRecoveryCode_AlreadyUsed Test a used code is rejected
+func TestTwoFactorRepository_UseRecoveryCode_AlreadyUsed(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := TwoFactorRepository{db: sqlx.NewDb(db, "postgres")}
+
+	mock.ExpectExec(`UPDATE goat.public.user_recovery_codes SET used_at = now\(\) WHERE id = \$1 AND used_at IS NULL`).
+		WithArgs(int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UseRecoveryCode(context.Background(), 7)
+	assert.ErrorIs(t, err, twofactor.ErrInvalidCode)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
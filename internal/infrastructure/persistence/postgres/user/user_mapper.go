@@ -1,9 +1,14 @@
 package user
 
-import "github.com/HiroLiang/goat-server/internal/domain/user"
+import (
+	"database/sql"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
 
 func toDomain(record *UserRecord) (*user.User, error) {
-	return &user.User{
+	u := &user.User{
 		ID:        record.ID,
 		Name:      record.Name,
 		Email:     record.Email,
@@ -12,7 +17,11 @@ func toDomain(record *UserRecord) (*user.User, error) {
 		LastIP:    record.UserIP,
 		CreatedAt: record.CreatedAt,
 		UpdatedAt: record.UpdatedAt,
-	}, nil
+	}
+	if record.VerifiedAt.Valid {
+		u.EmailVerifiedAt = &record.VerifiedAt.Time
+	}
+	return u, nil
 }
 
 func toRecord(user *user.User) *UserRecord {
@@ -23,5 +32,13 @@ func toRecord(user *user.User) *UserRecord {
 		Password:   user.Password,
 		UserStatus: user.Status,
 		UserIP:     user.LastIP,
+		VerifiedAt: toNullTime(user.EmailVerifiedAt),
+	}
+}
+
+func toNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
 	}
+	return sql.NullTime{Time: *t, Valid: true}
 }
@@ -2,10 +2,12 @@ package user
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
 	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
 	"github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
@@ -20,6 +22,7 @@ var Table = postgres.Table{
 		"password",
 		"user_status",
 		"user_ip",
+		"email_verified_at",
 		"created_at",
 		"updated_at",
 	},
@@ -43,18 +46,55 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email user.Email) (*us
 	return r.findOneBy(ctx, squirrel.Eq{"email": email})
 }
 
+func (r *UserRepository) FindByStatus(ctx context.Context, status user.Status) ([]*user.User, error) {
+	query, args, err := Table.
+		Select(Table.Columns...).
+		Where(squirrel.Eq{"user_status": status}).
+		OrderBy("created_at ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build users by status query: %w", err)
+	}
+
+	records, err := postgres.ScanAll[UserRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan users: %w", err)
+	}
+
+	users := make([]*user.User, 0, len(records))
+	for _, rec := range records {
+		u, err := toDomain(&rec)
+		if err != nil {
+			return nil, fmt.Errorf("convert user: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	return users, nil
+}
+
 func (r *UserRepository) Create(ctx context.Context, u *user.User) error {
 	record := toRecord(u)
 
 	query, args, err := Table.Insert().
 		Columns("name", "email", "password", "user_status", "user_ip").
 		Values(record.Name, record.Email, record.Password, record.UserStatus, record.UserIP).
+		Suffix("ON CONFLICT DO NOTHING RETURNING id").
 		ToSql()
 	if err != nil {
-		return err
+		return fmt.Errorf("build create user: %w", err)
 	}
 
-	return postgres.Exec(ctx, r.db, query, args...)
+	// The unique email is the only key a new user can collide on
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&u.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.ErrUserAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("create user: %w", err)
+	}
+
+	return nil
 }
 
 func (r *UserRepository) Update(ctx context.Context, u *user.User) error {
@@ -65,6 +105,8 @@ func (r *UserRepository) Update(ctx context.Context, u *user.User) error {
 		Set("password", rec.Password).
 		Set("user_status", rec.UserStatus).
 		Set("user_ip", rec.UserIP).
+		Set("email_verified_at", rec.VerifiedAt).
+		Set("updated_at", squirrel.Expr("now()")).
 		Where(squirrel.Eq{"id": rec.ID}).
 		ToSql()
 	if err != nil {
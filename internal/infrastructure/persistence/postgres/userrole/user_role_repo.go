@@ -9,6 +9,7 @@ import (
 	"github.com/HiroLiang/goat-server/internal/domain/role"
 	"github.com/HiroLiang/goat-server/internal/domain/user"
 	"github.com/HiroLiang/goat-server/internal/domain/userrole"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
 	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres"
 	dbRole "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/postgres/role"
 	"github.com/Masterminds/squirrel"
@@ -35,8 +36,15 @@ func NewUserRoleRepository(db *sqlx.DB) *UserRoleRepository {
 }
 
 func (r UserRoleRepository) FindRolesByUser(ctx context.Context, userID user.ID) ([]*role.Role, error) {
-	query, args, err := dbRole.Table.Select(dbRole.Table.Columns...).
-		Where(squirrel.Eq{"user_id": userID}).
+	columns := make([]string, 0, len(dbRole.Table.Columns))
+	for _, c := range dbRole.Table.Columns {
+		columns = append(columns, "r."+c)
+	}
+
+	query, args, err := postgres.Builder.Select(columns...).
+		From(dbRole.Table.Name + " r").
+		Join(Table.Name + " ur ON ur.role_id = r.id").
+		Where(squirrel.Eq{"ur.user_id": userID}).
 		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build user_role query: %w", err)
@@ -89,7 +97,7 @@ func (r UserRoleRepository) Assign(ctx context.Context, userID user.ID, role rol
 		return fmt.Errorf("build assign sql: %w", err)
 	}
 
-	err = r.db.QueryRowContext(ctx, query, args...).Scan(&userID)
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&userID)
 	if errors.Is(err, sql.ErrNoRows) {
 		return userrole.ErrUserRoleAlreadyAssigned
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/HiroLiang/goat-server/internal/domain/role"
@@ -65,3 +66,25 @@ func TestUserRoleRepository_Revoke(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+// TestUserRoleRepository_FindRolesByUser Test roles are joined through users_roles
+func TestUserRoleRepository_FindRolesByUser(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := UserRoleRepository{db: sqlx.NewDb(db, "postgres")}
+
+	mock.ExpectQuery(`SELECT r.id, r.type, .* FROM goat.public.roles r JOIN goat.public.users_roles ur ON ur.role_id = r.id WHERE ur.user_id = \$1`).
+		WithArgs(user.ID(1)).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "type", "require_two_factor", "creator", "created_at", "updated_at"}).
+				AddRow(1, "admin", true, nil, time.Now(), time.Now()),
+		)
+
+	roles, err := repo.FindRolesByUser(context.Background(), user.ID(1))
+	assert.NoError(t, err)
+	if assert.Len(t, roles, 1) {
+		assert.Equal(t, role.Admin, roles[0].Type)
+		assert.True(t, roles[0].RequireTwoFactor)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -37,3 +37,14 @@ func (r RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.
 func (r RedisCache) Delete(ctx context.Context, key string) error {
 	return r.Client.Del(ctx, key).Err()
 }
+
+func (r RedisCache) Take(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := r.Client.GetDel(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return b, true, nil
+}
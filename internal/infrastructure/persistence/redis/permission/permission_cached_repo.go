@@ -0,0 +1,55 @@
+package permission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/cache"
+)
+
+func rolePermissionsCacheKey(roleType role.Type) string {
+	return fmt.Sprintf("role_permissions:%s:v1", roleType)
+}
+
+// PermissionCachedRepo caches the permissions of each role. Grants only change
+// through migrations, so entries simply expire.
+type PermissionCachedRepo struct {
+	client         cache.Cache
+	permissionRepo permission.Repository
+}
+
+var _ permission.Repository = (*PermissionCachedRepo)(nil)
+
+func NewPermissionCachedRepo(client cache.Cache, permissionRepo permission.Repository) *PermissionCachedRepo {
+	return &PermissionCachedRepo{client: client, permissionRepo: permissionRepo}
+}
+
+func (p PermissionCachedRepo) FindByRole(ctx context.Context, roleType role.Type) ([]permission.Permission, error) {
+	key := rolePermissionsCacheKey(roleType)
+
+	// 1. try cache
+	if b, ok, err := p.client.Get(ctx, key); err == nil && ok {
+		var permissions []permission.Permission
+		if err := json.Unmarshal(b, &permissions); err == nil {
+			return permissions, nil
+		}
+		_ = p.client.Delete(ctx, key)
+	}
+
+	// 2. fallback DB
+	permissions, err := p.permissionRepo.FindByRole(ctx, roleType)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. set cache
+	if b, err := json.Marshal(permissions); err == nil {
+		_ = p.client.Set(ctx, key, b, 5*time.Minute)
+	}
+
+	return permissions, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"time"
 
 	"github.com/HiroLiang/goat-server/internal/domain/security"
@@ -80,3 +81,44 @@ func (r *RedisRateLimitRepository) IncrementSliding(ctx context.Context, key str
 
 	return c.Val(), nil
 }
+
+func (r *RedisRateLimitRepository) Take(ctx context.Context, key string, policy security.RateLimitPolicy, now time.Time) (security.RateLimitResult, error) {
+	// Nothing passes a policy without quota, and the scripts would divide by it
+	if policy.Limit <= 0 {
+		return security.RateLimitResult{Limit: policy.Limit, RetryAfter: max(policy.Window, time.Microsecond)}, nil
+	}
+
+	script, ok := takeScripts[policy.Algorithm]
+	if !ok {
+		return security.RateLimitResult{}, fmt.Errorf("unknown rate limit algorithm %q", policy.Algorithm)
+	}
+
+	// Algorithms keep different types under their keys
+	fullKey := r.prefix + string(policy.Algorithm) + ":" + key
+
+	var args []interface{}
+	switch policy.Algorithm {
+	case security.FixedWindow:
+		args = []interface{}{policy.Window.Microseconds(), policy.Limit}
+	case security.SlidingWindow:
+		member := fmt.Sprintf("%d-%d", now.UnixMicro(), rand.Uint32())
+		args = []interface{}{now.UnixMicro(), policy.Window.Microseconds(), policy.Limit, member}
+	case security.GCRA:
+		args = []interface{}{now.UnixMicro(), policy.Window.Microseconds(), policy.Limit}
+	}
+
+	values, err := script.Run(ctx, r.client, []string{fullKey}, args...).Int64Slice()
+	if err != nil {
+		return security.RateLimitResult{}, err
+	}
+	if len(values) != 4 {
+		return security.RateLimitResult{}, fmt.Errorf("rate limit script returned %d values", len(values))
+	}
+
+	return security.RateLimitResult{
+		Limit:      policy.Limit,
+		Remaining:  values[1],
+		Reset:      time.Duration(values[2]) * time.Microsecond,
+		RetryAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,115 @@
+package security
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestRateLimitRepo(t *testing.T) (*RedisRateLimitRepository, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewRedisRateLimitRepository(client), server
+}
+
+func TestRedisRateLimitRepository_Take_FixedWindow(t *testing.T) {
+	repo, server := newTestRateLimitRepo(t)
+	ctx := context.Background()
+	policy := security.RateLimitPolicy{Limit: 2, Window: time.Minute, Algorithm: security.FixedWindow}
+
+	result, err := repo.Take(ctx, "ip:1", policy, testNow)
+	require.NoError(t, err)
+	assert.Equal(t, security.RateLimitResult{Limit: 2, Remaining: 1, Reset: time.Minute}, result)
+
+	// the window runs on the clock of Redis
+	server.FastForward(10 * time.Second)
+	result, _ = repo.Take(ctx, "ip:1", policy, testNow)
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.Equal(t, 50*time.Second, result.Reset, "the window is not extended")
+
+	server.FastForward(10 * time.Second)
+	result, _ = repo.Take(ctx, "ip:1", policy, testNow)
+	assert.True(t, result.Exceeded())
+	assert.Equal(t, 40*time.Second, result.RetryAfter)
+
+	server.FastForward(40 * time.Second)
+	result, _ = repo.Take(ctx, "ip:1", policy, testNow)
+	assert.False(t, result.Exceeded(), "a new window starts once the old one expired")
+	assert.Equal(t, int64(1), result.Remaining)
+}
+
+func TestRedisRateLimitRepository_Take_SlidingWindow(t *testing.T) {
+	repo, _ := newTestRateLimitRepo(t)
+	ctx := context.Background()
+	policy := security.RateLimitPolicy{Limit: 2, Window: time.Minute, Algorithm: security.SlidingWindow}
+
+	result, err := repo.Take(ctx, "user:1", policy, testNow)
+	require.NoError(t, err)
+	assert.Equal(t, security.RateLimitResult{Limit: 2, Remaining: 1, Reset: time.Minute}, result)
+
+	_, _ = repo.Take(ctx, "user:1", policy, testNow.Add(30*time.Second))
+
+	result, _ = repo.Take(ctx, "user:1", policy, testNow.Add(40*time.Second))
+	assert.True(t, result.Exceeded())
+	assert.Equal(t, 20*time.Second, result.RetryAfter, "retry once the oldest request leaves the window")
+
+	result, _ = repo.Take(ctx, "user:1", policy, testNow.Add(time.Minute))
+	assert.False(t, result.Exceeded())
+	assert.Equal(t, int64(0), result.Remaining)
+}
+
+func TestRedisRateLimitRepository_Take_GCRA(t *testing.T) {
+	repo, _ := newTestRateLimitRepo(t)
+	ctx := context.Background()
+	policy := security.RateLimitPolicy{Limit: 2, Window: time.Minute, Algorithm: security.GCRA}
+
+	result, err := repo.Take(ctx, "user:1", policy, testNow)
+	require.NoError(t, err)
+	assert.Equal(t, security.RateLimitResult{Limit: 2, Remaining: 1, Reset: 30 * time.Second}, result)
+
+	result, _ = repo.Take(ctx, "user:1", policy, testNow)
+	assert.Equal(t, security.RateLimitResult{Limit: 2, Remaining: 0, Reset: time.Minute}, result)
+
+	result, _ = repo.Take(ctx, "user:1", policy, testNow)
+	assert.True(t, result.Exceeded())
+	assert.Equal(t, 30*time.Second, result.RetryAfter, "one request is released per interval")
+
+	result, _ = repo.Take(ctx, "user:1", policy, testNow.Add(30*time.Second))
+	assert.False(t, result.Exceeded())
+}
+
+func TestRedisRateLimitRepository_Take_NoQuota(t *testing.T) {
+	repo, server := newTestRateLimitRepo(t)
+	ctx := context.Background()
+
+	for _, algorithm := range []security.RateLimitAlgorithm{security.FixedWindow, security.SlidingWindow, security.GCRA} {
+		policy := security.RateLimitPolicy{Limit: 0, Window: time.Minute, Algorithm: algorithm}
+
+		result, err := repo.Take(ctx, "user:1", policy, testNow)
+
+		assert.NoError(t, err, algorithm)
+		assert.True(t, result.Exceeded(), algorithm)
+		assert.Equal(t, time.Minute, result.RetryAfter, algorithm)
+	}
+	assert.Empty(t, server.Keys(), "nothing is counted")
+}
+
+func TestRedisRateLimitRepository_Take_UnknownAlgorithm(t *testing.T) {
+	repo, _ := newTestRateLimitRepo(t)
+
+	_, err := repo.Take(context.Background(), "user:1", security.RateLimitPolicy{Limit: 1, Window: time.Minute}, testNow)
+
+	assert.Error(t, err)
+}
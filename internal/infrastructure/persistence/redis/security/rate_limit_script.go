@@ -0,0 +1,92 @@
+package security
+
+import (
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/redis/go-redis/v9"
+)
+
+// The take scripts count a request and return {allowed, remaining, reset, retry after},
+// times in microseconds. Requests over the limit leave the key untouched.
+var takeScripts = map[security.RateLimitAlgorithm]*redis.Script{
+	security.FixedWindow:   fixedWindowScript,
+	security.SlidingWindow: slidingWindowScript,
+	security.GCRA:          gcraScript,
+}
+
+// fixedWindowScript ARGV: window (µs), limit. The window starts with the first
+// request and its TTL is never extended.
+var fixedWindowScript = redis.NewScript(`
+local window = math.ceil(tonumber(ARGV[1]) / 1000)
+local limit = tonumber(ARGV[2])
+
+local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl <= 0 then
+	count = 0
+	ttl = window
+end
+
+if count >= limit then
+	return {0, 0, ttl * 1000, math.max(ttl, 1) * 1000}
+end
+
+if count == 0 then
+	redis.call('SET', KEYS[1], 1, 'PX', window)
+else
+	redis.call('INCR', KEYS[1])
+end
+return {1, limit - count - 1, ttl * 1000, 0}
+`)
+
+// slidingWindowScript ARGV: now (µs), window (µs), limit, member
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], ARGV[1], ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], math.ceil(window / 1000))
+	count = count + 1
+	allowed = 1
+end
+
+local reset, retry = 0, 0
+local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+if newest[2] then
+	reset = tonumber(newest[2]) + window - now
+end
+if allowed == 0 then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	retry = math.max(tonumber(oldest[2]) + window - now, 1)
+end
+return {allowed, math.max(limit - count, 0), reset, retry}
+`)
+
+// gcraScript ARGV: now (µs), window (µs), limit. The key holds the theoretical
+// arrival time of the next request; a request is allowed while that time is no
+// more than one window ahead.
+var gcraScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local interval = window / limit
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or '0')
+if tat < now then
+	tat = now
+end
+
+local next_tat = tat + interval
+local allow_at = next_tat - window
+if allow_at > now then
+	return {0, 0, math.ceil(tat - now), math.max(math.ceil(allow_at - now), 1)}
+end
+
+redis.call('SET', KEYS[1], string.format('%.0f', next_tat), 'PX', math.ceil((next_tat - now) / 1000))
+return {1, math.floor((now - allow_at) / interval), math.ceil(next_tat - now), 0}
+`)
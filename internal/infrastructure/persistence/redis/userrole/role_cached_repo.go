@@ -0,0 +1,33 @@
+package userrole
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/cache"
+)
+
+// RoleCachedRepo keeps the cached user roles in step with role settings.
+// Roles themselves are read straight from the database.
+type RoleCachedRepo struct {
+	client   cache.Cache
+	roleRepo role.Repository
+}
+
+var _ role.Repository = (*RoleCachedRepo)(nil)
+
+func NewRoleCachedRepo(client cache.Cache, roleRepo role.Repository) *RoleCachedRepo {
+	return &RoleCachedRepo{client: client, roleRepo: roleRepo}
+}
+
+func (r RoleCachedRepo) FindAll(ctx context.Context) ([]*role.Role, error) {
+	return r.roleRepo.FindAll(ctx)
+}
+
+// SetRequireTwoFactor updates the role and bumps the cached user roles so the change applies at once.
+func (r RoleCachedRepo) SetRequireTwoFactor(ctx context.Context, roleType role.Type, required bool) error {
+	if err := r.roleRepo.SetRequireTwoFactor(ctx, roleType, required); err != nil {
+		return err
+	}
+	return bumpUserRolesVersion(ctx, r.client)
+}
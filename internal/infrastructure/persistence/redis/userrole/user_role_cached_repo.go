@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/HiroLiang/goat-server/internal/domain/role"
@@ -12,8 +13,28 @@ import (
 	"github.com/HiroLiang/goat-server/internal/infrastructure/cache"
 )
 
-func userRolesCacheKey(userID user.ID) string {
-	return fmt.Sprintf("user_roles:%d:v1", userID)
+// userRolesVersionKey holds the generation of the cached roles. Role settings
+// are embedded in every entry, so changing one bumps the generation instead of
+// hunting down each user's key.
+const userRolesVersionKey = "user_roles:version"
+
+func userRolesCacheKey(userID user.ID, version string) string {
+	return fmt.Sprintf("user_roles:%d:v1:%s", userID, version)
+}
+
+// userRolesVersion returns the current generation, empty until the first bump.
+func userRolesVersion(ctx context.Context, client cache.Cache) string {
+	b, ok, err := client.Get(ctx, userRolesVersionKey)
+	if err != nil || !ok {
+		return ""
+	}
+	return string(b)
+}
+
+// bumpUserRolesVersion moves every user to a fresh key, the old entries expire on their own.
+func bumpUserRolesVersion(ctx context.Context, client cache.Cache) error {
+	version := strconv.FormatInt(time.Now().UnixNano(), 36)
+	return client.Set(ctx, userRolesVersionKey, []byte(version), 0)
 }
 
 type UserRoleCachedRepo struct {
@@ -28,7 +49,7 @@ func NewUserRoleCachedRepo(client cache.Cache, userRoleRepo userrole.Repository)
 }
 
 func (u UserRoleCachedRepo) FindRolesByUser(ctx context.Context, userID user.ID) ([]*role.Role, error) {
-	key := userRolesCacheKey(userID)
+	key := userRolesCacheKey(userID, userRolesVersion(ctx, u.client))
 
 	// 1. try cache
 	if b, ok, err := u.client.Get(ctx, key); err == nil && ok {
@@ -51,16 +72,35 @@ func (u UserRoleCachedRepo) FindRolesByUser(ctx context.Context, userID user.ID)
 	return roles, nil
 }
 
-func (u UserRoleCachedRepo) Exists(ctx context.Context, userID user.ID, role role.Type) bool {
-	return u.userRoleRepo.Exists(ctx, userID, role)
+// Exists answers from the cached roles so authorization checks avoid the database.
+func (u UserRoleCachedRepo) Exists(ctx context.Context, userID user.ID, roleType role.Type) bool {
+	roles, err := u.FindRolesByUser(ctx, userID)
+	if err != nil {
+		return false
+	}
+
+	for _, r := range roles {
+		if r.Type == roleType {
+			return true
+		}
+	}
+	return false
 }
 
+// Assign assigns the role and drops the cached roles so the change applies at once.
 func (u UserRoleCachedRepo) Assign(ctx context.Context, userID user.ID, role role.Type) error {
-	return u.userRoleRepo.Assign(ctx, userID, role)
+	if err := u.userRoleRepo.Assign(ctx, userID, role); err != nil {
+		return err
+	}
+	return u.client.Delete(ctx, userRolesCacheKey(userID, userRolesVersion(ctx, u.client)))
 }
 
+// Revoke revokes the role and drops the cached roles so the change applies at once.
 func (u UserRoleCachedRepo) Revoke(ctx context.Context, userID user.ID, role role.Type) error {
-	return u.userRoleRepo.Revoke(ctx, userID, role)
+	if err := u.userRoleRepo.Revoke(ctx, userID, role); err != nil {
+		return err
+	}
+	return u.client.Delete(ctx, userRolesCacheKey(userID, userRolesVersion(ctx, u.client)))
 }
 
 func encodeRoles(roles []*role.Role) []byte {
@@ -0,0 +1,161 @@
+package userrole
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type memCache struct {
+	values map[string][]byte
+}
+
+func (m *memCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	v, ok := m.values[key]
+	return v, ok, nil
+}
+
+func (m *memCache) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	m.values[key] = value
+	return nil
+}
+
+func (m *memCache) Delete(_ context.Context, key string) error {
+	delete(m.values, key)
+	return nil
+}
+
+func (m *memCache) Take(_ context.Context, key string) ([]byte, bool, error) {
+	v, ok := m.values[key]
+	delete(m.values, key)
+	return v, ok, nil
+}
+
+// memRoles counts the lookups that reach the underlying repository
+type memRoles struct {
+	roles     map[user.ID][]role.Type
+	twoFactor map[role.Type]bool
+	lookups   int
+}
+
+func (m *memRoles) FindRolesByUser(_ context.Context, userID user.ID) ([]*role.Role, error) {
+	m.lookups++
+	roles := make([]*role.Role, 0, len(m.roles[userID]))
+	for _, t := range m.roles[userID] {
+		roles = append(roles, &role.Role{Type: t, RequireTwoFactor: m.twoFactor[t]})
+	}
+	return roles, nil
+}
+
+func (m *memRoles) Exists(_ context.Context, userID user.ID, roleType role.Type) bool {
+	for _, t := range m.roles[userID] {
+		if t == roleType {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memRoles) Assign(_ context.Context, userID user.ID, roleType role.Type) error {
+	m.roles[userID] = append(m.roles[userID], roleType)
+	return nil
+}
+
+func (m *memRoles) Revoke(_ context.Context, userID user.ID, roleType role.Type) error {
+	kept := m.roles[userID][:0]
+	for _, t := range m.roles[userID] {
+		if t != roleType {
+			kept = append(kept, t)
+		}
+	}
+	m.roles[userID] = kept
+	return nil
+}
+
+func TestUserRoleCachedRepo_ExistsUsesCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &memRoles{roles: map[user.ID][]role.Type{1: {role.User}}}
+	repo := NewUserRoleCachedRepo(&memCache{values: map[string][]byte{}}, inner)
+
+	if !repo.Exists(ctx, 1, role.User) {
+		t.Fatal("Exists(user) = false, want true")
+	}
+	if repo.Exists(ctx, 1, role.Admin) {
+		t.Fatal("Exists(admin) = true, want false")
+	}
+	if inner.lookups != 1 {
+		t.Errorf("lookups = %d, want 1", inner.lookups)
+	}
+}
+
+func TestUserRoleCachedRepo_AssignAndRevokeInvalidate(t *testing.T) {
+	ctx := context.Background()
+	inner := &memRoles{roles: map[user.ID][]role.Type{1: {role.User}}}
+	repo := NewUserRoleCachedRepo(&memCache{values: map[string][]byte{}}, inner)
+
+	if repo.Exists(ctx, 1, role.Admin) {
+		t.Fatal("Exists(admin) before assign = true, want false")
+	}
+
+	if err := repo.Assign(ctx, 1, role.Admin); err != nil {
+		t.Fatalf("Assign() error = %v", err)
+	}
+	if !repo.Exists(ctx, 1, role.Admin) {
+		t.Error("Exists(admin) after assign = false, want true")
+	}
+
+	if err := repo.Revoke(ctx, 1, role.Admin); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if repo.Exists(ctx, 1, role.Admin) {
+		t.Error("Exists(admin) after revoke = true, want false")
+	}
+}
+
+// memRoleSettings writes the role settings memRoles reads
+type memRoleSettings struct {
+	roles *memRoles
+}
+
+func (m memRoleSettings) FindAll(_ context.Context) ([]*role.Role, error) {
+	return nil, nil
+}
+
+func (m memRoleSettings) SetRequireTwoFactor(_ context.Context, roleType role.Type, required bool) error {
+	m.roles.twoFactor[roleType] = required
+	return nil
+}
+
+func TestRoleCachedRepo_SetRequireTwoFactorInvalidates(t *testing.T) {
+	ctx := context.Background()
+	client := &memCache{values: map[string][]byte{}}
+	inner := &memRoles{roles: map[user.ID][]role.Type{1: {role.Admin}}, twoFactor: map[role.Type]bool{}}
+	userRoles := NewUserRoleCachedRepo(client, inner)
+	roles := NewRoleCachedRepo(client, memRoleSettings{roles: inner})
+
+	before, err := userRoles.FindRolesByUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindRolesByUser() error = %v", err)
+	}
+	if before[0].RequireTwoFactor {
+		t.Fatal("RequireTwoFactor before update = true, want false")
+	}
+
+	if err := roles.SetRequireTwoFactor(ctx, role.Admin, true); err != nil {
+		t.Fatalf("SetRequireTwoFactor() error = %v", err)
+	}
+
+	after, err := userRoles.FindRolesByUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindRolesByUser() error = %v", err)
+	}
+	if !after[0].RequireTwoFactor {
+		t.Error("RequireTwoFactor after update = false, want true")
+	}
+	if inner.lookups != 2 {
+		t.Errorf("lookups = %d, want 2", inner.lookups)
+	}
+}
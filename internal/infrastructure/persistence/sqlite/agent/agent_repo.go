@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var Table = sqlite.Table{
+	Name: "agents",
+	Columns: []string{
+		"id",
+		"name",
+		"type",
+		"status",
+		"engine",
+		"created_at",
+		"created_by",
+		"updated_at",
+		"updated_by",
+	},
+}
+
+type AgentRepository struct {
+	db *sqlx.DB
+}
+
+var _ agent.Repository = (*AgentRepository)(nil)
+
+func NewAgentRepository(db *sqlx.DB) *AgentRepository {
+	return &AgentRepository{db: db}
+}
+
+// FindAll returns all agents.
+func (r AgentRepository) FindAll(ctx context.Context) ([]*agent.Agent, error) {
+	return r.find(ctx, nil)
+}
+
+// FindAllByStatus returns all agents by status.
+func (r AgentRepository) FindAllByStatus(
+	ctx context.Context,
+	status agent.Status,
+) ([]*agent.Agent, error) {
+	return r.find(ctx, squirrel.Eq{"status": status})
+}
+
+// FindByID returns the agent with id.
+func (r AgentRepository) FindByID(ctx context.Context, id agent.ID) (*agent.Agent, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"id": id}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build agent query: %w", err)
+	}
+
+	rec, err := sqlite.ScanOne[AgentRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrNotFound) {
+			return nil, agent.ErrNotFound
+		}
+		return nil, fmt.Errorf("find agent: %w", err)
+	}
+
+	return toDomain(rec)
+}
+
+// Create inserts a new agent and fills its ID.
+func (r AgentRepository) Create(ctx context.Context, agent *agent.Agent) error {
+	record := toRecord(agent)
+
+	query, args, err := Table.Insert().
+		Columns("name", "type", "status", "engine", "created_by", "updated_by").
+		Values(record.Name, record.Type, record.Status, record.Engine, record.CreatedBy, record.UpdatedBy).
+		Suffix("RETURNING id, created_at, updated_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build insert agent: %w", err)
+	}
+
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).
+		Scan(&agent.ID, &agent.CreatedAt, &agent.UpdatedAt); err != nil {
+		return fmt.Errorf("insert agent: %w", err)
+	}
+
+	return nil
+}
+
+// find returns agents by condition.
+func (r AgentRepository) find(
+	ctx context.Context,
+	cond squirrel.Sqlizer,
+) ([]*agent.Agent, error) {
+
+	builder := Table.Select(Table.Columns...)
+	if cond != nil {
+		builder = builder.Where(cond)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sql: %w", err)
+	}
+
+	records, err := sqlite.ScanAll[AgentRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan agents: %w", err)
+	}
+
+	agents := make([]*agent.Agent, 0, len(records))
+	for _, rec := range records {
+		domain, err := toDomain(&rec)
+		if err != nil {
+			return nil, fmt.Errorf("convert agent: %w", err)
+		}
+		agents = append(agents, domain)
+	}
+
+	return agents, nil
+}
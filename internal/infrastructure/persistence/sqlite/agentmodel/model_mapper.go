@@ -0,0 +1,41 @@
+package agentmodel
+
+import (
+	"database/sql"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+)
+
+func toDomain(rec *ModelRecord) (*agentmodel.Model, error) {
+	return &agentmodel.Model{
+		ID:            rec.ID,
+		Provider:      rec.Provider,
+		Name:          rec.Name,
+		Family:        rec.Family,
+		ParameterSize: rec.ParameterSize,
+		Quantization:  rec.Quantization,
+		SizeBytes:     rec.SizeBytes,
+		Digest:        rec.Digest,
+		ModifiedAt:    rec.ModifiedAt.Time,
+		IsAvailable:   rec.IsAvailable,
+		SyncedAt:      rec.SyncedAt,
+		CreatedAt:     rec.CreatedAt,
+	}, nil
+}
+
+func toRecord(m *agentmodel.Model) *ModelRecord {
+	return &ModelRecord{
+		ID:            m.ID,
+		Provider:      m.Provider,
+		Name:          m.Name,
+		Family:        m.Family,
+		ParameterSize: m.ParameterSize,
+		Quantization:  m.Quantization,
+		SizeBytes:     m.SizeBytes,
+		Digest:        m.Digest,
+		ModifiedAt:    sql.NullTime{Time: m.ModifiedAt, Valid: !m.ModifiedAt.IsZero()},
+		IsAvailable:   m.IsAvailable,
+		SyncedAt:      m.SyncedAt,
+		CreatedAt:     m.CreatedAt,
+	}
+}
@@ -0,0 +1,153 @@
+package agentmodel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var Table = sqlite.Table{
+	Name: "agent_models",
+	Columns: []string{
+		"id",
+		"provider",
+		"name",
+		"family",
+		"parameter_size",
+		"quantization",
+		"size_bytes",
+		"digest",
+		"modified_at",
+		"is_available",
+		"synced_at",
+		"created_at",
+	},
+}
+
+type ModelRepository struct {
+	db *sqlx.DB
+}
+
+var _ agentmodel.Repository = (*ModelRepository)(nil)
+
+func NewModelRepository(db *sqlx.DB) *ModelRepository {
+	return &ModelRepository{db: db}
+}
+
+// FindAll returns the whole catalog ordered by provider and name.
+func (r *ModelRepository) FindAll(ctx context.Context) ([]*agentmodel.Model, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		OrderBy("provider ASC", "name ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build models query: %w", err)
+	}
+
+	records, err := sqlite.ScanAll[ModelRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan models: %w", err)
+	}
+
+	models := make([]*agentmodel.Model, 0, len(records))
+	for _, rec := range records {
+		m, err := toDomain(&rec)
+		if err != nil {
+			return nil, fmt.Errorf("convert model: %w", err)
+		}
+		models = append(models, m)
+	}
+
+	return models, nil
+}
+
+func (r *ModelRepository) FindByID(ctx context.Context, id agentmodel.ID) (*agentmodel.Model, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.Eq{"id": id}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build model query: %w", err)
+	}
+
+	rec, err := sqlite.ScanOne[ModelRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrNotFound) {
+			return nil, agentmodel.ErrNotFound
+		}
+		return nil, fmt.Errorf("find model: %w", err)
+	}
+
+	return toDomain(rec)
+}
+
+// Upsert inserts the model or refreshes the entry with the same provider and name.
+func (r *ModelRepository) Upsert(ctx context.Context, m *agentmodel.Model) error {
+	rec := toRecord(m)
+
+	query, args, err := Table.Insert().
+		Columns(
+			"provider",
+			"name",
+			"family",
+			"parameter_size",
+			"quantization",
+			"size_bytes",
+			"digest",
+			"modified_at",
+			"is_available",
+		).
+		Values(
+			rec.Provider,
+			rec.Name,
+			rec.Family,
+			rec.ParameterSize,
+			rec.Quantization,
+			rec.SizeBytes,
+			rec.Digest,
+			rec.ModifiedAt,
+			true,
+		).
+		Suffix(`ON CONFLICT (provider, name) DO UPDATE SET
+			family = EXCLUDED.family,
+			parameter_size = EXCLUDED.parameter_size,
+			quantization = EXCLUDED.quantization,
+			size_bytes = EXCLUDED.size_bytes,
+			digest = EXCLUDED.digest,
+			modified_at = EXCLUDED.modified_at,
+			is_available = TRUE,
+			synced_at = ` + sqlite.Now + `
+		RETURNING id`).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build upsert model: %w", err)
+	}
+
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&m.ID); err != nil {
+		return fmt.Errorf("upsert model: %w", err)
+	}
+
+	return nil
+}
+
+// MarkMissing flags every model of provider not listed in present as unavailable.
+func (r *ModelRepository) MarkMissing(ctx context.Context, provider string, present []string) error {
+	query, args, err := Table.Update().
+		Set("is_available", false).
+		Set("synced_at", squirrel.Expr(sqlite.Now)).
+		Where(squirrel.And{
+			squirrel.Eq{"provider": provider, "is_available": true},
+			squirrel.NotEq{"name": present},
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build mark missing models: %w", err)
+	}
+
+	return sqlite.Exec(ctx, r.db, query, args...)
+}
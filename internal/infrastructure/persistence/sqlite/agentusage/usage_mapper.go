@@ -0,0 +1,31 @@
+package agentusage
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agentusage"
+)
+
+func toDomain(rec *UsageRecord) (*agentusage.Usage, error) {
+	return &agentusage.Usage{
+		UserID:           rec.UserID,
+		AgentID:          rec.AgentID,
+		Date:             agentusage.Day(rec.UsageDate),
+		PromptTokens:     rec.PromptTokens,
+		CompletionTokens: rec.CompletionTokens,
+		RequestCount:     rec.RequestCount,
+		TotalLatency:     time.Duration(rec.TotalLatencyMs) * time.Millisecond,
+	}, nil
+}
+
+func toRecord(u *agentusage.Usage) *UsageRecord {
+	return &UsageRecord{
+		UserID:           u.UserID,
+		AgentID:          u.AgentID,
+		UsageDate:        agentusage.Day(u.Date),
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		RequestCount:     u.RequestCount,
+		TotalLatencyMs:   u.TotalLatency.Milliseconds(),
+	}
+}
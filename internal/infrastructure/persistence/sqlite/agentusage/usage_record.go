@@ -0,0 +1,18 @@
+package agentusage
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type UsageRecord struct {
+	UserID           user.ID   `db:"user_id"`
+	AgentID          agent.ID  `db:"agent_id"`
+	UsageDate        time.Time `db:"usage_date"`
+	PromptTokens     int64     `db:"prompt_tokens"`
+	CompletionTokens int64     `db:"completion_tokens"`
+	RequestCount     int64     `db:"request_count"`
+	TotalLatencyMs   int64     `db:"total_latency_ms"`
+}
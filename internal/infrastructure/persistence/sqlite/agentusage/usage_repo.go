@@ -0,0 +1,136 @@
+package agentusage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agentusage"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var Table = sqlite.Table{
+	Name: "agent_usages",
+	Columns: []string{
+		"user_id",
+		"agent_id",
+		"usage_date",
+		"prompt_tokens",
+		"completion_tokens",
+		"request_count",
+		"total_latency_ms",
+	},
+}
+
+type UsageRepository struct {
+	db *sqlx.DB
+}
+
+var _ agentusage.Repository = (*UsageRepository)(nil)
+
+func NewUsageRepository(db *sqlx.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// Record upserts the usage, accumulating it into the existing daily bucket.
+func (r *UsageRepository) Record(ctx context.Context, u *agentusage.Usage) error {
+	rec := toRecord(u)
+
+	query, args, err := Table.Insert().
+		Columns(Table.Columns...).
+		Values(
+			rec.UserID,
+			rec.AgentID,
+			rec.UsageDate,
+			rec.PromptTokens,
+			rec.CompletionTokens,
+			rec.RequestCount,
+			rec.TotalLatencyMs,
+		).
+		Suffix(`ON CONFLICT (user_id, agent_id, usage_date) DO UPDATE SET
+			prompt_tokens = agent_usages.prompt_tokens + EXCLUDED.prompt_tokens,
+			completion_tokens = agent_usages.completion_tokens + EXCLUDED.completion_tokens,
+			request_count = agent_usages.request_count + EXCLUDED.request_count,
+			total_latency_ms = agent_usages.total_latency_ms + EXCLUDED.total_latency_ms,
+			updated_at = ` + sqlite.Now).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build record usage: %w", err)
+	}
+
+	return sqlite.Exec(ctx, r.db, query, args...)
+}
+
+// SumByUserAndDay returns the usage of the user across all agents on day.
+func (r *UsageRepository) SumByUserAndDay(
+	ctx context.Context,
+	userID user.ID,
+	day time.Time,
+) (*agentusage.Usage, error) {
+	query, args, err := sqlite.Builder.
+		Select(
+			"user_id",
+			"0 AS agent_id",
+			"usage_date",
+			"SUM(prompt_tokens) AS prompt_tokens",
+			"SUM(completion_tokens) AS completion_tokens",
+			"SUM(request_count) AS request_count",
+			"SUM(total_latency_ms) AS total_latency_ms",
+		).
+		From(Table.Name).
+		Where(squirrel.Eq{"user_id": userID, "usage_date": agentusage.Day(day)}).
+		GroupBy("user_id", "usage_date").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build sum usage query: %w", err)
+	}
+
+	rec, err := sqlite.ScanOne[UsageRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrNotFound) {
+			return &agentusage.Usage{UserID: userID, Date: agentusage.Day(day)}, nil
+		}
+		return nil, fmt.Errorf("sum usage: %w", err)
+	}
+
+	return toDomain(rec)
+}
+
+// FindByUser returns the per-agent, per-day usage of the user within [from, to].
+func (r *UsageRepository) FindByUser(
+	ctx context.Context,
+	userID user.ID,
+	from, to time.Time,
+) ([]*agentusage.Usage, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		Where(squirrel.And{
+			squirrel.Eq{"user_id": userID},
+			squirrel.GtOrEq{"usage_date": agentusage.Day(from)},
+			squirrel.LtOrEq{"usage_date": agentusage.Day(to)},
+		}).
+		OrderBy("usage_date ASC", "agent_id ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build usage query: %w", err)
+	}
+
+	records, err := sqlite.ScanAll[UsageRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan usages: %w", err)
+	}
+
+	usages := make([]*agentusage.Usage, 0, len(records))
+	for _, rec := range records {
+		u, err := toDomain(&rec)
+		if err != nil {
+			return nil, fmt.Errorf("convert usage: %w", err)
+		}
+		usages = append(usages, u)
+	}
+
+	return usages, nil
+}
@@ -0,0 +1,53 @@
+package agentusage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentusage"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUsageRepository_AccumulatesDays Test usage adds up per day on a real database
+func TestUsageRepository_AccumulatesDays(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	db := testutil.OpenDB(t)
+	_, err := db.ExecContext(ctx, `INSERT INTO users (id, name, email, password, user_status, user_ip)
+		VALUES (1, 'alice', 'a@b.com', 'x', 'active', '127.0.0.1')`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO agents (id, name, type, engine) VALUES (2, 'helper', 'remote', 'api'), (3, 'coder', 'remote', 'api')`)
+	require.NoError(t, err)
+
+	repo := NewUsageRepository(db)
+	morning := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	for _, u := range []struct {
+		agentID      agent.ID
+		prompt, done int64
+		at           time.Time
+	}{
+		{2, 10, 5, morning},
+		{2, 20, 5, morning.Add(time.Hour)},
+		{3, 1, 1, morning.Add(2 * time.Hour)},
+		{2, 7, 7, morning.Add(24 * time.Hour)},
+	} {
+		usage, err := agentusage.NewUsage(1, u.agentID, u.prompt, u.done, time.Second, u.at)
+		require.NoError(t, err)
+		require.NoError(t, repo.Record(ctx, usage))
+	}
+
+	sum, err := repo.SumByUserAndDay(ctx, 1, morning)
+	require.NoError(t, err)
+	assert.Equal(t, int64(31), sum.PromptTokens)
+	assert.Equal(t, int64(11), sum.CompletionTokens)
+	assert.Equal(t, int64(3), sum.RequestCount)
+
+	usages, err := repo.FindByUser(ctx, 1, morning, morning.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, usages, 3)
+}
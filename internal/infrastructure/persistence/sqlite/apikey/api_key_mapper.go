@@ -0,0 +1,42 @@
+package apikey
+
+import (
+	"strings"
+
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+)
+
+func toDomain(record *APIKeyRecord) *apikey.APIKey {
+	fields := strings.Fields(record.Scopes)
+	scopes := make([]apikey.Scope, 0, len(fields))
+	for _, f := range fields {
+		scopes = append(scopes, apikey.Scope(f))
+	}
+
+	k := &apikey.APIKey{
+		ID:         record.ID,
+		UserID:     record.UserID,
+		Name:       record.Name,
+		Prefix:     record.Prefix,
+		Hash:       record.KeyHash,
+		Scopes:     scopes,
+		ExpiresAt:  record.ExpiresAt,
+		LastUsedIP: record.LastUsedIP.String,
+		CreatedAt:  record.CreatedAt,
+	}
+	if record.LastUsedAt.Valid {
+		k.LastUsedAt = &record.LastUsedAt.Time
+	}
+	if record.RevokedAt.Valid {
+		k.RevokedAt = &record.RevokedAt.Time
+	}
+	return k
+}
+
+func joinScopes(scopes []apikey.Scope) string {
+	parts := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		parts = append(parts, string(s))
+	}
+	return strings.Join(parts, " ")
+}
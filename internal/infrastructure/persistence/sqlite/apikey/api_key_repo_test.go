@@ -0,0 +1,49 @@
+package apikey
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAPIKeyRepository_FindByHash Test scopes are split from the stored column
+func TestAPIKeyRepository_FindByHash(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := APIKeyRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, last_used_ip, revoked_at, created_at FROM api_keys WHERE key_hash = \?`).
+		WithArgs("h").
+		WillReturnRows(sqlmock.NewRows(Table.Columns).
+			AddRow(1, 2, "ci", "goat_abcdef", "h", "chat:read agent:write", now, nil, nil, nil, now))
+
+	key, err := repo.FindByHash(context.Background(), "h")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID(2), key.UserID)
+	assert.Equal(t, []apikey.Scope{apikey.ChatRead, apikey.AgentWrite}, key.Scopes)
+	assert.Nil(t, key.LastUsedAt)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestAPIKeyRepository_Revoke_NotFound Test revoking a key of another user
+func TestAPIKeyRepository_Revoke_NotFound(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := APIKeyRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectExec(`UPDATE api_keys SET revoked_at = strftime\(.*\) WHERE id = \? AND revoked_at IS NULL AND user_id = \?`).
+		WithArgs(int64(5), user.ID(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Revoke(context.Background(), 1, 5)
+	assert.ErrorIs(t, err, apikey.ErrAPIKeyNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,29 @@
+package sqlite
+
+import "github.com/Masterminds/squirrel"
+
+var Builder = squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question)
+
+// Now the current UTC time in the layout the driver writes times with, so stored times compare as text
+const Now = "strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')"
+
+type Table struct {
+	Name    string
+	Columns []string
+}
+
+func (t Table) Select(columns ...string) squirrel.SelectBuilder {
+	return Builder.Select(columns...).From(t.Name)
+}
+
+func (t Table) Insert() squirrel.InsertBuilder {
+	return Builder.Insert(t.Name)
+}
+
+func (t Table) Update() squirrel.UpdateBuilder {
+	return Builder.Update(t.Name)
+}
+
+func (t Table) Delete() squirrel.DeleteBuilder {
+	return Builder.Delete(t.Name)
+}
@@ -0,0 +1,61 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/chatmember"
+)
+
+func toChatMemberDomain(rec *ChatMemberRecord) (*chatmember.ChatMember, error) {
+	var keywords []string
+	if rec.Keywords != "" {
+		if err := json.Unmarshal([]byte(rec.Keywords), &keywords); err != nil {
+			return nil, fmt.Errorf("decode member keywords: %w", err)
+		}
+	}
+
+	return &chatmember.ChatMember{
+		ID:             rec.ID,
+		GroupID:        rec.GroupID,
+		ParticipantID:  rec.ParticipantID,
+		Role:           rec.Role,
+		JoinedAt:       rec.JoinedAt,
+		IsArchived:     rec.IsArchived,
+		IsMuted:        rec.IsMuted,
+		IsPinned:       rec.IsPinned,
+		LastReadAt:     rec.LastReadAt,
+		UpdatedAt:      rec.UpdatedAt,
+		ResponsePolicy: rec.ResponsePolicy,
+		Keywords:       keywords,
+	}, nil
+}
+
+func toChatMemberRecord(m *chatmember.ChatMember) *ChatMemberRecord {
+	keywords := m.Keywords
+	if keywords == nil {
+		keywords = []string{}
+	}
+	// marshalling a string slice cannot fail
+	encoded, _ := json.Marshal(keywords)
+
+	policy := m.ResponsePolicy
+	if policy == "" {
+		policy = chatmember.RespondOnMention
+	}
+
+	return &ChatMemberRecord{
+		ID:             m.ID,
+		GroupID:        m.GroupID,
+		ParticipantID:  m.ParticipantID,
+		Role:           m.Role,
+		JoinedAt:       m.JoinedAt,
+		IsArchived:     m.IsArchived,
+		IsMuted:        m.IsMuted,
+		IsPinned:       m.IsPinned,
+		LastReadAt:     m.LastReadAt,
+		UpdatedAt:      m.UpdatedAt,
+		ResponsePolicy: policy,
+		Keywords:       string(encoded),
+	}
+}
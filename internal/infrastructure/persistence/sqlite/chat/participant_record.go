@@ -0,0 +1,19 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/participant"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type ParticipantRecord struct {
+	ID          participant.ID              `db:"id"`
+	Type        participant.ParticipantType `db:"type"`
+	UserID      *user.ID                    `db:"user_id"`
+	AgentID     *agent.ID                   `db:"agent_id"`
+	DisplayName string                      `db:"display_name"`
+	AvatarURL   string                      `db:"avatar_url"`
+	CreatedAt   time.Time                   `db:"created_at"`
+}
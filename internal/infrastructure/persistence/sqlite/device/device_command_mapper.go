@@ -0,0 +1,27 @@
+package device
+
+import (
+	"encoding/json"
+
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+)
+
+func toCommandDomain(rec *DeviceCommandRecord) *devicecommand.Command {
+	c := &devicecommand.Command{
+		ID:          rec.ID,
+		DeviceID:    rec.DeviceID,
+		UserID:      rec.UserID,
+		Type:        rec.Type,
+		Payload:     json.RawMessage(rec.Payload),
+		Status:      devicecommand.Status(rec.Status),
+		Error:       rec.Error,
+		ExpiresAt:   rec.ExpiresAt,
+		DeliveredAt: rec.DeliveredAt,
+		CompletedAt: rec.CompletedAt,
+		CreatedAt:   rec.CreatedAt,
+	}
+	if rec.Result.Valid {
+		c.Result = json.RawMessage(rec.Result.String)
+	}
+	return c
+}
@@ -0,0 +1,25 @@
+package device
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type DeviceCommandRecord struct {
+	ID          devicecommand.ID `db:"id"`
+	DeviceID    device.ID        `db:"device_id"`
+	UserID      user.ID          `db:"user_id"`
+	Type        string           `db:"command_type"`
+	Payload     string           `db:"payload"` // JSON object
+	Status      string           `db:"status"`
+	Result      sql.NullString   `db:"result"` // JSON value
+	Error       string           `db:"error"`
+	ExpiresAt   time.Time        `db:"expires_at"`
+	DeliveredAt *time.Time       `db:"delivered_at"`
+	CompletedAt *time.Time       `db:"completed_at"`
+	CreatedAt   time.Time        `db:"created_at"`
+}
@@ -0,0 +1,81 @@
+package device
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDeviceCommandRepository_Create Test the payload is stored as JSON text
+func TestDeviceCommandRepository_Create(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := DeviceCommandRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	mock.ExpectQuery(`INSERT INTO device_commands \(device_id,user_id,command_type,payload,status,expires_at\) VALUES \(\?,\?,\?,\?,\?,\?\) RETURNING id, created_at`).
+		WithArgs(device.ID(3), int64(1), "gpio.write", `{"pin":17}`, "pending", expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(9, now))
+
+	c := &devicecommand.Command{
+		DeviceID:  3,
+		UserID:    1,
+		Type:      "gpio.write",
+		Payload:   json.RawMessage(`{"pin":17}`),
+		Status:    devicecommand.Pending,
+		ExpiresAt: expiresAt,
+	}
+	err := repo.Create(context.Background(), c)
+	assert.NoError(t, err)
+	assert.Equal(t, devicecommand.ID(9), c.ID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDeviceCommandRepository_Complete_Closed Test a second acknowledgement updates nothing
+func TestDeviceCommandRepository_Complete_Closed(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := DeviceCommandRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	now := time.Now()
+	mock.ExpectExec(`UPDATE device_commands SET status = \?, result = \?, error = \?, completed_at = \? WHERE id = \? AND status IN \(\?,\?\) AND expires_at > \?`).
+		WithArgs("succeeded", sql.NullString{String: `{"ok":true}`, Valid: true}, "", &now, devicecommand.ID(9), "pending", "delivered", &now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	c := &devicecommand.Command{
+		ID:          9,
+		Status:      devicecommand.Succeeded,
+		Result:      json.RawMessage(`{"ok":true}`),
+		CompletedAt: &now,
+	}
+	err := repo.Complete(context.Background(), c)
+	assert.ErrorIs(t, err, devicecommand.ErrCommandClosed)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDeviceCommandRepository_ExpireDue Test open commands past their expiry are expired
+func TestDeviceCommandRepository_ExpireDue(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := DeviceCommandRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	now := time.Now()
+	mock.ExpectExec(`UPDATE device_commands SET status = \? WHERE status IN \(\?,\?\) AND expires_at <= \?`).
+		WithArgs("expired", "pending", "delivered", now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	n, err := repo.ExpireDue(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
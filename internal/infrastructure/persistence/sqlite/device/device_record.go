@@ -0,0 +1,22 @@
+package device
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type DeviceRecord struct {
+	ID         device.ID      `db:"id"`
+	UserID     user.ID        `db:"user_id"`
+	DeviceID   string         `db:"device_id"`
+	Name       string         `db:"name"`
+	Platform   string         `db:"platform"`
+	SessionID  string         `db:"session_id"`
+	PushToken  sql.NullString `db:"push_token"`
+	SecretHash sql.NullString `db:"secret_hash"`
+	CreatedAt  time.Time      `db:"created_at"`
+	UpdatedAt  time.Time      `db:"updated_at"`
+}
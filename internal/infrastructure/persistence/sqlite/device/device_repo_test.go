@@ -0,0 +1,82 @@
+package device
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDeviceRepository_Register Test registering again updates the device of the user
+func TestDeviceRepository_Register(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := DeviceRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	now := time.Now()
+	mock.ExpectQuery(`INSERT INTO devices \(user_id,device_id,name,platform,session_id,push_token,secret_hash\) VALUES \(\?,\?,\?,\?,\?,\?,\?\) ON CONFLICT \(user_id, device_id\) DO UPDATE SET`).
+		WithArgs(user.ID(1), "pixel-7", "Phone", "android", "s1", sql.NullString{}, sql.NullString{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(3, now, now))
+
+	d := &device.Device{UserID: 1, DeviceID: "pixel-7", Name: "Phone", Platform: device.Android, SessionID: "s1"}
+	err := repo.Register(context.Background(), d)
+	assert.NoError(t, err)
+	assert.Equal(t, device.ID(3), d.ID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDeviceRepository_Delete_NotFound Test deleting a device of another user
+func TestDeviceRepository_Delete_NotFound(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := DeviceRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectExec(`DELETE FROM devices WHERE id = \? AND user_id = \?`).
+		WithArgs(device.ID(5), user.ID(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Delete(context.Background(), 1, 5)
+	assert.ErrorIs(t, err, device.ErrDeviceNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDeviceRepository_FindPushTargets Test only devices with a push token are targets
+func TestDeviceRepository_FindPushTargets(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := DeviceRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT id, user_id, device_id, name, platform, session_id, push_token, secret_hash, created_at, updated_at FROM devices WHERE user_id IN \(\?,\?\) AND push_token IS NOT NULL`).
+		WithArgs(user.ID(1), user.ID(2)).
+		WillReturnRows(sqlmock.NewRows(Table.Columns).
+			AddRow(3, 2, "pixel-7", "Phone", "android", "s1", "fcm-token", nil, now, now))
+
+	devices, err := repo.FindPushTargets(context.Background(), []user.ID{1, 2})
+	assert.NoError(t, err)
+	assert.Len(t, devices, 1)
+	assert.Equal(t, "fcm-token", devices[0].PushToken)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestDeviceRepository_FindBySecretHash Test an unknown secret finds no device
+func TestDeviceRepository_FindBySecretHash(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := DeviceRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectQuery(`SELECT .* FROM devices WHERE secret_hash = \?`).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(Table.Columns))
+
+	_, err := repo.FindBySecretHash(context.Background(), "hash")
+	assert.ErrorIs(t, err, device.ErrDeviceNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
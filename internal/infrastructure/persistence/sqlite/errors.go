@@ -0,0 +1,7 @@
+package sqlite
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("record not found")
+)
@@ -0,0 +1,14 @@
+package identity
+
+import "github.com/HiroLiang/goat-server/internal/domain/identity"
+
+func toDomain(record *IdentityRecord) *identity.Identity {
+	return &identity.Identity{
+		ID:        record.ID,
+		UserID:    record.UserID,
+		Provider:  record.Provider,
+		Subject:   record.Subject,
+		Email:     record.Email,
+		CreatedAt: record.CreatedAt,
+	}
+}
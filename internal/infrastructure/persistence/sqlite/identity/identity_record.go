@@ -0,0 +1,16 @@
+package identity
+
+import (
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type IdentityRecord struct {
+	ID        int64     `db:"id"`
+	UserID    user.ID   `db:"user_id"`
+	Provider  string    `db:"provider"`
+	Subject   string    `db:"subject"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
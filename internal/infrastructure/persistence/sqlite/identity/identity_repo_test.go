@@ -0,0 +1,43 @@
+package identity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/domain/identity"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIdentityRepository_Create_AlreadyLinked Test a conflicting link is reported
+func TestIdentityRepository_Create_AlreadyLinked(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := IdentityRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectQuery(`INSERT INTO user_identities \(user_id,provider,subject,email\) VALUES \(\?,\?,\?,\?\) ON CONFLICT DO NOTHING RETURNING id, created_at`).
+		WithArgs(user.ID(1), "google", "ext-1", "a@b.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+
+	err := repo.Create(context.Background(), identity.New(1, "google", "ext-1", "a@b.com"))
+	assert.ErrorIs(t, err, identity.ErrAlreadyLinked)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestIdentityRepository_FindBySubject_NotFound Test an unknown provider account
+func TestIdentityRepository_FindBySubject_NotFound(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := IdentityRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectQuery(`SELECT id, user_id, provider, subject, email, created_at FROM user_identities WHERE provider = \? AND subject = \?`).
+		WithArgs("google", "ext-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "provider", "subject", "email", "created_at"}))
+
+	_, err := repo.FindBySubject(context.Background(), "google", "ext-1")
+	assert.ErrorIs(t, err, identity.ErrIdentityNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
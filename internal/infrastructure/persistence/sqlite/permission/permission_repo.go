@@ -0,0 +1,51 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite"
+	dbRole "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/role"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var Table = sqlite.Table{
+	Name: "role_permissions",
+	Columns: []string{
+		"role_id",
+		"permission",
+		"created_at",
+	},
+}
+
+type PermissionRepository struct {
+	db *sqlx.DB
+}
+
+var _ permission.Repository = (*PermissionRepository)(nil)
+
+func NewPermissionRepository(db *sqlx.DB) *PermissionRepository {
+	return &PermissionRepository{db: db}
+}
+
+func (r PermissionRepository) FindByRole(ctx context.Context, roleType role.Type) ([]permission.Permission, error) {
+	query, args, err := sqlite.Builder.Select("rp.permission").
+		From(Table.Name + " rp").
+		Join(dbRole.Table.Name + " r ON r.id = rp.role_id").
+		Where(squirrel.Eq{"r.type": roleType}).
+		OrderBy("rp.permission").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build permission query: %w", err)
+	}
+
+	permissions, err := sqlite.ScanAll[permission.Permission](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan permissions: %w", err)
+	}
+
+	return permissions, nil
+}
@@ -0,0 +1,33 @@
+package permission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPermissionRepository_FindByRole Test permissions are joined through roles
+func TestPermissionRepository_FindByRole(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := PermissionRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectQuery(`SELECT rp.permission FROM role_permissions rp JOIN roles r ON r.id = rp.role_id WHERE r.type = \?`).
+		WithArgs("admin").
+		WillReturnRows(
+			sqlmock.NewRows([]string{"permission"}).
+				AddRow("agent:manage").
+				AddRow("user:ban"),
+		)
+
+	permissions, err := repo.FindByRole(context.Background(), role.Admin)
+	assert.NoError(t, err)
+	assert.Equal(t, []permission.Permission{permission.AgentManage, permission.UserBan}, permissions)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
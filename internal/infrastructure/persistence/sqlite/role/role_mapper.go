@@ -0,0 +1,30 @@
+package role
+
+import (
+	"database/sql"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+func ToDomain(record *RoleRecord) (*role.Role, error) {
+	return &role.Role{
+		ID:               record.ID,
+		Type:             record.Type,
+		RequireTwoFactor: record.RequireTwoFactor,
+		Creator:          user.ID(record.Creator.Int64),
+		CreateAt:         record.CreatedAt,
+		UpdatedAt:        record.UpdatedAt,
+	}, nil
+}
+
+func ToRecord(role *role.Role) *RoleRecord {
+	return &RoleRecord{
+		ID:               role.ID,
+		Type:             role.Type,
+		RequireTwoFactor: role.RequireTwoFactor,
+		Creator:          sql.NullInt64{Int64: int64(role.Creator), Valid: role.Creator != 0},
+		CreatedAt:        role.CreateAt,
+		UpdatedAt:        role.UpdatedAt,
+	}
+}
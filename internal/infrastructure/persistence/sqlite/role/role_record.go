@@ -0,0 +1,17 @@
+package role
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+)
+
+type RoleRecord struct {
+	ID               role.ID       `db:"id"`
+	Type             role.Type     `db:"type"`
+	RequireTwoFactor bool          `db:"require_two_factor"`
+	Creator          sql.NullInt64 `db:"creator"` // NULL for seeded roles
+	CreatedAt        time.Time     `db:"created_at"`
+	UpdatedAt        time.Time     `db:"updated_at"`
+}
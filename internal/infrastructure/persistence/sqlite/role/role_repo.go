@@ -0,0 +1,81 @@
+package role
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var Table = sqlite.Table{
+	Name: "roles",
+	Columns: []string{
+		"id",
+		"type",
+		"require_two_factor",
+		"creator",
+		"created_at",
+		"updated_at",
+	},
+}
+
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+var _ role.Repository = (*RoleRepository)(nil)
+
+func NewRoleRepository(db *sqlx.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r RoleRepository) FindAll(ctx context.Context) ([]*role.Role, error) {
+	query, args, err := Table.Select(Table.Columns...).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build role query: %w", err)
+	}
+
+	records, err := sqlite.ScanAll[RoleRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan roles: %w", err)
+	}
+
+	roles := make([]*role.Role, 0, len(records))
+	for _, rec := range records {
+		converted, err := ToDomain(&rec)
+		if err != nil {
+			return nil, fmt.Errorf("convert role: %w", err)
+		}
+		roles = append(roles, converted)
+	}
+
+	return roles, nil
+}
+
+func (r RoleRepository) SetRequireTwoFactor(ctx context.Context, roleType role.Type, required bool) error {
+	query, args, err := Table.Update().
+		Set("require_two_factor", required).
+		Set("updated_at", squirrel.Expr(sqlite.Now)).
+		Where(squirrel.Eq{"type": roleType}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build role update: %w", err)
+	}
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("update role: %w", err)
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return role.ErrRoleNotFound
+	}
+
+	return nil
+}
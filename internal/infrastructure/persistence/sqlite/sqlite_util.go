@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/logger"
+)
+
+// ScanOne runs on the transaction the context carries, if any, as do ScanAll, Exec and Exists
+func ScanOne[T any](ctx context.Context, db database.Queryer, query string, args ...any) (*T, error) {
+	var rec T
+	if err := database.Conn(ctx, db).GetContext(ctx, &rec, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("scan one: %w", err)
+	}
+	return &rec, nil
+}
+
+func ScanAll[T any](ctx context.Context, db database.Queryer, query string, args ...any) ([]T, error) {
+	var list []T
+	if err := database.Conn(ctx, db).SelectContext(ctx, &list, query, args...); err != nil {
+		return nil, fmt.Errorf("scan all: %w", err)
+	}
+	return list, nil
+}
+
+func Exec(ctx context.Context, db database.Queryer, query string, args ...any) error {
+	if _, err := database.Conn(ctx, db).ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("exec query: %w", err)
+	}
+	return nil
+}
+
+func Exists(ctx context.Context, db database.Queryer, query string, args ...any) bool {
+	var one int
+	if err := database.Conn(ctx, db).QueryRowContext(ctx, query, args...).Scan(&one); err != nil {
+		logger.Log.Error(err.Error())
+		return false
+	}
+	return one > 0
+}
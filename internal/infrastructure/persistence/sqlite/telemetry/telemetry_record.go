@@ -0,0 +1,12 @@
+package telemetry
+
+import "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite"
+
+type PointRecord struct {
+	Metric string      `db:"metric"`
+	Bucket sqlite.Time `db:"bucket"`
+	Count  int64       `db:"sample_count"`
+	Avg    float64     `db:"value_avg"`
+	Min    float64     `db:"value_min"`
+	Max    float64     `db:"value_max"`
+}
@@ -0,0 +1,197 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var SampleTable = sqlite.Table{
+	Name: "device_telemetry",
+	Columns: []string{
+		"device_id",
+		"metric",
+		"value",
+		"recorded_at",
+	},
+}
+
+var RollupTable = sqlite.Table{
+	Name: "device_telemetry_rollups",
+	Columns: []string{
+		"device_id",
+		"metric",
+		"bucket_start",
+		"sample_count",
+		"value_sum",
+		"value_min",
+		"value_max",
+	},
+}
+
+// bucketOf truncates a timestamp column to buckets of a number of seconds, counted from the epoch,
+// in the layout the driver writes times with
+func bucketOf(column string) string {
+	return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:%%M:%%S+00:00', unixepoch(%s) / ? * ?, 'unixepoch')", column)
+}
+
+// rollupQuery folds old samples into buckets, Rollup deletes them in the same transaction
+var rollupQuery = `INSERT INTO ` + RollupTable.Name + ` (device_id, metric, bucket_start, sample_count, value_sum, value_min, value_max)
+SELECT device_id, metric, ` + bucketOf("recorded_at") + `,
+	COUNT(*), SUM(value), MIN(value), MAX(value)
+FROM ` + SampleTable.Name + `
+WHERE recorded_at < ?
+GROUP BY 1, 2, 3
+ON CONFLICT (device_id, metric, bucket_start) DO UPDATE SET
+	sample_count = device_telemetry_rollups.sample_count + excluded.sample_count,
+	value_sum = device_telemetry_rollups.value_sum + excluded.value_sum,
+	value_min = min(device_telemetry_rollups.value_min, excluded.value_min),
+	value_max = max(device_telemetry_rollups.value_max, excluded.value_max)`
+
+type TelemetryRepository struct {
+	db *sqlx.DB
+}
+
+var _ telemetry.Repository = (*TelemetryRepository)(nil)
+
+func NewTelemetryRepository(db *sqlx.DB) *TelemetryRepository {
+	return &TelemetryRepository{db: db}
+}
+
+func (r *TelemetryRepository) Insert(ctx context.Context, samples []*telemetry.Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	insert := SampleTable.Insert().Columns(SampleTable.Columns...)
+	for _, s := range samples {
+		insert = insert.Values(s.DeviceID, s.Metric, s.Value, s.At)
+	}
+
+	query, args, err := insert.ToSql()
+	if err != nil {
+		return fmt.Errorf("build insert telemetry: %w", err)
+	}
+
+	return sqlite.Exec(ctx, r.db, query, args...)
+}
+
+// Aggregate buckets the raw samples and the rollups of the range together, a bucket
+// of rolled up data counts the samples it was made of
+func (r *TelemetryRepository) Aggregate(ctx context.Context, q telemetry.Query) ([]telemetry.Point, error) {
+	raw := squirrel.Select(
+		"metric",
+		"recorded_at AS at",
+		"1 AS samples",
+		"value AS total",
+		"value AS low",
+		"value AS high").
+		From(SampleTable.Name).
+		Where(squirrel.Eq{"device_id": q.DeviceID}).
+		Where(squirrel.GtOrEq{"recorded_at": q.From}).
+		Where(squirrel.Lt{"recorded_at": q.To})
+
+	rolled := squirrel.Select(
+		"metric",
+		"bucket_start",
+		"sample_count",
+		"value_sum",
+		"value_min",
+		"value_max").
+		From(RollupTable.Name).
+		Where(squirrel.Eq{"device_id": q.DeviceID}).
+		Where(squirrel.GtOrEq{"bucket_start": q.From}).
+		Where(squirrel.Lt{"bucket_start": q.To})
+
+	if len(q.Metrics) > 0 {
+		raw = raw.Where(squirrel.Eq{"metric": q.Metrics})
+		rolled = rolled.Where(squirrel.Eq{"metric": q.Metrics})
+	}
+
+	rolledSQL, rolledArgs, err := rolled.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build telemetry rollups query: %w", err)
+	}
+
+	step := int64(q.Step / time.Second)
+	query, args, err := sqlite.Builder.
+		Select("metric").
+		Column(squirrel.Expr(bucketOf("at")+" AS bucket", step, step)).
+		Columns(
+			"SUM(samples) AS sample_count",
+			"SUM(total) / SUM(samples) AS value_avg",
+			"MIN(low) AS value_min",
+			"MAX(high) AS value_max").
+		FromSelect(raw.Suffix("UNION ALL "+rolledSQL, rolledArgs...), "s").
+		GroupBy("metric", "bucket").
+		OrderBy("metric", "bucket").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build telemetry query: %w", err)
+	}
+
+	records, err := sqlite.ScanAll[PointRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan telemetry: %w", err)
+	}
+
+	points := make([]telemetry.Point, 0, len(records))
+	for _, rec := range records {
+		points = append(points, toPoint(&rec))
+	}
+
+	return points, nil
+}
+
+// Rollup copies and deletes in one transaction, so a sample is never counted twice
+// or lost in between; SQLite allows no DELETE inside a WITH clause
+func (r *TelemetryRepository) Rollup(ctx context.Context, cutoff time.Time, step time.Duration) (int64, error) {
+	deleteQuery, deleteArgs, err := SampleTable.Delete().
+		Where(squirrel.Lt{"recorded_at": cutoff}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build delete rolled up telemetry: %w", err)
+	}
+
+	var rolledUp int64
+	err = database.WithinTx(ctx, r.db, func(ctx context.Context) error {
+		seconds := int64(step / time.Second)
+		result, err := database.Conn(ctx, r.db).ExecContext(ctx, rollupQuery, seconds, seconds, cutoff)
+		if err != nil {
+			return fmt.Errorf("rollup telemetry: %w", err)
+		}
+		if err := sqlite.Exec(ctx, r.db, deleteQuery, deleteArgs...); err != nil {
+			return fmt.Errorf("delete rolled up telemetry: %w", err)
+		}
+
+		rolledUp, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return rolledUp, nil
+}
+
+func (r *TelemetryRepository) DeleteRollupsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	query, args, err := RollupTable.Delete().
+		Where(squirrel.Lt{"bucket_start": cutoff}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build delete telemetry rollups: %w", err)
+	}
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete telemetry rollups: %w", err)
+	}
+
+	return result.RowsAffected()
+}
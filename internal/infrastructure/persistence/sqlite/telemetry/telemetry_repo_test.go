@@ -0,0 +1,121 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTelemetryRepository_Insert Test a batch is stored in one statement
+func TestTelemetryRepository_Insert(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := TelemetryRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	now := time.Now()
+	mock.ExpectExec(`INSERT INTO device_telemetry \(device_id,metric,value,recorded_at\) VALUES \(\?,\?,\?,\?\),\(\?,\?,\?,\?\)`).
+		WithArgs(device.ID(3), "temperature", 21.5, now, device.ID(3), "cpu", 12.0, now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := repo.Insert(context.Background(), []*telemetry.Sample{
+		{DeviceID: 3, Metric: "temperature", Value: 21.5, At: now},
+		{DeviceID: 3, Metric: "cpu", Value: 12, At: now},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTelemetryRepository_Aggregate Test raw samples and rollups are bucketed together
+func TestTelemetryRepository_Aggregate(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := TelemetryRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	to := time.Now().Truncate(time.Hour)
+	from := to.Add(-time.Hour)
+	mock.ExpectQuery(`SELECT metric, strftime\('%Y-%m-%d %H:%M:%S\+00:00', unixepoch\(at\) / \? \* \?, 'unixepoch'\) AS bucket, SUM\(samples\) AS sample_count, .* `+
+		`FROM \(SELECT metric, recorded_at AS at, .* FROM device_telemetry WHERE device_id = \? AND recorded_at >= \? AND recorded_at < \? AND metric IN \(\?\) `+
+		`UNION ALL SELECT metric, bucket_start, .* FROM device_telemetry_rollups WHERE device_id = \? AND bucket_start >= \? AND bucket_start < \? AND metric IN \(\?\)\) AS s `+
+		`GROUP BY metric, bucket ORDER BY metric, bucket`).
+		WithArgs(int64(300), int64(300), device.ID(3), from, to, "cpu", device.ID(3), from, to, "cpu").
+		WillReturnRows(sqlmock.NewRows([]string{"metric", "bucket", "sample_count", "value_avg", "value_min", "value_max"}).
+			AddRow("cpu", from, 10, 12.5, 3.0, 40.0))
+
+	points, err := repo.Aggregate(context.Background(), telemetry.Query{
+		DeviceID: 3,
+		Metrics:  []string{"cpu"},
+		From:     from,
+		To:       to,
+		Step:     5 * time.Minute,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []telemetry.Point{{Metric: "cpu", Bucket: from, Count: 10, Avg: 12.5, Min: 3, Max: 40}}, points)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTelemetryRepository_Rollup Test old samples are moved into buckets of the step in one transaction
+func TestTelemetryRepository_Rollup(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := TelemetryRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	cutoff := time.Now().Add(-48 * time.Hour)
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO device_telemetry_rollups .* FROM device_telemetry\s+WHERE recorded_at < \?\s+GROUP BY 1, 2, 3\s+ON CONFLICT`).
+		WithArgs(int64(300), int64(300), cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(`DELETE FROM device_telemetry WHERE recorded_at < \?`).
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 9))
+	mock.ExpectCommit()
+
+	n, err := repo.Rollup(context.Background(), cutoff, 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTelemetryRepository_RollupOnSqlite Test rolled up buckets aggregate like the raw samples on a real database
+func TestTelemetryRepository_RollupOnSqlite(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	db := testutil.OpenDB(t)
+	_, err := db.ExecContext(ctx, `INSERT INTO users (id, name, email, password, user_status, user_ip)
+		VALUES (1, 'alice', 'a@b.com', 'x', 'active', '127.0.0.1')`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO devices (id, user_id, device_id, name, platform, session_id)
+		VALUES (3, 1, 'pi', 'Pi', 'embedded', 's')`)
+	require.NoError(t, err)
+
+	repo := NewTelemetryRepository(db)
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Insert(ctx, []*telemetry.Sample{
+		{DeviceID: 3, Metric: "cpu", Value: 1, At: start.Add(10 * time.Second)},
+		{DeviceID: 3, Metric: "cpu", Value: 3, At: start.Add(50 * time.Second)},
+		{DeviceID: 3, Metric: "cpu", Value: 5, At: start.Add(2 * time.Minute)},
+	}))
+
+	rolledUp, err := repo.Rollup(ctx, start.Add(time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rolledUp)
+
+	points, err := repo.Aggregate(ctx, telemetry.Query{
+		DeviceID: 3,
+		From:     start,
+		To:       start.Add(5 * time.Minute),
+		Step:     time.Minute,
+	})
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	assert.Equal(t, telemetry.Point{Metric: "cpu", Bucket: start, Count: 2, Avg: 2, Min: 1, Max: 3}, points[0])
+	assert.Equal(t, telemetry.Point{Metric: "cpu", Bucket: start.Add(2 * time.Minute), Count: 1, Avg: 5, Min: 5, Max: 5}, points[1])
+}
@@ -0,0 +1,36 @@
+package testutil
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/migration"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+// OpenDB opens a SQLite file with the options of the config, migrated to the latest version
+func OpenDB(t *testing.T) *sqlx.DB {
+	dsn := "file:" + filepath.Join(t.TempDir(), "goat.db") +
+		"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)" +
+		"&_time_format=sqlite&_timezone=UTC&_texttotime=1"
+
+	db, err := sqlx.Connect("sqlite", dsn)
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	migrator, err := migration.NewMigrator(db, database.Sqlite)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+
+	return db
+}
@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"fmt"
+	"time"
+)
+
+// timeLayout the layout the driver writes times with, fractional seconds are optional
+const timeLayout = "2006-01-02 15:04:05.999999999-07:00"
+
+// Time scans a timestamp computed in a query. The driver only converts columns
+// declared as a time type, an expression arrives as text.
+type Time struct {
+	time.Time
+}
+
+func (t *Time) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		t.Time = v
+		return nil
+	case string:
+		return t.parse(v)
+	case []byte:
+		return t.parse(string(v))
+	default:
+		return fmt.Errorf("scan time: unsupported type %T", src)
+	}
+}
+
+func (t *Time) parse(s string) error {
+	parsed, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return fmt.Errorf("scan time: %w", err)
+	}
+	t.Time = parsed.UTC()
+	return nil
+}
@@ -0,0 +1,24 @@
+package twofactor
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type TwoFactorRecord struct {
+	UserID       user.ID      `db:"user_id"`
+	Secret       string       `db:"secret"`
+	EnabledAt    sql.NullTime `db:"enabled_at"`
+	LastUsedStep int64        `db:"last_used_step"`
+	CreatedAt    time.Time    `db:"created_at"`
+	UpdatedAt    time.Time    `db:"updated_at"`
+}
+
+type RecoveryCodeRecord struct {
+	ID       int64        `db:"id"`
+	UserID   user.ID      `db:"user_id"`
+	CodeHash string       `db:"code_hash"`
+	UsedAt   sql.NullTime `db:"used_at"`
+}
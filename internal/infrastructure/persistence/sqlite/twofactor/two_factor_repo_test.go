@@ -0,0 +1,68 @@
+package twofactor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTwoFactorRepository_ReplaceRecoveryCodes Test old codes are dropped in the same transaction
+func TestTwoFactorRepository_ReplaceRecoveryCodes(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := TwoFactorRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM user_recovery_codes WHERE user_id = \?`).
+		WithArgs(user.ID(1)).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectExec(`INSERT INTO user_recovery_codes \(user_id,code_hash\) VALUES \(\?,\?\),\(\?,\?\)`).
+		WithArgs(user.ID(1), "h1", user.ID(1), "h2").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := repo.ReplaceRecoveryCodes(context.Background(), 1, []string{"h1", "h2"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTwoFactorRepository_ReplaceRecoveryCodes_RollsBack Test the old codes stay when the new ones fail
+func TestTwoFactorRepository_ReplaceRecoveryCodes_RollsBack(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := TwoFactorRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM user_recovery_codes WHERE user_id = \?`).
+		WithArgs(user.ID(1)).
+		WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectExec(`INSERT INTO user_recovery_codes`).
+		WillReturnError(errors.New("disk I/O error"))
+	mock.ExpectRollback()
+
+	err := repo.ReplaceRecoveryCodes(context.Background(), 1, []string{"h1"})
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTwoFactorRepository_UseRecoveryCode_AlreadyUsed Test a used code is rejected
+func TestTwoFactorRepository_UseRecoveryCode_AlreadyUsed(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := TwoFactorRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectExec(`UPDATE user_recovery_codes SET used_at = strftime\(.*\) WHERE id = \? AND used_at IS NULL`).
+		WithArgs(int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UseRecoveryCode(context.Background(), 7)
+	assert.ErrorIs(t, err, twofactor.ErrInvalidCode)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,33 @@
+package user
+
+import (
+	"database/sql"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+func toStatusChangeDomain(record *StatusHistoryRecord) *user.StatusChange {
+	return &user.StatusChange{
+		ID:        record.ID,
+		UserID:    record.UserID,
+		From:      record.FromStatus,
+		To:        record.ToStatus,
+		Reason:    record.Reason,
+		ActorID:   user.ID(record.ActorID.Int64),
+		CreatedAt: record.CreatedAt,
+	}
+}
+
+func toStatusHistoryRecord(change *user.StatusChange) *StatusHistoryRecord {
+	return &StatusHistoryRecord{
+		ID:         change.ID,
+		UserID:     change.UserID,
+		FromStatus: change.From,
+		ToStatus:   change.To,
+		Reason:     change.Reason,
+		ActorID: sql.NullInt64{
+			Int64: int64(change.ActorID),
+			Valid: change.ActorID != 0,
+		},
+	}
+}
@@ -0,0 +1,18 @@
+package user
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type StatusHistoryRecord struct {
+	ID         user.StatusChangeID `db:"id"`
+	UserID     user.ID             `db:"user_id"`
+	FromStatus user.Status         `db:"from_status"`
+	ToStatus   user.Status         `db:"to_status"`
+	Reason     string              `db:"reason"`
+	ActorID    sql.NullInt64       `db:"actor_id"`
+	CreatedAt  time.Time           `db:"created_at"`
+}
@@ -0,0 +1,98 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var StatusHistoryTable = sqlite.Table{
+	Name: "user_status_histories",
+	Columns: []string{
+		"id",
+		"user_id",
+		"from_status",
+		"to_status",
+		"reason",
+		"actor_id",
+		"created_at",
+	},
+}
+
+type StatusHistoryRepository struct {
+	db *sqlx.DB
+}
+
+var _ user.StatusHistoryRepository = (*StatusHistoryRepository)(nil)
+
+func NewStatusHistoryRepository(db *sqlx.DB) *StatusHistoryRepository {
+	return &StatusHistoryRepository{db: db}
+}
+
+func (r *StatusHistoryRepository) Create(ctx context.Context, change *user.StatusChange) error {
+	rec := toStatusHistoryRecord(change)
+
+	query, args, err := StatusHistoryTable.Insert().
+		Columns("user_id", "from_status", "to_status", "reason", "actor_id").
+		Values(rec.UserID, rec.FromStatus, rec.ToStatus, rec.Reason, rec.ActorID).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build insert status change: %w", err)
+	}
+
+	if err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&change.ID, &change.CreatedAt); err != nil {
+		return fmt.Errorf("insert status change: %w", err)
+	}
+
+	return nil
+}
+
+func (r *StatusHistoryRepository) FindByUser(ctx context.Context, userID user.ID) ([]*user.StatusChange, error) {
+	query, args, err := StatusHistoryTable.Select(StatusHistoryTable.Columns...).
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("created_at DESC", "id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build status history query: %w", err)
+	}
+
+	records, err := sqlite.ScanAll[StatusHistoryRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan status history: %w", err)
+	}
+
+	changes := make([]*user.StatusChange, 0, len(records))
+	for _, rec := range records {
+		changes = append(changes, toStatusChangeDomain(&rec))
+	}
+
+	return changes, nil
+}
+
+func (r *StatusHistoryRepository) FindLatest(ctx context.Context, userID user.ID) (*user.StatusChange, error) {
+	query, args, err := StatusHistoryTable.Select(StatusHistoryTable.Columns...).
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("created_at DESC", "id DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build latest status change query: %w", err)
+	}
+
+	rec, err := sqlite.ScanOne[StatusHistoryRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrNotFound) {
+			return nil, user.ErrStatusChangeNotFound
+		}
+		return nil, fmt.Errorf("find latest status change: %w", err)
+	}
+
+	return toStatusChangeDomain(rec), nil
+}
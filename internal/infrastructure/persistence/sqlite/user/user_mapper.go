@@ -0,0 +1,44 @@
+package user
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+func toDomain(record *UserRecord) (*user.User, error) {
+	u := &user.User{
+		ID:        record.ID,
+		Name:      record.Name,
+		Email:     record.Email,
+		Password:  record.Password,
+		Status:    record.UserStatus,
+		LastIP:    record.UserIP,
+		CreatedAt: record.CreatedAt,
+		UpdatedAt: record.UpdatedAt,
+	}
+	if record.VerifiedAt.Valid {
+		u.EmailVerifiedAt = &record.VerifiedAt.Time
+	}
+	return u, nil
+}
+
+func toRecord(user *user.User) *UserRecord {
+	return &UserRecord{
+		ID:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Password:   user.Password,
+		UserStatus: user.Status,
+		UserIP:     user.LastIP,
+		VerifiedAt: toNullTime(user.EmailVerifiedAt),
+	}
+}
+
+func toNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
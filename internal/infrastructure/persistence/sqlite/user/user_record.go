@@ -0,0 +1,20 @@
+package user
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+type UserRecord struct {
+	ID         user.ID      `db:"id"`
+	Name       string       `db:"name" `
+	Email      user.Email   `db:"email" `
+	Password   string       `db:"password"`
+	UserStatus user.Status  `db:"user_status"`
+	UserIP     string       `db:"user_ip"`
+	VerifiedAt sql.NullTime `db:"email_verified_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+}
@@ -0,0 +1,142 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var Table = sqlite.Table{
+	Name: "users",
+	Columns: []string{
+		"id",
+		"name",
+		"email",
+		"password",
+		"user_status",
+		"user_ip",
+		"email_verified_at",
+		"created_at",
+		"updated_at",
+	},
+}
+
+type UserRepository struct {
+	db *sqlx.DB
+}
+
+var _ user.Repository = (*UserRepository)(nil)
+
+func NewUserRepository(db *sqlx.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) FindByID(ctx context.Context, id user.ID) (*user.User, error) {
+	return r.findOneBy(ctx, squirrel.Eq{"id": id})
+}
+
+func (r *UserRepository) FindByEmail(ctx context.Context, email user.Email) (*user.User, error) {
+	return r.findOneBy(ctx, squirrel.Eq{"email": email})
+}
+
+func (r *UserRepository) FindByStatus(ctx context.Context, status user.Status) ([]*user.User, error) {
+	query, args, err := Table.
+		Select(Table.Columns...).
+		Where(squirrel.Eq{"user_status": status}).
+		OrderBy("created_at ASC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build users by status query: %w", err)
+	}
+
+	records, err := sqlite.ScanAll[UserRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan users: %w", err)
+	}
+
+	users := make([]*user.User, 0, len(records))
+	for _, rec := range records {
+		u, err := toDomain(&rec)
+		if err != nil {
+			return nil, fmt.Errorf("convert user: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) Create(ctx context.Context, u *user.User) error {
+	record := toRecord(u)
+
+	query, args, err := Table.Insert().
+		Columns("name", "email", "password", "user_status", "user_ip").
+		Values(record.Name, record.Email, record.Password, record.UserStatus, record.UserIP).
+		Suffix("ON CONFLICT DO NOTHING RETURNING id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build create user: %w", err)
+	}
+
+	// The unique email is the only key a new user can collide on
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&u.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user.ErrUserAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("create user: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, u *user.User) error {
+	rec := toRecord(u)
+
+	query, args, err := Table.Update().
+		Set("name", rec.Name).
+		Set("password", rec.Password).
+		Set("user_status", rec.UserStatus).
+		Set("user_ip", rec.UserIP).
+		Set("email_verified_at", rec.VerifiedAt).
+		Set("updated_at", squirrel.Expr(sqlite.Now)).
+		Where(squirrel.Eq{"id": rec.ID}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	return sqlite.Exec(ctx, r.db, query, args...)
+}
+
+func (r *UserRepository) findOneBy(
+	ctx context.Context,
+	cond squirrel.Eq,
+) (*user.User, error) {
+
+	query, args, err := Table.
+		Select(Table.Columns...).
+		Where(cond).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build user query: %w", err)
+	}
+
+	rec, err := sqlite.ScanOne[UserRecord](ctx, r.db, query, args...)
+	if err != nil {
+		if errors.Is(err, sqlite.ErrNotFound) {
+			return nil, user.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("find user: %w", err)
+	}
+
+	return toDomain(rec)
+}
@@ -0,0 +1,50 @@
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUserRepository_RoundTrip Test users are stored and read back from a real database
+func TestUserRepository_RoundTrip(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	repo := NewUserRepository(testutil.OpenDB(t))
+
+	created := user.NewUser("alice", user.Email("alice@b.com"), "hashed", "127.0.0.1")
+	require.NoError(t, repo.Create(ctx, created))
+	assert.NotZero(t, created.ID)
+
+	found, err := repo.FindByEmail(ctx, "alice@b.com")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+	assert.Equal(t, "alice", found.Name)
+	assert.Equal(t, user.Applying, found.Status)
+	assert.False(t, found.CreatedAt.IsZero())
+	assert.False(t, found.IsEmailVerified())
+
+	now := time.Now()
+	found.EmailVerifiedAt = &now
+	found.Status = user.Active
+	require.NoError(t, repo.Update(ctx, found))
+
+	active, err := repo.FindByStatus(ctx, user.Active)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, found.ID, active[0].ID)
+	assert.True(t, active[0].IsEmailVerified())
+
+	_, err = repo.FindByEmail(ctx, "nobody@b.com")
+	assert.ErrorIs(t, err, user.ErrUserNotFound)
+
+	// The email is unique
+	err = repo.Create(ctx, user.NewUser("bob", user.Email("alice@b.com"), "hashed", "127.0.0.1"))
+	assert.ErrorIs(t, err, user.ErrUserAlreadyExists)
+}
@@ -0,0 +1,128 @@
+package userrole
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/domain/userrole"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/database"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite"
+	dbRole "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/role"
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+var Table = sqlite.Table{
+	Name: "users_roles",
+	Columns: []string{
+		"user_id",
+		"role_id",
+		"created_at",
+	},
+}
+
+type UserRoleRepository struct {
+	db *sqlx.DB
+}
+
+var _ userrole.Repository = (*UserRoleRepository)(nil)
+
+func NewUserRoleRepository(db *sqlx.DB) *UserRoleRepository {
+	return &UserRoleRepository{db: db}
+}
+
+func (r UserRoleRepository) FindRolesByUser(ctx context.Context, userID user.ID) ([]*role.Role, error) {
+	columns := make([]string, 0, len(dbRole.Table.Columns))
+	for _, c := range dbRole.Table.Columns {
+		columns = append(columns, "r."+c)
+	}
+
+	query, args, err := sqlite.Builder.Select(columns...).
+		From(dbRole.Table.Name + " r").
+		Join(Table.Name + " ur ON ur.role_id = r.id").
+		Where(squirrel.Eq{"ur.user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build user_role query: %w", err)
+	}
+
+	records, err := sqlite.ScanAll[dbRole.RoleRecord](ctx, r.db, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("scan roles: %w", err)
+	}
+
+	roles := make([]*role.Role, 0, len(records))
+	for _, rec := range records {
+		r, err := dbRole.ToDomain(&rec)
+		if err != nil {
+			return nil, fmt.Errorf("convert role: %w", err)
+		}
+		roles = append(roles, r)
+	}
+
+	return roles, nil
+}
+
+func (r UserRoleRepository) Exists(ctx context.Context, userID user.ID, role role.Type) bool {
+	query, args, err := Table.Select("1").
+		From(Table.Name+" ur").
+		LeftJoin(dbRole.Table.Name+" r ON ur.role_id = r.id").
+		Where("ur.user_id = ? AND r.type = ?", userID, role).
+		ToSql()
+	if err != nil {
+		return false
+	}
+
+	return sqlite.Exists(ctx, r.db, query, args...)
+}
+
+func (r UserRoleRepository) Assign(ctx context.Context, userID user.ID, role role.Type) error {
+	query, args, err := Table.Insert().
+		Columns("user_id", "role_id").
+		Select(
+			sqlite.Builder.
+				Select().
+				Column(squirrel.Expr("?", userID)).
+				Column("id").
+				From(dbRole.Table.Name).
+				Where(squirrel.Eq{"type": role}),
+		).
+		Suffix("ON CONFLICT DO NOTHING RETURNING user_id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build assign sql: %w", err)
+	}
+
+	err = database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return userrole.ErrUserRoleAlreadyAssigned
+	}
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r UserRoleRepository) Revoke(ctx context.Context, userID user.ID, role role.Type) error {
+	// SQLite has no DELETE ... USING, the role is looked up in a subquery
+	query, args, err := Table.Delete().
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Expr("role_id IN (SELECT id FROM "+dbRole.Table.Name+" WHERE type = ?)", role)).
+		ToSql()
+
+	if err != nil {
+		return fmt.Errorf("build revoke sql: %w", err)
+	}
+
+	err = sqlite.Exec(ctx, r.db, query, args...)
+	if err != nil {
+		return userrole.ErrRevokeFailed
+	}
+
+	return nil
+}
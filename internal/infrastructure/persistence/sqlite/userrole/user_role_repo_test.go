@@ -0,0 +1,87 @@
+package userrole
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/persistence/sqlite/testutil"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUserRoleRepository_Exists Test is the Exist query correct
+func TestUserRoleRepository_Exists(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := UserRoleRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectQuery(`SELECT 1 .*users_roles.*roles.*`).
+		WithArgs(user.ID(1), "admin").
+		WillReturnRows(
+			sqlmock.NewRows([]string{"1"}).AddRow(1),
+		)
+
+	ok := repo.Exists(context.Background(), user.ID(1), role.Admin)
+	assert.True(t, ok)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUserRoleRepository_Assign Test is the Assign query correct
+func TestUserRoleRepository_Assign(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := UserRoleRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`
+		INSERT INTO users_roles (user_id,role_id)
+		SELECT ?, id FROM roles WHERE type = ?
+		ON CONFLICT DO NOTHING RETURNING user_id
+	`)).
+		WithArgs(user.ID(1), "admin").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+
+	err := repo.Assign(context.Background(), user.ID(1), role.Admin)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRoleRepository_Revoke(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := UserRoleRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectExec(`DELETE FROM users_roles WHERE user_id = \? AND role_id IN \(SELECT id FROM roles WHERE type = \?\)`).
+		WithArgs(user.ID(1), role.Admin).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.Revoke(context.Background(), user.ID(1), role.Admin)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUserRoleRepository_FindRolesByUser Test roles are joined through users_roles
+func TestUserRoleRepository_FindRolesByUser(t *testing.T) {
+	db, mock := testutil.SetupDB(t)
+	repo := UserRoleRepository{db: sqlx.NewDb(db, "sqlite")}
+
+	mock.ExpectQuery(`SELECT r.id, r.type, .* FROM roles r JOIN users_roles ur ON ur.role_id = r.id WHERE ur.user_id = \?`).
+		WithArgs(user.ID(1)).
+		WillReturnRows(
+			sqlmock.NewRows([]string{"id", "type", "require_two_factor", "creator", "created_at", "updated_at"}).
+				AddRow(1, "admin", true, nil, time.Now(), time.Now()),
+		)
+
+	roles, err := repo.FindRolesByUser(context.Background(), user.ID(1))
+	assert.NoError(t, err)
+	if assert.Len(t, roles, 1) {
+		assert.Equal(t, role.Admin, roles[0].Type)
+		assert.True(t, roles[0].RequireTwoFactor)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,178 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/push"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	APNsProductionURL = "https://api.push.apple.com"
+	APNsSandboxURL    = "https://api.sandbox.push.apple.com"
+
+	// apnsTokenTTL APNs accepts a provider token for an hour and refuses to
+	// have it renewed more often than every 20 minutes
+	apnsTokenTTL = 50 * time.Minute
+
+	// apnsMaxCollapseID is the size limit of the apns-collapse-id header
+	apnsMaxCollapseID = 64
+)
+
+// APNsConfig the token based auth key of an Apple developer team
+type APNsConfig struct {
+	TeamID     string
+	KeyID      string
+	PrivateKey string // PEM of the .p8 key
+	Topic      string // bundle ID of the app
+
+	// BaseURL APNsProductionURL or APNsSandboxURL
+	BaseURL string
+}
+
+// APNsProvider sends notifications through the Apple Push Notification service
+// with a provider token signed by the team key. Go's HTTP client speaks the
+// HTTP/2 APNs requires over TLS.
+type APNsProvider struct {
+	conf APNsConfig
+	http *http.Client
+
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+var _ push.Provider = (*APNsProvider)(nil)
+
+// NewAPNsProvider creates an APNs client. A nil httpClient uses a client with a 10s timeout.
+func NewAPNsProvider(conf APNsConfig, httpClient *http.Client) *APNsProvider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	if conf.BaseURL == "" {
+		conf.BaseURL = APNsProductionURL
+	}
+	conf.BaseURL = strings.TrimRight(conf.BaseURL, "/")
+	return &APNsProvider{
+		conf: conf,
+		http: httpClient,
+	}
+}
+
+func (p *APNsProvider) Name() string {
+	return "apns"
+}
+
+func (p *APNsProvider) Supports(platform device.Platform) bool {
+	return platform == device.IOS
+}
+
+func (p *APNsProvider) Send(ctx context.Context, msg push.Message) error {
+	token, err := p.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]any{
+		"aps": apnsAps{
+			Alert:    apnsAlert{Title: msg.Title, Body: msg.Body},
+			Sound:    "default",
+			ThreadID: msg.CollapseKey,
+		},
+	}
+	for k, v := range msg.Data {
+		payload[k] = v
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	target := p.conf.BaseURL + "/3/device/" + url.PathEscape(msg.Token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("apns-topic", p.conf.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	if msg.CollapseKey != "" && len(msg.CollapseKey) <= apnsMaxCollapseID {
+		req.Header.Set("apns-collapse-id", msg.CollapseKey)
+	}
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("apns send: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&failure)
+
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		failure.Reason == "BadDeviceToken",
+		failure.Reason == "DeviceTokenNotForTopic",
+		failure.Reason == "Unregistered":
+		return device.ErrPushTokenRejected
+	default:
+		return fmt.Errorf("apns send: %s %s", resp.Status, failure.Reason)
+	}
+}
+
+// providerToken returns the cached provider token, or signs a new one once it is old
+func (p *APNsProvider) providerToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.jwt != "" && time.Since(p.issuedAt) < apnsTokenTTL {
+		return p.jwt, nil
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM([]byte(p.conf.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("apns private key: %w", err)
+	}
+
+	now := time.Now()
+	t := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.conf.TeamID,
+		"iat": now.Unix(),
+	})
+	t.Header["kid"] = p.conf.KeyID
+
+	signed, err := t.SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("apns sign token: %w", err)
+	}
+
+	p.jwt = signed
+	p.issuedAt = now
+	return signed, nil
+}
+
+type apnsAps struct {
+	Alert    apnsAlert `json:"alert"`
+	Sound    string    `json:"sound,omitempty"`
+	ThreadID string    `json:"thread-id,omitempty"`
+}
+
+type apnsAlert struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
@@ -0,0 +1,87 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/push"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAPNsProvider(t *testing.T, handler http.HandlerFunc) (*APNsProvider, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return NewAPNsProvider(APNsConfig{
+		TeamID:     "TEAM",
+		KeyID:      "KEY",
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		Topic:      "com.hiroliang.goat",
+		BaseURL:    srv.URL,
+	}, srv.Client()), key
+}
+
+func TestAPNsProvider_SendsSignedRequest(t *testing.T) {
+	var got *http.Request
+	var p *APNsProvider
+	var key *ecdsa.PrivateKey
+	p, key = newAPNsProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "bearer ")
+		parsed, err := jwt.Parse(token, func(*jwt.Token) (any, error) { return &key.PublicKey, nil })
+		if err != nil || parsed.Header["kid"] != "KEY" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	})
+
+	err := p.Send(context.Background(), push.Message{Token: "abc", Title: "Team", Body: "hi", CollapseKey: "chat-group-7"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "/3/device/abc", got.URL.Path)
+	assert.Equal(t, "com.hiroliang.goat", got.Header.Get("apns-topic"))
+	assert.Equal(t, "chat-group-7", got.Header.Get("apns-collapse-id"))
+}
+
+func TestAPNsProvider_RejectedTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		reject bool
+	}{
+		{"unregistered", http.StatusGone, `{"reason":"Unregistered"}`, true},
+		{"bad token", http.StatusBadRequest, `{"reason":"BadDeviceToken"}`, true},
+		{"throttled", http.StatusTooManyRequests, `{"reason":"TooManyRequests"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newAPNsProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			err := p.Send(context.Background(), push.Message{Token: "abc"})
+			assert.Error(t, err)
+			assert.Equal(t, tt.reject, errors.Is(err, device.ErrPushTokenRejected))
+		})
+	}
+}
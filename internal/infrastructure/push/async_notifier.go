@@ -0,0 +1,46 @@
+package push
+
+import (
+	"context"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/notification"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"go.uber.org/zap"
+)
+
+// AsyncNotifier delivers notifications in the background, so the request that
+// posted a message never waits on a push service. Failures are only logged.
+type AsyncNotifier struct {
+	next    notification.Notifier
+	timeout time.Duration
+}
+
+var _ notification.Notifier = (*AsyncNotifier)(nil)
+
+// NewAsyncNotifier wraps next. A zero timeout bounds each delivery to 30s.
+func NewAsyncNotifier(next notification.Notifier, timeout time.Duration) *AsyncNotifier {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &AsyncNotifier{
+		next:    next,
+		timeout: timeout,
+	}
+}
+
+func (n *AsyncNotifier) NotifyChatMessage(ctx context.Context, msg notification.ChatMessage) error {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), n.timeout)
+		defer cancel()
+
+		if err := n.next.NotifyChatMessage(ctx, msg); err != nil {
+			logger.Log.Warn("chat notification failed",
+				zap.Int64("group_id", int64(msg.GroupID)),
+				zap.Int64("message_id", int64(msg.MessageID)),
+				zap.Error(err),
+			)
+		}
+	}()
+	return nil
+}
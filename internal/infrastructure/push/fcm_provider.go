@@ -0,0 +1,187 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/push"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultTimeout    = 10 * time.Second
+	defaultFCMBaseURL = "https://fcm.googleapis.com"
+	fcmScope          = "https://www.googleapis.com/auth/firebase.messaging"
+)
+
+// FCMConfig the service account of a Firebase project, as found in its credentials file
+type FCMConfig struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURL    string `json:"token_uri"`
+
+	// BaseURL of the FCM API, empty for the public endpoint
+	BaseURL string `json:"-"`
+}
+
+// ParseFCMCredentials reads the service account credentials file of a Firebase project
+func ParseFCMCredentials(data []byte) (FCMConfig, error) {
+	var conf FCMConfig
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return FCMConfig{}, fmt.Errorf("parse fcm credentials: %w", err)
+	}
+	if conf.ProjectID == "" || conf.ClientEmail == "" || conf.PrivateKey == "" || conf.TokenURL == "" {
+		return FCMConfig{}, errors.New("fcm credentials miss project_id, client_email, private_key or token_uri")
+	}
+	return conf, nil
+}
+
+// FCMProvider sends notifications through the Firebase Cloud Messaging HTTP v1 API.
+// The service account signs a JWT for an OAuth access token, which is cached until
+// shortly before it expires.
+type FCMProvider struct {
+	conf FCMConfig
+	http *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+var _ push.Provider = (*FCMProvider)(nil)
+
+// NewFCMProvider creates an FCM client. A nil httpClient uses a client with a 10s timeout.
+func NewFCMProvider(conf FCMConfig, httpClient *http.Client) *FCMProvider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	if conf.BaseURL == "" {
+		conf.BaseURL = defaultFCMBaseURL
+	}
+	conf.BaseURL = strings.TrimRight(conf.BaseURL, "/")
+	return &FCMProvider{
+		conf: conf,
+		http: httpClient,
+	}
+}
+
+func (p *FCMProvider) Name() string {
+	return "fcm"
+}
+
+func (p *FCMProvider) Supports(platform device.Platform) bool {
+	return platform == device.Android || platform == device.Web
+}
+
+func (p *FCMProvider) Send(ctx context.Context, msg push.Message) error {
+	token, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload := fcmRequest{Message: fcmMessage{
+		Token:        msg.Token,
+		Notification: fcmNotification{Title: msg.Title, Body: msg.Body},
+		Data:         msg.Data,
+		Android:      fcmAndroid{CollapseKey: msg.CollapseKey},
+	}}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	target := fmt.Sprintf("%s/v1/projects/%s/messages:send", p.conf.BaseURL, url.PathEscape(p.conf.ProjectID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("fcm send: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure fcmError
+	_ = json.NewDecoder(resp.Body).Decode(&failure)
+	if failure.unregistered() {
+		return device.ErrPushTokenRejected
+	}
+	return fmt.Errorf("fcm send: %s %s", resp.Status, failure.Error.Message)
+}
+
+// token returns the cached access token, or exchanges a fresh service account assertion
+func (p *FCMProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(p.conf.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("fcm private key: %w", err)
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.conf.ClientEmail,
+		"scope": fcmScope,
+		"aud":   p.conf.TokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(key)
+	if err != nil {
+		return "", fmt.Errorf("fcm sign assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.conf.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fcm token: %s", resp.Status)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decode fcm token: %w", err)
+	}
+
+	// Renew a minute early so a token never expires in flight
+	p.accessToken = body.AccessToken
+	p.expiresAt = now.Add(time.Duration(body.ExpiresIn)*time.Second - time.Minute)
+	return p.accessToken, nil
+}
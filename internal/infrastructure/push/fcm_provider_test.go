@@ -0,0 +1,105 @@
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/push"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFCM issues access tokens for valid assertions and knows the token "live" only
+type fakeFCM struct {
+	srv       *httptest.Server
+	key       *rsa.PrivateKey
+	exchanges atomic.Int32
+	last      fcmRequest
+}
+
+func newFakeFCM(t *testing.T) *fakeFCM {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	f := &fakeFCM{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_, err := jwt.Parse(r.FormValue("assertion"), func(*jwt.Token) (any, error) {
+			return &key.PublicKey, nil
+		}, jwt.WithAudience(f.srv.URL+"/token"), jwt.WithIssuer("push@goat.iam"))
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.exchanges.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "access", "expires_in": 3600})
+	})
+	mux.HandleFunc("/v1/projects/goat/messages:send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&f.last)
+		if f.last.Message.Token != "live" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"projects/goat/messages/1"}`))
+	})
+	f.srv = httptest.NewServer(mux)
+	t.Cleanup(f.srv.Close)
+
+	return f
+}
+
+func (f *fakeFCM) provider() *FCMProvider {
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(f.key)})
+	return NewFCMProvider(FCMConfig{
+		ProjectID:   "goat",
+		ClientEmail: "push@goat.iam",
+		PrivateKey:  string(keyPEM),
+		TokenURL:    f.srv.URL + "/token",
+		BaseURL:     f.srv.URL,
+	}, f.srv.Client())
+}
+
+func TestFCMProvider_SendCachesAccessToken(t *testing.T) {
+	f := newFakeFCM(t)
+	p := f.provider()
+
+	msg := push.Message{Token: "live", Title: "Team", Body: "Alice: hi", CollapseKey: "chat-group-7"}
+	require.NoError(t, p.Send(context.Background(), msg))
+	require.NoError(t, p.Send(context.Background(), msg))
+
+	assert.Equal(t, int32(1), f.exchanges.Load())
+	assert.Equal(t, "chat-group-7", f.last.Message.Android.CollapseKey)
+	assert.Equal(t, "Alice: hi", f.last.Message.Notification.Body)
+}
+
+func TestFCMProvider_UnregisteredTokenIsRejected(t *testing.T) {
+	f := newFakeFCM(t)
+
+	err := f.provider().Send(context.Background(), push.Message{Token: "stale"})
+	assert.ErrorIs(t, err, device.ErrPushTokenRejected)
+}
+
+func TestParseFCMCredentials(t *testing.T) {
+	_, err := ParseFCMCredentials([]byte(`{"project_id":"goat"}`))
+	assert.Error(t, err)
+
+	conf, err := ParseFCMCredentials([]byte(`{"project_id":"goat","client_email":"a@b","private_key":"k","token_uri":"https://t"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "goat", conf.ProjectID)
+}
@@ -0,0 +1,46 @@
+package push
+
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      fcmAndroid        `json:"android"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type fcmAndroid struct {
+	CollapseKey string `json:"collapse_key,omitempty"`
+}
+
+// fcmError the error body of the FCM v1 API
+type fcmError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// unregistered reports whether FCM no longer knows the token
+func (e fcmError) unregistered() bool {
+	if e.Error.Status == "NOT_FOUND" {
+		return true
+	}
+	for _, d := range e.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" {
+			return true
+		}
+	}
+	return false
+}
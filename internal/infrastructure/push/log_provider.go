@@ -0,0 +1,46 @@
+package push
+
+import (
+	"context"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/push"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"go.uber.org/zap"
+)
+
+// LogProvider only logs notifications. It reaches every platform and is used
+// when no push service is configured, e.g. in development.
+type LogProvider struct{}
+
+var _ push.Provider = (*LogProvider)(nil)
+
+func NewLogProvider() *LogProvider {
+	return &LogProvider{}
+}
+
+func (p *LogProvider) Name() string {
+	return "log"
+}
+
+func (p *LogProvider) Supports(device.Platform) bool {
+	return true
+}
+
+func (p *LogProvider) Send(_ context.Context, msg push.Message) error {
+	logger.Log.Info("push notification",
+		zap.String("token", tokenPrefix(msg.Token)),
+		zap.String("title", msg.Title),
+		zap.String("body", msg.Body),
+		zap.String("collapse_key", msg.CollapseKey),
+	)
+	return nil
+}
+
+// tokenPrefix keeps enough of a push token to tell devices apart in logs
+func tokenPrefix(token string) string {
+	if len(token) > 8 {
+		return token[:8] + "…"
+	}
+	return token
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/HiroLiang/goat-server/internal/application/shared/security"
+	domainSecurity "github.com/HiroLiang/goat-server/internal/domain/security"
 )
 
 type RateLimiter struct{}
@@ -14,10 +15,38 @@ func MockRateLimiter() security.RateLimiter {
 
 var _ security.RateLimiter = (*RateLimiter)(nil)
 
-func (m RateLimiter) CheckGlobal(_ context.Context) error {
+func (m RateLimiter) CheckGlobal(_ context.Context) (domainSecurity.RateLimitResult, error) {
+	return domainSecurity.RateLimitResult{}, nil
+}
+
+func (m RateLimiter) CheckIP(_ context.Context, _ string) (domainSecurity.RateLimitResult, error) {
+	return domainSecurity.RateLimitResult{}, nil
+}
+
+func (m RateLimiter) CheckUser(_ context.Context, _ string) (domainSecurity.RateLimitResult, error) {
+	return domainSecurity.RateLimitResult{}, nil
+}
+
+func (m RateLimiter) CheckRoute(_ context.Context, _, _ string) (domainSecurity.RateLimitResult, error) {
+	return domainSecurity.RateLimitResult{}, nil
+}
+
+type LoginRateLimiter struct{}
+
+func MockLoginRateLimiter() security.LoginRateLimiter {
+	return LoginRateLimiter{}
+}
+
+var _ security.LoginRateLimiter = (*LoginRateLimiter)(nil)
+
+func (m LoginRateLimiter) CheckLoginAttempt(_ context.Context, _, _ string) error {
+	return nil
+}
+
+func (m LoginRateLimiter) RecordLoginAttempt(_ context.Context, _, _ string, _ bool) error {
 	return nil
 }
 
-func (m RateLimiter) CheckIP(_ context.Context, _ string) error {
+func (m LoginRateLimiter) ReleaseLock(_ context.Context, _ string) error {
 	return nil
 }
@@ -0,0 +1,89 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	securityApp "github.com/HiroLiang/goat-server/internal/application/shared/security"
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/cache"
+)
+
+// RedisActionTokenService keeps action tokens in the cache under their HMAC, so
+// a leaked cache never reveals a usable token.
+type RedisActionTokenService struct {
+	cache  cache.Cache
+	hmacer securityApp.HMACer
+}
+
+func NewRedisActionTokenService(cache cache.Cache, hmacer securityApp.HMACer) *RedisActionTokenService {
+	return &RedisActionTokenService{cache: cache, hmacer: hmacer}
+}
+
+var _ securityApp.ActionTokenService = (*RedisActionTokenService)(nil)
+
+func (s RedisActionTokenService) Issue(
+	ctx context.Context,
+	purpose security.TokenPurpose,
+	subject string,
+	ttl time.Duration,
+) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	digest := s.hmacer.Sign(token)
+
+	// Drop the previous token of the subject, only the latest link works
+	latestKey := latestTokenKey(purpose, subject)
+	previous, ok, err := s.cache.Get(ctx, latestKey)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		if err := s.cache.Delete(ctx, tokenKey(purpose, string(previous))); err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.cache.Set(ctx, tokenKey(purpose, digest), []byte(subject), ttl); err != nil {
+		return "", err
+	}
+	if err := s.cache.Set(ctx, latestKey, []byte(digest), ttl); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s RedisActionTokenService) Consume(
+	ctx context.Context,
+	purpose security.TokenPurpose,
+	token string,
+) (string, error) {
+	// Taking the token is the check, so concurrent requests cannot both use it
+	subject, ok, err := s.cache.Take(ctx, tokenKey(purpose, s.hmacer.Sign(token)))
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", security.ErrInvalidActionToken
+	}
+
+	if err := s.cache.Delete(ctx, latestTokenKey(purpose, string(subject))); err != nil {
+		return "", err
+	}
+
+	return string(subject), nil
+}
+
+func tokenKey(purpose security.TokenPurpose, digest string) string {
+	return "action_token:" + string(purpose) + ":" + digest
+}
+
+func latestTokenKey(purpose security.TokenPurpose, subject string) string {
+	return "action_token:" + string(purpose) + ":latest:" + subject
+}
@@ -0,0 +1,93 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+	redisInfra "github.com/HiroLiang/goat-server/internal/infrastructure/persistence/redis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestActionTokens() (*RedisActionTokenService, *memCache) {
+	c := &memCache{values: map[string][]byte{}}
+	return NewRedisActionTokenService(c, NewSHA256HMACer("secret")), c
+}
+
+func TestActionToken_SingleUse(t *testing.T) {
+	ctx := context.Background()
+	s, c := newTestActionTokens()
+
+	token, err := s.Issue(ctx, security.PurposeResetPassword, "a@b.com", time.Minute)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	for key := range c.values {
+		if len(key) >= len(token) && key[len(key)-len(token):] == token {
+			t.Fatalf("token stored in plain text under %q", key)
+		}
+	}
+
+	if _, err := s.Consume(ctx, security.PurposeVerifyEmail, token); !errors.Is(err, security.ErrInvalidActionToken) {
+		t.Errorf("Consume() other purpose = %v, want ErrInvalidActionToken", err)
+	}
+
+	subject, err := s.Consume(ctx, security.PurposeResetPassword, token)
+	if err != nil || subject != "a@b.com" {
+		t.Fatalf("Consume() = %q, %v, want a@b.com", subject, err)
+	}
+
+	if _, err := s.Consume(ctx, security.PurposeResetPassword, token); !errors.Is(err, security.ErrInvalidActionToken) {
+		t.Errorf("Consume() twice = %v, want ErrInvalidActionToken", err)
+	}
+}
+
+func TestActionToken_ReissueInvalidatesPrevious(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestActionTokens()
+
+	first, _ := s.Issue(ctx, security.PurposeVerifyEmail, "a@b.com", time.Minute)
+	second, _ := s.Issue(ctx, security.PurposeVerifyEmail, "a@b.com", time.Minute)
+
+	if _, err := s.Consume(ctx, security.PurposeVerifyEmail, first); !errors.Is(err, security.ErrInvalidActionToken) {
+		t.Errorf("Consume() first = %v, want ErrInvalidActionToken", err)
+	}
+	if _, err := s.Consume(ctx, security.PurposeVerifyEmail, second); err != nil {
+		t.Errorf("Consume() second = %v, want nil", err)
+	}
+}
+
+func TestActionToken_ConcurrentConsumeOnRedis(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	s := NewRedisActionTokenService(redisInfra.NewRedisCache(client), NewSHA256HMACer("secret"))
+
+	token, err := s.Issue(ctx, security.PurposeResetPassword, "a@b.com", time.Minute)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	var consumed atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.Consume(ctx, security.PurposeResetPassword, token); err == nil {
+				consumed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := consumed.Load(); got != 1 {
+		t.Errorf("Consume() succeeded %d times, want once", got)
+	}
+}
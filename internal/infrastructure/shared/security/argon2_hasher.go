@@ -1,50 +1,211 @@
 package security
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
+	"fmt"
+	"strconv"
 	"strings"
 
+	securityApp "github.com/HiroLiang/goat-server/internal/application/shared/security"
 	"golang.org/x/crypto/argon2"
 )
 
-type Argon2Hasher struct{}
+// Argon2Params cost of new hashes. Memory is in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params the OWASP recommended argon2id baseline
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
 
-func NewArgon2Hasher() *Argon2Hasher { return &Argon2Hasher{} }
+// legacyParams the parameters of hashes stored as "salt:hash", before they were encoded in the hash
+var legacyParams = Argon2Params{Memory: 64 * 1024, Iterations: 1, Parallelism: 4, KeyLength: 32}
+
+// Argon2Hasher hashes passwords with argon2id in the PHC string format
+// $argon2id$v=19$m=65536,t=3,p=4$salt$hash. With a pepper, the password is
+// HMAC-ed with it first and the hash carries keyid=<pepper id>, so hashes made
+// before the pepper was set keep verifying and get upgraded on the next login.
+type Argon2Hasher struct {
+	params   Argon2Params
+	pepper   []byte
+	pepperID string
+}
+
+var _ securityApp.Hasher = (*Argon2Hasher)(nil)
+
+// NewArgon2Hasher creates a hasher. An empty pepper disables peppering.
+func NewArgon2Hasher(params Argon2Params, pepper, pepperID string) *Argon2Hasher {
+	h := &Argon2Hasher{params: params}
+	if pepper != "" {
+		h.pepper = []byte(pepper)
+		h.pepperID = pepperID
+	}
+	return h
+}
 
 func (h *Argon2Hasher) Hash(password string) (string, error) {
-	salt := make([]byte, 16)
+	salt := make([]byte, h.params.SaltLength)
 	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
 
-	hash := h.getArgon2Hash(password, salt)
-	encoded := base64.RawStdEncoding.EncodeToString(hash)
-	encodedSalt := base64.RawStdEncoding.EncodeToString(salt)
-	return encodedSalt + ":" + encoded, nil
+	hash := argon2Key(h.peppered(password, h.pepperID), salt, h.params)
+
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", h.params.Memory, h.params.Iterations, h.params.Parallelism)
+	if h.pepperID != "" {
+		params += ",keyid=" + h.pepperID
+	}
+
+	return fmt.Sprintf("$argon2id$v=%d$%s$%s$%s",
+		argon2.Version,
+		params,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
 }
 
-func (h *Argon2Hasher) Verify(hashed, plain string) bool {
-	parts := strings.Split(plain, ":")
-	if len(parts) != 2 {
+func (h *Argon2Hasher) Verify(password, encoded string) bool {
+	d, err := decodeHash(encoded)
+	if err != nil {
+		return false
+	}
+
+	// A hash peppered with another key can never match
+	if d.keyID != "" && d.keyID != h.pepperID {
 		return false
 	}
 
+	hash := argon2Key(h.peppered(password, d.keyID), d.salt, d.params)
+	return subtle.ConstantTimeCompare(d.hash, hash) == 1
+}
+
+// NeedsRehash reports whether the hash is in the legacy format, cheaper than
+// the current parameters, or not peppered with the current pepper.
+func (h *Argon2Hasher) NeedsRehash(encoded string) bool {
+	d, err := decodeHash(encoded)
+	if err != nil {
+		return true
+	}
+
+	return d.legacy ||
+		d.params.Memory < h.params.Memory ||
+		d.params.Iterations < h.params.Iterations ||
+		d.params.Parallelism < h.params.Parallelism ||
+		d.params.KeyLength < h.params.KeyLength ||
+		len(d.salt) < int(h.params.SaltLength) ||
+		d.keyID != h.pepperID
+}
+
+// peppered HMACs the password with the pepper when the hash uses one
+func (h *Argon2Hasher) peppered(password, keyID string) []byte {
+	if keyID == "" {
+		return []byte(password)
+	}
+	mac := hmac.New(sha256.New, h.pepper)
+	mac.Write([]byte(password))
+	return mac.Sum(nil)
+}
+
+type decodedHash struct {
+	params Argon2Params
+	keyID  string
+	salt   []byte
+	hash   []byte
+	legacy bool
+}
+
+func decodeHash(encoded string) (*decodedHash, error) {
+	if !strings.HasPrefix(encoded, "$") {
+		return decodeLegacyHash(encoded)
+	}
+
+	// "", "argon2id", "v=19", params, salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, fmt.Errorf("unsupported hash format")
+	}
+
+	if parts[2] != "v="+strconv.Itoa(argon2.Version) {
+		return nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+
+	d := &decodedHash{}
+	for _, kv := range strings.Split(parts[3], ",") {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid argon2 parameter %q", kv)
+		}
+		if key == "keyid" {
+			d.keyID = value
+			continue
+		}
+
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argon2 parameter %q", kv)
+		}
+		switch key {
+		case "m":
+			d.params.Memory = uint32(n)
+		case "t":
+			d.params.Iterations = uint32(n)
+		case "p":
+			if n == 0 || n > 255 {
+				return nil, fmt.Errorf("invalid argon2 parallelism %d", n)
+			}
+			d.params.Parallelism = uint8(n)
+		}
+	}
+	if d.params.Memory == 0 || d.params.Iterations == 0 || d.params.Parallelism == 0 {
+		return nil, fmt.Errorf("missing argon2 parameters")
+	}
+
+	var err error
+	if d.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, err
+	}
+	if d.hash, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, err
+	}
+	d.params.KeyLength = uint32(len(d.hash))
+	d.params.SaltLength = uint32(len(d.salt))
+
+	return d, nil
+}
+
+func decodeLegacyHash(encoded string) (*decodedHash, error) {
+	parts := strings.Split(encoded, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("unsupported hash format")
+	}
+
 	salt, err := base64.RawStdEncoding.DecodeString(parts[0])
 	if err != nil {
-		return false
+		return nil, err
 	}
 
 	hash, err := base64.RawStdEncoding.DecodeString(parts[1])
 	if err != nil {
-		return false
+		return nil, err
 	}
 
-	newHash := h.getArgon2Hash(hashed, salt)
-	return subtle.ConstantTimeCompare(hash, newHash) == 1
+	return &decodedHash{params: legacyParams, salt: salt, hash: hash, legacy: true}, nil
 }
 
-func (h *Argon2Hasher) getArgon2Hash(str string, salt []byte) []byte {
-	return argon2.IDKey([]byte(str), salt, 1, 64*1024, 4, 32)
+func argon2Key(password, salt []byte, params Argon2Params) []byte {
+	return argon2.IDKey(password, salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
 }
@@ -1,9 +1,19 @@
 package security
 
-import "testing"
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// testParams keep the tests fast
+var testParams = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
 
 func TestArgon2(t *testing.T) {
-	hasher := NewArgon2Hasher()
+	hasher := NewArgon2Hasher(testParams, "", "")
 
 	input := "password"
 	got, err := hasher.Hash(input)
@@ -12,6 +22,10 @@ func TestArgon2(t *testing.T) {
 		return
 	}
 
+	if !strings.HasPrefix(got, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Hash() = %q, want PHC format", got)
+	}
+
 	if isValid := hasher.Verify(input, got); !isValid {
 		t.Errorf("VerifyArgon2Base64() = %v, want %v", isValid, true)
 	}
@@ -19,4 +33,67 @@ func TestArgon2(t *testing.T) {
 	if isValid := hasher.Verify("wrong", got); isValid {
 		t.Errorf("VerifyArgon2Base64() = %v, want %v", isValid, false)
 	}
+
+	if hasher.NeedsRehash(got) {
+		t.Errorf("NeedsRehash() = true for a current hash")
+	}
+}
+
+func TestArgon2_LegacyFormat(t *testing.T) {
+	salt := make([]byte, 16)
+	_, _ = rand.Read(salt)
+	hash := argon2.IDKey([]byte("password"), salt, 1, 64*1024, 4, 32)
+	legacy := base64.RawStdEncoding.EncodeToString(salt) + ":" + base64.RawStdEncoding.EncodeToString(hash)
+
+	hasher := NewArgon2Hasher(testParams, "", "")
+
+	if !hasher.Verify("password", legacy) {
+		t.Errorf("Verify() legacy = false, want true")
+	}
+	if hasher.Verify("wrong", legacy) {
+		t.Errorf("Verify() legacy wrong password = true, want false")
+	}
+	if !hasher.NeedsRehash(legacy) {
+		t.Errorf("NeedsRehash() legacy = false, want true")
+	}
+}
+
+func TestArgon2_UpgradeParams(t *testing.T) {
+	old := NewArgon2Hasher(testParams, "", "")
+	hash, _ := old.Hash("password")
+
+	stronger := testParams
+	stronger.Iterations = 2
+	current := NewArgon2Hasher(stronger, "", "")
+
+	if !current.Verify("password", hash) {
+		t.Errorf("Verify() with older params = false, want true")
+	}
+	if !current.NeedsRehash(hash) {
+		t.Errorf("NeedsRehash() with older params = false, want true")
+	}
+}
+
+func TestArgon2_Pepper(t *testing.T) {
+	plain := NewArgon2Hasher(testParams, "", "")
+	peppered := NewArgon2Hasher(testParams, "pepper", "1")
+
+	unpepperedHash, _ := plain.Hash("password")
+	if !peppered.Verify("password", unpepperedHash) || !peppered.NeedsRehash(unpepperedHash) {
+		t.Errorf("hash from before the pepper should verify and need a rehash")
+	}
+
+	hash, _ := peppered.Hash("password")
+	if !strings.Contains(hash, ",keyid=1$") {
+		t.Errorf("Hash() = %q, want keyid", hash)
+	}
+	if !peppered.Verify("password", hash) || peppered.NeedsRehash(hash) {
+		t.Errorf("peppered hash should verify without rehash")
+	}
+	if plain.Verify("password", hash) {
+		t.Errorf("Verify() without the pepper = true, want false")
+	}
+	if NewArgon2Hasher(testParams, "other", "2").Verify("password", hash) {
+		t.Errorf("Verify() with another pepper = true, want false")
+	}
 }
@@ -0,0 +1,136 @@
+package security
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	securityApp "github.com/HiroLiang/goat-server/internal/application/shared/security"
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/cache"
+)
+
+// lockLevelTTL how long a lock level is remembered, so repeat offenders keep
+// getting longer lockouts until they stay quiet for a day
+const lockLevelTTL = 24 * time.Hour
+
+// RedisLoginRateLimiter counts failed logins per email and per IP and locks
+// either one out with exponentially growing windows.
+type RedisLoginRateLimiter struct {
+	counters    security.RateLimitRepository
+	locks       cache.Cache
+	emailPolicy security.LoginLockPolicy
+	ipPolicy    security.LoginLockPolicy
+}
+
+func NewRedisLoginRateLimiter(
+	counters security.RateLimitRepository,
+	locks cache.Cache,
+	emailPolicy security.LoginLockPolicy,
+	ipPolicy security.LoginLockPolicy,
+) *RedisLoginRateLimiter {
+	return &RedisLoginRateLimiter{
+		counters:    counters,
+		locks:       locks,
+		emailPolicy: emailPolicy,
+		ipPolicy:    ipPolicy,
+	}
+}
+
+var _ securityApp.LoginRateLimiter = (*RedisLoginRateLimiter)(nil)
+
+// CheckLoginAttempt returns *security.AccountLockedError while the email or the IP is locked.
+func (limiter RedisLoginRateLimiter) CheckLoginAttempt(ctx context.Context, ip, email string) error {
+	if err := limiter.checkLock(ctx, emailKey(email)); err != nil {
+		return err
+	}
+	return limiter.checkLock(ctx, ipKey(ip))
+}
+
+// RecordLoginAttempt unlocks the email on success and counts a failure otherwise.
+// Successes do not clear the IP counter, so one valid account does not hide
+// stuffing of many others from the same address.
+func (limiter RedisLoginRateLimiter) RecordLoginAttempt(ctx context.Context, ip, email string, success bool) error {
+	if success {
+		return limiter.release(ctx, emailKey(email))
+	}
+
+	if err := limiter.recordFailure(ctx, emailKey(email), limiter.emailPolicy); err != nil {
+		return err
+	}
+	return limiter.recordFailure(ctx, ipKey(ip), limiter.ipPolicy)
+}
+
+// ReleaseLock lifts the lock of an email and forgets its failures and lock level.
+func (limiter RedisLoginRateLimiter) ReleaseLock(ctx context.Context, email string) error {
+	return limiter.release(ctx, emailKey(email))
+}
+
+func (limiter RedisLoginRateLimiter) checkLock(ctx context.Context, key string) error {
+	b, ok, err := limiter.locks.Get(ctx, "login_lock:"+key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	until, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return err
+	}
+
+	retryAfter := time.Until(time.Unix(until, 0))
+	if retryAfter <= 0 {
+		return nil
+	}
+
+	return &security.AccountLockedError{RetryAfter: retryAfter}
+}
+
+func (limiter RedisLoginRateLimiter) recordFailure(
+	ctx context.Context,
+	key string,
+	policy security.LoginLockPolicy,
+) error {
+	failures, err := limiter.counters.Increment(ctx, "login_fail:"+key, policy.Window)
+	if err != nil {
+		return err
+	}
+	if failures < policy.MaxFailures {
+		return nil
+	}
+
+	// Lock out and start counting again for the next lock
+	level, err := limiter.counters.Increment(ctx, "login_lock_level:"+key, lockLevelTTL)
+	if err != nil {
+		return err
+	}
+
+	lockout := policy.LockoutFor(level)
+	until := time.Now().Add(lockout).Unix()
+	if err := limiter.locks.Set(ctx, "login_lock:"+key, []byte(strconv.FormatInt(until, 10)), lockout); err != nil {
+		return err
+	}
+
+	return limiter.counters.Reset(ctx, "login_fail:"+key)
+}
+
+func (limiter RedisLoginRateLimiter) release(ctx context.Context, key string) error {
+	if err := limiter.locks.Delete(ctx, "login_lock:"+key); err != nil {
+		return err
+	}
+	if err := limiter.counters.Reset(ctx, "login_fail:"+key); err != nil {
+		return err
+	}
+	return limiter.counters.Reset(ctx, "login_lock_level:"+key)
+}
+
+func emailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,161 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+)
+
+type memCounters struct {
+	counts map[string]int64
+}
+
+func (m *memCounters) Increment(_ context.Context, key string, _ time.Duration) (int64, error) {
+	m.counts[key]++
+	return m.counts[key], nil
+}
+
+func (m *memCounters) Get(_ context.Context, key string) (int64, error) {
+	return m.counts[key], nil
+}
+
+func (m *memCounters) Reset(_ context.Context, key string) error {
+	delete(m.counts, key)
+	return nil
+}
+
+func (m *memCounters) IncrementSliding(ctx context.Context, key string, window time.Duration, _ time.Time) (int64, error) {
+	return m.Increment(ctx, key, window)
+}
+
+// Take counts like a fixed window, without expiry
+func (m *memCounters) Take(_ context.Context, key string, policy security.RateLimitPolicy, _ time.Time) (security.RateLimitResult, error) {
+	result := security.RateLimitResult{Limit: policy.Limit, Reset: policy.Window}
+	if m.counts[key] >= policy.Limit {
+		result.RetryAfter = policy.Window
+		return result, nil
+	}
+
+	m.counts[key]++
+	result.Remaining = policy.Limit - m.counts[key]
+	return result, nil
+}
+
+type memCache struct {
+	values map[string][]byte
+}
+
+func (m *memCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	v, ok := m.values[key]
+	return v, ok, nil
+}
+
+func (m *memCache) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	m.values[key] = value
+	return nil
+}
+
+func (m *memCache) Delete(_ context.Context, key string) error {
+	delete(m.values, key)
+	return nil
+}
+
+func (m *memCache) Take(_ context.Context, key string) ([]byte, bool, error) {
+	v, ok := m.values[key]
+	delete(m.values, key)
+	return v, ok, nil
+}
+
+func newTestLoginLimiter() *RedisLoginRateLimiter {
+	policy := security.LoginLockPolicy{
+		MaxFailures: 3,
+		Window:      time.Minute,
+		BaseLockout: time.Minute,
+		MaxLockout:  time.Hour,
+	}
+	ipPolicy := policy
+	ipPolicy.MaxFailures = 10
+	return NewRedisLoginRateLimiter(
+		&memCounters{counts: map[string]int64{}},
+		&memCache{values: map[string][]byte{}},
+		policy,
+		ipPolicy,
+	)
+}
+
+func failLogins(t *testing.T, limiter *RedisLoginRateLimiter, ip, email string, n int) {
+	for i := 0; i < n; i++ {
+		if err := limiter.RecordLoginAttempt(context.Background(), ip, email, false); err != nil {
+			t.Fatalf("RecordLoginAttempt() error = %v", err)
+		}
+	}
+}
+
+func TestLoginRateLimiter_LocksEmailWithGrowingLockout(t *testing.T) {
+	ctx := context.Background()
+	limiter := newTestLoginLimiter()
+
+	failLogins(t, limiter, "10.0.0.1", "a@b.com", 2)
+	if err := limiter.CheckLoginAttempt(ctx, "10.0.0.1", "a@b.com"); err != nil {
+		t.Fatalf("CheckLoginAttempt() before threshold = %v, want nil", err)
+	}
+
+	failLogins(t, limiter, "10.0.0.1", "a@b.com", 1)
+	err := limiter.CheckLoginAttempt(ctx, "10.0.0.2", "A@B.com ")
+	var locked *security.AccountLockedError
+	if !errors.As(err, &locked) || !errors.Is(err, security.ErrAccountLocked) {
+		t.Fatalf("CheckLoginAttempt() = %v, want AccountLockedError", err)
+	}
+	if locked.RetryAfter <= 0 || locked.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %v, want within first lockout", locked.RetryAfter)
+	}
+
+	// Second lock doubles the lockout
+	failLogins(t, limiter, "10.0.0.1", "a@b.com", 3)
+	err = limiter.CheckLoginAttempt(ctx, "10.0.0.2", "a@b.com")
+	if !errors.As(err, &locked) || locked.RetryAfter <= time.Minute {
+		t.Errorf("CheckLoginAttempt() = %v, want lockout over a minute", err)
+	}
+}
+
+func TestLoginRateLimiter_ReleaseAndSuccessUnlock(t *testing.T) {
+	ctx := context.Background()
+	limiter := newTestLoginLimiter()
+
+	failLogins(t, limiter, "10.0.0.1", "a@b.com", 3)
+	if err := limiter.ReleaseLock(ctx, "a@b.com"); err != nil {
+		t.Fatalf("ReleaseLock() error = %v", err)
+	}
+	if err := limiter.CheckLoginAttempt(ctx, "10.0.0.1", "a@b.com"); err != nil {
+		t.Errorf("CheckLoginAttempt() after release = %v, want nil", err)
+	}
+
+	failLogins(t, limiter, "10.0.0.1", "a@b.com", 2)
+	if err := limiter.RecordLoginAttempt(ctx, "10.0.0.1", "a@b.com", true); err != nil {
+		t.Fatalf("RecordLoginAttempt() error = %v", err)
+	}
+	failLogins(t, limiter, "10.0.0.1", "a@b.com", 2)
+	if err := limiter.CheckLoginAttempt(ctx, "10.0.0.1", "a@b.com"); err != nil {
+		t.Errorf("CheckLoginAttempt() after success = %v, want failures reset", err)
+	}
+}
+
+func TestLoginRateLimiter_LocksIPAcrossEmails(t *testing.T) {
+	ctx := context.Background()
+	limiter := newTestLoginLimiter()
+
+	for i := 0; i < 10; i++ {
+		email := string(rune('a'+i)) + "@b.com"
+		failLogins(t, limiter, "10.0.0.9", email, 1)
+	}
+
+	if err := limiter.CheckLoginAttempt(ctx, "10.0.0.9", "new@b.com"); !errors.Is(err, security.ErrAccountLocked) {
+		t.Errorf("CheckLoginAttempt() = %v, want IP locked", err)
+	}
+	if err := limiter.CheckLoginAttempt(ctx, "10.0.0.8", "new@b.com"); err != nil {
+		t.Errorf("CheckLoginAttempt() other IP = %v, want nil", err)
+	}
+}
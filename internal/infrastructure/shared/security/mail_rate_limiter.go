@@ -0,0 +1,37 @@
+package security
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	securityApp "github.com/HiroLiang/goat-server/internal/application/shared/security"
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+)
+
+// RedisMailRateLimiter limits the mails sent to one address within a sliding window
+type RedisMailRateLimiter struct {
+	redis  security.RateLimitRepository
+	policy security.RateLimitPolicy
+}
+
+func NewRedisMailRateLimiter(redis security.RateLimitRepository, policy security.RateLimitPolicy) *RedisMailRateLimiter {
+	return &RedisMailRateLimiter{redis: redis, policy: policy}
+}
+
+var _ securityApp.MailRateLimiter = (*RedisMailRateLimiter)(nil)
+
+func (limiter RedisMailRateLimiter) CheckMail(ctx context.Context, email string) error {
+	key := "mail:" + strings.ToLower(strings.TrimSpace(email))
+
+	c, err := limiter.redis.IncrementSliding(ctx, key, limiter.policy.Window, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if c > limiter.policy.Limit {
+		return security.ErrRateLimitExceeded
+	}
+
+	return nil
+}
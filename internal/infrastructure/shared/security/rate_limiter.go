@@ -9,55 +9,63 @@ import (
 )
 
 type RedisRateLimiter struct {
-	redis        security.RateLimitRepository
-	globalPolicy security.RateLimitPolicy
-	ipPolicy     security.RateLimitPolicy
+	redis         security.RateLimitRepository
+	globalPolicy  security.RateLimitPolicy
+	ipPolicy      security.RateLimitPolicy
+	userPolicy    security.RateLimitPolicy
+	routePolicies map[string]security.RateLimitPolicy
 }
 
+// NewRedisRateLimiter routePolicies maps routes such as "POST /api/user/login" to
+// their own policy, counted on top of the others
 func NewRedisRateLimiter(
 	redis security.RateLimitRepository,
 	globalPolicy security.RateLimitPolicy,
 	ipPolicy security.RateLimitPolicy,
+	userPolicy security.RateLimitPolicy,
+	routePolicies map[string]security.RateLimitPolicy,
 ) *RedisRateLimiter {
-	return &RedisRateLimiter{redis, globalPolicy, ipPolicy}
+	return &RedisRateLimiter{
+		redis:         redis,
+		globalPolicy:  globalPolicy,
+		ipPolicy:      ipPolicy,
+		userPolicy:    userPolicy,
+		routePolicies: routePolicies,
+	}
 }
 
 var _ securityApp.RateLimiter = (*RedisRateLimiter)(nil)
 
-func (limiter RedisRateLimiter) CheckGlobal(ctx context.Context) error {
-
-	// Increment global rate limit counter with the sliding window and get count
-	c, err := limiter.redis.IncrementSliding(ctx, "global", limiter.globalPolicy.Window, time.Now())
-	if err != nil {
-		return err
-	}
-
-	// Check if the count exceeds the limit
-	if c > limiter.globalPolicy.Limit {
-		return security.ErrRateLimitExceeded
-	}
+func (limiter RedisRateLimiter) CheckGlobal(ctx context.Context) (security.RateLimitResult, error) {
+	return limiter.take(ctx, "global", limiter.globalPolicy)
+}
 
-	return nil
+func (limiter RedisRateLimiter) CheckIP(ctx context.Context, ip string) (security.RateLimitResult, error) {
+	return limiter.take(ctx, "ip:"+ip, limiter.ipPolicy)
 }
 
-func (limiter RedisRateLimiter) CheckIP(ctx context.Context, ip string) error {
+func (limiter RedisRateLimiter) CheckUser(ctx context.Context, userID string) (security.RateLimitResult, error) {
+	return limiter.take(ctx, "user:"+userID, limiter.userPolicy)
+}
 
-	// get count
-	c, err := limiter.redis.Get(ctx, "ip:"+ip)
-	if err != nil {
-		return err
+func (limiter RedisRateLimiter) CheckRoute(ctx context.Context, route, key string) (security.RateLimitResult, error) {
+	policy, ok := limiter.routePolicies[route]
+	if !ok {
+		return security.RateLimitResult{}, nil
 	}
 
-	// Check if the count exceeds the limit
-	if c > limiter.ipPolicy.Limit {
-		return security.ErrRateLimitExceeded
-	}
+	return limiter.take(ctx, "route:"+route+":"+key, policy)
+}
 
-	// Increment IP rate limit counter
-	c, err = limiter.redis.Increment(ctx, "ip:"+ip, limiter.ipPolicy.Window)
+// take counts a request for key and fails once the policy is exceeded
+func (limiter RedisRateLimiter) take(ctx context.Context, key string, policy security.RateLimitPolicy) (security.RateLimitResult, error) {
+	result, err := limiter.redis.Take(ctx, key, policy, time.Now())
 	if err != nil {
-		return err
+		return security.RateLimitResult{}, err
 	}
 
-	return nil
+	if result.Exceeded() {
+		return result, security.ErrRateLimitExceeded
+	}
+	return result, nil
 }
@@ -0,0 +1,63 @@
+package security
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRateLimiter() *RedisRateLimiter {
+	policy := security.RateLimitPolicy{Limit: 2, Window: time.Minute}
+	return NewRedisRateLimiter(
+		&memCounters{counts: map[string]int64{}},
+		policy,
+		policy,
+		policy,
+		map[string]security.RateLimitPolicy{
+			"POST /api/user/login": {Limit: 1, Window: time.Hour},
+		},
+	)
+}
+
+func TestRateLimiter_CheckUser_CountsDownToLimit(t *testing.T) {
+	limiter := newTestRateLimiter()
+	ctx := context.Background()
+
+	result, err := limiter.CheckUser(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, security.RateLimitResult{Limit: 2, Remaining: 1, Reset: time.Minute}, result)
+
+	_, err = limiter.CheckUser(ctx, "1")
+	assert.NoError(t, err)
+
+	result, err = limiter.CheckUser(ctx, "1")
+	assert.ErrorIs(t, err, security.ErrRateLimitExceeded)
+	assert.Zero(t, result.Remaining)
+	assert.True(t, result.Exceeded())
+	assert.Equal(t, time.Minute, result.RetryAfter)
+
+	_, err = limiter.CheckUser(ctx, "2")
+	assert.NoError(t, err, "users are counted apart")
+}
+
+func TestRateLimiter_CheckRoute(t *testing.T) {
+	limiter := newTestRateLimiter()
+	ctx := context.Background()
+
+	result, err := limiter.CheckRoute(ctx, "GET /api/user/me", "user:1")
+	assert.NoError(t, err)
+	assert.Zero(t, result, "routes without a policy are not counted")
+
+	result, err = limiter.CheckRoute(ctx, "POST /api/user/login", "ip:10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), result.Remaining)
+
+	_, err = limiter.CheckRoute(ctx, "POST /api/user/login", "ip:10.0.0.1")
+	assert.ErrorIs(t, err, security.ErrRateLimitExceeded)
+
+	_, err = limiter.CheckRoute(ctx, "POST /api/user/login", "ip:10.0.0.2")
+	assert.NoError(t, err)
+}
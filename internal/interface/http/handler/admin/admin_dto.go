@@ -0,0 +1,58 @@
+package admin
+
+import "github.com/HiroLiang/goat-server/internal/domain/role"
+
+// ApplicantResponse a user waiting for approval
+type ApplicantResponse struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	IP        string `json:"ip"`
+	AppliedAt string `json:"applied_at"`
+}
+
+type ListApplicantsResponse struct {
+	Applicants []ApplicantResponse `json:"applicants"`
+}
+
+// ChangeStatusRequest optional reason of a status change, required to reject
+type ChangeStatusRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// StatusChangeResponse one status transition, actor_id 0 means the system
+type StatusChangeResponse struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Reason    string `json:"reason,omitempty"`
+	ActorID   int64  `json:"actor_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+type StatusHistoryResponse struct {
+	Changes []StatusChangeResponse `json:"changes"`
+}
+
+// UserRoleRequest the role to assign or revoke: admin, vendor, user or guest
+type UserRoleRequest struct {
+	Role role.Type `json:"role" binding:"required"`
+}
+
+type UserRolesResponse struct {
+	Roles []string `json:"roles"`
+}
+
+// RoleResponse a role and whether its members must use 2FA
+type RoleResponse struct {
+	Type             string `json:"type"`
+	RequireTwoFactor bool   `json:"require_two_factor"`
+}
+
+type ListRolesResponse struct {
+	Roles []RoleResponse `json:"roles"`
+}
+
+// RoleTwoFactorRequest turns the 2FA requirement of a role on or off
+type RoleTwoFactorRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
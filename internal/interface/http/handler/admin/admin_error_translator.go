@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/domain/userrole"
+	"github.com/HiroLiang/goat-server/internal/interface/http/response"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func HandleError(c *gin.Context, err error) {
+	logger.Log.Error(err.Error())
+	switch {
+	case errors.Is(err, user.ErrForbidden):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "FORBIDDEN",
+			Message: "permission denied",
+		})
+		return
+
+	case errors.Is(err, user.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, response.ErrNotFound("user"))
+		return
+
+	case errors.Is(err, user.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("user id"))
+		return
+
+	case errors.Is(err, user.ErrInvalidUser):
+		c.JSON(http.StatusForbidden, response.ErrInvalid("user"))
+		return
+
+	case errors.Is(err, user.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, response.ErrorResponse{
+			Code:    "INVALID_TRANSITION",
+			Message: "the user status does not allow this change",
+		})
+		return
+
+	case errors.Is(err, user.ErrReasonRequired):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:    "REASON_REQUIRED",
+			Message: "a reason is required",
+		})
+		return
+
+	case errors.Is(err, userrole.ErrAssignFailed):
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Code:    "ROLE_ASSIGN_FAILED",
+			Message: "the role could not be assigned",
+		})
+		return
+
+	case errors.Is(err, userrole.ErrRevokeFailed):
+		c.JSON(http.StatusInternalServerError, response.ErrorResponse{
+			Code:    "ROLE_REVOKE_FAILED",
+			Message: "the role could not be revoked",
+		})
+		return
+
+	case errors.Is(err, userrole.ErrUserRoleAlreadyAssigned):
+		c.JSON(http.StatusConflict, response.ErrorResponse{
+			Code:    "ROLE_ALREADY_ASSIGNED",
+			Message: "the user already has this role",
+		})
+		return
+
+	case errors.Is(err, role.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, response.ErrNotFound("role"))
+		return
+
+	case errors.Is(err, role.ErrInvalidType):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("role"))
+		return
+
+	default:
+		_ = c.Error(err)
+		return
+	}
+}
@@ -0,0 +1,391 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	userApp "github.com/HiroLiang/goat-server/internal/application/user"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/interface/http/adapter"
+	"github.com/HiroLiang/goat-server/internal/interface/http/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler Rest api for administrators
+type AdminHandler struct {
+	userUseCase *userApp.UseCase
+	policy      auth.PolicyChecker
+}
+
+// NewAdminHandler Create a new AdminHandler instance with dependencies
+func NewAdminHandler(userUseCase *userApp.UseCase, policy auth.PolicyChecker) *AdminHandler {
+	return &AdminHandler{
+		userUseCase: userUseCase,
+		policy:      policy,
+	}
+}
+
+// RegisterAdminRoutes registers admin API routes, the group must require auth
+func (h *AdminHandler) RegisterAdminRoutes(r *gin.RouterGroup) {
+	approve := middleware.RequirePermission(h.policy, permission.UserApprove)
+	ban := middleware.RequirePermission(h.policy, permission.UserBan)
+	manageRoles := middleware.RequirePermission(h.policy, permission.UserManageRoles)
+
+	r.GET("/users/applicants", approve, h.listApplicants)
+	r.GET("/users/:id/status-history", approve, h.getStatusHistory)
+	r.POST("/users/:id/approve", approve, h.approveUser)
+	r.POST("/users/:id/reject", approve, h.rejectUser)
+	r.POST("/users/:id/ban", ban, h.banUser)
+	r.POST("/users/:id/unban", ban, h.unbanUser)
+	r.GET("/users/:id/roles", manageRoles, h.getUserRoles)
+	r.POST("/users/:id/roles", manageRoles, h.assignRole)
+	r.DELETE("/users/:id/roles", manageRoles, h.revokeRole)
+	r.GET("/roles", manageRoles, h.listRoles)
+	r.PUT("/roles/:role/two-factor", manageRoles, h.setRoleTwoFactor)
+}
+
+// @Summary List applicants
+// @Description List the users waiting for registration approval, oldest first.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ListApplicantsResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/applicants [get]
+func (h *AdminHandler) listApplicants(c *gin.Context) {
+	output, err := h.userUseCase.ListApplicants(c.Request.Context(), adapter.BuildEmptyInput(c))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	applicants := make([]ApplicantResponse, 0, len(output.Applicants))
+	for _, a := range output.Applicants {
+		applicants = append(applicants, ApplicantResponse{
+			ID:        int64(a.ID),
+			Name:      a.Name,
+			Email:     a.Email,
+			IP:        a.IP,
+			AppliedAt: a.AppliedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, ListApplicantsResponse{Applicants: applicants})
+}
+
+// @Summary User status history
+// @Description List every status transition of a user with the acting admin, newest first.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} StatusHistoryResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{id}/status-history [get]
+func (h *AdminHandler) getStatusHistory(c *gin.Context) {
+	userID, err := user.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	output, err := h.userUseCase.StatusHistory(
+		c.Request.Context(),
+		adapter.BuildInput(c, userApp.QueryStatusHistoryInput{UserID: userID}),
+	)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	changes := make([]StatusChangeResponse, 0, len(output.Changes))
+	for _, change := range output.Changes {
+		changes = append(changes, StatusChangeResponse{
+			From:      string(change.From),
+			To:        string(change.To),
+			Reason:    change.Reason,
+			ActorID:   int64(change.ActorID),
+			CreatedAt: change.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, StatusHistoryResponse{Changes: changes})
+}
+
+// @Summary Approve an applicant
+// @Description Activate an applying or rejected user and assign the default user role.
+// @Tags Admin
+// @Accept json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param payload body ChangeStatusRequest false "Optional note"
+// @Success 204
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Invalid transition"
+// @Router /api/admin/users/{id}/approve [post]
+func (h *AdminHandler) approveUser(c *gin.Context) {
+	h.changeStatus(c, h.userUseCase.ApproveUser)
+}
+
+// @Summary Reject an applicant
+// @Description Decline an applicant. The reason is shown to the applicant.
+// @Tags Admin
+// @Accept json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param payload body ChangeStatusRequest true "Reason"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse "Reason required"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Invalid transition"
+// @Router /api/admin/users/{id}/reject [post]
+func (h *AdminHandler) rejectUser(c *gin.Context) {
+	h.changeStatus(c, h.userUseCase.RejectUser)
+}
+
+// @Summary Ban a user
+// @Description Block a user from logging in and log out all of their sessions.
+// @Tags Admin
+// @Accept json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param payload body ChangeStatusRequest false "Reason"
+// @Success 204
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Invalid transition"
+// @Router /api/admin/users/{id}/ban [post]
+func (h *AdminHandler) banUser(c *gin.Context) {
+	h.changeStatus(c, h.userUseCase.BanUser)
+}
+
+// @Summary Unban a user
+// @Description Lift the ban of a user, restoring the status they had before it.
+// @Tags Admin
+// @Accept json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param payload body ChangeStatusRequest false "Optional note"
+// @Success 204
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Invalid transition"
+// @Router /api/admin/users/{id}/unban [post]
+func (h *AdminHandler) unbanUser(c *gin.Context) {
+	h.changeStatus(c, h.userUseCase.UnbanUser)
+}
+
+// @Summary User roles
+// @Description List the roles assigned to a user.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} UserRolesResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{id}/roles [get]
+func (h *AdminHandler) getUserRoles(c *gin.Context) {
+	userID, err := user.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	output, err := h.userUseCase.FindRolesByUser(
+		c.Request.Context(),
+		adapter.BuildInput(c, userApp.FindUserRolesInput{UserID: userID}),
+	)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	roles := make([]string, 0, len(output.Roles))
+	for _, r := range output.Roles {
+		roles = append(roles, string(r))
+	}
+
+	c.JSON(http.StatusOK, UserRolesResponse{Roles: roles})
+}
+
+// @Summary Assign a role
+// @Description Assign a role to a user. The change applies to the next request of the user.
+// @Tags Admin
+// @Accept json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param payload body UserRoleRequest true "Role"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 409 {object} response.ErrorResponse "Role already assigned"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{id}/roles [post]
+func (h *AdminHandler) assignRole(c *gin.Context) {
+	userID, req, ok := bindUserRole(c)
+	if !ok {
+		return
+	}
+
+	data := userApp.AssignRoleInput{UserID: userID, Role: req.Role}
+	if err := h.userUseCase.AssignRoleToUser(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Revoke a role
+// @Description Revoke a role from a user. Revoking a role the user does not hold is a no-op.
+// @Tags Admin
+// @Accept json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param payload body UserRoleRequest true "Role"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/users/{id}/roles [delete]
+func (h *AdminHandler) revokeRole(c *gin.Context) {
+	userID, req, ok := bindUserRole(c)
+	if !ok {
+		return
+	}
+
+	data := userApp.RevokeRoleInput{UserID: userID, Role: req.Role}
+	if err := h.userUseCase.RevokeRoleFromUser(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary List roles
+// @Description List every role and whether its members must use 2FA.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ListRolesResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/roles [get]
+func (h *AdminHandler) listRoles(c *gin.Context) {
+	output, err := h.userUseCase.ListRoles(c.Request.Context(), adapter.BuildEmptyInput(c))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	roles := make([]RoleResponse, 0, len(output.Roles))
+	for _, r := range output.Roles {
+		roles = append(roles, RoleResponse{
+			Type:             string(r.Type),
+			RequireTwoFactor: r.RequireTwoFactor,
+		})
+	}
+
+	c.JSON(http.StatusOK, ListRolesResponse{Roles: roles})
+}
+
+// @Summary Require 2FA for a role
+// @Description
+// Require 2FA from the members of a role, or stop requiring it.
+// Members without 2FA keep their session but lose the permissions of the role until they enroll.
+// @Tags Admin
+// @Accept json
+// @Security BearerAuth
+// @Param role path string true "Role: admin, vendor, user or guest"
+// @Param payload body RoleTwoFactorRequest true "Requirement"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Role not found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/admin/roles/{role}/two-factor [put]
+func (h *AdminHandler) setRoleTwoFactor(c *gin.Context) {
+	roleType, err := role.ParseType(c.Param("role"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	var req RoleTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := userApp.SetRoleTwoFactorInput{Role: roleType, Required: *req.Required}
+	if err := h.userUseCase.SetRoleTwoFactor(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// bindUserRole binds the user id and the role body, writing the error response on failure
+func bindUserRole(c *gin.Context) (user.ID, UserRoleRequest, bool) {
+	userID, err := user.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return 0, UserRoleRequest{}, false
+	}
+
+	var req UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return 0, UserRoleRequest{}, false
+	}
+
+	return userID, req, true
+}
+
+// changeStatus binds the user id and the optional reason body, then runs the status change
+func (h *AdminHandler) changeStatus(
+	c *gin.Context,
+	change func(ctx context.Context, input shared.UseCaseInput[userApp.ChangeUserStatusInput]) error,
+) {
+	userID, err := user.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	var req ChangeStatusRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			HandleError(c, err)
+			return
+		}
+	}
+
+	data := userApp.ChangeUserStatusInput{
+		UserID: userID,
+		Reason: req.Reason,
+	}
+
+	if err := change(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -8,3 +8,111 @@ type AgentInfo struct {
 	Provider string `json:"provider"`
 	Status   string `json:"status"`
 }
+
+// UsageQuery is the date range of a usage report, formatted as 2006-01-02.
+type UsageQuery struct {
+	From string `form:"from"`
+	To   string `form:"to"`
+}
+
+// UsageItemResponse is the usage of one agent on one day.
+type UsageItemResponse struct {
+	Date             string `json:"date"`
+	AgentID          int64  `json:"agent_id"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	RequestCount     int64  `json:"request_count"`
+	AvgLatencyMs     int64  `json:"avg_latency_ms"`
+}
+
+// UsageSummaryResponse totals the usage of the report range.
+type UsageSummaryResponse struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	RequestCount     int64 `json:"request_count"`
+	AvgLatencyMs     int64 `json:"avg_latency_ms"`
+}
+
+// QuotaResponse is today's budget. Zero limits mean unlimited.
+type QuotaResponse struct {
+	DailyTokens   int64  `json:"daily_tokens"`
+	DailyRequests int64  `json:"daily_requests"`
+	UsedTokens    int64  `json:"used_tokens"`
+	UsedRequests  int64  `json:"used_requests"`
+	ResetAt       string `json:"reset_at"`
+}
+
+// UsageReportResponse is the response body of the usage endpoints.
+type UsageReportResponse struct {
+	UserID int64                `json:"user_id"`
+	From   string               `json:"from"`
+	To     string               `json:"to"`
+	Total  UsageSummaryResponse `json:"total"`
+	Daily  []UsageItemResponse  `json:"daily"`
+	Quota  QuotaResponse        `json:"quota"`
+}
+
+// SyncCatalogResponse summarizes a catalog sync. Failed maps a skipped provider to its error.
+type SyncCatalogResponse struct {
+	Synced   int               `json:"synced"`
+	Failed   map[string]string `json:"failed"`
+	SyncedAt string            `json:"synced_at"`
+}
+
+type CatalogModelResponse struct {
+	ID            int64  `json:"id"`
+	Provider      string `json:"provider"`
+	Name          string `json:"name"`
+	Family        string `json:"family"`
+	ParameterSize string `json:"parameter_size"`
+	Quantization  string `json:"quantization"`
+	SizeBytes     int64  `json:"size_bytes"`
+	IsAvailable   bool   `json:"is_available"`
+	SyncedAt      string `json:"synced_at"`
+}
+
+// ParametersRequest are the generation parameters of an agent. max_tokens 0 keeps the provider default.
+type ParametersRequest struct {
+	Temperature   float64  `json:"temperature"`
+	MaxTokens     int      `json:"max_tokens"`
+	StopSequences []string `json:"stop_sequences"`
+	SystemPrompt  string   `json:"system_prompt"`
+}
+
+// CreateAgentRequest creates an agent on a catalog model. An empty name uses the model name.
+type CreateAgentRequest struct {
+	Name       string            `json:"name"`
+	ModelID    int64             `json:"model_id" binding:"required"`
+	Parameters ParametersRequest `json:"parameters"`
+}
+
+// UpdateAgentConfigRequest stores a new config version. An empty model_id keeps the current model.
+type UpdateAgentConfigRequest struct {
+	ModelID    int64             `json:"model_id"`
+	Parameters ParametersRequest `json:"parameters"`
+	ChangeNote string            `json:"change_note"`
+}
+
+type ParametersResponse struct {
+	Temperature   float64  `json:"temperature"`
+	MaxTokens     int      `json:"max_tokens"`
+	StopSequences []string `json:"stop_sequences"`
+	SystemPrompt  string   `json:"system_prompt"`
+}
+
+type AgentConfigResponse struct {
+	AgentID    int64              `json:"agent_id"`
+	Version    int                `json:"version"`
+	ModelID    int64              `json:"model_id"`
+	Parameters ParametersResponse `json:"parameters"`
+	ChangeNote string             `json:"change_note"`
+	CreatedBy  int64              `json:"created_by"`
+	CreatedAt  string             `json:"created_at"`
+}
+
+type CreateAgentResponse struct {
+	ID     int64               `json:"id"`
+	Name   string              `json:"name"`
+	Status string              `json:"status"`
+	Config AgentConfigResponse `json:"config"`
+}
@@ -1,13 +1,91 @@
 package agent
 
 import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+	"github.com/HiroLiang/goat-server/internal/domain/agentusage"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/interface/http/response"
 	"github.com/HiroLiang/goat-server/internal/logger"
 	"github.com/gin-gonic/gin"
 )
 
 func HandleError(c *gin.Context, err error) bool {
 	logger.Log.Error(err.Error())
+
+	var quotaErr *agentusage.QuotaExceededError
 	switch {
+	case errors.As(err, &quotaErr):
+		c.JSON(http.StatusTooManyRequests, response.ErrorResponse{
+			Code:    "QUOTA_EXCEEDED",
+			Message: quotaErr.Error(),
+			Details: map[string]any{
+				"kind":     quotaErr.Kind,
+				"limit":    quotaErr.Limit,
+				"used":     quotaErr.Used,
+				"reset_at": quotaErr.ResetAt.Format(time.RFC3339),
+			},
+		})
+		return true
+
+	case errors.Is(err, agentusage.ErrInvalidRange):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("date range"))
+		return true
+
+	case errors.Is(err, agentusage.ErrInvalidUsage):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("usage"))
+		return true
+
+	case errors.Is(err, agent.ErrNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Code:    "AGENT_NOT_FOUND",
+			Message: "agent not found",
+		})
+		return true
+
+	case errors.Is(err, agent.ErrConfigNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Code:    "AGENT_CONFIG_NOT_FOUND",
+			Message: "agent has no config yet, a model_id is required",
+		})
+		return true
+
+	case errors.Is(err, agent.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("agent id"))
+		return true
+
+	case errors.Is(err, agent.ErrInvalidParameters):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("parameters"))
+		return true
+
+	case errors.Is(err, agentmodel.ErrNotFound):
+		c.JSON(http.StatusNotFound, response.ErrorResponse{
+			Code:    "MODEL_NOT_FOUND",
+			Message: "model not found in catalog",
+		})
+		return true
+
+	case errors.Is(err, agentmodel.ErrUnavailable):
+		c.JSON(http.StatusConflict, response.ErrorResponse{
+			Code:    "MODEL_UNAVAILABLE",
+			Message: "model is not available from its provider",
+		})
+		return true
+
+	case errors.Is(err, agentusage.ErrForbidden), errors.Is(err, agent.ErrForbidden):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "FORBIDDEN",
+			Message: "admin role required",
+		})
+		return true
+
+	case errors.Is(err, user.ErrInvalidUser), errors.Is(err, user.ErrInvalidID):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("user"))
+		return true
 
 	default:
 		_ = c.Error(err)
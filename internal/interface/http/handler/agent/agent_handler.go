@@ -2,9 +2,15 @@ package agent
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/HiroLiang/goat-server/internal/application/agent"
+	domainAgent "github.com/HiroLiang/goat-server/internal/domain/agent"
+	"github.com/HiroLiang/goat-server/internal/domain/agentmodel"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
 	"github.com/HiroLiang/goat-server/internal/interface/http/adapter"
+	"github.com/HiroLiang/goat-server/internal/interface/http/response"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
 	"github.com/gin-gonic/gin"
 )
 
@@ -19,6 +25,13 @@ func NewAgentHandler(agentUseCase *agent.UseCase) *AgentHandler {
 // RegisterAgentRoutes registers user-related API routes
 func (h *AgentHandler) RegisterAgentRoutes(r *gin.RouterGroup) {
 	r.GET("/available", h.getAvailableAgents)
+	r.GET("/usage", h.getMyUsage)
+	r.GET("/usage/users/:id", h.getUserUsage)
+	r.GET("/catalog", h.getCatalog)
+	r.POST("/catalog/sync", h.syncCatalog)
+	r.POST("", h.createAgent)
+	r.GET("/:id/configs", h.getConfigHistory)
+	r.PUT("/:id/config", h.updateConfig)
 }
 
 // @Summary Available agents info
@@ -53,3 +66,347 @@ func (h *AgentHandler) getAvailableAgents(c *gin.Context) {
 
 	c.JSON(http.StatusOK, agents)
 }
+
+// @Summary My agent usage
+// @Description Token, request and latency usage of the current user per agent and day, with today's quota.
+// @Tags Agent
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (2006-01-02), default 6 days before to"
+// @Param to   query string false "End date (2006-01-02), default today"
+// @Success 200 {object} UsageReportResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/agent/usage [get]
+func (h *AgentHandler) getMyUsage(c *gin.Context) {
+	from, to, ok := bindUsageRange(c)
+	if !ok {
+		return
+	}
+
+	output, err := h.agentUseCase.GetMyUsage(
+		c.Request.Context(),
+		adapter.BuildInput(c, agent.QueryUsageInput{From: from, To: to}),
+	)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toUsageReportResponse(output))
+}
+
+// @Summary Agent usage of a user
+// @Description Token, request and latency usage of any user per agent and day. Admin only.
+// @Tags Agent
+// @Produce json
+// @Security BearerAuth
+// @Param id   path  int    true  "User ID"
+// @Param from query string false "Start date (2006-01-02), default 6 days before to"
+// @Param to   query string false "End date (2006-01-02), default today"
+// @Success 200 {object} UsageReportResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/agent/usage/users/{id} [get]
+func (h *AgentHandler) getUserUsage(c *gin.Context) {
+	userID, err := user.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	from, to, ok := bindUsageRange(c)
+	if !ok {
+		return
+	}
+
+	output, err := h.agentUseCase.GetUserUsage(
+		c.Request.Context(),
+		adapter.BuildInput(c, agent.QueryUserUsageInput{UserID: userID, From: from, To: to}),
+	)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toUsageReportResponse(output))
+}
+
+// @Summary Model catalog
+// @Description All models synced from the configured providers, including ones no longer available. Admin only.
+// @Tags Agent
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} CatalogModelResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/agent/catalog [get]
+func (h *AgentHandler) getCatalog(c *gin.Context) {
+	outputs, err := h.agentUseCase.ListCatalog(
+		c.Request.Context(),
+		adapter.BuildInput(c, agent.QueryCatalogInput{}),
+	)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	models := make([]CatalogModelResponse, len(outputs))
+	for i, output := range outputs {
+		models[i] = CatalogModelResponse{
+			ID:            output.ID,
+			Provider:      output.Provider,
+			Name:          output.Name,
+			Family:        output.Family,
+			ParameterSize: output.ParameterSize,
+			Quantization:  output.Quantization,
+			SizeBytes:     output.SizeBytes,
+			IsAvailable:   output.IsAvailable,
+			SyncedAt:      output.SyncedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, models)
+}
+
+// @Summary Sync model catalog
+// @Description Query every configured provider for its models now and update the catalog. Admin only.
+// @Tags Agent
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SyncCatalogResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/agent/catalog/sync [post]
+func (h *AgentHandler) syncCatalog(c *gin.Context) {
+	output, err := h.agentUseCase.RequestCatalogSync(
+		c.Request.Context(),
+		adapter.BuildInput(c, agent.SyncCatalogInput{}),
+	)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SyncCatalogResponse{
+		Synced:   output.Synced,
+		Failed:   output.Failed,
+		SyncedAt: output.SyncedAt,
+	})
+}
+
+// @Summary Create agent
+// @Description Create an agent on a catalog model with its first config version. Admin only.
+// @Tags Agent
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAgentRequest true "Agent"
+// @Success 201 {object} CreateAgentResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Model Unavailable"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/agent [post]
+func (h *AgentHandler) createAgent(c *gin.Context) {
+	var req CreateAgentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("request"))
+		return
+	}
+
+	output, err := h.agentUseCase.CreateAgent(
+		c.Request.Context(),
+		adapter.BuildInput(c, agent.CreateAgentInput{
+			Name:       req.Name,
+			ModelID:    agentmodel.ID(req.ModelID),
+			Parameters: toParametersInput(req.Parameters),
+		}),
+	)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAgentResponse{
+		ID:     output.ID,
+		Name:   output.Name,
+		Status: output.Status.Desc(),
+		Config: toConfigResponse(output.Config),
+	})
+}
+
+// @Summary Agent config history
+// @Description Every config version of an agent, newest first. Admin only.
+// @Tags Agent
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Agent ID"
+// @Success 200 {array} AgentConfigResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/agent/{id}/configs [get]
+func (h *AgentHandler) getConfigHistory(c *gin.Context) {
+	agentID, err := domainAgent.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	outputs, err := h.agentUseCase.GetAgentConfigHistory(
+		c.Request.Context(),
+		adapter.BuildInput(c, agent.QueryConfigHistoryInput{AgentID: agentID}),
+	)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	configs := make([]AgentConfigResponse, len(outputs))
+	for i, output := range outputs {
+		configs[i] = toConfigResponse(output)
+	}
+
+	c.JSON(http.StatusOK, configs)
+}
+
+// @Summary Update agent config
+// @Description Store a new config version of an agent. Previous versions are kept. Admin only.
+// @Tags Agent
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id      path int                      true "Agent ID"
+// @Param request body UpdateAgentConfigRequest true "Config"
+// @Success 200 {object} AgentConfigResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Model Unavailable"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/agent/{id}/config [put]
+func (h *AgentHandler) updateConfig(c *gin.Context) {
+	agentID, err := domainAgent.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	var req UpdateAgentConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("request"))
+		return
+	}
+
+	output, err := h.agentUseCase.UpdateAgentConfig(
+		c.Request.Context(),
+		adapter.BuildInput(c, agent.UpdateAgentConfigInput{
+			AgentID:    agentID,
+			ModelID:    agentmodel.ID(req.ModelID),
+			Parameters: toParametersInput(req.Parameters),
+			ChangeNote: req.ChangeNote,
+		}),
+	)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toConfigResponse(output))
+}
+
+// bindUsageRange parses the optional from/to query, writing a 400 response on failure.
+func bindUsageRange(c *gin.Context) (from, to time.Time, ok bool) {
+	var query UsageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("query"))
+		return from, to, false
+	}
+
+	var err error
+	if query.From != "" {
+		if from, err = timeutil.Parse(timeutil.FormatDate, query.From); err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrInvalid("from"))
+			return from, to, false
+		}
+	}
+	if query.To != "" {
+		if to, err = timeutil.Parse(timeutil.FormatDate, query.To); err != nil {
+			c.JSON(http.StatusBadRequest, response.ErrInvalid("to"))
+			return from, to, false
+		}
+	}
+
+	return from, to, true
+}
+
+func toUsageReportResponse(output agent.UsageReportOutput) UsageReportResponse {
+	daily := make([]UsageItemResponse, len(output.Daily))
+	for i, item := range output.Daily {
+		daily[i] = UsageItemResponse{
+			Date:             item.Date,
+			AgentID:          item.AgentID,
+			PromptTokens:     item.PromptTokens,
+			CompletionTokens: item.CompletionTokens,
+			RequestCount:     item.RequestCount,
+			AvgLatencyMs:     item.AvgLatencyMs,
+		}
+	}
+
+	return UsageReportResponse{
+		UserID: output.UserID,
+		From:   output.From,
+		To:     output.To,
+		Total: UsageSummaryResponse{
+			PromptTokens:     output.Total.PromptTokens,
+			CompletionTokens: output.Total.CompletionTokens,
+			RequestCount:     output.Total.RequestCount,
+			AvgLatencyMs:     output.Total.AvgLatencyMs,
+		},
+		Daily: daily,
+		Quota: QuotaResponse{
+			DailyTokens:   output.Quota.DailyTokens,
+			DailyRequests: output.Quota.DailyRequests,
+			UsedTokens:    output.Quota.UsedTokens,
+			UsedRequests:  output.Quota.UsedRequests,
+			ResetAt:       output.Quota.ResetAt,
+		},
+	}
+}
+
+func toParametersInput(req ParametersRequest) agent.ParametersInput {
+	return agent.ParametersInput{
+		Temperature:   req.Temperature,
+		MaxTokens:     req.MaxTokens,
+		StopSequences: req.StopSequences,
+		SystemPrompt:  req.SystemPrompt,
+	}
+}
+
+func toConfigResponse(output agent.AgentConfigOutput) AgentConfigResponse {
+	return AgentConfigResponse{
+		AgentID: output.AgentID,
+		Version: output.Version,
+		ModelID: output.ModelID,
+		Parameters: ParametersResponse{
+			Temperature:   output.Parameters.Temperature,
+			MaxTokens:     output.Parameters.MaxTokens,
+			StopSequences: output.Parameters.StopSequences,
+			SystemPrompt:  output.Parameters.SystemPrompt,
+		},
+		ChangeNote: output.ChangeNote,
+		CreatedBy:  output.CreatedBy,
+		CreatedAt:  output.CreatedAt,
+	}
+}
@@ -0,0 +1,30 @@
+package apikey
+
+// CreateAPIKeyRequest a named key with scopes such as "chat:read",
+// expires_in_days 0 uses the default lifetime
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0"`
+}
+
+type APIKeyResponse struct {
+	ID         int64    `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expires_at"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	LastUsedIP string   `json:"last_used_ip,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// CreateAPIKeyResponse key is shown only once, send it as "Authorization: Bearer <key>"
+type CreateAPIKeyResponse struct {
+	Key    string         `json:"key"`
+	APIKey APIKeyResponse `json:"api_key"`
+}
+
+type ListAPIKeysResponse struct {
+	APIKeys []APIKeyResponse `json:"api_keys"`
+}
@@ -0,0 +1,58 @@
+package apikey
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/interface/http/response"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func HandleError(c *gin.Context, err error) {
+	logger.Log.Error(err.Error())
+	switch {
+	case errors.Is(err, apikey.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, response.ErrNotFound("api key"))
+		return
+
+	case errors.Is(err, apikey.ErrInvalidScope):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("scope"))
+		return
+
+	case errors.Is(err, apikey.ErrNameRequired):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("name"))
+		return
+
+	case errors.Is(err, apikey.ErrInvalidExpiry):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:    "INVALID_EXPIRY",
+			Message: "the expiry exceeds the maximum key lifetime",
+		})
+		return
+
+	case errors.Is(err, apikey.ErrTooManyKeys):
+		c.JSON(http.StatusConflict, response.ErrorResponse{
+			Code:    "TOO_MANY_API_KEYS",
+			Message: "revoke an api key before creating another",
+		})
+		return
+
+	case errors.Is(err, apikey.ErrKeyNotAllowed):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "API_KEY_NOT_ALLOWED",
+			Message: "log in with a session to create api keys",
+		})
+		return
+
+	case errors.Is(err, user.ErrInvalidUser):
+		c.JSON(http.StatusForbidden, response.ErrInvalid("user"))
+		return
+
+	default:
+		_ = c.Error(err)
+		return
+	}
+}
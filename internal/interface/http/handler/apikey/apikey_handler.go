@@ -0,0 +1,136 @@
+package apikey
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	apikeyApp "github.com/HiroLiang/goat-server/internal/application/apikey"
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+	"github.com/HiroLiang/goat-server/internal/interface/http/adapter"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler Rest api for the API keys of the current user
+type APIKeyHandler struct {
+	apiKeyUseCase *apikeyApp.UseCase
+}
+
+// NewAPIKeyHandler Create a new APIKeyHandler instance with dependencies
+func NewAPIKeyHandler(apiKeyUseCase *apikeyApp.UseCase) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUseCase: apiKeyUseCase,
+	}
+}
+
+// RegisterAPIKeyRoutes registers API key routes, the group must require auth
+func (h *APIKeyHandler) RegisterAPIKeyRoutes(r *gin.RouterGroup) {
+	r.GET("", h.listAPIKeys)
+	r.POST("", h.createAPIKey)
+	r.DELETE("/:id", h.revokeAPIKey)
+}
+
+// @Summary Create an API key
+// @Description
+// Mint a named, scoped and expiring key for scripts and bots. The key is returned only once.
+// Scopes are "<group>:read" or "<group>:write" for the groups user, admin, agent, chat and device.
+// Requests made with an API key cannot create keys.
+// @Tags APIKey
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body CreateAPIKeyRequest true "Key"
+// @Success 201 {object} CreateAPIKeyResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 409 {object} response.ErrorResponse "Too many keys"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/api-keys [post]
+func (h *APIKeyHandler) createAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := apikeyApp.CreateInput{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresIn: time.Duration(req.ExpiresInDays) * 24 * time.Hour,
+	}
+
+	output, err := h.apiKeyUseCase.Create(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{
+		Key:    output.Key,
+		APIKey: toAPIKeyResponse(output.APIKey),
+	})
+}
+
+// @Summary List API keys
+// @Description List the active keys of the current user with their last use
+// @Tags APIKey
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ListAPIKeysResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/api-keys [get]
+func (h *APIKeyHandler) listAPIKeys(c *gin.Context) {
+	output, err := h.apiKeyUseCase.List(c.Request.Context(), adapter.BuildEmptyInput(c))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	keys := make([]APIKeyResponse, 0, len(output.APIKeys))
+	for _, k := range output.APIKeys {
+		keys = append(keys, toAPIKeyResponse(k))
+	}
+
+	c.JSON(http.StatusOK, ListAPIKeysResponse{APIKeys: keys})
+}
+
+// @Summary Revoke an API key
+// @Description Revoke a key of the current user, it stops working with the next request
+// @Tags APIKey
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/api-keys/{id} [delete]
+func (h *APIKeyHandler) revokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		HandleError(c, apikey.ErrAPIKeyNotFound)
+		return
+	}
+
+	data := apikeyApp.RevokeInput{ID: id}
+	if err := h.apiKeyUseCase.Revoke(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func toAPIKeyResponse(k apikeyApp.APIKeyItem) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		LastUsedIP: k.LastUsedIP,
+		CreatedAt:  k.CreatedAt,
+	}
+}
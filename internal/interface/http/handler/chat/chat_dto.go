@@ -45,3 +45,51 @@ type GetGroupMessagesResponse struct {
 	NextCursor *int64                `json:"nextCursor,omitempty"`
 	HasMore    bool                  `json:"hasMore"`
 }
+
+// CreateGroupRequest is the request body for POST /api/chat/groups.
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Type        string `json:"type" binding:"required,oneof=GROUP CHANNEL"`
+	MaxMembers  int    `json:"maxMembers" binding:"min=0"`
+}
+
+// SendMessageRequest is the request body for POST /api/chat/groups/:id/messages.
+type SendMessageRequest struct {
+	Content   string `json:"content" binding:"required"`
+	ReplyToID *int64 `json:"replyToId,omitempty"`
+}
+
+// SendMessageResponse is the stored message with the mentioned participants and the agents asked to reply.
+type SendMessageResponse struct {
+	Message         ChatMessageResponse `json:"message"`
+	Mentions        []int64             `json:"mentions"`
+	TriggeredAgents []int64             `json:"triggeredAgents"`
+	FailedAgents    []int64             `json:"failedAgents"`
+	RefusedAgents   []int64             `json:"refusedAgents"`
+}
+
+// AgentReplyRequest is the request body for POST /api/chat/agent-replies, sent by the agent runtime.
+type AgentReplyRequest struct {
+	TriggerID        int64  `json:"triggerId" binding:"required"`
+	AgentID          int64  `json:"agentId" binding:"required"`
+	Content          string `json:"content" binding:"required"`
+	PromptTokens     int64  `json:"promptTokens" binding:"min=0"`
+	CompletionTokens int64  `json:"completionTokens" binding:"min=0"`
+	LatencyMs        int64  `json:"latencyMs" binding:"min=0"`
+}
+
+// ResponsePolicyRequest is the request body for PUT /api/chat/groups/:id/members/:participantId/policy.
+// Policy is one of ALWAYS, MENTION or KEYWORD; KEYWORD requires at least one keyword.
+type ResponsePolicyRequest struct {
+	Policy   string   `json:"policy" binding:"required"`
+	Keywords []string `json:"keywords"`
+}
+
+// ResponsePolicyResponse is the response policy of an agent member.
+type ResponsePolicyResponse struct {
+	GroupID       int64    `json:"groupId"`
+	ParticipantID int64    `json:"participantId"`
+	Policy        string   `json:"policy"`
+	Keywords      []string `json:"keywords"`
+}
@@ -5,6 +5,9 @@ import (
 	"net/http"
 
 	"github.com/HiroLiang/goat-server/internal/domain/chatgroup"
+	"github.com/HiroLiang/goat-server/internal/domain/chatmember"
+	"github.com/HiroLiang/goat-server/internal/domain/chatmessage"
+	"github.com/HiroLiang/goat-server/internal/domain/participant"
 	"github.com/HiroLiang/goat-server/internal/domain/user"
 	"github.com/HiroLiang/goat-server/internal/interface/http/response"
 	"github.com/HiroLiang/goat-server/internal/logger"
@@ -25,10 +28,41 @@ func HandleError(c *gin.Context, err error) {
 		})
 		return
 
+	case errors.Is(err, chatgroup.ErrInvalid):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("chat group"))
+		return
+
 	case errors.Is(err, chatgroup.ErrForbidden):
 		c.JSON(http.StatusForbidden, response.ErrInvalid("chat group access"))
 		return
 
+	case errors.Is(err, chatmember.ErrForbidden):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "CHAT_MEMBER_FORBIDDEN",
+			Message: "only group owners and admins can do this",
+		})
+		return
+
+	case errors.Is(err, chatmember.ErrNotFound), errors.Is(err, participant.ErrNotFound):
+		c.JSON(http.StatusNotFound, response.ErrNotFound("chat member"))
+		return
+
+	case errors.Is(err, chatmember.ErrInvalidPolicy):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("response policy"))
+		return
+
+	case errors.Is(err, chatmessage.ErrNotFound):
+		c.JSON(http.StatusNotFound, response.ErrNotFound("chat message"))
+		return
+
+	case errors.Is(err, chatmessage.ErrNoRequester):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("trigger message"))
+		return
+
+	case errors.Is(err, chatmessage.ErrEmpty), errors.Is(err, chatmessage.ErrTooLong):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("message content"))
+		return
+
 	case errors.Is(err, user.ErrInvalidUser):
 		c.JSON(http.StatusUnauthorized, response.ErrorResponse{
 			Code:    "INVALID_USER",
@@ -3,26 +3,36 @@ package chat
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	appchat "github.com/HiroLiang/goat-server/internal/application/chat"
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
 	"github.com/HiroLiang/goat-server/internal/interface/http/adapter"
+	"github.com/HiroLiang/goat-server/internal/interface/http/middleware"
 	"github.com/gin-gonic/gin"
 )
 
 // ChatHandler handles REST endpoints for chat groups and messages.
 type ChatHandler struct {
 	chatUseCase *appchat.UseCase
+	policy      auth.PolicyChecker
 }
 
 // NewChatHandler creates a new ChatHandler.
-func NewChatHandler(chatUseCase *appchat.UseCase) *ChatHandler {
-	return &ChatHandler{chatUseCase: chatUseCase}
+func NewChatHandler(chatUseCase *appchat.UseCase, policy auth.PolicyChecker) *ChatHandler {
+	return &ChatHandler{chatUseCase: chatUseCase, policy: policy}
 }
 
-// RegisterChatRoutes registers chat-related API routes.
+// RegisterChatRoutes registers chat-related API routes. Agent replies come from the
+// agent runtime, which authenticates as an account holding agent:manage.
 func (h *ChatHandler) RegisterChatRoutes(r *gin.RouterGroup) {
 	r.GET("/groups", h.getMyGroups)
+	r.POST("/groups", h.createGroup)
 	r.GET("/groups/:id/messages", h.getGroupMessages)
+	r.POST("/groups/:id/messages", h.sendMessage)
+	r.PUT("/groups/:id/members/:participantId/policy", h.updateResponsePolicy)
+	r.POST("/agent-replies", middleware.RequirePermission(h.policy, permission.AgentManage), h.postAgentReply)
 }
 
 // @Summary List my chat groups
@@ -65,6 +75,46 @@ func (h *ChatHandler) getMyGroups(c *gin.Context) {
 	c.JSON(http.StatusOK, GetMyGroupsResponse{Groups: groups})
 }
 
+// @Summary Create a chat group
+// @Description Creates a GROUP or CHANNEL chat with the current user as its owner. Creating a CHANNEL requires the chat:create_channel permission.
+// @Tags Chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateGroupRequest true "Chat group"
+// @Success 201 {object} ChatGroupResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/chat/groups [post]
+func (h *ChatHandler) createGroup(c *gin.Context) {
+	var req CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": "INVALID_PARAM", "message": "invalid chat group"})
+		return
+	}
+
+	output, err := h.chatUseCase.CreateGroup(c.Request.Context(), adapter.BuildInput(c, appchat.CreateGroupInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        req.Type,
+		MaxMembers:  req.MaxMembers,
+	}))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ChatGroupResponse{
+		ID:          output.ID,
+		Type:        output.Type,
+		Name:        output.Name,
+		Description: output.Description,
+		AvatarURL:   output.AvatarURL,
+		MemberCount: output.MemberCount,
+	})
+}
+
 // @Summary Get messages in a chat group
 // @Description Returns a page of messages for the given group. Use the nextCursor value as the `before` query param to load older messages.
 // @Tags Chat
@@ -135,3 +185,170 @@ func (h *ChatHandler) getGroupMessages(c *gin.Context) {
 		HasMore:    output.HasMore,
 	})
 }
+
+// @Summary Send a message to a chat group
+// @Description Stores a text message. Agent members reply according to their response policy; @mentions always trigger the mentioned agent.
+// @Tags Chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id      path int                true "Chat group ID"
+// @Param request body SendMessageRequest true "Message"
+// @Success 201 {object} SendMessageResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/chat/groups/{id}/messages [post]
+func (h *ChatHandler) sendMessage(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": "INVALID_PARAM", "message": "invalid group id"})
+		return
+	}
+
+	var req SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": "INVALID_PARAM", "message": "invalid message"})
+		return
+	}
+
+	output, err := h.chatUseCase.SendMessage(c.Request.Context(), adapter.BuildInput(c, appchat.SendMessageInput{
+		GroupID:   groupID,
+		Content:   req.Content,
+		ReplyToID: req.ReplyToID,
+	}))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	m := output.Message
+	c.JSON(http.StatusCreated, SendMessageResponse{
+		Message: ChatMessageResponse{
+			ID:           m.ID,
+			ChatID:       m.ChatID,
+			SenderID:     m.SenderID,
+			SenderName:   m.SenderName,
+			SenderAvatar: m.SenderAvatar,
+			Content:      m.Content,
+			Type:         string(m.Type),
+			ReplyToID:    m.ReplyToID,
+			IsEdited:     m.IsEdited,
+			IsMe:         m.IsMe,
+			Timestamp:    m.Timestamp,
+		},
+		Mentions:        output.Mentions,
+		TriggeredAgents: output.TriggeredAgents,
+		FailedAgents:    output.FailedAgents,
+		RefusedAgents:   output.RefusedAgents,
+	})
+}
+
+// @Summary Post the reply of an agent
+// @Description Stores the answer of an agent to a dispatched message and meters its usage against the user who started the chain. Called by the agent runtime.
+// @Tags Chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AgentReplyRequest true "Agent reply"
+// @Success 201 {object} SendMessageResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/chat/agent-replies [post]
+func (h *ChatHandler) postAgentReply(c *gin.Context) {
+	var req AgentReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": "INVALID_PARAM", "message": "invalid agent reply"})
+		return
+	}
+
+	output, err := h.chatUseCase.PostAgentReply(c.Request.Context(), adapter.BuildInput(c, appchat.PostAgentReplyInput{
+		TriggerID:        req.TriggerID,
+		AgentID:          req.AgentID,
+		Content:          req.Content,
+		PromptTokens:     req.PromptTokens,
+		CompletionTokens: req.CompletionTokens,
+		Latency:          time.Duration(req.LatencyMs) * time.Millisecond,
+	}))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	m := output.Message
+	c.JSON(http.StatusCreated, SendMessageResponse{
+		Message: ChatMessageResponse{
+			ID:           m.ID,
+			ChatID:       m.ChatID,
+			SenderID:     m.SenderID,
+			SenderName:   m.SenderName,
+			SenderAvatar: m.SenderAvatar,
+			Content:      m.Content,
+			Type:         string(m.Type),
+			ReplyToID:    m.ReplyToID,
+			IsEdited:     m.IsEdited,
+			IsMe:         m.IsMe,
+			Timestamp:    m.Timestamp,
+		},
+		Mentions:        output.Mentions,
+		TriggeredAgents: output.TriggeredAgents,
+		FailedAgents:    output.FailedAgents,
+		RefusedAgents:   output.RefusedAgents,
+	})
+}
+
+// @Summary Update the response policy of an agent member
+// @Description Sets when an agent in the group replies: ALWAYS, MENTION or KEYWORD. Group owners and admins only.
+// @Tags Chat
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id            path int                   true "Chat group ID"
+// @Param participantId path int                   true "Participant ID of the agent"
+// @Param request       body ResponsePolicyRequest true "Policy"
+// @Success 200 {object} ResponsePolicyResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/chat/groups/{id}/members/{participantId}/policy [put]
+func (h *ChatHandler) updateResponsePolicy(c *gin.Context) {
+	groupID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": "INVALID_PARAM", "message": "invalid group id"})
+		return
+	}
+
+	participantID, err := strconv.ParseInt(c.Param("participantId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": "INVALID_PARAM", "message": "invalid participant id"})
+		return
+	}
+
+	var req ResponsePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": "INVALID_PARAM", "message": "invalid policy"})
+		return
+	}
+
+	output, err := h.chatUseCase.UpdateResponsePolicy(c.Request.Context(), adapter.BuildInput(c, appchat.UpdateResponsePolicyInput{
+		GroupID:       groupID,
+		ParticipantID: participantID,
+		Policy:        req.Policy,
+		Keywords:      req.Keywords,
+	}))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ResponsePolicyResponse{
+		GroupID:       output.GroupID,
+		ParticipantID: output.ParticipantID,
+		Policy:        output.Policy,
+		Keywords:      output.Keywords,
+	})
+}
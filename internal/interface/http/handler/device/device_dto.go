@@ -1,13 +1,109 @@
 package device
 
+import (
+	"encoding/json"
+	"time"
+)
+
+// RegisterDeviceIdRequest device_id is chosen by the client and stays the same across logins,
+// push_token is the FCM or APNs registration, omit it to turn push notifications off
 type RegisterDeviceIdRequest struct {
-	DeviceID   string `json:"device_id" binding:"required"`
-	DeviceName string `json:"device_name" binding:"required"`
+	DeviceID   string `json:"device_id" binding:"required,max=128"`
+	DeviceName string `json:"device_name" binding:"required,max=64"`
 	Platform   string `json:"platform" binding:"required"`
+	PushToken  string `json:"push_token" binding:"max=4096"`
 }
 
+// RegisterDeviceIdResponse secret is only set for embedded devices, it cannot be shown again
 type RegisterDeviceIdResponse struct {
-	Success  bool   `json:"success"`
-	DeviceID string `json:"device_id"`
-	Message  string `json:"message"`
+	Success  bool           `json:"success"`
+	DeviceID string         `json:"device_id"`
+	Message  string         `json:"message"`
+	Secret   string         `json:"secret,omitempty"`
+	Device   DeviceResponse `json:"device"`
+}
+
+// DeviceResponse current is set for the device of the requesting session,
+// headless for devices that connect with a secret and take commands
+type DeviceResponse struct {
+	ID        int64  `json:"id"`
+	DeviceID  string `json:"device_id"`
+	Name      string `json:"name"`
+	Platform  string `json:"platform"`
+	Push      bool   `json:"push"`
+	Headless  bool   `json:"headless"`
+	Current   bool   `json:"current"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type ListDevicesResponse struct {
+	Devices []DeviceResponse `json:"devices"`
+}
+
+type RenameDeviceRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+// SendCommandRequest type names what the device should do, such as "gpio.write",
+// payload is a JSON object, expires_in_seconds 0 uses the default lifetime
+type SendCommandRequest struct {
+	Type             string          `json:"type" binding:"required,max=64"`
+	Payload          json.RawMessage `json:"payload" swaggertype:"object"`
+	ExpiresInSeconds int64           `json:"expires_in_seconds" binding:"min=0"`
+}
+
+// CommandResponse status is one of pending, delivered, succeeded, failed and expired
+type CommandResponse struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+	Status      string          `json:"status"`
+	Result      json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	Error       string          `json:"error,omitempty"`
+	ExpiresAt   string          `json:"expires_at"`
+	DeliveredAt string          `json:"delivered_at,omitempty"`
+	CompletedAt string          `json:"completed_at,omitempty"`
+	CreatedAt   string          `json:"created_at"`
+}
+
+type ListCommandsResponse struct {
+	Commands []CommandResponse `json:"commands"`
+}
+
+// SampleRequest at is an ISO time, the time the sample arrives when omitted
+type SampleRequest struct {
+	Metric string    `json:"metric" binding:"required,max=64"`
+	Value  float64   `json:"value"`
+	At     time.Time `json:"at"`
+}
+
+type IngestTelemetryRequest struct {
+	Samples []SampleRequest `json:"samples" binding:"required,dive"`
+}
+
+type IngestTelemetryResponse struct {
+	Accepted int `json:"accepted"`
+}
+
+// PointResponse aggregates the samples in the bucket starting at at
+type PointResponse struct {
+	At    string  `json:"at"`
+	Count int64   `json:"count"`
+	Avg   float64 `json:"avg"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+}
+
+type SeriesResponse struct {
+	Metric string          `json:"metric"`
+	Points []PointResponse `json:"points"`
+}
+
+// TelemetryResponse step is the bucket size in seconds
+type TelemetryResponse struct {
+	From   string           `json:"from"`
+	To     string           `json:"to"`
+	Step   int64            `json:"step"`
+	Series []SeriesResponse `json:"series"`
 }
@@ -0,0 +1,122 @@
+package device
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/interface/http/response"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func HandleError(c *gin.Context, err error) {
+	logger.Log.Error(err.Error())
+	switch {
+	case errors.Is(err, device.ErrDeviceNotFound), errors.Is(err, device.ErrInvalidID):
+		c.JSON(http.StatusNotFound, response.ErrNotFound("device"))
+		return
+
+	case errors.Is(err, device.ErrInvalidDeviceID):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("device_id"))
+		return
+
+	case errors.Is(err, device.ErrInvalidName):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("device_name"))
+		return
+
+	case errors.Is(err, device.ErrInvalidPlatform):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("platform"))
+		return
+
+	case errors.Is(err, device.ErrInvalidPushToken):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("push_token"))
+		return
+
+	case errors.Is(err, devicecommand.ErrCommandNotFound), errors.Is(err, devicecommand.ErrInvalidID):
+		c.JSON(http.StatusNotFound, response.ErrNotFound("command"))
+		return
+
+	case errors.Is(err, devicecommand.ErrInvalidType):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("type"))
+		return
+
+	case errors.Is(err, devicecommand.ErrInvalidPayload):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("payload"))
+		return
+
+	case errors.Is(err, devicecommand.ErrInvalidExpiry):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("expires_in_seconds"))
+		return
+
+	case errors.Is(err, device.ErrNotHeadless):
+		c.JSON(http.StatusConflict, response.ErrorResponse{
+			Code:    "NOT_HEADLESS",
+			Message: "only embedded devices take commands",
+		})
+		return
+
+	case errors.Is(err, devicecommand.ErrTooManyPending):
+		c.JSON(http.StatusTooManyRequests, response.ErrorResponse{
+			Code:    "TOO_MANY_COMMANDS",
+			Message: "the device has too many open commands",
+		})
+		return
+
+	case errors.Is(err, telemetry.ErrInvalidMetric), errors.Is(err, telemetry.ErrUnknownMetric):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("metric"))
+		return
+
+	case errors.Is(err, telemetry.ErrValueOutOfRange):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("value"))
+		return
+
+	case errors.Is(err, telemetry.ErrInvalidTime):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("at"))
+		return
+
+	case errors.Is(err, telemetry.ErrEmptyBatch), errors.Is(err, telemetry.ErrBatchTooLarge):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("samples"))
+		return
+
+	case errors.Is(err, telemetry.ErrInvalidRange):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("from"))
+		return
+
+	case errors.Is(err, telemetry.ErrInvalidStep):
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("step"))
+		return
+
+	case errors.Is(err, telemetry.ErrTooManyPoints):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:    "TOO_MANY_POINTS",
+			Message: "use a larger step or a shorter range",
+		})
+		return
+
+	case errors.Is(err, telemetry.ErrNoSchema):
+		c.JSON(http.StatusConflict, response.ErrorResponse{
+			Code:    "NO_TELEMETRY",
+			Message: "the device platform takes no telemetry",
+		})
+		return
+
+	case errors.Is(err, device.ErrSessionRequired):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "SESSION_REQUIRED",
+			Message: "log in with a session to register a device",
+		})
+		return
+
+	case errors.Is(err, user.ErrInvalidUser):
+		c.JSON(http.StatusForbidden, response.ErrInvalid("user"))
+		return
+
+	default:
+		_ = c.Error(err)
+		return
+	}
+}
@@ -2,55 +2,478 @@ package device
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
+	deviceApp "github.com/HiroLiang/goat-server/internal/application/device"
+	commandApp "github.com/HiroLiang/goat-server/internal/application/devicecommand"
+	telemetryApp "github.com/HiroLiang/goat-server/internal/application/telemetry"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
 	"github.com/HiroLiang/goat-server/internal/interface/http/adapter"
-	"github.com/HiroLiang/goat-server/internal/logger"
+	"github.com/HiroLiang/goat-server/internal/interface/http/response"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
 	"github.com/gin-gonic/gin"
 )
 
+// DeviceHandler Rest api for the registered devices of the current user, the
+// commands sent to them and the telemetry they report
 type DeviceHandler struct {
+	deviceUseCase    *deviceApp.UseCase
+	commandUseCase   *commandApp.UseCase
+	telemetryUseCase *telemetryApp.UseCase
 }
 
-func NewDeviceHandler() *DeviceHandler {
-	return &DeviceHandler{}
+// NewDeviceHandler Create a new DeviceHandler instance with dependencies
+func NewDeviceHandler(
+	deviceUseCase *deviceApp.UseCase,
+	commandUseCase *commandApp.UseCase,
+	telemetryUseCase *telemetryApp.UseCase) *DeviceHandler {
+	return &DeviceHandler{
+		deviceUseCase:    deviceUseCase,
+		commandUseCase:   commandUseCase,
+		telemetryUseCase: telemetryUseCase,
+	}
 }
 
+// RegisterDeviceRoutes registers device routes, the group must require auth
 func (h *DeviceHandler) RegisterDeviceRoutes(r *gin.RouterGroup) {
 	r.POST("/register", h.registerDeviceId)
+	r.GET("", h.listDevices)
+	r.PATCH("/:id", h.renameDevice)
+	r.DELETE("/:id", h.removeDevice)
+	r.POST("/:id/commands", h.sendCommand)
+	r.GET("/:id/commands", h.listCommands)
+	r.GET("/:id/commands/:commandId", h.getCommand)
+	r.GET("/:id/telemetry", h.queryTelemetry)
+}
+
+// RegisterHeadlessRoutes registers the routes headless devices call themselves,
+// the group must require a device
+func (h *DeviceHandler) RegisterHeadlessRoutes(r *gin.RouterGroup) {
+	r.POST("/telemetry", h.ingestTelemetry)
 }
 
 // @Summary registerDeviceId
-// @Description try to register device id checking is id already registered
+// @Description
+// Register the device of the current session. Registering the same device_id again
+// updates its name and platform and binds it to the current session, so clients may
+// register on every start. Send the device_id as "X-Device-ID" when logging in to
+// bind the new session as well. Platform is one of ios, android, web, desktop and embedded.
+// Chat messages are pushed to the push_token while the user has no live WebSocket.
+// Embedded devices get a secret to connect to /ws with as "Authorization: Bearer <secret>";
+// every registration issues a new one.
 // @Tags Device
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param payload body RegisterDeviceIdRequest true "Register device"
 // @Success 200 {object} RegisterDeviceIdResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Session required"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
 // @Router /api/device/register [post]
 func (h *DeviceHandler) registerDeviceId(c *gin.Context) {
 	var req RegisterDeviceIdRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid request: " + err.Error(),
-		})
+		HandleError(c, err)
 		return
 	}
 
-	input := adapter.BuildInput(c, req)
+	data := deviceApp.RegisterInput{
+		DeviceID:  req.DeviceID,
+		Name:      req.DeviceName,
+		Platform:  req.Platform,
+		PushToken: req.PushToken,
+	}
 
-	logger.Log.Info("Register device id: " + req.DeviceID)
+	output, err := h.deviceUseCase.Register(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
 
-	if input.Data.DeviceID != "" {
-		c.JSON(http.StatusOK, RegisterDeviceIdResponse{
-			Success:  true,
-			DeviceID: input.Data.DeviceID,
-			Message:  "Device registered successfully",
-		})
-	} else {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid request",
+	c.JSON(http.StatusOK, RegisterDeviceIdResponse{
+		Success:  true,
+		DeviceID: output.Device.DeviceID,
+		Message:  "Device registered successfully",
+		Secret:   output.Secret,
+		Device:   toDeviceResponse(output.Device),
+	})
+}
+
+// @Summary List devices
+// @Description List the registered devices of the current user, most recently registered first
+// @Tags Device
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ListDevicesResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/device [get]
+func (h *DeviceHandler) listDevices(c *gin.Context) {
+	output, err := h.deviceUseCase.List(c.Request.Context(), adapter.BuildEmptyInput(c))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	devices := make([]DeviceResponse, 0, len(output.Devices))
+	for _, d := range output.Devices {
+		devices = append(devices, toDeviceResponse(d))
+	}
+
+	c.JSON(http.StatusOK, ListDevicesResponse{Devices: devices})
+}
+
+// @Summary Rename a device
+// @Description Change the display name of a device of the current user
+// @Tags Device
+// @Accept json
+// @Security BearerAuth
+// @Param id path int true "Device ID"
+// @Param payload body RenameDeviceRequest true "Name"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/device/{id} [patch]
+func (h *DeviceHandler) renameDevice(c *gin.Context) {
+	id, err := device.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	var req RenameDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := deviceApp.RenameInput{ID: id, Name: req.Name}
+	if err := h.deviceUseCase.Rename(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Remove a device
+// @Description Forget a device of the current user and log out the sessions bound to it
+// @Tags Device
+// @Security BearerAuth
+// @Param id path int true "Device ID"
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/device/{id} [delete]
+func (h *DeviceHandler) removeDevice(c *gin.Context) {
+	id, err := device.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := deviceApp.RemoveInput{ID: id}
+	if err := h.deviceUseCase.Remove(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Send a command
+// @Description
+// Send a command to an embedded device of the current user. The device gets it as a
+// "device.command" message on /ws right away when connected, or as soon as it connects,
+// and answers with a "device.ack" message of the same id and status succeeded or failed.
+// Commands nobody acknowledged by expires_at expire.
+// @Tags Device
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Device ID"
+// @Param payload body SendCommandRequest true "Command"
+// @Success 202 {object} CommandResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Not a headless device"
+// @Failure 429 {object} response.ErrorResponse "Too many open commands"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/device/{id}/commands [post]
+func (h *DeviceHandler) sendCommand(c *gin.Context) {
+	id, err := device.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	var req SendCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := commandApp.SendInput{
+		DeviceID:  id,
+		Type:      req.Type,
+		Payload:   req.Payload,
+		ExpiresIn: time.Duration(req.ExpiresInSeconds) * time.Second,
+	}
+
+	output, err := h.commandUseCase.Send(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, toCommandResponse(output))
+}
+
+// @Summary List commands
+// @Description List the latest commands sent to a device of the current user, newest first
+// @Tags Device
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Device ID"
+// @Param limit query int false "Number of commands to return (default 20, max 100)"
+// @Success 200 {object} ListCommandsResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/device/{id}/commands [get]
+func (h *DeviceHandler) listCommands(c *gin.Context) {
+	id, err := device.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := commandApp.ListInput{DeviceID: id}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		v, err := strconv.ParseUint(limitStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": "INVALID_PARAM", "message": "invalid limit"})
+			return
+		}
+		data.Limit = v
+	}
+
+	output, err := h.commandUseCase.List(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	commands := make([]CommandResponse, 0, len(output.Commands))
+	for _, cmd := range output.Commands {
+		commands = append(commands, toCommandResponse(cmd))
+	}
+
+	c.JSON(http.StatusOK, ListCommandsResponse{Commands: commands})
+}
+
+// @Summary Get a command
+// @Description Get a command sent to a device of the current user, with the result the device acknowledged it with
+// @Tags Device
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Device ID"
+// @Param commandId path int true "Command ID"
+// @Success 200 {object} CommandResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/device/{id}/commands/{commandId} [get]
+func (h *DeviceHandler) getCommand(c *gin.Context) {
+	id, err := device.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	commandID, err := devicecommand.ToID(c.Param("commandId"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := commandApp.GetInput{DeviceID: id, ID: commandID}
+	output, err := h.commandUseCase.Get(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toCommandResponse(output))
+}
+
+// @Summary Query telemetry
+// @Description
+// Aggregate the telemetry of a device of the current user into buckets of step seconds,
+// one series per metric. Defaults to the last 24 hours in buckets of the rollup step.
+// Telemetry older than the raw retention only exists at the rollup step, so finer steps
+// over old ranges answer with the rolled-up buckets.
+// @Tags Device
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Device ID"
+// @Param from query string false "Start time (ISO 8601)"
+// @Param to query string false "End time (ISO 8601)"
+// @Param step query int false "Bucket size in seconds"
+// @Param metric query []string false "Metrics to return, all when omitted" collectionFormat(multi)
+// @Success 200 {object} TelemetryResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/device/{id}/telemetry [get]
+func (h *DeviceHandler) queryTelemetry(c *gin.Context) {
+	id, err := device.ToID(c.Param("id"))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := telemetryApp.QueryInput{DeviceID: id, Metrics: c.QueryArray("metric")}
+	if data.From, err = queryTime(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("from"))
+		return
+	}
+	if data.To, err = queryTime(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, response.ErrInvalid("to"))
+		return
+	}
+	if v := c.Query("step"); v != "" {
+		seconds, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || seconds == 0 {
+			c.JSON(http.StatusBadRequest, response.ErrInvalid("step"))
+			return
+		}
+		data.Step = time.Duration(seconds) * time.Second
+	}
+
+	output, err := h.telemetryUseCase.Query(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toTelemetryResponse(output))
+}
+
+// @Summary Report telemetry
+// @Description
+// Report a batch of telemetry as an embedded device, authenticated with the device secret
+// as "Authorization: Bearer <secret>". Connected devices may send the same payload as a
+// "device.telemetry" message on /ws instead. Samples are checked against the schema of
+// the platform and a batch with an invalid sample is rejected as a whole. Samples without
+// "at" are recorded at the time they arrive. Owners watching the device on /ws get
+// them as "device.telemetry" messages.
+// @Tags Device
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param payload body IngestTelemetryRequest true "Samples"
+// @Success 202 {object} IngestTelemetryResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 409 {object} response.ErrorResponse "Platform takes no telemetry"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/device/telemetry [post]
+func (h *DeviceHandler) ingestTelemetry(c *gin.Context) {
+	d := c.MustGet("deviceContext").(*device.Device)
+
+	var req IngestTelemetryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := telemetryApp.IngestInput{Samples: make([]telemetryApp.SampleInput, 0, len(req.Samples))}
+	for _, s := range req.Samples {
+		data.Samples = append(data.Samples, telemetryApp.SampleInput{
+			Metric: s.Metric,
+			Value:  s.Value,
+			At:     s.At,
 		})
 	}
+
+	output, err := h.telemetryUseCase.Ingest(c.Request.Context(), d.ID, data)
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, IngestTelemetryResponse{Accepted: output.Accepted})
+}
+
+// queryTime parses an ISO time query param, zero when omitted
+func queryTime(c *gin.Context, param string) (time.Time, error) {
+	v := c.Query(param)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return timeutil.Parse(timeutil.FormatISO, v)
+}
+
+func toTelemetryResponse(output telemetryApp.QueryOutput) TelemetryResponse {
+	series := make([]SeriesResponse, 0, len(output.Series))
+	for _, s := range output.Series {
+		points := make([]PointResponse, 0, len(s.Points))
+		for _, p := range s.Points {
+			points = append(points, PointResponse{
+				At:    p.At,
+				Count: p.Count,
+				Avg:   p.Avg,
+				Min:   p.Min,
+				Max:   p.Max,
+			})
+		}
+		series = append(series, SeriesResponse{Metric: s.Metric, Points: points})
+	}
+
+	return TelemetryResponse{
+		From:   output.From,
+		To:     output.To,
+		Step:   output.Step,
+		Series: series,
+	}
+}
+
+func toCommandResponse(cmd commandApp.CommandItem) CommandResponse {
+	return CommandResponse{
+		ID:          int64(cmd.ID),
+		Type:        cmd.Type,
+		Payload:     cmd.Payload,
+		Status:      cmd.Status,
+		Result:      cmd.Result,
+		Error:       cmd.Error,
+		ExpiresAt:   cmd.ExpiresAt,
+		DeliveredAt: cmd.DeliveredAt,
+		CompletedAt: cmd.CompletedAt,
+		CreatedAt:   cmd.CreatedAt,
+	}
+}
+
+func toDeviceResponse(d deviceApp.DeviceItem) DeviceResponse {
+	return DeviceResponse{
+		ID:        int64(d.ID),
+		DeviceID:  d.DeviceID,
+		Name:      d.Name,
+		Platform:  d.Platform,
+		Push:      d.Push,
+		Headless:  d.Headless,
+		Current:   d.Current,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
 }
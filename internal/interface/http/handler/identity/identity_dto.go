@@ -0,0 +1,38 @@
+package identity
+
+// ProvidersResponse the names of the configured OIDC providers
+type ProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// AuthorizeResponse the provider URL to send the browser to
+type AuthorizeResponse struct {
+	URL string `json:"url"`
+}
+
+// CallbackRequest the code and state the provider redirected back with
+type CallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// CallbackResponse mirrors the login response. The access token is sent in the
+// Authorization header; linked is set instead when the callback finished a link.
+type CallbackResponse struct {
+	Message           string `json:"message"`
+	RefreshToken      string `json:"refresh_token,omitempty"`
+	ExpiresIn         int    `json:"expires_in,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+	Linked            bool   `json:"linked,omitempty"`
+}
+
+type IdentityResponse struct {
+	Provider string `json:"provider"`
+	Email    string `json:"email,omitempty"`
+	LinkedAt string `json:"linked_at"`
+}
+
+type ListIdentitiesResponse struct {
+	Identities []IdentityResponse `json:"identities"`
+}
@@ -0,0 +1,98 @@
+package identity
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HiroLiang/goat-server/internal/domain/identity"
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/interface/http/response"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func HandleError(c *gin.Context, err error) {
+	logger.Log.Error(err.Error())
+	switch {
+	case errors.Is(err, identity.ErrUnknownProvider):
+		c.JSON(http.StatusNotFound, response.ErrNotFound("provider"))
+		return
+
+	case errors.Is(err, security.ErrInvalidActionToken):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:    "INVALID_STATE",
+			Message: "the login expired or was already used, start again",
+		})
+		return
+
+	case errors.Is(err, identity.ErrInvalidIDToken):
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{
+			Code:    "INVALID_ID_TOKEN",
+			Message: "the provider login could not be verified",
+		})
+		return
+
+	case errors.Is(err, identity.ErrEmailNotVerified):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "IDENTITY_NOT_LINKED",
+			Message: "log in with your password and link this account first",
+		})
+		return
+
+	case errors.Is(err, identity.ErrLinkSession):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "LINK_SESSION_MISMATCH",
+			Message: "finish linking in the session that started it",
+		})
+		return
+
+	case errors.Is(err, identity.ErrIdentityNotFound):
+		c.JSON(http.StatusNotFound, response.ErrNotFound("identity"))
+		return
+
+	case errors.Is(err, identity.ErrAlreadyLinked):
+		c.JSON(http.StatusConflict, response.ErrorResponse{
+			Code:    "IDENTITY_ALREADY_LINKED",
+			Message: "this provider account or provider is already linked",
+		})
+		return
+
+	case errors.Is(err, user.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, response.ErrNotFound("user"))
+		return
+
+	case errors.Is(err, user.ErrInvalidUser):
+		c.JSON(http.StatusForbidden, response.ErrInvalid("user"))
+		return
+
+	case errors.Is(err, user.ErrUserApplying):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "USER_APPLYING",
+			Message: "your registration is pending approval",
+		})
+		return
+
+	case errors.Is(err, user.ErrUserBanned):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "USER_BANNED",
+			Message: "this account has been banned",
+		})
+		return
+
+	case errors.Is(err, user.ErrUserRejected):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "USER_REJECTED",
+			Message: "your registration was rejected",
+		})
+		return
+
+	case errors.Is(err, user.ErrGenerateToken):
+		c.JSON(http.StatusInternalServerError, response.ErrAuthFailed)
+		return
+
+	default:
+		_ = c.Error(err)
+		return
+	}
+}
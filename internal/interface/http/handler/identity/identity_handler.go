@@ -0,0 +1,236 @@
+package identity
+
+import (
+	"net/http"
+
+	"github.com/HiroLiang/goat-server/internal/application/identity"
+	"github.com/HiroLiang/goat-server/internal/interface/http/adapter"
+	"github.com/HiroLiang/goat-server/internal/interface/http/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// IdentityHandler Rest api for OpenID Connect login and linked identities
+type IdentityHandler struct {
+	identityUseCase *identity.UseCase
+}
+
+// NewIdentityHandler Create a new IdentityHandler instance with dependencies
+func NewIdentityHandler(identityUseCase *identity.UseCase) *IdentityHandler {
+	return &IdentityHandler{
+		identityUseCase: identityUseCase,
+	}
+}
+
+// RegisterIdentityRoutes registers OIDC API routes
+func (h *IdentityHandler) RegisterIdentityRoutes(r *gin.RouterGroup) {
+	r.GET("/providers", h.listProviders)
+	r.POST("/:provider/authorize", h.authorize)
+	r.POST("/:provider/callback", h.callback)
+	r.POST("/:provider/link", middleware.RequireAuthMiddleware(), h.link)
+	r.POST("/:provider/link/callback", middleware.RequireAuthMiddleware(), h.linkCallback)
+
+	r.GET("/identities", middleware.RequireAuthMiddleware(), h.listIdentities)
+	r.DELETE("/identities/:provider", middleware.RequireAuthMiddleware(), h.unlink)
+}
+
+// @Summary List OIDC providers
+// @Description List the providers users can sign in with
+// @Tags Identity
+// @Produce json
+// @Success 200 {object} ProvidersResponse
+// @Router /api/oidc/providers [get]
+func (h *IdentityHandler) listProviders(c *gin.Context) {
+	output := h.identityUseCase.Providers(c.Request.Context(), adapter.BuildEmptyInput(c))
+
+	c.JSON(http.StatusOK, ProvidersResponse{Providers: output.Providers})
+}
+
+// @Summary Start an OIDC login
+// @Description
+// Start the authorization code flow with PKCE. Send the browser to the returned url;
+// the provider redirects back to the frontend with code and state for the callback.
+// @Tags Identity
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} AuthorizeResponse
+// @Failure 404 {object} response.ErrorResponse "Unknown provider"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/oidc/{provider}/authorize [post]
+func (h *IdentityHandler) authorize(c *gin.Context) {
+	data := identity.ProviderInput{Provider: c.Param("provider")}
+
+	output, err := h.identityUseCase.Authorize(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthorizeResponse{URL: output.URL})
+}
+
+// @Summary Link an OIDC identity
+// @Description
+// Start the flow like authorize; the link callback links the provider account to the
+// current user. Only the session that started the link may finish it.
+// @Tags Identity
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider name"
+// @Success 200 {object} AuthorizeResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "No login session, API keys cannot link"
+// @Failure 404 {object} response.ErrorResponse "Unknown provider"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/oidc/{provider}/link [post]
+func (h *IdentityHandler) link(c *gin.Context) {
+	data := identity.ProviderInput{Provider: c.Param("provider")}
+
+	output, err := h.identityUseCase.Link(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthorizeResponse{URL: output.URL})
+}
+
+// @Summary Finish an OIDC login
+// @Description
+// Redeem the code the provider redirected back with. Logs in the user of the linked
+// identity, or the user with the same verified email, and returns a session like the
+// password login. With 2FA enabled a challenge_token for POST /api/user/login/2fa is
+// returned instead. A flow started by link finishes at the link callback.
+// @Tags Identity
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param payload body CallbackRequest true "Code and state"
+// @Success 200 {object} CallbackResponse
+// @Failure 400 {object} response.ErrorResponse "Invalid or expired state"
+// @Failure 401 {object} response.ErrorResponse "ID token rejected"
+// @Failure 403 {object} response.ErrorResponse "No linked account, link it first"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Already linked"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/oidc/{provider}/callback [post]
+func (h *IdentityHandler) callback(c *gin.Context) {
+	var req CallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := identity.CallbackInput{
+		Provider: c.Param("provider"),
+		Code:     req.Code,
+		State:    req.State,
+	}
+
+	output, err := h.identityUseCase.Callback(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	switch {
+	case output.TwoFactorRequired:
+		c.JSON(http.StatusOK, CallbackResponse{
+			Message:           "Two-factor code required",
+			TwoFactorRequired: true,
+			ChallengeToken:    output.ChallengeToken,
+		})
+	default:
+		c.Header("Authorization", "Bearer "+output.Token)
+		c.JSON(http.StatusOK, CallbackResponse{
+			Message:      "Login successful",
+			RefreshToken: output.RefreshToken,
+			ExpiresIn:    output.ExpiresIn,
+		})
+	}
+}
+
+// @Summary Finish an OIDC link
+// @Description Redeem the code of a flow started by link and link the provider account to the current user.
+// @Tags Identity
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider name"
+// @Param payload body CallbackRequest true "Code and state"
+// @Success 200 {object} CallbackResponse
+// @Failure 400 {object} response.ErrorResponse "Invalid or expired state"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized or ID token rejected"
+// @Failure 403 {object} response.ErrorResponse "Link started by another session"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 409 {object} response.ErrorResponse "Already linked"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/oidc/{provider}/link/callback [post]
+func (h *IdentityHandler) linkCallback(c *gin.Context) {
+	var req CallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := identity.CallbackInput{
+		Provider: c.Param("provider"),
+		Code:     req.Code,
+		State:    req.State,
+	}
+
+	if _, err := h.identityUseCase.LinkCallback(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CallbackResponse{Message: "Identity linked", Linked: true})
+}
+
+// @Summary List linked identities
+// @Description List the providers linked to the current user
+// @Tags Identity
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ListIdentitiesResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/oidc/identities [get]
+func (h *IdentityHandler) listIdentities(c *gin.Context) {
+	output, err := h.identityUseCase.ListIdentities(c.Request.Context(), adapter.BuildEmptyInput(c))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	identities := make([]IdentityResponse, 0, len(output.Identities))
+	for _, i := range output.Identities {
+		identities = append(identities, IdentityResponse{
+			Provider: i.Provider,
+			Email:    i.Email,
+			LinkedAt: i.LinkedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, ListIdentitiesResponse{Identities: identities})
+}
+
+// @Summary Unlink an identity
+// @Description Remove the provider from the current user. The password login keeps working.
+// @Tags Identity
+// @Security BearerAuth
+// @Param provider path string true "Provider name"
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not linked"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/oidc/identities/{provider} [delete]
+func (h *IdentityHandler) unlink(c *gin.Context) {
+	data := identity.ProviderInput{Provider: c.Param("provider")}
+
+	if err := h.identityUseCase.Unlink(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -9,7 +9,8 @@ type RegisterRequest struct {
 
 // RegisterResponse User register response
 type RegisterResponse struct {
-	Message string `json:"message"`
+	Message          string `json:"message"`
+	VerificationSent bool   `json:"verification_sent"`
 }
 
 // LoginRequest represents the required fields for a user login request.
@@ -19,16 +20,108 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 }
 
-// LoginResponse represents the server's response after a successful login.
-// It contains a token and user details.
+// LoginResponse represents the server's response after a successful login or token refresh.
+// The access token is sent in the Authorization header; refresh_token is omitted in opaque token mode.
+// With 2FA enabled the login only returns a challenge_token for POST /api/user/login/2fa.
 type LoginResponse struct {
-	Message string `json:"message"`
+	Message                string `json:"message"`
+	RefreshToken           string `json:"refresh_token,omitempty"`
+	ExpiresIn              int    `json:"expires_in"`
+	TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken         string `json:"challenge_token,omitempty"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"`
+}
+
+// LoginTwoFactorRequest the login challenge and a TOTP or recovery code
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// RefreshTokenRequest the refresh token to exchange for a new token pair
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 // CurrentUserResponse queried for user login request.
 type CurrentUserResponse struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	CreateAt string `json:"create_at"`
+	ID            int      `json:"id"`
+	Name          string   `json:"name"`
+	Email         string   `json:"email"`
+	CreateAt      string   `json:"create_at"`
+	EmailVerified bool     `json:"email_verified"`
+	Permissions   []string `json:"permissions"` // e.g. "agent:manage", lets clients hide actions
+}
+
+// SessionResponse is one login of the current user, identified by an opaque session ID.
+type SessionResponse struct {
+	ID         string `json:"id"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	Browser    string `json:"browser"`
+	OS         string `json:"os"`
+	Platform   string `json:"platform"`
+	CreatedAt  string `json:"created_at"`
+	LastSeenAt string `json:"last_seen_at"`
+	Current    bool   `json:"current"`
+
+	// Registered device of the session, omitted when there is none
+	DeviceID       int64  `json:"device_id,omitempty"`
+	DeviceName     string `json:"device_name,omitempty"`
+	DevicePlatform string `json:"device_platform,omitempty"`
+}
+
+// ListSessionsResponse active sessions of the current user, most recently used first.
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+// ReleaseLoginLockRequest the email whose login lockout is lifted
+type ReleaseLoginLockRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ApplicationStatusRequest the credentials of an applicant
+type ApplicationStatusRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ApplicationStatusResponse the registration status of an applicant
+type ApplicationStatusResponse struct {
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// TokenRequest a token received by mail
+type TokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// EmailRequest an email to mail a link to
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest the mailed reset token and the new password
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// TwoFactorCodeRequest a TOTP code, or a recovery code where accepted
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorEnrollmentResponse the TOTP secret to add to an authenticator app
+type TwoFactorEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse the one-time recovery codes, shown only once
+type RecoveryCodesResponse struct {
+	Codes []string `json:"codes"`
 }
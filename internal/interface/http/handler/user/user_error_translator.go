@@ -2,8 +2,13 @@ package user
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
+	"github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/security"
+	"github.com/HiroLiang/goat-server/internal/domain/twofactor"
 	"github.com/HiroLiang/goat-server/internal/domain/user"
 	"github.com/HiroLiang/goat-server/internal/interface/http/response"
 	"github.com/HiroLiang/goat-server/internal/logger"
@@ -12,7 +17,64 @@ import (
 
 func HandleError(c *gin.Context, err error) {
 	logger.Log.Error(err.Error())
+
+	var locked *security.AccountLockedError
 	switch {
+	case errors.As(err, &locked):
+		retryAfter := int(math.Ceil(locked.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.JSON(http.StatusTooManyRequests, response.ErrorResponse{
+			Code:    "ACCOUNT_LOCKED",
+			Message: "too many failed login attempts, try again later",
+			Details: map[string]any{
+				"retry_after": retryAfter,
+			},
+		})
+		return
+
+	case errors.Is(err, security.ErrRateLimitExceeded):
+		c.JSON(http.StatusTooManyRequests, response.ErrorResponse{
+			Code:    "RATE_LIMITED",
+			Message: "too many requests for this email, try again later",
+		})
+		return
+
+	case errors.Is(err, security.ErrInvalidActionToken):
+		c.JSON(http.StatusBadRequest, response.ErrorResponse{
+			Code:    "INVALID_TOKEN",
+			Message: "the link is invalid or has expired",
+		})
+		return
+
+	case errors.Is(err, twofactor.ErrInvalidCode):
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{
+			Code:    "INVALID_2FA_CODE",
+			Message: "the two-factor code is invalid",
+		})
+		return
+
+	case errors.Is(err, twofactor.ErrNotAllowed):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "2FA_NOT_ALLOWED",
+			Message: "two-factor authentication is only available to admin and vendor accounts",
+		})
+		return
+
+	case errors.Is(err, twofactor.ErrNotEnrolled),
+		errors.Is(err, twofactor.ErrNotEnabled):
+		c.JSON(http.StatusConflict, response.ErrorResponse{
+			Code:    "2FA_NOT_ENABLED",
+			Message: "two-factor authentication is not enabled",
+		})
+		return
+
+	case errors.Is(err, twofactor.ErrAlreadyEnabled):
+		c.JSON(http.StatusConflict, response.ErrorResponse{
+			Code:    "2FA_ALREADY_ENABLED",
+			Message: "two-factor authentication is already enabled",
+		})
+		return
+
 	case errors.Is(err, user.ErrUserNotFound):
 		c.JSON(http.StatusNotFound, response.ErrNotFound("user"))
 		return
@@ -42,6 +104,13 @@ func HandleError(c *gin.Context, err error) {
 		})
 		return
 
+	case errors.Is(err, user.ErrUserRejected):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "USER_REJECTED",
+			Message: "your registration was rejected",
+		})
+		return
+
 	case errors.Is(err, user.ErrInvalidPassword):
 		c.JSON(http.StatusUnauthorized, response.ErrInvalid("password"))
 		return
@@ -50,6 +119,28 @@ func HandleError(c *gin.Context, err error) {
 		c.JSON(http.StatusBadRequest, response.ErrInvalid("email"))
 		return
 
+	case errors.Is(err, user.ErrForbidden):
+		c.JSON(http.StatusForbidden, response.ErrorResponse{
+			Code:    "FORBIDDEN",
+			Message: "permission denied",
+		})
+		return
+
+	case errors.Is(err, auth.ErrTokenReused):
+		c.JSON(http.StatusUnauthorized, response.ErrorResponse{
+			Code:    "TOKEN_REUSED",
+			Message: "refresh token was already used, the session has been revoked",
+		})
+		return
+
+	case errors.Is(err, auth.ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, response.ErrInvalid("token"))
+		return
+
+	case errors.Is(err, auth.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, response.ErrNotFound("session"))
+		return
+
 	case errors.Is(err, user.ErrGenerateToken):
 		c.JSON(http.StatusInternalServerError, response.ErrAuthFailed)
 		return
@@ -25,9 +25,27 @@ func NewUserHandler(userUseCase *user.UseCase) *UserHandler {
 func (h *UserHandler) RegisterUserRoutes(r *gin.RouterGroup) {
 	r.POST("/register", h.register)
 	r.POST("/login", h.login)
+	r.POST("/login/2fa", h.loginTwoFactor)
 
+	r.POST("/token/refresh", h.refreshToken)
+	r.POST("/application/status", h.applicationStatus)
+
+	r.POST("/email/verify", h.verifyEmail)
+	r.POST("/email/verify/resend", h.resendVerification)
+	r.POST("/password/forgot", h.forgotPassword)
+	r.POST("/password/reset", h.resetPassword)
 	r.POST("/logout", middleware.RequireAuthMiddleware(), h.logout)
 	r.GET("/me", middleware.RequireAuthMiddleware(), h.getCurrentUser)
+
+	r.GET("/sessions", middleware.RequireAuthMiddleware(), h.listSessions)
+	r.DELETE("/sessions/others", middleware.RequireAuthMiddleware(), h.revokeOtherSessions)
+	r.DELETE("/sessions/:id", middleware.RequireAuthMiddleware(), h.revokeSession)
+
+	r.POST("/unlock", middleware.RequireAuthMiddleware(), h.releaseLoginLock)
+
+	r.POST("/2fa/enroll", middleware.RequireAuthMiddleware(), h.enrollTwoFactor)
+	r.POST("/2fa/confirm", middleware.RequireAuthMiddleware(), h.confirmTwoFactor)
+	r.POST("/2fa/disable", middleware.RequireAuthMiddleware(), h.disableTwoFactor)
 }
 
 // @Summary User register
@@ -55,12 +73,16 @@ func (h *UserHandler) register(c *gin.Context) {
 		Password: req.Password,
 	}
 
-	if err := h.userUseCase.Register(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+	output, err := h.userUseCase.Register(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
 		HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, RegisterResponse{Message: "Register successful"})
+	c.JSON(http.StatusCreated, RegisterResponse{
+		Message:          "Register successful",
+		VerificationSent: output.VerificationSent,
+	})
 }
 
 // @Summary User Login
@@ -68,6 +90,7 @@ func (h *UserHandler) register(c *gin.Context) {
 // Authenticate a user using an email and password.
 // If the request already contains an Authorization Bearer token,
 // it will be forwarded to the authentication service for session continuity.
+// With 2FA enabled no session is issued, the response carries a challenge_token instead.
 // @Tags User
 // @Accept json
 // @Produce json
@@ -76,6 +99,7 @@ func (h *UserHandler) register(c *gin.Context) {
 // @Failure 400 {object} response.ErrorResponse "Bad Request"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized"
 // @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 429 {object} response.ErrorResponse "Account locked, see Retry-After"
 // @Failure 500 {object} response.ErrorResponse "Internal Server Error"
 // @Router /api/user/login [post]
 func (h *UserHandler) login(c *gin.Context) {
@@ -96,9 +120,101 @@ func (h *UserHandler) login(c *gin.Context) {
 		return
 	}
 
+	if output.TwoFactorRequired {
+		c.JSON(http.StatusOK, LoginResponse{
+			Message:           "Two-factor code required",
+			TwoFactorRequired: true,
+			ChallengeToken:    output.ChallengeToken,
+		})
+		return
+	}
+
+	c.Header("Authorization", "Bearer "+output.Token)
+
+	c.JSON(http.StatusOK, LoginResponse{
+		Message:                "Login successful",
+		RefreshToken:           output.RefreshToken,
+		ExpiresIn:              output.ExpiresIn,
+		TwoFactorSetupRequired: output.TwoFactorSetupRequired,
+	})
+}
+
+// @Summary Login second factor
+// @Description
+// Finish a login that returned a challenge_token with a TOTP code or a recovery code.
+// The challenge is single use; after a wrong code, log in with the password again.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param payload body LoginTwoFactorRequest true "Challenge and code"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} response.ErrorResponse "Invalid or expired challenge"
+// @Failure 401 {object} response.ErrorResponse "Invalid code"
+// @Failure 429 {object} response.ErrorResponse "Account locked, see Retry-After"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/login/2fa [post]
+func (h *UserHandler) loginTwoFactor(c *gin.Context) {
+	var req LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := user.LoginTwoFactorInput{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+	}
+
+	output, err := h.userUseCase.LoginTwoFactor(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
 	c.Header("Authorization", "Bearer "+output.Token)
 
-	c.JSON(http.StatusOK, LoginResponse{Message: "Login successful"})
+	c.JSON(http.StatusOK, LoginResponse{
+		Message:      "Login successful",
+		RefreshToken: output.RefreshToken,
+		ExpiresIn:    output.ExpiresIn,
+	})
+}
+
+// @Summary Refresh tokens
+// @Description
+// Exchange a refresh token for a new access token and a new refresh token.
+// Every refresh token is single use; presenting a used one revokes its session.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param payload body RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/token/refresh [post]
+func (h *UserHandler) refreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := user.RefreshTokenInput{RefreshToken: req.RefreshToken}
+
+	output, err := h.userUseCase.RefreshToken(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Header("Authorization", "Bearer "+output.Token)
+
+	c.JSON(http.StatusOK, LoginResponse{
+		Message:      "Token refreshed",
+		RefreshToken: output.RefreshToken,
+		ExpiresIn:    output.ExpiresIn,
+	})
 }
 
 // @Summary User Logout
@@ -124,7 +240,7 @@ func (h *UserHandler) logout(c *gin.Context) {
 }
 
 // @Summary Current User info
-// @Description query the current user info
+// @Description query the current user info with the permissions the user holds
 // @Tags User
 // @Accept json
 // @Produce json
@@ -142,10 +258,348 @@ func (h *UserHandler) getCurrentUser(c *gin.Context) {
 		return
 	}
 
+	permissions := make([]string, 0, len(output.Permissions))
+	for _, p := range output.Permissions {
+		permissions = append(permissions, string(p))
+	}
+
 	c.JSON(http.StatusOK, CurrentUserResponse{
-		ID:       output.ID,
-		Name:     output.Name,
-		Email:    output.Email,
-		CreateAt: output.CreateAt,
+		ID:            output.ID,
+		Name:          output.Name,
+		Email:         output.Email,
+		CreateAt:      output.CreateAt,
+		EmailVerified: output.EmailVerified,
+		Permissions:   permissions,
 	})
 }
+
+// @Summary List my sessions
+// @Description List the active sessions (devices) of the current user with IP, client and last activity.
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ListSessionsResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/sessions [get]
+func (h *UserHandler) listSessions(c *gin.Context) {
+	output, err := h.userUseCase.ListSessions(c.Request.Context(), adapter.BuildEmptyInput(c))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	sessions := make([]SessionResponse, len(output.Sessions))
+	for i, s := range output.Sessions {
+		sessions[i] = SessionResponse{
+			ID:             s.ID,
+			IP:             s.IP,
+			UserAgent:      s.UserAgent,
+			Browser:        s.Browser,
+			OS:             s.OS,
+			Platform:       s.Platform,
+			CreatedAt:      s.CreatedAt,
+			LastSeenAt:     s.LastSeenAt,
+			Current:        s.Current,
+			DeviceID:       int64(s.DeviceID),
+			DeviceName:     s.DeviceName,
+			DevicePlatform: s.DevicePlatform,
+		}
+	}
+
+	c.JSON(http.StatusOK, ListSessionsResponse{Sessions: sessions})
+}
+
+// @Summary Revoke a session
+// @Description Log out one session of the current user. Revoking the current session logs the caller out.
+// @Tags User
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 404 {object} response.ErrorResponse "Not Found"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/sessions/{id} [delete]
+func (h *UserHandler) revokeSession(c *gin.Context) {
+	data := user.RevokeSessionInput{SessionID: c.Param("id")}
+
+	if err := h.userUseCase.RevokeSession(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Log out everywhere else
+// @Description Revoke every session of the current user except the one making this request.
+// @Tags User
+// @Security BearerAuth
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/sessions/others [delete]
+func (h *UserHandler) revokeOtherSessions(c *gin.Context) {
+	if err := h.userUseCase.RevokeOtherSessions(c.Request.Context(), adapter.BuildEmptyInput(c)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Release a login lockout
+// @Description Admin only. Lift the lockout of an email locked after too many failed logins.
+// @Tags User
+// @Accept json
+// @Security BearerAuth
+// @Param payload body ReleaseLoginLockRequest true "Email to unlock"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Forbidden"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/unlock [post]
+func (h *UserHandler) releaseLoginLock(c *gin.Context) {
+	var req ReleaseLoginLockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := user.ReleaseLoginLockInput{Email: req.Email}
+
+	if err := h.userUseCase.ReleaseLoginLock(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Registration status
+// @Description
+// Applicants cannot log in until approved, so they check their registration
+// with their credentials. The reason is shown once rejected or banned.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param payload body ApplicationStatusRequest true "Applicant credentials"
+// @Success 200 {object} ApplicationStatusResponse
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 429 {object} response.ErrorResponse "Account locked, see Retry-After"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/application/status [post]
+func (h *UserHandler) applicationStatus(c *gin.Context) {
+	var req ApplicationStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := user.ApplicationStatusInput{
+		Email:    req.Email,
+		Password: req.Password,
+	}
+
+	output, err := h.userUseCase.ApplicationStatus(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ApplicationStatusResponse{
+		Status:    string(output.Status),
+		Reason:    output.Reason,
+		UpdatedAt: output.UpdatedAt,
+	})
+}
+
+// @Summary Verify email
+// @Description Verify the email of an account with the token from the verification mail.
+// @Tags User
+// @Accept json
+// @Param payload body TokenRequest true "Mailed token"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse "Invalid or expired token"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/email/verify [post]
+func (h *UserHandler) verifyEmail(c *gin.Context) {
+	var req TokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := user.VerifyEmailInput{Token: req.Token}
+
+	if err := h.userUseCase.VerifyEmail(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Resend verification mail
+// @Description Mail a new verification link. Always accepted for unknown emails, limited per email.
+// @Tags User
+// @Accept json
+// @Param payload body EmailRequest true "Email"
+// @Success 202
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 429 {object} response.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/email/verify/resend [post]
+func (h *UserHandler) resendVerification(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := user.EmailInput{Email: req.Email}
+
+	if err := h.userUseCase.ResendVerification(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// @Summary Forgot password
+// @Description Mail a password reset link. Always accepted for unknown emails, limited per email.
+// @Tags User
+// @Accept json
+// @Param payload body EmailRequest true "Email"
+// @Success 202
+// @Failure 400 {object} response.ErrorResponse "Bad Request"
+// @Failure 429 {object} response.ErrorResponse "Too Many Requests"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/password/forgot [post]
+func (h *UserHandler) forgotPassword(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := user.EmailInput{Email: req.Email}
+
+	if err := h.userUseCase.ForgotPassword(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// @Summary Reset password
+// @Description Set a new password with the token from the reset mail. Every session of the account is logged out.
+// @Tags User
+// @Accept json
+// @Param payload body ResetPasswordRequest true "Mailed token and new password"
+// @Success 204
+// @Failure 400 {object} response.ErrorResponse "Invalid or expired token"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/password/reset [post]
+func (h *UserHandler) resetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := user.ResetPasswordInput{
+		Token:    req.Token,
+		Password: req.Password,
+	}
+
+	if err := h.userUseCase.ResetPassword(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Enroll two-factor authentication
+// @Description Start a TOTP enrollment for admin and vendor accounts. 2FA is enabled once confirmed with a code.
+// @Tags User
+// @Produce json
+// @Success 200 {object} TwoFactorEnrollmentResponse
+// @Failure 401 {object} response.ErrorResponse "Unauthorized"
+// @Failure 403 {object} response.ErrorResponse "Not allowed for this account"
+// @Failure 409 {object} response.ErrorResponse "Already enabled"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/2fa/enroll [post]
+func (h *UserHandler) enrollTwoFactor(c *gin.Context) {
+	output, err := h.userUseCase.EnrollTwoFactor(c.Request.Context(), adapter.BuildInput(c, struct{}{}))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TwoFactorEnrollmentResponse{
+		Secret:          output.Secret,
+		ProvisioningURI: output.ProvisioningURI,
+	})
+}
+
+// @Summary Confirm two-factor authentication
+// @Description Enable 2FA with a first TOTP code. The recovery codes are returned only once.
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param payload body TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 401 {object} response.ErrorResponse "Invalid code"
+// @Failure 409 {object} response.ErrorResponse "Not enrolled or already enabled"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/2fa/confirm [post]
+func (h *UserHandler) confirmTwoFactor(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := user.TwoFactorCodeInput{Code: req.Code}
+
+	output, err := h.userUseCase.ConfirmTwoFactor(c.Request.Context(), adapter.BuildInput(c, data))
+	if err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{Codes: output.Codes})
+}
+
+// @Summary Disable two-factor authentication
+// @Description Turn off 2FA with a TOTP code or a recovery code
+// @Tags User
+// @Accept json
+// @Param payload body TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 204
+// @Failure 401 {object} response.ErrorResponse "Invalid code"
+// @Failure 409 {object} response.ErrorResponse "Not enabled"
+// @Failure 500 {object} response.ErrorResponse "Internal Server Error"
+// @Router /api/user/2fa/disable [post]
+func (h *UserHandler) disableTwoFactor(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	data := user.TwoFactorCodeInput{Code: req.Code}
+
+	if err := h.userUseCase.DisableTwoFactor(c.Request.Context(), adapter.BuildInput(c, data)); err != nil {
+		HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,42 +1,123 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/HiroLiang/goat-server/internal/application/shared"
 	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/domain/userrole"
 	"github.com/HiroLiang/goat-server/internal/interface/http/response"
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware try to validate auth token from the header
-func AuthMiddleware(tokenService auth.TokenService) gin.HandlerFunc {
+// AuthMiddleware try to validate auth token from the header. Tokens with the API key
+// prefix are checked by apiKeys instead, a nil apiKeys accepts session tokens only.
+// Device secrets are left to DeviceAuthMiddleware. The highest role of the user is
+// loaded through roles and recorded as the RoleID of the auth context.
+func AuthMiddleware(tokenService auth.TokenService, apiKeys auth.APIKeyAuthenticator, roles userrole.Repository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		// Get auth token from the header
 		authHeader := c.GetHeader("Authorization")
+		token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+		isAPIKey := strings.HasPrefix(token, apikey.TokenPrefix)
+		isDevice := strings.HasPrefix(token, device.SecretPrefix)
 
 		// Validate token if exists
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
-			if session, err := tokenService.Validate(c.Request.Context(), token); err == nil {
+		switch {
+		case !strings.HasPrefix(authHeader, "Bearer "), isDevice:
+			break
+
+		case isAPIKey:
+			if apiKeys == nil {
+				break
+			}
+			if key, err := apiKeys.Authenticate(c.Request.Context(), token, c.ClientIP()); err == nil {
 				c.Set("authContext", &shared.AuthContext{
-					UserID: session.UserID,
-					Token:  token,
+					UserID:   strconv.FormatInt(int64(key.UserID), 10),
+					RoleID:   primaryRoleID(c.Request.Context(), roles, key.UserID),
+					Token:    token,
+					APIKeyID: strconv.FormatInt(key.ID, 10),
+					Scopes:   key.Scopes,
 				})
 			}
+
+		default:
+			if session, err := tokenService.Validate(c.Request.Context(), token); err == nil {
+				authCtx := &shared.AuthContext{
+					UserID:    session.UserID,
+					Token:     token,
+					SessionID: session.ID,
+				}
+				if userID, err := user.ToID(session.UserID); err == nil {
+					authCtx.RoleID = primaryRoleID(c.Request.Context(), roles, userID)
+				}
+				c.Set("authContext", authCtx)
+			}
 		}
 
 		c.Next()
 
-		if strings.HasPrefix(authHeader, "Bearer ") {
+		// API keys and device secrets are never echoed back
+		if strings.HasPrefix(authHeader, "Bearer ") && !isAPIKey && !isDevice {
 			c.Header("Authorization", authHeader)
 		}
 	}
 }
 
+// rolePriority ranks roles from the most to the least privileged
+var rolePriority = []role.Type{role.Admin, role.Vendor, role.User, role.Guest}
+
+// primaryRoleID returns the ID of the highest role the user holds, empty if none
+// or the roles can't be loaded. RequireRole narrows it to the role a route accepts.
+func primaryRoleID(ctx context.Context, roles userrole.Repository, userID user.ID) string {
+	if roles == nil {
+		return ""
+	}
+
+	held, err := roles.FindRolesByUser(ctx, userID)
+	if err != nil {
+		return ""
+	}
+
+	for _, t := range rolePriority {
+		for _, r := range held {
+			if r.Type == t {
+				return strconv.FormatInt(int64(r.ID), 10)
+			}
+		}
+	}
+	return ""
+}
+
+// DeviceAuthMiddleware try to validate a device secret from the header, and sets the
+// device context for headless devices. Other tokens pass untouched.
+func DeviceAuthMiddleware(devices auth.DeviceAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		// Get device secret from the header
+		secret := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if !strings.HasPrefix(secret, device.SecretPrefix) {
+			c.Next()
+			return
+		}
+
+		if d, err := devices.AuthenticateDevice(c.Request.Context(), secret); err == nil {
+			c.Set("deviceContext", d)
+		}
+
+		c.Next()
+	}
+}
+
 // RequireAuthMiddleware require auth context to be set or abort with 401
 func RequireAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -52,3 +133,16 @@ func RequireAuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireDeviceMiddleware require device context to be set or abort with 401
+func RequireDeviceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("deviceContext"); !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": response.ErrAuthFailed,
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	session "github.com/HiroLiang/goat-server/internal/domain/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/infrastructure/auth/mock"
+	"github.com/gin-gonic/gin"
+)
+
+// stubTokenService accepts every token as a session of user 1
+type stubTokenService struct {
+	auth.TokenService
+}
+
+func (stubTokenService) Validate(_ context.Context, _ string) (*session.Session, error) {
+	return &session.Session{ID: "s1", UserID: "1"}, nil
+}
+
+func TestAuthMiddleware_SetsHighestRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	roles := mock.MockUserRoleRepo()
+	_ = roles.Assign(context.Background(), 1, role.User)
+	_ = roles.Assign(context.Background(), 1, role.Admin)
+
+	var got *shared.AuthContext
+	r := gin.New()
+	r.GET("/", AuthMiddleware(stubTokenService{}, nil, roles), func(c *gin.Context) {
+		if v, ok := c.Get("authContext"); ok {
+			got = v.(*shared.AuthContext)
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got == nil {
+		t.Fatal("auth context not set")
+	}
+	if got.RoleID != "1" {
+		t.Errorf("RoleID = %q, want %q", got.RoleID, "1")
+	}
+}
@@ -18,8 +18,10 @@ func ContextMiddleware() gin.HandlerFunc {
 		// Set context
 		c.Set("context", &shared.BaseInput{
 			Request: shared.RequestContext{
-				IP:      c.ClientIP(),
-				TraceID: c.GetHeader("traceparent"),
+				IP:        c.ClientIP(),
+				TraceID:   c.GetHeader("traceparent"),
+				UserAgent: c.Request.UserAgent(),
+				DeviceID:  c.GetHeader("X-Device-ID"),
 			},
 			Auth: authCtx,
 		})
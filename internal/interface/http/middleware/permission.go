@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/permission"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/interface/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission require the authenticated user to hold perm or abort with 403.
+// It must run after RequireAuthMiddleware.
+func RequirePermission(checker auth.PolicyChecker, perm permission.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		// Get auth context
+		v, ok := c.Get("authContext")
+		if !ok {
+			_ = c.Error(response.ErrAuthFailed)
+			c.Abort()
+			return
+		}
+
+		userID, err := user.ToID(v.(*shared.AuthContext).UserID)
+		if err != nil {
+			_ = c.Error(response.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		// Check permissions of the user
+		err = checker.Authorize(c.Request.Context(), userID, perm)
+		if errors.Is(err, permission.ErrDenied) {
+			_ = c.Error(response.ErrForbidden)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
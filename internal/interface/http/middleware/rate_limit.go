@@ -1,19 +1,27 @@
 package middleware
 
 import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
 	"github.com/HiroLiang/goat-server/internal/application/shared/security"
+	domainSecurity "github.com/HiroLiang/goat-server/internal/domain/security"
 	"github.com/HiroLiang/goat-server/internal/interface/http/response"
 	"github.com/gin-gonic/gin"
 )
 
+// rateLimitKey holds the quota the RateLimit headers of the response report
+const rateLimitKey = "rateLimit"
+
+// GlobalRateLimitMiddleware limits the requests of the whole server. Its quota is not
+// the client's, so only Retry-After reports it once used up.
 func GlobalRateLimitMiddleware(limiter security.RateLimiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if err := limiter.CheckGlobal(c.Request.Context()); err != nil {
-			_ = c.Error(response.ErrorResponse{
-				Code:    "RATE_LIMIT_EXCEEDED",
-				Message: "Server is busy, please try again later",
-			})
-			c.Abort()
+		result, err := limiter.CheckGlobal(c.Request.Context())
+		if err != nil {
+			rejectRateLimited(c, result, "Server is busy, please try again later")
 			return
 		}
 		c.Next()
@@ -25,15 +33,90 @@ func IPRateLimitMiddleware(limiter security.RateLimiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ip := c.ClientIP()
 
-		if err := limiter.CheckIP(c.Request.Context(), ip); err != nil {
-			_ = c.Error(response.ErrorResponse{
-				Code:    "RATE_LIMIT_EXCEEDED",
-				Message: "Too many requests from your IP, please try again later",
-			})
-			c.Abort()
+		result, err := limiter.CheckIP(c.Request.Context(), ip)
+		reportRateLimit(c, result)
+		if err != nil {
+			rejectRateLimited(c, result, "Too many requests from your IP, please try again later")
+			return
+		}
+		c.Next()
+
+	}
+}
+
+// UserRateLimitMiddleware limits the requests of the authenticated user across their
+// sessions and API keys. Must run after AuthMiddleware; anonymous requests pass.
+func UserRateLimitMiddleware(limiter security.RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v, ok := c.Get("authContext")
+		if !ok {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.CheckUser(c.Request.Context(), v.(*shared.AuthContext).UserID)
+		reportRateLimit(c, result)
+		if err != nil {
+			rejectRateLimited(c, result, "Too many requests, please try again later")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RouteRateLimitMiddleware applies the policies of single routes, counted per user
+// or per IP for anonymous requests. Must run after AuthMiddleware.
+func RouteRateLimitMiddleware(limiter security.RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		key := "ip:" + c.ClientIP()
+		if v, ok := c.Get("authContext"); ok {
+			key = "user:" + v.(*shared.AuthContext).UserID
+		}
+
+		result, err := limiter.CheckRoute(c.Request.Context(), c.Request.Method+" "+c.FullPath(), key)
+		reportRateLimit(c, result)
+		if err != nil {
+			rejectRateLimited(c, result, "Too many requests to this endpoint, please try again later")
 			return
 		}
 		c.Next()
+	}
+}
+
+// reportRateLimit sets the RateLimit headers to result, unless an earlier check left
+// the client with less
+func reportRateLimit(c *gin.Context, result domainSecurity.RateLimitResult) {
+	if result.Limit == 0 {
+		return
+	}
+	if v, ok := c.Get(rateLimitKey); ok && v.(domainSecurity.RateLimitResult).Remaining <= result.Remaining {
+		return
+	}
 
+	c.Set(rateLimitKey, result)
+	c.Header("RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+	c.Header("RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+	c.Header("RateLimit-Reset", seconds(result.Reset))
+}
+
+// rejectRateLimited aborts with 429, telling when to retry if the limiter knows
+func rejectRateLimited(c *gin.Context, result domainSecurity.RateLimitResult, message string) {
+	if result.RetryAfter > 0 {
+		c.Header("Retry-After", seconds(result.RetryAfter))
 	}
+	_ = c.Error(response.ErrorResponse{
+		Code:    "RATE_LIMIT_EXCEEDED",
+		Message: message,
+	})
+	c.Abort()
+}
+
+// seconds rounds d up to whole seconds, as the headers take
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
 }
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/HiroLiang/goat-server/internal/interface/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole require the authenticated user to hold one of roles or abort with 403.
+// The matched role is recorded on the auth context, so it must run after RequireAuthMiddleware.
+func RequireRole(checker auth.RoleChecker, roles ...role.Type) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		// Get auth context
+		v, ok := c.Get("authContext")
+		if !ok {
+			_ = c.Error(response.ErrAuthFailed)
+			c.Abort()
+			return
+		}
+		authCtx := v.(*shared.AuthContext)
+
+		// Check roles of the user
+		matched, err := checker.MatchRole(c.Request.Context(), authCtx.UserID, roles...)
+		if errors.Is(err, user.ErrForbidden) || errors.Is(err, user.ErrInvalidUser) {
+			_ = c.Error(response.ErrForbidden)
+			c.Abort()
+			return
+		}
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		authCtx.RoleID = strconv.FormatInt(int64(matched.ID), 10)
+		c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared"
+	"github.com/HiroLiang/goat-server/internal/domain/apikey"
+	"github.com/HiroLiang/goat-server/internal/interface/http/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope limits requests made with an API key to keys scoped to the route group.
+// GET and HEAD need a read scope, other methods a write scope. Session tokens pass.
+func RequireScope(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		// Get auth context
+		v, ok := c.Get("authContext")
+		if !ok {
+			c.Next()
+			return
+		}
+
+		authCtx := v.(*shared.AuthContext)
+		if authCtx.APIKeyID == "" {
+			c.Next()
+			return
+		}
+
+		write := c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead
+		if !apikey.Allows(authCtx.Scopes, group, write) {
+			_ = c.Error(response.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -7,6 +7,10 @@ var (
 		Code:    "AUTH_FAILED",
 		Message: "authentication failed",
 	}
+	ErrForbidden = ErrorResponse{
+		Code:    "FORBIDDEN",
+		Message: "permission denied",
+	}
 )
 
 type ErrorResponse struct {
@@ -0,0 +1,341 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	writeWait = 10 * time.Second
+
+	// protocolLevel is MQTT 3.1.1, the only version the broker speaks
+	protocolLevel = 4
+)
+
+var ErrBrokerClosed = errors.New("mqtt broker closed")
+
+// Config MaxPacketSize bounds the packets clients send, ConnectTimeout how long a new
+// connection may take to send CONNECT.
+type Config struct {
+	MaxPacketSize  int
+	ConnectTimeout time.Duration
+}
+
+// DefaultConfig the limits used for values left unset
+var DefaultConfig = Config{
+	MaxPacketSize:  64 * 1024,
+	ConnectTimeout: 10 * time.Second,
+}
+
+// Broker is a lightweight MQTT 3.1.1 listener. Clients authenticate on CONNECT and
+// may only use the topics the Authenticator allows them. Messages clients publish go
+// to the router instead of other clients; the server reaches subscribers through
+// Publish. Messages are received at QoS 0 or 1 and sent at QoS 0, nothing is retained
+// and sessions do not outlive their connection.
+type Broker struct {
+	conf Config
+
+	// auth and router are set once by Serve.
+	auth   Authenticator
+	router *MessageRouter
+
+	// clients maps identity and client ID → client; subscriptions maps filter → clients.
+	// Both protected by mu.
+	clients       map[clientKey]*Client
+	subscriptions map[string]map[*Client]bool
+	listener      net.Listener
+	closed        bool
+	mu            sync.RWMutex
+
+	// onSubscribe runs in its own goroutine for every subscription granted.
+	onSubscribe func(client *Client, filter string)
+}
+
+// NewBroker creates a new Broker. Publish reaches nobody until it serves.
+func NewBroker(conf Config) *Broker {
+	return &Broker{
+		conf:          conf,
+		clients:       make(map[clientKey]*Client),
+		subscriptions: make(map[string]map[*Client]bool),
+	}
+}
+
+// OnSubscribe sets fn to run once a subscription is granted, such as delivering
+// what was queued while the client was offline. Must be called before Serve.
+func (b *Broker) OnSubscribe(fn func(client *Client, filter string)) {
+	b.onSubscribe = fn
+}
+
+// ListenAndServe listens on the TCP address addr and serves until Close.
+func (b *Broker) ListenAndServe(addr string, auth Authenticator, router *MessageRouter) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return b.Serve(ln, auth, router)
+}
+
+// Serve accepts connections on ln until Close, and then returns ErrBrokerClosed.
+// Clients are checked by auth and what they publish goes to router. Must be called
+// once.
+func (b *Broker) Serve(ln net.Listener, auth Authenticator, router *MessageRouter) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		_ = ln.Close()
+		return ErrBrokerClosed
+	}
+	b.auth = auth
+	b.router = router
+	b.listener = ln
+	b.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			b.mu.RLock()
+			closed := b.closed
+			b.mu.RUnlock()
+			if closed {
+				return ErrBrokerClosed
+			}
+			return err
+		}
+
+		go b.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections and disconnects every client.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, c := range b.clients {
+		_ = c.conn.Close()
+	}
+
+	if b.listener != nil {
+		return b.listener.Close()
+	}
+	return nil
+}
+
+// Publish sends a message to every client subscribed to a filter matching topic,
+// and reports false when there are none. Safe to call from any goroutine.
+func (b *Broker) Publish(topic string, payload []byte) bool {
+	p := newPublishPacket(topic, payload)
+
+	// Holding the read lock keeps unregister from closing a send channel meanwhile
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	sent := make(map[*Client]bool)
+	for filter, clients := range b.subscriptions {
+		if !Match(filter, topic) {
+			continue
+		}
+		for c := range clients {
+			if !sent[c] {
+				sent[c] = true
+				c.enqueue(p)
+			}
+		}
+	}
+	return len(sent) > 0
+}
+
+// serveConn runs a connection from CONNECT to its end. Once registered, the
+// writePump of the client closes the connection after flushing what is queued.
+func (b *Broker) serveConn(conn net.Conn) {
+	if client, r := b.connect(conn); client != nil {
+		defer b.unregister(client)
+		go client.writePump()
+		client.enqueue(connackPacket(connAccepted))
+
+		b.readLoop(client, r, client.keepAlive)
+		return
+	}
+	_ = conn.Close()
+}
+
+// connect reads CONNECT and registers the client, or answers why it may not connect
+// and returns nil.
+func (b *Broker) connect(conn net.Conn) (*Client, *bufio.Reader) {
+	r := bufio.NewReader(conn)
+
+	_ = conn.SetReadDeadline(time.Now().Add(b.conf.ConnectTimeout))
+	first, err := readPacket(r, b.conf.MaxPacketSize)
+	if err != nil || first.typ != typeConnect {
+		return nil, nil
+	}
+
+	connect, err := parseConnect(first.body)
+	if err != nil {
+		return nil, nil
+	}
+	if connect.protocol != "MQTT" || connect.level != protocolLevel {
+		_, _ = conn.Write(connackPacket(connBadProtocol).encode())
+		return nil, nil
+	}
+
+	identity, err := b.auth.Authenticate(context.Background(), connect.username, connect.password)
+	if err != nil {
+		_, _ = conn.Write(connackPacket(connNotAuthorized).encode())
+		return nil, nil
+	}
+
+	clientID := connect.clientID
+	if clientID == "" {
+		clientID = conn.RemoteAddr().String()
+	}
+
+	client := NewClient(conn, clientID, identity)
+	client.keepAlive = time.Duration(connect.keepAlive) * time.Second
+	if !b.register(client) {
+		_, _ = conn.Write(connackPacket(connIdentifierRefused).encode())
+		return nil, nil
+	}
+	return client, r
+}
+
+// readLoop handles the packets of a connected client until it disconnects, breaks
+// the protocol or goes quiet for one and a half keep-alive periods.
+func (b *Broker) readLoop(client *Client, r *bufio.Reader, keepAlive time.Duration) {
+	for {
+		deadline := time.Time{}
+		if keepAlive > 0 {
+			deadline = time.Now().Add(keepAlive * 3 / 2)
+		}
+		_ = client.conn.SetReadDeadline(deadline)
+
+		p, err := readPacket(r, b.conf.MaxPacketSize)
+		if err != nil {
+			return
+		}
+
+		switch p.typ {
+		case typePublish:
+			pub, err := parsePublish(p)
+			if err != nil || pub.qos > 1 || !validTopic(pub.topic) || !b.auth.Allowed(client.Identity, pub.topic) {
+				return
+			}
+			_ = b.router.Route(client, pub.topic, pub.payload)
+			if pub.qos == 1 {
+				client.enqueue(ackPacket(typePuback, pub.packetID))
+			}
+
+		case typeSubscribe:
+			sub, err := parseSubscribe(p.body, true)
+			if err != nil {
+				return
+			}
+			b.subscribe(client, sub)
+
+		case typeUnsubscribe:
+			sub, err := parseSubscribe(p.body, false)
+			if err != nil {
+				return
+			}
+			b.mu.Lock()
+			for _, filter := range sub.filters {
+				b.unsubscribe(filter, client)
+			}
+			b.mu.Unlock()
+			client.enqueue(ackPacket(typeUnsuback, sub.packetID))
+
+		case typePingreq:
+			client.enqueue(&packet{typ: typePingresp})
+
+		case typeDisconnect:
+			return
+
+		default:
+			return
+		}
+	}
+}
+
+// subscribe grants the allowed filters of sub at QoS 0 and refuses the others
+func (b *Broker) subscribe(client *Client, sub *subscribePacket) {
+	codes := make([]byte, len(sub.filters))
+	granted := make([]string, 0, len(sub.filters))
+
+	b.mu.Lock()
+	for i, filter := range sub.filters {
+		if !validFilter(filter) || !b.auth.Allowed(client.Identity, filter) {
+			codes[i] = subackFailure
+			continue
+		}
+		if b.subscriptions[filter] == nil {
+			b.subscriptions[filter] = make(map[*Client]bool)
+		}
+		b.subscriptions[filter][client] = true
+		granted = append(granted, filter)
+	}
+	b.mu.Unlock()
+
+	client.enqueue(subackPacket(sub.packetID, codes))
+
+	if b.onSubscribe != nil {
+		for _, filter := range granted {
+			go b.onSubscribe(client, filter)
+		}
+	}
+}
+
+// clientKey scopes client IDs to the identity that authenticated, so a client ID
+// chosen by one device never names the session of another.
+type clientKey struct {
+	identity string
+	id       string
+}
+
+func keyOf(client *Client) clientKey {
+	return clientKey{identity: client.Identity, id: client.ID}
+}
+
+// register adds client, taking over the connection of an earlier client of the
+// same identity and ID. Reports false once the broker is closed.
+func (b *Broker) register(client *Client) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return false
+	}
+	key := keyOf(client)
+	if previous, ok := b.clients[key]; ok {
+		_ = previous.conn.Close()
+	}
+	b.clients[key] = client
+	return true
+}
+
+// unregister drops the client and its subscriptions, and stops its writePump.
+func (b *Broker) unregister(client *Client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if key := keyOf(client); b.clients[key] == client {
+		delete(b.clients, key)
+	}
+	for filter := range b.subscriptions {
+		b.unsubscribe(filter, client)
+	}
+	close(client.send)
+}
+
+// unsubscribe removes client from filter, dropping the filter once empty.
+// Caller must hold b.mu.Lock().
+func (b *Broker) unsubscribe(filter string, client *Client) {
+	delete(b.subscriptions[filter], client)
+	if len(b.subscriptions[filter]) == 0 {
+		delete(b.subscriptions, filter)
+	}
+}
@@ -0,0 +1,145 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		filter string
+		topic  string
+		want   bool
+	}{
+		{"devices/3/commands", "devices/3/commands", true},
+		{"devices/3/commands", "devices/4/commands", false},
+		{"devices/+/telemetry", "devices/3/telemetry", true},
+		{"devices/+/telemetry", "devices/3/ack", false},
+		{"devices/#", "devices/3/telemetry", true},
+		{"devices/3/#", "devices/3", true},
+		{"devices/+", "devices/3/ack", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Match(tt.filter, tt.topic), "%s ~ %s", tt.filter, tt.topic)
+	}
+}
+
+func TestReadPacket_RejectsOversizedBody(t *testing.T) {
+	raw := (&packet{typ: typePublish, body: make([]byte, 200)}).encode()
+
+	_, err := readPacket(bufio.NewReader(bytes.NewReader(raw)), 100)
+
+	assert.ErrorIs(t, err, ErrPacketTooLarge)
+}
+
+func TestBroker_Connect_RejectsInvalidSecret(t *testing.T) {
+	_, addr := startTestBroker(t, NewMessageRouter())
+
+	_, code := dialTestClient(t, addr, "pi", "wrong")
+
+	assert.Equal(t, connNotAuthorized, code)
+}
+
+func TestBroker_Publish_RoutesAndAcks(t *testing.T) {
+	handler := &recordingHandler{}
+	router := NewMessageRouter()
+	router.Register("devices/+/telemetry", handler)
+	_, addr := startTestBroker(t, router)
+
+	client, code := dialTestClient(t, addr, "pi", "secret-3")
+	assert.Equal(t, connAccepted, code)
+
+	client.publish("devices/3/telemetry", 1, `{"samples":[]}`)
+
+	puback := client.read()
+	assert.Equal(t, typePuback, puback.typ)
+	assert.Equal(t, []string{"devices/3/telemetry"}, handler.Topics())
+	assert.Equal(t, []string{`{"samples":[]}`}, handler.payloads)
+}
+
+func TestBroker_Publish_OtherDeviceTopic_Disconnects(t *testing.T) {
+	handler := &recordingHandler{}
+	router := NewMessageRouter()
+	router.Register("devices/+/telemetry", handler)
+	_, addr := startTestBroker(t, router)
+
+	client, _ := dialTestClient(t, addr, "pi", "secret-3")
+	client.publish("devices/4/telemetry", 0, `{}`)
+
+	_ = client.conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err := client.r.ReadByte()
+	assert.Error(t, err, "connection should be closed")
+	assert.Empty(t, handler.Topics())
+}
+
+func TestBroker_Subscribe_DeliversToSubscribers(t *testing.T) {
+	subscribed := make(chan string, 1)
+	broker, addr := startTestBroker(t, NewMessageRouter(), func(b *Broker) {
+		b.OnSubscribe(func(client *Client, filter string) { subscribed <- client.Identity + " " + filter })
+	})
+
+	client, _ := dialTestClient(t, addr, "pi", "secret-3")
+	codes := client.subscribe("devices/3/commands", "devices/4/commands")
+
+	assert.Equal(t, []byte{0x00, subackFailure}, codes)
+	select {
+	case got := <-subscribed:
+		assert.Equal(t, "3 devices/3/commands", got)
+	case <-time.After(time.Second):
+		t.Fatal("OnSubscribe did not run")
+	}
+
+	assert.True(t, broker.Publish("devices/3/commands", []byte(`{"id":9}`)))
+	assert.False(t, broker.Publish("devices/4/commands", []byte(`{"id":9}`)))
+
+	p := client.read()
+	pub, err := parsePublish(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "devices/3/commands", pub.topic)
+	assert.Equal(t, `{"id":9}`, string(pub.payload))
+}
+
+func TestBroker_Disconnect_DropsSubscriptions(t *testing.T) {
+	broker, addr := startTestBroker(t, NewMessageRouter())
+
+	client, _ := dialTestClient(t, addr, "pi", "secret-3")
+	client.subscribe("devices/3/commands")
+	client.write(&packet{typ: typeDisconnect})
+
+	assert.Eventually(t, func() bool {
+		return !broker.Publish("devices/3/commands", []byte(`{}`))
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBroker_Connect_SameClientIDOfOtherIdentity_KeepsSession(t *testing.T) {
+	broker, addr := startTestBroker(t, NewMessageRouter())
+
+	victim, _ := dialTestClient(t, addr, "pi", "secret-3")
+	victim.subscribe("devices/3/commands")
+
+	_, code := dialTestClient(t, addr, "pi", "secret-4")
+	assert.Equal(t, connAccepted, code)
+
+	assert.True(t, broker.Publish("devices/3/commands", []byte(`{"id":9}`)))
+	pub, err := parsePublish(victim.read())
+	assert.NoError(t, err)
+	assert.Equal(t, "devices/3/commands", pub.topic)
+}
+
+func TestBroker_Connect_SameClientIDOfSameIdentity_TakesOver(t *testing.T) {
+	_, addr := startTestBroker(t, NewMessageRouter())
+
+	previous, _ := dialTestClient(t, addr, "pi", "secret-3")
+
+	_, code := dialTestClient(t, addr, "pi", "secret-3")
+	assert.Equal(t, connAccepted, code)
+
+	_ = previous.conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, err := previous.r.ReadByte()
+	assert.Error(t, err, "earlier connection should be closed")
+}
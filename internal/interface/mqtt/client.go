@@ -0,0 +1,47 @@
+package mqtt
+
+import (
+	"net"
+	"time"
+)
+
+// Client represents a single MQTT connection. Identity is what the Authenticator
+// returned for its credentials.
+type Client struct {
+	conn      net.Conn
+	send      chan *packet
+	keepAlive time.Duration
+	ID        string
+	Identity  string
+}
+
+// NewClient creates a new Client for an authenticated connection.
+func NewClient(conn net.Conn, clientID, identity string) *Client {
+	return &Client{
+		conn:     conn,
+		send:     make(chan *packet, 256),
+		ID:       clientID,
+		Identity: identity,
+	}
+}
+
+// writePump writes queued packets to the connection until send is closed.
+// Must be called in a goroutine.
+func (c *Client) writePump() {
+	defer c.conn.Close()
+
+	for p := range c.send {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if _, err := c.conn.Write(p.encode()); err != nil {
+			return
+		}
+	}
+}
+
+// enqueue queues p for delivery. Drops silently if the buffer is full.
+func (c *Client) enqueue(p *packet) {
+	select {
+	case c.send <- p:
+	default:
+	}
+}
@@ -0,0 +1,19 @@
+package mqtt
+
+import "context"
+
+// MessageHandler handles the messages clients publish to the topics it is registered for.
+type MessageHandler interface {
+	Handle(client *Client, topic string, payload []byte) error
+}
+
+// Authenticator decides who may connect and which topics they may use.
+type Authenticator interface {
+
+	// Authenticate returns the identity of a client connecting with the credentials
+	Authenticate(ctx context.Context, username, password string) (string, error)
+
+	// Allowed reports whether the identity may publish or subscribe to topic,
+	// which may be a filter with wildcards
+	Allowed(identity, topic string) bool
+}
@@ -0,0 +1,45 @@
+package device
+
+import (
+	"encoding/json"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/devicechannel"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/interface/mqtt"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+)
+
+// CommandPayload is the message published on the command topic of a device.
+// The device answers on its ack topic with the same id.
+type CommandPayload struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	ExpiresAt string          `json:"expires_at"`
+}
+
+// CommandChannel delivers commands to devices subscribed to their command topic.
+type CommandChannel struct {
+	broker *mqtt.Broker
+	topics Topics
+}
+
+var _ devicechannel.Channel = (*CommandChannel)(nil)
+
+func NewCommandChannel(broker *mqtt.Broker, topics Topics) *CommandChannel {
+	return &CommandChannel{broker: broker, topics: topics}
+}
+
+func (ch *CommandChannel) Deliver(cmd *devicecommand.Command) bool {
+	msg, err := json.Marshal(CommandPayload{
+		ID:        int64(cmd.ID),
+		Type:      cmd.Type,
+		Payload:   cmd.Payload,
+		ExpiresAt: timeutil.Format(cmd.ExpiresAt, timeutil.FormatISO),
+	})
+	if err != nil {
+		return false
+	}
+
+	return ch.broker.Publish(ch.topics.Commands(cmd.DeviceID), msg)
+}
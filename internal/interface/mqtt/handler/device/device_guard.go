@@ -0,0 +1,34 @@
+package device
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/interface/mqtt"
+)
+
+// Guard authenticates headless devices by the device secret they send as the MQTT
+// password; the username is not checked. Devices may only use their own topics.
+type Guard struct {
+	devices auth.DeviceAuthenticator
+	topics  Topics
+}
+
+var _ mqtt.Authenticator = (*Guard)(nil)
+
+func NewGuard(devices auth.DeviceAuthenticator, topics Topics) *Guard {
+	return &Guard{devices: devices, topics: topics}
+}
+
+func (g *Guard) Authenticate(ctx context.Context, _, password string) (string, error) {
+	d, err := g.devices.AuthenticateDevice(ctx, password)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(int64(d.ID), 10), nil
+}
+
+func (g *Guard) Allowed(identity, topic string) bool {
+	return g.topics.owns(identity, topic)
+}
@@ -0,0 +1,136 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	commandApp "github.com/HiroLiang/goat-server/internal/application/devicecommand"
+	telemetryApp "github.com/HiroLiang/goat-server/internal/application/telemetry"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/interface/mqtt"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+	"go.uber.org/zap"
+)
+
+// AckPayload is the message a device publishes on its ack topic. Status is
+// succeeded or failed; result is any JSON value and error a message for failed commands.
+type AckPayload struct {
+	ID     int64           `json:"id"`
+	Status string          `json:"status"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// SamplePayload is one telemetry reading. At is an ISO time, the time the
+// sample arrives when a device leaves it empty.
+type SamplePayload struct {
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+	At     string  `json:"at,omitempty"`
+}
+
+// TelemetryPayload is the message a device publishes on its telemetry topic.
+type TelemetryPayload struct {
+	Samples []SamplePayload `json:"samples"`
+}
+
+// Acknowledger records the outcome of device commands.
+type Acknowledger interface {
+	Acknowledge(ctx context.Context, deviceID device.ID, input commandApp.AckInput) error
+}
+
+// Ingester stores the telemetry devices report.
+type Ingester interface {
+	Ingest(ctx context.Context, deviceID device.ID, input telemetryApp.IngestInput) (telemetryApp.IngestOutput, error)
+}
+
+// AckHandler handles the messages of the ack topics.
+type AckHandler struct {
+	commands Acknowledger
+}
+
+func NewAckHandler(commands Acknowledger) *AckHandler {
+	return &AckHandler{commands: commands}
+}
+
+func (h *AckHandler) Handle(client *mqtt.Client, _ string, payload []byte) error {
+	deviceID, err := device.ToID(client.Identity)
+	if err != nil {
+		return err
+	}
+
+	var p AckPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+
+	err = h.commands.Acknowledge(context.Background(), deviceID, commandApp.AckInput{
+		ID:     devicecommand.ID(p.ID),
+		Status: p.Status,
+		Result: p.Result,
+		Error:  p.Error,
+	})
+	if err != nil {
+		logger.Log.Warn("mqtt device ack rejected",
+			zap.String("device_id", client.Identity),
+			zap.Int64("command_id", p.ID),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+// TelemetryHandler handles the messages of the telemetry topics.
+type TelemetryHandler struct {
+	telemetry Ingester
+}
+
+func NewTelemetryHandler(telemetry Ingester) *TelemetryHandler {
+	return &TelemetryHandler{telemetry: telemetry}
+}
+
+func (h *TelemetryHandler) Handle(client *mqtt.Client, _ string, payload []byte) error {
+	deviceID, err := device.ToID(client.Identity)
+	if err != nil {
+		return err
+	}
+
+	var p TelemetryPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+
+	input, err := toIngestInput(p.Samples)
+	if err == nil {
+		_, err = h.telemetry.Ingest(context.Background(), deviceID, input)
+	}
+	if err != nil {
+		logger.Log.Warn("mqtt device telemetry rejected",
+			zap.String("device_id", client.Identity),
+			zap.Int("samples", len(p.Samples)),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+// toIngestInput converts the reported samples, rejecting malformed times
+func toIngestInput(samples []SamplePayload) (telemetryApp.IngestInput, error) {
+	input := telemetryApp.IngestInput{Samples: make([]telemetryApp.SampleInput, 0, len(samples))}
+	for i, s := range samples {
+		in := telemetryApp.SampleInput{Metric: s.Metric, Value: s.Value}
+		if s.At != "" {
+			at, err := timeutil.Parse(timeutil.FormatISO, s.At)
+			if err != nil {
+				return input, fmt.Errorf("sample %d: %w", i, telemetry.ErrInvalidTime)
+			}
+			in.At = at
+		}
+		input.Samples = append(input.Samples, in)
+	}
+	return input, nil
+}
@@ -0,0 +1,105 @@
+package device
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commandApp "github.com/HiroLiang/goat-server/internal/application/devicecommand"
+	telemetryApp "github.com/HiroLiang/goat-server/internal/application/telemetry"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/interface/mqtt"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitTestEnv()
+	m.Run()
+}
+
+var testTopics = Topics{Prefix: "goat/devices"}
+
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) AuthenticateDevice(_ context.Context, secret string) (*device.Device, error) {
+	if secret != "gdev_pi" {
+		return nil, device.ErrInvalidSecret
+	}
+	return &device.Device{ID: 3, Platform: device.Embedded}, nil
+}
+
+type recordingDevice struct {
+	deviceID device.ID
+	ack      commandApp.AckInput
+	samples  telemetryApp.IngestInput
+}
+
+func (r *recordingDevice) Acknowledge(_ context.Context, deviceID device.ID, input commandApp.AckInput) error {
+	r.deviceID = deviceID
+	r.ack = input
+	return nil
+}
+
+func (r *recordingDevice) Ingest(_ context.Context, deviceID device.ID, input telemetryApp.IngestInput) (telemetryApp.IngestOutput, error) {
+	r.deviceID = deviceID
+	r.samples = input
+	return telemetryApp.IngestOutput{Accepted: len(input.Samples)}, nil
+}
+
+func TestGuard(t *testing.T) {
+	guard := NewGuard(stubAuthenticator{}, testTopics)
+
+	identity, err := guard.Authenticate(context.Background(), "pi", "gdev_pi")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", identity)
+
+	_, err = guard.Authenticate(context.Background(), "pi", "gdev_other")
+	assert.ErrorIs(t, err, device.ErrInvalidSecret)
+
+	assert.True(t, guard.Allowed("3", "goat/devices/3/telemetry"))
+	assert.True(t, guard.Allowed("3", "goat/devices/3/#"))
+	assert.False(t, guard.Allowed("3", "goat/devices/30/telemetry"))
+	assert.False(t, guard.Allowed("3", "goat/devices/+/commands"))
+}
+
+func TestAckHandler_Handle_PassesOutcome(t *testing.T) {
+	commands := &recordingDevice{}
+	client := mqtt.NewClient(nil, "pi", "3")
+
+	err := NewAckHandler(commands).Handle(client, "goat/devices/3/ack", []byte(`{"id":9,"status":"failed","error":"busy"}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, device.ID(3), commands.deviceID)
+	assert.Equal(t, devicecommand.ID(9), commands.ack.ID)
+	assert.Equal(t, "busy", commands.ack.Error)
+}
+
+func TestTelemetryHandler_Handle_IngestsSamples(t *testing.T) {
+	ingester := &recordingDevice{}
+	client := mqtt.NewClient(nil, "pi", "3")
+
+	err := NewTelemetryHandler(ingester).Handle(client, "goat/devices/3/telemetry",
+		[]byte(`{"samples":[{"metric":"cpu","value":42,"at":"2026-03-01T12:00:00Z"}]}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, device.ID(3), ingester.deviceID)
+	if assert.Len(t, ingester.samples.Samples, 1) {
+		assert.Equal(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), ingester.samples.Samples[0].At.UTC())
+	}
+
+	err = NewTelemetryHandler(ingester).Handle(client, "goat/devices/3/telemetry",
+		[]byte(`{"samples":[{"metric":"cpu","value":42,"at":"noon"}]}`))
+	assert.ErrorIs(t, err, telemetry.ErrInvalidTime)
+}
+
+func TestCommandChannel_Deliver_NoSubscriber(t *testing.T) {
+	broker := mqtt.NewBroker(mqtt.Config{})
+	channel := NewCommandChannel(broker, testTopics)
+
+	delivered := channel.Deliver(&devicecommand.Command{ID: 9, DeviceID: 3, Type: "gpio.write", ExpiresAt: time.Now()})
+
+	assert.False(t, delivered)
+}
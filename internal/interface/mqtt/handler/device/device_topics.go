@@ -0,0 +1,41 @@
+package device
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+)
+
+// Topics lays out the topics of a device under Prefix:
+//
+//	<prefix>/<device id>/commands   commands for the device to subscribe to
+//	<prefix>/<device id>/ack        acknowledgements the device publishes
+//	<prefix>/<device id>/telemetry  telemetry batches the device publishes
+type Topics struct {
+	Prefix string
+}
+
+// Commands is the topic a device receives its commands on
+func (t Topics) Commands(deviceID device.ID) string {
+	return t.device(deviceID) + "/commands"
+}
+
+// Ack matches the acknowledgements of every device
+func (t Topics) Ack() string {
+	return t.Prefix + "/+/ack"
+}
+
+// Telemetry matches the telemetry of every device
+func (t Topics) Telemetry() string {
+	return t.Prefix + "/+/telemetry"
+}
+
+// owns reports whether topic is under the topics of deviceID
+func (t Topics) owns(deviceID string, topic string) bool {
+	return strings.HasPrefix(topic, t.Prefix+"/"+deviceID+"/")
+}
+
+func (t Topics) device(deviceID device.ID) string {
+	return t.Prefix + "/" + strconv.FormatInt(int64(deviceID), 10)
+}
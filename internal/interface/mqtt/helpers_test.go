@@ -0,0 +1,146 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubAuth accepts the password "secret-<identity>" and lets identities use the
+// topics under "devices/<identity>/".
+type stubAuth struct{}
+
+func (stubAuth) Authenticate(_ context.Context, _, password string) (string, error) {
+	identity, ok := strings.CutPrefix(password, "secret-")
+	if !ok {
+		return "", errors.New("invalid secret")
+	}
+	return identity, nil
+}
+
+func (stubAuth) Allowed(identity, topic string) bool {
+	return strings.HasPrefix(topic, "devices/"+identity+"/")
+}
+
+// recordingHandler is a test double for MessageHandler.
+type recordingHandler struct {
+	mu       sync.Mutex
+	topics   []string
+	payloads []string
+}
+
+func (h *recordingHandler) Handle(_ *Client, topic string, payload []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.topics = append(h.topics, topic)
+	h.payloads = append(h.payloads, string(payload))
+	return nil
+}
+
+func (h *recordingHandler) Topics() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.topics...)
+}
+
+// startTestBroker serves a broker on a loopback port until the test ends,
+// running setup before it serves.
+func startTestBroker(t *testing.T, router *MessageRouter, setup ...func(*Broker)) (*Broker, string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	broker := NewBroker(Config{MaxPacketSize: 1024, ConnectTimeout: time.Second})
+	for _, fn := range setup {
+		fn(broker)
+	}
+	go func() { _ = broker.Serve(ln, stubAuth{}, router) }()
+	t.Cleanup(func() { _ = broker.Close() })
+
+	return broker, ln.Addr().String()
+}
+
+// testClient speaks just enough MQTT to drive the broker.
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dialTestClient connects with password and returns the CONNACK return code.
+func dialTestClient(t *testing.T, addr, clientID, password string) (*testClient, byte) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	c := &testClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, protocolLevel, 0xc2) // username, password, clean session
+	body = binary.BigEndian.AppendUint16(body, 30)
+	body = appendString(body, clientID)
+	body = appendString(body, "device")
+	body = appendString(body, password)
+	c.write(&packet{typ: typeConnect, body: body})
+
+	connack := c.read()
+	if connack.typ != typeConnack {
+		t.Fatalf("got packet type %d, want CONNACK", connack.typ)
+	}
+	return c, connack.body[1]
+}
+
+func (c *testClient) write(p *packet) {
+	c.t.Helper()
+	if _, err := c.conn.Write(p.encode()); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+func (c *testClient) read() *packet {
+	c.t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(time.Second))
+	p, err := readPacket(c.r, 1<<16)
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+	return p
+}
+
+func (c *testClient) publish(topic string, qos byte, payload string) {
+	c.t.Helper()
+	body := appendString(nil, topic)
+	if qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, 1)
+	}
+	c.write(&packet{typ: typePublish, flags: qos << 1, body: append(body, payload...)})
+}
+
+// subscribe subscribes to filters and returns the SUBACK return codes.
+func (c *testClient) subscribe(filters ...string) []byte {
+	c.t.Helper()
+	body := binary.BigEndian.AppendUint16(nil, 7)
+	for _, f := range filters {
+		body = append(appendString(body, f), 0)
+	}
+	c.write(&packet{typ: typeSubscribe, flags: 0x02, body: body})
+
+	suback := c.read()
+	if suback.typ != typeSuback {
+		c.t.Fatalf("got packet type %d, want SUBACK", suback.typ)
+	}
+	return suback.body[2:]
+}
@@ -0,0 +1,235 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Control packet types of MQTT 3.1.1.
+const (
+	typeConnect     byte = 1
+	typeConnack     byte = 2
+	typePublish     byte = 3
+	typePuback      byte = 4
+	typeSubscribe   byte = 8
+	typeSuback      byte = 9
+	typeUnsubscribe byte = 10
+	typeUnsuback    byte = 11
+	typePingreq     byte = 12
+	typePingresp    byte = 13
+	typeDisconnect  byte = 14
+)
+
+// CONNACK return codes.
+const (
+	connAccepted          byte = 0x00
+	connBadProtocol       byte = 0x01
+	connIdentifierRefused byte = 0x02
+	connNotAuthorized     byte = 0x05
+)
+
+// subackFailure is the SUBACK return code of a refused subscription.
+const subackFailure byte = 0x80
+
+var (
+	ErrMalformedPacket = errors.New("malformed mqtt packet")
+	ErrPacketTooLarge  = errors.New("mqtt packet too large")
+)
+
+// packet is a control packet split into its fixed header and the rest.
+type packet struct {
+	typ   byte
+	flags byte
+	body  []byte
+}
+
+// connectPacket is the part of CONNECT the broker uses. Will messages are not supported
+// and skipped.
+type connectPacket struct {
+	protocol  string
+	level     byte
+	clientID  string
+	username  string
+	password  string
+	keepAlive uint16
+}
+
+type publishPacket struct {
+	topic    string
+	qos      byte
+	packetID uint16
+	payload  []byte
+}
+
+// subscribePacket holds the filters of SUBSCRIBE and UNSUBSCRIBE; the requested QoS
+// is ignored since messages go out at QoS 0.
+type subscribePacket struct {
+	packetID uint16
+	filters  []string
+}
+
+// readPacket reads the next packet, rejecting bodies over maxSize bytes
+func readPacket(r *bufio.Reader, maxSize int) (*packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	size, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return nil, ErrMalformedPacket
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		size += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	if size > maxSize {
+		return nil, ErrPacketTooLarge
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	return &packet{typ: header >> 4, flags: header & 0x0f, body: body}, nil
+}
+
+// encode returns the packet with its fixed header
+func (p *packet) encode() []byte {
+	out := []byte{p.typ<<4 | p.flags}
+	size := len(p.body)
+	for {
+		b := byte(size % 128)
+		size /= 128
+		if size > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if size == 0 {
+			break
+		}
+	}
+	return append(out, p.body...)
+}
+
+func parseConnect(body []byte) (*connectPacket, error) {
+	d := decoder{buf: body}
+	c := &connectPacket{protocol: d.string(), level: d.byte()}
+	flags := d.byte()
+	c.keepAlive = d.uint16()
+	c.clientID = d.string()
+	if flags&0x04 != 0 {
+		d.string()
+		d.string()
+	}
+	if flags&0x80 != 0 {
+		c.username = d.string()
+	}
+	if flags&0x40 != 0 {
+		c.password = d.string()
+	}
+	if d.err != nil || flags&0x01 != 0 {
+		return nil, ErrMalformedPacket
+	}
+	return c, nil
+}
+
+func parsePublish(p *packet) (*publishPacket, error) {
+	d := decoder{buf: p.body}
+	pub := &publishPacket{topic: d.string(), qos: (p.flags >> 1) & 0x03}
+	if pub.qos > 0 {
+		pub.packetID = d.uint16()
+	}
+	if d.err != nil || pub.topic == "" || pub.qos > 2 {
+		return nil, ErrMalformedPacket
+	}
+	pub.payload = d.buf
+	return pub, nil
+}
+
+// parseSubscribe parses SUBSCRIBE, or UNSUBSCRIBE when withQoS is false
+func parseSubscribe(body []byte, withQoS bool) (*subscribePacket, error) {
+	d := decoder{buf: body}
+	s := &subscribePacket{packetID: d.uint16()}
+	for d.err == nil && len(d.buf) > 0 {
+		s.filters = append(s.filters, d.string())
+		if withQoS {
+			d.byte()
+		}
+	}
+	if d.err != nil || len(s.filters) == 0 {
+		return nil, ErrMalformedPacket
+	}
+	return s, nil
+}
+
+func connackPacket(code byte) *packet {
+	return &packet{typ: typeConnack, body: []byte{0, code}}
+}
+
+func newPublishPacket(topic string, payload []byte) *packet {
+	body := appendString(nil, topic)
+	return &packet{typ: typePublish, body: append(body, payload...)}
+}
+
+// ackPacket builds PUBACK and UNSUBACK, which carry only the packet id
+func ackPacket(typ byte, packetID uint16) *packet {
+	return &packet{typ: typ, body: binary.BigEndian.AppendUint16(nil, packetID)}
+}
+
+func subackPacket(packetID uint16, codes []byte) *packet {
+	body := binary.BigEndian.AppendUint16(nil, packetID)
+	return &packet{typ: typeSuback, body: append(body, codes...)}
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// decoder reads the fields of a packet body, keeping the first error
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil || len(d.buf) < 1 {
+		d.err = ErrMalformedPacket
+		return 0
+	}
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b
+}
+
+func (d *decoder) uint16() uint16 {
+	if d.err != nil || len(d.buf) < 2 {
+		d.err = ErrMalformedPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint16(d.buf)
+	d.buf = d.buf[2:]
+	return v
+}
+
+func (d *decoder) string() string {
+	n := int(d.uint16())
+	if d.err != nil || len(d.buf) < n {
+		d.err = ErrMalformedPacket
+		return ""
+	}
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
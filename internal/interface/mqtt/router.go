@@ -0,0 +1,35 @@
+package mqtt
+
+import "fmt"
+
+type route struct {
+	filter  string
+	handler MessageHandler
+}
+
+// MessageRouter dispatches published messages to the handler of the first filter
+// matching their topic.
+type MessageRouter struct {
+	routes []route
+}
+
+// NewMessageRouter creates a new MessageRouter.
+func NewMessageRouter() *MessageRouter {
+	return &MessageRouter{}
+}
+
+// Register binds filter (e.g. "devices/+/telemetry") to a handler.
+func (r *MessageRouter) Register(filter string, handler MessageHandler) {
+	r.routes = append(r.routes, route{filter: filter, handler: handler})
+}
+
+// Route dispatches a message to the appropriate handler.
+// Returns an error if no filter matches topic.
+func (r *MessageRouter) Route(client *Client, topic string, payload []byte) error {
+	for _, rt := range r.routes {
+		if Match(rt.filter, topic) {
+			return rt.handler.Handle(client, topic, payload)
+		}
+	}
+	return fmt.Errorf("no handler for topic %q", topic)
+}
@@ -0,0 +1,45 @@
+package mqtt
+
+import "strings"
+
+// Match reports whether topic matches filter. Filters may use the "+" wildcard
+// for one level and end with "#" for any number of levels.
+func Match(filter, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+
+	for i, level := range filterLevels {
+		if level == "#" {
+			return i == len(filterLevels)-1
+		}
+		if i >= len(topicLevels) {
+			return false
+		}
+		if level != "+" && level != topicLevels[i] {
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
+
+// validFilter checks the wildcards of a subscription filter
+func validFilter(filter string) bool {
+	if filter == "" {
+		return false
+	}
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if level == "#" && i != len(levels)-1 {
+			return false
+		}
+		if len(level) > 1 && strings.ContainsAny(level, "+#") {
+			return false
+		}
+	}
+	return true
+}
+
+// validTopic rejects wildcards in the topics clients publish to
+func validTopic(topic string) bool {
+	return topic != "" && !strings.ContainsAny(topic, "+#")
+}
@@ -14,12 +14,14 @@ const (
 	maxMessageSize = 4096
 )
 
-// Client represents a single WebSocket connection.
+// Client represents a single WebSocket connection. Headless devices connect
+// with DeviceID set and no UserID, so they never count as their owner being online.
 type Client struct {
-	hub    *Hub
-	conn   *websocket.Conn
-	send   chan []byte
-	UserID string
+	hub      *Hub
+	conn     *websocket.Conn
+	send     chan []byte
+	UserID   string
+	DeviceID string
 }
 
 // NewClient creates a new Client and registers it with the hub.
@@ -32,6 +34,16 @@ func NewClient(hub *Hub, conn *websocket.Conn, userID string) *Client {
 	}
 }
 
+// NewDeviceClient creates a new Client for a headless device.
+func NewDeviceClient(hub *Hub, conn *websocket.Conn, deviceID string) *Client {
+	return &Client{
+		hub:      hub,
+		conn:     conn,
+		send:     make(chan []byte, 256),
+		DeviceID: deviceID,
+	}
+}
+
 // ReadPump reads messages from the WebSocket connection and routes them.
 // Must be called in a goroutine. Exits when the connection is closed.
 func (c *Client) ReadPump(router *MessageRouter) {
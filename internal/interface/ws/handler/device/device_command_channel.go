@@ -0,0 +1,50 @@
+package device
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/devicechannel"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/interface/ws"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+)
+
+// CommandPayload is the payload of a "device.command" message sent to a device.
+// The device answers with a "device.ack" message of the same id.
+type CommandPayload struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	ExpiresAt string          `json:"expires_at"`
+}
+
+// CommandChannel delivers commands to headless devices connected to the hub.
+type CommandChannel struct {
+	hub *ws.Hub
+}
+
+var _ devicechannel.Channel = (*CommandChannel)(nil)
+
+func NewCommandChannel(hub *ws.Hub) *CommandChannel {
+	return &CommandChannel{hub: hub}
+}
+
+func (ch *CommandChannel) Deliver(cmd *devicecommand.Command) bool {
+	payload, err := json.Marshal(CommandPayload{
+		ID:        int64(cmd.ID),
+		Type:      cmd.Type,
+		Payload:   cmd.Payload,
+		ExpiresAt: timeutil.Format(cmd.ExpiresAt, timeutil.FormatISO),
+	})
+	if err != nil {
+		return false
+	}
+
+	msg, err := json.Marshal(ws.Message{Type: "device.command", Payload: payload})
+	if err != nil {
+		return false
+	}
+
+	return ch.hub.SendToDevice(strconv.FormatInt(int64(cmd.DeviceID), 10), msg)
+}
@@ -0,0 +1,67 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+
+	commandApp "github.com/HiroLiang/goat-server/internal/application/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/interface/ws"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"go.uber.org/zap"
+)
+
+// AckPayload is the payload for a "device.ack" message. Status is succeeded or
+// failed; result is any JSON value and error a message for failed commands.
+type AckPayload struct {
+	ID     int64           `json:"id"`
+	Status string          `json:"status"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Acknowledger records the outcome of device commands.
+type Acknowledger interface {
+	Acknowledge(ctx context.Context, deviceID device.ID, input commandApp.AckInput) error
+}
+
+// AckHandler handles "device.ack" messages, only headless devices may send them.
+type AckHandler struct {
+	commands Acknowledger
+}
+
+func NewAckHandler(commands Acknowledger) *AckHandler {
+	return &AckHandler{commands: commands}
+}
+
+func (h *AckHandler) Handle(client *ws.Client, payload json.RawMessage) error {
+	if client == nil || client.DeviceID == "" {
+		return ws.ErrForbidden
+	}
+
+	deviceID, err := device.ToID(client.DeviceID)
+	if err != nil {
+		return ws.ErrForbidden
+	}
+
+	var p AckPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+
+	err = h.commands.Acknowledge(context.Background(), deviceID, commandApp.AckInput{
+		ID:     devicecommand.ID(p.ID),
+		Status: p.Status,
+		Result: p.Result,
+		Error:  p.Error,
+	})
+	if err != nil {
+		logger.Log.Warn("device.ack rejected",
+			zap.String("device_id", client.DeviceID),
+			zap.Int64("command_id", p.ID),
+			zap.Error(err),
+		)
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	commandApp "github.com/HiroLiang/goat-server/internal/application/devicecommand"
+	telemetryApp "github.com/HiroLiang/goat-server/internal/application/telemetry"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/devicecommand"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/interface/ws"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitTestEnv()
+	m.Run()
+}
+
+type recordingAcknowledger struct {
+	deviceID device.ID
+	input    commandApp.AckInput
+	err      error
+}
+
+func (r *recordingAcknowledger) Acknowledge(_ context.Context, deviceID device.ID, input commandApp.AckInput) error {
+	r.deviceID = deviceID
+	r.input = input
+	return r.err
+}
+
+func TestAckHandler_Handle_PassesOutcome(t *testing.T) {
+	commands := &recordingAcknowledger{}
+	handler := NewAckHandler(commands)
+	client := ws.NewDeviceClient(nil, nil, "3")
+
+	err := handler.Handle(client, json.RawMessage(`{"id":9,"status":"succeeded","result":{"temp":21.5}}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, device.ID(3), commands.deviceID)
+	assert.Equal(t, devicecommand.ID(9), commands.input.ID)
+	assert.Equal(t, "succeeded", commands.input.Status)
+	assert.JSONEq(t, `{"temp":21.5}`, string(commands.input.Result))
+}
+
+func TestAckHandler_Handle_UserClient_Forbidden(t *testing.T) {
+	commands := &recordingAcknowledger{}
+	handler := NewAckHandler(commands)
+	client := ws.NewClient(nil, nil, "user1")
+
+	err := handler.Handle(client, json.RawMessage(`{"id":9,"status":"succeeded"}`))
+
+	assert.ErrorIs(t, err, ws.ErrForbidden)
+	assert.Zero(t, commands.deviceID)
+}
+
+func TestAckHandler_Handle_PropagatesRejection(t *testing.T) {
+	handler := NewAckHandler(&recordingAcknowledger{err: devicecommand.ErrCommandClosed})
+	client := ws.NewDeviceClient(nil, nil, "3")
+
+	err := handler.Handle(client, json.RawMessage(`{"id":9,"status":"failed"}`))
+
+	assert.ErrorIs(t, err, devicecommand.ErrCommandClosed)
+}
+
+type recordingTelemetry struct {
+	deviceID device.ID
+	input    telemetryApp.IngestInput
+	watchErr error
+}
+
+func (r *recordingTelemetry) Ingest(_ context.Context, deviceID device.ID, input telemetryApp.IngestInput) (telemetryApp.IngestOutput, error) {
+	r.deviceID = deviceID
+	r.input = input
+	return telemetryApp.IngestOutput{Accepted: len(input.Samples)}, nil
+}
+
+func (r *recordingTelemetry) Watch(_ context.Context, _ string, _ device.ID) error {
+	return r.watchErr
+}
+
+func TestTelemetryHandler_Handle_IngestsSamples(t *testing.T) {
+	ingester := &recordingTelemetry{}
+	handler := NewTelemetryHandler(ingester)
+	client := ws.NewDeviceClient(nil, nil, "3")
+
+	err := handler.Handle(client, json.RawMessage(`{"samples":[{"metric":"cpu","value":42},{"metric":"temperature","value":21.5,"at":"2026-03-01T12:00:00Z"}]}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, device.ID(3), ingester.deviceID)
+	if assert.Len(t, ingester.input.Samples, 2) {
+		assert.True(t, ingester.input.Samples[0].At.IsZero())
+		assert.Equal(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), ingester.input.Samples[1].At.UTC())
+	}
+}
+
+func TestTelemetryHandler_Handle_RejectsBadTime(t *testing.T) {
+	handler := NewTelemetryHandler(&recordingTelemetry{})
+	client := ws.NewDeviceClient(nil, nil, "3")
+
+	err := handler.Handle(client, json.RawMessage(`{"samples":[{"metric":"cpu","value":42,"at":"yesterday"}]}`))
+
+	assert.ErrorIs(t, err, telemetry.ErrInvalidTime)
+}
+
+func TestSubscribeHandler_Handle_RejectsOthers(t *testing.T) {
+	hub := ws.NewHub()
+	handler := NewSubscribeHandler(hub, &recordingTelemetry{watchErr: device.ErrDeviceNotFound})
+
+	err := handler.Handle(ws.NewClient(hub, nil, "2"), json.RawMessage(`{"device_id":3}`))
+	assert.ErrorIs(t, err, device.ErrDeviceNotFound)
+
+	err = handler.Handle(ws.NewDeviceClient(hub, nil, "4"), json.RawMessage(`{"device_id":3}`))
+	assert.ErrorIs(t, err, ws.ErrForbidden)
+}
@@ -0,0 +1,69 @@
+package device
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/devicechannel"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/interface/ws"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+)
+
+// SamplePayload is one telemetry reading. At is an ISO time, the time the
+// sample arrives when a device leaves it empty.
+type SamplePayload struct {
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+	At     string  `json:"at,omitempty"`
+}
+
+// TelemetryPayload is the payload of "device.telemetry" messages, sent by devices
+// reporting telemetry and to the owners watching them.
+type TelemetryPayload struct {
+	DeviceID int64           `json:"device_id,omitempty"`
+	Samples  []SamplePayload `json:"samples"`
+}
+
+// TelemetryFeed streams the telemetry of a device to the clients watching it.
+type TelemetryFeed struct {
+	hub *ws.Hub
+}
+
+var _ devicechannel.Feed = (*TelemetryFeed)(nil)
+
+func NewTelemetryFeed(hub *ws.Hub) *TelemetryFeed {
+	return &TelemetryFeed{hub: hub}
+}
+
+func (f *TelemetryFeed) Publish(deviceID device.ID, samples []*telemetry.Sample) {
+	p := TelemetryPayload{
+		DeviceID: int64(deviceID),
+		Samples:  make([]SamplePayload, 0, len(samples)),
+	}
+	for _, s := range samples {
+		p.Samples = append(p.Samples, SamplePayload{
+			Metric: s.Metric,
+			Value:  s.Value,
+			At:     timeutil.Format(s.At, timeutil.FormatISO),
+		})
+	}
+
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+
+	msg, err := json.Marshal(ws.Message{Type: "device.telemetry", Payload: payload})
+	if err != nil {
+		return
+	}
+
+	f.hub.Publish(TelemetryTopic(deviceID), msg)
+}
+
+// TelemetryTopic is the hub topic of the telemetry of a device
+func TelemetryTopic(deviceID device.ID) string {
+	return "device:" + strconv.FormatInt(int64(deviceID), 10) + ":telemetry"
+}
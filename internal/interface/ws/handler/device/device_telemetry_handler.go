@@ -0,0 +1,137 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	telemetryApp "github.com/HiroLiang/goat-server/internal/application/telemetry"
+	"github.com/HiroLiang/goat-server/internal/domain/device"
+	"github.com/HiroLiang/goat-server/internal/domain/telemetry"
+	"github.com/HiroLiang/goat-server/internal/interface/ws"
+	"github.com/HiroLiang/goat-server/internal/logger"
+	"github.com/HiroLiang/goat-server/internal/shared/timeutil"
+	"go.uber.org/zap"
+)
+
+// WatchPayload is the payload for "device.telemetry.subscribe" and
+// "device.telemetry.unsubscribe" messages.
+type WatchPayload struct {
+	DeviceID int64 `json:"device_id"`
+}
+
+// Ingester stores the telemetry devices report.
+type Ingester interface {
+	Ingest(ctx context.Context, deviceID device.ID, input telemetryApp.IngestInput) (telemetryApp.IngestOutput, error)
+}
+
+// Watcher checks whether a user may watch the telemetry of a device.
+type Watcher interface {
+	Watch(ctx context.Context, userID string, deviceID device.ID) error
+}
+
+// TelemetryHandler handles "device.telemetry" messages, only headless devices may send them.
+type TelemetryHandler struct {
+	telemetry Ingester
+}
+
+func NewTelemetryHandler(telemetry Ingester) *TelemetryHandler {
+	return &TelemetryHandler{telemetry: telemetry}
+}
+
+func (h *TelemetryHandler) Handle(client *ws.Client, payload json.RawMessage) error {
+	if client == nil || client.DeviceID == "" {
+		return ws.ErrForbidden
+	}
+
+	deviceID, err := device.ToID(client.DeviceID)
+	if err != nil {
+		return ws.ErrForbidden
+	}
+
+	var p TelemetryPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+
+	input, err := toIngestInput(p.Samples)
+	if err == nil {
+		_, err = h.telemetry.Ingest(context.Background(), deviceID, input)
+	}
+	if err != nil {
+		logger.Log.Warn("device.telemetry rejected",
+			zap.String("device_id", client.DeviceID),
+			zap.Int("samples", len(p.Samples)),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+// SubscribeHandler handles "device.telemetry.subscribe" messages from the owner of a device.
+type SubscribeHandler struct {
+	hub       *ws.Hub
+	telemetry Watcher
+}
+
+func NewSubscribeHandler(hub *ws.Hub, telemetry Watcher) *SubscribeHandler {
+	return &SubscribeHandler{hub: hub, telemetry: telemetry}
+}
+
+func (h *SubscribeHandler) Handle(client *ws.Client, payload json.RawMessage) error {
+	if client == nil || client.UserID == "" {
+		return ws.ErrForbidden
+	}
+
+	var p WatchPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+
+	if err := h.telemetry.Watch(context.Background(), client.UserID, device.ID(p.DeviceID)); err != nil {
+		return err
+	}
+
+	h.hub.Subscribe(TelemetryTopic(device.ID(p.DeviceID)), client)
+	return nil
+}
+
+// UnsubscribeHandler handles "device.telemetry.unsubscribe" messages.
+type UnsubscribeHandler struct {
+	hub *ws.Hub
+}
+
+func NewUnsubscribeHandler(hub *ws.Hub) *UnsubscribeHandler {
+	return &UnsubscribeHandler{hub: hub}
+}
+
+func (h *UnsubscribeHandler) Handle(client *ws.Client, payload json.RawMessage) error {
+	if client == nil {
+		return ws.ErrForbidden
+	}
+
+	var p WatchPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+
+	h.hub.Unsubscribe(TelemetryTopic(device.ID(p.DeviceID)), client)
+	return nil
+}
+
+// toIngestInput converts the reported samples, rejecting malformed times
+func toIngestInput(samples []SamplePayload) (telemetryApp.IngestInput, error) {
+	input := telemetryApp.IngestInput{Samples: make([]telemetryApp.SampleInput, 0, len(samples))}
+	for i, s := range samples {
+		in := telemetryApp.SampleInput{Metric: s.Metric, Value: s.Value}
+		if s.At != "" {
+			at, err := timeutil.Parse(timeutil.FormatISO, s.At)
+			if err != nil {
+				return input, fmt.Errorf("sample %d: %w", i, telemetry.ErrInvalidTime)
+			}
+			in.At = at
+		}
+		input.Samples = append(input.Samples, in)
+	}
+	return input, nil
+}
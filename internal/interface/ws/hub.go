@@ -9,7 +9,16 @@ type Hub struct {
 
 	// userClients maps userID → clients; protected by mu.
 	userClients map[string][]*Client
-	mu          sync.RWMutex
+
+	// deviceClients maps deviceID → clients of headless devices; protected by mu.
+	deviceClients map[string][]*Client
+
+	// topics maps topic → subscribed clients; protected by mu.
+	topics map[string]map[*Client]bool
+	mu     sync.RWMutex
+
+	// onDeviceConnect runs in its own goroutine for every device that connects.
+	onDeviceConnect func(deviceID string)
 
 	// Broadcast sends a message to every connected client.
 	Broadcast chan []byte
@@ -24,14 +33,22 @@ type Hub struct {
 // NewHub creates a new Hub.
 func NewHub() *Hub {
 	return &Hub{
-		clients:     make(map[*Client]bool),
-		userClients: make(map[string][]*Client),
-		Broadcast:   make(chan []byte, 256),
-		Register:    make(chan *Client),
-		Unregister:  make(chan *Client),
+		clients:       make(map[*Client]bool),
+		userClients:   make(map[string][]*Client),
+		deviceClients: make(map[string][]*Client),
+		topics:        make(map[string]map[*Client]bool),
+		Broadcast:     make(chan []byte, 256),
+		Register:      make(chan *Client),
+		Unregister:    make(chan *Client),
 	}
 }
 
+// OnDeviceConnect sets fn to run once a device connection is registered, such as
+// delivering what was queued while it was offline. Must be called before Run.
+func (h *Hub) OnDeviceConnect(fn func(deviceID string)) {
+	h.onDeviceConnect = fn
+}
+
 // Run starts the Hub event loop. Must be called in a goroutine.
 func (h *Hub) Run() {
 	for {
@@ -43,6 +60,14 @@ func (h *Hub) Run() {
 				h.userClients[client.UserID] = append(h.userClients[client.UserID], client)
 				h.mu.Unlock()
 			}
+			if client.DeviceID != "" {
+				h.mu.Lock()
+				h.deviceClients[client.DeviceID] = append(h.deviceClients[client.DeviceID], client)
+				h.mu.Unlock()
+				if h.onDeviceConnect != nil {
+					go h.onDeviceConnect(client.DeviceID)
+				}
+			}
 
 		case client := <-h.Unregister:
 			if _, ok := h.clients[client]; ok {
@@ -50,9 +75,25 @@ func (h *Hub) Run() {
 				close(client.send)
 				if client.UserID != "" {
 					h.mu.Lock()
-					h.removeUserClient(client.UserID, client)
+					h.userClients[client.UserID] = removeClient(h.userClients[client.UserID], client)
+					if len(h.userClients[client.UserID]) == 0 {
+						delete(h.userClients, client.UserID)
+					}
+					h.mu.Unlock()
+				}
+				if client.DeviceID != "" {
+					h.mu.Lock()
+					h.deviceClients[client.DeviceID] = removeClient(h.deviceClients[client.DeviceID], client)
+					if len(h.deviceClients[client.DeviceID]) == 0 {
+						delete(h.deviceClients, client.DeviceID)
+					}
 					h.mu.Unlock()
 				}
+				h.mu.Lock()
+				for topic := range h.topics {
+					h.unsubscribe(topic, client)
+				}
+				h.mu.Unlock()
 			}
 
 		case message := <-h.Broadcast:
@@ -75,17 +116,78 @@ func (h *Hub) SendToUser(userID string, msg []byte) {
 	}
 }
 
-// removeUserClient removes target from h.userClients[userID].
+// SendToDevice sends a message to the connections of a headless device, and
+// reports false when it has none. Safe to call from any goroutine.
+func (h *Hub) SendToDevice(deviceID string, msg []byte) bool {
+	h.mu.RLock()
+	clients := h.deviceClients[deviceID]
+	h.mu.RUnlock()
+
+	for _, c := range clients {
+		c.Send(msg)
+	}
+	return len(clients) > 0
+}
+
+// Subscribe adds client to the receivers of topic until it unsubscribes or
+// disconnects. Safe to call from any goroutine.
+func (h *Hub) Subscribe(topic string, client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.topics[topic] == nil {
+		h.topics[topic] = make(map[*Client]bool)
+	}
+	h.topics[topic][client] = true
+}
+
+// Unsubscribe removes client from the receivers of topic.
+// Safe to call from any goroutine.
+func (h *Hub) Unsubscribe(topic string, client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.unsubscribe(topic, client)
+}
+
+// Publish sends a message to every client subscribed to topic.
+// Safe to call from any goroutine.
+func (h *Hub) Publish(topic string, msg []byte) {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.topics[topic]))
+	for c := range h.topics[topic] {
+		clients = append(clients, c)
+	}
+	h.mu.RUnlock()
+
+	for _, c := range clients {
+		c.Send(msg)
+	}
+}
+
+// IsOnline reports whether userID has at least one active connection.
+// Safe to call from any goroutine.
+func (h *Hub) IsOnline(userID string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.userClients[userID]) > 0
+}
+
+// removeClient removes target from clients.
 // Caller must hold h.mu.Lock().
-func (h *Hub) removeUserClient(userID string, target *Client) {
-	clients := h.userClients[userID]
+func removeClient(clients []*Client, target *Client) []*Client {
 	for i, c := range clients {
 		if c == target {
-			h.userClients[userID] = append(clients[:i], clients[i+1:]...)
-			break
+			return append(clients[:i], clients[i+1:]...)
 		}
 	}
-	if len(h.userClients[userID]) == 0 {
-		delete(h.userClients, userID)
+	return clients
+}
+
+// unsubscribe removes client from topic, dropping the topic once empty.
+// Caller must hold h.mu.Lock().
+func (h *Hub) unsubscribe(topic string, client *Client) {
+	delete(h.topics[topic], client)
+	if len(h.topics[topic]) == 0 {
+		delete(h.topics, topic)
 	}
 }
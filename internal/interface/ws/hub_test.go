@@ -171,3 +171,105 @@ func TestHub_UnregisterWithUserID_RemovesFromUserIndex(t *testing.T) {
 		// expected: nothing delivered
 	}
 }
+
+func TestHub_IsOnline_TracksConnections(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	alice := newTestClient(hub, "alice")
+	hub.Register <- alice
+	// Barrier: Run() has finished indexing alice once it accepts the next client.
+	hub.Register <- newTestClient(hub, "")
+
+	assert.True(t, hub.IsOnline("alice"))
+	assert.False(t, hub.IsOnline("bob"))
+
+	hub.Unregister <- alice
+	hub.Register <- newTestClient(hub, "")
+
+	assert.False(t, hub.IsOnline("alice"))
+}
+
+func TestHub_SendToDevice_OnlyDeviceReceives(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	owner := newTestClient(hub, "alice")
+	pi := &Client{hub: hub, send: make(chan []byte, 256), DeviceID: "3"}
+	hub.Register <- owner
+	hub.Register <- pi
+	barrier := newTestClient(hub, "")
+	hub.Register <- barrier
+
+	assert.True(t, hub.SendToDevice("3", []byte("cmd")))
+	assert.False(t, hub.SendToDevice("4", []byte("cmd")))
+
+	assert.Equal(t, []byte("cmd"), <-pi.send)
+	select {
+	case got := <-owner.send:
+		t.Errorf("owner received %q", got)
+	default:
+	}
+}
+
+func TestHub_OnDeviceConnect_RunsForDevices(t *testing.T) {
+	hub := NewHub()
+	connected := make(chan string, 1)
+	hub.OnDeviceConnect(func(deviceID string) { connected <- deviceID })
+	go hub.Run()
+
+	hub.Register <- newTestClient(hub, "alice")
+	hub.Register <- &Client{hub: hub, send: make(chan []byte, 256), DeviceID: "3"}
+
+	select {
+	case got := <-connected:
+		assert.Equal(t, "3", got)
+	case <-time.After(time.Second):
+		t.Fatal("OnDeviceConnect did not run")
+	}
+}
+
+func TestHub_Publish_OnlySubscribersReceive(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	alice := newTestClient(hub, "alice")
+	bob := newTestClient(hub, "bob")
+	hub.Register <- alice
+	hub.Register <- bob
+
+	hub.Subscribe("device:3", alice)
+	hub.Publish("device:3", []byte("sample"))
+
+	assert.Equal(t, []byte("sample"), <-alice.send)
+	select {
+	case got := <-bob.send:
+		t.Errorf("bob received %q", got)
+	default:
+	}
+
+	hub.Unsubscribe("device:3", alice)
+	hub.Publish("device:3", []byte("sample"))
+
+	select {
+	case got := <-alice.send:
+		t.Errorf("alice received %q after unsubscribing", got)
+	default:
+	}
+}
+
+func TestHub_Unregister_DropsSubscriptions(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+
+	alice := newTestClient(hub, "alice")
+	hub.Register <- alice
+	hub.Subscribe("device:3", alice)
+	hub.Unregister <- alice
+	barrier := newTestClient(hub, "")
+	hub.Register <- barrier
+
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	assert.NotContains(t, hub.topics, "device:3")
+}
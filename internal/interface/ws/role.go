@@ -0,0 +1,43 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/HiroLiang/goat-server/internal/application/shared/auth"
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+)
+
+// ErrForbidden is returned when the client lacks the role a handler requires.
+var ErrForbidden = errors.New("forbidden")
+
+// roleHandler runs the next handler only for clients holding one of roles.
+type roleHandler struct {
+	checker auth.RoleChecker
+	roles   []role.Type
+	next    MessageHandler
+}
+
+// RequireRole wraps next so that only clients holding one of roles reach it.
+// Roles are checked on every message, so role changes apply to open connections.
+func RequireRole(checker auth.RoleChecker, next MessageHandler, roles ...role.Type) MessageHandler {
+	return &roleHandler{checker: checker, roles: roles, next: next}
+}
+
+func (h *roleHandler) Handle(client *Client, payload json.RawMessage) error {
+	if client == nil || client.UserID == "" {
+		return ErrForbidden
+	}
+
+	_, err := h.checker.MatchRole(context.Background(), client.UserID, h.roles...)
+	if errors.Is(err, user.ErrForbidden) || errors.Is(err, user.ErrInvalidUser) {
+		return ErrForbidden
+	}
+	if err != nil {
+		return err
+	}
+
+	return h.next.Handle(client, payload)
+}
@@ -0,0 +1,50 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/HiroLiang/goat-server/internal/domain/role"
+	"github.com/HiroLiang/goat-server/internal/domain/user"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubRoleChecker grants the roles listed per user ID.
+type stubRoleChecker struct {
+	roles map[string]role.Type
+}
+
+func (s stubRoleChecker) MatchRole(_ context.Context, userID string, roles ...role.Type) (*role.Role, error) {
+	held, ok := s.roles[userID]
+	if !ok {
+		return nil, user.ErrForbidden
+	}
+	for _, r := range roles {
+		if r == held {
+			return &role.Role{Type: held}, nil
+		}
+	}
+	return nil, user.ErrForbidden
+}
+
+func TestRequireRole_AllowsMatchingRole(t *testing.T) {
+	next := &mockHandler{}
+	checker := stubRoleChecker{roles: map[string]role.Type{"1": role.Admin}}
+	handler := RequireRole(checker, next, role.Admin, role.Vendor)
+
+	err := handler.Handle(newTestClient(nil, "1"), json.RawMessage(`{}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, next.Calls())
+}
+
+func TestRequireRole_RejectsOtherRolesAndAnonymous(t *testing.T) {
+	next := &mockHandler{}
+	checker := stubRoleChecker{roles: map[string]role.Type{"1": role.User}}
+	handler := RequireRole(checker, next, role.Admin)
+
+	assert.ErrorIs(t, handler.Handle(newTestClient(nil, "1"), nil), ErrForbidden)
+	assert.ErrorIs(t, handler.Handle(newTestClient(nil, ""), nil), ErrForbidden)
+	assert.Equal(t, 0, next.Calls())
+}
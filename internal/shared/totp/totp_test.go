@@ -0,0 +1,63 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed of the RFC 6238 test vectors, "12345678901234567890"
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 Appendix B, last six digits of the 8 digit SHA1 codes
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, want := range vectors {
+		got, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Code(%d) = %s, want %s", unix, got, want)
+		}
+	}
+}
+
+func TestValidate_AcceptsSkewOnly(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	previous, _ := Code(rfcSecret, Step(now)-1)
+	old, _ := Code(rfcSecret, Step(now)-2)
+
+	if step, ok := Validate(rfcSecret, previous, now, 1); !ok || step != Step(now)-1 {
+		t.Errorf("Validate(previous) = %d, %v, want step %d", step, ok, Step(now)-1)
+	}
+	if _, ok := Validate(rfcSecret, old, now, 1); ok {
+		t.Error("Validate(two steps old) = true, want false")
+	}
+	if _, ok := Validate(rfcSecret, "abc", now, 1); ok {
+		t.Error("Validate(malformed) = true, want false")
+	}
+}
+
+func TestGenerateSecret_RoundTripsThroughURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Fatalf("Code(generated) error = %v", err)
+	}
+
+	uri := ProvisioningURI(secret, "Goat", "a@b.com")
+	if !strings.HasPrefix(uri, "otpauth://totp/Goat:a@b.com?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("ProvisioningURI() = %s", uri)
+	}
+}
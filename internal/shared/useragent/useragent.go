@@ -0,0 +1,93 @@
+package useragent
+
+import (
+	"regexp"
+	"strings"
+)
+
+type Platform string
+
+const (
+	Desktop Platform = "desktop"
+	Mobile  Platform = "mobile"
+	Tablet  Platform = "tablet"
+	Bot     Platform = "bot"
+	Unknown Platform = "unknown"
+)
+
+// Info is the coarse description of a client shown in session listings.
+type Info struct {
+	Browser  string
+	OS       string
+	Platform Platform
+}
+
+// rule maps the first matching pattern to a name; order matters because many
+// browsers also announce the engines they are based on (e.g. Edge says Chrome and Safari).
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+var browserRules = []rule{
+	{"Goat App", regexp.MustCompile(`(?i)goat-app`)},
+	{"Tauri", regexp.MustCompile(`(?i)tauri`)},
+	{"Edge", regexp.MustCompile(`Edg(e|A|iOS)?/`)},
+	{"Opera", regexp.MustCompile(`OPR/|Opera`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/`)},
+	{"Firefox", regexp.MustCompile(`Firefox/|FxiOS/`)},
+	{"Chrome", regexp.MustCompile(`Chrome/|CriOS/`)},
+	{"Safari", regexp.MustCompile(`Safari/`)},
+	{"curl", regexp.MustCompile(`^curl/`)},
+	{"Postman", regexp.MustCompile(`PostmanRuntime/`)},
+	{"Go HTTP Client", regexp.MustCompile(`^Go-http-client/`)},
+}
+
+var osRules = []rule{
+	{"iPadOS", regexp.MustCompile(`iPad`)},
+	{"iOS", regexp.MustCompile(`iPhone|iPod`)},
+	{"Android", regexp.MustCompile(`Android`)},
+	{"Windows", regexp.MustCompile(`Windows`)},
+	{"ChromeOS", regexp.MustCompile(`CrOS`)},
+	{"macOS", regexp.MustCompile(`Mac OS X|Macintosh`)},
+	{"Linux", regexp.MustCompile(`Linux`)},
+}
+
+var botPattern = regexp.MustCompile(`(?i)bot|crawler|spider|curl/|PostmanRuntime|Go-http-client`)
+
+// Parse describes the client of a User-Agent header. Unrecognized parts are "Unknown".
+func Parse(ua string) Info {
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		return Info{Browser: "Unknown", OS: "Unknown", Platform: Unknown}
+	}
+
+	info := Info{
+		Browser: match(browserRules, ua),
+		OS:      match(osRules, ua),
+	}
+
+	switch {
+	case botPattern.MatchString(ua):
+		info.Platform = Bot
+	case info.OS == "iPadOS" || (info.OS == "Android" && !strings.Contains(ua, "Mobile")):
+		info.Platform = Tablet
+	case info.OS == "iOS" || info.OS == "Android" || strings.Contains(ua, "Mobile"):
+		info.Platform = Mobile
+	case info.OS != "Unknown":
+		info.Platform = Desktop
+	default:
+		info.Platform = Unknown
+	}
+
+	return info
+}
+
+func match(rules []rule, ua string) string {
+	for _, r := range rules {
+		if r.pattern.MatchString(ua) {
+			return r.name
+		}
+	}
+	return "Unknown"
+}
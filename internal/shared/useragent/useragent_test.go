@@ -0,0 +1,57 @@
+package useragent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want Info
+	}{
+		{
+			name: "chrome on windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want: Info{Browser: "Chrome", OS: "Windows", Platform: Desktop},
+		},
+		{
+			name: "edge is not chrome",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51",
+			want: Info{Browser: "Edge", OS: "Windows", Platform: Desktop},
+		},
+		{
+			name: "safari on iphone",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			want: Info{Browser: "Safari", OS: "iOS", Platform: Mobile},
+		},
+		{
+			name: "android tablet",
+			ua:   "Mozilla/5.0 (Linux; Android 14; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want: Info{Browser: "Chrome", OS: "Android", Platform: Tablet},
+		},
+		{
+			name: "firefox on mac",
+			ua:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 14.4; rv:125.0) Gecko/20100101 Firefox/125.0",
+			want: Info{Browser: "Firefox", OS: "macOS", Platform: Desktop},
+		},
+		{
+			name: "curl",
+			ua:   "curl/8.5.0",
+			want: Info{Browser: "curl", OS: "Unknown", Platform: Bot},
+		},
+		{
+			name: "empty",
+			ua:   "",
+			want: Info{Browser: "Unknown", OS: "Unknown", Platform: Unknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Parse(tt.ua))
+		})
+	}
+}